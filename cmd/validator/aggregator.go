@@ -0,0 +1,1306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/buildinfo"
+	"latency-arbitrage-validator/internal/chaos"
+	"latency-arbitrage-validator/internal/checkpoint"
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/blackout"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/core/paper"
+	sigengine "latency-arbitrage-validator/internal/core/signal"
+	"latency-arbitrage-validator/internal/core/store"
+	"latency-arbitrage-validator/internal/exchange/binance"
+	"latency-arbitrage-validator/internal/exchange/bittap"
+	"latency-arbitrage-validator/internal/exchange/connerr"
+	"latency-arbitrage-validator/internal/exchange/dedup"
+	"latency-arbitrage-validator/internal/exchange/okx"
+	"latency-arbitrage-validator/internal/exchange/status"
+	"latency-arbitrage-validator/internal/output/jsonl"
+	"latency-arbitrage-validator/internal/output/sink"
+	"latency-arbitrage-validator/internal/stats/bars"
+	"latency-arbitrage-validator/internal/stats/equity"
+	"latency-arbitrage-validator/internal/stats/ev"
+	"latency-arbitrage-validator/internal/stats/infoshare"
+	"latency-arbitrage-validator/internal/stats/latency"
+	"latency-arbitrage-validator/internal/stats/latencysample"
+	"latency-arbitrage-validator/internal/stats/leadlag"
+	"latency-arbitrage-validator/internal/stats/pipeline"
+	"latency-arbitrage-validator/internal/stats/procstats"
+	"latency-arbitrage-validator/internal/stats/quotespread"
+	"latency-arbitrage-validator/internal/stats/significance"
+	"latency-arbitrage-validator/internal/stats/spreadsample"
+	"latency-arbitrage-validator/internal/stats/staleness"
+	"latency-arbitrage-validator/internal/stats/volatility"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+type rateKey struct {
+	ex  string
+	sym string
+}
+
+// leaderPipeline 单条 Leader 链路的一套策略实例
+// Engine/Executor/EV 计算器互相独立，不与其它链路或变体共享状态。
+type leaderPipeline struct {
+	// variant 策略变体名称（基础策略为空）
+	variant string
+	// leader 领先交易所: okx 或 binance
+	leader string
+
+	engine *sigengine.Engine
+	exec   *paper.Executor
+	ev     *ev.Calculator
+	equity *equity.Curve
+	// hourly 按入场 UTC 小时分桶的 EV（会话累计）
+	hourly *ev.HourOfDay
+	// evFlipExit 持仓期间交易对滚动 EV 转负时提前平仓
+	evFlipExit bool
+	// leaderFeeBps Leader 侧对冲腿往返手续费（基点，计入信号 FeeBps）
+	leaderFeeBps float64
+
+	// sigSamples 上次显著性检验所用的净利样本（样本未变化时复用 sigResult）
+	sigSamples []float64
+	sigResult  significance.Result
+}
+
+// buildPipelines 按基础策略与配置的变体创建链路实例
+// 返回顺序：基础策略（okx、binance），随后每个变体（okx、binance）。
+func buildPipelines(cfg *config.Config) []*leaderPipeline {
+	newPair := func(variant string, strategy config.StrategyConfig, paperCfg config.PaperConfig) []*leaderPipeline {
+		out := make([]*leaderPipeline, 0, 2)
+		for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+			exec := paper.NewExecutor(leader, paperCfg, cfg.Fees.Bittap)
+			if cfg.Fees.BorrowRateAnnual > 0 {
+				exec.SetBorrowCost(cfg.Fees.BorrowCostBps)
+			}
+			out = append(out, &leaderPipeline{
+				variant:      variant,
+				leader:       leader,
+				engine:       sigengine.NewEngine(leader, strategy),
+				exec:         exec,
+				ev:           ev.NewCalculatorFromConfig(cfg.EV),
+				equity:       equity.NewCurve(),
+				hourly:       ev.NewHourOfDay(),
+				evFlipExit:   paperCfg.EVFlipExit,
+				leaderFeeBps: cfg.Fees.LeaderRoundTripFeeBps(leader),
+			})
+		}
+		return out
+	}
+
+	pipelines := newPair("", cfg.Strategy, cfg.Paper)
+	for _, v := range cfg.Variants {
+		pipelines = append(pipelines, newPair(v.Name, cfg.VariantStrategy(v), cfg.VariantPaper(v))...)
+	}
+	return pipelines
+}
+
+// aggregator 聚合器（单 goroutine）
+// 唯一写入 book store 与各链路状态机，避免锁与竞态。
+type aggregator struct {
+	logger     *zap.Logger
+	bookStore  *store.Store
+	latTracker *latency.Tracker
+	pipelines  []*leaderPipeline
+	// pipeTimer 本进程管线各阶段耗时（仅本 goroutine 访问）
+	pipeTimer *pipeline.Tracker
+
+	okxClient     *okx.Client
+	binanceClient *binance.Client
+	bittapClient  *bittap.Client
+
+	// 冗余连接（可选）：备用客户端与按 Seq 去重的合并器
+	okxBackup     *okx.Client
+	binanceBackup *binance.Client
+	okxMerger     *dedup.Merger
+	binanceMerger *dedup.Merger
+
+	// errCh 合并后的各客户端连接错误（nil 表示无实时连接）
+	errCh <-chan error
+	// lastErrors 各交易所最近一次连接错误（写入指标快照）
+	lastErrors map[string]*connerr.Last
+
+	// 输出流（每个流可同时写入多个 sink，nil 表示不输出）
+	signalsWriter sink.Sink
+	paperWriter   sink.Sink
+	metricsWriter sink.Sink
+	// signalsBBOOnly 信号输出不含深度档位（output.signals_bbo_only）
+	signalsBBOOnly bool
+	// booksWriter 订单簿事件录制（可选，供回测使用）
+	booksWriter sink.Sink
+	// alertsWriter 告警事件（可选，如时延尖峰）
+	alertsWriter sink.Sink
+	// positionsWriter 未平仓仓位心跳（可选，随指标周期输出）
+	positionsWriter sink.Sink
+	// spreadsWriter 价差采样序列（可选，与 spreadSampler 同时设置）
+	spreadsWriter sink.Sink
+	// spreadSampler 按间隔采样两条链路的价差（nil 表示不输出）
+	spreadSampler *spreadsample.Sampler
+	// latencySamplesWriter 原始时延样本（可选，与 latencySampler 同时设置）
+	latencySamplesWriter sink.Sink
+	// latencySampler 时延观测抽样（nil 表示不输出）
+	latencySampler *latencysample.Sampler
+	// stalenessWriter 报价陈旧度分布（可选，与 stalenessBuilder 同时设置）
+	stalenessWriter sink.Sink
+	// stalenessBuilder 陈旧度 × Leader 变动分布构建器（nil 表示不输出）
+	stalenessBuilder *staleness.Builder
+	// barsWriter 中间价与价差 K 线（可选，与 barBuilder 同时设置）
+	barsWriter sink.Sink
+	// barBuilder K 线构建器（nil 表示不输出）
+	barBuilder *bars.Builder
+	// rawWriters 原始帧采样录制（可选，每交易所一个）
+	rawWriters []*jsonl.Writer
+
+	metricsIntervalMs int
+	// spikeCheckIntervalMs 时延尖峰检测间隔（0 表示不检测）
+	spikeCheckIntervalMs int
+
+	// leadlag 收益率互相关采样器（nil 表示不启用）
+	leadlag *leadlag.Estimator
+	// leadlagWriter 互相关结果输出
+	leadlagWriter sink.Sink
+	// leadlagIntervalMs 互相关计算间隔
+	leadlagIntervalMs int
+	// leadlagBusy 上一轮互相关计算尚未完成
+	leadlagBusy atomic.Bool
+
+	// infoShare 信息份额采样器（nil 表示不启用）
+	infoShare *infoshare.Estimator
+	// infoShareIntervalMs 信息份额估计间隔
+	infoShareIntervalMs int
+	// infoShareBusy 上一轮信息份额估计尚未完成
+	infoShareBusy atomic.Bool
+	// infoShareResults 最近一轮估计结果（估计 goroutine 写入，指标快照读取）
+	infoShareResults atomic.Pointer[[]infoshare.Result]
+
+	// checkpointSaver 运行状态检查点写入器（nil 表示不启用）
+	checkpointSaver *checkpoint.Saver
+	// checkpointIntervalMs 检查点保存间隔
+	checkpointIntervalMs int
+
+	// hotMode 低时延热模式：独占 OS 线程忙轮询输入通道（app.hot_mode）
+	hotMode bool
+	// poolEvents 处理完的订单簿事件归还对象池（app.pool_events）
+	poolEvents bool
+
+	// clock 业务时钟（nil 为系统时钟；回放时为虚拟时钟）
+	// 注意：pipeTimer 测量本进程处理耗时，始终使用墙钟。
+	clock timeutil.Clock
+
+	// counts 聚合器侧统计 updates_per_sec（按交易所/交易对）
+	counts     map[rateKey]int64
+	lastCounts map[rateKey]int64
+	// lastMetricsAt 上次输出指标的时间（纳秒，业务时钟）
+	lastMetricsAt int64
+	// lagP50Ms 各 Leader 的到达时延 P50（写入信号并作为 latency_fill 延迟，按 paper.FillDelayRefreshNs 刷新，避免每个信号排序窗口）
+	lagP50Ms map[string]float64
+	// lagRefreshedAt 上次刷新 lagP50Ms 的业务时间（纳秒）
+	lagRefreshedAt int64
+	// degraded 按交易所统计 REST 轮询降级行情事件数（累计）
+	degraded map[string]int64
+	// quoteSpread Bittap 报价价差跟踪（nil 表示 slippage_mode 非 spread）
+	quoteSpread *quotespread.Tracker
+	// volTracker Leader 中间价 realized vol 跟踪（nil 表示未启用 paper.vol_scale_ref）
+	volTracker *volatility.Tracker
+	// calendar 禁止开仓时段（nil 表示未配置）
+	calendar *blackout.Calendar
+	// blackoutName 当前所处的禁止开仓时段（空串表示不在时段内）
+	blackoutName string
+	// blackoutSuppressed 基础策略在禁止开仓时段内被抑制的信号数（按 Leader，累计）
+	blackoutSuppressed map[string]int64
+	// statusMonitor 交易所维护公告（nil 表示未启用）
+	statusMonitor *status.Monitor
+	// maintenance 当前处于维护中的交易所及公告标题（每秒按事件时间刷新）
+	maintenance map[string]string
+	// maintenanceCheckedNs 上次刷新维护状态的时间
+	maintenanceCheckedNs int64
+	// maintenanceSkipped 基础策略因 Leader 维护跳过的信号评估次数（按 Leader，累计）
+	maintenanceSkipped map[string]int64
+	// feedGuard Follower 行情降级保护配置（Enabled=false 表示未启用）
+	feedGuard config.FeedGuardConfig
+	// feedDegraded Bittap 行情当前是否处于降级状态（每秒按事件时间刷新）
+	feedDegraded bool
+	// feedCheckedNs 上次检查 Bittap 行情状态的时间
+	feedCheckedNs int64
+	// feedPauses 进入降级状态的次数（累计）
+	feedPauses int64
+	// chaos 按交易所的故障注入器（测试模式；未启用时为空）
+	chaos map[string]*chaos.Injector
+}
+
+// now 获取业务时钟当前时间（纳秒）
+func (a *aggregator) now() int64 {
+	if a.clock == nil {
+		return timeutil.NowNano()
+	}
+	return a.clock.NowNano()
+}
+
+// useSpreadSlippage 以各交易对 Bittap 滚动中位报价价差的一半作为影子成交滑点
+func (a *aggregator) useSpreadSlippage(windowSize int) {
+	a.quoteSpread = quotespread.New(windowSize)
+	for _, p := range a.pipelines {
+		p.exec.SetSlippageSource(a.quoteSpread.HalfSpreadBps)
+	}
+}
+
+// useSpreadSampling 按间隔输出两条链路的价差样本到 spreads 流
+func (a *aggregator) useSpreadSampling(w sink.Sink, intervalMs int) {
+	a.spreadsWriter = w
+	a.spreadSampler = spreadsample.New(intervalMs)
+}
+
+// useLatencySampling 每条链路每 every 次时延观测输出一条原始样本到 latency_samples 流
+func (a *aggregator) useLatencySampling(w sink.Sink, every int) {
+	a.latencySamplesWriter = w
+	a.latencySampler = latencysample.New(every)
+}
+
+// useStaleness 按周期统计 Follower 陈旧度 × Leader 变动分布并输出到 staleness 流
+func (a *aggregator) useStaleness(w sink.Sink, intervalMs int) {
+	a.stalenessWriter = w
+	a.stalenessBuilder = staleness.New(intervalMs, func(hm *staleness.Heatmap) { _ = w.Write(hm) })
+}
+
+// flushStaleness 写出所有未完成周期的陈旧度分布（停机或回放结束时调用）
+func (a *aggregator) flushStaleness() {
+	if a.stalenessBuilder != nil {
+		a.stalenessBuilder.Flush()
+	}
+}
+
+// useBars 按周期构建中间价与价差 K 线并输出到 bars 流
+func (a *aggregator) useBars(w sink.Sink, intervalsMs []int) {
+	a.barsWriter = w
+	a.barBuilder = bars.New(intervalsMs, func(bar *bars.Bar) { _ = w.Write(bar) })
+}
+
+// flushBars 写出所有未完成的 K 线（停机或回放结束时调用）
+func (a *aggregator) flushBars() {
+	if a.barBuilder != nil {
+		a.barBuilder.Flush()
+	}
+}
+
+// useVolScaling 以各 Leader 的 realized vol 缩放影子成交退出参数
+func (a *aggregator) useVolScaling(windowMs int) {
+	a.volTracker = volatility.New(windowMs)
+	for _, p := range a.pipelines {
+		leader := p.leader
+		p.exec.SetVolSource(func(symbolCanon string) (float64, bool) {
+			return a.volTracker.RealizedVol(leader, symbolCanon)
+		})
+	}
+}
+
+// useBlackout 启用禁止开仓时段：时段内信号不开仓，进入时段时平掉全部影子仓位
+func (a *aggregator) useBlackout(cal *blackout.Calendar) {
+	a.calendar = cal
+	a.blackoutSuppressed = make(map[string]int64, 2)
+	for _, p := range a.pipelines {
+		p.engine.SetCalendar(cal)
+	}
+}
+
+// useExchangeStatus 启用交易所维护公告：维护期间暂停以该交易所为 Leader 的信号生成
+func (a *aggregator) useExchangeStatus(m *status.Monitor) {
+	a.statusMonitor = m
+	a.maintenance = make(map[string]string, 2)
+	a.maintenanceSkipped = make(map[string]int64, 2)
+}
+
+// checkMaintenance 每秒刷新一次各 Leader 的维护状态，状态变化时记录日志
+func (a *aggregator) checkMaintenance(nowNs int64) {
+	if nowNs-a.maintenanceCheckedNs < 1_000_000_000 {
+		return
+	}
+	a.maintenanceCheckedNs = nowNs
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		n, paused := a.statusMonitor.Paused(leader, nowNs)
+		prev, wasPaused := a.maintenance[leader]
+		switch {
+		case paused && !wasPaused:
+			a.logger.Warn("交易所维护中，暂停信号生成", zap.String("exchange", leader), zap.String("title", n.Title))
+			a.maintenance[leader] = n.Title
+		case !paused && wasPaused:
+			a.logger.Info("交易所维护结束，恢复信号生成", zap.String("exchange", leader), zap.String("title", prev))
+			delete(a.maintenance, leader)
+		case paused:
+			a.maintenance[leader] = n.Title
+		}
+	}
+}
+
+// useFeedGuard 启用 Follower 行情降级保护（需要实时 Bittap 客户端）
+func (a *aggregator) useFeedGuard(cfg config.FeedGuardConfig) {
+	a.feedGuard = cfg
+}
+
+// checkFeed 每秒检查一次 Bittap 连接状态：断线重连中或最后消息过久时暂停新信号
+// 进入降级状态时按配置以最后已知报价平掉全部影子仓位。
+func (a *aggregator) checkFeed(nowNs int64) {
+	if nowNs-a.feedCheckedNs < 1_000_000_000 {
+		return
+	}
+	a.feedCheckedNs = nowNs
+	m := a.bittapClient.Metrics()
+	degraded := m.Reconnecting || m.LastMessageAgeMs > int64(a.feedGuard.MaxMessageAgeMs)
+	if degraded == a.feedDegraded {
+		return
+	}
+	a.feedDegraded = degraded
+	if !degraded {
+		a.logger.Info("Bittap 行情恢复，继续生成信号")
+		return
+	}
+	a.feedPauses++
+	fields := []zap.Field{zap.Bool("reconnecting", m.Reconnecting), zap.Int64("last_message_age_ms", m.LastMessageAgeMs)}
+	if a.feedGuard.Flatten {
+		fields = append(fields, zap.Int("flattened", a.closeOpenPositions(model.ExitFeedDegraded)))
+	}
+	a.logger.Warn("Bittap 行情降级，暂停生成信号", fields...)
+}
+
+// checkBlackout 按事件时间更新禁止开仓时段状态，进入时段时平掉全部影子仓位
+func (a *aggregator) checkBlackout(nowNs int64) {
+	name, active := a.calendar.Active(nowNs)
+	if active && a.blackoutName == "" {
+		n := a.closeOpenPositions(model.ExitBlackout)
+		a.logger.Info("进入禁止开仓时段", zap.String("window", name), zap.Int("flattened", n))
+	} else if !active && a.blackoutName != "" {
+		a.logger.Info("禁止开仓时段结束", zap.String("window", a.blackoutName))
+	}
+	a.blackoutName = name
+}
+
+// useChaos 启用故障注入测试模式（按配置的交易所创建注入器）
+func (a *aggregator) useChaos(cfg config.ChaosConfig) {
+	a.chaos = make(map[string]*chaos.Injector, 3)
+	for _, ex := range []string{model.ExchangeOKX, model.ExchangeBinance, model.ExchangeBittap} {
+		if chaos.Applies(cfg, ex) {
+			a.chaos[ex] = chaos.New(ex, cfg)
+		}
+	}
+}
+
+// wrapChaos 为交易所事件通道接入故障注入（未启用时原样返回）
+func (a *aggregator) wrapChaos(ctx context.Context, exchange string, in <-chan *model.BookEvent, errChs []<-chan error) (<-chan *model.BookEvent, []<-chan error) {
+	inj := a.chaos[exchange]
+	if inj == nil {
+		return in, errChs
+	}
+	out, errs := inj.Wrap(ctx, in)
+	return out, append(errChs, errs)
+}
+
+// handleReplayEvent 处理回放事件（启用故障注入时先经注入器）
+func (a *aggregator) handleReplayEvent(ev *model.BookEvent) {
+	if ev != nil {
+		if inj := a.chaos[ev.Exchange]; inj != nil {
+			inj.Apply(ev, a.handleBookEvent, a.handleClientError)
+			return
+		}
+	}
+	a.handleBookEvent(ev)
+}
+
+// resetCounters 初始化更新速率统计
+func (a *aggregator) resetCounters() {
+	a.counts = make(map[rateKey]int64)
+	a.lastCounts = make(map[rateKey]int64)
+	a.lastMetricsAt = a.now()
+}
+
+// run 聚合器主循环，直到 ctx 取消或所有输入通道关闭
+func (a *aggregator) run(ctx context.Context) error {
+	okxCh := a.okxClient.BookCh()
+	if a.okxMerger != nil {
+		okxCh = a.okxMerger.Out()
+	}
+	binanceCh := a.binanceClient.BookCh()
+	if a.binanceMerger != nil {
+		binanceCh = a.binanceMerger.Out()
+	}
+	bittapCh := a.bittapClient.BookCh()
+	errCh := a.errCh
+	if len(a.chaos) > 0 {
+		var errChs []<-chan error
+		if errCh != nil {
+			errChs = append(errChs, errCh)
+		}
+		okxCh, errChs = a.wrapChaos(ctx, model.ExchangeOKX, okxCh, errChs)
+		binanceCh, errChs = a.wrapChaos(ctx, model.ExchangeBinance, binanceCh, errChs)
+		bittapCh, errChs = a.wrapChaos(ctx, model.ExchangeBittap, bittapCh, errChs)
+		errCh = connerr.Merge(ctx, errChs...)
+	}
+
+	if a.metricsIntervalMs <= 0 {
+		a.metricsIntervalMs = 10000
+	}
+	if a.hotMode {
+		return a.runHot(ctx, okxCh, binanceCh, bittapCh, errCh)
+	}
+	metricsTicker := time.NewTicker(time.Duration(a.metricsIntervalMs) * time.Millisecond)
+	defer metricsTicker.Stop()
+
+	var spikeCh <-chan time.Time
+	if a.spikeCheckIntervalMs > 0 {
+		spikeTicker := time.NewTicker(time.Duration(a.spikeCheckIntervalMs) * time.Millisecond)
+		defer spikeTicker.Stop()
+		spikeCh = spikeTicker.C
+	}
+
+	var leadlagCh <-chan time.Time
+	if a.leadlag != nil && a.leadlagIntervalMs > 0 {
+		leadlagTicker := time.NewTicker(time.Duration(a.leadlagIntervalMs) * time.Millisecond)
+		defer leadlagTicker.Stop()
+		leadlagCh = leadlagTicker.C
+	}
+
+	var infoShareCh <-chan time.Time
+	if a.infoShare != nil && a.infoShareIntervalMs > 0 {
+		infoShareTicker := time.NewTicker(time.Duration(a.infoShareIntervalMs) * time.Millisecond)
+		defer infoShareTicker.Stop()
+		infoShareCh = infoShareTicker.C
+	}
+
+	var checkpointCh <-chan time.Time
+	if a.checkpointSaver != nil && a.checkpointIntervalMs > 0 {
+		checkpointTicker := time.NewTicker(time.Duration(a.checkpointIntervalMs) * time.Millisecond)
+		defer checkpointTicker.Stop()
+		checkpointCh = checkpointTicker.C
+	}
+
+	a.resetCounters()
+
+	for {
+		// Follower 更新是决策的关键路径：每轮先处理已到达的 Bittap 事件，再参与公平 select
+		if bittapCh != nil && !a.drainFollower(bittapCh) {
+			bittapCh = nil
+			if okxCh == nil && binanceCh == nil {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev, ok := <-okxCh:
+			if !ok {
+				okxCh = nil
+				continue
+			}
+			a.handleBookEvent(ev)
+
+		case ev, ok := <-binanceCh:
+			if !ok {
+				binanceCh = nil
+				continue
+			}
+			a.handleBookEvent(ev)
+
+		case ev, ok := <-bittapCh:
+			if !ok {
+				bittapCh = nil
+				continue
+			}
+			a.handleBookEvent(ev)
+
+		case err, ok := <-errCh:
+			if !ok {
+				errCh = nil
+				continue
+			}
+			a.handleClientError(err)
+
+		case <-spikeCh:
+			a.checkLatencySpikes()
+
+		case <-metricsTicker.C:
+			a.writeMetrics()
+
+		case <-leadlagCh:
+			a.emitLeadLag()
+
+		case <-infoShareCh:
+			a.estimateInfoShare()
+
+		case <-checkpointCh:
+			a.checkpointSaver.Submit(a.checkpointState())
+		}
+
+		if okxCh == nil && binanceCh == nil && bittapCh == nil {
+			return nil
+		}
+	}
+}
+
+// hotTask 热模式下按到期时间执行的定时任务
+type hotTask struct {
+	intervalNs int64
+	nextNs     int64
+	fn         func()
+}
+
+// hotCheckEvery 热模式持续有事件时，每轮询多少轮检查一次定时任务（避免定时任务被长期饿死）
+const hotCheckEvery = 1024
+
+// runHot 低时延热模式主循环：锁定 OS 线程，非阻塞地忙轮询各输入通道
+// 定时任务（指标、尖峰检测、lead-lag、信息份额、检查点）不参与 select，仅在一轮轮询无事件（或每 hotCheckEvery 轮）时
+// 按到期时间执行，待处理行情不会因定时任务排队。该模式持续占满一个 CPU 核，需 GOMAXPROCS >= 2。
+func (a *aggregator) runHot(ctx context.Context, okxCh, binanceCh, bittapCh <-chan *model.BookEvent, errCh <-chan error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var tasks []*hotTask
+	addTask := func(intervalMs int, fn func()) {
+		if intervalMs > 0 {
+			ns := int64(intervalMs) * int64(time.Millisecond)
+			tasks = append(tasks, &hotTask{intervalNs: ns, nextNs: time.Now().UnixNano() + ns, fn: fn})
+		}
+	}
+	addTask(a.metricsIntervalMs, a.writeMetrics)
+	addTask(a.spikeCheckIntervalMs, a.checkLatencySpikes)
+	if a.leadlag != nil {
+		addTask(a.leadlagIntervalMs, a.emitLeadLag)
+	}
+	if a.infoShare != nil {
+		addTask(a.infoShareIntervalMs, a.estimateInfoShare)
+	}
+	if a.checkpointSaver != nil {
+		addTask(a.checkpointIntervalMs, func() { a.checkpointSaver.Submit(a.checkpointState()) })
+	}
+
+	// poll 非阻塞读取一个事件；通道关闭时置 nil
+	poll := func(ch *<-chan *model.BookEvent) bool {
+		if *ch == nil {
+			return false
+		}
+		select {
+		case ev, ok := <-*ch:
+			if !ok {
+				*ch = nil
+				return false
+			}
+			a.handleBookEvent(ev)
+			return true
+		default:
+			return false
+		}
+	}
+
+	a.resetCounters()
+
+	for spins := 1; ; spins++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Follower 优先（同 run）
+		busy := false
+		for i := 0; i < followerDrainMax && poll(&bittapCh); i++ {
+			busy = true
+		}
+		if poll(&okxCh) {
+			busy = true
+		}
+		if poll(&binanceCh) {
+			busy = true
+		}
+		if errCh != nil {
+			select {
+			case err, ok := <-errCh:
+				if !ok {
+					errCh = nil
+				} else {
+					a.handleClientError(err)
+				}
+			default:
+			}
+		}
+		if okxCh == nil && binanceCh == nil && bittapCh == nil {
+			return nil
+		}
+
+		if busy && spins%hotCheckEvery != 0 {
+			continue
+		}
+		nowNs := time.Now().UnixNano()
+		for _, t := range tasks {
+			if nowNs >= t.nextNs {
+				t.fn()
+				t.nextNs = nowNs + t.intervalNs
+			}
+		}
+	}
+}
+
+// followerDrainMax 每轮主循环优先处理的 Bittap 事件上限（避免 Follower 洪峰饿死 Leader 与定时任务）
+const followerDrainMax = 64
+
+// drainFollower 非阻塞地处理已排队的 Bittap 事件（至多 followerDrainMax 条）
+// 返回: 通道是否仍然打开
+func (a *aggregator) drainFollower(ch <-chan *model.BookEvent) bool {
+	for i := 0; i < followerDrainMax; i++ {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return false
+			}
+			a.handleBookEvent(ev)
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+// handleClientError 记录客户端上报的连接错误（按交易所保留最近一次）
+func (a *aggregator) handleClientError(err error) {
+	var ce *connerr.Error
+	if !errors.As(err, &ce) {
+		ce = connerr.New("unknown", "", err)
+	}
+	if a.lastErrors == nil {
+		a.lastErrors = make(map[string]*connerr.Last, 3)
+	}
+	last := a.lastErrors[ce.Exchange]
+	if last == nil {
+		last = &connerr.Last{}
+		a.lastErrors[ce.Exchange] = last
+	}
+	last.Category = ce.Category
+	last.Error = ce.Err.Error()
+	last.AtUnixNs = ce.AtUnixNs
+	last.Total++
+	a.logger.Debug("客户端连接错误", zap.String("exchange", ce.Exchange), zap.String("category", ce.Category), zap.Error(ce.Err))
+}
+
+// writeMetrics 输出一条指标快照并 flush 各输出文件
+func (a *aggregator) writeMetrics() {
+	if a.metricsWriter == nil {
+		return
+	}
+
+	nowNs := a.now()
+	elapsedSec := float64(nowNs-a.lastMetricsAt) / 1e9
+	if elapsedSec <= 0 {
+		elapsedSec = float64(a.metricsIntervalMs) / 1000
+	}
+
+	var rates []updateRate
+	for k, v := range a.counts {
+		prev := a.lastCounts[k]
+		qps := float64(v-prev) / elapsedSec
+		rates = append(rates, updateRate{Exchange: k.ex, SymbolCanon: k.sym, UpdatesPerSec: qps})
+		a.lastCounts[k] = v
+	}
+	a.lastMetricsAt = nowNs
+
+	snap := a.snapshot(nowNs, rates)
+	_ = a.metricsWriter.Write(snap)
+	_ = a.metricsWriter.Flush()
+	if a.positionsWriter != nil {
+		for i := range snap.OpenPositions {
+			_ = a.positionsWriter.Write(&snap.OpenPositions[i])
+		}
+		_ = a.positionsWriter.Flush()
+	}
+	// 同时 flush signals 和 paper_trades，确保数据落盘
+	if a.signalsWriter != nil {
+		_ = a.signalsWriter.Flush()
+	}
+	if a.paperWriter != nil {
+		_ = a.paperWriter.Flush()
+	}
+	if a.booksWriter != nil {
+		_ = a.booksWriter.Flush()
+	}
+	if a.alertsWriter != nil {
+		_ = a.alertsWriter.Flush()
+	}
+	if a.spreadsWriter != nil {
+		_ = a.spreadsWriter.Flush()
+	}
+	if a.barsWriter != nil {
+		_ = a.barsWriter.Flush()
+	}
+	if a.latencySamplesWriter != nil {
+		_ = a.latencySamplesWriter.Flush()
+	}
+	if a.stalenessWriter != nil {
+		_ = a.stalenessWriter.Flush()
+	}
+	for _, w := range a.rawWriters {
+		_ = w.Flush()
+	}
+}
+
+// outputReporters 返回所有已启用输出目标中可报告写入统计的 sink
+func (a *aggregator) outputReporters() []sink.Reporter {
+	var rs []sink.Reporter
+	for _, s := range []sink.Sink{a.signalsWriter, a.paperWriter, a.metricsWriter, a.booksWriter, a.alertsWriter, a.positionsWriter, a.leadlagWriter, a.spreadsWriter, a.barsWriter, a.latencySamplesWriter, a.stalenessWriter} {
+		rs = append(rs, sink.Reporters(s)...)
+	}
+	for _, w := range a.rawWriters {
+		rs = append(rs, w)
+	}
+	return rs
+}
+
+// checkLatencySpikes 检测时延尖峰并输出告警事件
+func (a *aggregator) checkLatencySpikes() {
+	for _, spike := range a.latTracker.CheckSpikes(a.now()) {
+		a.logger.Warn("检测到时延尖峰",
+			zap.String("leader", spike.Leader),
+			zap.Float64("recent_p90_ms", spike.RecentP90Ms),
+			zap.Float64("baseline_p90_ms", spike.BaselineP90Ms))
+		if a.alertsWriter != nil {
+			_ = a.alertsWriter.Write(spike)
+		}
+	}
+}
+
+// emitLeadLag 导出互相关采样窗口，并在独立 goroutine 中计算与输出
+// 计算量为 窗口桶数 × 滞后数 × 交易对数，不在聚合器 goroutine 中执行；上一轮未完成时跳过本轮。
+func (a *aggregator) emitLeadLag() {
+	if !a.leadlagBusy.CompareAndSwap(false, true) {
+		return
+	}
+	pairs := a.leadlag.Snapshot(a.now())
+	go func() {
+		defer a.leadlagBusy.Store(false)
+		for _, p := range pairs {
+			if a.leadlagWriter != nil {
+				_ = a.leadlagWriter.Write(p.Compute())
+			}
+		}
+	}()
+}
+
+// estimateInfoShare 导出信息份额采样窗口，并在独立 goroutine 中拟合 VECM
+// 结果保存为最近一轮估计，随下一次指标快照输出；上一轮未完成时跳过本轮。
+func (a *aggregator) estimateInfoShare() {
+	if !a.infoShareBusy.CompareAndSwap(false, true) {
+		return
+	}
+	pairs := a.infoShare.Snapshot(a.now())
+	go func() {
+		defer a.infoShareBusy.Store(false)
+		results := make([]infoshare.Result, 0, len(pairs))
+		for _, p := range pairs {
+			if res, ok := p.Compute(); ok {
+				results = append(results, res)
+			}
+		}
+		a.infoShareResults.Store(&results)
+	}()
+}
+
+// snapshot 汇总当前指标快照
+// 基础策略的 EV 写入 ev_okx/ev_binance，变体写入 variants。
+func (a *aggregator) snapshot(nowNs int64, rates []updateRate) metricsSnapshot {
+	snap := metricsSnapshot{
+		SchemaVersion:  metricsSchemaVersion,
+		TsUnixNs:       nowNs,
+		Build:          buildinfo.Get(),
+		Process:        procstats.Read(),
+		LatencyOKX:     a.latTracker.Stats(model.ExchangeOKX),
+		LatencyBinance: a.latTracker.Stats(model.ExchangeBinance),
+		LeaderLead:     a.latTracker.LeaderStats(),
+		LeadShare:      a.latTracker.LeadShare(),
+		UpdatesPerSec:  rates,
+	}
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		if hs := a.latTracker.HourlyStats(leader); len(hs) > 0 {
+			if snap.LatencyByHour == nil {
+				snap.LatencyByHour = make(map[string][]latency.HourLatencyStats, 2)
+			}
+			snap.LatencyByHour[leader] = hs
+		}
+	}
+	// 回放模式下无实时连接
+	if a.okxClient != nil {
+		snap.OKX = a.okxClient.Metrics()
+		if st, ok := a.okxClient.ChannelComparison(); ok {
+			snap.OKXChannels = &st
+		}
+	}
+	if a.binanceClient != nil {
+		snap.Binance = a.binanceClient.Metrics()
+	}
+	if a.bittapClient != nil {
+		snap.Bittap = a.bittapClient.Metrics()
+	}
+	if a.pipeTimer != nil {
+		snap.Pipeline = a.pipeTimer.Snapshot()
+	}
+	if len(a.lastErrors) > 0 {
+		snap.LastErrors = make(map[string]connerr.Last, len(a.lastErrors))
+		for ex, last := range a.lastErrors {
+			snap.LastErrors[ex] = *last
+		}
+	}
+	for _, ex := range []string{model.ExchangeOKX, model.ExchangeBinance, model.ExchangeBittap} {
+		if n := a.bookStore.StaleCount(ex); n > 0 {
+			if snap.StaleDropped == nil {
+				snap.StaleDropped = make(map[string]int64)
+			}
+			snap.StaleDropped[ex] = n
+		}
+	}
+	if a.quoteSpread != nil {
+		snap.FollowerSpreadBps = a.quoteSpread.Medians()
+	}
+	if results := a.infoShareResults.Load(); results != nil {
+		snap.InfoShare = *results
+	}
+	if len(a.chaos) > 0 {
+		snap.Chaos = make(map[string]chaos.Stats, len(a.chaos))
+		for ex, inj := range a.chaos {
+			snap.Chaos[ex] = inj.Stats()
+		}
+	}
+	snap.OpenPositions = a.openPositionHeartbeats(nowNs)
+	for _, w := range a.outputReporters() {
+		st := w.Stats()
+		if st.MarshalErrors == 0 && st.WriteErrors == 0 {
+			continue
+		}
+		if snap.OutputErrors == nil {
+			snap.OutputErrors = make(map[string]jsonl.Stats)
+		}
+		snap.OutputErrors[filepath.Base(w.Path())] = st
+	}
+	if len(a.blackoutSuppressed) > 0 {
+		snap.BlackoutSuppressed = make(map[string]int64, len(a.blackoutSuppressed))
+		for leader, n := range a.blackoutSuppressed {
+			snap.BlackoutSuppressed[leader] = n
+		}
+	}
+	snap.BlackoutActive = a.blackoutName
+	if len(a.maintenance) > 0 {
+		snap.Maintenance = make(map[string]string, len(a.maintenance))
+		for ex, title := range a.maintenance {
+			snap.Maintenance[ex] = title
+		}
+	}
+	snap.FeedDegraded = a.feedDegraded
+	snap.FeedPauses = a.feedPauses
+	if len(a.maintenanceSkipped) > 0 {
+		snap.MaintenanceSkipped = make(map[string]int64, len(a.maintenanceSkipped))
+		for leader, n := range a.maintenanceSkipped {
+			snap.MaintenanceSkipped[leader] = n
+		}
+	}
+	if len(a.degraded) > 0 {
+		snap.DegradedEvents = make(map[string]int64, len(a.degraded))
+		for ex, n := range a.degraded {
+			snap.DegradedEvents[ex] = n
+		}
+	}
+	if a.okxBackup != nil {
+		m := a.okxBackup.Metrics()
+		snap.OKXBackup = &m
+	}
+	if a.binanceBackup != nil {
+		m := a.binanceBackup.Metrics()
+		snap.BinanceBackup = &m
+	}
+	if a.okxMerger != nil {
+		st := a.okxMerger.Stats()
+		snap.DedupOKX = &st
+	}
+	if a.binanceMerger != nil {
+		st := a.binanceMerger.Stats()
+		snap.DedupBinance = &st
+	}
+
+	variantIdx := make(map[string]int)
+	for _, p := range a.pipelines {
+		p.ev.Expire(nowNs)
+		evStats := p.ev.Stats()
+		if p.variant == "" {
+			switch p.leader {
+			case model.ExchangeOKX:
+				snap.EVOKX = evStats
+				snap.EquityOKX = p.equity.Stats()
+			case model.ExchangeBinance:
+				snap.EVBinance = evStats
+				snap.EquityBinance = p.equity.Stats()
+			}
+			if evStats.Count > 0 {
+				if snap.EVSignificance == nil {
+					snap.EVSignificance = make(map[string]significance.Result, 2)
+				}
+				snap.EVSignificance[p.leader] = evSignificance(p)
+			}
+			if n := p.exec.PhantomFills(); n > 0 {
+				if snap.PhantomFills == nil {
+					snap.PhantomFills = make(map[string]int64, 2)
+				}
+				snap.PhantomFills[p.leader] = n
+			}
+			if hs := p.hourly.Stats(); len(hs) > 0 {
+				if snap.EVByHour == nil {
+					snap.EVByHour = make(map[string][]ev.HourEVStats, 2)
+				}
+				snap.EVByHour[p.leader] = hs
+			}
+			continue
+		}
+
+		idx, ok := variantIdx[p.variant]
+		if !ok {
+			idx = len(snap.Variants)
+			variantIdx[p.variant] = idx
+			snap.Variants = append(snap.Variants, variantMetrics{Name: p.variant})
+		}
+		switch p.leader {
+		case model.ExchangeOKX:
+			snap.Variants[idx].EVOKX = evStats
+			snap.Variants[idx].EquityOKX = p.equity.Stats()
+		case model.ExchangeBinance:
+			snap.Variants[idx].EVBinance = evStats
+			snap.Variants[idx].EquityBinance = p.equity.Stats()
+		}
+		if evStats.Count > 0 {
+			if snap.Variants[idx].EVSignificance == nil {
+				snap.Variants[idx].EVSignificance = make(map[string]significance.Result, 2)
+			}
+			snap.Variants[idx].EVSignificance[p.leader] = evSignificance(p)
+		}
+		if n := p.exec.PhantomFills(); n > 0 {
+			if snap.Variants[idx].PhantomFills == nil {
+				snap.Variants[idx].PhantomFills = make(map[string]int64, 2)
+			}
+			snap.Variants[idx].PhantomFills[p.leader] = n
+		}
+	}
+	return snap
+}
+
+// sampleSpreads 事件所影响的链路到达采样间隔时写出价差样本
+// Leader 事件只影响自身链路，Follower 事件影响两条链路。
+func (a *aggregator) sampleSpreads(ev *model.BookEvent) {
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		if ev.Exchange != leader && ev.Exchange != model.ExchangeBittap {
+			continue
+		}
+		leaderBook, followerBook := a.bookStore.GetPair(leader, ev.SymbolCanon)
+		if sample, ok := a.spreadSampler.Sample(ev.ArrivedAtUnixNs, leader, leaderBook, followerBook); ok {
+			_ = a.spreadsWriter.Write(sample)
+		}
+	}
+}
+
+// observeStaleness 以事件更新受影响链路的陈旧度分布
+// Leader 事件只影响自身链路，Follower 事件影响两条链路。
+func (a *aggregator) observeStaleness(ev *model.BookEvent) {
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		if ev.Exchange != leader && ev.Exchange != model.ExchangeBittap {
+			continue
+		}
+		leaderBook, followerBook := a.bookStore.GetPair(leader, ev.SymbolCanon)
+		a.stalenessBuilder.Observe(leader, ev, leaderBook, followerBook)
+	}
+}
+
+// observeBars 以事件更新该交易所中间价 K 线及受影响链路的价差 K 线
+func (a *aggregator) observeBars(ev *model.BookEvent) {
+	if !ev.IsValid() {
+		return
+	}
+	a.barBuilder.Observe(ev.ArrivedAtUnixNs, bars.SeriesMid, ev.Exchange, ev.SymbolCanon, ev.MidPrice())
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		if ev.Exchange != leader && ev.Exchange != model.ExchangeBittap {
+			continue
+		}
+		leaderBook, followerBook := a.bookStore.GetPair(leader, ev.SymbolCanon)
+		if leaderBook == nil || followerBook == nil || !leaderBook.IsValid() || !followerBook.IsValid() {
+			continue
+		}
+		followerMid := followerBook.MidPrice()
+		spreadBps := (leaderBook.MidPrice() - followerMid) / followerMid * 10000
+		a.barBuilder.Observe(ev.ArrivedAtUnixNs, bars.SeriesSpread, leader, ev.SymbolCanon, spreadBps)
+	}
+}
+
+// evSignificance 对链路滚动窗口内的每笔净利做 EV > 0 显著性检验
+// bootstrap 使用固定种子，同一份回放数据的输出可复现；
+// 窗口样本与上次相同时（无新成交、无过期）直接返回缓存结果，避免每个指标周期重复重抽样。
+func evSignificance(p *leaderPipeline) significance.Result {
+	samples := p.ev.NetPnLSamples()
+	if p.sigSamples != nil && slices.Equal(samples, p.sigSamples) {
+		return p.sigResult
+	}
+	p.sigSamples = samples
+	p.sigResult = significance.Test(samples, significance.DefaultBootstrapIterations, 1)
+	return p.sigResult
+}
+
+func (a *aggregator) handleBookEvent(ev *model.BookEvent) {
+	if ev == nil || ev.Exchange == "" || ev.SymbolCanon == "" {
+		ev.Release()
+		return
+	}
+	if a.poolEvents {
+		// 最后执行（晚于 pipeTimer 统计）：未进入 store 的事件已无人引用
+		defer a.releaseRejected(ev)
+	}
+	if a.pipeTimer != nil {
+		dequeuedNs := timeutil.NowNano()
+		defer func() { a.pipeTimer.Observe(ev, dequeuedNs, timeutil.NowNano()) }()
+	}
+	// 重复/乱序事件（Seq 不大于已缓存值）不参与统计与策略评估
+	if !a.bookStore.Update(ev) {
+		return
+	}
+	a.counts[rateKey{ex: ev.Exchange, sym: ev.SymbolCanon}]++
+	if a.quoteSpread != nil {
+		a.quoteSpread.Observe(ev)
+	}
+	if a.volTracker != nil {
+		a.volTracker.Observe(ev)
+	}
+
+	// 录制按到达顺序写出（BookEvent 入 store 后不再修改，可安全异步编码；
+	// 启用对象池时事件被替换后即归还，须写出副本）
+	if a.booksWriter != nil {
+		if a.poolEvents {
+			_ = a.booksWriter.Write(ev.Clone())
+		} else {
+			_ = a.booksWriter.Write(ev)
+		}
+	}
+
+	// REST 轮询的降级行情到达时间受轮询间隔支配：暂停时延统计，只更新盘口供价差监控
+	if ev.Degraded {
+		if a.degraded == nil {
+			a.degraded = make(map[string]int64)
+		}
+		a.degraded[ev.Exchange]++
+	} else {
+		a.observeLatency(ev)
+	}
+
+	if a.spreadSampler != nil {
+		a.sampleSpreads(ev)
+	}
+	if a.barBuilder != nil {
+		a.observeBars(ev)
+	}
+	if a.stalenessBuilder != nil {
+		a.observeStaleness(ev)
+	}
+
+	if a.calendar != nil {
+		a.checkBlackout(ev.ArrivedAtUnixNs)
+	}
+	if a.statusMonitor != nil {
+		a.checkMaintenance(ev.ArrivedAtUnixNs)
+	}
+	if a.feedGuard.Enabled && a.bittapClient != nil {
+		a.checkFeed(ev.ArrivedAtUnixNs)
+	}
+
+	// 评估与执行（各链路、各变体独立）
+	for _, p := range a.pipelines {
+		leaderBook, followerBook := a.bookStore.GetPair(p.leader, ev.SymbolCanon)
+		if leaderBook == nil || followerBook == nil {
+			continue
+		}
+		// 任一侧为降级行情、Leader 维护中或 Follower 连接降级时时延优势无法成立，不开新仓；已有仓位照常判断退出
+		_, inMaintenance := a.maintenance[p.leader]
+		if inMaintenance && p.variant == "" {
+			a.maintenanceSkipped[p.leader]++
+		}
+		if !leaderBook.Degraded && !followerBook.Degraded && !inMaintenance && !a.feedDegraded {
+			if sig := p.engine.Evaluate(ev.ArrivedAtUnixNs, leaderBook, followerBook); sig != nil {
+				a.applyEVAndMaybeOpen(p, sig)
+			} else if sig := p.engine.TakeFiltered(); sig != nil {
+				// 波动率过滤的标记信号只记录不开仓
+				a.applyEVAndMaybeOpen(p, sig)
+			}
+		}
+		for _, closed := range p.exec.Evaluate(ev.ArrivedAtUnixNs, leaderBook, followerBook) {
+			a.recordClosed(p, closed, ev.ArrivedAtUnixNs)
+		}
+		if p.evFlipExit && p.exec.HasOpen(ev.SymbolCanon) {
+			p.ev.Expire(ev.ArrivedAtUnixNs)
+			if st := p.ev.SymbolStats(ev.SymbolCanon); st.Count > 0 && st.EV < 0 {
+				for _, closed := range p.exec.CloseSymbol(ev.ArrivedAtUnixNs, ev.SymbolCanon, followerBook, model.ExitEVFlip) {
+					a.recordClosed(p, closed, ev.ArrivedAtUnixNs)
+				}
+			}
+		}
+	}
+}
+
+// usePooledEvents 启用订单簿事件对象池：store 替换/挤出的事件与被拒绝的事件归还复用
+func (a *aggregator) usePooledEvents() {
+	a.poolEvents = true
+	a.bookStore.SetOnEvict((*model.BookEvent).Release)
+}
+
+// releaseRejected 归还未被 store 接受的事件（重复/乱序）
+// 故障注入的重复投递是同一指针，已作为最新盘口缓存时不得归还。
+func (a *aggregator) releaseRejected(ev *model.BookEvent) {
+	if a.bookStore.Get(ev.Exchange, ev.SymbolCanon) != ev {
+		ev.Release()
+	}
+}
+
+// observeLatency 实时行情参与 lead-lag 采样与时延统计
+func (a *aggregator) observeLatency(ev *model.BookEvent) {
+	if a.leadlag != nil {
+		a.leadlag.Observe(ev)
+	}
+	if a.infoShare != nil {
+		a.infoShare.Observe(ev)
+	}
+
+	a.latTracker.ObserveMove(ev)
+
+	// Leader 更新参与 OKX/Binance 领先比较；Follower 更新时记录时延（使用最新 Leader 快照，降级行情除外）
+	if ev.Exchange != model.ExchangeBittap {
+		a.latTracker.AddLeader(ev)
+		return
+	}
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		if leaderBook, _ := a.bookStore.GetPair(leader, ev.SymbolCanon); leaderBook != nil && !leaderBook.Degraded {
+			a.latTracker.Add(leaderBook, ev)
+			if a.latencySampler != nil {
+				if sample, ok := a.latencySampler.Observe(leaderBook, ev); ok {
+					_ = a.latencySamplesWriter.Write(sample)
+				}
+			}
+		}
+	}
+	if ev.ArrivedAtUnixNs-a.lagRefreshedAt >= paper.FillDelayRefreshNs {
+		a.refreshLagP50(ev.ArrivedAtUnixNs)
+	}
+}
+
+// refreshLagP50 以时延窗口刷新各链路到达时延 P50，并作为后续信号的 latency_fill 模拟成交延迟
+func (a *aggregator) refreshLagP50(nowNs int64) {
+	a.lagRefreshedAt = nowNs
+	a.lagP50Ms = map[string]float64{
+		model.ExchangeOKX:     a.latTracker.ArrivedP50Ms(model.ExchangeOKX),
+		model.ExchangeBinance: a.latTracker.ArrivedP50Ms(model.ExchangeBinance),
+	}
+	for _, p := range a.pipelines {
+		p.exec.SetFillDelayNs(int64(a.lagP50Ms[p.leader] * 1e6))
+	}
+}
+
+// recordClosed 平仓后更新 EV/权益统计并输出影子成交
+func (a *aggregator) recordClosed(p *leaderPipeline, closed *model.Position, nowNs int64) {
+	p.ev.Add(closed)
+	p.equity.Add(closed)
+	p.hourly.Add(closed)
+	if closed.ExitReason == model.ExitSL {
+		p.engine.NotifyStopLoss(closed.SymbolCanon, nowNs)
+	}
+	if a.paperWriter != nil {
+		_ = a.paperWriter.Write(closed.ToPaperTrade(p.ev.Snapshot()))
+	}
+}
+
+// openPositionHeartbeats 汇总各链路的未平仓仓位心跳
+func (a *aggregator) openPositionHeartbeats(nowNs int64) []model.PositionHeartbeat {
+	var out []model.PositionHeartbeat
+	for _, p := range a.pipelines {
+		leader := p.leader
+		leaderBook := func(symbolCanon string) *model.BookEvent {
+			return a.bookStore.Get(leader, symbolCanon)
+		}
+		followerBook := func(symbolCanon string) *model.BookEvent {
+			return a.bookStore.Get(model.ExchangeBittap, symbolCanon)
+		}
+		out = append(out, p.exec.Heartbeats(nowNs, leaderBook, followerBook)...)
+	}
+	return out
+}
+
+// closeOpenPositions 以最后已知的 Follower 报价强制平掉各链路的未平仓仓位
+// 须在聚合器 goroutine 内（禁止开仓时段、行情降级）或 run 返回后调用。
+// 返回: 平仓笔数
+func (a *aggregator) closeOpenPositions(reason model.ExitReason) int {
+	nowNs := a.now()
+	followerBook := func(symbolCanon string) *model.BookEvent {
+		return a.bookStore.Get(model.ExchangeBittap, symbolCanon)
+	}
+	n := 0
+	for _, p := range a.pipelines {
+		for _, closed := range p.exec.CloseAll(nowNs, followerBook, reason) {
+			a.recordClosed(p, closed, nowNs)
+			n++
+		}
+	}
+	return n
+}
+
+func (a *aggregator) applyEVAndMaybeOpen(p *leaderPipeline, sig *model.Signal) {
+	if sig == nil {
+		return
+	}
+	if p.variant != "" {
+		sig.Variant = p.variant
+		sig.ID = p.variant + "-" + sig.ID
+	}
+
+	// 数据新鲜度：检测时刻两侧快照的年龄与链路当前时延；手续费后的理论边际
+	nowNs := a.now()
+	if sig.LeaderBook != nil {
+		sig.LeaderBookAgeMs = float64(nowNs-sig.LeaderBook.ArrivedAtUnixNs) / 1e6
+	}
+	if sig.FollowerBook != nil {
+		sig.FollowerBookAgeMs = float64(nowNs-sig.FollowerBook.ArrivedAtUnixNs) / 1e6
+	}
+	sig.LagP50Ms = a.lagP50Ms[p.leader]
+	sig.FeeBps = p.exec.RoundTripFeeBps() + p.leaderFeeBps
+	sig.NetEdgeBps = sig.SpreadBps - sig.FeeBps
+
+	// EV 拒绝：当 EV<0，标记信号但不执行影子成交
+	p.ev.Expire(sig.DetectedAtNs)
+	ev.ApplyRejection(sig, p.ev.Stats())
+
+	if a.signalsWriter != nil {
+		if a.signalsBBOOnly {
+			_ = a.signalsWriter.Write(sig.WithoutDepth())
+		} else {
+			_ = a.signalsWriter.Write(sig)
+		}
+	}
+
+	// 被过滤的信号（EV 为负、波动率超阈值、禁止开仓时段等）只记录不开仓
+	if sig.FilterReason != "" {
+		if sig.FilterReason == model.FilterReasonBlackout && p.variant == "" {
+			a.blackoutSuppressed[p.leader]++
+		}
+		return
+	}
+
+	pos, opened, err := p.exec.TryOpen(sig)
+	if err != nil {
+		a.logger.Warn("TryOpen 失败", zap.Error(err), zap.String("leader", sig.Leader), zap.String("symbol", sig.SymbolCanon), zap.String("variant", p.variant))
+		return
+	}
+	// 开仓成功后不输出 paper trade；平仓时输出
+	if opened {
+		pos.Variant = p.variant
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/output/jsonl"
+	"latency-arbitrage-validator/internal/stats/latencysample"
+	"latency-arbitrage-validator/internal/stats/pnlfactor"
+)
+
+// runAnalyze 关联影子成交、信号与时延样本，输出净利随滞后、价差、深度与时段变化的分桶统计与回归
+// 时延样本需启用 output.latency_samples_enabled；缺少 signals/latency_samples 时对应因子不参与分析。
+// 返回进程退出码。
+func runAnalyze(args []string) int {
+	fs, cf := newFlagSet("analyze")
+	dir := fs.String("dir", "", "输出目录（默认最近一次运行目录）")
+	buckets := fs.Int("buckets", 5, "每个因子按分位数分桶的桶数")
+	maxGapMs := fs.Int64("max-gap-ms", 1000, "时延样本距信号检测时刻的最大间隔（毫秒），超过视为无观测")
+	leader := fs.String("leader", "", "只分析该 Leader 的成交（okx/binance，为空表示全部）")
+	_ = fs.Parse(args)
+
+	cfg, err := cf.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+	if *dir == "" {
+		*dir = defaultRunDir(cfg)
+	}
+
+	joiner := pnlfactor.NewJoiner(*maxGapMs)
+	if err := readOptional(filepath.Join(*dir, "signals.jsonl"), func(s *model.Signal) error {
+		joiner.AddSignal(s)
+		return nil
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "读取信号失败: %v\n", err)
+		return 1
+	}
+	if err := readOptional(filepath.Join(*dir, "latency_samples.jsonl"), func(s *latencysample.Sample) error {
+		joiner.AddSample(s)
+		return nil
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "读取时延样本失败: %v\n", err)
+		return 1
+	}
+
+	var obs []pnlfactor.Observation
+	err = jsonl.ForEach(filepath.Join(*dir, "paper_trades.jsonl"), func(t *model.PaperTrade) error {
+		if *leader == "" || t.Leader == *leader {
+			obs = append(obs, joiner.Join(t))
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取影子成交失败: %v\n", err)
+		return 1
+	}
+	if len(obs) == 0 {
+		fmt.Fprintln(os.Stderr, "没有影子成交")
+		return 1
+	}
+	if err := writeAnalysis(os.Stdout, obs, *buckets); err != nil {
+		fmt.Fprintf(os.Stderr, "输出分析结果失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readOptional 逐条读取 JSONL 文件，文件不存在时提示并跳过
+func readOptional[T any](path string, fn func(v *T) error) error {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "%s 不存在，相关因子不参与分析\n", path)
+		return nil
+	}
+	return jsonl.ForEach(path, fn)
+}
+
+// writeAnalysis 输出各因子分桶表、小时分桶表与回归结果
+// 单因子回归分别给出各因子的边际效应；多因子回归控制其余因子（只含三个因子都可关联的成交）。
+func writeAnalysis(w io.Writer, obs []pnlfactor.Observation, n int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "成交笔数: %d\n", len(obs))
+
+	factors := []string{pnlfactor.FactorLag, pnlfactor.FactorSpread, pnlfactor.FactorDepth}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "factor\tlo\thi\ttrades\twin_rate\tavg_net_bps\t")
+	for _, f := range factors {
+		for _, b := range pnlfactor.QuantileBuckets(obs, f, n) {
+			fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t%d\t%.3f\t%.3f\t\n", f, b.Lo, b.Hi, b.Trades, b.WinRate, b.AvgNetBps)
+		}
+	}
+	for _, b := range pnlfactor.HourBuckets(obs) {
+		fmt.Fprintf(tw, "%s\t%02.0f\t%02.0f\t%d\t%.3f\t%.3f\t\n", pnlfactor.FactorHour, b.Lo, b.Hi, b.Trades, b.WinRate, b.AvgNetBps)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "model\tterm\tbeta\tstd_err\tt_stat\tn\tr2\t")
+	models := make([][]string, 0, len(factors)+1)
+	for _, f := range factors {
+		models = append(models, []string{f})
+	}
+	models = append(models, factors)
+	for _, m := range models {
+		name := "multi"
+		if len(m) == 1 {
+			name = m[0]
+		}
+		res, ok := pnlfactor.Regress(obs, m)
+		if !ok {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\t-\t-\t\n", name)
+			continue
+		}
+		for _, c := range res.Coefs {
+			fmt.Fprintf(tw, "%s\t%s\t%.4f\t%.4f\t%.2f\t%d\t%.4f\t\n", name, c.Name, c.Beta, c.StdErr, c.TStat, res.N, res.R2)
+		}
+	}
+	return tw.Flush()
+}
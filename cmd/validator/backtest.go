@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	ossignal "os/signal"
+	"path/filepath"
+	"syscall"
+
+	"latency-arbitrage-validator/internal/backtest"
+	"latency-arbitrage-validator/internal/output/jsonl"
+)
+
+// runBacktest 离线回测：在录制的 books.jsonl 上并行扫描参数网格并输出排名表
+// 返回进程退出码。
+func runBacktest(args []string) int {
+	fs, cf := newFlagSet("backtest")
+	booksPath := fs.String("books", "", "录制的 books.jsonl 路径（默认最近一次运行目录下的 books.jsonl）")
+	workers := fs.Int("workers", 0, "并行 goroutine 数（0 表示沿用 backtest.workers）")
+	top := fs.Int("top", 20, "排名表输出前 N 行（0 表示全部）")
+	outPath := fs.String("out", "", "排名结果 JSONL 输出路径（可选）")
+	_ = fs.Parse(args)
+
+	cfg, err := cf.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+	if *booksPath == "" {
+		*booksPath = filepath.Join(defaultRunDir(cfg), "books.jsonl")
+	}
+	if *workers <= 0 {
+		*workers = cfg.Backtest.Workers
+	}
+
+	ctx, cancel := ossignal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	combos := backtest.Combos(cfg, cfg.Backtest.Grid)
+	fmt.Fprintf(os.Stderr, "回测 %s：%d 组参数\n", *booksPath, len(combos))
+
+	results, err := backtest.Sweep(ctx, bookSource(cfg, *booksPath), cfg, combos, *workers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "回测失败: %v\n", err)
+		return 1
+	}
+	backtest.Rank(results)
+
+	if err := backtest.WriteTable(os.Stdout, results, *top); err != nil {
+		fmt.Fprintf(os.Stderr, "输出排名表失败: %v\n", err)
+		return 1
+	}
+
+	if *outPath != "" {
+		w, err := jsonl.NewWriter(*outPath, len(results)+1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建结果 writer 失败: %v\n", err)
+			return 1
+		}
+		for _, r := range results {
+			_ = w.Write(r)
+		}
+		if err := w.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "写入结果失败: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
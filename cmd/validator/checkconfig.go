@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"latency-arbitrage-validator/internal/metadata"
+)
+
+// runCheckConfig 配置检查（dry-run）：校验 YAML、用公开元数据解析 symbols 映射、打印生效配置
+// 不建立任何 WS 连接。返回进程退出码：任何问题均返回非零。
+func runCheckConfig(args []string) int {
+	fs, cf := newFlagSet("check-config")
+	offline := fs.Bool("offline", false, "跳过元数据拉取与 symbol 映射检查")
+	quiet := fs.Bool("quiet", false, "不打印生效配置")
+	_ = fs.Parse(args)
+
+	// 加载即完成默认值填充与字段校验
+	cfg, err := cf.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+
+	if !*quiet {
+		fmt.Fprintf(os.Stdout, "# 生效配置（已填充默认值）: %s\n", cf.configPath)
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "序列化生效配置失败: %v\n", err)
+			return 1
+		}
+		_ = enc.Close()
+	}
+
+	if *offline {
+		fmt.Fprintln(os.Stderr, "配置有效（已跳过 symbol 映射检查）")
+		return 0
+	}
+
+	timeout := time.Duration(cfg.Metadata.TimeoutMs)*time.Millisecond*3 + 5*time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	fetcher := metadata.NewHTTPFetcher(cfg.Metadata.TimeoutMs)
+	symbolMaps, mapErrs, err := metadata.ResolveSymbols(ctx, cfg, fetcher)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "获取元数据失败: %v\n", err)
+		return 1
+	}
+	for _, e := range mapErrs {
+		fmt.Fprintf(os.Stderr, "%v\n", e)
+	}
+	if len(mapErrs) > 0 {
+		fmt.Fprintf(os.Stderr, "配置检查失败：%d/%d 个交易对无法映射\n", len(mapErrs), len(cfg.Symbols))
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "配置有效：%d 个交易对均已映射\n", len(symbolMaps))
+	return 0
+}
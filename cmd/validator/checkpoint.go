@@ -0,0 +1,81 @@
+package main
+
+import (
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/checkpoint"
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// checkpointState 导出当前运行状态（须在聚合器 goroutine 中调用）
+func (a *aggregator) checkpointState() *checkpoint.State {
+	st := &checkpoint.State{
+		Version:       checkpoint.Version,
+		SavedAtUnixNs: a.now(),
+		Pipelines:     make([]checkpoint.PipelineState, 0, len(a.pipelines)),
+		Latency:       a.latTracker.ExportState(),
+	}
+	for _, p := range a.pipelines {
+		st.Pipelines = append(st.Pipelines, checkpoint.PipelineState{
+			Variant:   p.variant,
+			Leader:    p.leader,
+			Positions: p.exec.OpenPositions(),
+			EV:        p.ev.ExportState(),
+			Equity:    p.equity.Stats(),
+			Cooldowns: p.engine.Cooldowns(),
+		})
+	}
+	return st
+}
+
+// restoreCheckpoint 按（变体, Leader）恢复各链路状态；配置中已不存在的链路忽略
+// 须在 run 之前调用。
+// 返回: 恢复的链路数与未平仓仓位数
+func (a *aggregator) restoreCheckpoint(st *checkpoint.State) (pipelines, positions int) {
+	byKey := make(map[[2]string]*checkpoint.PipelineState, len(st.Pipelines))
+	for i := range st.Pipelines {
+		ps := &st.Pipelines[i]
+		byKey[[2]string{ps.Variant, ps.Leader}] = ps
+	}
+
+	for _, p := range a.pipelines {
+		ps := byKey[[2]string{p.variant, p.leader}]
+		if ps == nil {
+			continue
+		}
+		positions += p.exec.RestorePositions(ps.Positions)
+		p.ev.RestoreState(ps.EV)
+		p.equity.Restore(ps.Equity)
+		p.engine.RestoreCooldowns(ps.Cooldowns)
+		pipelines++
+	}
+	if st.Latency != nil {
+		a.latTracker.RestoreState(st.Latency)
+	}
+	return pipelines, positions
+}
+
+// restoreCheckpoint 加载检查点文件并恢复聚合器状态
+// 文件不存在、读取失败或超过 max_age_ms 时从空状态启动（仅记录日志）。
+func restoreCheckpoint(agg *aggregator, cfg *config.Config, logger *zap.Logger) {
+	st, err := checkpoint.Load(cfg.Checkpoint.Path)
+	if err != nil {
+		logger.Warn("加载检查点失败，从空状态启动", zap.Error(err))
+		return
+	}
+	if st == nil {
+		return
+	}
+	ageMs := (timeutil.NowNano() - st.SavedAtUnixNs) / 1e6
+	if cfg.Checkpoint.MaxAgeMs > 0 && ageMs > int64(cfg.Checkpoint.MaxAgeMs) {
+		logger.Info("检查点已过期，从空状态启动", zap.Int64("age_ms", ageMs))
+		return
+	}
+	pipelines, positions := agg.restoreCheckpoint(st)
+	logger.Info("已从检查点恢复",
+		zap.String("path", cfg.Checkpoint.Path),
+		zap.Int64("age_ms", ageMs),
+		zap.Int("pipelines", pipelines),
+		zap.Int("positions", positions))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"latency-arbitrage-validator/internal/buildinfo"
+	"latency-arbitrage-validator/internal/config"
+)
+
+// command 子命令定义
+type command struct {
+	// name 子命令名
+	name string
+	// summary 一行说明（用于 help 输出）
+	summary string
+	// run 执行子命令，返回进程退出码
+	run func(args []string) int
+}
+
+// commands 返回全部子命令（按 help 输出顺序）
+func commands() []command {
+	return []command{
+		{"run", "连接实时行情并输出信号/影子成交/指标（默认子命令）", runLive},
+		{"backtest", "在录制的 books.jsonl 上扫描参数网格", runBacktest},
+		{"walkforward", "滚动前推评估样本外 EV", runWalkForward},
+		{"replay", "按录制时间回放 books.jsonl 驱动完整链路", runReplay},
+		{"verify", "回放 books.jsonl 重算信号，与录制的 signals.jsonl 比对（检查非确定性与回归）", runVerify},
+		{"report", "汇总输出目录中的影子成交结果", runReport},
+		{"analyze", "关联影子成交、信号与时延样本，分析净利与滞后/价差/深度/时段的关系", runAnalyze},
+		{"merge", "合并多个地域节点的输出目录，比较各节点观测到的 Bittap 时延", runMerge},
+		{"check-config", "校验配置、解析 symbol 映射并打印生效配置（不建立 WS 连接）", runCheckConfig},
+		{"dump-symbols", "拉取元数据并打印 symbol 映射", runDumpSymbols},
+		{"version", "打印构建版本信息", runVersion},
+	}
+}
+
+// dispatch 解析子命令并执行
+// 无子命令或首个参数为标志（如 -config）时执行 run，兼容旧的单入口用法。
+// 返回进程退出码。
+func dispatch(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runLive(args)
+	}
+	name := args[0]
+	if name == "help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+	for _, c := range commands() {
+		if c.name == name {
+			return c.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "未知子命令: %s\n\n", name)
+	printUsage(os.Stderr)
+	return 2
+}
+
+// runVersion 打印构建版本信息
+func runVersion(args []string) int {
+	fmt.Fprintf(os.Stdout, "validator %s\n", buildinfo.Get())
+	return 0
+}
+
+// printUsage 输出子命令列表
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "用法: validator <子命令> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "子命令:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-14s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "除 version、merge 外各子命令均支持 -config、-log-level；使用 validator <子命令> -h 查看其余参数。")
+}
+
+// commonFlags 各子命令共享的标志
+type commonFlags struct {
+	// configPath 配置文件路径
+	configPath string
+	// logLevel 覆盖 app.log_level（为空时沿用配置）
+	logLevel string
+}
+
+// newFlagSet 创建子命令 FlagSet 并注册共享标志
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cf := &commonFlags{}
+	fs.StringVar(&cf.configPath, "config", "config.yaml", "配置文件路径")
+	fs.StringVar(&cf.logLevel, "log-level", "", "日志级别，覆盖 app.log_level: debug, info, warn, error")
+	return fs, cf
+}
+
+// loadConfig 加载配置并应用共享标志覆盖项
+func (cf *commonFlags) loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(cf.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cf.logLevel != "" {
+		cfg.App.LogLevel = cf.logLevel
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("配置验证失败: %w", err)
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"latency-arbitrage-validator/internal/metadata"
+)
+
+// runDumpSymbols 拉取三家交易所公开元数据，打印 symbols 配置解析出的映射表
+// 仅访问公开行情元数据接口。返回进程退出码。
+func runDumpSymbols(args []string) int {
+	fs, cf := newFlagSet("dump-symbols")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	_ = fs.Parse(args)
+
+	cfg, err := cf.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+
+	fetcher := metadata.NewHTTPFetcher(cfg.Metadata.TimeoutMs)
+	symbolMaps, err := metadata.BuildSymbolMaps(context.Background(), cfg, fetcher)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "构建 symbol 映射失败: %v\n", err)
+		return 1
+	}
+
+	maps := make([]*metadata.SymbolMap, 0, len(symbolMaps))
+	for _, m := range symbolMaps {
+		maps = append(maps, m)
+	}
+	sort.Slice(maps, func(i, j int) bool { return maps[i].Canon < maps[j].Canon })
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(maps); err != nil {
+			fmt.Fprintf(os.Stderr, "输出映射失败: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "canon\tinput\tokx\tbinance\tbittap\tbittap_tick\ttick_size")
+	for _, m := range maps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%g\n",
+			m.Canon, m.UserInput, m.OKXInstId, m.BinanceSym, m.BittapSym, m.BittapTick, m.TickSize)
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "输出映射失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	ossignal "os/signal"
+	"syscall"
+)
+
+// notifyLatencyReset 收到 SIGUSR1 时调用 reset（kill -USR1 <pid>），直到 ctx 取消
+func notifyLatencyReset(ctx context.Context, reset func()) {
+	ch := make(chan os.Signal, 1)
+	ossignal.Notify(ch, syscall.SIGUSR1)
+	go func() {
+		defer ossignal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				reset()
+			}
+		}
+	}()
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package main
+
+import "context"
+
+// notifyLatencyReset Windows 无 SIGUSR1，不支持信号触发重置
+func notifyLatencyReset(ctx context.Context, reset func()) {}
@@ -7,39 +7,64 @@ package main
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"os"
 	ossignal "os/signal"
+	"runtime"
 	"syscall"
 	"time"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
+	"latency-arbitrage-validator/internal/buildinfo"
+	"latency-arbitrage-validator/internal/chaos"
+	"latency-arbitrage-validator/internal/checkpoint"
 	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/blackout"
 	"latency-arbitrage-validator/internal/core/model"
-	"latency-arbitrage-validator/internal/core/paper"
-	sigengine "latency-arbitrage-validator/internal/core/signal"
 	"latency-arbitrage-validator/internal/core/store"
 	"latency-arbitrage-validator/internal/exchange/binance"
 	"latency-arbitrage-validator/internal/exchange/bittap"
+	"latency-arbitrage-validator/internal/exchange/connerr"
+	"latency-arbitrage-validator/internal/exchange/dedup"
 	"latency-arbitrage-validator/internal/exchange/okx"
+	"latency-arbitrage-validator/internal/exchange/status"
+	"latency-arbitrage-validator/internal/logging"
 	"latency-arbitrage-validator/internal/metadata"
 	"latency-arbitrage-validator/internal/output/jsonl"
+	"latency-arbitrage-validator/internal/output/manifest"
+	"latency-arbitrage-validator/internal/output/rawcapture"
+	"latency-arbitrage-validator/internal/output/sink"
+	"latency-arbitrage-validator/internal/stats/bars"
+	"latency-arbitrage-validator/internal/stats/channelcmp"
+	"latency-arbitrage-validator/internal/stats/equity"
 	"latency-arbitrage-validator/internal/stats/ev"
+	"latency-arbitrage-validator/internal/stats/infoshare"
 	"latency-arbitrage-validator/internal/stats/latency"
+	"latency-arbitrage-validator/internal/stats/latencysample"
+	"latency-arbitrage-validator/internal/stats/leadlag"
+	"latency-arbitrage-validator/internal/stats/pipeline"
+	"latency-arbitrage-validator/internal/stats/procstats"
+	"latency-arbitrage-validator/internal/stats/significance"
+	"latency-arbitrage-validator/internal/stats/spreadsample"
+	"latency-arbitrage-validator/internal/stats/staleness"
+	"latency-arbitrage-validator/internal/util/logsample"
 	"latency-arbitrage-validator/internal/util/timeutil"
 )
 
-type rateKey struct {
-	ex  string
-	sym string
-}
+// metricsSchemaVersion metrics.jsonl 记录格式版本（字段含义变更或删除字段时递增）
+const metricsSchemaVersion = 1
 
+// metricsSnapshot 周期性运行指标快照（metrics.jsonl 的一条记录）
 type metricsSnapshot struct {
+	// SchemaVersion 输出格式版本（metricsSchemaVersion）
+	SchemaVersion int `json:"schema_version"`
 	// TsUnixNs 指标采集时间（纳秒）
 	TsUnixNs int64 `json:"ts_unix_ns"`
+	// Build 产生本快照的程序构建信息
+	Build buildinfo.Info `json:"build"`
+	// Process 进程资源（goroutine、堆内存、GC、线程）
+	Process procstats.Stats `json:"process"`
 
 	// OKX OKX 连接指标
 	OKX okx.ConnectionMetrics `json:"okx"`
@@ -48,18 +73,80 @@ type metricsSnapshot struct {
 	// Bittap Bittap 连接指标
 	Bittap bittap.ConnectionMetrics `json:"bittap"`
 
-	// LatencyOKX OKX↙Bittap 时延统计
+	// OKXChannels OKX books5 与对比频道的到达时延对比（未配置 ws.okx.compare_channel 时不输出）
+	OKXChannels *channelcmp.Stats `json:"okx_channels,omitempty"`
+	// OKXBackup OKX 冗余连接指标（未启用冗余时不输出）
+	OKXBackup *okx.ConnectionMetrics `json:"okx_backup,omitempty"`
+	// BinanceBackup Binance 冗余连接指标（未启用冗余时不输出）
+	BinanceBackup *binance.ConnectionMetrics `json:"binance_backup,omitempty"`
+	// DedupOKX OKX 双连接去重统计
+	DedupOKX *dedup.Stats `json:"dedup_okx,omitempty"`
+	// DedupBinance Binance 双连接去重统计
+	DedupBinance *dedup.Stats `json:"dedup_binance,omitempty"`
+	// LastErrors 各交易所最近一次连接错误（含冗余连接，按交易所合并）
+	LastErrors map[string]connerr.Last `json:"last_errors,omitempty"`
+
+	// LatencyOKX OKX↙Bittap 时延统计（分位数至 P99.9，含最小/最大/均值）
 	LatencyOKX latency.LatencyStats `json:"latency_okx"`
 	// LatencyBinance Binance↙Bittap 时延统计
 	LatencyBinance latency.LatencyStats `json:"latency_binance"`
+	// LeaderLead 逐交易对 OKX 与 Binance 谁先动及领先时延
+	LeaderLead []latency.LeaderLeadStats `json:"leader_lead,omitempty"`
+	// LeadShare 逐交易对 Follower 显著变动前 Leader 已同向变动的占比
+	LeadShare []latency.LeadShareStats `json:"lead_share,omitempty"`
+	// LatencyByHour 按 UTC 小时分桶的到达时延（按 Leader）
+	LatencyByHour map[string][]latency.HourLatencyStats `json:"latency_by_hour,omitempty"`
+	// InfoShare 逐交易对 Leader 的 Hasbrouck 信息份额与成分份额（最近一轮估计）
+	InfoShare []infoshare.Result `json:"info_share,omitempty"`
 
 	// EVOKX OKX 链路 EV 统计
 	EVOKX ev.EVStats `json:"ev_okx"`
 	// EVBinance Binance 链路 EV 统计
 	EVBinance ev.EVStats `json:"ev_binance"`
 
+	// EquityOKX OKX 链路权益曲线与回撤（会话累计）
+	EquityOKX equity.EquityStats `json:"equity_okx"`
+	// EquityBinance Binance 链路权益曲线与回撤（会话累计）
+	EquityBinance equity.EquityStats `json:"equity_binance"`
+	// EVByHour 基础策略按入场 UTC 小时分桶的 EV（按 Leader，会话累计）
+	EVByHour map[string][]ev.HourEVStats `json:"ev_by_hour,omitempty"`
+	// EVSignificance 基础策略 EV > 0 的显著性检验（按 Leader，滚动窗口内的每笔净利）
+	EVSignificance map[string]significance.Result `json:"ev_significance,omitempty"`
+	// PhantomFills 基础策略报价持续校验失败的幻影成交笔数（按 Leader，累计；未启用时不输出）
+	PhantomFills map[string]int64 `json:"phantom_fills,omitempty"`
+	// BlackoutSuppressed 基础策略在禁止开仓时段内被抑制的信号数（按 Leader，累计）
+	BlackoutSuppressed map[string]int64 `json:"blackout_suppressed,omitempty"`
+	// BlackoutActive 快照时刻所处的禁止开仓时段名称（不在时段内时不输出）
+	BlackoutActive string `json:"blackout_active,omitempty"`
+	// Maintenance 快照时刻处于维护中的交易所及公告标题（信号生成已暂停）
+	Maintenance map[string]string `json:"maintenance,omitempty"`
+	// MaintenanceSkipped 基础策略因 Leader 维护跳过的信号评估次数（按 Leader，累计）
+	MaintenanceSkipped map[string]int64 `json:"maintenance_skipped,omitempty"`
+	// FeedDegraded 快照时刻 Bittap 行情是否处于降级状态（信号生成已暂停）
+	FeedDegraded bool `json:"feed_degraded,omitempty"`
+	// FeedPauses Bittap 行情降级导致的暂停次数（累计）
+	FeedPauses int64 `json:"feed_pauses,omitempty"`
+
 	// UpdatesPerSec 按交易所/交易对的更新速率（基于聚合器统计）
 	UpdatesPerSec []updateRate `json:"updates_per_sec,omitempty"`
+
+	// Pipeline 本进程管线各阶段耗时（按交易所，统计窗口为两次快照之间）
+	Pipeline map[string]pipeline.Stats `json:"pipeline,omitempty"`
+	// StaleDropped 按交易所统计因 Seq 重复/乱序被丢弃的事件数（累计）
+	StaleDropped map[string]int64 `json:"stale_dropped,omitempty"`
+	// DegradedEvents 按交易所统计 WS 断线期间 REST 轮询得到的降级行情事件数（累计）
+	DegradedEvents map[string]int64 `json:"degraded_events,omitempty"`
+	// FollowerSpreadBps 按交易对的 Bittap 滚动中位报价价差（slippage_mode=spread 时输出）
+	FollowerSpreadBps map[string]float64 `json:"follower_spread_median_bps,omitempty"`
+	// OpenPositions 各链路未平仓仓位心跳（无持仓时不输出）
+	OpenPositions []model.PositionHeartbeat `json:"open_positions,omitempty"`
+	// Chaos 按交易所的故障注入统计（仅测试模式输出）
+	Chaos map[string]chaos.Stats `json:"chaos,omitempty"`
+	// OutputErrors 按输出文件的写入统计（仅输出发生过编码/写入错误的文件）
+	OutputErrors map[string]jsonl.Stats `json:"output_errors,omitempty"`
+
+	// Variants 策略变体（A/B 实验）的 EV 统计
+	Variants []variantMetrics `json:"variants,omitempty"`
 }
 
 type updateRate struct {
@@ -71,23 +158,67 @@ type updateRate struct {
 	UpdatesPerSec float64 `json:"updates_per_sec"`
 }
 
+type variantMetrics struct {
+	// Name 变体名称
+	Name string `json:"name"`
+	// EVOKX OKX 链路 EV 统计
+	EVOKX ev.EVStats `json:"ev_okx"`
+	// EVBinance Binance 链路 EV 统计
+	EVBinance ev.EVStats `json:"ev_binance"`
+	// EquityOKX OKX 链路权益曲线
+	EquityOKX equity.EquityStats `json:"equity_okx"`
+	// EquityBinance Binance 链路权益曲线
+	EquityBinance equity.EquityStats `json:"equity_binance"`
+	// PhantomFills 幻影成交笔数（按 Leader，累计）
+	PhantomFills map[string]int64 `json:"phantom_fills,omitempty"`
+	// EVSignificance EV > 0 的显著性检验（按 Leader，滚动窗口）
+	EVSignificance map[string]significance.Result `json:"ev_significance,omitempty"`
+}
+
 func main() {
-	var configPath string
-	flag.StringVar(&configPath, "config", "config.yaml", "配置文件路径")
-	flag.Parse()
+	os.Exit(dispatch(os.Args[1:]))
+}
 
-	cfg, err := config.Load(configPath)
+// runLive 实时验证：连接三家行情并运行聚合器，直到收到退出信号
+// 返回进程退出码。
+func runLive(args []string) int {
+	fs, cf := newFlagSet("run")
+	legacyOutput := fs.Bool("legacy-output", false, "沿用旧输出布局（追加写入 output.dir 下的共享文件），覆盖 output.legacy_layout")
+	_ = fs.Parse(args)
+
+	cfg, err := cf.loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
-	if err := cfg.Validate(); err != nil {
-		fmt.Fprintf(os.Stderr, "配置验证失败: %v\n", err)
-		os.Exit(1)
+	if *legacyOutput {
+		cfg.Output.LegacyLayout = true
 	}
+	// 每次运行使用独立输出目录，重复或并发运行的记录互不交错
+	runDir, runID, err := prepareRunDir(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "准备输出目录失败: %v\n", err)
+		return 1
+	}
+	cfg.Output.Dir = runDir
 
-	logger := newLogger(cfg.App.LogLevel)
-	defer logger.Sync()
+	logger, closeLog, err := logging.New(cfg.App)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化日志失败: %v\n", err)
+		return 1
+	}
+	defer closeLog()
+
+	build := buildinfo.Get()
+	logger.Info("验证器启动",
+		zap.String("version", build.Version),
+		zap.String("commit", build.Commit),
+		zap.String("build_time", build.BuildTime),
+		zap.Bool("dirty", build.Dirty),
+		zap.String("go_version", build.GoVersion),
+		zap.String("config", cf.configPath),
+		zap.String("run_id", runID),
+		zap.String("output_dir", runDir))
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -106,102 +237,292 @@ func main() {
 	symbolMaps, err := metadata.BuildSymbolMaps(ctx, cfg, fetcher)
 	if err != nil {
 		logger.Error("构建 symbol 映射失败", zap.Error(err))
-		os.Exit(1)
+		return 1
 	}
 
 	logger.Info("symbol 映射完成", zap.Int("symbols", len(symbolMaps)))
 
-	okxClient := okx.NewClient(&cfg.WS.OKX, symbolMaps, logger)
-	binanceClient := binance.NewClient(&cfg.WS.Binance, symbolMaps, logger)
-	bittapClient := bittap.NewClient(&cfg.WS.Bittap, symbolMaps, logger)
+	// 运行清单：启动时写入，退出时补记结束时间
+	runManifest, err := manifest.New("run", cf.configPath, cfg, symbolMaps, outputSchemaVersions())
+	if err != nil {
+		logger.Error("创建运行清单失败", zap.Error(err))
+		return 1
+	}
+	runManifest.RunID = runID
+	if err := runManifest.Write(cfg.Output.Dir); err != nil {
+		logger.Error("写入运行清单失败", zap.Error(err))
+		return 1
+	}
+
+	// 行情客户端日志归入 exchange 组件（exchange.okx 等），可在 app.log_levels 单独调整级别
+	exchangeLogger := logger.Named("exchange")
+	okxClient := okx.NewClient(&cfg.WS.OKX, symbolMaps, exchangeLogger)
+	binanceClient := binance.NewClient(&cfg.WS.Binance, symbolMaps, exchangeLogger)
+	bittapClient := bittap.NewClient(&cfg.WS.Bittap, symbolMaps, exchangeLogger)
+
+	// 冗余连接（仅 Leader）：第二条连接可指向其它接入点
+	var okxBackup *okx.Client
+	var binanceBackup *binance.Client
+	if cfg.WS.OKX.Redundant {
+		okxBackup = okx.NewClient(backupWSConfig(cfg.WS.OKX), symbolMaps, exchangeLogger.Named("backup"))
+	}
+	if cfg.WS.Binance.Redundant {
+		binanceBackup = binance.NewClient(backupWSConfig(cfg.WS.Binance), symbolMaps, exchangeLogger.Named("backup"))
+	}
+
+	// 原始帧采样录制（按交易所独立文件，冗余连接共用同一录制器）
+	var rawWriters []*jsonl.Writer
+	rawTargets := []struct {
+		exchange string
+		rate     float64
+		set      []func(*rawcapture.Recorder)
+	}{
+		{model.ExchangeOKX, cfg.WS.OKX.RawCaptureRate, []func(*rawcapture.Recorder){okxClient.SetRawCapture}},
+		{model.ExchangeBinance, cfg.WS.Binance.RawCaptureRate, []func(*rawcapture.Recorder){binanceClient.SetRawCapture}},
+		{model.ExchangeBittap, cfg.WS.Bittap.RawCaptureRate, []func(*rawcapture.Recorder){bittapClient.SetRawCapture}},
+	}
+	if okxBackup != nil {
+		rawTargets[0].set = append(rawTargets[0].set, okxBackup.SetRawCapture)
+	}
+	if binanceBackup != nil {
+		rawTargets[1].set = append(rawTargets[1].set, binanceBackup.SetRawCapture)
+	}
+	for _, t := range rawTargets {
+		if t.rate <= 0 {
+			continue
+		}
+		w, err := jsonl.NewWriterWithOptions(fmt.Sprintf("%s/raw_%s.jsonl", cfg.Output.Dir, t.exchange), sink.JSONLOptions(cfg.Output))
+		if err != nil {
+			logger.Error("创建原始帧录制文件失败", zap.Error(err), zap.String("exchange", t.exchange))
+			return 1
+		}
+		rawWriters = append(rawWriters, w)
+		rec := rawcapture.NewRecorder(t.exchange, t.rate, w)
+		for _, set := range t.set {
+			set(rec)
+		}
+	}
 
 	startCtx, startCancel := context.WithTimeout(ctx, 10*time.Second)
 	defer startCancel()
 
-	if err := okxClient.Connect(startCtx); err != nil {
-		logger.Error("OKX 连接失败", zap.Error(err))
-		os.Exit(1)
+	feeds := []namedFeed{
+		{name: "OKX", client: okxClient, restFallback: cfg.WS.OKX.RestFallbackAfterMs > 0},
+		{name: "Binance", client: binanceClient, restFallback: cfg.WS.Binance.RestFallbackAfterMs > 0},
+		{name: "Bittap", client: bittapClient},
 	}
-	if err := okxClient.Subscribe(); err != nil {
-		logger.Error("OKX 订阅失败", zap.Error(err))
-		os.Exit(1)
+	if okxBackup != nil {
+		feeds = append(feeds, namedFeed{name: "OKX(backup)", client: okxBackup})
+	}
+	if binanceBackup != nil {
+		feeds = append(feeds, namedFeed{name: "Binance(backup)", client: binanceBackup})
 	}
 
-	if err := binanceClient.Connect(startCtx); err != nil {
-		logger.Error("Binance 连接失败", zap.Error(err))
-		os.Exit(1)
+	for _, f := range feeds {
+		if err := f.client.Connect(startCtx); err != nil {
+			if !f.restFallback {
+				logger.Error(f.name+" 连接失败", zap.Error(err))
+				return 1
+			}
+			// 已启用 REST 轮询降级：交由读循环退避重连（成功后自动订阅），断线超时后改用 REST 深度
+			logger.Warn(f.name+" 连接失败，后台重连并启用 REST 轮询降级", zap.Error(err))
+			continue
+		}
+		if err := f.client.Subscribe(); err != nil {
+			logger.Error(f.name+" 订阅失败", zap.Error(err))
+			return 1
+		}
 	}
-	if err := binanceClient.Subscribe(); err != nil {
-		logger.Error("Binance 订阅失败", zap.Error(err))
-		os.Exit(1)
+	errChs := make([]<-chan error, 0, len(feeds))
+	for _, f := range feeds {
+		errChs = append(errChs, f.client.ErrCh())
+		go f.client.Run(ctx)
 	}
 
-	if err := bittapClient.Connect(startCtx); err != nil {
-		logger.Error("Bittap 连接失败", zap.Error(err))
-		os.Exit(1)
+	var okxMerger, binanceMerger *dedup.Merger
+	if okxBackup != nil {
+		okxMerger = dedup.NewMerger(cap(okxClient.BookCh()), okxClient.BookCh(), okxBackup.BookCh())
+		go okxMerger.Run(ctx)
 	}
-	if err := bittapClient.Subscribe(); err != nil {
-		logger.Error("Bittap 订阅失败", zap.Error(err))
-		os.Exit(1)
+	if binanceBackup != nil {
+		binanceMerger = dedup.NewMerger(cap(binanceClient.BookCh()), binanceClient.BookCh(), binanceBackup.BookCh())
+		go binanceMerger.Run(ctx)
 	}
 
-	go okxClient.Run(ctx)
-	go binanceClient.Run(ctx)
-	go bittapClient.Run(ctx)
-
-	var signalsWriter *jsonl.Writer
-	var paperWriter *jsonl.Writer
-	var metricsWriter *jsonl.Writer
-	if cfg.Output.SignalsEnabled {
-		signalsWriter, err = jsonl.NewWriter(fmt.Sprintf("%s/signals.jsonl", cfg.Output.Dir), cfg.Output.BufferSize)
-		if err != nil {
-			logger.Error("创建 signals writer 失败", zap.Error(err))
-			os.Exit(1)
-		}
+	// 输出流：*_enabled 控制默认 JSONL 文件，output.sinks 可为同一流附加其他目标
+	streams := []struct {
+		name    string
+		enabled bool
+	}{
+		{"signals", cfg.Output.SignalsEnabled},
+		{"paper_trades", cfg.Output.PaperTradesEnabled},
+		{"metrics", cfg.Output.MetricsEnabled},
+		{"books", cfg.Output.BooksEnabled},
+		{"alerts", cfg.Output.AlertsEnabled},
+		{"positions", cfg.Output.PositionsEnabled},
+		{"leadlag", cfg.LeadLag.Enabled},
+		{"spreads", cfg.Output.SpreadsEnabled},
+		{"bars", cfg.Output.BarsEnabled},
+		{"latency_samples", cfg.Output.LatencySamplesEnabled},
+		{"staleness", cfg.Output.StalenessEnabled},
 	}
-	if cfg.Output.PaperTradesEnabled {
-		paperWriter, err = jsonl.NewWriter(fmt.Sprintf("%s/paper_trades.jsonl", cfg.Output.Dir), cfg.Output.BufferSize)
-		if err != nil {
-			logger.Error("创建 paper_trades writer 失败", zap.Error(err))
-			os.Exit(1)
+	outputs := make(map[string]sink.Sink, len(streams))
+	for _, st := range streams {
+		if st.name == "leadlag" && !cfg.LeadLag.Enabled {
+			continue
 		}
-	}
-	if cfg.Output.MetricsEnabled {
-		metricsWriter, err = jsonl.NewWriter(fmt.Sprintf("%s/metrics.jsonl", cfg.Output.Dir), cfg.Output.BufferSize)
+		s, err := openOutputStream(cfg, st.name, st.enabled)
 		if err != nil {
-			logger.Error("创建 metrics writer 失败", zap.Error(err))
-			os.Exit(1)
+			logger.Error("创建 "+st.name+" 输出失败", zap.Error(err))
+			for _, o := range outputs {
+				_ = o.Close()
+			}
+			return 1
+		}
+		if s != nil {
+			outputs[st.name] = s
 		}
 	}
+	metricsWriter := outputs["metrics"]
 
-	// 初始化核心组件（两条 Leader 链路独立）
-	bookStore := store.New()
 	latTracker := latency.NewTracker(10000)
+	latTracker.EnableTimeWindow(cfg.Latency.WindowMs)
+	latTracker.EnableSpikeDetection(cfg.Latency)
+	latTracker.EnableLeaderComparison(cfg.Latency.LeaderMatchWindowMs)
+	latTracker.EnableLeadShare(cfg.Latency.LeadShareMinMoveBps, cfg.Latency.LeadShareWindowMs)
+	// 运维在已知网络事件恢复后可通过信号清空时延统计（Windows 不支持）
+	notifyLatencyReset(ctx, func() {
+		latTracker.Reset()
+		logger.Info("收到重置信号，已清空时延统计")
+	})
+	spikeCheckIntervalMs := 0
+	if cfg.Latency.SpikeFactor > 0 {
+		spikeCheckIntervalMs = cfg.Latency.SpikeCheckIntervalMs
+	}
 
-	okxEngine := sigengine.NewEngine(model.ExchangeOKX, cfg.Strategy)
-	binanceEngine := sigengine.NewEngine(model.ExchangeBinance, cfg.Strategy)
-	okxExec := paper.NewExecutor(model.ExchangeOKX, cfg.Paper, cfg.Fees.Bittap)
-	binanceExec := paper.NewExecutor(model.ExchangeBinance, cfg.Paper, cfg.Fees.Bittap)
-	okxEV := ev.NewCalculator(1000)
-	binanceEV := ev.NewCalculator(1000)
+	// 初始化核心组件（两条 Leader 链路独立；每个策略变体各自一套）
+	agg := &aggregator{
+		logger:            logger.Named("core"),
+		bookStore:         store.New(),
+		latTracker:        latTracker,
+		pipeTimer:         pipeline.NewTracker(),
+		pipelines:         buildPipelines(cfg),
+		okxClient:         okxClient,
+		binanceClient:     binanceClient,
+		bittapClient:      bittapClient,
+		okxBackup:         okxBackup,
+		binanceBackup:     binanceBackup,
+		okxMerger:         okxMerger,
+		binanceMerger:     binanceMerger,
+		errCh:             connerr.Merge(ctx, errChs...),
+		signalsWriter:     outputs["signals"],
+		signalsBBOOnly:    cfg.Output.SignalsBBOOnly,
+		paperWriter:       outputs["paper_trades"],
+		metricsWriter:     metricsWriter,
+		booksWriter:       outputs["books"],
+		alertsWriter:      outputs["alerts"],
+		positionsWriter:   outputs["positions"],
+		rawWriters:        rawWriters,
+		metricsIntervalMs: cfg.Output.MetricsIntervalMs,
+
+		spikeCheckIntervalMs: spikeCheckIntervalMs,
+	}
 
-	if err := runAggregator(ctx, logger, bookStore, latTracker, okxEngine, binanceEngine, okxExec, binanceExec, okxEV, binanceEV, okxClient, binanceClient, bittapClient, signalsWriter, paperWriter, metricsWriter, cfg.Output.MetricsIntervalMs); err != nil {
+	if cfg.Paper.SlippageMode == config.SlippageModeSpread {
+		agg.useSpreadSlippage(cfg.Paper.SpreadWindow)
+	}
+	if cfg.Paper.VolScaleRef > 0 {
+		agg.useVolScaling(cfg.Paper.VolScaleWindowMs)
+	}
+	if cal := blackout.New(cfg.Blackout); cal != nil {
+		agg.useBlackout(cal)
+	}
+	if cfg.ExchangeStatus.Enabled {
+		monitor := status.NewMonitor()
+		statusLogger := exchangeLogger.Named("status")
+		go status.RunOKX(ctx, cfg.ExchangeStatus.OKXURL, model.ExchangeOKX, monitor, statusLogger)
+		go status.RunBinance(ctx, cfg.ExchangeStatus.BinanceURL, cfg.ExchangeStatus.BinancePollIntervalMs, model.ExchangeBinance, monitor, statusLogger)
+		agg.useExchangeStatus(monitor)
+	}
+	if cfg.FeedGuard.Enabled {
+		agg.useFeedGuard(cfg.FeedGuard)
+	}
+	if s := outputs["latency_samples"]; s != nil {
+		agg.useLatencySampling(s, cfg.Output.LatencySampleEvery)
+	}
+	if s := outputs["spreads"]; s != nil {
+		agg.useSpreadSampling(s, cfg.Output.SpreadsIntervalMs)
+	}
+	if s := outputs["bars"]; s != nil {
+		agg.useBars(s, cfg.Output.BarIntervalsMs)
+	}
+	if s := outputs["staleness"]; s != nil {
+		agg.useStaleness(s, cfg.Output.StalenessIntervalMs)
+	}
+	if cfg.Chaos.Enabled {
+		logger.Warn("故障注入测试模式已启用（仅用于测试）", zap.Int64("seed", cfg.Chaos.Seed), zap.Strings("exchanges", cfg.Chaos.Exchanges))
+		agg.useChaos(cfg.Chaos)
+	}
+	if cfg.LeadLag.Enabled {
+		agg.leadlag = leadlag.NewEstimator(cfg.LeadLag.BucketMs, cfg.LeadLag.MaxLagMs, cfg.LeadLag.WindowMs)
+		agg.leadlagWriter = outputs["leadlag"]
+		agg.leadlagIntervalMs = cfg.LeadLag.IntervalMs
+	}
+	if cfg.InfoShare.Enabled {
+		agg.infoShare = infoshare.NewEstimator(cfg.InfoShare.SampleMs, cfg.InfoShare.WindowMs, cfg.InfoShare.Lags)
+		agg.infoShareIntervalMs = cfg.InfoShare.IntervalMs
+	}
+
+	// 输出写入错误（磁盘已满、权限不足等）采样告警，避免研究数据静默丢失
+	outputErrLog := logsample.New(100, time.Minute)
+	for _, w := range agg.outputReporters() {
+		w.OnError(func(err error) {
+			if ok, suppressed := outputErrLog.Allow(); ok {
+				logger.Warn("输出文件写入失败", zap.Error(err), zap.Uint64("suppressed", suppressed))
+			}
+		})
+	}
+
+	// 运行状态检查点：启动时恢复，运行中周期性保存
+	if cfg.Checkpoint.Path != "" {
+		restoreCheckpoint(agg, cfg, logger)
+		agg.checkpointSaver = checkpoint.NewSaver(cfg.Checkpoint.Path, func(err error) {
+			logger.Warn("保存检查点失败", zap.Error(err))
+		})
+		agg.checkpointIntervalMs = cfg.Checkpoint.IntervalMs
+		go agg.checkpointSaver.Run(ctx)
+	}
+
+	if cfg.App.PoolEvents {
+		agg.usePooledEvents()
+	}
+	if cfg.App.HotMode {
+		logger.Info("低时延热模式已启用：聚合器独占 OS 线程忙轮询", zap.Int("gomaxprocs", runtime.GOMAXPROCS(0)))
+		agg.hotMode = true
+	}
+
+	if err := agg.run(ctx); err != nil {
 		logger.Error("聚合器退出", zap.Error(err))
 	}
 
+	if cfg.Checkpoint.Path != "" {
+		// 停机时同步保存最终检查点（此时聚合器已退出，可安全读取状态）；
+		// 未平仓仓位随检查点保留到下次启动恢复，不强制平仓，避免重启后重复统计
+		if err := checkpoint.Save(cfg.Checkpoint.Path, agg.checkpointState()); err != nil {
+			logger.Warn("保存检查点失败", zap.Error(err))
+		}
+	} else if n := agg.closeOpenPositions(model.ExitShutdown); n > 0 {
+		// 未平仓仓位按最后已知报价强制平仓并写出，避免从统计中消失
+		logger.Info("停机强制平仓", zap.Int("positions", n))
+	}
+
+	// 未完成的 K 线与陈旧度分布在输出关闭前写出
+	agg.flushBars()
+	agg.flushStaleness()
+
 	// 输出最后一条 metrics 快照（便于离线复盘）
 	if metricsWriter != nil {
-		nowNs := timeutil.NowNano()
-		_ = metricsWriter.Write(metricsSnapshot{
-			TsUnixNs:       nowNs,
-			OKX:            okxClient.Metrics(),
-			Binance:        binanceClient.Metrics(),
-			Bittap:         bittapClient.Metrics(),
-			LatencyOKX:     latTracker.Stats(model.ExchangeOKX),
-			LatencyBinance: latTracker.Stats(model.ExchangeBinance),
-			EVOKX:          okxEV.Stats(),
-			EVBinance:      binanceEV.Stats(),
-		})
+		_ = metricsWriter.Write(agg.snapshot(timeutil.NowNano(), nil))
 		_ = metricsWriter.Flush()
 	}
 
@@ -214,14 +535,21 @@ func main() {
 		_ = okxClient.Close()
 		_ = binanceClient.Close()
 		_ = bittapClient.Close()
-		if signalsWriter != nil {
-			_ = signalsWriter.Close()
+		if okxBackup != nil {
+			_ = okxBackup.Close()
 		}
-		if paperWriter != nil {
-			_ = paperWriter.Close()
+		if binanceBackup != nil {
+			_ = binanceBackup.Close()
 		}
-		if metricsWriter != nil {
-			_ = metricsWriter.Close()
+		for _, o := range outputs {
+			_ = o.Close()
+		}
+		for _, w := range rawWriters {
+			_ = w.Close()
+		}
+		runManifest.Stop()
+		if err := runManifest.Write(cfg.Output.Dir); err != nil {
+			logger.Warn("更新运行清单失败", zap.Error(err))
 		}
 	}()
 
@@ -231,231 +559,70 @@ func main() {
 	case <-done:
 		logger.Info("关闭完成")
 	}
+	return 0
 }
 
-func newLogger(level string) *zap.Logger {
-	lvl := zapcore.InfoLevel
-	if err := lvl.Set(level); err != nil {
-		lvl = zapcore.InfoLevel
-	}
-
-	cfg := zap.NewProductionConfig()
-	cfg.Level = zap.NewAtomicLevelAt(lvl)
-	cfg.EncoderConfig.TimeKey = "ts"
-	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-
-	logger, err := cfg.Build()
-	if err != nil {
-		return zap.NewNop()
-	}
-	return logger
+// feedClient 行情客户端的公共生命周期接口
+type feedClient interface {
+	Connect(ctx context.Context) error
+	Subscribe() error
+	Run(ctx context.Context)
+	ErrCh() <-chan error
 }
 
-func runAggregator(
-	ctx context.Context,
-	logger *zap.Logger,
-	bookStore *store.Store,
-	latTracker *latency.Tracker,
-	okxEngine *sigengine.Engine,
-	binanceEngine *sigengine.Engine,
-	okxExec *paper.Executor,
-	binanceExec *paper.Executor,
-	okxEV *ev.Calculator,
-	binanceEV *ev.Calculator,
-	okxClient *okx.Client,
-	binanceClient *binance.Client,
-	bittapClient *bittap.Client,
-	signalsWriter *jsonl.Writer,
-	paperWriter *jsonl.Writer,
-	metricsWriter *jsonl.Writer,
-	metricsIntervalMs int,
-) error {
-	okxCh := okxClient.BookCh()
-	binanceCh := binanceClient.BookCh()
-	bittapCh := bittapClient.BookCh()
-
-	if metricsIntervalMs <= 0 {
-		metricsIntervalMs = 10000
-	}
-	metricsTicker := time.NewTicker(time.Duration(metricsIntervalMs) * time.Millisecond)
-	defer metricsTicker.Stop()
-
-	// 聚合器侧统计 updates_per_sec（按交易所/交易对）
-	counts := make(map[rateKey]int64)
-	lastCounts := make(map[rateKey]int64)
-	lastMetricsAt := timeutil.NowNano()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-
-		case ev, ok := <-okxCh:
-			if !ok {
-				okxCh = nil
-				continue
-			}
-			handleBookEvent(logger, bookStore, latTracker, okxEngine, binanceEngine, okxExec, binanceExec, okxEV, binanceEV, signalsWriter, paperWriter, ev, counts)
-
-		case ev, ok := <-binanceCh:
-			if !ok {
-				binanceCh = nil
-				continue
-			}
-			handleBookEvent(logger, bookStore, latTracker, okxEngine, binanceEngine, okxExec, binanceExec, okxEV, binanceEV, signalsWriter, paperWriter, ev, counts)
-
-		case ev, ok := <-bittapCh:
-			if !ok {
-				bittapCh = nil
-				continue
-			}
-			handleBookEvent(logger, bookStore, latTracker, okxEngine, binanceEngine, okxExec, binanceExec, okxEV, binanceEV, signalsWriter, paperWriter, ev, counts)
-
-		case <-metricsTicker.C:
-			if metricsWriter == nil {
-				continue
-			}
-
-			nowNs := timeutil.NowNano()
-			elapsedSec := float64(nowNs-lastMetricsAt) / 1e9
-			if elapsedSec <= 0 {
-				elapsedSec = float64(metricsIntervalMs) / 1000
-			}
-
-			var rates []updateRate
-			for k, v := range counts {
-				prev := lastCounts[k]
-				qps := float64(v-prev) / elapsedSec
-				rates = append(rates, updateRate{Exchange: k.ex, SymbolCanon: k.sym, UpdatesPerSec: qps})
-				lastCounts[k] = v
-			}
-			lastMetricsAt = nowNs
-
-			snap := metricsSnapshot{
-				TsUnixNs:       nowNs,
-				OKX:            okxClient.Metrics(),
-				Binance:        binanceClient.Metrics(),
-				Bittap:         bittapClient.Metrics(),
-				LatencyOKX:     latTracker.Stats(model.ExchangeOKX),
-				LatencyBinance: latTracker.Stats(model.ExchangeBinance),
-				EVOKX:          okxEV.Stats(),
-				EVBinance:      binanceEV.Stats(),
-				UpdatesPerSec:  rates,
-			}
-			_ = metricsWriter.Write(snap)
-			_ = metricsWriter.Flush()
-			// 同时 flush signals 和 paper_trades，确保数据落盘
-			if signalsWriter != nil {
-				_ = signalsWriter.Flush()
-			}
-			if paperWriter != nil {
-				_ = paperWriter.Flush()
-			}
-		}
+// namedFeed 带日志名的行情客户端
+type namedFeed struct {
+	name   string
+	client feedClient
+	// restFallback 已启用 REST 轮询降级（首次连接失败时不退出，由读循环重连）
+	restFallback bool
+}
 
-		if okxCh == nil && binanceCh == nil && bittapCh == nil {
-			return nil
-		}
+// backupWSConfig 生成冗余连接配置（BackupURL 为空时沿用 URL）
+// REST 轮询降级与频道对比只由主连接负责，避免重复拉取占用 REST 权重；
+// 固定 IP 属于主连接域名，冗余连接指向其它接入点时不沿用。
+func backupWSConfig(primary config.ExchangeWSConfig) *config.ExchangeWSConfig {
+	backup := primary
+	if backup.BackupURL != "" {
+		backup.URL = backup.BackupURL
+		backup.PinIP = ""
 	}
+	backup.RestFallbackAfterMs = 0
+	backup.CompareChannel = ""
+	return &backup
 }
 
-func handleBookEvent(
-	logger *zap.Logger,
-	bookStore *store.Store,
-	latTracker *latency.Tracker,
-	okxEngine *sigengine.Engine,
-	binanceEngine *sigengine.Engine,
-	okxExec *paper.Executor,
-	binanceExec *paper.Executor,
-	okxEV *ev.Calculator,
-	binanceEV *ev.Calculator,
-	signalsWriter *jsonl.Writer,
-	paperWriter *jsonl.Writer,
-	ev *model.BookEvent,
-	counts map[rateKey]int64,
-) {
-	if ev == nil || ev.Exchange == "" || ev.SymbolCanon == "" {
-		return
-	}
-	counts[rateKey{ex: ev.Exchange, sym: ev.SymbolCanon}]++
-
-	bookStore.Update(ev)
-
-	// 仅在 Follower 更新时记录时延（使用最新 Leader 快照）
-	if ev.Exchange == model.ExchangeBittap {
-		if okxBook, _ := bookStore.GetPair(model.ExchangeOKX, ev.SymbolCanon); okxBook != nil {
-			latTracker.Add(okxBook, ev)
-		}
-		if binanceBook, _ := bookStore.GetPair(model.ExchangeBinance, ev.SymbolCanon); binanceBook != nil {
-			latTracker.Add(binanceBook, ev)
-		}
+// outputSchemaVersions 各输出流的记录格式版本（写入运行清单）
+func outputSchemaVersions() map[string]int {
+	return map[string]int{
+		"signals":         model.SignalSchemaVersion,
+		"paper_trades":    model.PaperTradeSchemaVersion,
+		"metrics":         metricsSchemaVersion,
+		"spreads":         spreadsample.SchemaVersion,
+		"bars":            bars.SchemaVersion,
+		"latency_samples": latencysample.SchemaVersion,
+		"staleness":       staleness.SchemaVersion,
 	}
+}
 
-	// 评估与执行（两条链路独立）
-	okxBook, bittapBook := bookStore.GetPair(model.ExchangeOKX, ev.SymbolCanon)
-	if okxBook != nil && bittapBook != nil {
-		if sig := okxEngine.Evaluate(ev.ArrivedAtUnixNs, okxBook, bittapBook); sig != nil {
-			applyEVAndMaybeOpen(sig, okxEV, okxExec, signalsWriter, paperWriter, logger)
-		}
-		if closed := okxExec.Evaluate(ev.ArrivedAtUnixNs, okxBook, bittapBook); closed != nil {
-			okxEV.Add(closed)
-			if closed.ExitReason == model.ExitSL {
-				okxEngine.NotifyStopLoss(closed.SymbolCanon, ev.ArrivedAtUnixNs)
-			}
-			if paperWriter != nil {
-				_ = paperWriter.Write(closed.ToPaperTrade(okxEV.Snapshot()))
-			}
-		}
+// openOutputStream 创建输出流：enabled 时写入默认文件 <output.dir>/<name>.jsonl，另附加 output.sinks 中该流的目标
+// 返回: 无任何目标时为 nil
+func openOutputStream(cfg *config.Config, name string, enabled bool) (sink.Sink, error) {
+	var targets []config.SinkConfig
+	if enabled {
+		targets = append(targets, config.SinkConfig{Type: config.SinkTypeJSONL, Path: fmt.Sprintf("%s/%s.jsonl", cfg.Output.Dir, name)})
 	}
-
-	binBook, bittapBook2 := bookStore.GetPair(model.ExchangeBinance, ev.SymbolCanon)
-	if binBook != nil && bittapBook2 != nil {
-		if sig := binanceEngine.Evaluate(ev.ArrivedAtUnixNs, binBook, bittapBook2); sig != nil {
-			applyEVAndMaybeOpen(sig, binanceEV, binanceExec, signalsWriter, paperWriter, logger)
-		}
-		if closed := binanceExec.Evaluate(ev.ArrivedAtUnixNs, binBook, bittapBook2); closed != nil {
-			binanceEV.Add(closed)
-			if closed.ExitReason == model.ExitSL {
-				binanceEngine.NotifyStopLoss(closed.SymbolCanon, ev.ArrivedAtUnixNs)
-			}
-			if paperWriter != nil {
-				_ = paperWriter.Write(closed.ToPaperTrade(binanceEV.Snapshot()))
+	targets = append(targets, cfg.Output.Sinks[name]...)
+	var sinks []sink.Sink
+	for _, t := range targets {
+		s, err := sink.Open(t, cfg.Output)
+		if err != nil {
+			for _, opened := range sinks {
+				_ = opened.Close()
 			}
+			return nil, err
 		}
+		sinks = append(sinks, s)
 	}
-}
-
-func applyEVAndMaybeOpen(
-	sig *model.Signal,
-	evCalc *ev.Calculator,
-	exec *paper.Executor,
-	signalsWriter *jsonl.Writer,
-	paperWriter *jsonl.Writer,
-	logger *zap.Logger,
-) {
-	if sig == nil {
-		return
-	}
-
-	// EV 拒绝：当 EV<0，标记信号但不执行影子成交
-	evStats := evCalc.Stats()
-	ev.ApplyRejection(sig, evStats)
-
-	if signalsWriter != nil {
-		_ = signalsWriter.Write(sig)
-	}
-
-	if sig.RejectedByEV {
-		return
-	}
-
-	if _, opened, err := exec.TryOpen(sig); err != nil {
-		logger.Warn("TryOpen 失败", zap.Error(err), zap.String("leader", sig.Leader), zap.String("symbol", sig.SymbolCanon))
-		return
-	} else if opened {
-		// 开仓成功后立即不输出 paper trade；平仓时输出
-		return
-	}
-
-	_ = paperWriter // 避免未使用（后续可能扩展为开仓事件输出）
+	return sink.New(sinks...), nil
 }
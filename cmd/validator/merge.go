@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"latency-arbitrage-validator/internal/output/jsonl"
+	"latency-arbitrage-validator/internal/output/manifest"
+	"latency-arbitrage-validator/internal/stats/latencysample"
+	"latency-arbitrage-validator/internal/stats/vantage"
+)
+
+// runMerge 合并多个地域节点的输出目录，按（Leader, 交易对, 时间桶）对齐时延样本，
+// 比较哪个节点观测到的 Bittap 滞后最小
+// 各节点需启用 output.latency_samples_enabled；节点标识取 manifest.json 的 node_id（未配置时用目录名）。
+// 返回进程退出码。
+func runMerge(args []string) int {
+	fset := flag.NewFlagSet("merge", flag.ExitOnError)
+	bucketMs := fset.Int64("bucket-ms", 60000, "对齐时间桶长度（毫秒）")
+	metric := fset.String("metric", "arrived", "比较的时延口径: arrived（Follower 到达 - Leader 到达）/ event（Follower 到达 - Leader 交易所时间戳）")
+	outPath := fset.String("out", "", "对齐时间桶 JSONL 输出路径（可选）")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "用法: validator merge [flags] <输出目录> <输出目录> ...")
+		fset.PrintDefaults()
+	}
+	_ = fset.Parse(args)
+
+	dirs := fset.Args()
+	if len(dirs) < 2 {
+		fmt.Fprintln(os.Stderr, "至少需要两个节点的输出目录")
+		fset.Usage()
+		return 2
+	}
+	if *metric != "arrived" && *metric != "event" {
+		fmt.Fprintf(os.Stderr, "未知时延口径: %s（可选 arrived/event）\n", *metric)
+		return 2
+	}
+
+	aligner := vantage.NewAligner(*bucketMs)
+	seen := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		node, err := nodeID(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "读取节点信息失败: %v\n", err)
+			return 1
+		}
+		if prev, ok := seen[node]; ok {
+			fmt.Fprintf(os.Stderr, "节点标识重复: %s（%s 与 %s），请为各节点配置不同的 app.node_id\n", node, prev, dir)
+			return 1
+		}
+		seen[node] = dir
+		aligner.AddNode(node)
+
+		n, err := readNodeSamples(aligner, node, filepath.Join(dir, "latency_samples.jsonl"), *metric == "event")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "读取 %s 时延样本失败: %v\n", dir, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "节点 %s: %s（%d 条样本）\n", node, dir, n)
+	}
+
+	buckets := aligner.Buckets()
+	if len(buckets) == 0 {
+		fmt.Fprintln(os.Stderr, "没有全部节点都有样本的时间桶（检查各节点运行时段是否重叠）")
+		return 1
+	}
+	if err := writeMergeReport(os.Stdout, aligner.Summaries(), *metric); err != nil {
+		fmt.Fprintf(os.Stderr, "输出结果表失败: %v\n", err)
+		return 1
+	}
+
+	if *outPath != "" {
+		w, err := jsonl.NewWriter(*outPath, len(buckets)+1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建结果 writer 失败: %v\n", err)
+			return 1
+		}
+		for i := range buckets {
+			_ = w.Write(&buckets[i])
+		}
+		if err := w.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "写入结果失败: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// nodeID 读取输出目录的节点标识（manifest.json 的 node_id，缺失时用目录名）
+func nodeID(dir string) (string, error) {
+	m, err := manifest.Read(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return filepath.Base(filepath.Clean(dir)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if m.NodeID != "" {
+		return m.NodeID, nil
+	}
+	return filepath.Base(filepath.Clean(dir)), nil
+}
+
+// readNodeSamples 读取一个节点的 latency_samples.jsonl 并加入对齐器
+// 参数 event: true 使用事件时延（Leader 无交易所时间戳的样本跳过），false 使用到达时延
+// 返回: 读取的样本数
+func readNodeSamples(a *vantage.Aligner, node, path string, event bool) (int, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("%s 不存在（需启用 output.latency_samples_enabled）", path)
+	}
+	n := 0
+	err := jsonl.ForEach(path, func(s *latencysample.Sample) error {
+		lag := s.LagArrivedMs
+		if event {
+			if s.LagEventMs == 0 {
+				return nil
+			}
+			lag = s.LagEventMs
+		}
+		a.Add(node, s.Leader, s.SymbolCanon, s.TsNs, lag)
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// writeMergeReport 输出各节点的对比表
+// best_share 为该节点时延中位数最小的对齐时间桶占比；symbol 为 * 的行汇总该 Leader 下全部交易对。
+func writeMergeReport(w io.Writer, sums []vantage.Summary, metric string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "leader\tsymbol\tnode\tbuckets\tsamples\tp50_%s_ms\tp90_%s_ms\tbest\tbest_share\t\n", metric, metric)
+	for _, s := range sums {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%.2f\t%.2f\t%d\t%.3f\t\n",
+			s.Leader, s.Symbol, s.Node, s.Buckets, s.Samples, s.P50Ms, s.P90Ms, s.Best, s.BestShare)
+	}
+	return tw.Flush()
+}
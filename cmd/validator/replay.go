@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	ossignal "os/signal"
+	"path/filepath"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/blackout"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/core/store"
+	"latency-arbitrage-validator/internal/output/jsonl"
+	"latency-arbitrage-validator/internal/output/manifest"
+	"latency-arbitrage-validator/internal/output/sink"
+	"latency-arbitrage-validator/internal/replay"
+	"latency-arbitrage-validator/internal/stats/latency"
+	"latency-arbitrage-validator/internal/stats/pipeline"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// runReplay 回放录制的 books.jsonl，驱动完整聚合器链路并输出 signals/paper_trades/metrics（及启用时的 spreads/bars/latency_samples/staleness）
+// 业务时间由虚拟时钟按事件到达时间推进，同一份录制数据的输出可确定性复现。
+// 返回进程退出码。
+func runReplay(args []string) int {
+	fs, cf := newFlagSet("replay")
+	booksPath := fs.String("books", "", "录制的 books.jsonl 路径（默认最近一次运行目录下的 books.jsonl）")
+	mode := fs.String("mode", replay.ModeMax, "回放节奏: max / realtime / accelerated / step")
+	speed := fs.Float64("speed", 10, "加速倍数（仅 accelerated）")
+	outDir := fs.String("out", "", "输出目录（默认 <output.dir>/replay）")
+	_ = fs.Parse(args)
+
+	cfg, err := cf.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+	if *booksPath == "" {
+		*booksPath = filepath.Join(defaultRunDir(cfg), "books.jsonl")
+	}
+	if *outDir == "" {
+		*outDir = filepath.Join(cfg.Output.Dir, "replay")
+	}
+
+	player, err := replay.NewPlayer(*mode, *speed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建回放驱动失败: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := ossignal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// 单步模式：每读到一行标准输入推进一个事件
+	if player.Mode == replay.ModeStep {
+		step := make(chan struct{})
+		player.Step = step
+		go func() {
+			defer close(step)
+			sc := bufio.NewScanner(os.Stdin)
+			for sc.Scan() {
+				select {
+				case step <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "创建输出目录失败: %v\n", err)
+		return 1
+	}
+	runManifest, err := manifest.New("replay", cf.configPath, cfg, nil, outputSchemaVersions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建运行清单失败: %v\n", err)
+		return 1
+	}
+	if err := runManifest.Write(*outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "写入运行清单失败: %v\n", err)
+		return 1
+	}
+	defer func() {
+		runManifest.Stop()
+		_ = runManifest.Write(*outDir)
+	}()
+
+	names := []string{"signals", "paper_trades", "metrics"}
+	if cfg.Output.SpreadsEnabled {
+		names = append(names, "spreads")
+	}
+	if cfg.Output.BarsEnabled {
+		names = append(names, "bars")
+	}
+	if cfg.Output.LatencySamplesEnabled {
+		names = append(names, "latency_samples")
+	}
+	if cfg.Output.StalenessEnabled {
+		names = append(names, "staleness")
+	}
+	writers := make(map[string]*jsonl.Writer, len(names))
+	for _, name := range names {
+		w, err := jsonl.NewWriter(filepath.Join(*outDir, name+".jsonl"), cfg.Output.BufferSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建 %s writer 失败: %v\n", name, err)
+			return 1
+		}
+		defer w.Close()
+		writers[name] = w
+	}
+
+	outputs := make(map[string]sink.Sink, len(writers))
+	for name, w := range writers {
+		outputs[name] = w
+	}
+	agg := newReplayAggregator(cfg, player.Clock, outputs)
+
+	fmt.Fprintf(os.Stderr, "回放 %s（%s）-> %s\n", *booksPath, player.Mode, *outDir)
+
+	var onEvent func(n int64, ev *model.BookEvent)
+	if player.Mode == replay.ModeStep {
+		onEvent = func(n int64, ev *model.BookEvent) {
+			fmt.Fprintf(os.Stderr, "#%d %s %s bid=%v ask=%v arrived=%d\n",
+				n, ev.Exchange, ev.SymbolCanon, ev.BestBidPx, ev.BestAskPx, ev.ArrivedAtUnixNs)
+		}
+	}
+	events, err := playReplay(ctx, agg, player, bookSource(cfg, *booksPath), onEvent)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "回放失败: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "回放结束：%d 个事件\n", events)
+	return 0
+}
+
+// newReplayAggregator 创建离线回放用的聚合器（业务时间由虚拟时钟推进）
+// 参数 outputs: 输出流（键为流名）；signals/paper_trades/metrics 缺失时不输出，其余流存在时启用对应功能
+func newReplayAggregator(cfg *config.Config, clock timeutil.Clock, outputs map[string]sink.Sink) *aggregator {
+	latTracker := latency.NewTracker(10000)
+	latTracker.EnableTimeWindow(cfg.Latency.WindowMs)
+	latTracker.EnableSpikeDetection(cfg.Latency)
+	latTracker.EnableLeaderComparison(cfg.Latency.LeaderMatchWindowMs)
+	latTracker.EnableLeadShare(cfg.Latency.LeadShareMinMoveBps, cfg.Latency.LeadShareWindowMs)
+	spikeCheckIntervalMs := 0
+	if cfg.Latency.SpikeFactor > 0 {
+		spikeCheckIntervalMs = cfg.Latency.SpikeCheckIntervalMs
+	}
+
+	agg := &aggregator{
+		logger:            zap.NewNop(),
+		bookStore:         store.New(),
+		latTracker:        latTracker,
+		pipeTimer:         pipeline.NewTracker(),
+		pipelines:         buildPipelines(cfg),
+		signalsWriter:     outputs["signals"],
+		signalsBBOOnly:    cfg.Output.SignalsBBOOnly,
+		paperWriter:       outputs["paper_trades"],
+		metricsWriter:     outputs["metrics"],
+		metricsIntervalMs: cfg.Output.MetricsIntervalMs,
+		clock:             clock,
+
+		spikeCheckIntervalMs: spikeCheckIntervalMs,
+	}
+	if cfg.Paper.SlippageMode == config.SlippageModeSpread {
+		agg.useSpreadSlippage(cfg.Paper.SpreadWindow)
+	}
+	if cfg.Paper.VolScaleRef > 0 {
+		agg.useVolScaling(cfg.Paper.VolScaleWindowMs)
+	}
+	if cal := blackout.New(cfg.Blackout); cal != nil {
+		agg.useBlackout(cal)
+	}
+	if w := outputs["spreads"]; w != nil {
+		agg.useSpreadSampling(w, cfg.Output.SpreadsIntervalMs)
+	}
+	if w := outputs["latency_samples"]; w != nil {
+		agg.useLatencySampling(w, cfg.Output.LatencySampleEvery)
+	}
+	if w := outputs["bars"]; w != nil {
+		agg.useBars(w, cfg.Output.BarIntervalsMs)
+	}
+	if w := outputs["staleness"]; w != nil {
+		agg.useStaleness(w, cfg.Output.StalenessIntervalMs)
+	}
+	if cfg.Chaos.Enabled {
+		agg.useChaos(cfg.Chaos)
+	}
+	return agg
+}
+
+// playReplay 驱动回放：逐个事件推进聚合器，按业务时间输出指标与检查时延突增；
+// 结束后按最后报价平掉未平仓仓位（与实时运行的停机处理一致）并输出最后一条指标快照
+// 参数 onEvent: 每个事件处理后调用（可为 nil）
+// 返回: 处理的事件数（被信号中断时不视为错误）
+func playReplay(ctx context.Context, agg *aggregator, player *replay.Player, src replay.Source, onEvent func(n int64, ev *model.BookEvent)) (int64, error) {
+	var events int64
+	var lastSpikeAt int64
+	started := false
+	err := player.Source(ctx, src)(func(ev *model.BookEvent) error {
+		// 首个事件到达后以其时间作为指标周期起点
+		if !started {
+			agg.resetCounters()
+			lastSpikeAt = agg.now()
+			started = true
+		}
+		agg.handleReplayEvent(ev)
+		events++
+
+		nowNs := agg.now()
+		if agg.metricsIntervalMs > 0 && nowNs-agg.lastMetricsAt >= int64(agg.metricsIntervalMs)*1e6 {
+			agg.writeMetrics()
+		}
+		if agg.spikeCheckIntervalMs > 0 && nowNs-lastSpikeAt >= int64(agg.spikeCheckIntervalMs)*1e6 {
+			agg.checkLatencySpikes()
+			lastSpikeAt = nowNs
+		}
+		if onEvent != nil {
+			onEvent(events, ev)
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return events, err
+	}
+
+	agg.closeOpenPositions(model.ExitShutdown)
+	agg.flushBars()
+	agg.flushStaleness()
+	if agg.metricsWriter != nil {
+		_ = agg.metricsWriter.Write(agg.snapshot(agg.now(), nil))
+	}
+	return events, nil
+}
+
+// bookSource 创建录制文件事件源；启用合成 Follower 校准模式时以 Leader 行情合成 Bittap 事件
+func bookSource(cfg *config.Config, path string) replay.Source {
+	src := replay.FileSource(path)
+	if !cfg.Synthetic.Enabled {
+		return src
+	}
+	fmt.Fprintf(os.Stderr, "合成 Follower 校准模式：leader=%s lag=%dms spread=%.2fbps（录制中的 Bittap 行情被忽略）\n",
+		cfg.Synthetic.Leader, cfg.Synthetic.LagMs, cfg.Synthetic.SpreadBps)
+	return replay.SyntheticFollower(src, cfg.Synthetic.Leader, cfg.Synthetic.LagMs, cfg.Synthetic.SpreadBps)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/output/jsonl"
+	"latency-arbitrage-validator/internal/stats/equity"
+	"latency-arbitrage-validator/internal/stats/ev"
+	"latency-arbitrage-validator/internal/stats/latency"
+	"latency-arbitrage-validator/internal/stats/significance"
+)
+
+// reportRow 单条链路（变体 + Leader）的影子成交汇总
+type reportRow struct {
+	variant string
+	leader  string
+	wins    int64
+	curve   *equity.Curve
+	// nets 每笔净利（基点，EV 显著性检验用）
+	nets []float64
+	// hourly 按入场 UTC 小时分桶的 EV
+	hourly *ev.HourOfDay
+}
+
+// runReport 汇总输出目录中的 paper_trades.jsonl，按变体与 Leader 输出结果表
+// 返回进程退出码。
+func runReport(args []string) int {
+	fs, cf := newFlagSet("report")
+	dir := fs.String("dir", "", "输出目录（默认最近一次运行目录）")
+	byHour := fs.Bool("by-hour", false, "追加按 UTC 小时分桶的 EV 与时延（时延取自 metrics.jsonl 最后一条快照）")
+	_ = fs.Parse(args)
+
+	cfg, err := cf.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+	if *dir == "" {
+		*dir = defaultRunDir(cfg)
+	}
+
+	rows, err := readPaperTrades(filepath.Join(*dir, "paper_trades.jsonl"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取影子成交失败: %v\n", err)
+		return 1
+	}
+	if err := writeReport(os.Stdout, rows); err != nil {
+		fmt.Fprintf(os.Stderr, "输出报告失败: %v\n", err)
+		return 1
+	}
+	if *byHour {
+		latByHour, err := readLatencyByHour(filepath.Join(*dir, "metrics.jsonl"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "读取指标失败: %v\n", err)
+			return 1
+		}
+		if err := writeHourlyReport(os.Stdout, rows, latByHour); err != nil {
+			fmt.Fprintf(os.Stderr, "输出报告失败: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// readPaperTrades 读取影子成交并按（变体, Leader）累计
+func readPaperTrades(path string) ([]*reportRow, error) {
+	byKey := make(map[[2]string]*reportRow)
+	err := jsonl.ForEach(path, func(t *model.PaperTrade) error {
+		key := [2]string{t.Variant, t.Leader}
+		r := byKey[key]
+		if r == nil {
+			r = &reportRow{variant: t.Variant, leader: t.Leader, curve: equity.NewCurve(), hourly: ev.NewHourOfDay()}
+			byKey[key] = r
+		}
+		if t.NetPnLBps > 0 {
+			r.wins++
+		}
+		r.curve.Add(&model.Position{Closed: true, NetPnLBps: t.NetPnLBps})
+		r.nets = append(r.nets, t.NetPnLBps)
+		r.hourly.AddTrade(t.TEntryNs, t.GrossPnLBps, t.FeeBps+t.BorrowCostBps, t.NetPnLBps)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*reportRow, 0, len(byKey))
+	for _, r := range byKey {
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].variant != rows[j].variant {
+			return rows[i].variant < rows[j].variant
+		}
+		return rows[i].leader < rows[j].leader
+	})
+	return rows, nil
+}
+
+// writeReport 输出对齐的结果表
+// p_ttest/p_boot 为 EV > 0 的单侧 t 检验与 bootstrap p 值（越小越显著，笔数少时应偏大）。
+func writeReport(w io.Writer, rows []*reportRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "variant\tleader\ttrades\twin_rate\tavg_net_bps\ttotal_net_bps\tmax_dd_bps\tprofit_factor\tt_stat\tp_ttest\tp_boot\t")
+	for _, r := range rows {
+		s := r.curve.Stats()
+		var winRate, avg float64
+		if s.Trades > 0 {
+			winRate = float64(r.wins) / float64(s.Trades)
+			avg = s.CumNetBps / float64(s.Trades)
+		}
+		variant := r.variant
+		if variant == "" {
+			variant = "-"
+		}
+		sig := significance.Test(r.nets, significance.DefaultBootstrapIterations, 1)
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.3f\t%.3f\t%.2f\t%.2f\t%.2f\t%.2f\t%.4f\t%.4f\t\n",
+			variant, r.leader, s.Trades, winRate, avg, s.CumNetBps, s.MaxDrawdownBps, s.ProfitFactor, sig.TStat, sig.PValueT, sig.PValueBootstrap)
+	}
+	return tw.Flush()
+}
+
+// readLatencyByHour 读取 metrics.jsonl 最后一条快照中的分小时时延
+// 文件不存在时返回 nil。
+func readLatencyByHour(path string) (map[string][]latency.HourLatencyStats, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	var last map[string][]latency.HourLatencyStats
+	err := jsonl.ForEach(path, func(m *struct {
+		LatencyByHour map[string][]latency.HourLatencyStats `json:"latency_by_hour"`
+	}) error {
+		if m.LatencyByHour != nil {
+			last = m.LatencyByHour
+		}
+		return nil
+	})
+	return last, err
+}
+
+// writeHourlyReport 输出按 UTC 小时分桶的 EV（各链路）与到达时延（各 Leader）
+func writeHourlyReport(w io.Writer, rows []*reportRow, latByHour map[string][]latency.HourLatencyStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "variant\tleader\thour_utc\ttrades\twin_rate\tavg_net_bps\tev_bps\t")
+	for _, r := range rows {
+		variant := r.variant
+		if variant == "" {
+			variant = "-"
+		}
+		for _, h := range r.hourly.Stats() {
+			fmt.Fprintf(tw, "%s\t%s\t%02d\t%d\t%.3f\t%.3f\t%.3f\t\n",
+				variant, r.leader, h.Hour, h.Count, h.WinRate, h.AvgNetBps, h.EV)
+		}
+	}
+
+	if len(latByHour) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "leader\thour_utc\tsamples\tp50_ms\tp90_ms\tp99_ms\t")
+		for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+			for _, h := range latByHour[leader] {
+				fmt.Fprintf(tw, "%s\t%02d\t%d\t%.2f\t%.2f\t%.2f\t\n",
+					leader, h.Hour, h.Count, h.P50Ms, h.P90Ms, h.P99Ms)
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+// latestRunLink 指向最近一次运行目录的符号链接名（位于 output.dir 下）
+const latestRunLink = "latest"
+
+// newRunID 生成运行 ID：<UTC 时间>-<6 位随机十六进制>，字典序即时间序
+func newRunID(now time.Time) string {
+	var b [3]byte
+	_, _ = rand.Read(b[:])
+	return now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b[:])
+}
+
+// prepareRunDir 为本次运行创建独立输出目录 <output.dir>/<run_id>/ 并更新 latest 链接
+// output.legacy_layout 时直接返回 output.dir（多次运行追加写入同一组文件）。
+// 返回: (输出目录, 运行 ID；旧布局时为空)
+func prepareRunDir(cfg *config.Config) (string, string, error) {
+	if cfg.Output.LegacyLayout {
+		return cfg.Output.Dir, "", nil
+	}
+	runID := newRunID(time.Now())
+	dir := filepath.Join(cfg.Output.Dir, runID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", fmt.Errorf("创建运行目录失败: %w", err)
+	}
+	// latest 链接仅为便利（看板、report 默认读取），创建失败（如 Windows 无权限）不影响运行
+	link := filepath.Join(cfg.Output.Dir, latestRunLink)
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(runID, tmp); err == nil {
+		if err := os.Rename(tmp, link); err != nil {
+			_ = os.Remove(tmp)
+		}
+	}
+	return dir, runID, nil
+}
+
+// defaultRunDir 离线命令的默认输入目录：output.dir/latest 存在时使用最近一次运行目录，否则为 output.dir
+func defaultRunDir(cfg *config.Config) string {
+	if !cfg.Output.LegacyLayout {
+		latest := filepath.Join(cfg.Output.Dir, latestRunLink)
+		if fi, err := os.Stat(latest); err == nil && fi.IsDir() {
+			return latest
+		}
+	}
+	return cfg.Output.Dir
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	ossignal "os/signal"
+	"path/filepath"
+	"syscall"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/output/jsonl"
+	"latency-arbitrage-validator/internal/output/sink"
+	"latency-arbitrage-validator/internal/replay"
+)
+
+// runVerify 将录制的 books.jsonl 全速回放到全新的聚合器与信号引擎，比对重算信号与录制的 signals.jsonl
+// 用于发现非确定性（同一录制重复回放结果不同）与代码改动后的信号回归；比对对象可以是实时运行或回放的输出目录。
+// 需使用与录制时相同的配置；存在差异时返回退出码 1。
+// 返回进程退出码。
+func runVerify(args []string) int {
+	fs, cf := newFlagSet("verify")
+	dir := fs.String("dir", "", "输出目录（默认最近一次运行目录），读取其中的 books.jsonl 与 signals.jsonl")
+	booksPath := fs.String("books", "", "录制的 books.jsonl 路径（默认 <dir>/books.jsonl）")
+	signalsPath := fs.String("signals", "", "录制的 signals.jsonl 路径（默认 <dir>/signals.jsonl）")
+	tol := fs.Float64("tol", 1e-9, "浮点字段允许的相对误差")
+	maxShow := fs.Int("max-show", 20, "每类差异最多打印的条数")
+	_ = fs.Parse(args)
+
+	cfg, err := cf.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+	if *dir == "" {
+		*dir = defaultRunDir(cfg)
+	}
+	if *booksPath == "" {
+		*booksPath = filepath.Join(*dir, "books.jsonl")
+	}
+	if *signalsPath == "" {
+		*signalsPath = filepath.Join(*dir, "signals.jsonl")
+	}
+
+	var recorded []*model.Signal
+	if err := jsonl.ForEach(*signalsPath, func(s *model.Signal) error {
+		recorded = append(recorded, s)
+		return nil
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "读取录制信号失败: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := ossignal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	player, err := replay.NewPlayer(replay.ModeMax, 1)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建回放驱动失败: %v\n", err)
+		return 1
+	}
+	capture := &signalCapture{}
+	agg := newReplayAggregator(cfg, player.Clock, map[string]sink.Sink{"signals": capture})
+	events, err := playReplay(ctx, agg, player, bookSource(cfg, *booksPath), nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "回放失败: %v\n", err)
+		return 1
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "已中断")
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "重算 %s：%d 个事件\n", *booksPath, events)
+
+	diff := replay.DiffSignals(recorded, capture.signals, *tol)
+	writeSignalDiff(os.Stdout, diff, *maxShow)
+	if !diff.OK() {
+		return 1
+	}
+	return 0
+}
+
+// signalCapture 在内存中收集重算信号（实现 sink.Sink）
+// 只保留最优价与时间戳，与 signals_bbo_only 的录制格式一致。
+type signalCapture struct {
+	signals []*model.Signal
+}
+
+func (c *signalCapture) Write(v any) error {
+	if s, ok := v.(*model.Signal); ok {
+		c.signals = append(c.signals, s.WithoutDepth())
+	}
+	return nil
+}
+
+func (c *signalCapture) Flush() error { return nil }
+
+func (c *signalCapture) Close() error { return nil }
+
+// writeSignalDiff 输出比对结果与各类差异的前 maxShow 条
+func writeSignalDiff(w io.Writer, d *replay.SignalDiff, maxShow int) {
+	fmt.Fprintf(w, "录制 %d 条，重算 %d 条，匹配 %d 条；缺失 %d，多出 %d，字段不一致 %d\n",
+		d.Recorded, d.Recomputed, d.Matched, len(d.Missing), len(d.Extra), len(d.Mismatches))
+	if d.OK() {
+		fmt.Fprintln(w, "一致")
+		return
+	}
+	show := func(title string, sigs []*model.Signal) {
+		if len(sigs) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n%s:\n", title)
+		for i, s := range sigs {
+			if i >= maxShow {
+				fmt.Fprintf(w, "  ...（其余 %d 条）\n", len(sigs)-maxShow)
+				break
+			}
+			fmt.Fprintf(w, "  %s variant=%q spread_bps=%.4f filter=%q\n", s.ID, s.Variant, s.SpreadBps, s.FilterReason)
+		}
+	}
+	show("录制中有、重算中没有", d.Missing)
+	show("重算中有、录制中没有", d.Extra)
+	if len(d.Mismatches) > 0 {
+		fmt.Fprintln(w, "\n字段不一致:")
+		for i, m := range d.Mismatches {
+			if i >= maxShow {
+				fmt.Fprintf(w, "  ...（其余 %d 条）\n", len(d.Mismatches)-maxShow)
+				break
+			}
+			fmt.Fprintf(w, "  %s %s: 录制 %s，重算 %s\n", m.ID, m.Field, m.Recorded, m.Recomputed)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	ossignal "os/signal"
+	"path/filepath"
+	"syscall"
+
+	"latency-arbitrage-validator/internal/backtest"
+	"latency-arbitrage-validator/internal/output/jsonl"
+)
+
+// runWalkForward 滚动前推评估：训练段择优参数，测试段报告样本外 EV
+// 返回进程退出码。
+func runWalkForward(args []string) int {
+	fs, cf := newFlagSet("walkforward")
+	booksPath := fs.String("books", "", "录制的 books.jsonl 路径（默认最近一次运行目录下的 books.jsonl）")
+	workers := fs.Int("workers", 0, "并行 goroutine 数（0 表示沿用 backtest.workers）")
+	trainMs := fs.Int64("train-ms", 0, "训练段长度（毫秒，0 表示沿用 backtest.walkforward.train_ms）")
+	testMs := fs.Int64("test-ms", 0, "测试段长度（毫秒，0 表示沿用 backtest.walkforward.test_ms）")
+	outPath := fs.String("out", "", "窗口结果 JSONL 输出路径（可选）")
+	_ = fs.Parse(args)
+
+	cfg, err := cf.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+	if *booksPath == "" {
+		*booksPath = filepath.Join(defaultRunDir(cfg), "books.jsonl")
+	}
+	if *workers <= 0 {
+		*workers = cfg.Backtest.Workers
+	}
+	if *trainMs > 0 {
+		cfg.Backtest.WalkForward.TrainMs = *trainMs
+	}
+	if *testMs > 0 {
+		cfg.Backtest.WalkForward.TestMs = *testMs
+	}
+
+	ctx, cancel := ossignal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	combos := backtest.Combos(cfg, cfg.Backtest.Grid)
+	fmt.Fprintf(os.Stderr, "walk-forward %s：%d 组参数，训练 %dms / 测试 %dms\n",
+		*booksPath, len(combos), cfg.Backtest.WalkForward.TrainMs, cfg.Backtest.WalkForward.TestMs)
+
+	res, err := backtest.WalkForward(ctx, bookSource(cfg, *booksPath), cfg, combos, *workers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "walk-forward 失败: %v\n", err)
+		return 1
+	}
+
+	if err := backtest.WriteWalkForwardTable(os.Stdout, res); err != nil {
+		fmt.Fprintf(os.Stderr, "输出结果表失败: %v\n", err)
+		return 1
+	}
+
+	if *outPath != "" {
+		w, err := jsonl.NewWriter(*outPath, len(res.Windows)+1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建结果 writer 失败: %v\n", err)
+			return 1
+		}
+		for _, win := range res.Windows {
+			_ = w.Write(win)
+		}
+		if err := w.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "写入结果失败: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -17,8 +17,11 @@ CORS(app)
 OUTPUT_DIR = os.environ.get('OUTPUT_DIR', '/opt/latency-validator/output')
 
 def load_jsonl(filename):
-    """加载 JSONL 文件"""
-    filepath = Path(OUTPUT_DIR) / filename
+    """加载 JSONL 文件（验证器按运行分目录时读取 latest 指向的最近一次运行）"""
+    base = Path(OUTPUT_DIR)
+    if (base / 'latest').is_dir():
+        base = base / 'latest'
+    filepath = base / filename
     records = []
     if not filepath.exists():
         return records
@@ -46,6 +49,8 @@ def status():
     latest = metrics[-1]
     return jsonify({
         'timestamp': latest.get('ts_unix_ns', 0) / 1e9,
+        # 产生该数据的程序构建信息（version/commit/build_time）
+        'build': latest.get('build', {}),
         'connections': {
             'okx': latest.get('okx', {}),
             'binance': latest.get('binance', {}),
@@ -59,7 +64,9 @@ def status():
             'okx': latest.get('ev_okx', {}),
             'binance': latest.get('ev_binance', {})
         },
-        'updates_per_sec': latest.get('updates_per_sec', [])
+        'updates_per_sec': latest.get('updates_per_sec', []),
+        # 未平仓仓位心跳（入场/当前价差、浮动净利、持仓时长）
+        'open_positions': latest.get('open_positions', [])
     })
 
 @app.route('/api/metrics')
@@ -114,6 +121,7 @@ def summary():
     total_pnl = okx_pnl + binance_pnl
     
     return jsonify({
+        'build': latest.get('build', {}),
         'uptime_seconds': len(metrics) * 10,
         'total_signals': len(signals),
         'total_trades': total_trades,
@@ -138,6 +146,11 @@ def summary():
         'ev': {
             'okx': ev_okx,
             'binance': ev_binance
+        },
+        # 权益曲线：累计净利、最大回撤、最长连亏、盈亏比（会话累计）
+        'equity': {
+            'okx': latest.get('equity_okx', {}),
+            'binance': latest.get('equity_binance', {})
         }
     })
 
@@ -113,7 +113,7 @@ function Deploy-Quick {
 
 function Show-Status {
     Write-Host "=== 服务状态 ===" -ForegroundColor Cyan
-    gcloud compute ssh $Instance --zone=$Zone --command="sudo systemctl status latency-validator --no-pager && echo '' && echo '=== 输出文件 ===' && ls -lh /opt/latency-validator/output/ && echo '' && echo '=== 最新 metrics ===' && tail -1 /opt/latency-validator/output/metrics.jsonl | python3 -c 'import sys,json; d=json.load(sys.stdin); print(f\"Trades: OKX={d[\"\"ev_okx\"\"][\"\"Count\"\"]}, Binance={d[\"\"ev_binance\"\"][\"\"Count\"\"]}\")'  2>/dev/null || echo 'No metrics yet'"
+    gcloud compute ssh $Instance --zone=$Zone --command="sudo systemctl status latency-validator --no-pager && echo '' && echo '=== 输出文件 ===' && ls -lh /opt/latency-validator/output/ && echo '' && echo '=== 最新 metrics ===' && tail -1 /opt/latency-validator/output/latest/metrics.jsonl | python3 -c 'import sys,json; d=json.load(sys.stdin); print(f\"Trades: OKX={d[\"\"ev_okx\"\"][\"\"Count\"\"]}, Binance={d[\"\"ev_binance\"\"][\"\"Count\"\"]}\")'  2>/dev/null || echo 'No metrics yet'"
 }
 
 function Show-Logs {
@@ -135,7 +135,7 @@ function Download-Output {
     Write-Host "=== 下载输出文件 ===" -ForegroundColor Cyan
     $LocalOutput = Join-Path $ProjectDir "output_download"
     New-Item -ItemType Directory -Force -Path $LocalOutput | Out-Null
-    gcloud compute scp "${Instance}:/opt/latency-validator/output/*.jsonl" $LocalOutput --zone=$Zone
+    gcloud compute scp "${Instance}:/opt/latency-validator/output/latest/*.jsonl" $LocalOutput --zone=$Zone
     Write-Host "下载完成: $LocalOutput" -ForegroundColor Green
 }
 
@@ -22,7 +22,13 @@ echo "本地项目: ${LOCAL_PROJECT_DIR}"
 echo ""
 echo "[1/4] 交叉编译 Linux amd64 二进制..."
 cd "${LOCAL_PROJECT_DIR}"
-GOOS=linux GOARCH=amd64 CGO_ENABLED=0 go build -ldflags="-s -w" -o ./deploy/validator ./cmd/validator
+# 注入构建信息（commit/构建时间），运行日志与 metrics.jsonl 中可追溯
+BUILDINFO_PKG="latency-arbitrage-validator/internal/buildinfo"
+GIT_COMMIT="$(git rev-parse HEAD 2>/dev/null || echo unknown)"
+BUILD_TIME="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+GOOS=linux GOARCH=amd64 CGO_ENABLED=0 go build \
+    -ldflags="-s -w -X ${BUILDINFO_PKG}.Commit=${GIT_COMMIT} -X ${BUILDINFO_PKG}.BuildTime=${BUILD_TIME}" \
+    -o ./deploy/validator ./cmd/validator
 
 if [ ! -f "./deploy/validator" ]; then
     echo "编译失败！"
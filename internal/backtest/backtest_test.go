@@ -0,0 +1,162 @@
+// Package backtest 回测模块测试
+package backtest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/replay"
+)
+
+// convergingEvents 构造一段 OKX 领先、Bittap 随后收敛的行情
+// 多头价差约 10bps，200ms 后 Bittap 追上。
+func convergingEvents() []*model.BookEvent {
+	const t0 = int64(1_000_000_000)
+	return []*model.BookEvent{
+		{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, ArrivedAtUnixNs: t0},
+		{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, ArrivedAtUnixNs: t0 + 1_000_000},
+		{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, ArrivedAtUnixNs: t0 + 200_000_000},
+	}
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Strategy: config.StrategyConfig{ThetaEntryBps: 5, PersistMs: 0},
+		Paper:    config.PaperConfig{TPRatio: 0.5, SLRatio: 1.0, MaxHoldMs: 60000},
+	}
+}
+
+func TestRun_TakeProfit(t *testing.T) {
+	cfg := testConfig()
+	res, err := Run(context.Background(), replay.SliceSource(convergingEvents()), cfg.Strategy, cfg.Paper, config.FeesConfig{}, config.EVConfig{})
+	if err != nil {
+		t.Fatalf("Run err=%v", err)
+	}
+	if res.Events != 3 {
+		t.Fatalf("Events=%d, want 3", res.Events)
+	}
+	if res.Signals != 1 || res.Trades != 1 || res.Wins != 1 {
+		t.Fatalf("Signals=%d Trades=%d Wins=%d, want 1/1/1", res.Signals, res.Trades, res.Wins)
+	}
+	if res.AvgNetBps <= 0 {
+		t.Fatalf("AvgNetBps=%f, want >0", res.AvgNetBps)
+	}
+	if res.EVOKX.Count != 1 || res.EVBinance.Count != 0 {
+		t.Fatalf("EV 链路统计错误: okx=%d binance=%d", res.EVOKX.Count, res.EVBinance.Count)
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	events := make([]*model.BookEvent, 0, 2*ctxCheckEvery)
+	for i := 0; i < 2*ctxCheckEvery; i++ {
+		events = append(events, &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100, BestAskPx: 100.01, ArrivedAtUnixNs: int64(i+1) * 1_000_000})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg := testConfig()
+	if _, err := Run(ctx, replay.SliceSource(events), cfg.Strategy, cfg.Paper, config.FeesConfig{}, config.EVConfig{}); err != context.Canceled {
+		t.Fatalf("err=%v, want context.Canceled", err)
+	}
+}
+
+func TestCombos(t *testing.T) {
+	cfg := testConfig()
+	combos := Combos(cfg, config.GridConfig{
+		ThetaEntryBps: []float64{5, 10, 20},
+		SLRatio:       []float64{0.5, 1.0},
+	})
+	if len(combos) != 6 {
+		t.Fatalf("len(combos)=%d, want 6", len(combos))
+	}
+	for _, c := range combos {
+		if c.PersistMs != cfg.Strategy.PersistMs || c.TPRatio != cfg.Paper.TPRatio {
+			t.Fatalf("空维度应沿用基础配置: %+v", c)
+		}
+	}
+}
+
+func TestSweep_RankAndTable(t *testing.T) {
+	cfg := testConfig()
+	combos := Combos(cfg, config.GridConfig{ThetaEntryBps: []float64{1000, 5}})
+
+	results, err := Sweep(context.Background(), replay.SliceSource(convergingEvents()), cfg, combos, 2)
+	if err != nil {
+		t.Fatalf("Sweep err=%v", err)
+	}
+	Rank(results)
+	if results[0].Params.ThetaEntryBps != 5 || results[0].Trades != 1 {
+		t.Fatalf("排名第一应为 theta=5: %+v", results[0])
+	}
+	if results[1].Trades != 0 {
+		t.Fatalf("theta=1000 不应成交: %+v", results[1])
+	}
+
+	var sb strings.Builder
+	if err := WriteTable(&sb, results, 1); err != nil {
+		t.Fatalf("WriteTable err=%v", err)
+	}
+	if lines := strings.Count(sb.String(), "\n"); lines != 2 {
+		t.Fatalf("表格行数=%d, want 2（表头+1）", lines)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	wf := config.WalkForwardConfig{TrainMs: 1000, TestMs: 500}
+	// 数据跨度 0 ~ 3s：训练 1s + 测试 0.5s，步长 0.5s
+	wins := Windows(0, 3_000_000_000, wf)
+	if len(wins) != 4 {
+		t.Fatalf("len(wins)=%d, want 4", len(wins))
+	}
+	last := wins[len(wins)-1]
+	if last.TrainFromNs != 1_500_000_000 || last.TestToNs != 3_000_000_000 {
+		t.Fatalf("最后窗口错误: %+v", last)
+	}
+	if Windows(0, 1_000_000_000, wf) != nil {
+		t.Fatal("数据不足一个窗口时应返回空")
+	}
+}
+
+func TestWalkForward_OutOfSample(t *testing.T) {
+	// 四段相同行情，间隔 1s
+	var events []*model.BookEvent
+	for k := int64(0); k < 4; k++ {
+		for _, ev := range convergingEvents() {
+			ev.ArrivedAtUnixNs += k * 1_000_000_000
+			events = append(events, ev)
+		}
+	}
+
+	cfg := testConfig()
+	cfg.Backtest.WalkForward = config.WalkForwardConfig{TrainMs: 1000, TestMs: 1000, MinTrades: 1}
+	combos := Combos(cfg, config.GridConfig{ThetaEntryBps: []float64{1000, 5}})
+
+	res, err := WalkForward(context.Background(), replay.SliceSource(events), cfg, combos, 2)
+	if err != nil {
+		t.Fatalf("WalkForward err=%v", err)
+	}
+	if len(res.Windows) != 2 {
+		t.Fatalf("len(Windows)=%d, want 2", len(res.Windows))
+	}
+	for i, w := range res.Windows {
+		if w.Best == nil || w.Best.ThetaEntryBps != 5 {
+			t.Fatalf("窗口 %d 应选出 theta=5: %+v", i, w.Best)
+		}
+		if w.OutOfSample.Trades != 1 {
+			t.Fatalf("窗口 %d 样本外成交=%d, want 1", i, w.OutOfSample.Trades)
+		}
+	}
+	if res.OOSTrades != 2 || res.OOSAvgNetBps <= 0 {
+		t.Fatalf("样本外汇总错误: trades=%d avg=%f", res.OOSTrades, res.OOSAvgNetBps)
+	}
+
+	var sb strings.Builder
+	if err := WriteWalkForwardTable(&sb, res); err != nil {
+		t.Fatalf("WriteWalkForwardTable err=%v", err)
+	}
+	if !strings.Contains(sb.String(), "样本外汇总") {
+		t.Fatalf("输出缺少汇总行:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,241 @@
+// Package backtest 在录制的 BookEvent 流上离线重放信号引擎与影子成交。
+// 与实时聚合器相同：两条 Leader 链路独立，EV 拒绝、EV 转负平仓、资金/借币成本、点差滑点、波动率缩放与 latency_fill 规则一致。
+// 与实时聚合器不同（回测结果可能偏乐观）：
+//   - 不应用禁止开仓时段（blackout），也不读取交易所维护状态，维护期间照常开仓；
+//   - 降级行情（REST 轮询）与 Follower 连接降级（feed_guard）时不暂停开新仓；
+//   - 不注入 chaos 故障，不运行 variants（参数组合由 Sweep 网格扫描代替）；
+//   - 不恢复 checkpoint，每次回放从空仓、空 EV 窗口开始。
+//
+// 重要：仅用于研究/验证，严禁真实下单。
+package backtest
+
+import (
+	"context"
+	"fmt"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/core/paper"
+	sigengine "latency-arbitrage-validator/internal/core/signal"
+	"latency-arbitrage-validator/internal/core/store"
+	"latency-arbitrage-validator/internal/replay"
+	"latency-arbitrage-validator/internal/stats/equity"
+	"latency-arbitrage-validator/internal/stats/ev"
+	"latency-arbitrage-validator/internal/stats/latency"
+	"latency-arbitrage-validator/internal/stats/quotespread"
+	"latency-arbitrage-validator/internal/stats/volatility"
+)
+
+// Params 一组可扫描的策略参数
+type Params struct {
+	// ThetaEntryBps 入场阈值（基点）
+	ThetaEntryBps float64 `json:"theta_entry_bps"`
+	// PersistMs 持续时间过滤（毫秒）
+	PersistMs int `json:"persist_ms"`
+	// TPRatio 止盈比例
+	TPRatio float64 `json:"tp_ratio"`
+	// SLRatio 止损比例
+	SLRatio float64 `json:"sl_ratio"`
+}
+
+// Apply 将参数写入策略与影子成交配置（返回副本）
+func (p Params) Apply(strategy config.StrategyConfig, paperCfg config.PaperConfig) (config.StrategyConfig, config.PaperConfig) {
+	strategy.ThetaEntryBps = p.ThetaEntryBps
+	strategy.PersistMs = p.PersistMs
+	paperCfg.TPRatio = p.TPRatio
+	paperCfg.SLRatio = p.SLRatio
+	return strategy, paperCfg
+}
+
+// String 返回参数的简短描述
+func (p Params) String() string {
+	return fmt.Sprintf("theta=%g persist=%d tp=%g sl=%g", p.ThetaEntryBps, p.PersistMs, p.TPRatio, p.SLRatio)
+}
+
+// Result 单组参数的回测结果
+type Result struct {
+	// Params 参数组合
+	Params Params `json:"params"`
+	// Events 回放的事件数
+	Events int64 `json:"events"`
+	// Signals 产生的信号数（含被 EV 或波动率过滤的）
+	Signals int64 `json:"signals"`
+	// Trades 平仓笔数
+	Trades int64 `json:"trades"`
+	// Wins 盈利笔数（净利>0）
+	Wins int64 `json:"wins"`
+	// PhantomFills 报价持续校验失败、未计入 Trades 的幻影成交笔数
+	PhantomFills int64 `json:"phantom_fills,omitempty"`
+	// TotalNetBps 累计净利（基点）
+	TotalNetBps float64 `json:"total_net_bps"`
+	// AvgNetBps 平均每笔净利（基点），即实现 EV
+	AvgNetBps float64 `json:"avg_net_bps"`
+	// EVOKX OKX 链路滚动 EV 统计（结束时）
+	EVOKX ev.EVStats `json:"ev_okx"`
+	// EVBinance Binance 链路滚动 EV 统计（结束时）
+	EVBinance ev.EVStats `json:"ev_binance"`
+	// Equity 两条链路合并的权益曲线（按平仓顺序）
+	Equity equity.EquityStats `json:"equity"`
+}
+
+type link struct {
+	leader string
+	engine *sigengine.Engine
+	exec   *paper.Executor
+	ev     *ev.Calculator
+}
+
+// ctxCheckEvery 回放时每处理多少个事件检查一次 ctx 是否已取消
+const ctxCheckEvery = 1024
+
+// Run 在事件源上回放一组策略配置
+// ctx 取消时中止回放并返回 ctx.Err()。
+// 参数 src: 录制的事件源
+// 参数 strategy/paperCfg: 生效的策略与影子成交配置
+// 参数 fees: 手续费配置（Bittap 费率与资金/借币成本）
+// 参数 evCfg: EV 统计窗口配置（与实时聚合器一致）
+func Run(ctx context.Context, src replay.Source, strategy config.StrategyConfig, paperCfg config.PaperConfig, fees config.FeesConfig, evCfg config.EVConfig) (*Result, error) {
+	res := &Result{
+		Params: Params{
+			ThetaEntryBps: strategy.ThetaEntryBps,
+			PersistMs:     strategy.PersistMs,
+			TPRatio:       paperCfg.TPRatio,
+			SLRatio:       paperCfg.SLRatio,
+		},
+	}
+
+	bookStore := store.New()
+	curve := equity.NewCurve()
+	links := make([]*link, 0, 2)
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		exec := paper.NewExecutor(leader, paperCfg, fees.Bittap)
+		if fees.BorrowRateAnnual > 0 {
+			exec.SetBorrowCost(fees.BorrowCostBps)
+		}
+		links = append(links, &link{
+			leader: leader,
+			engine: sigengine.NewEngine(leader, strategy),
+			exec:   exec,
+			ev:     ev.NewCalculatorFromConfig(evCfg),
+		})
+	}
+
+	record := func(l *link, closed *model.Position, nowNs int64) {
+		l.ev.Add(closed)
+		curve.Add(closed)
+		if closed.ExitReason == model.ExitSL {
+			l.engine.NotifyStopLoss(closed.SymbolCanon, nowNs)
+		}
+		res.Trades++
+		res.TotalNetBps += closed.NetPnLBps
+		if closed.NetPnLBps > 0 {
+			res.Wins++
+		}
+	}
+
+	var spreads *quotespread.Tracker
+	if paperCfg.SlippageMode == config.SlippageModeSpread {
+		spreads = quotespread.New(paperCfg.SpreadWindow)
+		for _, l := range links {
+			l.exec.SetSlippageSource(spreads.HalfSpreadBps)
+		}
+	}
+
+	var vols *volatility.Tracker
+	if paperCfg.VolScaleRef > 0 {
+		vols = volatility.New(paperCfg.VolScaleWindowMs)
+		for _, l := range links {
+			leader := l.leader
+			l.exec.SetVolSource(func(symbolCanon string) (float64, bool) {
+				return vols.RealizedVol(leader, symbolCanon)
+			})
+		}
+	}
+
+	// latency_fill：与实时聚合器相同，按业务时间定期以链路到达时延 P50 刷新模拟成交延迟
+	var lat *latency.Tracker
+	var latRefreshedAt int64
+	if paperCfg.LatencyFill {
+		lat = latency.NewTracker(10000)
+	}
+
+	err := src(func(bookEv *model.BookEvent) error {
+		if bookEv == nil || bookEv.Exchange == "" || bookEv.SymbolCanon == "" {
+			return nil
+		}
+		res.Events++
+		if res.Events%ctxCheckEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if !bookStore.Update(bookEv) {
+			return nil
+		}
+		if spreads != nil {
+			spreads.Observe(bookEv)
+		}
+		if vols != nil {
+			vols.Observe(bookEv)
+		}
+
+		nowNs := bookEv.ArrivedAtUnixNs
+		if lat != nil && bookEv.Exchange == model.ExchangeBittap && !bookEv.Degraded {
+			for _, l := range links {
+				if leaderBook, _ := bookStore.GetPair(l.leader, bookEv.SymbolCanon); leaderBook != nil && !leaderBook.Degraded {
+					lat.Add(leaderBook, bookEv)
+				}
+			}
+			if nowNs-latRefreshedAt >= paper.FillDelayRefreshNs {
+				latRefreshedAt = nowNs
+				for _, l := range links {
+					l.exec.SetFillDelayNs(int64(lat.ArrivedP50Ms(l.leader) * 1e6))
+				}
+			}
+		}
+		for _, l := range links {
+			leaderBook, followerBook := bookStore.GetPair(l.leader, bookEv.SymbolCanon)
+			if leaderBook == nil || followerBook == nil {
+				continue
+			}
+			if sig := l.engine.Evaluate(nowNs, leaderBook, followerBook); sig != nil {
+				res.Signals++
+				l.ev.Expire(nowNs)
+				ev.ApplyRejection(sig, l.ev.Stats())
+				if sig.FilterReason == "" {
+					if _, _, err := l.exec.TryOpen(sig); err != nil {
+						return fmt.Errorf("回放开仓失败: %w", err)
+					}
+				}
+			} else if l.engine.TakeFiltered() != nil {
+				res.Signals++
+			}
+			for _, closed := range l.exec.Evaluate(nowNs, leaderBook, followerBook) {
+				record(l, closed, nowNs)
+			}
+			if paperCfg.EVFlipExit && l.exec.HasOpen(bookEv.SymbolCanon) {
+				l.ev.Expire(nowNs)
+				if st := l.ev.SymbolStats(bookEv.SymbolCanon); st.Count > 0 && st.EV < 0 {
+					for _, closed := range l.exec.CloseSymbol(nowNs, bookEv.SymbolCanon, followerBook, model.ExitEVFlip) {
+						record(l, closed, nowNs)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Trades > 0 {
+		res.AvgNetBps = res.TotalNetBps / float64(res.Trades)
+	}
+	for _, l := range links {
+		res.PhantomFills += l.exec.PhantomFills()
+	}
+	res.EVOKX = links[0].ev.Stats()
+	res.EVBinance = links[1].ev.Stats()
+	res.Equity = curve.Stats()
+	return res, nil
+}
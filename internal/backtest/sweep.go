@@ -0,0 +1,142 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/replay"
+)
+
+// Combos 展开参数网格为组合列表
+// 网格中为空的维度沿用基础配置的取值。
+func Combos(cfg *config.Config, grid config.GridConfig) []Params {
+	thetas := grid.ThetaEntryBps
+	if len(thetas) == 0 {
+		thetas = []float64{cfg.Strategy.ThetaEntryBps}
+	}
+	persists := grid.PersistMs
+	if len(persists) == 0 {
+		persists = []int{cfg.Strategy.PersistMs}
+	}
+	tps := grid.TPRatio
+	if len(tps) == 0 {
+		tps = []float64{cfg.Paper.TPRatio}
+	}
+	sls := grid.SLRatio
+	if len(sls) == 0 {
+		sls = []float64{cfg.Paper.SLRatio}
+	}
+
+	out := make([]Params, 0, len(thetas)*len(persists)*len(tps)*len(sls))
+	for _, theta := range thetas {
+		for _, persist := range persists {
+			for _, tp := range tps {
+				for _, sl := range sls {
+					out = append(out, Params{ThetaEntryBps: theta, PersistMs: persist, TPRatio: tp, SLRatio: sl})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Sweep 并行回放所有参数组合
+// 每个 worker goroutine 独立遍历事件源，组合之间不共享任何状态。
+// 参数 workers: 并行数（<=0 表示 CPU 核数）
+// 返回: 与 combos 一一对应的结果（未排序）
+func Sweep(ctx context.Context, src replay.Source, cfg *config.Config, combos []Params, workers int) ([]*Result, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(combos) {
+		workers = len(combos)
+	}
+
+	results := make([]*Result, len(combos))
+	jobs := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				strategy, paperCfg := combos[i].Apply(cfg.Strategy, cfg.Paper)
+				res, err := Run(ctx, src, strategy, paperCfg, cfg.Fees, cfg.EV)
+				if ctx.Err() != nil {
+					continue
+				}
+				if err != nil {
+					errOnce.Do(func() { firstErr = fmt.Errorf("回测 %s 失败: %w", combos[i], err) })
+					continue
+				}
+				results[i] = res
+			}
+		}()
+	}
+
+	for i := range combos {
+		select {
+		case <-ctx.Done():
+			close(jobs)
+			wg.Wait()
+			return nil, ctx.Err()
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// Rank 按实现 EV（平均每笔净利）降序排序，EV 相同则按累计净利降序
+// 无成交的组合排在最后。
+func Rank(results []*Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if (a.Trades > 0) != (b.Trades > 0) {
+			return a.Trades > 0
+		}
+		if a.AvgNetBps != b.AvgNetBps {
+			return a.AvgNetBps > b.AvgNetBps
+		}
+		return a.TotalNetBps > b.TotalNetBps
+	})
+}
+
+// WriteTable 以对齐表格输出排名结果
+// 参数 top: 最多输出行数（<=0 表示全部）
+func WriteTable(w io.Writer, results []*Result, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "rank\ttheta_bps\tpersist_ms\ttp\tsl\tsignals\ttrades\twin_rate\tavg_net_bps\ttotal_net_bps\tmax_dd_bps\t")
+	for i, r := range results {
+		if top > 0 && i >= top {
+			break
+		}
+		var winRate float64
+		if r.Trades > 0 {
+			winRate = float64(r.Wins) / float64(r.Trades)
+		}
+		fmt.Fprintf(tw, "%d\t%g\t%d\t%g\t%g\t%d\t%d\t%.3f\t%.3f\t%.2f\t%.2f\t\n",
+			i+1, r.Params.ThetaEntryBps, r.Params.PersistMs, r.Params.TPRatio, r.Params.SLRatio,
+			r.Signals, r.Trades, winRate, r.AvgNetBps, r.TotalNetBps, r.Equity.MaxDrawdownBps)
+	}
+	return tw.Flush()
+}
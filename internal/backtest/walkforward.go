@@ -0,0 +1,145 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/replay"
+)
+
+// Window 单个 walk-forward 窗口的结果
+type Window struct {
+	// TrainFromNs/TrainToNs 训练段 [from, to)（纳秒）
+	TrainFromNs int64 `json:"train_from_ns"`
+	TrainToNs   int64 `json:"train_to_ns"`
+	// TestFromNs/TestToNs 测试段 [from, to)（纳秒）
+	TestFromNs int64 `json:"test_from_ns"`
+	TestToNs   int64 `json:"test_to_ns"`
+	// Best 训练段选出的最优参数（无合格组合时为 nil）
+	Best *Params `json:"best,omitempty"`
+	// InSample 最优参数的训练段结果
+	InSample *Result `json:"in_sample,omitempty"`
+	// OutOfSample 最优参数的测试段结果
+	OutOfSample *Result `json:"out_of_sample,omitempty"`
+}
+
+// WalkForwardResult walk-forward 汇总结果
+type WalkForwardResult struct {
+	// Windows 各窗口结果（按时间顺序）
+	Windows []*Window `json:"windows"`
+	// OOSTrades 样本外累计成交笔数
+	OOSTrades int64 `json:"oos_trades"`
+	// OOSTotalNetBps 样本外累计净利（基点）
+	OOSTotalNetBps float64 `json:"oos_total_net_bps"`
+	// OOSAvgNetBps 样本外平均每笔净利（基点），即样本外 EV
+	OOSAvgNetBps float64 `json:"oos_avg_net_bps"`
+}
+
+// Windows 按数据时间范围切分滚动训练/测试窗口
+// 仅返回测试段完整落在 [firstNs, lastNs] 内的窗口。
+// 参数 wf: 窗口配置（StepMs<=0 时等于 TestMs）
+func Windows(firstNs, lastNs int64, wf config.WalkForwardConfig) []*Window {
+	trainNs := wf.TrainMs * int64(time.Millisecond)
+	testNs := wf.TestMs * int64(time.Millisecond)
+	stepNs := wf.StepMs * int64(time.Millisecond)
+	if stepNs <= 0 {
+		stepNs = testNs
+	}
+	if trainNs <= 0 || testNs <= 0 {
+		return nil
+	}
+
+	var out []*Window
+	for start := firstNs; start+trainNs+testNs <= lastNs+1; start += stepNs {
+		out = append(out, &Window{
+			TrainFromNs: start,
+			TrainToNs:   start + trainNs,
+			TestFromNs:  start + trainNs,
+			TestToNs:    start + trainNs + testNs,
+		})
+	}
+	return out
+}
+
+// WalkForward 滚动前推评估
+// 每个窗口在训练段上并行扫描 combos，选出成交数不低于 MinTrades 的最优参数，
+// 再以该参数在测试段上回放，累计样本外结果。
+// 注意：每段回放均从空 store/空 EV 窗口开始，段间不延续持仓与状态。
+func WalkForward(ctx context.Context, src replay.Source, cfg *config.Config, combos []Params, workers int) (*WalkForwardResult, error) {
+	wf := cfg.Backtest.WalkForward
+
+	firstNs, lastNs, err := replay.Bounds(src)
+	if err != nil {
+		return nil, fmt.Errorf("扫描数据时间范围失败: %w", err)
+	}
+
+	out := &WalkForwardResult{Windows: Windows(firstNs, lastNs, wf)}
+	if len(out.Windows) == 0 {
+		return nil, fmt.Errorf("数据时长不足一个窗口（训练 %dms + 测试 %dms）", wf.TrainMs, wf.TestMs)
+	}
+
+	for _, w := range out.Windows {
+		train := replay.TimeRange(src, w.TrainFromNs, w.TrainToNs)
+		results, err := Sweep(ctx, train, cfg, combos, workers)
+		if err != nil {
+			return nil, err
+		}
+		Rank(results)
+
+		var best *Result
+		for _, r := range results {
+			if r.Trades > 0 && r.Trades >= wf.MinTrades {
+				best = r
+				break
+			}
+		}
+		if best == nil {
+			continue
+		}
+
+		params := best.Params
+		w.Best = &params
+		w.InSample = best
+
+		strategy, paperCfg := params.Apply(cfg.Strategy, cfg.Paper)
+		oos, err := Run(ctx, replay.TimeRange(src, w.TestFromNs, w.TestToNs), strategy, paperCfg, cfg.Fees, cfg.EV)
+		if err != nil {
+			return nil, fmt.Errorf("样本外回测 %s 失败: %w", params, err)
+		}
+		w.OutOfSample = oos
+
+		out.OOSTrades += oos.Trades
+		out.OOSTotalNetBps += oos.TotalNetBps
+	}
+
+	if out.OOSTrades > 0 {
+		out.OOSAvgNetBps = out.OOSTotalNetBps / float64(out.OOSTrades)
+	}
+	return out, nil
+}
+
+// WriteWalkForwardTable 以对齐表格输出各窗口与样本外汇总
+func WriteWalkForwardTable(w io.Writer, res *WalkForwardResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "window\ttest_from\tparams\tis_trades\tis_avg_bps\toos_trades\toos_avg_bps\toos_total_bps\t")
+	for i, win := range res.Windows {
+		testFrom := time.Unix(0, win.TestFromNs).UTC().Format(time.RFC3339)
+		if win.Best == nil {
+			fmt.Fprintf(tw, "%d\t%s\t-\t-\t-\t-\t-\t-\t\n", i+1, testFrom)
+			continue
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%.3f\t%d\t%.3f\t%.2f\t\n",
+			i+1, testFrom, win.Best, win.InSample.Trades, win.InSample.AvgNetBps,
+			win.OutOfSample.Trades, win.OutOfSample.AvgNetBps, win.OutOfSample.TotalNetBps)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n样本外汇总: trades=%d avg_net_bps=%.3f total_net_bps=%.2f\n",
+		res.OOSTrades, res.OOSAvgNetBps, res.OOSTotalNetBps)
+	return err
+}
@@ -0,0 +1,106 @@
+// Package buildinfo 记录构建版本信息，使输出文件可追溯到产生它的代码。
+// 版本、提交与构建时间通过 -ldflags -X 注入；未注入时回退到 Go 工具链记录的 VCS 信息。
+//
+//	go build -ldflags "-X latency-arbitrage-validator/internal/buildinfo.Version=v1.2.0 \
+//	  -X latency-arbitrage-validator/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X latency-arbitrage-validator/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/validator
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// 由 -ldflags -X 注入
+var (
+	// Version 版本号
+	Version = "dev"
+	// Commit git 提交哈希
+	Commit = ""
+	// BuildTime 构建时间（UTC，RFC3339）
+	BuildTime = ""
+)
+
+// Info 构建信息
+type Info struct {
+	// Version 版本号
+	Version string `json:"version"`
+	// Commit git 提交哈希
+	Commit string `json:"commit"`
+	// BuildTime 构建时间
+	BuildTime string `json:"build_time"`
+	// Dirty 构建时工作区有未提交修改（仅 VCS 回退信息可得）
+	Dirty bool `json:"dirty,omitempty"`
+	// GoVersion Go 工具链版本
+	GoVersion string `json:"go_version"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get 获取构建信息（首次调用时解析，之后复用）
+func Get() Info {
+	once.Do(func() {
+		info = resolve(Version, Commit, BuildTime, readVCS)
+	})
+	return info
+}
+
+// String 单行描述，如 "v1.2.0 (abc1234, 2026-01-02T03:04:05Z)"
+func (i Info) String() string {
+	commit := i.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	if commit == "" {
+		commit = "unknown"
+	}
+	if i.Dirty {
+		commit += "-dirty"
+	}
+	if i.BuildTime == "" {
+		return i.Version + " (" + commit + ")"
+	}
+	return i.Version + " (" + commit + ", " + i.BuildTime + ")"
+}
+
+// vcsReader 读取工具链记录的 VCS 信息（便于测试替换）
+type vcsReader func() (revision, time string, modified bool)
+
+func readVCS() (revision, vcsTime string, modified bool) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", "", false
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.time":
+			vcsTime = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	return revision, vcsTime, modified
+}
+
+// resolve 合并注入值与 VCS 回退值（注入值优先）
+func resolve(version, commit, buildTime string, vcs vcsReader) Info {
+	out := Info{Version: version, Commit: commit, BuildTime: buildTime, GoVersion: runtime.Version()}
+	if out.Version == "" {
+		out.Version = "dev"
+	}
+	if commit == "" {
+		rev, t, modified := vcs()
+		out.Commit = rev
+		out.Dirty = modified
+		if out.BuildTime == "" {
+			out.BuildTime = t
+		}
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+// Package buildinfo 构建信息测试
+package buildinfo
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	vcs := func() (string, string, bool) { return "0123456789abcdef", "2026-01-01T00:00:00Z", true }
+
+	tests := []struct {
+		name                       string
+		version, commit, buildTime string
+		wantCommit, wantTime       string
+		wantDirty                  bool
+		wantString                 string
+	}{
+		{
+			name: "注入值优先", version: "v1.0.0", commit: "feedface", buildTime: "2026-02-02T00:00:00Z",
+			wantCommit: "feedface", wantTime: "2026-02-02T00:00:00Z",
+			wantString: "v1.0.0 (feedface, 2026-02-02T00:00:00Z)",
+		},
+		{
+			name: "回退到 VCS 信息", version: "",
+			wantCommit: "0123456789abcdef", wantTime: "2026-01-01T00:00:00Z", wantDirty: true,
+			wantString: "dev (0123456789ab-dirty, 2026-01-01T00:00:00Z)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolve(tt.version, tt.commit, tt.buildTime, vcs)
+			if got.Commit != tt.wantCommit || got.BuildTime != tt.wantTime || got.Dirty != tt.wantDirty {
+				t.Fatalf("got=%+v", got)
+			}
+			if s := got.String(); s != tt.wantString {
+				t.Fatalf("String()=%q, want %q", s, tt.wantString)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package chaos 在客户端 → 聚合器路径上注入故障（仅用于测试）。
+// 按固定随机种子对事件流注入到达时延、丢弃、重复与断线风暴；相同种子与输入序列得到
+// 相同的注入结果，使引擎/执行器/统计管线的健壮性可在回放或 CI 运行中确定性复现。
+package chaos
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/exchange/connerr"
+)
+
+// ErrInjectedDisconnect 断线风暴中上报的注入断线错误
+var ErrInjectedDisconnect = errors.New("chaos: 注入断线")
+
+// Stats 注入统计（累计）
+type Stats struct {
+	// Passed 送达的事件数（含注入时延的事件，不含重复副本）
+	Passed int64 `json:"passed"`
+	// Dropped 丢弃的事件数（随机丢弃 + 断线期间丢失）
+	Dropped int64 `json:"dropped"`
+	// Duplicated 重复投递的事件数
+	Duplicated int64 `json:"duplicated"`
+	// Delayed 注入到达时延的事件数
+	Delayed int64 `json:"delayed"`
+	// Storms 断线风暴次数
+	Storms int64 `json:"storms"`
+	// Outages 断线次数（每次上报一条 read 错误）
+	Outages int64 `json:"outages"`
+}
+
+// Injector 单个交易所事件流的故障注入器
+// Apply 须由单一 goroutine 调用；Stats 可并发读取。
+type Injector struct {
+	exchange string
+	cfg      config.ChaosConfig
+	rng      *rand.Rand
+
+	// cyclesLeft 当前风暴剩余的断线次数
+	cyclesLeft int
+	// outageLeft 当前断线剩余需丢弃的事件数
+	outageLeft int
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// Applies 判断配置是否对该交易所启用注入
+func Applies(cfg config.ChaosConfig, exchange string) bool {
+	if !cfg.Enabled {
+		return false
+	}
+	if len(cfg.Exchanges) == 0 {
+		return true
+	}
+	for _, ex := range cfg.Exchanges {
+		if ex == exchange {
+			return true
+		}
+	}
+	return false
+}
+
+// New 创建故障注入器
+// 各交易所的随机序列由 seed 与交易所名共同决定，互不影响。
+func New(exchange string, cfg config.ChaosConfig) *Injector {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(exchange))
+	return &Injector{
+		exchange: exchange,
+		cfg:      cfg,
+		rng:      rand.New(rand.NewSource(cfg.Seed ^ int64(h.Sum64()))),
+	}
+}
+
+// Stats 获取注入统计快照
+func (i *Injector) Stats() Stats {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.stats
+}
+
+// Apply 对单个事件注入故障
+// 参数 emit: 送达事件（重复时再送达一份副本，避免同一池化事件被归还两次；注入时延时为修改了到达时间的副本）
+// 参数 fail: 上报注入的连接错误
+// 未送达的事件（丢弃、注入时延被副本替代）由注入器归还对象池。
+func (i *Injector) Apply(ev *model.BookEvent, emit func(*model.BookEvent), fail func(error)) {
+	if ev == nil {
+		return
+	}
+	if i.outageLeft > 0 {
+		i.outageLeft--
+		i.add(&i.stats.Dropped)
+		ev.Release()
+		return
+	}
+
+	// 断线风暴：每次断线前的最后一个事件送达，随后丢失 storm_outage_events 个事件；
+	// 恢复后的首个事件送达并触发下一次断线
+	if i.cyclesLeft == 0 && i.cfg.StormRate > 0 && i.rng.Float64() < i.cfg.StormRate {
+		i.cyclesLeft = i.cfg.StormCycles
+		i.add(&i.stats.Storms)
+	}
+	if i.cyclesLeft > 0 {
+		i.cyclesLeft--
+		i.outageLeft = i.cfg.StormOutageEvents
+		i.add(&i.stats.Outages)
+		fail(connerr.New(i.exchange, connerr.CategoryRead, ErrInjectedDisconnect))
+	}
+
+	if i.cfg.DropRate > 0 && i.rng.Float64() < i.cfg.DropRate {
+		i.add(&i.stats.Dropped)
+		ev.Release()
+		return
+	}
+	out := ev
+	if i.cfg.DelayRate > 0 && i.cfg.DelayMaxMs > 0 && i.rng.Float64() < i.cfg.DelayRate {
+		out = ev.Clone()
+		out.ArrivedAtUnixNs += 1 + i.rng.Int63n(int64(i.cfg.DelayMaxMs)*1_000_000)
+		ev.Release()
+		i.add(&i.stats.Delayed)
+	}
+	// 副本须在送达前复制：送达后接收方可能已归还原事件
+	var dup *model.BookEvent
+	if i.cfg.DupRate > 0 && i.rng.Float64() < i.cfg.DupRate {
+		dup = out.Clone()
+	}
+	i.add(&i.stats.Passed)
+	emit(out)
+	if dup != nil {
+		i.add(&i.stats.Duplicated)
+		emit(dup)
+	}
+}
+
+// Wrap 在独立 goroutine 中对输入通道注入故障（实时模式）
+// 返回注入后的事件通道与注入错误通道；输入关闭或 ctx 取消后两者均关闭。
+func (i *Injector) Wrap(ctx context.Context, in <-chan *model.BookEvent) (<-chan *model.BookEvent, <-chan error) {
+	out := make(chan *model.BookEvent, cap(in))
+	errCh := make(chan error, 16)
+	go func() {
+		defer close(out)
+		defer close(errCh)
+		emit := func(ev *model.BookEvent) {
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+			}
+		}
+		fail := func(err error) {
+			select {
+			case errCh <- err:
+			default:
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-in:
+				if !ok {
+					return
+				}
+				i.Apply(ev, emit, fail)
+			}
+		}
+	}()
+	return out, errCh
+}
+
+func (i *Injector) add(counter *int64) {
+	i.mu.Lock()
+	*counter++
+	i.mu.Unlock()
+}
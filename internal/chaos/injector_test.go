@@ -0,0 +1,144 @@
+// Package chaos 故障注入测试
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func events(n int) []*model.BookEvent {
+	out := make([]*model.BookEvent, n)
+	for i := range out {
+		out[i] = &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", Seq: int64(i + 1), ArrivedAtUnixNs: int64(i+1) * 1_000_000}
+	}
+	return out
+}
+
+// run 依次注入并返回送达事件的 (Seq, ArrivedAtUnixNs) 序列与错误数
+func run(cfg config.ChaosConfig, in []*model.BookEvent) (out [][2]int64, errs int) {
+	inj := New(model.ExchangeBittap, cfg)
+	for _, ev := range in {
+		inj.Apply(ev,
+			func(e *model.BookEvent) { out = append(out, [2]int64{e.Seq, e.ArrivedAtUnixNs}) },
+			func(error) { errs++ })
+	}
+	return out, errs
+}
+
+func TestInjector_Deterministic(t *testing.T) {
+	cfg := config.ChaosConfig{Enabled: true, Seed: 42, DropRate: 0.1, DupRate: 0.1, DelayRate: 0.2, DelayMaxMs: 50, StormRate: 0.01, StormCycles: 3, StormOutageEvents: 5}
+	in := events(2000)
+	a, errsA := run(cfg, in)
+	b, errsB := run(cfg, in)
+	if len(a) != len(b) || errsA != errsB {
+		t.Fatalf("相同种子结果不一致: len %d/%d errs %d/%d", len(a), len(b), errsA, errsB)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("第 %d 个事件不一致: %v vs %v", i, a[i], b[i])
+		}
+	}
+
+	cfg.Seed = 43
+	if c, _ := run(cfg, in); len(c) == len(a) {
+		same := true
+		for i := range c {
+			if c[i] != a[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			t.Fatalf("不同种子不应得到相同注入序列")
+		}
+	}
+}
+
+func TestInjector_StormAndStats(t *testing.T) {
+	cfg := config.ChaosConfig{Enabled: true, Seed: 1, StormRate: 1, StormCycles: 2, StormOutageEvents: 3}
+	inj := New(model.ExchangeBittap, cfg)
+	var got []int64
+	var errs []error
+	for _, ev := range events(8) {
+		inj.Apply(ev, func(e *model.BookEvent) { got = append(got, e.Seq) }, func(err error) { errs = append(errs, err) })
+	}
+
+	// 断线前事件 1 送达，2-4 丢失；恢复后事件 5 送达并再次断线，6-8 丢失
+	if len(got) != 2 || got[0] != 1 || got[1] != 5 {
+		t.Fatalf("送达事件=%v, want [1 5]", got)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrInjectedDisconnect) {
+		t.Fatalf("errs=%v, want 2 条注入断线错误", errs)
+	}
+	st := inj.Stats()
+	if st.Storms != 1 || st.Outages != 2 || st.Dropped != 6 || st.Passed != 2 {
+		t.Fatalf("Stats=%+v", st)
+	}
+}
+
+func TestInjector_ReleasesPooled(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ChaosConfig
+		// emitted 原事件是否原样送达（未送达或被副本替代时应已归还对象池）
+		emitted bool
+	}{
+		{"随机丢弃", config.ChaosConfig{Enabled: true, DropRate: 1}, false},
+		{"注入时延", config.ChaosConfig{Enabled: true, DelayRate: 1, DelayMaxMs: 10}, false},
+		{"原样送达", config.ChaosConfig{Enabled: true}, true},
+	}
+	for _, tt := range tests {
+		inj := New(model.ExchangeBittap, tt.cfg)
+		ev := model.AcquireBookEvent()
+		ev.Exchange, ev.SymbolCanon, ev.Seq, ev.ArrivedAtUnixNs = model.ExchangeBittap, "BTCUSDT", 1, 1_000_000
+		var got []*model.BookEvent
+		inj.Apply(ev, func(e *model.BookEvent) { got = append(got, e) }, func(error) {})
+		if released := ev.SymbolCanon == ""; released == tt.emitted {
+			t.Errorf("%s: 原事件归还=%v, want %v", tt.name, released, !tt.emitted)
+		}
+		for _, e := range got {
+			if e.SymbolCanon != "BTCUSDT" || e.Seq != 1 {
+				t.Errorf("%s: 送达事件内容错误: %+v", tt.name, e)
+			}
+		}
+	}
+}
+
+func TestInjector_Wrap(t *testing.T) {
+	cfg := config.ChaosConfig{Enabled: true, Seed: 7, DupRate: 1}
+	in := make(chan *model.BookEvent, 4)
+	for _, ev := range events(2) {
+		in <- ev
+	}
+	close(in)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, errCh := New(model.ExchangeBittap, cfg).Wrap(ctx, in)
+
+	var seqs []int64
+	for ev := range out {
+		seqs = append(seqs, ev.Seq)
+	}
+	if len(seqs) != 4 || seqs[0] != 1 || seqs[1] != 1 || seqs[2] != 2 || seqs[3] != 2 {
+		t.Fatalf("seqs=%v, want [1 1 2 2]", seqs)
+	}
+	if _, ok := <-errCh; ok {
+		t.Fatalf("未触发风暴时不应有错误")
+	}
+}
+
+func TestApplies(t *testing.T) {
+	if Applies(config.ChaosConfig{}, model.ExchangeOKX) {
+		t.Fatalf("未启用时不应注入")
+	}
+	cfg := config.ChaosConfig{Enabled: true, Exchanges: []string{model.ExchangeBittap}}
+	if Applies(cfg, model.ExchangeOKX) || !Applies(cfg, model.ExchangeBittap) {
+		t.Fatalf("应仅对 bittap 注入")
+	}
+}
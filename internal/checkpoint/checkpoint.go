@@ -0,0 +1,132 @@
+// Package checkpoint 周期性保存与启动时恢复运行状态（未平仓仓位、EV 窗口、止损冷却、时延窗口），
+// 使短暂重启（改配置、崩溃）不会清空已累计数小时的统计。
+// 状态文件为单个 JSON，先写临时文件再 rename，保证任意时刻文件完整。
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/stats/equity"
+	"latency-arbitrage-validator/internal/stats/ev"
+	"latency-arbitrage-validator/internal/stats/latency"
+)
+
+// Version 状态文件格式版本（不兼容变更时递增，旧版本文件不恢复）
+const Version = 1
+
+// PipelineState 单条链路（变体 + Leader）的状态
+type PipelineState struct {
+	// Variant 策略变体名称（基础策略为空）
+	Variant string `json:"variant,omitempty"`
+	// Leader 领先交易所
+	Leader string `json:"leader"`
+	// Positions 未平仓仓位
+	Positions []model.Position `json:"positions,omitempty"`
+	// EV EV 滚动窗口
+	EV ev.State `json:"ev"`
+	// Equity 权益曲线累计统计
+	Equity equity.EquityStats `json:"equity"`
+	// Cooldowns 止损冷却到期时间（按交易对，纳秒）
+	Cooldowns map[string]int64 `json:"cooldowns,omitempty"`
+}
+
+// State 运行状态检查点
+type State struct {
+	// Version 格式版本
+	Version int `json:"version"`
+	// SavedAtUnixNs 保存时间（纳秒）
+	SavedAtUnixNs int64 `json:"saved_at_unix_ns"`
+	// Pipelines 各链路状态
+	Pipelines []PipelineState `json:"pipelines"`
+	// Latency 时延滚动窗口
+	Latency latency.State `json:"latency"`
+}
+
+// Save 原子写入状态文件
+func Save(path string, st *State) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("序列化检查点失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建检查点目录失败: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("写入检查点失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("替换检查点文件失败: %w", err)
+	}
+	return nil
+}
+
+// Load 读取状态文件
+// 文件不存在时返回 (nil, nil)；版本不匹配时返回错误。
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取检查点失败: %w", err)
+	}
+
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("解析检查点失败: %w", err)
+	}
+	if st.Version != Version {
+		return nil, fmt.Errorf("检查点版本不匹配: %d，当前支持 %d", st.Version, Version)
+	}
+	return &st, nil
+}
+
+// Saver 异步检查点写入器
+// 聚合器 goroutine 只负责导出状态并投递，文件 I/O 在 Run 所在 goroutine 完成；
+// 上一次写入未完成时新的投递被丢弃（下个周期会再次保存）。
+type Saver struct {
+	path string
+	ch   chan *State
+	// onErr 写入失败回调（可为 nil）
+	onErr func(error)
+}
+
+// NewSaver 创建异步检查点写入器
+// 参数 onErr: 写入失败回调（可为 nil）
+func NewSaver(path string, onErr func(error)) *Saver {
+	return &Saver{path: path, ch: make(chan *State, 1), onErr: onErr}
+}
+
+// Submit 非阻塞投递待保存的状态
+// 返回: 是否投递成功
+func (s *Saver) Submit(st *State) bool {
+	select {
+	case s.ch <- st:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run 写入循环，直到 ctx 取消
+func (s *Saver) Run(ctx context.Context) {
+	for {
+		select {
+		case st := <-s.ch:
+			if err := Save(s.path, st); err != nil && s.onErr != nil {
+				s.onErr(err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
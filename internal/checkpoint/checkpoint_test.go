@@ -0,0 +1,131 @@
+// Package checkpoint 运行状态检查点测试
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/core/paper"
+	"latency-arbitrage-validator/internal/stats/ev"
+	"latency-arbitrage-validator/internal/stats/latency"
+)
+
+func TestSaveLoad_RoundTrip(t *testing.T) {
+	calc := ev.NewCalculator(10)
+	calc.Add(&model.Position{Closed: true, NetPnLBps: 5, GrossPnLBps: 7, FeeBps: 2, ExitTime: time.Unix(100, 0), SymbolCanon: "BTCUSDT"})
+	calc.Add(&model.Position{Closed: true, NetPnLBps: -3, GrossPnLBps: -1, FeeBps: 2, ExitTime: time.Unix(101, 0), SymbolCanon: "ETHUSDT"})
+
+	st := &State{
+		Version:       Version,
+		SavedAtUnixNs: 123,
+		Pipelines: []PipelineState{{
+			Leader:    model.ExchangeOKX,
+			Positions: []model.Position{{ID: "p1", Leader: model.ExchangeOKX, SymbolCanon: "BTCUSDT", Side: model.SideLong}},
+			EV:        calc.ExportState(),
+			Cooldowns: map[string]int64{"BTCUSDT": 456},
+		}},
+		Latency: latency.State{model.ExchangeOKX: {Arrived: latency.WindowState{Values: []int64{1, 2}, Count: 2}}},
+	}
+
+	path := filepath.Join(t.TempDir(), "state", "checkpoint.json")
+	if err := Save(path, st); err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("临时文件应已被 rename")
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load 失败: %v", err)
+	}
+	if got.SavedAtUnixNs != 123 || len(got.Pipelines) != 1 {
+		t.Fatalf("状态不一致: %+v", got)
+	}
+	p := got.Pipelines[0]
+	if len(p.Positions) != 1 || p.Positions[0].ID != "p1" || p.Cooldowns["BTCUSDT"] != 456 {
+		t.Fatalf("链路状态不一致: %+v", p)
+	}
+	if !reflect.DeepEqual(p.EV, st.Pipelines[0].EV) {
+		t.Fatalf("EV 状态不一致: %+v vs %+v", p.EV, st.Pipelines[0].EV)
+	}
+	if !reflect.DeepEqual(got.Latency, st.Latency) {
+		t.Fatalf("时延状态不一致: %+v", got.Latency)
+	}
+}
+
+func TestShutdownRestore_OpenPositions(t *testing.T) {
+	paperCfg := config.PaperConfig{TPRatio: 0.5, SLRatio: 1.0, MaxHoldMs: 60000}
+	exec := paper.NewExecutor(model.ExchangeOKX, paperCfg, config.FeeDetail{})
+	sig := &model.Signal{
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    100,
+		DetectedAtNs: 1_000_000_000,
+		LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10},
+		FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90},
+	}
+	if _, opened, err := exec.TryOpen(sig); err != nil || !opened {
+		t.Fatalf("TryOpen 失败: opened=%v err=%v", opened, err)
+	}
+
+	// 停机：聚合器退出后保存最终检查点（不强制平仓）
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	st := &State{
+		Version:   Version,
+		Pipelines: []PipelineState{{Leader: model.ExchangeOKX, Positions: exec.OpenPositions()}},
+	}
+	if err := Save(path, st); err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+
+	// 重启：新执行器从检查点恢复未平仓仓位，并照常判断退出
+	got, err := Load(path)
+	if err != nil || got == nil {
+		t.Fatalf("Load 失败: st=%v err=%v", got, err)
+	}
+	restored := paper.NewExecutor(model.ExchangeOKX, paperCfg, config.FeeDetail{})
+	if n := restored.RestorePositions(got.Pipelines[0].Positions); n != 1 || !restored.HasOpen("BTCUSDT") {
+		t.Fatalf("恢复仓位数=%d, want 1", n)
+	}
+	leaderNow := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10}
+	followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 100.01, BestAskPx: 99.99}
+	closed := restored.Evaluate(1_200_000_000, leaderNow, followerNow)
+	if len(closed) != 1 || closed[0].ExitReason != model.ExitTP {
+		t.Fatalf("恢复的仓位应触发止盈平仓: %+v", closed)
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	st, err := Load(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil || st != nil {
+		t.Fatalf("文件不存在应返回 nil, nil: st=%v err=%v", st, err)
+	}
+}
+
+func TestLoad_VersionMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := Save(path, &State{Version: Version + 1}); err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("版本不匹配应返回错误")
+	}
+}
+
+func TestSaver_SubmitNonBlocking(t *testing.T) {
+	s := NewSaver(filepath.Join(t.TempDir(), "checkpoint.json"), nil)
+	if !s.Submit(&State{Version: Version}) {
+		t.Fatalf("首次投递应成功")
+	}
+	// Run 未启动时缓冲已满，再次投递应立即返回 false
+	if s.Submit(&State{Version: Version}) {
+		t.Fatalf("缓冲已满时投递应失败")
+	}
+}
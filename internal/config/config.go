@@ -4,8 +4,13 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -27,16 +32,62 @@ type Config struct {
 	Strategy StrategyConfig `yaml:"strategy"`
 	// Paper 影子成交配置
 	Paper PaperConfig `yaml:"paper"`
+	// Blackout 禁止开仓时段（资金费结算、计划维护、重大数据发布等）
+	Blackout BlackoutConfig `yaml:"blackout"`
+	// ExchangeStatus 交易所维护公告监控（维护期间暂停对应 Leader 的信号）
+	ExchangeStatus ExchangeStatusConfig `yaml:"exchange_status"`
+	// FeedGuard Follower 行情降级保护（断线重连或长时间无消息时暂停信号）
+	FeedGuard FeedGuardConfig `yaml:"feed_guard"`
+	// EV EV 统计窗口配置
+	EV EVConfig `yaml:"ev"`
+	// Latency 时延统计配置
+	Latency LatencyConfig `yaml:"latency"`
+	// LeadLag 收益率互相关（信息领先）估计配置
+	LeadLag LeadLagConfig `yaml:"leadlag"`
+	// InfoShare Hasbrouck 信息份额 / 成分份额估计配置
+	InfoShare InfoShareConfig `yaml:"infoshare"`
+	// Variants 策略变体列表（A/B 实验），与基础策略共享同一行情流
+	Variants []VariantConfig `yaml:"variants"`
 	// Output 输出配置
 	Output OutputConfig `yaml:"output"`
+	// Checkpoint 运行状态检查点配置
+	Checkpoint CheckpointConfig `yaml:"checkpoint"`
+	// Backtest 回测配置（离线模式使用）
+	Backtest BacktestConfig `yaml:"backtest"`
+	// Chaos 故障注入测试模式（仅用于测试，生产运行保持关闭）
+	Chaos ChaosConfig `yaml:"chaos"`
+	// Synthetic 合成 Follower 校准模式（仅作用于 replay/backtest/walkforward）
+	Synthetic SyntheticConfig `yaml:"synthetic"`
 }
 
 // AppConfig 应用基础配置
 type AppConfig struct {
 	// Name 应用名称，用于日志标识
 	Name string `yaml:"name"`
+	// NodeID 部署节点标识（如 tokyo-1），写入运行清单；多地域部署时 validator merge 据此区分各节点输出
+	NodeID string `yaml:"node_id"`
 	// LogLevel 日志级别: debug, info, warn, error
 	LogLevel string `yaml:"log_level"`
+	// LogFormat 日志编码: json 或 console
+	LogFormat string `yaml:"log_format"`
+	// LogLevels 按组件覆盖日志级别，键为 logger 名称前缀（如 exchange、exchange.okx、core）
+	LogLevels map[string]string `yaml:"log_levels"`
+	// LogFile 日志文件输出（Path 为空时输出到 stderr）
+	LogFile LogFileConfig `yaml:"log_file"`
+	// HotMode 低时延热模式：聚合器锁定 OS 线程并忙轮询输入通道，定时任务移出热路径（持续占满一个 CPU 核）
+	HotMode bool `yaml:"hot_mode"`
+	// PoolEvents 订单簿事件对象池：聚合器处理完（被替换或丢弃）的事件归还复用，降低 GC 压力
+	PoolEvents bool `yaml:"pool_events"`
+}
+
+// LogFileConfig 日志文件与按大小轮转配置
+type LogFileConfig struct {
+	// Path 日志文件路径（为空表示输出到 stderr）
+	Path string `yaml:"path"`
+	// MaxSizeMB 单个文件最大大小（MB），超过后轮转
+	MaxSizeMB int `yaml:"max_size_mb"`
+	// MaxBackups 保留的历史文件数（path.1 ... path.N）
+	MaxBackups int `yaml:"max_backups"`
 }
 
 // SymbolConfig 交易对配置
@@ -77,12 +128,153 @@ type ExchangeWSConfig struct {
 	PongTimeoutMs int `yaml:"pong_timeout_ms"`
 	// ReadTimeoutMs 读取超时（毫秒）
 	ReadTimeoutMs int `yaml:"read_timeout_ms"`
+	// StaleTimeoutMs 行情看门狗超时（毫秒）：连接仍打开但超过此时间未收到任何消息则强制重连
+	// 用于识别半开 TCP 连接（0 使用默认 60 秒，负数表示关闭看门狗）
+	StaleTimeoutMs int `yaml:"stale_timeout_ms"`
+	// Redundant 是否建立第二条冗余连接并按 Seq 去重合并（仅 Leader: okx/binance）
+	Redundant bool `yaml:"redundant"`
+	// BackupURL 冗余连接地址（为空则与 URL 相同），可指向其它区域/接入点
+	BackupURL string `yaml:"backup_url"`
+	// Backpressure 订单簿通道已满时的处理策略: drop_newest / drop_oldest / block
+	// 为空默认 drop_oldest（保留最新行情，对时延验证最重要）
+	Backpressure string `yaml:"backpressure"`
+	// BlockTimeoutMs block 策略的最长等待时间（毫秒），超时后丢弃新事件
+	BlockTimeoutMs int `yaml:"block_timeout_ms"`
+	// BookChSize 订单簿事件通道容量（交易对较多时调大，参考指标 BookChHighWater）
+	BookChSize int `yaml:"book_ch_size"`
+	// ErrChSize 连接错误通道容量
+	ErrChSize int `yaml:"err_ch_size"`
+	// DiffBook 使用增量深度流 + REST 快照维护本地订单簿（仅 Binance）
+	// 相比 depth5@100ms 部分快照，档位更深且不丢中间变化。
+	DiffBook bool `yaml:"diff_book"`
+	// SnapshotURL 深度快照 REST 地址（仅 diff_book 使用，公共行情接口）
+	SnapshotURL string `yaml:"snapshot_url"`
+	// SnapshotLimit 深度快照档位数（仅 diff_book 使用）
+	SnapshotLimit int `yaml:"snapshot_limit"`
+	// DepthLevels 解析并保存的单侧档位数（可选值见 DepthLevelOptions，默认 5）
+	DepthLevels int `yaml:"depth_levels"`
+	// DepthUpdateMs 深度流推送间隔（毫秒，仅 Binance，可选值见 BinanceDepthUpdateOptions，默认 100）
+	// 生效值随配置快照写入运行清单，便于区分不同推送频率下的时延结果。
+	DepthUpdateMs int `yaml:"depth_update_ms"`
+	// RawCaptureRate 原始帧采样录制比例（0-1，0 表示关闭），写入 raw_<exchange>.jsonl
+	// 解析失败的帧无论是否命中采样都会录制，便于复现解析问题。
+	RawCaptureRate float64 `yaml:"raw_capture_rate"`
+	// SubscribeChunkSize 单个订阅请求最多包含的交易对数（交易对较多时拆分为多帧发送）
+	SubscribeChunkSize int `yaml:"subscribe_chunk_size"`
+	// SubscribeIntervalMs 相邻订阅请求帧的发送间隔（毫秒），避免触发交易所消息频率限制
+	SubscribeIntervalMs int `yaml:"subscribe_interval_ms"`
+	// SubscribeAckTimeoutMs 订阅请求等待交易所确认的超时（毫秒），超时未确认的交易对进入重试
+	SubscribeAckTimeoutMs int `yaml:"subscribe_ack_timeout_ms"`
+	// SubscribeMaxRetries 单个交易对在同一连接上的最大订阅重试次数（被拒绝或确认超时后重试）
+	SubscribeMaxRetries int `yaml:"subscribe_max_retries"`
+	// MaxMsgsPerSec 单连接出站消息（订阅/退订/心跳）每秒上限，令牌桶限速
+	MaxMsgsPerSec float64 `yaml:"max_msgs_per_sec"`
+	// MsgBurst 出站消息允许的突发条数（令牌桶容量）
+	MsgBurst int `yaml:"msg_burst"`
+	// RestFallbackAfterMs WS 断线超过该时长（毫秒）后改用 REST 轮询深度（0 表示不启用）
+	// 启用时启动阶段首次连接失败不退出，后台重连期间同样按该时长启动 REST 轮询。
+	RestFallbackAfterMs int `yaml:"rest_fallback_after_ms"`
+	// RestFallbackIntervalMs REST 轮询间隔（毫秒，每轮依次拉取全部交易对）
+	RestFallbackIntervalMs int `yaml:"rest_fallback_interval_ms"`
+	// RestFallbackURL REST 深度接口地址（公共行情接口，为空时使用交易所默认地址）
+	RestFallbackURL string `yaml:"rest_fallback_url"`
+	// ReconnectMaxAttempts 连续重连失败的最大次数（0 表示无限重试），达到后放弃重连并上报 dial 错误
+	// 退避在连接持续健康 backoff_reset_after_ms 后才重置，闪断同样计入连续失败
+	ReconnectMaxAttempts int `yaml:"reconnect_max_attempts"`
+	// BackoffResetAfterMs 重连后连接持续收到消息超过该时长（毫秒）才重置退避间隔，
+	// 避免闪断的接入点始终以基础间隔重连（默认 30 秒）
+	BackoffResetAfterMs int `yaml:"backoff_reset_after_ms"`
+	// CompareChannel 在同一连接上额外订阅的对比频道（仅 OKX，可选值见 OKXCompareChannels，为空表示不对比）
+	// 对比频道的行情不进入策略，只与正式频道按同一盘口变化比较到达时延，结果写入指标 okx_channels。
+	CompareChannel string `yaml:"compare_channel"`
+	// Channel 正式行情频道（仅 OKX，可选值见 OKXChannels，默认 books5）
+	// books 频道为 400 档 快照 + 增量推送，由本地订单簿合并；仅支持公共频道，需登录的 l2-tbt 频道不可用。
+	Channel string `yaml:"channel"`
+	// PreResolveDNS 启动时解析接入点域名并缓存 IP，重连直接按 IP 建连（TLS SNI 仍为域名）
+	// 去掉重连时的 DNS 查询耗时与抖动；缓存 IP 全部连不上时才重新解析。
+	PreResolveDNS bool `yaml:"pre_resolve_dns"`
+	// PinIP 固定优先建连的接入点 IP（为空表示不固定；连不上时回退到解析得到的 IP）
+	// 便于在多个接入点 IP 中固定时延最低的一个，使滞后测量在重连前后可比。
+	PinIP string `yaml:"pin_ip"`
+	// TCPNoDelay 是否设置 TCP_NODELAY（关闭 Nagle 合包，默认 true；仅影响出站的订阅/心跳帧）
+	TCPNoDelay *bool `yaml:"tcp_nodelay"`
+	// SocketReadBuffer 内核接收缓冲区 SO_RCVBUF（字节，0 表示使用系统默认）
+	// 建连后设置，窗口缩放因子已在握手时确定；实际生效值受系统上限（如 net.core.rmem_max）约束。
+	SocketReadBuffer int `yaml:"socket_read_buffer"`
+	// SocketWriteBuffer 内核发送缓冲区 SO_SNDBUF（字节，0 表示使用系统默认）
+	SocketWriteBuffer int `yaml:"socket_write_buffer"`
+	// LocalAddr 建连绑定的本地 IP（为空表示由系统选择；多网卡主机可指定低时延出口）
+	LocalAddr string `yaml:"local_addr"`
+}
+
+// DefaultDepthLevels 默认解析并保存的单侧档位数
+const DefaultDepthLevels = 5
+
+// DepthLevelOptions 各交易所 depth_levels 可选值（受行情频道提供的档位限制）
+var DepthLevelOptions = map[string][]int{
+	"okx":     {5, 10, 20, 50}, // books5 仅 5 档；books 频道从本地订单簿截取
+	"binance": {5, 10, 20},     // depth<N>@100ms；diff_book 模式从本地订单簿截取同样档位
+	"bittap":  {5, 10, 30},     // f_depth30 截取
 }
 
+// BinanceDepthUpdateOptions ws.binance.depth_update_ms 可选值（Binance 深度流支持的推送间隔）
+var BinanceDepthUpdateOptions = []int{100, 250, 500}
+
+// OKXCompareChannels ws.okx.compare_channel 可选值（须为带最优价的全量推送频道，增量频道无法单帧得到最优价）
+var OKXCompareChannels = []string{"bbo-tbt"}
+
+// OKXChannels ws.okx.channel 可选值（仅公共频道）
+var OKXChannels = []string{"books5", "books"}
+
+// 订单簿通道背压策略
+const (
+	// BackpressureDropNewest 通道满时丢弃新事件
+	BackpressureDropNewest = "drop_newest"
+	// BackpressureDropOldest 通道满时丢弃最旧的排队事件，再放入新事件
+	BackpressureDropOldest = "drop_oldest"
+	// BackpressureBlock 通道满时阻塞读循环，最多等待 block_timeout_ms
+	BackpressureBlock = "block"
+)
+
 // FeesConfig 手续费配置
 type FeesConfig struct {
 	// Bittap Bittap 交易所手续费配置（影子成交使用）
 	Bittap FeeDetail `yaml:"bittap"`
+	// OKX OKX 手续费配置（Leader 侧对冲腿，计入信号的 fee_bps/net_edge_bps）
+	OKX FeeDetail `yaml:"okx"`
+	// Binance Binance 手续费配置（Leader 侧对冲腿，计入信号的 fee_bps/net_edge_bps）
+	Binance FeeDetail `yaml:"binance"`
+	// BorrowRateAnnual 持仓资金/借币成本年化利率（0-1，0 表示不计），按持仓时长折算后从影子成交净利中扣除
+	BorrowRateAnnual float64 `yaml:"borrow_rate_annual"`
+}
+
+// Leader 返回 Leader 交易所的手续费配置
+// 参数 leader: okx 或 binance；其它值返回零值
+func (f FeesConfig) Leader(leader string) FeeDetail {
+	switch leader {
+	case "okx":
+		return f.OKX
+	case "binance":
+		return f.Binance
+	default:
+		return FeeDetail{}
+	}
+}
+
+// LeaderRoundTripFeeBps Leader 侧对冲腿的往返手续费（基点，taker 含返佣）
+// 参数 leader: okx 或 binance；其它值返回 0
+func (f FeesConfig) LeaderRoundTripFeeBps(leader string) float64 {
+	fee := f.Leader(leader)
+	return 2 * fee.EffectiveTakerFee() * 10000
+}
+
+// BorrowCostBps 计算持仓 holdMs 毫秒的资金/借币成本（基点）
+func (f FeesConfig) BorrowCostBps(holdMs int64) float64 {
+	if f.BorrowRateAnnual <= 0 || holdMs <= 0 {
+		return 0
+	}
+	const msPerYear = 365 * 24 * 3600 * 1000
+	return f.BorrowRateAnnual * float64(holdMs) / msPerYear * 10000
 }
 
 // FeeDetail 手续费详情
@@ -101,16 +293,32 @@ type StrategyConfig struct {
 	ThetaEntryBps float64 `yaml:"theta_entry_bps"`
 	// PersistMs 持续时间过滤（毫秒），价差需持续超过此时间
 	PersistMs int `yaml:"persist_ms"`
-	// MinDepthUSD 最小深度过滤（USD），Leader 前 5 档深度需超过此值
+	// MinDepthUSD 最小深度过滤（USD），Leader 已解析档位逐档吃单需能成交此名义金额
 	MinDepthUSD float64 `yaml:"min_depth_usd"`
+	// MinDepthSide 深度统计的 Leader 盘口方向: bid / ask / both（两侧取较小值，默认 both）
+	MinDepthSide string `yaml:"min_depth_side"`
+	// MaxAdverseImbalance Follower 盘口逆向失衡过滤（0 表示不启用，范围 (0, 1]）
+	// 多头时 Follower 失衡度 < -阈值（卖盘压倒买盘）、空头时 > 阈值则跳过信号
+	MaxAdverseImbalance float64 `yaml:"max_adverse_imbalance"`
 	// VolFilterEnabled 是否启用波动率过滤
 	VolFilterEnabled bool `yaml:"vol_filter_enabled"`
-	// VolThreshold 波动率阈值，1 分钟实现波动率超过此值跳过信号
+	// VolThreshold 波动率阈值，1 分钟实现波动率超过此值跳过信号（未配置 vol_horizons 时生效）
 	VolThreshold float64 `yaml:"vol_threshold"`
+	// VolHorizons 多窗口波动率过滤（如 10s/1m/5m 各自独立阈值），任一窗口超阈值即过滤
+	// 为空时等价于单个 1 分钟窗口 + vol_threshold。
+	VolHorizons []VolHorizonConfig `yaml:"vol_horizons"`
 	// CooldownMs 止损冷却时间（毫秒）
 	CooldownMs int `yaml:"cooldown_ms"`
 }
 
+// VolHorizonConfig 波动率过滤的单个时间窗口
+type VolHorizonConfig struct {
+	// WindowMs 窗口长度（毫秒，按 1s 采样中间价，至少 3000）
+	WindowMs int `yaml:"window_ms"`
+	// Threshold 窗口内 1s 对数收益标准差超过此值则过滤信号
+	Threshold float64 `yaml:"threshold"`
+}
+
 // PaperConfig 影子成交配置
 type PaperConfig struct {
 	// TPRatio 止盈比例，价差收敛到 (1-r_tp)*入场价差 时止盈
@@ -121,6 +329,276 @@ type PaperConfig struct {
 	MaxHoldMs int `yaml:"max_hold_ms"`
 	// SlippageBps 滑点（基点），影子成交时额外扣除
 	SlippageBps float64 `yaml:"slippage_bps"`
+	// SlippageMode 滑点来源: fixed（slippage_bps）/ spread（Bittap 滚动中位报价价差的一半，默认 fixed）
+	SlippageMode string `yaml:"slippage_mode"`
+	// SpreadWindow spread 模式下每个交易对统计中位价差的样本数
+	SpreadWindow int `yaml:"spread_window"`
+	// NotionalUSD 每笔影子成交的名义金额（USD，0 表示按最优价成交）
+	// 大于 0 时开平仓价按 Follower 盘口逐档吃单的成交均价计算（滑点另计）
+	NotionalUSD float64 `yaml:"notional_usd"`
+	// LatencyFill 按 信号时刻 + 实测中位到达时延 时的 Follower 盘口成交（近似真实订单到达时可得的价格）
+	LatencyFill bool `yaml:"latency_fill"`
+	// QuotePersistMs 入场所用 Follower 最优价需在成交后持续的最短时间（毫秒，0 表示不校验）
+	// 期间最优价变差的成交记为幻影成交（phantom fill），不计入影子成交统计
+	QuotePersistMs int `yaml:"quote_persist_ms"`
+	// MaxPositionsPerSymbol 单个交易对同时持有的最大仓位数（0 或 1 表示单仓）
+	// 大于 1 时信号簇中的后续信号也会开仓，各仓位独立记录入场价差并独立判断退出。
+	MaxPositionsPerSymbol int `yaml:"max_positions_per_symbol"`
+	// ExitMode 止盈方式: fixed（固定 tp_ratio）/ trailing（跟踪止盈，默认 fixed）
+	ExitMode string `yaml:"exit_mode"`
+	// TrailLockRatio 跟踪止盈锁定比例（0-1），价差回撤到仅剩最佳收敛幅度的该比例时平仓
+	TrailLockRatio float64 `yaml:"trail_lock_ratio"`
+	// TrailActivationRatio 跟踪止盈启动比例（0-1），最佳收敛幅度达到入场价差的该比例后才开始跟踪
+	TrailActivationRatio float64 `yaml:"trail_activation_ratio"`
+	// ExitOnReversal 带符号价差穿越零轴（Leader 不再领先）时平仓，与 tp_ratio 无关
+	ExitOnReversal bool `yaml:"exit_on_reversal"`
+	// EVFlipExit 持仓期间该交易对的滚动 EV 转负时提前平仓
+	EVFlipExit bool `yaml:"ev_flip_exit"`
+	// VolScaleRef 波动率缩放参考值（Leader 1s 对数收益标准差，0 表示不缩放）
+	// 大于 0 时开仓按 k = 当前 realized vol / 参考值（限制在 [vol_scale_min, vol_scale_max]）缩放退出参数：
+	// tp_ratio、sl_ratio 乘以 k（tp_ratio 不超过 1），max_hold_ms 除以 k。
+	VolScaleRef float64 `yaml:"vol_scale_ref"`
+	// VolScaleWindowMs 缩放所用 realized vol 的估计窗口（毫秒）
+	VolScaleWindowMs int `yaml:"vol_scale_window_ms"`
+	// VolScaleMin 缩放系数下限
+	VolScaleMin float64 `yaml:"vol_scale_min"`
+	// VolScaleMax 缩放系数上限
+	VolScaleMax float64 `yaml:"vol_scale_max"`
+	// Symbols 按交易对（SymbolCanon）覆盖的退出参数，未覆盖的字段沿用上述配置
+	Symbols map[string]PaperSymbolConfig `yaml:"symbols"`
+}
+
+// PaperSymbolConfig 单个交易对的退出参数覆盖（0/空表示沿用全局值）
+type PaperSymbolConfig struct {
+	// TPRatio 止盈比例
+	TPRatio float64 `yaml:"tp_ratio"`
+	// SLRatio 止损比例
+	SLRatio float64 `yaml:"sl_ratio"`
+	// MaxHoldMs 最大持仓时间（毫秒）
+	MaxHoldMs int `yaml:"max_hold_ms"`
+	// ExitMode 止盈方式: fixed / trailing
+	ExitMode string `yaml:"exit_mode"`
+	// TrailLockRatio 跟踪止盈锁定比例（0-1）
+	TrailLockRatio float64 `yaml:"trail_lock_ratio"`
+	// TrailActivationRatio 跟踪止盈启动比例（0-1）
+	TrailActivationRatio float64 `yaml:"trail_activation_ratio"`
+	// ExitOnReversal 价差穿越零轴时平仓（未填沿用全局值，可显式设为 false 关闭）
+	ExitOnReversal *bool `yaml:"exit_on_reversal"`
+}
+
+// ForSymbol 返回交易对生效的影子成交配置（未覆盖的字段沿用全局配置）
+func (p PaperConfig) ForSymbol(symbolCanon string) PaperConfig {
+	o, ok := p.Symbols[symbolCanon]
+	if !ok {
+		return p
+	}
+	if o.TPRatio > 0 {
+		p.TPRatio = o.TPRatio
+	}
+	if o.SLRatio > 0 {
+		p.SLRatio = o.SLRatio
+	}
+	if o.MaxHoldMs > 0 {
+		p.MaxHoldMs = o.MaxHoldMs
+	}
+	if o.ExitMode != "" {
+		p.ExitMode = o.ExitMode
+	}
+	if o.TrailLockRatio > 0 {
+		p.TrailLockRatio = o.TrailLockRatio
+	}
+	if o.TrailActivationRatio > 0 {
+		p.TrailActivationRatio = o.TrailActivationRatio
+	}
+	if o.ExitOnReversal != nil {
+		p.ExitOnReversal = *o.ExitOnReversal
+	}
+	return p
+}
+
+// 深度过滤统计方向
+const (
+	// DepthSideBid 只统计买盘前 5 档
+	DepthSideBid = "bid"
+	// DepthSideAsk 只统计卖盘前 5 档
+	DepthSideAsk = "ask"
+	// DepthSideBoth 买卖盘前 5 档分别统计，取较小值（两侧均需满足阈值）
+	DepthSideBoth = "both"
+)
+
+// 影子成交止盈方式
+const (
+	// ExitModeFixed 固定止盈：价差收敛到 (1-r_tp)*入场价差 时平仓
+	ExitModeFixed = "fixed"
+	// ExitModeTrailing 跟踪止盈：止盈线随价差收敛单向上移，锁定最佳收敛幅度的 trail_lock_ratio
+	ExitModeTrailing = "trailing"
+)
+
+// 影子成交滑点来源
+const (
+	// SlippageModeFixed 使用全局常量 slippage_bps
+	SlippageModeFixed = "fixed"
+	// SlippageModeSpread 使用该交易对 Bittap 滚动中位报价价差的一半（无样本时回退 slippage_bps）
+	SlippageModeSpread = "spread"
+)
+
+// EVConfig EV 统计窗口配置
+// 决定 EV 拒绝规则与 metrics 中 EV 统计使用的样本范围。
+type EVConfig struct {
+	// WindowSize 滚动窗口大小（最近 N 笔；时间窗口模式下为样本数上限）
+	WindowSize int `yaml:"window_size"`
+	// WindowMs 时间窗口（毫秒，0 表示仅按笔数滚动），如 21600000 = 最近 6 小时
+	WindowMs int64 `yaml:"window_ms"`
+	// EWMAAlpha 指数加权衰减系数（0 表示不启用，取值 (0,1]）
+	// 启用后 EV 按指数加权计算，每笔新成交使历史样本权重乘以 (1-alpha)
+	EWMAAlpha float64 `yaml:"ewma_alpha"`
+}
+
+// LatencyConfig 时延统计配置
+type LatencyConfig struct {
+	// WindowMs 时延分位数的时间窗口（毫秒，如 600000 = 最近 10 分钟；0 表示仅按样本数滚动）
+	WindowMs int64 `yaml:"window_ms"`
+	// SpikeFactor 尖峰倍数阈值：近期 P90 >= 基线 P90 × 此值视为尖峰（0 表示不启用检测）
+	SpikeFactor float64 `yaml:"spike_factor"`
+	// SpikeMinDeltaMs 尖峰最小绝对增量（毫秒），避免基线很小时的误报
+	SpikeMinDeltaMs float64 `yaml:"spike_min_delta_ms"`
+	// SpikeRecentWindow 近期窗口样本数
+	SpikeRecentWindow int `yaml:"spike_recent_window"`
+	// SpikeCheckIntervalMs 尖峰检测间隔（毫秒）
+	SpikeCheckIntervalMs int `yaml:"spike_check_interval_ms"`
+	// LeaderMatchWindowMs OKX 与 Binance 同向变动视为同一次行情的最大间隔（毫秒），用于逐交易对领先比较（负数表示不启用）
+	LeaderMatchWindowMs int `yaml:"leader_match_window_ms"`
+	// LeadShareMinMoveBps 领先占比统计的显著变动阈值（基点，负数表示不启用）
+	LeadShareMinMoveBps float64 `yaml:"lead_share_min_move_bps"`
+	// LeadShareWindowMs 领先占比统计中 Leader 先行变动的回看窗口（毫秒）
+	LeadShareWindowMs int `yaml:"lead_share_window_ms"`
+}
+
+// LeadLagConfig 收益率互相关估计配置
+// 按固定时间桶采样 Leader/Follower 中间价，周期性计算滞后网格上的收益率互相关，输出 leadlag.jsonl。
+type LeadLagConfig struct {
+	// Enabled 是否启用
+	Enabled bool `yaml:"enabled"`
+	// BucketMs 采样桶宽度（毫秒），同时是滞后网格步长
+	BucketMs int `yaml:"bucket_ms"`
+	// MaxLagMs 最大滞后（毫秒），网格为 [-max_lag_ms, max_lag_ms]
+	MaxLagMs int `yaml:"max_lag_ms"`
+	// WindowMs 计算窗口长度（毫秒）
+	WindowMs int `yaml:"window_ms"`
+	// IntervalMs 计算与输出间隔（毫秒）
+	IntervalMs int `yaml:"interval_ms"`
+}
+
+// InfoShareConfig 信息份额估计配置
+// 按固定间隔采样 Leader/Follower 对数中间价，周期性拟合 VECM 计算 Leader 的 Hasbrouck 信息份额
+// 与 Gonzalo-Granger 成分份额，写入 metrics.jsonl 的 info_share。
+type InfoShareConfig struct {
+	// Enabled 是否启用
+	Enabled bool `yaml:"enabled"`
+	// SampleMs 采样间隔（毫秒）
+	SampleMs int `yaml:"sample_ms"`
+	// WindowMs 估计窗口长度（毫秒）
+	WindowMs int `yaml:"window_ms"`
+	// Lags VECM 差分滞后阶数
+	Lags int `yaml:"lags"`
+	// IntervalMs 估计间隔（毫秒），结果随下一次指标输出
+	IntervalMs int `yaml:"interval_ms"`
+}
+
+// BlackoutConfig 禁止开仓时段配置
+// 时段内引擎产生的信号标记 FilterReason=blackout 且不开仓；进入时段时平掉全部影子仓位。
+type BlackoutConfig struct {
+	// Daily 每日重复时段（UTC 时刻 HH:MM 或 HH:MM:SS；结束早于开始表示跨零点），如资金费结算前后
+	Daily []BlackoutWindowConfig `yaml:"daily"`
+	// Windows 一次性时段（RFC3339 时间），如计划维护、重大数据发布
+	Windows []BlackoutWindowConfig `yaml:"windows"`
+}
+
+// BlackoutWindowConfig 单个禁止开仓时段
+type BlackoutWindowConfig struct {
+	// Name 时段名称（日志与指标输出）
+	Name string `yaml:"name"`
+	// Start 开始时刻（含）
+	Start string `yaml:"start"`
+	// End 结束时刻（不含）
+	End string `yaml:"end"`
+}
+
+// DailyRange 解析每日时段的起止时刻（距 UTC 零点的偏移）
+func (w BlackoutWindowConfig) DailyRange() (start, end time.Duration, err error) {
+	if start, err = parseClock(w.Start); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(w.End); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// AbsRange 解析一次性时段的起止时间
+func (w BlackoutWindowConfig) AbsRange() (start, end time.Time, err error) {
+	if start, err = time.Parse(time.RFC3339, w.Start); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if end, err = time.Parse(time.RFC3339, w.End); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// parseClock 解析 HH:MM 或 HH:MM:SS 为距零点的偏移
+func parseClock(s string) (time.Duration, error) {
+	layout := "15:04:05"
+	if strings.Count(s, ":") == 1 {
+		layout = "15:04"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// ExchangeStatusConfig 交易所维护公告监控配置（仅实时模式）
+// 维护期间不再为以该交易所为 Leader 的链路生成信号，已有仓位照常判断退出。
+type ExchangeStatusConfig struct {
+	// Enabled 是否启用
+	Enabled bool `yaml:"enabled"`
+	// OKXURL OKX 公共 WS 地址（订阅 status 频道）
+	OKXURL string `yaml:"okx_url"`
+	// BinanceURL Binance 系统状态接口地址（公共接口，无需签名）
+	BinanceURL string `yaml:"binance_url"`
+	// BinancePollIntervalMs Binance 系统状态轮询间隔（毫秒）
+	BinancePollIntervalMs int `yaml:"binance_poll_interval_ms"`
+}
+
+// FeedGuardConfig Follower 行情降级保护配置（仅实时模式）
+// Bittap 连接断开重连中或 LastMessageAgeMs 超过 max_message_age_ms 时，各链路暂停生成新信号，恢复后自动继续。
+type FeedGuardConfig struct {
+	// Enabled 是否启用
+	Enabled bool `yaml:"enabled"`
+	// MaxMessageAgeMs Bittap 最后消息距今的上限（毫秒）
+	MaxMessageAgeMs int `yaml:"max_message_age_ms"`
+	// Flatten 进入降级状态时以最后已知报价平掉全部影子仓位（exit_reason=feed_degraded）
+	Flatten bool `yaml:"flatten"`
+}
+
+// VariantConfig 策略变体配置（A/B 实验）
+// 每个变体拥有独立的 Engine/Executor/EV 计算器，输出按 Name 标记。
+// 数值字段为 0 表示沿用基础 strategy/paper 配置。
+type VariantConfig struct {
+	// Name 变体名称（输出标记，必须唯一）
+	Name string `yaml:"name"`
+	// ThetaEntryBps 入场阈值（基点）
+	ThetaEntryBps float64 `yaml:"theta_entry_bps"`
+	// PersistMs 持续时间过滤（毫秒）
+	PersistMs int `yaml:"persist_ms"`
+	// TPRatio 止盈比例
+	TPRatio float64 `yaml:"tp_ratio"`
+	// SLRatio 止损比例
+	SLRatio float64 `yaml:"sl_ratio"`
+	// MaxHoldMs 最大持仓时间（毫秒）
+	MaxHoldMs int `yaml:"max_hold_ms"`
 }
 
 // OutputConfig 输出配置
@@ -129,6 +607,9 @@ type OutputConfig struct {
 	Dir string `yaml:"dir"`
 	// SignalsEnabled 是否输出信号文件
 	SignalsEnabled bool `yaml:"signals_enabled"`
+	// SignalsBBOOnly 信号中的 LeaderBook/FollowerBook 只输出最优价、时间戳与序列号（不输出 Bids/Asks 深度档位）
+	// 默认输出检测时刻两侧保留的全部档位，供事后执行研究；交易对多、信号频繁时可开启以减小文件体积。
+	SignalsBBOOnly bool `yaml:"signals_bbo_only"`
 	// PaperTradesEnabled 是否输出影子成交文件
 	PaperTradesEnabled bool `yaml:"paper_trades_enabled"`
 	// MetricsEnabled 是否输出指标文件
@@ -137,6 +618,148 @@ type OutputConfig struct {
 	MetricsIntervalMs int `yaml:"metrics_interval_ms"`
 	// BufferSize 异步写入缓冲区大小
 	BufferSize int `yaml:"buffer_size"`
+	// FlushIntervalMs JSONL 文件周期性 flush 间隔（毫秒），主机崩溃时最多丢失该间隔内的记录
+	FlushIntervalMs int `yaml:"flush_interval_ms"`
+	// Fsync 每次 flush 后 fsync 落盘（防主机断电/内核崩溃，代价为额外的磁盘 I/O）
+	Fsync bool `yaml:"fsync"`
+	// BooksEnabled 是否录制订单簿事件（books.jsonl，供回测/回放使用）
+	BooksEnabled bool `yaml:"books_enabled"`
+	// AlertsEnabled 是否输出告警事件文件（alerts.jsonl，如时延尖峰）
+	AlertsEnabled bool `yaml:"alerts_enabled"`
+	// PositionsEnabled 是否按指标周期输出未平仓仓位心跳（positions.jsonl）
+	PositionsEnabled bool `yaml:"positions_enabled"`
+	// SpreadsEnabled 是否输出按间隔采样的价差序列（spreads.jsonl）
+	SpreadsEnabled bool `yaml:"spreads_enabled"`
+	// SpreadsIntervalMs 价差采样间隔（毫秒，按链路与交易对分别计时）
+	SpreadsIntervalMs int `yaml:"spreads_interval_ms"`
+	// LatencySamplesEnabled 是否输出抽样的原始时延观测（latency_samples.jsonl）
+	LatencySamplesEnabled bool `yaml:"latency_samples_enabled"`
+	// LatencySampleEvery 每条链路每 N 次时延观测输出一条（1 表示全部输出）
+	LatencySampleEvery int `yaml:"latency_sample_every"`
+	// StalenessEnabled 是否输出 Follower 陈旧度 × Leader 变动分布（staleness.jsonl）
+	StalenessEnabled bool `yaml:"staleness_enabled"`
+	// StalenessIntervalMs 陈旧度分布统计周期（毫秒）
+	StalenessIntervalMs int `yaml:"staleness_interval_ms"`
+	// BarsEnabled 是否输出中间价与价差 K 线（bars.jsonl）
+	BarsEnabled bool `yaml:"bars_enabled"`
+	// BarIntervalsMs K 线周期列表（毫秒，默认 1s 与 1m）
+	BarIntervalsMs []int `yaml:"bar_intervals_ms"`
+	// LegacyLayout 旧输出布局：所有运行追加写入 output.dir 下同一组文件
+	// 默认每次 run 创建独立目录 <dir>/<时间>-<run_id>/，并将 <dir>/latest 指向最近一次运行。
+	LegacyLayout bool `yaml:"legacy_layout"`
+	// Sinks 按输出流附加的 sink（键为流名，见 OutputStreams）
+	// 与 *_enabled 控制的默认 JSONL 文件并行写入；流未启用默认文件时也可只写附加 sink。
+	Sinks map[string][]SinkConfig `yaml:"sinks"`
+}
+
+// OutputStreams 可附加 sink 的输出流
+var OutputStreams = []string{"signals", "paper_trades", "metrics", "books", "alerts", "positions", "leadlag", "spreads", "bars", "latency_samples", "staleness"}
+
+// 输出 sink 类型
+const (
+	// SinkTypeJSONL 本地 JSONL 文件
+	SinkTypeJSONL = "jsonl"
+	// SinkTypeUDP 每条记录一个 JSON 数据报发送到 UDP 地址（可由本机采集器转发至 Kafka 等）
+	SinkTypeUDP = "udp"
+)
+
+// SinkConfig 输出 sink 配置
+type SinkConfig struct {
+	// Type sink 类型（jsonl/udp）
+	Type string `yaml:"type"`
+	// Path 输出文件路径（type=jsonl）
+	Path string `yaml:"path"`
+	// Addr 目标地址 host:port（type=udp）
+	Addr string `yaml:"addr"`
+}
+
+// CheckpointConfig 运行状态检查点配置
+// 周期性保存未平仓仓位、EV 窗口、止损冷却与时延窗口，启动时恢复。
+// 启用时停机不强制平仓，未平仓仓位写入最终检查点，下次启动恢复后继续判断退出。
+type CheckpointConfig struct {
+	// Path 状态文件路径（为空表示不启用）
+	Path string `yaml:"path"`
+	// IntervalMs 保存间隔（毫秒）
+	IntervalMs int `yaml:"interval_ms"`
+	// MaxAgeMs 允许恢复的最大状态年龄（毫秒，0 表示不限制）
+	// 超过该年龄的状态视为过期（长时间停机后行情已变化），启动时忽略。
+	MaxAgeMs int64 `yaml:"max_age_ms"`
+}
+
+// ChaosConfig 故障注入测试模式配置
+// 在客户端 → 聚合器路径上按固定随机种子注入时延、丢弃、重复与断线风暴，
+// 使引擎/执行器/统计管线的健壮性可在回放或 CI 运行中确定性复现。
+type ChaosConfig struct {
+	// Enabled 是否启用
+	Enabled bool `yaml:"enabled"`
+	// Seed 随机种子（相同种子与输入得到相同的注入序列）
+	Seed int64 `yaml:"seed"`
+	// Exchanges 注入的交易所（为空表示全部）
+	Exchanges []string `yaml:"exchanges"`
+	// DropRate 单个事件被丢弃的概率（0-1）
+	DropRate float64 `yaml:"drop_rate"`
+	// DupRate 单个事件被重复投递的概率（0-1）
+	DupRate float64 `yaml:"dup_rate"`
+	// DelayRate 单个事件被注入到达时延的概率（0-1）
+	DelayRate float64 `yaml:"delay_rate"`
+	// DelayMaxMs 注入时延上限（毫秒，在 (0, delay_max_ms] 内均匀分布）
+	DelayMaxMs int `yaml:"delay_max_ms"`
+	// StormRate 每个事件触发断线风暴的概率（0-1）
+	StormRate float64 `yaml:"storm_rate"`
+	// StormCycles 每次风暴的断线次数
+	StormCycles int `yaml:"storm_cycles"`
+	// StormOutageEvents 每次断线丢弃的事件数
+	StormOutageEvents int `yaml:"storm_outage_events"`
+}
+
+// SyntheticConfig 合成 Follower 校准模式配置
+// 以 Leader 行情延迟固定时延并展开固定价差合成 Follower 行情，替代录制中的 Bittap 事件，
+// 使完整链路（引擎、执行器、EV）可在已知理论收益的数据上端到端校验。
+type SyntheticConfig struct {
+	// Enabled 是否启用
+	Enabled bool `yaml:"enabled"`
+	// Leader 合成来源的 Leader（okx/binance，默认 okx）
+	Leader string `yaml:"leader"`
+	// LagMs Follower 相对 Leader 的到达时延（毫秒）
+	LagMs int `yaml:"lag_ms"`
+	// SpreadBps 合成盘口的买卖价差（基点，必须大于 0）
+	SpreadBps float64 `yaml:"spread_bps"`
+}
+
+// BacktestConfig 回测配置
+type BacktestConfig struct {
+	// Workers 并行 goroutine 数（0 表示使用 CPU 核数）
+	Workers int `yaml:"workers"`
+	// Grid 参数网格（为空的维度沿用基础配置）
+	Grid GridConfig `yaml:"grid"`
+	// WalkForward 滚动训练/测试窗口配置
+	WalkForward WalkForwardConfig `yaml:"walkforward"`
+}
+
+// WalkForwardConfig 滚动前推（walk-forward）评估配置
+// 每个窗口：在训练段上扫描网格选出最优参数，再在紧随其后的测试段上样本外评估。
+type WalkForwardConfig struct {
+	// TrainMs 训练段长度（毫秒）
+	TrainMs int64 `yaml:"train_ms"`
+	// TestMs 测试段长度（毫秒）
+	TestMs int64 `yaml:"test_ms"`
+	// StepMs 窗口滚动步长（毫秒，0 表示等于 TestMs）
+	StepMs int64 `yaml:"step_ms"`
+	// MinTrades 训练段最少成交笔数，低于此值的参数组合不参与择优
+	MinTrades int64 `yaml:"min_trades"`
+}
+
+// GridConfig 参数扫描网格
+// 扫描组合数 = 各维度取值数之积。
+type GridConfig struct {
+	// ThetaEntryBps 入场阈值候选值（基点）
+	ThetaEntryBps []float64 `yaml:"theta_entry_bps"`
+	// PersistMs 持续时间候选值（毫秒）
+	PersistMs []int `yaml:"persist_ms"`
+	// TPRatio 止盈比例候选值
+	TPRatio []float64 `yaml:"tp_ratio"`
+	// SLRatio 止损比例候选值
+	SLRatio []float64 `yaml:"sl_ratio"`
 }
 
 // Load 从文件加载配置并验证
@@ -175,6 +798,17 @@ func (c *Config) setDefaults() {
 	if c.App.LogLevel == "" {
 		c.App.LogLevel = "info"
 	}
+	if c.App.LogFormat == "" {
+		c.App.LogFormat = "json"
+	}
+	if c.App.LogFile.Path != "" {
+		if c.App.LogFile.MaxSizeMB == 0 {
+			c.App.LogFile.MaxSizeMB = 100
+		}
+		if c.App.LogFile.MaxBackups == 0 {
+			c.App.LogFile.MaxBackups = 5
+		}
+	}
 
 	// 元数据 API 默认超时
 	if c.Metadata.TimeoutMs == 0 {
@@ -194,6 +828,90 @@ func (c *Config) setDefaults() {
 	if c.WS.Binance.ReadTimeoutMs == 0 {
 		c.WS.Binance.ReadTimeoutMs = 30000 // 30 秒
 	}
+	if c.WS.OKX.RestFallbackURL == "" {
+		c.WS.OKX.RestFallbackURL = "https://www.okx.com/api/v5/market/books"
+	}
+	if c.WS.Binance.RestFallbackURL == "" {
+		c.WS.Binance.RestFallbackURL = "https://fapi.binance.com/fapi/v1/depth"
+	}
+	if c.WS.Binance.DiffBook {
+		if c.WS.Binance.SnapshotURL == "" {
+			c.WS.Binance.SnapshotURL = "https://fapi.binance.com/fapi/v1/depth"
+		}
+		if c.WS.Binance.SnapshotLimit == 0 {
+			c.WS.Binance.SnapshotLimit = 1000
+		}
+	}
+	if c.ExchangeStatus.OKXURL == "" {
+		c.ExchangeStatus.OKXURL = "wss://ws.okx.com:8443/ws/v5/public"
+	}
+	if c.ExchangeStatus.BinanceURL == "" {
+		c.ExchangeStatus.BinanceURL = "https://api.binance.com/sapi/v1/system/status"
+	}
+	if c.ExchangeStatus.BinancePollIntervalMs == 0 {
+		c.ExchangeStatus.BinancePollIntervalMs = 60000 // 60 秒
+	}
+	if c.FeedGuard.MaxMessageAgeMs == 0 {
+		c.FeedGuard.MaxMessageAgeMs = 5000 // 5 秒
+	}
+	if c.WS.Binance.DepthUpdateMs == 0 {
+		c.WS.Binance.DepthUpdateMs = 100
+	}
+	if c.WS.OKX.Channel == "" {
+		c.WS.OKX.Channel = "books5"
+	}
+	for _, ws := range []*ExchangeWSConfig{&c.WS.OKX, &c.WS.Binance, &c.WS.Bittap} {
+		if ws.StaleTimeoutMs == 0 {
+			ws.StaleTimeoutMs = 60000 // 60 秒（大于各交易所心跳间隔）
+		}
+		if ws.BackoffResetAfterMs == 0 {
+			ws.BackoffResetAfterMs = 30000 // 30 秒
+		}
+		if ws.TCPNoDelay == nil {
+			noDelay := true
+			ws.TCPNoDelay = &noDelay
+		}
+		if ws.Backpressure == "" {
+			ws.Backpressure = BackpressureDropOldest
+		}
+		if ws.Backpressure == BackpressureBlock && ws.BlockTimeoutMs == 0 {
+			ws.BlockTimeoutMs = 50 // 50 毫秒
+		}
+		if ws.BookChSize == 0 {
+			ws.BookChSize = 1000
+		}
+		if ws.DepthLevels == 0 {
+			ws.DepthLevels = DefaultDepthLevels
+		}
+		if ws.ErrChSize == 0 {
+			ws.ErrChSize = 10
+		}
+		if ws.SubscribeChunkSize == 0 {
+			ws.SubscribeChunkSize = 50
+		}
+		if ws.SubscribeIntervalMs == 0 {
+			ws.SubscribeIntervalMs = 250 // 每秒不超过 4 帧
+		}
+		if ws.SubscribeAckTimeoutMs == 0 {
+			ws.SubscribeAckTimeoutMs = 5000 // 5 秒
+		}
+		if ws.SubscribeMaxRetries == 0 {
+			ws.SubscribeMaxRetries = 3
+		}
+		if ws.MaxMsgsPerSec == 0 {
+			ws.MaxMsgsPerSec = 5 // Binance 单连接约 5 条/秒
+		}
+		if ws.RestFallbackIntervalMs == 0 {
+			ws.RestFallbackIntervalMs = 2000 // 2 秒
+		}
+		if ws.MsgBurst == 0 {
+			// 默认允许 1 秒的突发量
+			ws.MsgBurst = int(ws.MaxMsgsPerSec)
+			if ws.MsgBurst < 1 {
+				ws.MsgBurst = 1
+			}
+		}
+	}
 
 	// 策略默认值
 	if c.Strategy.PersistMs == 0 {
@@ -202,11 +920,59 @@ func (c *Config) setDefaults() {
 	if c.Strategy.CooldownMs == 0 {
 		c.Strategy.CooldownMs = 3000 // 3 秒
 	}
+	if c.Strategy.MinDepthSide == "" {
+		c.Strategy.MinDepthSide = DepthSideBoth
+	}
 
 	// 影子成交默认值
 	if c.Paper.MaxHoldMs == 0 {
 		c.Paper.MaxHoldMs = 60000 // 60 秒
 	}
+	if c.Paper.ExitMode == "" {
+		c.Paper.ExitMode = ExitModeFixed
+	}
+	if c.Paper.SlippageMode == "" {
+		c.Paper.SlippageMode = SlippageModeFixed
+	}
+	if c.Paper.VolScaleWindowMs == 0 {
+		c.Paper.VolScaleWindowMs = 60000
+	}
+	if c.Paper.VolScaleMin == 0 {
+		c.Paper.VolScaleMin = 0.5
+	}
+	if c.Paper.VolScaleMax == 0 {
+		c.Paper.VolScaleMax = 2
+	}
+	if c.Paper.SlippageMode == SlippageModeSpread && c.Paper.SpreadWindow == 0 {
+		c.Paper.SpreadWindow = 500
+	}
+	if c.Paper.ExitMode == ExitModeTrailing && c.Paper.TrailLockRatio == 0 {
+		c.Paper.TrailLockRatio = 0.5
+	}
+	for sym, o := range c.Paper.Symbols {
+		if o.ExitMode == ExitModeTrailing && o.TrailLockRatio == 0 && c.Paper.TrailLockRatio == 0 {
+			o.TrailLockRatio = 0.5
+			c.Paper.Symbols[sym] = o
+		}
+	}
+
+	// 故障注入默认值
+	if c.Chaos.Enabled {
+		if c.Chaos.DelayMaxMs == 0 {
+			c.Chaos.DelayMaxMs = 50
+		}
+		if c.Chaos.StormCycles == 0 {
+			c.Chaos.StormCycles = 5
+		}
+		if c.Chaos.StormOutageEvents == 0 {
+			c.Chaos.StormOutageEvents = 20
+		}
+	}
+
+	// 合成 Follower 默认值
+	if c.Synthetic.Enabled && c.Synthetic.Leader == "" {
+		c.Synthetic.Leader = "okx"
+	}
 
 	// 输出默认值
 	if c.Output.Dir == "" {
@@ -215,9 +981,91 @@ func (c *Config) setDefaults() {
 	if c.Output.MetricsIntervalMs == 0 {
 		c.Output.MetricsIntervalMs = 10000 // 10 秒
 	}
+	if c.Output.LatencySampleEvery == 0 {
+		c.Output.LatencySampleEvery = 10
+	}
+	if c.Output.SpreadsIntervalMs == 0 {
+		c.Output.SpreadsIntervalMs = 1000
+	}
+	if c.Output.StalenessIntervalMs == 0 {
+		c.Output.StalenessIntervalMs = 60000
+	}
+	if len(c.Output.BarIntervalsMs) == 0 {
+		c.Output.BarIntervalsMs = []int{1000, 60000}
+	}
 	if c.Output.BufferSize == 0 {
 		c.Output.BufferSize = 1000
 	}
+	if c.Output.FlushIntervalMs == 0 {
+		c.Output.FlushIntervalMs = 1000
+	}
+
+	if c.EV.WindowSize == 0 {
+		c.EV.WindowSize = 1000
+	}
+
+	// 时延尖峰检测默认值
+	if c.Latency.SpikeRecentWindow == 0 {
+		c.Latency.SpikeRecentWindow = 200
+	}
+	if c.Latency.SpikeCheckIntervalMs == 0 {
+		c.Latency.SpikeCheckIntervalMs = 1000 // 1 秒
+	}
+	if c.Latency.LeaderMatchWindowMs == 0 {
+		c.Latency.LeaderMatchWindowMs = 1000 // 1 秒
+	}
+	if c.Latency.LeadShareMinMoveBps == 0 {
+		c.Latency.LeadShareMinMoveBps = 2
+	}
+	if c.Latency.LeadShareWindowMs == 0 {
+		c.Latency.LeadShareWindowMs = 1000 // 1 秒
+	}
+
+	// 互相关默认值：10ms 桶、±500ms 滞后、60 秒窗口、每分钟输出
+	if c.LeadLag.BucketMs == 0 {
+		c.LeadLag.BucketMs = 10
+	}
+	if c.LeadLag.MaxLagMs == 0 {
+		c.LeadLag.MaxLagMs = 500
+	}
+	if c.LeadLag.WindowMs == 0 {
+		c.LeadLag.WindowMs = 60000
+	}
+	if c.LeadLag.IntervalMs == 0 {
+		c.LeadLag.IntervalMs = 60000
+	}
+
+	// 信息份额默认值：1 秒采样、1 小时窗口、2 阶滞后、每分钟估计
+	if c.InfoShare.SampleMs == 0 {
+		c.InfoShare.SampleMs = 1000
+	}
+	if c.InfoShare.WindowMs == 0 {
+		c.InfoShare.WindowMs = 3600000
+	}
+	if c.InfoShare.Lags == 0 {
+		c.InfoShare.Lags = 2
+	}
+	if c.InfoShare.IntervalMs == 0 {
+		c.InfoShare.IntervalMs = 60000
+	}
+
+	// 检查点默认值（仅启用时）：每分钟保存，1 小时内的状态可恢复
+	if c.Checkpoint.Path != "" {
+		if c.Checkpoint.IntervalMs == 0 {
+			c.Checkpoint.IntervalMs = 60000
+		}
+		if c.Checkpoint.MaxAgeMs == 0 {
+			c.Checkpoint.MaxAgeMs = 3600 * 1000
+		}
+	}
+
+	// walk-forward 默认值：训练 6 小时、测试 1 小时
+	if c.Backtest.WalkForward.TrainMs == 0 {
+		c.Backtest.WalkForward.TrainMs = 6 * 3600 * 1000
+	}
+	if c.Backtest.WalkForward.TestMs == 0 {
+		c.Backtest.WalkForward.TestMs = 3600 * 1000
+	}
 }
 
 // Validate 验证配置合法性
@@ -230,10 +1078,18 @@ func (c *Config) Validate() error {
 	if len(c.Symbols) == 0 {
 		errs = append(errs, "symbols: 至少需要配置一个交易对")
 	}
+	seenSymbols := make(map[string]int, len(c.Symbols))
 	for i, sym := range c.Symbols {
 		if sym.Input == "" {
 			errs = append(errs, fmt.Sprintf("symbols[%d].input: 交易对不能为空", i))
+			continue
 		}
+		key := symbolKey(sym.Input)
+		if j, ok := seenSymbols[key]; ok {
+			errs = append(errs, fmt.Sprintf("symbols[%d].input: 交易对 '%s' 与 symbols[%d] 重复", i, sym.Input, j))
+			continue
+		}
+		seenSymbols[key] = i
 	}
 
 	// 验证元数据 API 配置
@@ -246,6 +1102,20 @@ func (c *Config) Validate() error {
 	if c.Metadata.Bittap == "" {
 		errs = append(errs, "metadata.bittap: Bittap 元数据 API 地址不能为空")
 	}
+	for _, u := range []struct{ field, raw string }{
+		{"metadata.okx", c.Metadata.OKX},
+		{"metadata.binance", c.Metadata.Binance},
+		{"metadata.bittap", c.Metadata.Bittap},
+		{"ws.okx.rest_fallback_url", c.WS.OKX.RestFallbackURL},
+		{"ws.binance.rest_fallback_url", c.WS.Binance.RestFallbackURL},
+		{"ws.bittap.rest_fallback_url", c.WS.Bittap.RestFallbackURL},
+		{"ws.binance.snapshot_url", c.WS.Binance.SnapshotURL},
+		{"exchange_status.binance_url", c.ExchangeStatus.BinanceURL},
+	} {
+		if err := validateURL(u.raw, u.field, "https"); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
 
 	// 验证 WebSocket 配置
 	if c.WS.OKX.URL == "" {
@@ -257,6 +1127,19 @@ func (c *Config) Validate() error {
 	if c.WS.Bittap.URL == "" {
 		errs = append(errs, "ws.bittap.url: Bittap WebSocket 地址不能为空")
 	}
+	for _, u := range []struct{ field, raw string }{
+		{"ws.okx.url", c.WS.OKX.URL},
+		{"ws.binance.url", c.WS.Binance.URL},
+		{"ws.bittap.url", c.WS.Bittap.URL},
+		{"ws.okx.backup_url", c.WS.OKX.BackupURL},
+		{"ws.binance.backup_url", c.WS.Binance.BackupURL},
+		{"ws.bittap.backup_url", c.WS.Bittap.BackupURL},
+		{"exchange_status.okx_url", c.ExchangeStatus.OKXURL},
+	} {
+		if err := validateURL(u.raw, u.field, "wss"); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
 
 	// 验证手续费配置（范围 0-1）
 	if err := validateFeeRate(c.Fees.Bittap.TakerRate, "fees.bittap.taker_rate"); err != nil {
@@ -268,6 +1151,23 @@ func (c *Config) Validate() error {
 	if err := validateFeeRate(c.Fees.Bittap.RebateRate, "fees.bittap.rebate_rate"); err != nil {
 		errs = append(errs, err.Error())
 	}
+	for _, lf := range []struct {
+		name string
+		fee  FeeDetail
+	}{{"okx", c.Fees.OKX}, {"binance", c.Fees.Binance}} {
+		if err := validateFeeRate(lf.fee.TakerRate, "fees."+lf.name+".taker_rate"); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if err := validateFeeRate(lf.fee.MakerRate, "fees."+lf.name+".maker_rate"); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if err := validateFeeRate(lf.fee.RebateRate, "fees."+lf.name+".rebate_rate"); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if err := validateFeeRate(c.Fees.BorrowRateAnnual, "fees.borrow_rate_annual"); err != nil {
+		errs = append(errs, err.Error())
+	}
 
 	// 验证策略参数
 	if c.Strategy.ThetaEntryBps <= 0 {
@@ -279,6 +1179,22 @@ func (c *Config) Validate() error {
 	if c.Strategy.CooldownMs < 0 {
 		errs = append(errs, "strategy.cooldown_ms: 冷却时间不能为负数")
 	}
+	if c.Strategy.MaxAdverseImbalance < 0 || c.Strategy.MaxAdverseImbalance > 1 {
+		errs = append(errs, fmt.Sprintf("strategy.max_adverse_imbalance: 必须在 [0, 1] 范围内，当前值: %v", c.Strategy.MaxAdverseImbalance))
+	}
+	for i, h := range c.Strategy.VolHorizons {
+		if h.WindowMs < 3000 {
+			errs = append(errs, fmt.Sprintf("strategy.vol_horizons[%d].window_ms: 至少 3000（按 1s 采样），当前值: %d", i, h.WindowMs))
+		}
+		if h.Threshold < 0 {
+			errs = append(errs, fmt.Sprintf("strategy.vol_horizons[%d].threshold: 不能为负数，当前值: %v", i, h.Threshold))
+		}
+	}
+	switch c.Strategy.MinDepthSide {
+	case "", DepthSideBid, DepthSideAsk, DepthSideBoth:
+	default:
+		errs = append(errs, fmt.Sprintf("strategy.min_depth_side: 必须为 bid/ask/both，当前值: %s", c.Strategy.MinDepthSide))
+	}
 
 	// 验证影子成交参数
 	if c.Paper.TPRatio < 0 || c.Paper.TPRatio > 1 {
@@ -293,6 +1209,379 @@ func (c *Config) Validate() error {
 	if c.Paper.SlippageBps < 0 {
 		errs = append(errs, "paper.slippage_bps: 滑点不能为负数")
 	}
+	if c.Paper.NotionalUSD < 0 {
+		errs = append(errs, "paper.notional_usd: 名义金额不能为负数")
+	}
+	if c.Paper.VolScaleRef < 0 {
+		errs = append(errs, "paper.vol_scale_ref: 参考波动率不能为负数")
+	}
+	if c.Paper.VolScaleRef > 0 {
+		if c.Paper.VolScaleWindowMs < 3000 {
+			errs = append(errs, fmt.Sprintf("paper.vol_scale_window_ms: 至少 3000（按 1s 采样），当前值: %d", c.Paper.VolScaleWindowMs))
+		}
+		if c.Paper.VolScaleMin <= 0 || c.Paper.VolScaleMax < c.Paper.VolScaleMin {
+			errs = append(errs, fmt.Sprintf("paper.vol_scale_min/vol_scale_max: 须满足 0 < min <= max，当前值: %v/%v", c.Paper.VolScaleMin, c.Paper.VolScaleMax))
+		}
+	}
+	if c.Paper.MaxPositionsPerSymbol < 0 {
+		errs = append(errs, "paper.max_positions_per_symbol: 单交易对最大仓位数不能为负数")
+	}
+	switch c.Paper.SlippageMode {
+	case "", SlippageModeFixed, SlippageModeSpread:
+	default:
+		errs = append(errs, fmt.Sprintf("paper.slippage_mode: 必须为 fixed/spread，当前值: %s", c.Paper.SlippageMode))
+	}
+	if c.Paper.QuotePersistMs < 0 {
+		errs = append(errs, "paper.quote_persist_ms: 报价持续时间不能为负数")
+	}
+	if c.Paper.SpreadWindow < 0 {
+		errs = append(errs, "paper.spread_window: 价差样本数不能为负数")
+	}
+	switch c.Paper.ExitMode {
+	case "", ExitModeFixed, ExitModeTrailing:
+	default:
+		errs = append(errs, fmt.Sprintf("paper.exit_mode: 必须为 fixed/trailing，当前值: %s", c.Paper.ExitMode))
+	}
+	if c.Paper.TrailLockRatio < 0 || c.Paper.TrailLockRatio > 1 {
+		errs = append(errs, "paper.trail_lock_ratio: 跟踪止盈锁定比例必须在 0-1 之间")
+	}
+	if c.Paper.TrailActivationRatio < 0 || c.Paper.TrailActivationRatio >= 1 {
+		errs = append(errs, "paper.trail_activation_ratio: 跟踪止盈启动比例必须在 [0, 1) 之间")
+	}
+	paperSymbols := make([]string, 0, len(c.Paper.Symbols))
+	for sym := range c.Paper.Symbols {
+		paperSymbols = append(paperSymbols, sym)
+	}
+	sort.Strings(paperSymbols)
+	for _, sym := range paperSymbols {
+		o := c.Paper.Symbols[sym]
+		if o.TPRatio < 0 || o.TPRatio > 1 {
+			errs = append(errs, fmt.Sprintf("paper.symbols.%s.tp_ratio: 止盈比例必须在 0-1 之间", sym))
+		}
+		if o.SLRatio < 0 {
+			errs = append(errs, fmt.Sprintf("paper.symbols.%s.sl_ratio: 止损比例不能为负数", sym))
+		}
+		if o.MaxHoldMs < 0 {
+			errs = append(errs, fmt.Sprintf("paper.symbols.%s.max_hold_ms: 最大持仓时间不能为负数", sym))
+		}
+		switch o.ExitMode {
+		case "", ExitModeFixed, ExitModeTrailing:
+		default:
+			errs = append(errs, fmt.Sprintf("paper.symbols.%s.exit_mode: 必须为 fixed/trailing，当前值: %s", sym, o.ExitMode))
+		}
+		if o.TrailLockRatio < 0 || o.TrailLockRatio > 1 {
+			errs = append(errs, fmt.Sprintf("paper.symbols.%s.trail_lock_ratio: 跟踪止盈锁定比例必须在 0-1 之间", sym))
+		}
+		if o.TrailActivationRatio < 0 || o.TrailActivationRatio >= 1 {
+			errs = append(errs, fmt.Sprintf("paper.symbols.%s.trail_activation_ratio: 跟踪止盈启动比例必须在 [0, 1) 之间", sym))
+		}
+	}
+
+	// 验证策略变体
+	variantNames := make(map[string]bool, len(c.Variants))
+	for i, v := range c.Variants {
+		if v.Name == "" {
+			errs = append(errs, fmt.Sprintf("variants[%d].name: 变体名称不能为空", i))
+		} else if variantNames[v.Name] {
+			errs = append(errs, fmt.Sprintf("variants[%d].name: 变体名称重复 '%s'", i, v.Name))
+		}
+		variantNames[v.Name] = true
+		if v.ThetaEntryBps < 0 {
+			errs = append(errs, fmt.Sprintf("variants[%d].theta_entry_bps: 入场阈值不能为负数", i))
+		}
+		if v.PersistMs < 0 {
+			errs = append(errs, fmt.Sprintf("variants[%d].persist_ms: 持续时间不能为负数", i))
+		}
+		if v.TPRatio < 0 || v.TPRatio > 1 {
+			errs = append(errs, fmt.Sprintf("variants[%d].tp_ratio: 止盈比例必须在 0-1 之间", i))
+		}
+		if v.SLRatio < 0 {
+			errs = append(errs, fmt.Sprintf("variants[%d].sl_ratio: 止损比例不能为负数", i))
+		}
+		if v.MaxHoldMs < 0 {
+			errs = append(errs, fmt.Sprintf("variants[%d].max_hold_ms: 最大持仓时间不能为负数", i))
+		}
+	}
+
+	// 验证回测参数网格
+	if c.Backtest.Workers < 0 {
+		errs = append(errs, "backtest.workers: 并行数不能为负数")
+	}
+	for i, v := range c.Backtest.Grid.ThetaEntryBps {
+		if v <= 0 {
+			errs = append(errs, fmt.Sprintf("backtest.grid.theta_entry_bps[%d]: 入场阈值必须为正数", i))
+		}
+	}
+	for i, v := range c.Backtest.Grid.PersistMs {
+		if v < 0 {
+			errs = append(errs, fmt.Sprintf("backtest.grid.persist_ms[%d]: 持续时间不能为负数", i))
+		}
+	}
+	for i, v := range c.Backtest.Grid.TPRatio {
+		if v < 0 || v > 1 {
+			errs = append(errs, fmt.Sprintf("backtest.grid.tp_ratio[%d]: 止盈比例必须在 0-1 之间", i))
+		}
+	}
+	for i, v := range c.Backtest.Grid.SLRatio {
+		if v < 0 {
+			errs = append(errs, fmt.Sprintf("backtest.grid.sl_ratio[%d]: 止损比例不能为负数", i))
+		}
+	}
+
+	// 验证故障注入参数
+	for _, r := range []struct {
+		name string
+		v    float64
+	}{{"drop_rate", c.Chaos.DropRate}, {"dup_rate", c.Chaos.DupRate}, {"delay_rate", c.Chaos.DelayRate}, {"storm_rate", c.Chaos.StormRate}} {
+		if r.v < 0 || r.v > 1 {
+			errs = append(errs, fmt.Sprintf("chaos.%s: 必须在 [0, 1] 范围内，当前值: %v", r.name, r.v))
+		}
+	}
+	if c.Chaos.DelayMaxMs < 0 || c.Chaos.StormCycles < 0 || c.Chaos.StormOutageEvents < 0 {
+		errs = append(errs, "chaos.delay_max_ms/storm_cycles/storm_outage_events: 不能为负数")
+	}
+	for _, ex := range c.Chaos.Exchanges {
+		switch ex {
+		case "okx", "binance", "bittap":
+		default:
+			errs = append(errs, fmt.Sprintf("chaos.exchanges: 未知交易所 %s（可选 okx/binance/bittap）", ex))
+		}
+	}
+
+	// 验证禁止开仓时段
+	for i, w := range c.Blackout.Daily {
+		if start, end, err := w.DailyRange(); err != nil {
+			errs = append(errs, fmt.Sprintf("blackout.daily[%d]: 时刻格式应为 HH:MM 或 HH:MM:SS: %v", i, err))
+		} else if start == end {
+			errs = append(errs, fmt.Sprintf("blackout.daily[%d]: 开始与结束时刻不能相同", i))
+		}
+	}
+	for i, w := range c.Blackout.Windows {
+		if start, end, err := w.AbsRange(); err != nil {
+			errs = append(errs, fmt.Sprintf("blackout.windows[%d]: 时间格式应为 RFC3339: %v", i, err))
+		} else if !end.After(start) {
+			errs = append(errs, fmt.Sprintf("blackout.windows[%d]: 结束时间必须晚于开始时间", i))
+		}
+	}
+
+	// 验证交易所维护公告监控
+	if c.ExchangeStatus.Enabled && c.ExchangeStatus.BinancePollIntervalMs <= 0 {
+		errs = append(errs, fmt.Sprintf("exchange_status.binance_poll_interval_ms: 必须为正数，当前值: %d", c.ExchangeStatus.BinancePollIntervalMs))
+	}
+
+	// 验证 Follower 行情降级保护
+	if c.FeedGuard.Enabled && c.FeedGuard.MaxMessageAgeMs <= 0 {
+		errs = append(errs, fmt.Sprintf("feed_guard.max_message_age_ms: 必须为正数，当前值: %d", c.FeedGuard.MaxMessageAgeMs))
+	}
+
+	// 验证合成 Follower 参数
+	if c.Synthetic.Enabled {
+		switch c.Synthetic.Leader {
+		case "okx", "binance":
+		default:
+			errs = append(errs, fmt.Sprintf("synthetic.leader: 必须是 okx 或 binance，当前值: %s", c.Synthetic.Leader))
+		}
+		if c.Synthetic.LagMs < 0 {
+			errs = append(errs, fmt.Sprintf("synthetic.lag_ms: 不能为负数，当前值: %d", c.Synthetic.LagMs))
+		}
+		if c.Synthetic.SpreadBps <= 0 {
+			errs = append(errs, fmt.Sprintf("synthetic.spread_bps: 必须大于 0，当前值: %v", c.Synthetic.SpreadBps))
+		}
+	}
+
+	if c.Output.MetricsIntervalMs <= 0 {
+		errs = append(errs, fmt.Sprintf("output.metrics_interval_ms: 必须为正数，当前值: %d", c.Output.MetricsIntervalMs))
+	}
+	if c.Output.BufferSize <= 0 {
+		errs = append(errs, fmt.Sprintf("output.buffer_size: 必须为正数，当前值: %d", c.Output.BufferSize))
+	}
+	if c.Output.FlushIntervalMs < 0 {
+		errs = append(errs, fmt.Sprintf("output.flush_interval_ms: 不能为负数，当前值: %d", c.Output.FlushIntervalMs))
+	}
+	if c.Output.LatencySampleEvery < 0 {
+		errs = append(errs, fmt.Sprintf("output.latency_sample_every: 不能为负数，当前值: %d", c.Output.LatencySampleEvery))
+	}
+	if c.Output.SpreadsIntervalMs < 0 {
+		errs = append(errs, fmt.Sprintf("output.spreads_interval_ms: 不能为负数，当前值: %d", c.Output.SpreadsIntervalMs))
+	}
+	if c.Output.StalenessIntervalMs < 0 {
+		errs = append(errs, fmt.Sprintf("output.staleness_interval_ms: 不能为负数，当前值: %d", c.Output.StalenessIntervalMs))
+	}
+	for i, ms := range c.Output.BarIntervalsMs {
+		if ms <= 0 {
+			errs = append(errs, fmt.Sprintf("output.bar_intervals_ms[%d]: 必须为正数，当前值: %d", i, ms))
+		}
+	}
+
+	// 验证附加输出 sink
+	for stream, sinks := range c.Output.Sinks {
+		known := false
+		for _, s := range OutputStreams {
+			known = known || s == stream
+		}
+		if !known {
+			errs = append(errs, fmt.Sprintf("output.sinks: 未知输出流 %s（可选 %s）", stream, strings.Join(OutputStreams, "/")))
+			continue
+		}
+		for i, s := range sinks {
+			switch s.Type {
+			case SinkTypeJSONL:
+				if s.Path == "" {
+					errs = append(errs, fmt.Sprintf("output.sinks.%s[%d].path: jsonl sink 必须配置文件路径", stream, i))
+				}
+			case SinkTypeUDP:
+				if s.Addr == "" {
+					errs = append(errs, fmt.Sprintf("output.sinks.%s[%d].addr: udp sink 必须配置目标地址", stream, i))
+				}
+			default:
+				errs = append(errs, fmt.Sprintf("output.sinks.%s[%d].type: 必须为 jsonl/udp，当前值: %s", stream, i, s.Type))
+			}
+		}
+	}
+
+	wsByName := []struct {
+		name string
+		ws   ExchangeWSConfig
+	}{{"okx", c.WS.OKX}, {"binance", c.WS.Binance}, {"bittap", c.WS.Bittap}}
+	for _, item := range wsByName {
+		name, ws := item.name, item.ws
+		switch ws.Backpressure {
+		case "", BackpressureDropNewest, BackpressureDropOldest, BackpressureBlock:
+		default:
+			errs = append(errs, fmt.Sprintf("ws.%s.backpressure: 必须为 drop_newest/drop_oldest/block，当前值: %s", name, ws.Backpressure))
+		}
+		if ws.BlockTimeoutMs < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.block_timeout_ms: 不能为负数", name))
+		}
+		if ws.PingIntervalMs < 0 || ws.PongTimeoutMs < 0 || ws.ReadTimeoutMs < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.ping_interval_ms/pong_timeout_ms/read_timeout_ms: 不能为负数", name))
+		} else if ws.PingIntervalMs > 0 && ws.ReadTimeoutMs > 0 && ws.PingIntervalMs >= ws.ReadTimeoutMs {
+			// 读超时在两次心跳之间到期会在空闲时段误判断线
+			errs = append(errs, fmt.Sprintf("ws.%s.ping_interval_ms: 必须小于 read_timeout_ms，当前值: %d/%d", name, ws.PingIntervalMs, ws.ReadTimeoutMs))
+		}
+		if ws.BookChSize < 0 || ws.ErrChSize < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.book_ch_size/err_ch_size: 不能为负数", name))
+		}
+		if ws.RawCaptureRate < 0 || ws.RawCaptureRate > 1 {
+			errs = append(errs, fmt.Sprintf("ws.%s.raw_capture_rate: 必须在 [0, 1] 范围内，当前值: %v", name, ws.RawCaptureRate))
+		}
+		if ws.SubscribeChunkSize < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.subscribe_chunk_size: 不能为负数", name))
+		}
+		if ws.SubscribeIntervalMs < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.subscribe_interval_ms: 不能为负数", name))
+		}
+		if ws.SubscribeAckTimeoutMs < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.subscribe_ack_timeout_ms: 不能为负数", name))
+		}
+		if ws.SubscribeMaxRetries < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.subscribe_max_retries: 不能为负数", name))
+		}
+		if ws.MaxMsgsPerSec < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.max_msgs_per_sec: 不能为负数", name))
+		}
+		if ws.MsgBurst < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.msg_burst: 不能为负数", name))
+		}
+		if ws.RestFallbackAfterMs < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.rest_fallback_after_ms: 不能为负数", name))
+		}
+		if ws.RestFallbackIntervalMs < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.rest_fallback_interval_ms: 不能为负数", name))
+		}
+		if ws.ReconnectMaxAttempts < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.reconnect_max_attempts: 不能为负数", name))
+		}
+		if ws.BackoffResetAfterMs < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.backoff_reset_after_ms: 不能为负数", name))
+		}
+		if ws.PinIP != "" && net.ParseIP(ws.PinIP) == nil {
+			errs = append(errs, fmt.Sprintf("ws.%s.pin_ip: 不是合法的 IP 地址: %s", name, ws.PinIP))
+		}
+		if ws.LocalAddr != "" && net.ParseIP(ws.LocalAddr) == nil {
+			errs = append(errs, fmt.Sprintf("ws.%s.local_addr: 不是合法的 IP 地址: %s", name, ws.LocalAddr))
+		}
+		if ws.SocketReadBuffer < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.socket_read_buffer: 不能为负数", name))
+		}
+		if ws.SocketWriteBuffer < 0 {
+			errs = append(errs, fmt.Sprintf("ws.%s.socket_write_buffer: 不能为负数", name))
+		}
+		if ws.DepthLevels != 0 && !slices.Contains(DepthLevelOptions[name], ws.DepthLevels) {
+			errs = append(errs, fmt.Sprintf("ws.%s.depth_levels: 可选值 %v，当前值: %d", name, DepthLevelOptions[name], ws.DepthLevels))
+		}
+	}
+
+	if ms := c.WS.Binance.DepthUpdateMs; ms != 0 && !slices.Contains(BinanceDepthUpdateOptions, ms) {
+		errs = append(errs, fmt.Sprintf("ws.binance.depth_update_ms: 可选值 %v，当前值: %d", BinanceDepthUpdateOptions, ms))
+	}
+	if c.WS.OKX.DepthUpdateMs != 0 || c.WS.Bittap.DepthUpdateMs != 0 {
+		errs = append(errs, "ws.*.depth_update_ms: 深度推送间隔仅支持 Binance")
+	}
+	if ch := c.WS.OKX.CompareChannel; ch != "" && !slices.Contains(OKXCompareChannels, ch) {
+		errs = append(errs, fmt.Sprintf("ws.okx.compare_channel: 可选值 %v，当前值: %s", OKXCompareChannels, ch))
+	}
+	if c.WS.Binance.CompareChannel != "" || c.WS.Bittap.CompareChannel != "" {
+		errs = append(errs, "ws.*.compare_channel: 频道对比仅支持 OKX")
+	}
+	if ch := c.WS.OKX.Channel; ch != "" && !slices.Contains(OKXChannels, ch) {
+		errs = append(errs, fmt.Sprintf("ws.okx.channel: 可选值 %v，当前值: %s", OKXChannels, ch))
+	}
+	if (c.WS.OKX.Channel == "" || c.WS.OKX.Channel == "books5") && c.WS.OKX.DepthLevels > 5 {
+		errs = append(errs, fmt.Sprintf("ws.okx.depth_levels: books5 最多 5 档，当前值: %d", c.WS.OKX.DepthLevels))
+	}
+	if c.WS.Binance.Channel != "" || c.WS.Bittap.Channel != "" {
+		errs = append(errs, "ws.*.channel: 行情频道选择仅支持 OKX")
+	}
+
+	if c.WS.OKX.DiffBook || c.WS.Bittap.DiffBook {
+		errs = append(errs, "ws.*.diff_book: 增量深度本地订单簿仅支持 Binance")
+	}
+	if c.WS.Binance.SnapshotLimit < 0 {
+		errs = append(errs, "ws.binance.snapshot_limit: 不能为负数")
+	}
+
+	if c.WS.Bittap.Redundant {
+		errs = append(errs, "ws.bittap.redundant: 冗余连接仅支持 Leader（okx/binance）")
+	}
+
+	if c.EV.WindowSize < 0 || c.EV.WindowMs < 0 {
+		errs = append(errs, "ev: window_size 与 window_ms 不能为负数")
+	}
+	if c.EV.EWMAAlpha < 0 || c.EV.EWMAAlpha > 1 {
+		errs = append(errs, fmt.Sprintf("ev.ewma_alpha: 必须在 [0, 1] 范围内，当前值: %v", c.EV.EWMAAlpha))
+	}
+
+	if c.Latency.SpikeFactor != 0 && c.Latency.SpikeFactor <= 1 {
+		errs = append(errs, fmt.Sprintf("latency.spike_factor: 必须大于 1（或为 0 表示不启用），当前值: %v", c.Latency.SpikeFactor))
+	}
+	if c.Latency.SpikeMinDeltaMs < 0 || c.Latency.SpikeRecentWindow < 0 || c.Latency.SpikeCheckIntervalMs < 0 {
+		errs = append(errs, "latency: spike_min_delta_ms、spike_recent_window 与 spike_check_interval_ms 不能为负数")
+	}
+	if c.Latency.WindowMs < 0 {
+		errs = append(errs, fmt.Sprintf("latency.window_ms: 不能为负数，当前值: %d", c.Latency.WindowMs))
+	}
+
+	if ll := c.LeadLag; ll.BucketMs < 0 || ll.MaxLagMs < 0 || ll.WindowMs < 0 || ll.IntervalMs < 0 {
+		errs = append(errs, "leadlag: bucket_ms、max_lag_ms、window_ms 与 interval_ms 不能为负数")
+	} else if ll.Enabled && (ll.BucketMs == 0 || ll.WindowMs <= ll.MaxLagMs) {
+		errs = append(errs, "leadlag: 启用时 bucket_ms 必须大于 0，且 window_ms 必须大于 max_lag_ms")
+	}
+
+	if is := c.InfoShare; is.SampleMs < 0 || is.WindowMs < 0 || is.Lags < 0 || is.IntervalMs < 0 {
+		errs = append(errs, "infoshare: sample_ms、window_ms、lags 与 interval_ms 不能为负数")
+	} else if is.Enabled && is.WindowMs/is.SampleMs < 10*(2+2*is.Lags) {
+		errs = append(errs, "infoshare: 启用时 window_ms / sample_ms 至少为 10 × (2 + 2 × lags)，否则样本不足以估计")
+	}
+
+	if c.Checkpoint.IntervalMs < 0 || c.Checkpoint.MaxAgeMs < 0 {
+		errs = append(errs, "checkpoint: interval_ms 与 max_age_ms 不能为负数")
+	}
+
+	wf := c.Backtest.WalkForward
+	if wf.TrainMs < 0 || wf.TestMs < 0 || wf.StepMs < 0 || wf.MinTrades < 0 {
+		errs = append(errs, "backtest.walkforward: 窗口长度、步长与最少成交数不能为负数")
+	}
 
 	// 验证日志级别
 	validLogLevels := map[string]bool{
@@ -301,6 +1590,17 @@ func (c *Config) Validate() error {
 	if !validLogLevels[strings.ToLower(c.App.LogLevel)] {
 		errs = append(errs, fmt.Sprintf("app.log_level: 无效的日志级别 '%s'，有效值: debug, info, warn, error", c.App.LogLevel))
 	}
+	for component, level := range c.App.LogLevels {
+		if !validLogLevels[strings.ToLower(level)] {
+			errs = append(errs, fmt.Sprintf("app.log_levels.%s: 无效的日志级别 '%s'，有效值: debug, info, warn, error", component, level))
+		}
+	}
+	if f := c.App.LogFormat; f != "" && f != "json" && f != "console" {
+		errs = append(errs, fmt.Sprintf("app.log_format: 无效的日志编码 '%s'，有效值: json, console", f))
+	}
+	if c.App.LogFile.MaxSizeMB < 0 || c.App.LogFile.MaxBackups < 0 {
+		errs = append(errs, "app.log_file: max_size_mb 与 max_backups 不能为负数")
+	}
 
 	if len(errs) > 0 {
 		return fmt.Errorf("配置验证错误:\n  - %s", strings.Join(errs, "\n  - "))
@@ -320,6 +1620,31 @@ func validateFeeRate(rate float64, field string) error {
 	return nil
 }
 
+// validateURL 验证地址格式与协议（为空时跳过，必填项由调用方单独检查）
+// 参数 raw: 地址
+// 参数 field: 字段名称，用于错误消息
+// 参数 scheme: 要求的协议，如 wss、https
+// 返回: 若地址无效则返回错误
+func validateURL(raw, field, scheme string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%s: 地址格式错误: %v", field, err)
+	}
+	if u.Scheme != scheme || u.Host == "" {
+		return fmt.Errorf("%s: 必须为 %s:// 地址，当前值: %s", field, scheme, raw)
+	}
+	return nil
+}
+
+// symbolKey 交易对去重键（忽略大小写与 -、_、/ 分隔符）
+func symbolKey(input string) string {
+	r := strings.NewReplacer("-", "", "_", "", "/", "")
+	return strings.ToUpper(r.Replace(strings.TrimSpace(input)))
+}
+
 // GetSymbolInputs 获取所有配置的交易对输入
 // 返回: 交易对输入字符串列表
 func (c *Config) GetSymbolInputs() []string {
@@ -330,6 +1655,33 @@ func (c *Config) GetSymbolInputs() []string {
 	return inputs
 }
 
+// VariantStrategy 返回变体生效的策略配置（未覆盖的字段沿用基础配置）
+func (c *Config) VariantStrategy(v VariantConfig) StrategyConfig {
+	out := c.Strategy
+	if v.ThetaEntryBps > 0 {
+		out.ThetaEntryBps = v.ThetaEntryBps
+	}
+	if v.PersistMs > 0 {
+		out.PersistMs = v.PersistMs
+	}
+	return out
+}
+
+// VariantPaper 返回变体生效的影子成交配置（未覆盖的字段沿用基础配置）
+func (c *Config) VariantPaper(v VariantConfig) PaperConfig {
+	out := c.Paper
+	if v.TPRatio > 0 {
+		out.TPRatio = v.TPRatio
+	}
+	if v.SLRatio > 0 {
+		out.SLRatio = v.SLRatio
+	}
+	if v.MaxHoldMs > 0 {
+		out.MaxHoldMs = v.MaxHoldMs
+	}
+	return out
+}
+
 // EffectiveTakerFee 计算有效 Taker 手续费（考虑返佣）
 // 返回: 有效手续费率
 func (f *FeeDetail) EffectiveTakerFee() float64 {
@@ -2,8 +2,10 @@
 package config
 
 import (
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/leanovate/gopter"
@@ -429,6 +431,48 @@ func TestEffectiveFee(t *testing.T) {
 	}
 }
 
+// TestLeaderFeesAndBorrowCost 测试 Leader 费率选择与资金成本折算
+func TestLeaderFeesAndBorrowCost(t *testing.T) {
+	fees := FeesConfig{
+		OKX:              FeeDetail{TakerRate: 0.0005},
+		Binance:          FeeDetail{TakerRate: 0.0004, RebateRate: 0.5},
+		BorrowRateAnnual: 0.365,
+	}
+	if got := fees.Leader("okx").TakerRate; got != 0.0005 {
+		t.Errorf("Leader(okx).TakerRate = %f, want 0.0005", got)
+	}
+	if got := fees.Leader("binance").TakerRate; got != 0.0004 {
+		t.Errorf("Leader(binance).TakerRate = %f, want 0.0004", got)
+	}
+	if got := fees.Leader("bittap"); got != (FeeDetail{}) {
+		t.Errorf("Leader(bittap) = %+v, want 零值", got)
+	}
+
+	// 对冲腿往返: 2 × taker × (1 - rebate) × 10000
+	for _, tt := range []struct {
+		leader string
+		want   float64
+	}{{"okx", 10}, {"binance", 4}, {"bittap", 0}} {
+		if got := fees.LeaderRoundTripFeeBps(tt.leader); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("LeaderRoundTripFeeBps(%s) = %f, want %f", tt.leader, got, tt.want)
+		}
+	}
+
+	// 年化 36.5% → 每日 0.1% = 10bps
+	if got := fees.BorrowCostBps(24 * 3600 * 1000); got < 10-1e-9 || got > 10+1e-9 {
+		t.Errorf("BorrowCostBps(1d) = %f, want 10", got)
+	}
+	if got := (FeesConfig{}).BorrowCostBps(60000); got != 0 {
+		t.Errorf("BorrowCostBps 未配置时 = %f, want 0", got)
+	}
+
+	cfg := createValidConfig()
+	cfg.Fees.Binance.TakerRate = 1.5
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "fees.binance.taker_rate") {
+		t.Errorf("Validate() err = %v, want fees.binance.taker_rate", err)
+	}
+}
+
 // TestGetSymbolInputs 测试获取交易对输入列表
 func TestGetSymbolInputs(t *testing.T) {
 	cfg := &Config{
@@ -447,3 +491,312 @@ func TestGetSymbolInputs(t *testing.T) {
 		t.Errorf("inputs[0] = %s, want BTC-USDT", inputs[0])
 	}
 }
+
+// TestVariantOverrides 测试策略变体覆盖与继承
+func TestVariantOverrides(t *testing.T) {
+	cfg := createValidConfig()
+	v := VariantConfig{Name: "wide", ThetaEntryBps: 20, SLRatio: 0.3}
+
+	st := cfg.VariantStrategy(v)
+	if st.ThetaEntryBps != 20 {
+		t.Errorf("ThetaEntryBps = %f, want 20", st.ThetaEntryBps)
+	}
+	if st.PersistMs != cfg.Strategy.PersistMs {
+		t.Errorf("PersistMs = %d, want %d（沿用基础配置）", st.PersistMs, cfg.Strategy.PersistMs)
+	}
+
+	pp := cfg.VariantPaper(v)
+	if pp.SLRatio != 0.3 {
+		t.Errorf("SLRatio = %f, want 0.3", pp.SLRatio)
+	}
+	if pp.TPRatio != cfg.Paper.TPRatio || pp.MaxHoldMs != cfg.Paper.MaxHoldMs {
+		t.Errorf("未覆盖字段应沿用基础配置: %+v", pp)
+	}
+}
+
+// TestPaperForSymbol 测试按交易对覆盖退出参数
+func TestPaperForSymbol(t *testing.T) {
+	cfg := createValidConfig()
+	cfg.Paper.Symbols = map[string]PaperSymbolConfig{
+		"SOLUSDT": {SLRatio: 0.3, MaxHoldMs: 20000},
+	}
+
+	sol := cfg.Paper.ForSymbol("SOLUSDT")
+	if sol.SLRatio != 0.3 || sol.MaxHoldMs != 20000 {
+		t.Errorf("SOLUSDT 覆盖未生效: %+v", sol)
+	}
+	if sol.TPRatio != cfg.Paper.TPRatio {
+		t.Errorf("TPRatio = %f, want %f（沿用全局配置）", sol.TPRatio, cfg.Paper.TPRatio)
+	}
+	if btc := cfg.Paper.ForSymbol("BTCUSDT"); btc.SLRatio != cfg.Paper.SLRatio || btc.MaxHoldMs != cfg.Paper.MaxHoldMs {
+		t.Errorf("未覆盖的交易对应沿用全局配置: %+v", btc)
+	}
+
+	off := false
+	cfg.Paper.ExitOnReversal = true
+	cfg.Paper.Symbols["XRPUSDT"] = PaperSymbolConfig{ExitMode: ExitModeTrailing, TrailLockRatio: 0.4, ExitOnReversal: &off}
+	if xrp := cfg.Paper.ForSymbol("XRPUSDT"); xrp.ExitMode != ExitModeTrailing || xrp.TrailLockRatio != 0.4 || xrp.ExitOnReversal {
+		t.Errorf("XRPUSDT 止盈方式与反转平仓覆盖未生效: %+v", xrp)
+	}
+	if sol := cfg.Paper.ForSymbol("SOLUSDT"); !sol.ExitOnReversal {
+		t.Errorf("未填 exit_on_reversal 应沿用全局配置: %+v", sol)
+	}
+
+	cfg.Paper.Symbols["ETHUSDT"] = PaperSymbolConfig{TPRatio: 1.5}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "paper.symbols.ETHUSDT.tp_ratio") {
+		t.Errorf("Validate() err = %v, want paper.symbols.ETHUSDT.tp_ratio", err)
+	}
+	cfg.Paper.Symbols["ETHUSDT"] = PaperSymbolConfig{ExitMode: "ladder"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "paper.symbols.ETHUSDT.exit_mode") {
+		t.Errorf("Validate() err = %v, want paper.symbols.ETHUSDT.exit_mode", err)
+	}
+}
+
+// TestConfigValidation_Variants 测试策略变体验证
+func TestConfigValidation_Variants(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []VariantConfig
+		wantErr  bool
+	}{
+		{"无变体", nil, false},
+		{"有效变体", []VariantConfig{{Name: "a", ThetaEntryBps: 10}, {Name: "b", PersistMs: 200}}, false},
+		{"名称为空", []VariantConfig{{ThetaEntryBps: 10}}, true},
+		{"名称重复", []VariantConfig{{Name: "a"}, {Name: "a"}}, true},
+		{"止盈比例越界", []VariantConfig{{Name: "a", TPRatio: 1.5}}, true},
+		{"负阈值", []VariantConfig{{Name: "a", ThetaEntryBps: -1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createValidConfig()
+			cfg.Variants = tt.variants
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidation_MinDepthSide(t *testing.T) {
+	for side, wantErr := range map[string]bool{
+		"":            false,
+		DepthSideBid:  false,
+		DepthSideAsk:  false,
+		DepthSideBoth: false,
+		"mid":         true,
+	} {
+		cfg := createValidConfig()
+		cfg.Strategy.MinDepthSide = side
+		if err := cfg.Validate(); (err != nil) != wantErr {
+			t.Errorf("min_depth_side=%q: Validate() err = %v, wantErr %v", side, err, wantErr)
+		}
+	}
+}
+
+func TestConfigValidation_DepthLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     func(cfg *Config)
+		wantErr bool
+	}{
+		{"默认", func(cfg *Config) {}, false},
+		{"Bittap 30 档", func(cfg *Config) { cfg.WS.Bittap.DepthLevels = 30 }, false},
+		{"Binance 20 档", func(cfg *Config) { cfg.WS.Binance.DepthLevels = 20 }, false},
+		{"OKX 仅支持 5 档", func(cfg *Config) { cfg.WS.OKX.DepthLevels = 10 }, true},
+		{"OKX books 频道 50 档", func(cfg *Config) { cfg.WS.OKX.Channel = "books"; cfg.WS.OKX.DepthLevels = 50 }, false},
+		{"OKX 需登录的频道不可用", func(cfg *Config) { cfg.WS.OKX.Channel = "books-l2-tbt" }, true},
+		{"Binance 无 30 档", func(cfg *Config) { cfg.WS.Binance.DepthLevels = 30 }, true},
+		{"负数", func(cfg *Config) { cfg.WS.Bittap.DepthLevels = -5 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createValidConfig()
+			tt.set(cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidation_Backpressure(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		timeout int
+		wantErr bool
+	}{
+		{"默认", "", 0, false},
+		{"丢弃最旧", BackpressureDropOldest, 0, false},
+		{"阻塞", BackpressureBlock, 20, false},
+		{"未知策略", "drop_all", 0, true},
+		{"负超时", BackpressureBlock, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createValidConfig()
+			cfg.WS.Bittap.Backpressure = tt.policy
+			cfg.WS.Bittap.BlockTimeoutMs = tt.timeout
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidation_Checkpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval int
+		maxAge   int
+		wantErr  bool
+	}{
+		{"默认", 0, 0, false},
+		{"正常", 60000, 3600000, false},
+		{"负间隔", -1, 0, true},
+		{"负过期时间", 60000, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createValidConfig()
+			cfg.Checkpoint = CheckpointConfig{Path: "output/checkpoint.json", IntervalMs: tt.interval, MaxAgeMs: int64(tt.maxAge)}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidation_OutputSinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		sinks   map[string][]SinkConfig
+		wantErr bool
+	}{
+		{"未配置", nil, false},
+		{"jsonl+udp", map[string][]SinkConfig{"signals": {{Type: SinkTypeJSONL, Path: "/tmp/s.jsonl"}, {Type: SinkTypeUDP, Addr: "127.0.0.1:9000"}}}, false},
+		{"未知流", map[string][]SinkConfig{"orders": {{Type: SinkTypeJSONL, Path: "/tmp/o.jsonl"}}}, true},
+		{"未知类型", map[string][]SinkConfig{"signals": {{Type: "kafka"}}}, true},
+		{"jsonl 缺路径", map[string][]SinkConfig{"metrics": {{Type: SinkTypeJSONL}}}, true},
+		{"udp 缺地址", map[string][]SinkConfig{"alerts": {{Type: SinkTypeUDP}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createValidConfig()
+			cfg.Output.Sinks = tt.sinks
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidation_URLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(c *Config)
+		wantErr bool
+	}{
+		{"默认", func(c *Config) {}, false},
+		{"带端口与查询参数", func(c *Config) { c.WS.Bittap.URL = "wss://stream.bittap.com:443/endpoint?format=JSON" }, false},
+		{"ws 明文", func(c *Config) { c.WS.OKX.URL = "ws://ws.okx.com:8443/ws/v5/public" }, true},
+		{"https 用于 WS", func(c *Config) { c.WS.Binance.URL = "https://fstream.binance.com/ws" }, true},
+		{"缺少协议", func(c *Config) { c.WS.Bittap.URL = "stream.bittap.com/endpoint" }, true},
+		{"backup_url 协议错误", func(c *Config) { c.WS.OKX.BackupURL = "http://ws.okx.com/ws/v5/public" }, true},
+		{"元数据 http", func(c *Config) { c.Metadata.Binance = "http://fapi.binance.com/fapi/v1/exchangeInfo" }, true},
+		{"元数据 wss", func(c *Config) { c.Metadata.OKX = "wss://www.okx.com/api/v5/public/instruments" }, true},
+		{"REST 回退缺少主机", func(c *Config) { c.WS.OKX.RestFallbackURL = "https:///api/v5/market/books" }, true},
+		{"快照地址格式错误", func(c *Config) { c.WS.Binance.SnapshotURL = "https://fapi.binance.com/%zz" }, true},
+		{"状态监控 OKX 地址", func(c *Config) { c.ExchangeStatus.OKXURL = "https://ws.okx.com:8443/ws/v5/public" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createValidConfig()
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidation_NumericRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(c *Config)
+		wantErr bool
+	}{
+		{"默认", func(c *Config) {}, false},
+		{"指标间隔为 0", func(c *Config) { c.Output.MetricsIntervalMs = 0 }, true},
+		{"指标间隔为负", func(c *Config) { c.Output.MetricsIntervalMs = -1000 }, true},
+		{"缓冲区为 0", func(c *Config) { c.Output.BufferSize = 0 }, true},
+		{"心跳间隔小于读超时", func(c *Config) { c.WS.Binance.PingIntervalMs = 20000 }, false},
+		{"心跳间隔等于读超时", func(c *Config) { c.WS.Binance.PingIntervalMs = 30000 }, true},
+		{"心跳间隔大于读超时", func(c *Config) { c.WS.OKX.ReadTimeoutMs = 20000 }, true},
+		{"未设置读超时", func(c *Config) { c.WS.Bittap.ReadTimeoutMs = 0 }, false},
+		{"负心跳间隔", func(c *Config) { c.WS.Bittap.PingIntervalMs = -1 }, true},
+		{"负读超时", func(c *Config) { c.WS.Binance.ReadTimeoutMs = -1 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createValidConfig()
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidation_DuplicateSymbols(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  []string
+		wantErr bool
+	}{
+		{"不重复", []string{"BTC-USDT", "ETH-USDT"}, false},
+		{"完全相同", []string{"BTC-USDT", "ETH-USDT", "BTC-USDT"}, true},
+		{"大小写与分隔符不同", []string{"BTC-USDT", "btc_usdt"}, true},
+		{"无分隔符", []string{"BTCUSDT", "BTC/USDT"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createValidConfig()
+			cfg.Symbols = nil
+			for _, in := range tt.inputs {
+				cfg.Symbols = append(cfg.Symbols, SymbolConfig{Input: in})
+			}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestConfigValidation_ReportsAllViolations 多项错误应在一次验证中全部返回
+func TestConfigValidation_ReportsAllViolations(t *testing.T) {
+	cfg := createValidConfig()
+	cfg.WS.OKX.URL = "ws://ws.okx.com:8443/ws/v5/public"
+	cfg.Output.BufferSize = 0
+	cfg.Output.MetricsIntervalMs = 0
+	cfg.Symbols = append(cfg.Symbols, SymbolConfig{Input: "BTCUSDT"})
+	cfg.WS.OKX.ReadTimeoutMs = 10000
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("期望验证失败")
+	}
+	for _, field := range []string{"ws.okx.url", "output.buffer_size", "output.metrics_interval_ms", "symbols[1].input", "ws.okx.ping_interval_ms"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("错误信息缺少 %s: %v", field, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package blackout 判断当前时刻是否处于禁止开仓时段。
+// 时段包括每日重复时段（如资金费结算前后）与一次性时段（如计划维护、重大数据发布）。
+package blackout
+
+import (
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+// dayNs 一天的纳秒数
+const dayNs = int64(24 * time.Hour)
+
+// daily 每日重复时段（距 UTC 零点的纳秒偏移）
+type daily struct {
+	name    string
+	startNs int64
+	endNs   int64
+}
+
+// window 一次性时段（Unix 纳秒）
+type window struct {
+	name    string
+	startNs int64
+	endNs   int64
+}
+
+// Calendar 禁止开仓时段日历（构建后只读，可并发查询）
+type Calendar struct {
+	daily   []daily
+	windows []window
+}
+
+// New 由配置构建日历（配置应已通过校验，无法解析的时段被忽略）
+// 未配置任何时段时返回 nil。
+func New(cfg config.BlackoutConfig) *Calendar {
+	c := &Calendar{}
+	for _, w := range cfg.Daily {
+		start, end, err := w.DailyRange()
+		if err != nil {
+			continue
+		}
+		c.daily = append(c.daily, daily{name: w.Name, startNs: int64(start), endNs: int64(end)})
+	}
+	for _, w := range cfg.Windows {
+		start, end, err := w.AbsRange()
+		if err != nil {
+			continue
+		}
+		c.windows = append(c.windows, window{name: w.Name, startNs: start.UnixNano(), endNs: end.UnixNano()})
+	}
+	if len(c.daily) == 0 && len(c.windows) == 0 {
+		return nil
+	}
+	return c
+}
+
+// Active 判断时刻是否处于禁止开仓时段
+// 返回: (命中的时段名称, 是否命中)；nil 日历始终返回 false。
+func (c *Calendar) Active(nowNs int64) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	for _, w := range c.windows {
+		if nowNs >= w.startNs && nowNs < w.endNs {
+			return w.name, true
+		}
+	}
+	tod := nowNs % dayNs
+	if tod < 0 {
+		tod += dayNs
+	}
+	for _, d := range c.daily {
+		if d.startNs < d.endNs {
+			if tod >= d.startNs && tod < d.endNs {
+				return d.name, true
+			}
+		} else if tod >= d.startNs || tod < d.endNs {
+			// 跨零点
+			return d.name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+// Package blackout 禁止开仓时段日历测试
+package blackout
+
+import (
+	"testing"
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+func TestCalendar_Active(t *testing.T) {
+	c := New(config.BlackoutConfig{
+		Daily: []config.BlackoutWindowConfig{
+			{Name: "funding-00", Start: "23:59", End: "00:01"},
+			{Name: "funding-08", Start: "07:59:30", End: "08:00:30"},
+		},
+		Windows: []config.BlackoutWindowConfig{
+			{Name: "cpi", Start: "2026-11-12T13:29:00Z", End: "2026-11-12T13:35:00Z"},
+		},
+	})
+	at := func(s string) int64 {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts.UnixNano()
+	}
+
+	tests := []struct {
+		ts     string
+		want   string
+		active bool
+	}{
+		{"2026-11-12T23:59:30Z", "funding-00", true},
+		{"2026-11-13T00:00:59Z", "funding-00", true},
+		{"2026-11-13T00:01:00Z", "", false},
+		{"2026-11-12T07:59:29Z", "", false},
+		{"2026-11-12T08:00:00Z", "funding-08", true},
+		{"2026-11-12T13:30:00Z", "cpi", true},
+		{"2026-11-13T13:30:00Z", "", false},
+	}
+	for _, tt := range tests {
+		name, active := c.Active(at(tt.ts))
+		if name != tt.want || active != tt.active {
+			t.Errorf("%s: got (%q, %v), want (%q, %v)", tt.ts, name, active, tt.want, tt.active)
+		}
+	}
+
+	if New(config.BlackoutConfig{}) != nil {
+		t.Fatalf("未配置时段时应返回 nil")
+	}
+	if _, active := (*Calendar)(nil).Active(at("2026-11-12T23:59:30Z")); active {
+		t.Fatalf("nil 日历不应命中")
+	}
+}
@@ -52,9 +52,10 @@ type BookEvent struct {
 	BestAskPx float64
 	// BestAskQty 最优卖量（卖一量）
 	BestAskQty float64
-	// Levels 深度档位列表（Top 5）
-	// 包含买卖双方的深度信息
-	Levels []Level
+	// Bids 买盘深度档位（价格降序，档位数由 ws.<exchange>.depth_levels 决定，默认 5）
+	Bids []Level
+	// Asks 卖盘深度档位（价格升序，档位数同 Bids）
+	Asks []Level
 	// ArrivedAtUnixNs 本机收到消息的时间戳（纳秒）
 	// 用于计算 lead-lag 延迟，是延迟统计的主基准
 	ArrivedAtUnixNs int64
@@ -66,8 +67,17 @@ type BookEvent struct {
 	// Seq 序列号
 	// OKX: seqId 字段
 	// Bittap: lastUpdateId 字段
-	// Binance: 无此字段，设为 0
+	// Binance: u 字段（final updateId）
 	Seq int64
+	// ParsedAtUnixNs 解析完成、送入通道前的本机时间（纳秒）
+	// 仅用于统计本进程管线耗时，不参与录制
+	ParsedAtUnixNs int64 `json:"-"`
+	// Degraded 是否为 WS 断线期间 REST 轮询得到的降级行情
+	// 降级行情的到达时间受轮询间隔支配，不参与时延统计与开仓信号，仅用于价差监控与已有仓位的退出判断。
+	Degraded bool `json:",omitempty"`
+
+	// pooled 是否取自对象池（AcquireBookEvent），决定 Release 是否归还
+	pooled bool
 }
 
 // IsValid 检查订单簿事件是否有效
@@ -98,12 +108,43 @@ func (b *BookEvent) SpreadBps() float64 {
 	return (b.BestAskPx - b.BestBidPx) / mid * 10000
 }
 
-// Top5DepthUSD 计算前 5 档深度的 USD 价值
-// 用于深度过滤器
-func (b *BookEvent) Top5DepthUSD() float64 {
+// Imbalance 计算最优档挂单量失衡度
+// 公式: (BestBidQty - BestAskQty) / (BestBidQty + BestAskQty)，范围 [-1, 1]
+// 正值表示买盘更厚（价格倾向上行）；两侧均无挂单量时返回 0。
+func (b *BookEvent) Imbalance() float64 {
+	total := b.BestBidQty + b.BestAskQty
+	if total <= 0 {
+		return 0
+	}
+	return (b.BestBidQty - b.BestAskQty) / total
+}
+
+// Microprice 计算按最优档挂单量加权的微观价格
+// 公式: (BestBidPx * BestAskQty + BestAskPx * BestBidQty) / (BestBidQty + BestAskQty)
+// 买盘越厚越靠近卖价；两侧均无挂单量时退化为中间价。
+func (b *BookEvent) Microprice() float64 {
+	total := b.BestBidQty + b.BestAskQty
+	if total <= 0 {
+		return b.MidPrice()
+	}
+	return (b.BestBidPx*b.BestAskQty + b.BestAskPx*b.BestBidQty) / total
+}
+
+// Top5BidDepthUSD 计算买盘前 5 档深度的 USD 价值
+func (b *BookEvent) Top5BidDepthUSD() float64 {
+	return depthUSD(b.Bids, 5)
+}
+
+// Top5AskDepthUSD 计算卖盘前 5 档深度的 USD 价值
+func (b *BookEvent) Top5AskDepthUSD() float64 {
+	return depthUSD(b.Asks, 5)
+}
+
+// depthUSD 计算单侧前 n 档的名义价值
+func depthUSD(levels []Level, n int) float64 {
 	var total float64
-	for i, level := range b.Levels {
-		if i >= 5 {
+	for i, level := range levels {
+		if i >= n {
 			break
 		}
 		total += level.Price * level.Qty
@@ -111,6 +152,46 @@ func (b *BookEvent) Top5DepthUSD() float64 {
 	return total
 }
 
+// VWAPForNotional 按交易方向逐档吃单，计算成交名义金额 usd 的平均成交价与可成交比例
+// 多头（买入）吃卖盘 Asks，空头（卖出）吃买盘 Bids；无深度档位时以最优档代替。
+// 返回: (已成交部分的成交均价, 可成交比例 [0, 1])；usd <= 0 时返回最优价与 1，盘口为空时返回 (0, 0)。
+func (b *BookEvent) VWAPForNotional(side Side, usd float64) (avgPx, filled float64) {
+	levels := b.Asks
+	best := Level{Price: b.BestAskPx, Qty: b.BestAskQty}
+	if side == SideShort {
+		levels = b.Bids
+		best = Level{Price: b.BestBidPx, Qty: b.BestBidQty}
+	}
+	if len(levels) == 0 {
+		levels = []Level{best}
+	}
+	if usd <= 0 {
+		if levels[0].Price <= 0 {
+			return 0, 0
+		}
+		return levels[0].Price, 1
+	}
+	var notional, qty float64
+	for _, level := range levels {
+		if level.Price <= 0 || level.Qty <= 0 {
+			continue
+		}
+		take := level.Price * level.Qty
+		if take > usd-notional {
+			take = usd - notional
+		}
+		notional += take
+		qty += take / level.Price
+		if notional >= usd {
+			break
+		}
+	}
+	if qty == 0 {
+		return 0, 0
+	}
+	return notional / qty, notional / usd
+}
+
 // ArrivedAt 获取到达时间的 time.Time 表示
 func (b *BookEvent) ArrivedAt() time.Time {
 	return time.Unix(0, b.ArrivedAtUnixNs)
@@ -125,12 +206,17 @@ func (b *BookEvent) ExchTs() time.Time {
 	return time.UnixMilli(b.ExchTsUnixMs)
 }
 
-// Clone 创建 BookEvent 的深拷贝
+// Clone 创建 BookEvent 的深拷贝（副本不属于对象池）
 func (b *BookEvent) Clone() *BookEvent {
 	clone := *b
-	if b.Levels != nil {
-		clone.Levels = make([]Level, len(b.Levels))
-		copy(clone.Levels, b.Levels)
+	clone.pooled = false
+	if b.Bids != nil {
+		clone.Bids = make([]Level, len(b.Bids))
+		copy(clone.Bids, b.Bids)
+	}
+	if b.Asks != nil {
+		clone.Asks = make([]Level, len(b.Asks))
+		copy(clone.Asks, b.Asks)
 	}
 	return &clone
 }
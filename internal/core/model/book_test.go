@@ -0,0 +1,67 @@
+// Package model 订单簿事件特征测试
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBookEvent_ImbalanceMicroprice(t *testing.T) {
+	tests := []struct {
+		name      string
+		bidQty    float64
+		askQty    float64
+		wantImb   float64
+		wantMicro float64
+	}{
+		{"均衡", 1, 1, 0, 100.5},
+		{"买盘更厚", 3, 1, 0.5, 100.75},
+		{"卖盘更厚", 1, 3, -0.5, 100.25},
+		{"无挂单量", 0, 0, 0, 100.5},
+	}
+	for _, tt := range tests {
+		ev := &BookEvent{BestBidPx: 100, BestBidQty: tt.bidQty, BestAskPx: 101, BestAskQty: tt.askQty}
+		if got := ev.Imbalance(); math.Abs(got-tt.wantImb) > 1e-12 {
+			t.Errorf("%s: Imbalance=%v, want %v", tt.name, got, tt.wantImb)
+		}
+		if got := ev.Microprice(); math.Abs(got-tt.wantMicro) > 1e-12 {
+			t.Errorf("%s: Microprice=%v, want %v", tt.name, got, tt.wantMicro)
+		}
+	}
+}
+
+func TestBookEvent_VWAPForNotional(t *testing.T) {
+	ev := &BookEvent{
+		BestBidPx: 99, BestBidQty: 1, BestAskPx: 100, BestAskQty: 1,
+		Bids: []Level{{Price: 99, Qty: 1}, {Price: 98, Qty: 2}},
+		Asks: []Level{{Price: 100, Qty: 1}, {Price: 102, Qty: 1}},
+	}
+	tests := []struct {
+		name       string
+		side       Side
+		usd        float64
+		wantPx     float64
+		wantFilled float64
+	}{
+		{"多头一档内", SideLong, 50, 100, 1},
+		{"多头跨两档", SideLong, 151, 151 / 1.5, 1},
+		{"多头深度不足", SideLong, 404, 101, 0.5},
+		{"空头跨两档", SideShort, 197, 197 / 2.0, 1},
+		{"金额为 0", SideShort, 0, 99, 1},
+	}
+	for _, tt := range tests {
+		px, filled := ev.VWAPForNotional(tt.side, tt.usd)
+		if math.Abs(px-tt.wantPx) > 1e-9 || math.Abs(filled-tt.wantFilled) > 1e-12 {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", tt.name, px, filled, tt.wantPx, tt.wantFilled)
+		}
+	}
+
+	// 无深度档位时以最优档代替
+	bbo := &BookEvent{BestBidPx: 99, BestBidQty: 1, BestAskPx: 100, BestAskQty: 1}
+	if px, filled := bbo.VWAPForNotional(SideLong, 200); px != 100 || filled != 0.5 {
+		t.Errorf("BBO 回退: got (%v, %v), want (100, 0.5)", px, filled)
+	}
+	if px, filled := (&BookEvent{}).VWAPForNotional(SideLong, 100); px != 0 || filled != 0 {
+		t.Errorf("空盘口: got (%v, %v), want (0, 0)", px, filled)
+	}
+}
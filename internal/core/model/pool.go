@@ -0,0 +1,37 @@
+package model
+
+import "sync"
+
+// pooledLevelsCap 对象池中 BookEvent 每侧预分配的档位容量
+const pooledLevelsCap = 5
+
+// bookEventPool BookEvent 对象池（连同 Bids/Asks 底层数组一并复用）
+var bookEventPool = sync.Pool{
+	New: func() any {
+		return &BookEvent{
+			Bids: make([]Level, 0, pooledLevelsCap),
+			Asks: make([]Level, 0, pooledLevelsCap),
+		}
+	},
+}
+
+// AcquireBookEvent 从对象池获取一个清零的 BookEvent（Bids/Asks 长度为 0，保留容量）
+// 事件应由最后一个持有者调用 Release 归还；未归还时由 GC 回收，不影响正确性。
+func AcquireBookEvent() *BookEvent {
+	ev := bookEventPool.Get().(*BookEvent)
+	ev.pooled = true
+	return ev
+}
+
+// Release 将事件归还对象池
+// 仅对 AcquireBookEvent 获取的事件生效，其它事件（字面量构造、Clone 副本）为空操作。
+// 每次获取必须恰好归还一次：归还后该对象可能已被其它调用方重新获取，
+// 再次调用 Release 会把别人持有的事件放回池中。
+// 注意：归还后调用方不得再访问该事件及其档位。
+func (b *BookEvent) Release() {
+	if b == nil || !b.pooled {
+		return
+	}
+	*b = BookEvent{Bids: b.Bids[:0], Asks: b.Asks[:0]}
+	bookEventPool.Put(b)
+}
@@ -0,0 +1,61 @@
+// Package model 订单簿事件对象池测试
+package model
+
+import "testing"
+
+func TestBookEvent_Release(t *testing.T) {
+	ev := AcquireBookEvent()
+	ev.Exchange = ExchangeOKX
+	ev.BestBidPx = 100
+	ev.Bids = append(ev.Bids, Level{Price: 100, Qty: 1})
+	clone := ev.Clone()
+
+	ev.Release()
+	if ev.Exchange != "" || ev.BestBidPx != 0 || len(ev.Bids) != 0 || cap(ev.Bids) == 0 || cap(ev.Asks) == 0 {
+		t.Fatalf("归还后应清零并保留档位容量: %+v", ev)
+	}
+	if ev.pooled {
+		t.Fatal("归还后应清除对象池标记")
+	}
+
+	// 副本与字面量构造的事件不属于对象池
+	clone.Release()
+	if clone.Exchange != ExchangeOKX || len(clone.Bids) != 1 {
+		t.Fatalf("Clone 副本不应被归还: %+v", clone)
+	}
+	lit := &BookEvent{Exchange: ExchangeBittap}
+	lit.Release()
+	if lit.Exchange != ExchangeBittap {
+		t.Fatalf("字面量事件不应被归还: %+v", lit)
+	}
+}
+
+// BenchmarkBookEvent_New 每次新分配事件与档位（未启用对象池）
+func BenchmarkBookEvent_New(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ev := &BookEvent{Exchange: ExchangeOKX, Bids: make([]Level, 0, pooledLevelsCap), Asks: make([]Level, 0, pooledLevelsCap)}
+		for j := 0; j < pooledLevelsCap; j++ {
+			ev.Bids = append(ev.Bids, Level{Price: float64(j), Qty: 1})
+			ev.Asks = append(ev.Asks, Level{Price: float64(j), Qty: 1})
+		}
+		sinkEvent = ev
+	}
+}
+
+// BenchmarkBookEvent_Pooled 对象池获取/归还
+func BenchmarkBookEvent_Pooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ev := AcquireBookEvent()
+		ev.Exchange = ExchangeOKX
+		for j := 0; j < pooledLevelsCap; j++ {
+			ev.Bids = append(ev.Bids, Level{Price: float64(j), Qty: 1})
+			ev.Asks = append(ev.Asks, Level{Price: float64(j), Qty: 1})
+		}
+		ev.Release()
+	}
+}
+
+// sinkEvent 防止编译器优化掉基准中的分配
+var sinkEvent *BookEvent
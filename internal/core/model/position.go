@@ -18,6 +18,24 @@ const (
 	// ExitTimeout 超时退出
 	// 当持仓时间超过 max_hold_ms 时触发
 	ExitTimeout ExitReason = "timeout"
+	// ExitShutdown 停机退出
+	// 优雅关闭（或回放结束）时以最后已知的 Follower 报价强制平仓，避免未平仓仓位从统计中消失
+	ExitShutdown ExitReason = "shutdown"
+	// ExitTrail 跟踪止盈退出
+	// exit_mode=trailing 时，价差从最佳收敛点回撤到仅剩 trail_lock_ratio × 最佳收敛幅度时触发
+	ExitTrail ExitReason = "trail"
+	// ExitReversal 价差反转退出
+	// exit_on_reversal=true 时，带符号价差穿越零轴（Leader 不再领先）时触发
+	ExitReversal ExitReason = "reversal"
+	// ExitEVFlip EV 恶化退出
+	// ev_flip_exit=true 时，持仓期间该交易对的滚动 EV 转负时触发
+	ExitEVFlip ExitReason = "ev_flip"
+	// ExitBlackout 禁止开仓时段退出
+	// 进入 blackout 配置的时段（资金费结算、计划维护等）时以当前 Follower 报价平仓
+	ExitBlackout ExitReason = "blackout"
+	// ExitFeedDegraded Follower 行情降级退出
+	// feed_guard.flatten=true 时，Bittap 连接断开重连或长时间无消息时以最后已知报价平仓
+	ExitFeedDegraded ExitReason = "feed_degraded"
 )
 
 // Position 影子仓位
@@ -25,6 +43,8 @@ const (
 type Position struct {
 	// ID 仓位唯一标识
 	ID string
+	// SignalID 触发开仓的信号 ID（与 signals.jsonl 中的 ID 一致）
+	SignalID string
 	// Leader 领先交易所标识: okx 或 binance
 	Leader string
 	// SymbolCanon 统一交易对标识
@@ -41,6 +61,16 @@ type Position struct {
 	EntryTime time.Time
 	// EntryTimeNs 入场时间（纳秒时间戳）
 	EntryTimeNs int64
+	// PendingFillNs 待成交时刻（纳秒，latency_fill 模式；0 表示已成交）
+	// 成交前 EntryPx 为 0，仓位不参与退出判断
+	PendingFillNs int64
+	// FillDelayMs 信号到成交的模拟延迟（毫秒，latency_fill 模式）
+	FillDelayMs float64
+	// EntryQuotePx 成交时 Follower 的原始最优价（不含滑点；long 为 ask，short 为 bid）
+	EntryQuotePx float64
+	// QuoteConfirmNs 报价持续校验截止时刻（纳秒，quote_persist_ms 模式；0 表示已确认）
+	// 确认前仓位不参与退出判断
+	QuoteConfirmNs int64
 	// ExitPx 出场价格
 	// long: 使用 Follower.BestBid
 	// short: 使用 Follower.BestAsk
@@ -49,7 +79,7 @@ type Position struct {
 	ExitTime time.Time
 	// ExitTimeNs 出场时间（纳秒时间戳）
 	ExitTimeNs int64
-	// ExitReason 退出原因: tp, sl, timeout
+	// ExitReason 退出原因: tp, sl, timeout, shutdown, trail, reversal, ev_flip, blackout, feed_degraded
 	ExitReason ExitReason
 	// GrossPnLBps 毛利（基点）
 	// 计算公式: (exit_px - entry_px) / entry_px × 10000 × direction
@@ -57,11 +87,27 @@ type Position struct {
 	// FeeBps 手续费（基点）
 	// 计算公式: 2 × effective_fee × 10000（入场 + 出场）
 	FeeBps float64
+	// BorrowCostBps 持仓资金/借币成本（基点，fees.borrow_rate_annual > 0 时按持仓时长折算）
+	BorrowCostBps float64
 	// NetPnLBps 净利（基点）
-	// 计算公式: gross_pnl_bps - fee_bps
+	// 计算公式: gross_pnl_bps - fee_bps - borrow_cost_bps
 	NetPnLBps float64
+	// MAEBps 最大不利偏移（基点，>=0）
+	// 持仓期间按当前可平仓价计算的最差浮动毛利的绝对值
+	MAEBps float64
+	// MFEBps 最大有利偏移（基点，>=0）
+	// 持仓期间按当前可平仓价计算的最佳浮动毛利
+	MFEBps float64
+	// VolScale 开仓时的波动率缩放系数（paper.vol_scale_ref > 0 时记录；0 表示未缩放）
+	// 持仓期间的 tp_ratio、sl_ratio 乘以该系数，max_hold_ms 除以该系数
+	VolScale float64
+	// BestSpreadBps 持仓期间观测到的最小 |当前价差|（基点，跟踪止盈用）
+	// 开仓时为 |入场价差|，随价差收敛单向减小
+	BestSpreadBps float64
 	// Closed 是否已平仓
 	Closed bool
+	// Variant 策略变体名称（A/B 实验；基础策略为空）
+	Variant string
 }
 
 // IsLong 判断是否为多头仓位
@@ -96,6 +142,11 @@ func (p *Position) HoldDurationMs() int64 {
 	return p.HoldDuration().Milliseconds()
 }
 
+// CostBps 获取持仓总成本（基点）= 手续费 + 资金/借币成本
+func (p *Position) CostBps() float64 {
+	return p.FeeBps + p.BorrowCostBps
+}
+
 // IsWin 判断是否盈利
 func (p *Position) IsWin() bool {
 	return p.NetPnLBps > 0
@@ -106,9 +157,16 @@ func (p *Position) IsLoss() bool {
 	return p.NetPnLBps < 0
 }
 
+// PaperTradeSchemaVersion paper_trades.jsonl 记录格式版本（字段含义变更或删除字段时递增）
+const PaperTradeSchemaVersion = 1
+
 // PaperTrade 影子成交输出结构
 // 用于 JSONL 文件输出，包含所有必需字段
 type PaperTrade struct {
+	// SchemaVersion 输出格式版本（PaperTradeSchemaVersion）
+	SchemaVersion int `json:"schema_version"`
+	// SignalID 触发开仓的信号 ID，可与 signals.jsonl 的 ID 关联到触发时的订单簿快照
+	SignalID string `json:"signal_id"`
 	// Leader 领先交易所
 	Leader string `json:"leader"`
 	// SymbolCanon 统一交易对
@@ -127,12 +185,63 @@ type PaperTrade struct {
 	GrossPnLBps float64 `json:"gross_pnl_bps"`
 	// FeeBps 手续费（基点）
 	FeeBps float64 `json:"fee_bps"`
+	// BorrowCostBps 资金/借币成本（基点，仅配置 fees.borrow_rate_annual 时输出）
+	BorrowCostBps float64 `json:"borrow_cost_bps,omitempty"`
 	// NetPnLBps 净利（基点）
 	NetPnLBps float64 `json:"net_pnl_bps"`
 	// ExitReason 退出原因
 	ExitReason string `json:"exit_reason"`
+	// MAEBps 最大不利偏移（基点）
+	MAEBps float64 `json:"mae_bps"`
+	// MFEBps 最大有利偏移（基点）
+	MFEBps float64 `json:"mfe_bps"`
+	// FillDelayMs 信号到成交的模拟延迟（毫秒，仅 latency_fill 模式输出）
+	FillDelayMs float64 `json:"fill_delay_ms,omitempty"`
+	// VolScale 开仓时的波动率缩放系数（仅启用 paper.vol_scale_ref 时输出）
+	VolScale float64 `json:"vol_scale,omitempty"`
 	// EVSnapshot EV 快照（可选）
 	EVSnapshot *EVSnapshot `json:"ev_snapshot,omitempty"`
+	// Variant 策略变体名称（基础策略不输出）
+	Variant string `json:"variant,omitempty"`
+}
+
+// PositionHeartbeat 未平仓仓位心跳（positions.jsonl 与指标快照输出）
+// 使开仓到平仓之间的持仓状态可观测。
+type PositionHeartbeat struct {
+	// TsUnixNs 心跳时间（纳秒）
+	TsUnixNs int64 `json:"ts_unix_ns"`
+	// ID 仓位唯一标识
+	ID string `json:"id"`
+	// SignalID 触发开仓的信号 ID
+	SignalID string `json:"signal_id"`
+	// Variant 策略变体名称（基础策略不输出）
+	Variant string `json:"variant,omitempty"`
+	// Leader 领先交易所
+	Leader string `json:"leader"`
+	// SymbolCanon 统一交易对
+	SymbolCanon string `json:"symbol_canon"`
+	// Side 交易方向
+	Side string `json:"side"`
+	// TEntryNs 入场时间（纳秒）
+	TEntryNs int64 `json:"t_entry_ns"`
+	// AgeMs 持仓时长（毫秒）
+	AgeMs float64 `json:"age_ms"`
+	// EntryPx 入场价格（待成交时为 0）
+	EntryPx float64 `json:"entry_px"`
+	// EntrySpreadBps 入场价差（基点）
+	EntrySpreadBps float64 `json:"entry_spread_bps"`
+	// CurrentSpreadBps 当前价差（基点，与入场价差同口径）
+	CurrentSpreadBps float64 `json:"current_spread_bps"`
+	// UnrealizedPnLBps 按当前可平仓价（含滑点）扣除往返手续费后的浮动净利（基点）
+	UnrealizedPnLBps float64 `json:"unrealized_pnl_bps"`
+	// MAEBps 最大不利偏移（基点）
+	MAEBps float64 `json:"mae_bps"`
+	// MFEBps 最大有利偏移（基点）
+	MFEBps float64 `json:"mfe_bps"`
+	// Pending 尚未成交（latency_fill）或报价尚未确认（quote_persist_ms），不参与退出判断
+	Pending bool `json:"pending,omitempty"`
+	// NoQuote 缺少有效报价（当前价差或浮动净利未计算）
+	NoQuote bool `json:"no_quote,omitempty"`
 }
 
 // EVSnapshot EV 统计快照
@@ -152,17 +261,25 @@ type EVSnapshot struct {
 // ToPaperTrade 将 Position 转换为 PaperTrade 输出格式
 func (p *Position) ToPaperTrade(evSnapshot *EVSnapshot) *PaperTrade {
 	return &PaperTrade{
-		Leader:      p.Leader,
-		SymbolCanon: p.SymbolCanon,
-		Side:        string(p.Side),
-		TEntryNs:    p.EntryTimeNs,
-		TExitNs:     p.ExitTimeNs,
-		EntryPx:     p.EntryPx,
-		ExitPx:      p.ExitPx,
-		GrossPnLBps: p.GrossPnLBps,
-		FeeBps:      p.FeeBps,
-		NetPnLBps:   p.NetPnLBps,
-		ExitReason:  string(p.ExitReason),
-		EVSnapshot:  evSnapshot,
+		SchemaVersion: PaperTradeSchemaVersion,
+		SignalID:      p.SignalID,
+		Leader:        p.Leader,
+		SymbolCanon:   p.SymbolCanon,
+		Side:          string(p.Side),
+		TEntryNs:      p.EntryTimeNs,
+		TExitNs:       p.ExitTimeNs,
+		EntryPx:       p.EntryPx,
+		ExitPx:        p.ExitPx,
+		GrossPnLBps:   p.GrossPnLBps,
+		FeeBps:        p.FeeBps,
+		BorrowCostBps: p.BorrowCostBps,
+		NetPnLBps:     p.NetPnLBps,
+		ExitReason:    string(p.ExitReason),
+		MAEBps:        p.MAEBps,
+		MFEBps:        p.MFEBps,
+		FillDelayMs:   p.FillDelayMs,
+		VolScale:      p.VolScale,
+		EVSnapshot:    evSnapshot,
+		Variant:       p.Variant,
 	}
 }
@@ -5,9 +5,20 @@ import (
 	"time"
 )
 
+// SignalSchemaVersion signals.jsonl 记录格式版本（字段含义变更或删除字段时递增）
+// 2: LeaderBook/FollowerBook 的 Levels 拆分为 Bids/Asks
+// 3: 波动率过滤的信号不再丢弃，改为输出并在 FilterReason 记录触发窗口（vol_<窗口>）
+// 4: FeeBps/NetEdgeBps 计入 Leader 侧对冲腿的往返手续费
+const SignalSchemaVersion = 4
+
+// FilterReasonBlackout 信号产生于禁止开仓时段（blackout 配置）
+const FilterReasonBlackout = "blackout"
+
 // Signal 套利信号
 // 当检测到 Leader 和 Follower 之间存在价差机会时生成
 type Signal struct {
+	// SchemaVersion 输出格式版本（SignalSchemaVersion）
+	SchemaVersion int `json:"schema_version"`
 	// ID 信号唯一标识
 	ID string
 	// Leader 领先交易所标识: okx 或 binance
@@ -33,8 +44,50 @@ type Signal struct {
 	DetectedAtNs int64
 	// RejectedByEV 是否因 EV 为负被拒绝
 	RejectedByEV bool
-	// FilterReason 过滤原因（若被过滤）
+	// FilterReason 过滤原因（若被过滤，过滤的信号不开仓）
+	// ev_negative: 滚动 EV 为负；vol_<窗口>: 该窗口 realized vol 超阈值（如 vol_10s）；blackout: 禁止开仓时段
 	FilterReason string
+	// Variant 策略变体名称（A/B 实验；基础策略为空）
+	Variant string `json:",omitempty"`
+
+	// LeaderBookAgeMs 检测时 Leader 快照的年龄（检测时间 - 到达时间，毫秒）
+	LeaderBookAgeMs float64 `json:"leader_book_age_ms"`
+	// FollowerBookAgeMs 检测时 Follower 快照的年龄（检测时间 - 到达时间，毫秒）
+	FollowerBookAgeMs float64 `json:"follower_book_age_ms"`
+	// LagP50Ms 该 Leader 链路最近一次刷新（按业务时间每秒）的到达时延 P50（毫秒，尚无统计时为 0）
+	LagP50Ms float64 `json:"lag_p50_ms"`
+
+	// ThetaEntryBps 触发时使用的入场阈值（基点）
+	ThetaEntryBps float64 `json:"theta_entry_bps"`
+	// DepthUSD 触发时 Leader 前 5 档名义价值（USD，统计方向见 strategy.min_depth_side）
+	DepthUSD float64 `json:"depth_usd"`
+	// FollowerImbalance 触发时 Follower 最优档挂单量失衡度（[-1, 1]，正值买盘更厚）
+	FollowerImbalance float64 `json:"follower_imbalance"`
+	// RealizedVol 触发时 1 分钟 realized vol 估计（1s 对数收益标准差）
+	RealizedVol float64 `json:"realized_vol"`
+	// PersistElapsedMs 价差持续满足阈值的时长（毫秒）
+	PersistElapsedMs float64 `json:"persist_elapsed_ms"`
+	// FeeBps 有效往返手续费（基点，含返佣）= Bittap 往返 + Leader 对冲腿往返
+	FeeBps float64 `json:"fee_bps"`
+	// NetEdgeBps 扣除手续费后的理论边际 = SpreadBps - FeeBps（基点）
+	NetEdgeBps float64 `json:"net_edge_bps"`
+}
+
+// WithoutDepth 返回去掉两侧深度档位的浅拷贝（仅保留最优价、时间戳与序列号），用于精简输出
+// 原信号不变，执行器仍可按完整深度成交。
+func (s *Signal) WithoutDepth() *Signal {
+	out := *s
+	if s.LeaderBook != nil {
+		lb := *s.LeaderBook
+		lb.Bids, lb.Asks = nil, nil
+		out.LeaderBook = &lb
+	}
+	if s.FollowerBook != nil {
+		fb := *s.FollowerBook
+		fb.Bids, fb.Asks = nil, nil
+		out.FollowerBook = &fb
+	}
+	return &out
 }
 
 // IsLong 判断是否为多头信号
@@ -0,0 +1,30 @@
+// Package model 信号输出测试
+package model
+
+import (
+	"testing"
+)
+
+func TestSignal_WithoutDepth(t *testing.T) {
+	sig := &Signal{
+		ID: "s1",
+		LeaderBook: &BookEvent{
+			Exchange: ExchangeOKX, BestBidPx: 100, BestAskPx: 100.1, Seq: 7, ArrivedAtUnixNs: 1,
+			Bids: []Level{{Price: 100, Qty: 1}}, Asks: []Level{{Price: 100.1, Qty: 1}},
+		},
+		FollowerBook: &BookEvent{
+			Exchange: ExchangeBittap, BestBidPx: 99, BestAskPx: 99.1, Seq: 9,
+			Bids: []Level{{Price: 99, Qty: 2}}, Asks: []Level{{Price: 99.1, Qty: 2}},
+		},
+	}
+	out := sig.WithoutDepth()
+	if out.LeaderBook.Bids != nil || out.LeaderBook.Asks != nil || out.FollowerBook.Bids != nil || out.FollowerBook.Asks != nil {
+		t.Fatalf("精简输出不应包含深度档位")
+	}
+	if out.ID != "s1" || out.LeaderBook.Seq != 7 || out.LeaderBook.ArrivedAtUnixNs != 1 || out.FollowerBook.BestAskPx != 99.1 {
+		t.Errorf("最优价、时间戳与序列号应保留: %+v %+v", out.LeaderBook, out.FollowerBook)
+	}
+	if len(sig.LeaderBook.Bids) != 1 || len(sig.FollowerBook.Asks) != 1 {
+		t.Errorf("原信号的深度档位不应被修改")
+	}
+}
@@ -5,9 +5,11 @@ package paper
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/core/store"
 	"latency-arbitrage-validator/internal/util/timeutil"
 )
 
@@ -18,11 +20,26 @@ type Executor struct {
 	leader string
 	// cfg 影子成交配置
 	cfg config.PaperConfig
+	// symbolCfg 按交易对覆盖后的生效配置（未覆盖的交易对使用 cfg）
+	symbolCfg map[string]config.PaperConfig
 	// fee 手续费配置（用于计算有效 taker fee）
 	fee config.FeeDetail
 
-	// positions 当前持仓（按交易对）
-	positions map[string]*model.Position
+	// slippage 按交易对的滑点来源（slippage_mode=spread 时使用；nil 或无样本时回退 slippage_bps）
+	slippage func(symbolCanon string) (float64, bool)
+	// vol 按交易对的 Leader realized vol 来源（paper.vol_scale_ref > 0 时使用；nil 或无样本时不缩放）
+	vol func(symbolCanon string) (float64, bool)
+	// borrowCost 按持仓时长（毫秒）计算资金/借币成本（基点，nil 表示不计）
+	borrowCost func(holdMs int64) float64
+	// fillDelayNs latency_fill 模式下信号到成交的模拟延迟（纳秒）
+	fillDelayNs int64
+	// history 按交易对的 Follower 盘口历史（latency_fill 模式）
+	history map[string]*store.History
+	// phantomFills 报价持续校验失败的成交笔数（累计）
+	phantomFills int64
+
+	// positions 当前未平仓仓位（按交易对，同一交易对按开仓顺序）
+	positions map[string][]*model.Position
 }
 
 // NewExecutor 创建影子成交执行器
@@ -30,16 +47,103 @@ type Executor struct {
 // 参数 cfg: 影子成交配置
 // 参数 fee: Bittap 手续费配置
 func NewExecutor(leader string, cfg config.PaperConfig, fee config.FeeDetail) *Executor {
-	return &Executor{
+	e := &Executor{
 		leader:    leader,
 		cfg:       cfg,
 		fee:       fee,
-		positions: make(map[string]*model.Position),
+		positions: make(map[string][]*model.Position),
+		history:   make(map[string]*store.History),
+	}
+	if len(cfg.Symbols) > 0 {
+		e.symbolCfg = make(map[string]config.PaperConfig, len(cfg.Symbols))
+		for sym := range cfg.Symbols {
+			e.symbolCfg[sym] = cfg.ForSymbol(sym)
+		}
+	}
+	return e
+}
+
+// SetSlippageSource 设置按交易对的滑点来源（基点），仅 slippage_mode=spread 时生效
+func (e *Executor) SetSlippageSource(fn func(symbolCanon string) (float64, bool)) {
+	e.slippage = fn
+}
+
+// slippageBps 返回交易对生效的单边滑点（基点）
+func (e *Executor) slippageBps(symbolCanon string) float64 {
+	if e.cfg.SlippageMode == config.SlippageModeSpread && e.slippage != nil {
+		if bps, ok := e.slippage(symbolCanon); ok {
+			return bps
+		}
 	}
+	return e.cfg.SlippageBps
+}
+
+// SetBorrowCost 设置按持仓时长计算资金/借币成本的函数（基点），平仓时从净利中扣除
+func (e *Executor) SetBorrowCost(fn func(holdMs int64) float64) {
+	e.borrowCost = fn
+}
+
+// borrowCostBps 返回仓位持有到 nowNs 的资金/借币成本（基点，未设置时为 0）
+func (e *Executor) borrowCostBps(pos *model.Position, nowNs int64) float64 {
+	if e.borrowCost == nil {
+		return 0
+	}
+	return e.borrowCost((nowNs - pos.EntryTimeNs) / 1e6)
+}
+
+// SetVolSource 设置按交易对的 realized vol 来源（1s 对数收益标准差），仅 vol_scale_ref > 0 时生效
+func (e *Executor) SetVolSource(fn func(symbolCanon string) (float64, bool)) {
+	e.vol = fn
+}
+
+// volScale 返回交易对开仓时的波动率缩放系数（未启用或无样本时为 0）
+func (e *Executor) volScale(symbolCanon string) float64 {
+	if e.cfg.VolScaleRef <= 0 || e.vol == nil {
+		return 0
+	}
+	vol, ok := e.vol(symbolCanon)
+	if !ok || vol <= 0 {
+		return 0
+	}
+	return math.Min(math.Max(vol/e.cfg.VolScaleRef, e.cfg.VolScaleMin), e.cfg.VolScaleMax)
+}
+
+// exitCfgFor 返回仓位生效的退出参数（按开仓时的波动率缩放系数调整）
+func (e *Executor) exitCfgFor(pos *model.Position) config.PaperConfig {
+	cfg := e.cfgFor(pos.SymbolCanon)
+	if k := pos.VolScale; k > 0 {
+		cfg.TPRatio = math.Min(cfg.TPRatio*k, 1)
+		cfg.SLRatio *= k
+		cfg.MaxHoldMs = int(float64(cfg.MaxHoldMs) / k)
+	}
+	return cfg
+}
+
+// cfgFor 返回交易对生效的退出参数
+func (e *Executor) cfgFor(symbolCanon string) config.PaperConfig {
+	if cfg, ok := e.symbolCfg[symbolCanon]; ok {
+		return cfg
+	}
+	return e.cfg
+}
+
+// RoundTripFeeBps 获取往返手续费（基点）
+// 手续费采用 taker，有效费率 = raw_fee × (1 - rebate_rate)
+// round-trip fee_bps = 2 × effective_fee × 10000
+func (e *Executor) RoundTripFeeBps() float64 {
+	return 2 * e.fee.EffectiveTakerFee() * 10000
+}
+
+// maxPerSymbol 单个交易对同时持有的最大仓位数
+func (e *Executor) maxPerSymbol() int {
+	if e.cfg.MaxPositionsPerSymbol > 1 {
+		return e.cfg.MaxPositionsPerSymbol
+	}
+	return 1
 }
 
 // TryOpen 尝试根据信号开仓
-// 若该交易对已有未平仓仓位，则返回 (nil, false, nil)。
+// 若该交易对未平仓仓位已达 max_positions_per_symbol，则返回 (nil, false, nil)。
 func (e *Executor) TryOpen(sig *model.Signal) (*model.Position, bool, error) {
 	if sig == nil || sig.Leader != e.leader || sig.SymbolCanon == "" {
 		return nil, false, nil
@@ -51,7 +155,7 @@ func (e *Executor) TryOpen(sig *model.Signal) (*model.Position, bool, error) {
 		return nil, false, fmt.Errorf("Follower 必须为 bittap")
 	}
 
-	if pos := e.positions[sig.SymbolCanon]; pos != nil && !pos.Closed {
+	if len(e.positions[sig.SymbolCanon]) >= e.maxPerSymbol() {
 		return nil, false, nil
 	}
 
@@ -62,6 +166,7 @@ func (e *Executor) TryOpen(sig *model.Signal) (*model.Position, bool, error) {
 
 	pos := &model.Position{
 		ID:          fmt.Sprintf("paper-%s-%s-%d", e.leader, sig.SymbolCanon, sig.DetectedAtNs),
+		SignalID:    sig.ID,
 		Leader:      e.leader,
 		SymbolCanon: sig.SymbolCanon,
 		Side:        sig.Side,
@@ -71,19 +176,27 @@ func (e *Executor) TryOpen(sig *model.Signal) (*model.Position, bool, error) {
 		EntryTimeNs: sig.DetectedAtNs,
 		Closed:      false,
 	}
+	pos.BestSpreadBps = math.Abs(pos.EntrySpread)
+	pos.VolScale = e.volScale(sig.SymbolCanon)
+	// 时延惩罚成交：待 信号时刻 + 中位时延 到达后按当时的 Follower 盘口成交
+	e.recordFollower(sig.FollowerBook)
+	if e.cfg.LatencyFill && e.fillDelayNs > 0 {
+		pos.EntryPx = 0
+		pos.PendingFillNs = sig.DetectedAtNs + e.fillDelayNs
+		pos.FillDelayMs = float64(e.fillDelayNs) / 1e6
+	} else {
+		e.startQuoteCheck(pos, sig.FollowerBook)
+	}
 
-	// 手续费采用 taker，有效费率 = raw_fee × (1 - rebate_rate)
-	// round-trip fee_bps = 2 × effective_fee × 10000
-	effectiveFee := e.fee.EffectiveTakerFee()
-	pos.FeeBps = 2 * effectiveFee * 10000
+	pos.FeeBps = e.RoundTripFeeBps()
 
-	e.positions[sig.SymbolCanon] = pos
+	e.positions[sig.SymbolCanon] = append(e.positions[sig.SymbolCanon], pos)
 	return pos, true, nil
 }
 
-// Evaluate 评估持仓是否触发退出条件
-// 返回：若平仓则返回已平仓的 Position；否则返回 nil。
-func (e *Executor) Evaluate(nowNs int64, leaderBook, followerBook *model.BookEvent) *model.Position {
+// Evaluate 评估该交易对各持仓是否触发退出条件
+// 返回：本次平仓的 Position（按开仓顺序）；无平仓时返回 nil。
+func (e *Executor) Evaluate(nowNs int64, leaderBook, followerBook *model.BookEvent) []*model.Position {
 	if leaderBook == nil || followerBook == nil {
 		return nil
 	}
@@ -94,35 +207,129 @@ func (e *Executor) Evaluate(nowNs int64, leaderBook, followerBook *model.BookEve
 		return nil
 	}
 
-	pos := e.positions[leaderBook.SymbolCanon]
-	if pos == nil || pos.Closed {
+	e.recordFollower(followerBook)
+	open := e.positions[leaderBook.SymbolCanon]
+	if len(open) == 0 {
 		return nil
 	}
 
+	var closed []*model.Position
+	remaining := open[:0]
+	for _, pos := range open {
+		if !e.fillPending(nowNs, pos, followerBook) {
+			remaining = append(remaining, pos)
+			continue
+		}
+		if confirmed, phantom := e.confirmQuote(nowNs, pos, followerBook); phantom {
+			e.phantomFills++
+			continue
+		} else if !confirmed {
+			remaining = append(remaining, pos)
+			continue
+		}
+		if c := e.evaluatePosition(nowNs, pos, leaderBook, followerBook); c != nil {
+			closed = append(closed, c)
+			continue
+		}
+		remaining = append(remaining, pos)
+	}
+	e.setOpen(leaderBook.SymbolCanon, remaining)
+	return closed
+}
+
+// evaluatePosition 评估单个持仓的退出条件
+func (e *Executor) evaluatePosition(nowNs int64, pos *model.Position, leaderBook, followerBook *model.BookEvent) *model.Position {
+	e.trackExcursion(pos, followerBook)
+
 	curSpread, ok := currentSpreadBps(pos.Side, leaderBook, followerBook)
 	if !ok {
 		return nil
 	}
 
+	cfg := e.exitCfgFor(pos)
 	entryAbs := math.Abs(pos.EntrySpread)
 	curAbs := math.Abs(curSpread)
 
-	// TP：|current_spread| ≤ (1 - r_tp) × |entry_spread|
-	if e.cfg.TPRatio > 0 && entryAbs > 0 && curAbs <= (1.0-e.cfg.TPRatio)*entryAbs {
+	// Reversal：带符号价差与入场价差异号或归零（Leader 不再领先）
+	if cfg.ExitOnReversal && pos.EntrySpread != 0 && curSpread*math.Copysign(1, pos.EntrySpread) <= 0 {
+		return e.close(nowNs, pos, followerBook, model.ExitReversal)
+	}
+	if cfg.ExitMode == config.ExitModeTrailing {
+		if curAbs < pos.BestSpreadBps {
+			pos.BestSpreadBps = curAbs
+		}
+		// Trail：|current_spread| ≥ |entry_spread| - r_lock × best_convergence
+		if best := entryAbs - pos.BestSpreadBps; best > 0 && best >= cfg.TrailActivationRatio*entryAbs &&
+			curAbs >= entryAbs-cfg.TrailLockRatio*best {
+			return e.close(nowNs, pos, followerBook, model.ExitTrail)
+		}
+	} else if cfg.TPRatio > 0 && entryAbs > 0 && curAbs <= (1.0-cfg.TPRatio)*entryAbs {
+		// TP：|current_spread| ≤ (1 - r_tp) × |entry_spread|
 		return e.close(nowNs, pos, followerBook, model.ExitTP)
 	}
 	// SL：|current_spread| ≥ (1 + r_sl) × |entry_spread|
-	if e.cfg.SLRatio > 0 && entryAbs > 0 && curAbs >= (1.0+e.cfg.SLRatio)*entryAbs {
+	if cfg.SLRatio > 0 && entryAbs > 0 && curAbs >= (1.0+cfg.SLRatio)*entryAbs {
 		return e.close(nowNs, pos, followerBook, model.ExitSL)
 	}
 	// Timeout：持仓超过 max_hold_ms
-	if e.cfg.MaxHoldMs > 0 && (nowNs-pos.EntryTimeNs) > int64(e.cfg.MaxHoldMs)*1_000_000 {
+	if cfg.MaxHoldMs > 0 && (nowNs-pos.EntryTimeNs) > int64(cfg.MaxHoldMs)*1_000_000 {
 		return e.close(nowNs, pos, followerBook, model.ExitTimeout)
 	}
 
 	return nil
 }
 
+// CloseAll 以最后已知的 Follower 报价强制平掉全部未平仓仓位（优雅关闭时调用）
+// 参数 followerBook: 按交易对获取最新 Follower 订单簿
+// 返回: 已平仓的仓位（按交易对排序，同一交易对按开仓顺序）；缺少有效报价的仓位保持未平仓
+func (e *Executor) CloseAll(nowNs int64, followerBook func(symbolCanon string) *model.BookEvent, reason model.ExitReason) []*model.Position {
+	symbols := make([]string, 0, len(e.positions))
+	for sym := range e.positions {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	var closed []*model.Position
+	for _, sym := range symbols {
+		closed = append(closed, e.CloseSymbol(nowNs, sym, followerBook(sym), reason)...)
+	}
+	return closed
+}
+
+// HasOpen 判断交易对是否有未平仓仓位
+func (e *Executor) HasOpen(symbolCanon string) bool {
+	return len(e.positions[symbolCanon]) > 0
+}
+
+// CloseSymbol 以给定 Follower 报价平掉该交易对的全部未平仓仓位
+// 尚未成交（latency_fill）或报价尚未确认（quote_persist_ms）的仓位直接撤销，不产生成交记录。
+// 返回: 已平仓的仓位（按开仓顺序）；缺少有效报价的仓位保持未平仓
+func (e *Executor) CloseSymbol(nowNs int64, symbolCanon string, followerBook *model.BookEvent, reason model.ExitReason) []*model.Position {
+	var closed []*model.Position
+	remaining := e.positions[symbolCanon][:0]
+	for _, pos := range e.positions[symbolCanon] {
+		if pos.PendingFillNs != 0 || pos.QuoteConfirmNs != 0 {
+			continue
+		}
+		if c := e.close(nowNs, pos, followerBook, reason); c != nil {
+			closed = append(closed, c)
+			continue
+		}
+		remaining = append(remaining, pos)
+	}
+	e.setOpen(symbolCanon, remaining)
+	return closed
+}
+
+// setOpen 更新交易对的未平仓仓位（为空时删除，避免 map 随交易对累积）
+func (e *Executor) setOpen(symbolCanon string, open []*model.Position) {
+	if len(open) == 0 {
+		delete(e.positions, symbolCanon)
+		return
+	}
+	e.positions[symbolCanon] = open
+}
+
 func (e *Executor) close(nowNs int64, pos *model.Position, followerBook *model.BookEvent, reason model.ExitReason) *model.Position {
 	exitPx, err := e.exitPx(pos.Side, followerBook)
 	if err != nil {
@@ -137,28 +344,44 @@ func (e *Executor) close(nowNs int64, pos *model.Position, followerBook *model.B
 
 	// gross_pnl_bps = (exit_px - entry_px) / entry_px × 10000 × direction
 	pos.GrossPnLBps = (pos.ExitPx - pos.EntryPx) / pos.EntryPx * 10000 * pos.Direction()
-	// net_pnl_bps = gross_pnl_bps - fee_bps
-	pos.NetPnLBps = pos.GrossPnLBps - pos.FeeBps
+	// net_pnl_bps = gross_pnl_bps - fee_bps - borrow_cost_bps
+	pos.BorrowCostBps = e.borrowCostBps(pos, nowNs)
+	pos.NetPnLBps = pos.GrossPnLBps - pos.FeeBps - pos.BorrowCostBps
 
 	return pos
 }
 
+// trackExcursion 以当前可平仓价（含滑点）更新持仓的 MAE/MFE
+func (e *Executor) trackExcursion(pos *model.Position, followerBook *model.BookEvent) {
+	px, err := e.exitPx(pos.Side, followerBook)
+	if err != nil || pos.EntryPx <= 0 {
+		return
+	}
+	unrealized := (px - pos.EntryPx) / pos.EntryPx * 10000 * pos.Direction()
+	if unrealized > pos.MFEBps {
+		pos.MFEBps = unrealized
+	}
+	if -unrealized > pos.MAEBps {
+		pos.MAEBps = -unrealized
+	}
+}
+
 func (e *Executor) entryPx(side model.Side, followerBook *model.BookEvent) (float64, error) {
 	if followerBook == nil {
 		return 0, fmt.Errorf("follower book 为空")
 	}
-	slip := e.cfg.SlippageBps / 10000
+	slip := e.slippageBps(followerBook.SymbolCanon) / 10000
 	switch side {
 	case model.SideLong:
 		if followerBook.BestAskPx <= 0 {
 			return 0, fmt.Errorf("BestAskPx 无效")
 		}
-		return followerBook.BestAskPx * (1 + slip), nil
+		return e.fillPx(model.SideLong, followerBook, followerBook.BestAskPx) * (1 + slip), nil
 	case model.SideShort:
 		if followerBook.BestBidPx <= 0 {
 			return 0, fmt.Errorf("BestBidPx 无效")
 		}
-		return followerBook.BestBidPx * (1 - slip), nil
+		return e.fillPx(model.SideShort, followerBook, followerBook.BestBidPx) * (1 - slip), nil
 	default:
 		return 0, fmt.Errorf("未知 side: %s", side)
 	}
@@ -168,23 +391,35 @@ func (e *Executor) exitPx(side model.Side, followerBook *model.BookEvent) (float
 	if followerBook == nil {
 		return 0, fmt.Errorf("follower book 为空")
 	}
-	slip := e.cfg.SlippageBps / 10000
+	slip := e.slippageBps(followerBook.SymbolCanon) / 10000
 	switch side {
 	case model.SideLong:
 		if followerBook.BestBidPx <= 0 {
 			return 0, fmt.Errorf("BestBidPx 无效")
 		}
-		return followerBook.BestBidPx * (1 - slip), nil
+		return e.fillPx(model.SideShort, followerBook, followerBook.BestBidPx) * (1 - slip), nil
 	case model.SideShort:
 		if followerBook.BestAskPx <= 0 {
 			return 0, fmt.Errorf("BestAskPx 无效")
 		}
-		return followerBook.BestAskPx * (1 + slip), nil
+		return e.fillPx(model.SideLong, followerBook, followerBook.BestAskPx) * (1 + slip), nil
 	default:
 		return 0, fmt.Errorf("未知 side: %s", side)
 	}
 }
 
+// fillPx 返回吃单方向 taker 的成交价（未含滑点）
+// paper.notional_usd > 0 时按 Follower 盘口逐档吃单的成交均价，否则为最优价 best。
+func (e *Executor) fillPx(taker model.Side, followerBook *model.BookEvent, best float64) float64 {
+	if e.cfg.NotionalUSD <= 0 {
+		return best
+	}
+	if px, filled := followerBook.VWAPForNotional(taker, e.cfg.NotionalUSD); filled > 0 {
+		return px
+	}
+	return best
+}
+
 func currentSpreadBps(side model.Side, leaderBook, followerBook *model.BookEvent) (float64, bool) {
 	switch side {
 	case model.SideLong:
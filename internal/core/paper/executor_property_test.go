@@ -179,7 +179,7 @@ func TestExecutor_ExitConditions_Property(t *testing.T) {
 			leaderTPBid := ask * (1 + curTP/10000)
 			leaderNow := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: leaderTPBid, BestAskPx: leaderTPBid + 0.01}
 			followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: ask - 0.01, BestAskPx: ask}
-			closed := exec.Evaluate(2_000_000_000, leaderNow, followerNow)
+			closed := first(exec.Evaluate(2_000_000_000, leaderNow, followerNow))
 			if closed == nil || closed.ExitReason != model.ExitTP {
 				return false
 			}
@@ -198,7 +198,7 @@ func TestExecutor_ExitConditions_Property(t *testing.T) {
 			curSL := (1.0 + slRatio) * entrySpreadBps * 1.1
 			leaderSLBid := ask * (1 + curSL/10000)
 			leaderNow2 := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: leaderSLBid, BestAskPx: leaderSLBid + 0.01}
-			closed2 := exec2.Evaluate(2_000_000_000, leaderNow2, followerNow)
+			closed2 := first(exec2.Evaluate(2_000_000_000, leaderNow2, followerNow))
 			return closed2 != nil && closed2.ExitReason == model.ExitSL
 		},
 		gen.Float64Range(10, 2000),
@@ -251,7 +251,7 @@ func TestExecutor_PnL_Property(t *testing.T) {
 			exitPx := pos.EntryPx * (1 + moveBps/10000)
 			leaderNow := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: entryPx + 1, BestAskPx: entryPx + 1.01}
 			followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: exitPx, BestAskPx: exitPx + 0.01}
-			closed := exec.Evaluate(pos.EntryTimeNs+2_000_000, leaderNow, followerNow)
+			closed := first(exec.Evaluate(pos.EntryTimeNs+2_000_000, leaderNow, followerNow))
 			if closed == nil || !closed.Closed {
 				return false
 			}
@@ -2,6 +2,7 @@
 package paper
 
 import (
+	"math"
 	"testing"
 
 	"latency-arbitrage-validator/internal/config"
@@ -44,7 +45,7 @@ func TestExecutor_TakeProfit_Long(t *testing.T) {
 	// 价差收敛：long_spread = (100 - 99.99)/99.99*10000 ≈ 1 bps < 50 bps
 	leaderNow := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10}
 	followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 100.01, BestAskPx: 99.99}
-	closed := exec.Evaluate(1_200_000_000, leaderNow, followerNow)
+	closed := first(exec.Evaluate(1_200_000_000, leaderNow, followerNow))
 	if closed == nil {
 		t.Fatalf("应触发止盈平仓")
 	}
@@ -82,7 +83,7 @@ func TestExecutor_StopLoss_Long(t *testing.T) {
 	// 价差发散：FollowerAsk 大幅下降，long_spread 变大
 	leaderNow := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10}
 	followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 90.00, BestAskPx: 90.01}
-	closed := exec.Evaluate(1_200_000_000, leaderNow, followerNow)
+	closed := first(exec.Evaluate(1_200_000_000, leaderNow, followerNow))
 	if closed == nil {
 		t.Fatalf("应触发止损平仓")
 	}
@@ -116,8 +117,656 @@ func TestExecutor_Timeout(t *testing.T) {
 
 	leaderNow := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10}
 	followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90}
-	closed := exec.Evaluate(1_020_000_000, leaderNow, followerNow) // +20ms
+	closed := first(exec.Evaluate(1_020_000_000, leaderNow, followerNow)) // +20ms
 	if closed == nil || closed.ExitReason != model.ExitTimeout {
 		t.Fatalf("应触发超时平仓")
 	}
 }
+
+func TestExecutor_BorrowCost(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{MaxHoldMs: 10}, config.FeeDetail{TakerRate: 0.0005})
+	var gotHoldMs int64
+	exec.SetBorrowCost(func(holdMs int64) float64 {
+		gotHoldMs = holdMs
+		return 0.5 * float64(holdMs)
+	})
+
+	sig := &model.Signal{
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    100,
+		DetectedAtNs: 1_000_000_000,
+		LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10},
+		FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90},
+	}
+	if _, opened, err := exec.TryOpen(sig); err != nil || !opened {
+		t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+	}
+
+	leaderNow := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10}
+	followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90}
+	closed := first(exec.Evaluate(1_020_000_000, leaderNow, followerNow)) // +20ms 超时
+	if closed == nil {
+		t.Fatalf("应触发超时平仓")
+	}
+	// 持仓 20ms × 0.5 bps/ms = 10 bps，从净利中扣除
+	if gotHoldMs != 20 || closed.BorrowCostBps != 10 {
+		t.Fatalf("holdMs=%d BorrowCostBps=%f, want 20/10", gotHoldMs, closed.BorrowCostBps)
+	}
+	if want := closed.GrossPnLBps - closed.FeeBps - 10; closed.NetPnLBps != want {
+		t.Fatalf("NetPnLBps=%f, want %f", closed.NetPnLBps, want)
+	}
+	if closed.CostBps() != closed.FeeBps+10 {
+		t.Fatalf("CostBps=%f, want %f", closed.CostBps(), closed.FeeBps+10)
+	}
+}
+
+func TestExecutor_MAEMFE(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:   0.5,
+		SLRatio:   1.0,
+		MaxHoldMs: 60000,
+	}, config.FeeDetail{})
+
+	sig := &model.Signal{
+		ID:           "okx-BTCUSDT-long-1000000000",
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    20,
+		DetectedAtNs: 1_000_000_000,
+		LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.10, BestAskPx: 100.11},
+		FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90},
+	}
+	if _, opened, err := exec.TryOpen(sig); err != nil || !opened {
+		t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+	}
+
+	// 先逆向：可平仓价 99.70，浮亏约 20bps；价差约 20bps 不触发退出
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01}
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.70, BestAskPx: 99.80}
+	if closed := first(exec.Evaluate(1_100_000_000, leader, follower)); closed != nil {
+		t.Fatalf("不应平仓: %+v", closed)
+	}
+
+	// 再收敛止盈：可平仓价 100.00，浮盈约 10bps
+	leader = &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.10, BestAskPx: 100.11}
+	follower = &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.05}
+	closed := first(exec.Evaluate(1_200_000_000, leader, follower))
+	if closed == nil || closed.ExitReason != model.ExitTP {
+		t.Fatalf("应触发止盈平仓: %+v", closed)
+	}
+
+	wantMAE := (99.90 - 99.70) / 99.90 * 10000
+	wantMFE := (100.00 - 99.90) / 99.90 * 10000
+	if math.Abs(closed.MAEBps-wantMAE) > 1e-6 || math.Abs(closed.MFEBps-wantMFE) > 1e-6 {
+		t.Fatalf("MAE=%f MFE=%f, want %f/%f", closed.MAEBps, closed.MFEBps, wantMAE, wantMFE)
+	}
+	if pt := closed.ToPaperTrade(nil); pt.MAEBps != closed.MAEBps || pt.MFEBps != closed.MFEBps {
+		t.Fatalf("PaperTrade 未携带 MAE/MFE: %+v", pt)
+	}
+	if pt := closed.ToPaperTrade(nil); pt.SignalID != sig.ID {
+		t.Fatalf("PaperTrade.SignalID=%q, want %q", pt.SignalID, sig.ID)
+	}
+}
+
+func TestExecutor_CloseAll(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{MaxHoldMs: 60000}, config.FeeDetail{})
+
+	for _, sym := range []string{"ETHUSDT", "BTCUSDT"} {
+		sig := &model.Signal{
+			Leader:       model.ExchangeOKX,
+			SymbolCanon:  sym,
+			Side:         model.SideLong,
+			SpreadBps:    100,
+			DetectedAtNs: 1_000_000_000,
+			LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: sym, BestBidPx: 100.00, BestAskPx: 100.10},
+			FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: sym, BestBidPx: 99.80, BestAskPx: 99.90},
+		}
+		if _, opened, err := exec.TryOpen(sig); err != nil || !opened {
+			t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+		}
+	}
+
+	books := map[string]*model.BookEvent{
+		"BTCUSDT": {Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10},
+	}
+	closed := exec.CloseAll(2_000_000_000, func(sym string) *model.BookEvent { return books[sym] }, model.ExitShutdown)
+	// ETHUSDT 无最新报价，保持未平仓
+	if len(closed) != 1 || closed[0].SymbolCanon != "BTCUSDT" {
+		t.Fatalf("closed=%v, want 仅 BTCUSDT", closed)
+	}
+	pos := closed[0]
+	if pos.ExitReason != model.ExitShutdown || pos.ExitPx != 100.00 || pos.ExitTimeNs != 2_000_000_000 {
+		t.Fatalf("ExitReason=%s ExitPx=%v ExitTimeNs=%d", pos.ExitReason, pos.ExitPx, pos.ExitTimeNs)
+	}
+	if want := (100.00 - 99.90) / 99.90 * 10000; math.Abs(pos.NetPnLBps-want) > 1e-9 {
+		t.Fatalf("NetPnLBps=%v, want %v", pos.NetPnLBps, want)
+	}
+
+	books["ETHUSDT"] = &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "ETHUSDT", BestBidPx: 99.00, BestAskPx: 99.10}
+	if closed := exec.CloseAll(3_000_000_000, func(sym string) *model.BookEvent { return books[sym] }, model.ExitShutdown); len(closed) != 1 || closed[0].SymbolCanon != "ETHUSDT" {
+		t.Fatalf("已平仓仓位不应重复平仓: %v", closed)
+	}
+}
+
+func TestExecutor_MaxPositionsPerSymbol(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:               0.5,
+		SLRatio:               1.0,
+		MaxHoldMs:             60000,
+		MaxPositionsPerSymbol: 2,
+	}, config.FeeDetail{})
+
+	open := func(detectedAtNs int64, spreadBps float64) bool {
+		sig := &model.Signal{
+			Leader:       model.ExchangeOKX,
+			SymbolCanon:  "BTCUSDT",
+			Side:         model.SideLong,
+			SpreadBps:    spreadBps,
+			DetectedAtNs: detectedAtNs,
+			LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10},
+			FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90},
+		}
+		_, opened, err := exec.TryOpen(sig)
+		if err != nil {
+			t.Fatalf("TryOpen err: %v", err)
+		}
+		return opened
+	}
+	if !open(1_000_000_000, 100) || !open(1_010_000_000, 40) {
+		t.Fatalf("信号簇前两个信号应各自开仓")
+	}
+	if open(1_020_000_000, 100) {
+		t.Fatalf("已达单交易对上限，不应再开仓")
+	}
+	if got := exec.OpenPositions(); len(got) != 2 || got[0].EntryTimeNs > got[1].EntryTimeNs {
+		t.Fatalf("OpenPositions=%+v, want 2 笔按开仓时间排序", got)
+	}
+
+	// 当前价差 ≈ 30bps：仅入场价差 100bps 的仓位触发止盈（阈值 50bps），40bps 的仓位继续持有（阈值 20bps）
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.20, BestAskPx: 100.21}
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90}
+	closed := exec.Evaluate(1_100_000_000, leader, follower)
+	if len(closed) != 1 || closed[0].EntrySpread != 100 || closed[0].ExitReason != model.ExitTP {
+		t.Fatalf("closed=%+v, want 仅入场价差 100bps 的仓位止盈", closed)
+	}
+	if !open(1_200_000_000, 100) {
+		t.Fatalf("平仓释放名额后应可再开仓")
+	}
+
+	// 价差收敛：剩余两笔同时止盈，按开仓顺序返回
+	leader = &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 99.90, BestAskPx: 99.91}
+	closed = exec.Evaluate(1_300_000_000, leader, follower)
+	if len(closed) != 2 || closed[0].EntryTimeNs >= closed[1].EntryTimeNs {
+		t.Fatalf("closed=%+v, want 2 笔按开仓顺序平仓", closed)
+	}
+	if got := exec.OpenPositions(); len(got) != 0 {
+		t.Fatalf("OpenPositions=%+v, want 空", got)
+	}
+}
+
+// first 返回首个平仓仓位（无平仓时为 nil）
+func first(closed []*model.Position) *model.Position {
+	if len(closed) == 0 {
+		return nil
+	}
+	return closed[0]
+}
+
+func TestExecutor_TrailingExit(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:              0.5,
+		SLRatio:              1.0,
+		MaxHoldMs:            60000,
+		ExitMode:             config.ExitModeTrailing,
+		TrailLockRatio:       0.5,
+		TrailActivationRatio: 0.2,
+	}, config.FeeDetail{})
+
+	sig := &model.Signal{
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    100,
+		DetectedAtNs: 1_000_000_000,
+		LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 101.00, BestAskPx: 101.01},
+		FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.99, BestAskPx: 100.00},
+	}
+	if _, opened, err := exec.TryOpen(sig); err != nil || !opened {
+		t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+	}
+
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.99, BestAskPx: 100.00}
+	leaderAt := func(bid float64) *model.BookEvent {
+		return &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: bid, BestAskPx: bid + 0.01}
+	}
+
+	// 价差 40bps：固定止盈会平仓，跟踪模式下止盈线上移至 100-0.5×60=70bps，继续持有
+	if closed := first(exec.Evaluate(1_100_000_000, leaderAt(100.40), follower)); closed != nil {
+		t.Fatalf("跟踪模式不应按 tp_ratio 平仓: %+v", closed)
+	}
+	// 价差 20bps：最佳收敛 80bps，止盈线上移至 60bps
+	if closed := first(exec.Evaluate(1_200_000_000, leaderAt(100.20), follower)); closed != nil {
+		t.Fatalf("价差继续收敛不应平仓: %+v", closed)
+	}
+	// 价差回撤至 65bps ≥ 60bps：锁定一半最佳收敛后平仓
+	closed := first(exec.Evaluate(1_300_000_000, leaderAt(100.65), follower))
+	if closed == nil || closed.ExitReason != model.ExitTrail {
+		t.Fatalf("应触发跟踪止盈平仓: %+v", closed)
+	}
+	if math.Abs(closed.BestSpreadBps-20) > 1e-6 {
+		t.Fatalf("BestSpreadBps=%v, want 20", closed.BestSpreadBps)
+	}
+}
+
+func TestExecutor_ReversalExit(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:        0,
+		SLRatio:        0.5,
+		MaxHoldMs:      60000,
+		ExitOnReversal: true,
+	}, config.FeeDetail{})
+
+	sig := &model.Signal{
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    100,
+		DetectedAtNs: 1_000_000_000,
+		LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 101.00, BestAskPx: 101.01},
+		FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.99, BestAskPx: 100.00},
+	}
+	if _, opened, err := exec.TryOpen(sig); err != nil || !opened {
+		t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+	}
+
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.99, BestAskPx: 100.00}
+	// 价差 5bps：仍为正，不平仓
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.05, BestAskPx: 100.06}
+	if closed := first(exec.Evaluate(1_100_000_000, leader, follower)); closed != nil {
+		t.Fatalf("价差未反转不应平仓: %+v", closed)
+	}
+	// 价差 -200bps：|价差| 超过止损线，但反转优先
+	leader = &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 98.00, BestAskPx: 98.01}
+	closed := first(exec.Evaluate(1_200_000_000, leader, follower))
+	if closed == nil || closed.ExitReason != model.ExitReversal {
+		t.Fatalf("应触发价差反转平仓: %+v", closed)
+	}
+}
+
+func TestExecutor_CloseSymbol(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:               0.5,
+		SLRatio:               1.0,
+		MaxHoldMs:             60000,
+		MaxPositionsPerSymbol: 2,
+	}, config.FeeDetail{})
+
+	for _, sym := range []string{"BTCUSDT", "BTCUSDT", "ETHUSDT"} {
+		sig := &model.Signal{
+			Leader:       model.ExchangeOKX,
+			SymbolCanon:  sym,
+			Side:         model.SideLong,
+			SpreadBps:    100,
+			DetectedAtNs: 1_000_000_000 + int64(len(exec.OpenPositions())),
+			LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: sym, BestBidPx: 100.00, BestAskPx: 100.10},
+			FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: sym, BestBidPx: 99.80, BestAskPx: 99.90},
+		}
+		if _, opened, err := exec.TryOpen(sig); err != nil || !opened {
+			t.Fatalf("TryOpen(%s) failed: opened=%v err=%v", sym, opened, err)
+		}
+	}
+
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.85, BestAskPx: 99.95}
+	closed := exec.CloseSymbol(1_500_000_000, "BTCUSDT", follower, model.ExitEVFlip)
+	if len(closed) != 2 || closed[0].ExitReason != model.ExitEVFlip || closed[1].ExitReason != model.ExitEVFlip {
+		t.Fatalf("closed=%+v, want BTC 两笔 ev_flip 平仓", closed)
+	}
+	if exec.HasOpen("BTCUSDT") || !exec.HasOpen("ETHUSDT") {
+		t.Fatalf("BTC 应无持仓、ETH 应保留持仓")
+	}
+}
+
+func TestExecutor_SymbolOverride(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name     string
+		cfg      config.PaperConfig
+		override config.PaperSymbolConfig
+		// leaderBids 依次评估的 Leader 买一价（Follower 卖一保持 100，入场价差 100bps）
+		leaderBids []float64
+		// want 各交易对的平仓原因（空表示仍持仓）
+		want map[string]model.ExitReason
+	}{
+		{
+			name:       "max_hold_ms",
+			cfg:        config.PaperConfig{MaxHoldMs: 60000},
+			override:   config.PaperSymbolConfig{MaxHoldMs: 10},
+			leaderBids: []float64{101.00},
+			want:       map[string]model.ExitReason{"SOLUSDT": model.ExitTimeout},
+		},
+		{
+			name:       "exit_on_reversal 开启",
+			cfg:        config.PaperConfig{MaxHoldMs: 60000},
+			override:   config.PaperSymbolConfig{ExitOnReversal: &on},
+			leaderBids: []float64{98.00},
+			want:       map[string]model.ExitReason{"SOLUSDT": model.ExitReversal},
+		},
+		{
+			name:       "exit_on_reversal 关闭",
+			cfg:        config.PaperConfig{MaxHoldMs: 60000, ExitOnReversal: true},
+			override:   config.PaperSymbolConfig{ExitOnReversal: &off},
+			leaderBids: []float64{98.00},
+			want:       map[string]model.ExitReason{"BTCUSDT": model.ExitReversal},
+		},
+		{
+			name:       "exit_mode trailing",
+			cfg:        config.PaperConfig{TPRatio: 0.5, SLRatio: 1.0, MaxHoldMs: 60000},
+			override:   config.PaperSymbolConfig{ExitMode: config.ExitModeTrailing, TrailLockRatio: 0.5, TrailActivationRatio: 0.2},
+			leaderBids: []float64{100.40, 100.20, 100.65},
+			want:       map[string]model.ExitReason{"BTCUSDT": model.ExitTP, "SOLUSDT": model.ExitTrail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Symbols = map[string]config.PaperSymbolConfig{"SOLUSDT": tt.override}
+			exec := NewExecutor(model.ExchangeOKX, cfg, config.FeeDetail{})
+
+			symbols := []string{"BTCUSDT", "SOLUSDT"}
+			for _, sym := range symbols {
+				sig := &model.Signal{
+					Leader:       model.ExchangeOKX,
+					SymbolCanon:  sym,
+					Side:         model.SideLong,
+					SpreadBps:    100,
+					DetectedAtNs: 1_000_000_000,
+					LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: sym, BestBidPx: 101.00, BestAskPx: 101.01},
+					FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: sym, BestBidPx: 99.99, BestAskPx: 100.00},
+				}
+				if _, opened, err := exec.TryOpen(sig); err != nil || !opened {
+					t.Fatalf("TryOpen(%s) failed: opened=%v err=%v", sym, opened, err)
+				}
+			}
+
+			got := make(map[string]model.ExitReason)
+			for i, bid := range tt.leaderBids {
+				nowNs := 1_000_000_000 + int64(i+1)*20_000_000
+				for _, sym := range symbols {
+					leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: sym, BestBidPx: bid, BestAskPx: bid + 0.01}
+					follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: sym, BestBidPx: 99.99, BestAskPx: 100.00}
+					if closed := first(exec.Evaluate(nowNs, leader, follower)); closed != nil {
+						got[sym] = closed.ExitReason
+					}
+				}
+			}
+			for _, sym := range symbols {
+				if got[sym] != tt.want[sym] {
+					t.Fatalf("%s ExitReason=%q, want %q", sym, got[sym], tt.want[sym])
+				}
+			}
+		})
+	}
+}
+
+func TestExecutor_SpreadSlippage(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:      0.5,
+		SLRatio:      1.0,
+		MaxHoldMs:    60000,
+		SlippageBps:  2,
+		SlippageMode: config.SlippageModeSpread,
+	}, config.FeeDetail{})
+	exec.SetSlippageSource(func(symbolCanon string) (float64, bool) {
+		if symbolCanon == "BTCUSDT" {
+			return 10, true
+		}
+		return 0, false
+	})
+
+	open := func(sym string) *model.Position {
+		pos, opened, err := exec.TryOpen(&model.Signal{
+			Leader:       model.ExchangeOKX,
+			SymbolCanon:  sym,
+			Side:         model.SideLong,
+			SpreadBps:    100,
+			DetectedAtNs: 1_000_000_000,
+			LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: sym, BestBidPx: 101.00, BestAskPx: 101.01},
+			FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: sym, BestBidPx: 99.99, BestAskPx: 100.00},
+		})
+		if err != nil || !opened {
+			t.Fatalf("TryOpen(%s) failed: opened=%v err=%v", sym, opened, err)
+		}
+		return pos
+	}
+
+	// BTCUSDT 使用半价差 10bps；ETHUSDT 无样本回退 slippage_bps=2
+	if pos := open("BTCUSDT"); math.Abs(pos.EntryPx-100.10) > 1e-9 {
+		t.Fatalf("BTCUSDT EntryPx=%v, want 100.10", pos.EntryPx)
+	}
+	if pos := open("ETHUSDT"); math.Abs(pos.EntryPx-100.02) > 1e-9 {
+		t.Fatalf("ETHUSDT EntryPx=%v, want 100.02", pos.EntryPx)
+	}
+}
+
+func TestExecutor_NotionalFill(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:     0.5,
+		SLRatio:     1.0,
+		MaxHoldMs:   60000,
+		NotionalUSD: 150.5,
+	}, config.FeeDetail{})
+
+	follower := &model.BookEvent{
+		Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT",
+		BestBidPx: 99.90, BestBidQty: 1, BestAskPx: 100.00, BestAskQty: 1,
+		Bids: []model.Level{{Price: 99.90, Qty: 1}, {Price: 99.00, Qty: 1}},
+		Asks: []model.Level{{Price: 100.00, Qty: 1}, {Price: 101.00, Qty: 1}},
+	}
+	pos, opened, err := exec.TryOpen(&model.Signal{
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    100,
+		DetectedAtNs: 1_000_000_000,
+		LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 101.00, BestAskPx: 101.01},
+		FollowerBook: follower,
+	})
+	if err != nil || !opened {
+		t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+	}
+	// 吃卖一 100 USD（1 个）+ 卖二 50.5 USD（0.5 个）：均价 150.5 / 1.5
+	if want := 150.5 / 1.5; math.Abs(pos.EntryPx-want) > 1e-9 {
+		t.Fatalf("EntryPx=%v, want %v", pos.EntryPx, want)
+	}
+	// 平多吃买盘：99.9 USD（1 个）+ 50.6 USD @ 99（约 0.511 个）
+	exitPx, err := exec.exitPx(model.SideLong, follower)
+	if want := 150.5 / (1 + 50.6/99.0); err != nil || math.Abs(exitPx-want) > 1e-9 {
+		t.Fatalf("exitPx=%v err=%v, want %v", exitPx, err, want)
+	}
+}
+
+func TestExecutor_VolScaling(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:     0.3,
+		SLRatio:     0.5,
+		MaxHoldMs:   60000,
+		VolScaleRef: 0.001,
+		VolScaleMin: 0.5,
+		VolScaleMax: 2,
+	}, config.FeeDetail{})
+	// 当前 vol 为参考值 5 倍，系数限制为上限 2：TP 0.6、SL 1.0、max_hold 30s
+	exec.SetVolSource(func(string) (float64, bool) { return 0.005, true })
+
+	pos, opened, err := exec.TryOpen(&model.Signal{
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    100,
+		DetectedAtNs: 1_000_000_000,
+		LeaderBook:   &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10},
+		FollowerBook: &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90},
+	})
+	if err != nil || !opened {
+		t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+	}
+	if pos.VolScale != 2 {
+		t.Fatalf("VolScale=%v, want 2", pos.VolScale)
+	}
+
+	leaderNow := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10}
+	// 价差约 163 bps：超过未缩放止损线 150 bps，未达缩放后的 200 bps
+	followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 98.30, BestAskPx: 98.40}
+	if closed := exec.Evaluate(1_200_000_000, leaderNow, followerNow); len(closed) != 0 {
+		t.Fatalf("缩放后不应止损: %s", closed[0].ExitReason)
+	}
+	// 价差约 50 bps（未达缩放后的止盈线 40 bps），持仓超过缩放后的 30s 超时
+	followerNow = &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.40, BestAskPx: 99.50}
+	closed := first(exec.Evaluate(31_200_000_000, leaderNow, followerNow))
+	if closed == nil || closed.ExitReason != model.ExitTimeout {
+		t.Fatalf("应按缩放后的 max_hold 超时平仓: %+v", closed)
+	}
+	if tr := closed.ToPaperTrade(nil); tr.VolScale != 2 {
+		t.Fatalf("PaperTrade.VolScale=%v, want 2", tr.VolScale)
+	}
+}
+
+func TestExecutor_LatencyFill(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:     0.5,
+		SLRatio:     1.0,
+		MaxHoldMs:   60000,
+		LatencyFill: true,
+	}, config.FeeDetail{})
+	exec.SetFillDelayNs(50_000_000) // 50ms
+
+	followerAt := func(arrivedNs int64, ask float64) *model.BookEvent {
+		return &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: ask - 0.01, BestAskPx: ask, ArrivedAtUnixNs: arrivedNs}
+	}
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 101.00, BestAskPx: 101.01}
+
+	pos, opened, err := exec.TryOpen(&model.Signal{
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    100,
+		DetectedAtNs: 1_000_000_000,
+		LeaderBook:   leader,
+		FollowerBook: followerAt(1_000_000_000, 100.00),
+	})
+	if err != nil || !opened {
+		t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+	}
+	if pos.PendingFillNs != 1_050_000_000 || pos.EntryPx != 0 {
+		t.Fatalf("仓位应待成交: %+v", pos)
+	}
+
+	// +30ms Follower 追价：未到成交时刻，不成交也不退出
+	if closed := exec.Evaluate(1_030_000_000, leader, followerAt(1_030_000_000, 100.40)); closed != nil {
+		t.Fatalf("待成交仓位不应平仓: %+v", closed)
+	}
+	if pos.PendingFillNs == 0 {
+		t.Fatalf("未到成交时刻不应成交")
+	}
+
+	// +60ms 事件到达：按 +50ms 时刻有效的盘口（+30ms 的 100.40）成交
+	exec.Evaluate(1_060_000_000, leader, followerAt(1_060_000_000, 100.80))
+	if pos.PendingFillNs != 0 || math.Abs(pos.EntryPx-100.40) > 1e-9 || pos.EntryTimeNs != 1_050_000_000 {
+		t.Fatalf("应按 +50ms 时刻盘口成交: %+v", pos)
+	}
+	if pos.FillDelayMs != 50 {
+		t.Fatalf("FillDelayMs=%v, want 50", pos.FillDelayMs)
+	}
+}
+
+func TestExecutor_QuotePersistence(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{
+		TPRatio:        0.5,
+		SLRatio:        1.0,
+		MaxHoldMs:      60000,
+		QuotePersistMs: 20,
+	}, config.FeeDetail{})
+
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 101.00, BestAskPx: 101.01}
+	follower := func(ask float64) *model.BookEvent {
+		return &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: ask - 0.01, BestAskPx: ask}
+	}
+	open := func(detectedAtNs int64) *model.Position {
+		pos, opened, err := exec.TryOpen(&model.Signal{
+			Leader:       model.ExchangeOKX,
+			SymbolCanon:  "BTCUSDT",
+			Side:         model.SideLong,
+			SpreadBps:    100,
+			DetectedAtNs: detectedAtNs,
+			LeaderBook:   leader,
+			FollowerBook: follower(100.00),
+		})
+		if err != nil || !opened {
+			t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+		}
+		return pos
+	}
+
+	// +10ms ask 上移：入场价已不可得，记为幻影成交并撤销仓位
+	open(1_000_000_000)
+	if closed := exec.Evaluate(1_010_000_000, leader, follower(100.05)); closed != nil {
+		t.Fatalf("幻影成交不应产生平仓: %+v", closed)
+	}
+	if exec.PhantomFills() != 1 || exec.HasOpen("BTCUSDT") {
+		t.Fatalf("PhantomFills=%d HasOpen=%v, want 1/false", exec.PhantomFills(), exec.HasOpen("BTCUSDT"))
+	}
+
+	// ask 不变持续到截止时刻后确认；之后的变化不再视为幻影
+	pos := open(2_000_000_000)
+	exec.Evaluate(2_010_000_000, leader, follower(99.99))
+	exec.Evaluate(2_025_000_000, leader, follower(100.05))
+	if pos.QuoteConfirmNs != 0 || exec.PhantomFills() != 1 || !exec.HasOpen("BTCUSDT") {
+		t.Fatalf("报价持续后应确认成交: %+v phantom=%d", pos, exec.PhantomFills())
+	}
+}
+
+func TestExecutor_Heartbeats(t *testing.T) {
+	exec := NewExecutor(model.ExchangeOKX, config.PaperConfig{TPRatio: 0.5, SLRatio: 1.0, MaxHoldMs: 60000}, config.FeeDetail{})
+	noBook := func(string) *model.BookEvent { return nil }
+	if hbs := exec.Heartbeats(0, noBook, noBook); hbs != nil {
+		t.Fatalf("无持仓时 Heartbeats=%v, want nil", hbs)
+	}
+
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.10}
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90}
+	_, opened, err := exec.TryOpen(&model.Signal{
+		Leader: model.ExchangeOKX, SymbolCanon: "BTCUSDT", Side: model.SideLong, SpreadBps: 10,
+		DetectedAtNs: 1_000_000_000, LeaderBook: leader, FollowerBook: follower,
+	})
+	if err != nil || !opened {
+		t.Fatalf("TryOpen failed: opened=%v err=%v", opened, err)
+	}
+
+	followerNow := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.95, BestAskPx: 99.97}
+	hbs := exec.Heartbeats(1_500_000_000,
+		func(string) *model.BookEvent { return leader },
+		func(string) *model.BookEvent { return followerNow })
+	if len(hbs) != 1 {
+		t.Fatalf("len(Heartbeats)=%d, want 1", len(hbs))
+	}
+	hb := hbs[0]
+	if hb.AgeMs != 500 || hb.EntrySpreadBps != 10 || hb.NoQuote || hb.Pending {
+		t.Fatalf("heartbeat=%+v", hb)
+	}
+	wantSpread := (100.00 - 99.97) / 99.97 * 10000
+	if math.Abs(hb.CurrentSpreadBps-wantSpread) > 1e-9 {
+		t.Fatalf("CurrentSpreadBps=%v, want %v", hb.CurrentSpreadBps, wantSpread)
+	}
+	// 按 Follower bid 平多：(99.95 - 99.90) / 99.90 × 10000 - 往返手续费
+	wantPnL := (99.95-99.90)/99.90*10000 - exec.RoundTripFeeBps()
+	if math.Abs(hb.UnrealizedPnLBps-wantPnL) > 1e-9 {
+		t.Fatalf("UnrealizedPnLBps=%v, want %v", hb.UnrealizedPnLBps, wantPnL)
+	}
+
+	if hb := exec.Heartbeats(1_500_000_000, noBook, noBook)[0]; !hb.NoQuote || hb.UnrealizedPnLBps != 0 {
+		t.Fatalf("缺少报价时 heartbeat=%+v, want NoQuote", hb)
+	}
+}
@@ -0,0 +1,51 @@
+package paper
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// Heartbeats 生成未平仓仓位心跳（按交易对、开仓时间排序）
+// 参数 leaderBook/followerBook: 按交易对获取最新 Leader/Follower 订单簿（可返回 nil）
+func (e *Executor) Heartbeats(nowNs int64, leaderBook, followerBook func(symbolCanon string) *model.BookEvent) []model.PositionHeartbeat {
+	open := e.OpenPositions()
+	if len(open) == 0 {
+		return nil
+	}
+	out := make([]model.PositionHeartbeat, 0, len(open))
+	for i := range open {
+		pos := &open[i]
+		hb := model.PositionHeartbeat{
+			TsUnixNs:       nowNs,
+			ID:             pos.ID,
+			SignalID:       pos.SignalID,
+			Variant:        pos.Variant,
+			Leader:         pos.Leader,
+			SymbolCanon:    pos.SymbolCanon,
+			Side:           string(pos.Side),
+			TEntryNs:       pos.EntryTimeNs,
+			AgeMs:          float64(nowNs-pos.EntryTimeNs) / 1e6,
+			EntryPx:        pos.EntryPx,
+			EntrySpreadBps: pos.EntrySpread,
+			MAEBps:         pos.MAEBps,
+			MFEBps:         pos.MFEBps,
+			Pending:        pos.PendingFillNs != 0 || pos.QuoteConfirmNs != 0,
+		}
+		lb, fb := leaderBook(pos.SymbolCanon), followerBook(pos.SymbolCanon)
+		hb.NoQuote = true
+		if lb != nil && fb != nil {
+			if spread, ok := currentSpreadBps(pos.Side, lb, fb); ok {
+				hb.CurrentSpreadBps = spread
+				hb.NoQuote = false
+			}
+		}
+		if !hb.Pending && pos.EntryPx > 0 {
+			if px, err := e.exitPx(pos.Side, fb); err == nil {
+				hb.UnrealizedPnLBps = (px-pos.EntryPx)/pos.EntryPx*10000*pos.Direction() - pos.FeeBps - e.borrowCostBps(pos, nowNs)
+			} else {
+				hb.NoQuote = true
+			}
+		}
+		out = append(out, hb)
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package paper
+
+import (
+	"time"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/core/store"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// fillHistorySize 每个交易对保留的 Follower 盘口历史条数（latency_fill 模式）
+const fillHistorySize = 32
+
+// FillDelayRefreshNs 按链路到达时延 P50 刷新 latency_fill 延迟的间隔（纳秒，业务时间）
+// 聚合器与回测在记录时延样本处按此间隔刷新，不依赖指标输出。
+const FillDelayRefreshNs = int64(time.Second)
+
+// SetFillDelayNs 设置 latency_fill 模式下信号到成交的模拟延迟（纳秒，通常为链路到达时延 P50）
+func (e *Executor) SetFillDelayNs(delayNs int64) {
+	if delayNs < 0 {
+		delayNs = 0
+	}
+	e.fillDelayNs = delayNs
+}
+
+// recordFollower 记录 Follower 盘口历史（仅 latency_fill 模式）
+// 保存副本：原事件在聚合器处理完后可能被归还对象池；同一盘口（到达时间与 Seq 相同）只记录一次。
+func (e *Executor) recordFollower(followerBook *model.BookEvent) {
+	if !e.cfg.LatencyFill || followerBook == nil {
+		return
+	}
+	h := e.history[followerBook.SymbolCanon]
+	if h == nil {
+		h = store.NewHistory(fillHistorySize)
+		e.history[followerBook.SymbolCanon] = h
+	}
+	if last := h.AsOf(followerBook.ArrivedAtUnixNs); last != nil &&
+		last.ArrivedAtUnixNs == followerBook.ArrivedAtUnixNs && last.Seq == followerBook.Seq {
+		return
+	}
+	h.Add(followerBook.Clone())
+}
+
+// fillPending 待成交仓位到达成交时刻后，按该时刻的 Follower 盘口成交
+// 返回: 仓位是否已成交
+func (e *Executor) fillPending(nowNs int64, pos *model.Position, followerBook *model.BookEvent) bool {
+	if pos.PendingFillNs == 0 {
+		return true
+	}
+	if nowNs < pos.PendingFillNs {
+		return false
+	}
+	book := followerBook
+	if h := e.history[pos.SymbolCanon]; h != nil {
+		if past := h.AsOf(pos.PendingFillNs); past != nil {
+			book = past
+		}
+	}
+	entryPx, err := e.entryPx(pos.Side, book)
+	if err != nil {
+		return false
+	}
+	pos.EntryPx = entryPx
+	pos.EntryTimeNs = pos.PendingFillNs
+	pos.EntryTime = timeutil.NanoToTime(pos.PendingFillNs)
+	pos.PendingFillNs = 0
+	e.startQuoteCheck(pos, book)
+	return true
+}
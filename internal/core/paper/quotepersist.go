@@ -0,0 +1,45 @@
+package paper
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// PhantomFills 返回累计幻影成交笔数
+// 幻影成交：入场所用的 Follower 最优价在 quote_persist_ms 内变差，真实订单大概率无法以该价成交。
+func (e *Executor) PhantomFills() int64 {
+	return e.phantomFills
+}
+
+// startQuoteCheck 成交后记录入场报价并开始持续性校验（仅 quote_persist_ms>0）
+func (e *Executor) startQuoteCheck(pos *model.Position, followerBook *model.BookEvent) {
+	if e.cfg.QuotePersistMs <= 0 || followerBook == nil {
+		return
+	}
+	switch pos.Side {
+	case model.SideLong:
+		pos.EntryQuotePx = followerBook.BestAskPx
+	case model.SideShort:
+		pos.EntryQuotePx = followerBook.BestBidPx
+	}
+	pos.QuoteConfirmNs = pos.EntryTimeNs + int64(e.cfg.QuotePersistMs)*1_000_000
+}
+
+// confirmQuote 校验入场报价是否持续到截止时刻
+// 返回 confirmed: 已确认（可参与退出判断）；phantom: 截止前报价变差（幻影成交）
+func (e *Executor) confirmQuote(nowNs int64, pos *model.Position, followerBook *model.BookEvent) (confirmed, phantom bool) {
+	if pos.QuoteConfirmNs == 0 {
+		return true, false
+	}
+	// 截止时刻之后才到达的变化不影响确认
+	if nowNs >= pos.QuoteConfirmNs {
+		pos.QuoteConfirmNs = 0
+		return true, false
+	}
+	switch pos.Side {
+	case model.SideLong:
+		phantom = followerBook.BestAskPx <= 0 || followerBook.BestAskPx > pos.EntryQuotePx
+	case model.SideShort:
+		phantom = followerBook.BestBidPx <= 0 || followerBook.BestBidPx < pos.EntryQuotePx
+	}
+	return false, phantom
+}
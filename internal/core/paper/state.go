@@ -0,0 +1,44 @@
+package paper
+
+import (
+	"sort"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// OpenPositions 导出未平仓仓位副本（按交易对、开仓时间排序，检查点用）
+func (e *Executor) OpenPositions() []model.Position {
+	out := make([]model.Position, 0, len(e.positions))
+	for _, open := range e.positions {
+		for _, pos := range open {
+			if pos != nil && !pos.Closed {
+				out = append(out, *pos)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SymbolCanon != out[j].SymbolCanon {
+			return out[i].SymbolCanon < out[j].SymbolCanon
+		}
+		return out[i].EntryTimeNs < out[j].EntryTimeNs
+	})
+	return out
+}
+
+// RestorePositions 恢复未平仓仓位（忽略其它 Leader 的仓位、已平仓仓位与超出单交易对上限的仓位）
+// 返回: 恢复的仓位数
+func (e *Executor) RestorePositions(positions []model.Position) int {
+	n := 0
+	for i := range positions {
+		pos := positions[i]
+		if pos.Closed || pos.Leader != e.leader || pos.SymbolCanon == "" {
+			continue
+		}
+		if len(e.positions[pos.SymbolCanon]) >= e.maxPerSymbol() {
+			continue
+		}
+		e.positions[pos.SymbolCanon] = append(e.positions[pos.SymbolCanon], &pos)
+		n++
+	}
+	return n
+}
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/blackout"
 	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/stats/volatility"
 	"latency-arbitrage-validator/internal/util/timeutil"
 )
 
@@ -15,6 +17,8 @@ type candidateState struct {
 	active   bool
 	startNs  int64
 	signaled bool
+	// volTagged 候选已因波动率过滤输出过标记信号（同一候选只输出一次）
+	volTagged bool
 }
 
 type volState struct {
@@ -23,6 +27,19 @@ type volState struct {
 	maxSamples   int
 }
 
+// volHorizon 波动率过滤窗口（按 1s 采样的样本数计）
+type volHorizon struct {
+	// samples 窗口包含的样本数
+	samples int
+	// threshold 1s 对数收益标准差阈值
+	threshold float64
+	// reason 触发时写入信号的 FilterReason，如 vol_10s
+	reason string
+}
+
+// volOutputSamples 信号输出 RealizedVol 使用的窗口（1 分钟）
+const volOutputSamples = 60
+
 type symbolState struct {
 	longCand  candidateState
 	shortCand candidateState
@@ -43,9 +60,19 @@ type Engine struct {
 
 	// persistNs 持续时间过滤（纳秒）
 	persistNs int64
+	// volHorizons 波动率过滤窗口（strategy.vol_horizons；为空时为 1 分钟 + vol_threshold）
+	volHorizons []volHorizon
+	// volMaxSamples 每个交易对保留的中间价样本数（覆盖最长窗口与输出窗口）
+	volMaxSamples int
+
+	// calendar 禁止开仓时段（nil 表示不启用）
+	calendar *blackout.Calendar
 
 	// states 按交易对维护状态
 	states map[string]*symbolState
+
+	// filtered 最近一次 Evaluate 被波动率过滤的标记信号（见 TakeFiltered）
+	filtered *model.Signal
 }
 
 // NewEngine 创建信号引擎
@@ -58,9 +85,49 @@ func NewEngine(leader string, cfg config.StrategyConfig) *Engine {
 		persistNs: int64(cfg.PersistMs) * 1_000_000,
 		states:    make(map[string]*symbolState),
 	}
+	e.volHorizons = buildVolHorizons(cfg)
+	e.volMaxSamples = volOutputSamples
+	for _, h := range e.volHorizons {
+		if h.samples > e.volMaxSamples {
+			e.volMaxSamples = h.samples
+		}
+	}
 	return e
 }
 
+// buildVolHorizons 由配置生成波动率过滤窗口
+func buildVolHorizons(cfg config.StrategyConfig) []volHorizon {
+	if len(cfg.VolHorizons) == 0 {
+		return []volHorizon{{samples: volOutputSamples, threshold: cfg.VolThreshold, reason: "vol_1m"}}
+	}
+	out := make([]volHorizon, 0, len(cfg.VolHorizons))
+	for _, h := range cfg.VolHorizons {
+		out = append(out, volHorizon{
+			samples:   h.WindowMs / 1000,
+			threshold: h.Threshold,
+			reason:    "vol_" + horizonLabel(h.WindowMs),
+		})
+	}
+	return out
+}
+
+// horizonLabel 窗口长度的简写（10000 -> 10s，300000 -> 5m）
+func horizonLabel(ms int) string {
+	switch {
+	case ms%60_000 == 0:
+		return fmt.Sprintf("%dm", ms/60_000)
+	case ms%1000 == 0:
+		return fmt.Sprintf("%ds", ms/1000)
+	default:
+		return fmt.Sprintf("%dms", ms)
+	}
+}
+
+// SetCalendar 设置禁止开仓时段；时段内产生的信号标记 FilterReason=blackout
+func (e *Engine) SetCalendar(c *blackout.Calendar) {
+	e.calendar = c
+}
+
 // NotifyStopLoss 通知引擎发生止损，用于触发冷却窗口
 // 参数 symbolCanon: 统一交易对
 // 参数 nowNs: 当前时间（纳秒）
@@ -69,9 +136,18 @@ func (e *Engine) NotifyStopLoss(symbolCanon string, nowNs int64) {
 	st.cooldownUntilNs = nowNs + int64(e.cfg.CooldownMs)*1_000_000
 }
 
+// TakeFiltered 取出最近一次 Evaluate 因波动率过滤未触发的标记信号（FilterReason=vol_<窗口>），取出后清空
+// 被过滤的候选不消耗，波动回落后仍可正常触发；同一候选只产生一次标记信号。
+func (e *Engine) TakeFiltered() *model.Signal {
+	sig := e.filtered
+	e.filtered = nil
+	return sig
+}
+
 // Evaluate 评估当前 Leader/Follower 订单簿是否触发信号
 // 返回值：若触发并通过过滤器返回 Signal，否则返回 nil。
 func (e *Engine) Evaluate(nowNs int64, leaderBook, followerBook *model.BookEvent) *model.Signal {
+	e.filtered = nil
 	if leaderBook == nil || followerBook == nil {
 		return nil
 	}
@@ -95,24 +171,20 @@ func (e *Engine) Evaluate(nowNs int64, leaderBook, followerBook *model.BookEvent
 		return nil
 	}
 
-	// 深度过滤：Leader 前 5 档名义价值必须达到阈值
-	if e.cfg.MinDepthUSD > 0 && leaderBook.Top5DepthUSD() < e.cfg.MinDepthUSD {
+	// 深度过滤：Leader 盘口须能吃下 min_depth_usd 的名义金额
+	if e.cfg.MinDepthUSD > 0 && !e.depthFillable(leaderBook) {
 		e.resetCandidates(st)
 		return nil
 	}
 
-	// 波动率过滤：1min realized vol 超阈值跳过（可关闭）
-	if e.cfg.VolFilterEnabled {
-		e.updateVol(st, nowNs, leaderBook.MidPrice())
-		if e.realizedVol(st) > e.cfg.VolThreshold {
-			return nil
-		}
-	}
+	// 波动率采样始终进行，信号输出中携带波动率估计；
+	// 过滤在候选触发时判断（见 fire），超阈值时不出信号，标记信号经 TakeFiltered 取出。
+	e.updateVol(st, nowNs, leaderBook.MidPrice())
 
 	// 计算多头信号：Leader_bid - Follower_ask > θ_entry
 	longBps, longOK := calcLongSpreadBps(leaderBook, followerBook)
 	if longOK && longBps > e.cfg.ThetaEntryBps {
-		if sig := e.tryFire(nowNs, leaderBook, followerBook, model.SideLong, longBps, &st.longCand); sig != nil {
+		if sig := e.tryFire(nowNs, st, leaderBook, followerBook, model.SideLong, longBps, &st.longCand); sig != nil {
 			return sig
 		}
 	} else {
@@ -122,7 +194,7 @@ func (e *Engine) Evaluate(nowNs int64, leaderBook, followerBook *model.BookEvent
 	// 计算空头信号：Follower_bid - Leader_ask > θ_entry
 	shortBps, shortOK := calcShortSpreadBps(leaderBook, followerBook)
 	if shortOK && shortBps > e.cfg.ThetaEntryBps {
-		if sig := e.tryFire(nowNs, leaderBook, followerBook, model.SideShort, shortBps, &st.shortCand); sig != nil {
+		if sig := e.tryFire(nowNs, st, leaderBook, followerBook, model.SideShort, shortBps, &st.shortCand); sig != nil {
 			return sig
 		}
 	} else {
@@ -139,7 +211,7 @@ func (e *Engine) getState(symbolCanon string) *symbolState {
 	}
 	st = &symbolState{
 		vol: volState{
-			maxSamples: 60, // 1 分钟：按 1s 采样
+			maxSamples: e.volMaxSamples, // 按 1s 采样
 		},
 	}
 	e.states[symbolCanon] = st
@@ -151,27 +223,15 @@ func (e *Engine) resetCandidates(st *symbolState) {
 	st.shortCand = candidateState{}
 }
 
-func (e *Engine) tryFire(nowNs int64, leaderBook, followerBook *model.BookEvent, side model.Side, spreadBps float64, cand *candidateState) *model.Signal {
+func (e *Engine) tryFire(nowNs int64, st *symbolState, leaderBook, followerBook *model.BookEvent, side model.Side, spreadBps float64, cand *candidateState) *model.Signal {
 	if !cand.active {
 		cand.active = true
 		cand.startNs = nowNs
 		cand.signaled = false
 
 		// persist=0 表示不需要持续性过滤，首次满足条件即触发。
-		if e.persistNs == 0 {
-			cand.signaled = true
-			id := fmt.Sprintf("%s-%s-%s-%d", e.leader, leaderBook.SymbolCanon, side, nowNs)
-			return &model.Signal{
-				ID:           id,
-				Leader:       e.leader,
-				SymbolCanon:  leaderBook.SymbolCanon,
-				Side:         side,
-				SpreadBps:    spreadBps,
-				LeaderBook:   leaderBook.Clone(),
-				FollowerBook: followerBook.Clone(),
-				DetectedAt:   timeutil.NanoToTime(nowNs),
-				DetectedAtNs: nowNs,
-			}
+		if e.persistNs == 0 && !e.adverseImbalance(followerBook, side) {
+			return e.fire(nowNs, st, leaderBook, followerBook, side, spreadBps, cand)
 		}
 
 		return nil
@@ -182,20 +242,90 @@ func (e *Engine) tryFire(nowNs int64, leaderBook, followerBook *model.BookEvent,
 	if nowNs-cand.startNs < e.persistNs {
 		return nil
 	}
+	if e.adverseImbalance(followerBook, side) {
+		return nil
+	}
+
+	return e.fire(nowNs, st, leaderBook, followerBook, side, spreadBps, cand)
+}
 
+// fire 候选满足触发条件时出信号并标记已触发
+// 波动率超阈值时不出信号、不标记已触发，仅首次命中记录标记信号；禁止开仓时段优先于波动率过滤。
+func (e *Engine) fire(nowNs int64, st *symbolState, leaderBook, followerBook *model.BookEvent, side model.Side, spreadBps float64, cand *candidateState) *model.Signal {
+	if _, inBlackout := e.calendar.Active(nowNs); !inBlackout && e.volFilterReason(st) != "" {
+		if !cand.volTagged {
+			cand.volTagged = true
+			e.filtered = e.newSignal(nowNs, st, leaderBook, followerBook, side, spreadBps, cand)
+		}
+		return nil
+	}
 	cand.signaled = true
+	return e.newSignal(nowNs, st, leaderBook, followerBook, side, spreadBps, cand)
+}
 
+// newSignal 构造信号，并记录触发时的阈值、深度、波动率与持续时间
+func (e *Engine) newSignal(nowNs int64, st *symbolState, leaderBook, followerBook *model.BookEvent, side model.Side, spreadBps float64, cand *candidateState) *model.Signal {
 	id := fmt.Sprintf("%s-%s-%s-%d", e.leader, leaderBook.SymbolCanon, side, nowNs)
 	return &model.Signal{
-		ID:           id,
-		Leader:       e.leader,
-		SymbolCanon:  leaderBook.SymbolCanon,
-		Side:         side,
-		SpreadBps:    spreadBps,
-		LeaderBook:   leaderBook.Clone(),
-		FollowerBook: followerBook.Clone(),
-		DetectedAt:   timeutil.NanoToTime(nowNs),
-		DetectedAtNs: nowNs,
+		SchemaVersion:     model.SignalSchemaVersion,
+		ID:                id,
+		Leader:            e.leader,
+		SymbolCanon:       leaderBook.SymbolCanon,
+		Side:              side,
+		SpreadBps:         spreadBps,
+		LeaderBook:        leaderBook.Clone(),
+		FollowerBook:      followerBook.Clone(),
+		DetectedAt:        timeutil.NanoToTime(nowNs),
+		DetectedAtNs:      nowNs,
+		ThetaEntryBps:     e.cfg.ThetaEntryBps,
+		DepthUSD:          e.depthUSD(leaderBook),
+		FollowerImbalance: followerBook.Imbalance(),
+		RealizedVol:       e.realizedVol(st, volOutputSamples),
+		FilterReason:      e.filterReason(nowNs, st),
+		PersistElapsedMs:  float64(nowNs-cand.startNs) / 1e6,
+	}
+}
+
+// adverseImbalance 判断 Follower 盘口是否明显逆向于交易方向（strategy.max_adverse_imbalance）
+// 逆向失衡时候选保持激活、不标记已触发，失衡缓解后仍可出信号。
+func (e *Engine) adverseImbalance(followerBook *model.BookEvent, side model.Side) bool {
+	limit := e.cfg.MaxAdverseImbalance
+	if limit <= 0 {
+		return false
+	}
+	imb := followerBook.Imbalance()
+	if side == model.SideLong {
+		return imb < -limit
+	}
+	return imb > limit
+}
+
+// depthFillable 判断盘口按 strategy.min_depth_side 逐档吃单能否成交 min_depth_usd 的名义金额
+// 买盘深度对应卖出（空头方向）吃单，卖盘深度对应买入（多头方向）吃单；both 要求两侧均可成交。
+func (e *Engine) depthFillable(book *model.BookEvent) bool {
+	fillable := func(side model.Side) bool {
+		_, filled := book.VWAPForNotional(side, e.cfg.MinDepthUSD)
+		return filled >= 1
+	}
+	switch e.cfg.MinDepthSide {
+	case config.DepthSideBid:
+		return fillable(model.SideShort)
+	case config.DepthSideAsk:
+		return fillable(model.SideLong)
+	default:
+		return fillable(model.SideShort) && fillable(model.SideLong)
+	}
+}
+
+// depthUSD 按 strategy.min_depth_side 统计盘口前 5 档名义价值（信号输出用）
+func (e *Engine) depthUSD(book *model.BookEvent) float64 {
+	switch e.cfg.MinDepthSide {
+	case config.DepthSideBid:
+		return book.Top5BidDepthUSD()
+	case config.DepthSideAsk:
+		return book.Top5AskDepthUSD()
+	default:
+		return math.Min(book.Top5BidDepthUSD(), book.Top5AskDepthUSD())
 	}
 }
 
@@ -213,7 +343,7 @@ func calcShortSpreadBps(leaderBook, followerBook *model.BookEvent) (float64, boo
 	return (followerBook.BestBidPx - leaderBook.BestAskPx) / leaderBook.BestAskPx * 10000, true
 }
 
-// updateVol 更新 1 分钟 realized vol 的采样序列（1s 采样）
+// updateVol 更新 realized vol 的采样序列（1s 采样，保留最长窗口所需的样本）
 func (e *Engine) updateVol(st *symbolState, nowNs int64, midPx float64) {
 	if midPx <= 0 {
 		return
@@ -229,38 +359,35 @@ func (e *Engine) updateVol(st *symbolState, nowNs int64, midPx float64) {
 	}
 }
 
-// realizedVol 计算 1 分钟 realized volatility（log return 的标准差）
-// 返回值越大表示波动越大；本实现为验证阶段的轻量版本。
-func (e *Engine) realizedVol(st *symbolState) float64 {
-	n := len(st.vol.samples)
-	if n < 2 {
-		return 0
-	}
-
-	// 计算 log returns
-	returns := make([]float64, 0, n-1)
-	for i := 1; i < n; i++ {
-		p0 := st.vol.samples[i-1]
-		p1 := st.vol.samples[i]
-		if p0 <= 0 || p1 <= 0 {
-			continue
-		}
-		returns = append(returns, math.Log(p1/p0))
-	}
-	if len(returns) < 2 {
-		return 0
+// filterReason 返回信号的过滤原因：禁止开仓时段优先，其次为波动率过滤
+func (e *Engine) filterReason(nowNs int64, st *symbolState) string {
+	if _, active := e.calendar.Active(nowNs); active {
+		return model.FilterReasonBlackout
 	}
+	return e.volFilterReason(st)
+}
 
-	var sum float64
-	for _, r := range returns {
-		sum += r
+// volFilterReason 按 strategy.vol_horizons 顺序检查各窗口，返回首个超阈值窗口的过滤原因
+// 未启用波动率过滤或均未超阈值时返回空串。
+func (e *Engine) volFilterReason(st *symbolState) string {
+	if !e.cfg.VolFilterEnabled {
+		return ""
+	}
+	for _, h := range e.volHorizons {
+		if e.realizedVol(st, h.samples) > h.threshold {
+			return h.reason
+		}
 	}
-	mean := sum / float64(len(returns))
+	return ""
+}
 
-	var ss float64
-	for _, r := range returns {
-		d := r - mean
-		ss += d * d
+// realizedVol 计算最近 n 个 1s 样本的 realized volatility（log return 的标准差）
+// 返回值越大表示波动越大；本实现为验证阶段的轻量版本。
+// 暂不支持按 Leader 逐笔成交计算：当前未接入成交流（trades/aggTrade），待接入后再以成交价替代 1s 中间价采样。
+func (e *Engine) realizedVol(st *symbolState, n int) float64 {
+	samples := st.vol.samples
+	if len(samples) > n {
+		samples = samples[len(samples)-n:]
 	}
-	return math.Sqrt(ss / float64(len(returns)-1))
+	return volatility.Realized(samples)
 }
@@ -48,14 +48,14 @@ func TestEngine_SignalDetection_Property(t *testing.T) {
 				SymbolCanon: "BTCUSDT",
 				BestBidPx:   leaderBid,
 				BestAskPx:   leaderBid + 0.01,
-				Levels:      []model.Level{{Price: leaderBid, Qty: 10}},
+				Bids:        []model.Level{{Price: leaderBid, Qty: 10}},
 			}
 			follower := &model.BookEvent{
 				Exchange:    model.ExchangeBittap,
 				SymbolCanon: "BTCUSDT",
 				BestBidPx:   followerAsk - 0.01,
 				BestAskPx:   followerAsk,
-				Levels:      []model.Level{{Price: followerAsk, Qty: 10}},
+				Asks:        []model.Level{{Price: followerAsk, Qty: 10}},
 			}
 
 			sig := e.Evaluate(1_000_000_000, leader, follower)
@@ -93,14 +93,14 @@ func TestEngine_SignalDetection_Property(t *testing.T) {
 				SymbolCanon: "BTCUSDT",
 				BestBidPx:   leaderAsk - 0.01,
 				BestAskPx:   leaderAsk,
-				Levels:      []model.Level{{Price: leaderAsk, Qty: 10}},
+				Bids:        []model.Level{{Price: leaderAsk, Qty: 10}},
 			}
 			follower := &model.BookEvent{
 				Exchange:    model.ExchangeBittap,
 				SymbolCanon: "BTCUSDT",
 				BestBidPx:   followerBid,
 				BestAskPx:   followerBid + 0.01,
-				Levels:      []model.Level{{Price: followerBid, Qty: 10}},
+				Asks:        []model.Level{{Price: followerBid, Qty: 10}},
 			}
 
 			sig := e.Evaluate(1_000_000_000, leader, follower)
@@ -136,8 +136,8 @@ func TestEngine_PersistenceFilter_Property(t *testing.T) {
 				CooldownMs:       0,
 			})
 
-			leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, Levels: []model.Level{{Price: 100, Qty: 10}}}
-			follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, Levels: []model.Level{{Price: 99.90, Qty: 10}}}
+			leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, Bids: []model.Level{{Price: 100, Qty: 10}}}
+			follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, Asks: []model.Level{{Price: 99.90, Qty: 10}}}
 
 			now := int64(1_000_000_000)
 			if sig := e.Evaluate(now, leader, follower); sig != nil {
@@ -176,8 +176,8 @@ func TestEngine_DepthFilter_Property(t *testing.T) {
 				CooldownMs:       0,
 			})
 
-			leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, Levels: []model.Level{{Price: 100, Qty: 0.0001}}}
-			follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, Levels: []model.Level{{Price: 99.90, Qty: 10}}}
+			leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, Bids: []model.Level{{Price: 100, Qty: 0.0001}}}
+			follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, Asks: []model.Level{{Price: 99.90, Qty: 10}}}
 
 			// 由于 Leader 深度极小且 minDepth>0，必被过滤
 			return e.Evaluate(1_000_000_000, leader, follower) == nil
@@ -201,8 +201,8 @@ func TestEngine_VolatilityFilter_Property(t *testing.T) {
 		CooldownMs:       0,
 	})
 
-	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, Levels: []model.Level{{Price: 100, Qty: 10}}}
-	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, Levels: []model.Level{{Price: 99.90, Qty: 10}}}
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, Bids: []model.Level{{Price: 100, Qty: 10}}}
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, Asks: []model.Level{{Price: 99.90, Qty: 10}}}
 
 	// 通过 1s 采样让 mid price 发生变化，形成非零 realized vol
 	now := int64(1_000_000_000)
@@ -241,8 +241,8 @@ func TestEngine_Cooldown_Property(t *testing.T) {
 				CooldownMs:       cooldownMs,
 			})
 
-			leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, Levels: []model.Level{{Price: 100, Qty: 10}}}
-			follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, Levels: []model.Level{{Price: 99.90, Qty: 10}}}
+			leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01, Bids: []model.Level{{Price: 100, Qty: 10}}}
+			follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestAskPx: 99.90, Asks: []model.Level{{Price: 99.90, Qty: 10}}}
 
 			now := int64(1_000_000_000)
 			e.NotifyStopLoss("BTCUSDT", now)
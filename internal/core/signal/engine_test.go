@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/blackout"
 	"latency-arbitrage-validator/internal/core/model"
 )
 
@@ -21,14 +22,15 @@ func TestEngine_PersistFilter_Long(t *testing.T) {
 		SymbolCanon: "BTCUSDT",
 		BestBidPx:   100.00,
 		BestAskPx:   100.01,
-		Levels:      []model.Level{{Price: 100.00, Qty: 100}},
+		Bids:        []model.Level{{Price: 100.00, Qty: 100}},
+		Asks:        []model.Level{{Price: 100.01, Qty: 100}},
 	}
 	follower := &model.BookEvent{
 		Exchange:    model.ExchangeBittap,
 		SymbolCanon: "BTCUSDT",
 		BestBidPx:   99.80,
 		BestAskPx:   99.90,
-		Levels:      []model.Level{{Price: 99.90, Qty: 100}},
+		Asks:        []model.Level{{Price: 99.90, Qty: 100}},
 	}
 
 	now := int64(1_000_000_000)
@@ -52,6 +54,13 @@ func TestEngine_PersistFilter_Long(t *testing.T) {
 	if sig.Leader != model.ExchangeOKX {
 		t.Fatalf("Leader=%s, want okx", sig.Leader)
 	}
+	if sig.ThetaEntryBps != 10 || sig.PersistElapsedMs != 110 {
+		t.Fatalf("ThetaEntryBps=%v PersistElapsedMs=%v, want 10/110", sig.ThetaEntryBps, sig.PersistElapsedMs)
+	}
+	// 默认 both：买卖盘分别统计取较小值
+	if sig.DepthUSD != leader.Top5BidDepthUSD() {
+		t.Fatalf("DepthUSD=%v, want %v", sig.DepthUSD, leader.Top5BidDepthUSD())
+	}
 
 	// 条件持续成立，不应重复出信号（需等待条件失效后重新武装）
 	if sig2 := e.Evaluate(now+200*1_000_000, leader, follower); sig2 != nil {
@@ -78,14 +87,14 @@ func TestEngine_PersistFilter_Short(t *testing.T) {
 		SymbolCanon: "BTCUSDT",
 		BestBidPx:   100.00,
 		BestAskPx:   100.10,
-		Levels:      []model.Level{{Price: 100.10, Qty: 100}},
+		Bids:        []model.Level{{Price: 100.10, Qty: 100}},
 	}
 	follower := &model.BookEvent{
 		Exchange:    model.ExchangeBittap,
 		SymbolCanon: "BTCUSDT",
 		BestBidPx:   100.50,
 		BestAskPx:   100.60,
-		Levels:      []model.Level{{Price: 100.50, Qty: 100}},
+		Asks:        []model.Level{{Price: 100.50, Qty: 100}},
 	}
 
 	now := int64(1_000_000_000)
@@ -113,14 +122,14 @@ func TestEngine_DepthFilter(t *testing.T) {
 		SymbolCanon: "BTCUSDT",
 		BestBidPx:   100.00,
 		BestAskPx:   100.01,
-		Levels:      []model.Level{{Price: 100.00, Qty: 1}},
+		Bids:        []model.Level{{Price: 100.00, Qty: 1}},
 	}
 	follower := &model.BookEvent{
 		Exchange:    model.ExchangeBittap,
 		SymbolCanon: "BTCUSDT",
 		BestBidPx:   99.80,
 		BestAskPx:   99.90,
-		Levels:      []model.Level{{Price: 99.90, Qty: 1}},
+		Asks:        []model.Level{{Price: 99.90, Qty: 1}},
 	}
 
 	if sig := e.Evaluate(1_000_000_000, leader, follower); sig != nil {
@@ -141,14 +150,14 @@ func TestEngine_CooldownAfterStopLoss(t *testing.T) {
 		SymbolCanon: "BTCUSDT",
 		BestBidPx:   100.00,
 		BestAskPx:   100.01,
-		Levels:      []model.Level{{Price: 100.00, Qty: 100}},
+		Bids:        []model.Level{{Price: 100.00, Qty: 100}},
 	}
 	follower := &model.BookEvent{
 		Exchange:    model.ExchangeBittap,
 		SymbolCanon: "BTCUSDT",
 		BestBidPx:   99.80,
 		BestAskPx:   99.90,
-		Levels:      []model.Level{{Price: 99.90, Qty: 100}},
+		Asks:        []model.Level{{Price: 99.90, Qty: 100}},
 	}
 
 	now := int64(1_000_000_000)
@@ -160,3 +169,177 @@ func TestEngine_CooldownAfterStopLoss(t *testing.T) {
 		t.Fatalf("冷却结束后应允许产生信号")
 	}
 }
+
+func TestEngine_DepthSide(t *testing.T) {
+	// Leader 买盘深厚、卖盘单薄
+	leader := &model.BookEvent{
+		Exchange:    model.ExchangeOKX,
+		SymbolCanon: "BTCUSDT",
+		BestBidPx:   100.00,
+		BestAskPx:   100.01,
+		Bids:        []model.Level{{Price: 100.00, Qty: 100}, {Price: 99.99, Qty: 100}},
+		Asks:        []model.Level{{Price: 100.01, Qty: 1}},
+	}
+	follower := &model.BookEvent{
+		Exchange:    model.ExchangeBittap,
+		SymbolCanon: "BTCUSDT",
+		BestBidPx:   99.80,
+		BestAskPx:   99.90,
+		Asks:        []model.Level{{Price: 99.90, Qty: 100}},
+	}
+
+	tests := []struct {
+		side       string
+		wantSignal bool
+	}{
+		{config.DepthSideBid, true},
+		{config.DepthSideAsk, false},
+		{config.DepthSideBoth, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		e := NewEngine(model.ExchangeOKX, config.StrategyConfig{
+			ThetaEntryBps: 10,
+			PersistMs:     0,
+			MinDepthUSD:   10_000,
+			MinDepthSide:  tt.side,
+		})
+		if got := e.Evaluate(1_000_000_000, leader, follower) != nil; got != tt.wantSignal {
+			t.Fatalf("min_depth_side=%q: signal=%v, want %v", tt.side, got, tt.wantSignal)
+		}
+	}
+}
+
+func TestEngine_AdverseImbalanceFilter(t *testing.T) {
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.00, BestAskPx: 100.01}
+	// Follower 卖盘压倒买盘：失衡度 = (1-9)/10 = -0.8，逆向于多头
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.80, BestBidQty: 1, BestAskPx: 99.90, BestAskQty: 9}
+
+	e := NewEngine(model.ExchangeOKX, config.StrategyConfig{ThetaEntryBps: 10, MaxAdverseImbalance: 0.5})
+	if sig := e.Evaluate(1_000_000_000, leader, follower); sig != nil {
+		t.Fatalf("Follower 逆向失衡时不应产生多头信号")
+	}
+
+	// 失衡缓解后候选仍可触发
+	follower.BestBidQty, follower.BestAskQty = 5, 5
+	sig := e.Evaluate(1_001_000_000, leader, follower)
+	if sig == nil || sig.Side != model.SideLong || sig.FollowerImbalance != 0 {
+		t.Fatalf("失衡缓解后应产生多头信号: %+v", sig)
+	}
+
+	// 阈值为 0 时不过滤
+	follower.BestBidQty, follower.BestAskQty = 1, 9
+	off := NewEngine(model.ExchangeOKX, config.StrategyConfig{ThetaEntryBps: 10})
+	if sig := off.Evaluate(1_000_000_000, leader, follower); sig == nil {
+		t.Fatalf("未启用失衡过滤时应产生信号")
+	}
+}
+
+func TestEngine_VolHorizons(t *testing.T) {
+	run := func(cfg config.StrategyConfig) *model.Signal {
+		e := NewEngine(model.ExchangeOKX, cfg)
+		leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT"}
+		// Follower 先远离 Leader，仅最后一次评估形成价差
+		follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 199.9, BestAskPx: 200}
+		var sig *model.Signal
+		for i, mid := range []float64{100, 101, 99.5} {
+			if i == 2 {
+				follower.BestBidPx, follower.BestAskPx = 99.0, 99.1
+			}
+			leader.BestBidPx, leader.BestAskPx = mid, mid+0.01
+			sig = e.Evaluate(int64(i+1)*1_000_000_000, leader, follower)
+			if sig == nil {
+				sig = e.TakeFiltered()
+			}
+		}
+		if sig == nil {
+			t.Fatalf("应产生信号")
+		}
+		return sig
+	}
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		horizons []config.VolHorizonConfig
+		want     string
+	}{
+		{"短窗口触发", true, []config.VolHorizonConfig{{WindowMs: 10_000, Threshold: 0}, {WindowMs: 300_000, Threshold: 1}}, "vol_10s"},
+		{"长窗口触发", true, []config.VolHorizonConfig{{WindowMs: 10_000, Threshold: 1}, {WindowMs: 60_000, Threshold: 0}}, "vol_1m"},
+		{"均未超阈值", true, []config.VolHorizonConfig{{WindowMs: 10_000, Threshold: 1}, {WindowMs: 300_000, Threshold: 1}}, ""},
+		{"未启用", false, []config.VolHorizonConfig{{WindowMs: 10_000, Threshold: 0}}, ""},
+	}
+	for _, tt := range tests {
+		sig := run(config.StrategyConfig{ThetaEntryBps: 10, VolFilterEnabled: tt.enabled, VolHorizons: tt.horizons})
+		if sig.FilterReason != tt.want {
+			t.Errorf("%s: FilterReason=%q, want %q", tt.name, sig.FilterReason, tt.want)
+		}
+		if sig.RealizedVol <= 0 {
+			t.Errorf("%s: RealizedVol 应为正", tt.name)
+		}
+	}
+}
+
+func TestEngine_VolFilterKeepsCandidate(t *testing.T) {
+	e := NewEngine(model.ExchangeOKX, config.StrategyConfig{
+		ThetaEntryBps:    10,
+		VolFilterEnabled: true,
+		VolHorizons:      []config.VolHorizonConfig{{WindowMs: 10_000, Threshold: 0}},
+	})
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT"}
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 199.9, BestAskPx: 200}
+	for i, mid := range []float64{100, 101} {
+		leader.BestBidPx, leader.BestAskPx = mid, mid+0.01
+		e.Evaluate(int64(i+1)*1_000_000_000, leader, follower)
+	}
+
+	// 波动率超阈值：不出信号，标记信号仅在首次命中时输出
+	follower.BestBidPx, follower.BestAskPx = 99.0, 99.1
+	leader.BestBidPx, leader.BestAskPx = 99.5, 99.51
+	if sig := e.Evaluate(3_000_000_000, leader, follower); sig != nil {
+		t.Fatalf("波动率超阈值时不应产生信号: %+v", sig)
+	}
+	tagged := e.TakeFiltered()
+	if tagged == nil || tagged.FilterReason != "vol_10s" || tagged.Side != model.SideLong {
+		t.Fatalf("应输出 vol_10s 标记信号, got %+v", tagged)
+	}
+	if sig := e.Evaluate(4_000_000_000, leader, follower); sig != nil || e.TakeFiltered() != nil {
+		t.Fatalf("同一候选不应重复输出标记信号")
+	}
+
+	// 中间价平稳后波动率回落为 0，原候选仍可触发
+	var sig *model.Signal
+	for now := int64(5_000_000_000); now <= 12_000_000_000 && sig == nil; now += 1_000_000_000 {
+		sig = e.Evaluate(now, leader, follower)
+	}
+	if sig == nil || sig.FilterReason != "" {
+		t.Fatalf("波动回落后应产生可开仓信号, got %+v", sig)
+	}
+	if sig.PersistElapsedMs != 9000 {
+		t.Fatalf("候选应保留首次满足时间: PersistElapsedMs=%f, want 9000", sig.PersistElapsedMs)
+	}
+}
+
+func TestEngine_Blackout(t *testing.T) {
+	cal := blackout.New(config.BlackoutConfig{
+		Daily: []config.BlackoutWindowConfig{{Name: "funding", Start: "00:00:00", End: "00:00:10"}},
+	})
+	newEngine := func() *Engine {
+		e := NewEngine(model.ExchangeOKX, config.StrategyConfig{ThetaEntryBps: 10})
+		e.SetCalendar(cal)
+		return e
+	}
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100, BestAskPx: 100.01}
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.0, BestAskPx: 99.1}
+
+	// 时段内：信号照常产生但标记为被过滤
+	sig := newEngine().Evaluate(5_000_000_000, leader, follower)
+	if sig == nil || sig.FilterReason != model.FilterReasonBlackout {
+		t.Fatalf("时段内应产生被过滤的信号, got %+v", sig)
+	}
+	// 时段外：不受影响
+	sig = newEngine().Evaluate(20_000_000_000, leader, follower)
+	if sig == nil || sig.FilterReason != "" {
+		t.Fatalf("时段外应产生可开仓信号, got %+v", sig)
+	}
+}
@@ -0,0 +1,19 @@
+package signal
+
+// Cooldowns 导出止损冷却到期时间（按交易对，纳秒；仅包含设置过冷却的交易对）
+func (e *Engine) Cooldowns() map[string]int64 {
+	out := make(map[string]int64)
+	for sym, st := range e.states {
+		if st.cooldownUntilNs > 0 {
+			out[sym] = st.cooldownUntilNs
+		}
+	}
+	return out
+}
+
+// RestoreCooldowns 恢复止损冷却到期时间（已过期的冷却不影响后续信号）
+func (e *Engine) RestoreCooldowns(cooldowns map[string]int64) {
+	for sym, until := range cooldowns {
+		e.getState(sym).cooldownUntilNs = until
+	}
+}
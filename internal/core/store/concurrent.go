@@ -0,0 +1,158 @@
+package store
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// shardCount ConcurrentStore 的分片数（按交易对散列）
+const shardCount = 16
+
+// Snapshot 单个交易对在各交易所的最新订单簿副本
+// 同一交易对的各交易所盘口在同一把分片锁下复制，彼此一致；副本与缓存互不影响。
+type Snapshot struct {
+	// SymbolCanon 统一交易对标识
+	SymbolCanon string
+	// Books 按交易所的订单簿副本（未收到行情的交易所不出现）
+	Books map[string]*model.BookEvent
+}
+
+// Get 获取指定交易所的订单簿副本（可能为 nil）
+func (s Snapshot) Get(exchange string) *model.BookEvent {
+	return s.Books[exchange]
+}
+
+// ConcurrentStore 并发安全的最新订单簿缓存
+// 按交易对散列到带读写锁的分片，写入方（聚合器）与多个读取方（看板、markout、REST API）
+// 可并发访问；读取接口均返回深拷贝，调用方可自由持有与修改。
+// Seq 过期/重置规则与 Store 相同。
+type ConcurrentStore struct {
+	shards [shardCount]*shard
+}
+
+// shard 单个分片
+type shard struct {
+	mu sync.RWMutex
+	// books 第一层 key: SymbolCanon；第二层 key: exchange
+	books map[string]map[string]*model.BookEvent
+	// stale/seqResets 按交易所的分片内计数，读取时跨分片汇总
+	stale     map[string]int64
+	seqResets map[string]int64
+}
+
+// NewConcurrent 创建并发安全的订单簿缓存
+func NewConcurrent() *ConcurrentStore {
+	s := &ConcurrentStore{}
+	for i := range s.shards {
+		s.shards[i] = &shard{
+			books:     make(map[string]map[string]*model.BookEvent),
+			stale:     make(map[string]int64, 3),
+			seqResets: make(map[string]int64, 3),
+		}
+	}
+	return s
+}
+
+// shardFor 返回交易对所在分片
+func (s *ConcurrentStore) shardFor(symbolCanon string) *shard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(symbolCanon))
+	return s.shards[h.Sum32()%shardCount]
+}
+
+// Update 更新缓存（语义同 Store.Update）
+// 缓存保存 ev 指针本身，调用方写入后不应再修改 ev。
+// 返回: 是否已写入缓存
+func (s *ConcurrentStore) Update(ev *model.BookEvent) bool {
+	if ev == nil || ev.Exchange == "" || ev.SymbolCanon == "" {
+		return false
+	}
+	sh := s.shardFor(ev.SymbolCanon)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	books, ok := sh.books[ev.SymbolCanon]
+	if !ok {
+		books = make(map[string]*model.BookEvent, 3)
+		sh.books[ev.SymbolCanon] = books
+	}
+	switch checkSeq(books[ev.Exchange], ev) {
+	case seqStale:
+		sh.stale[ev.Exchange]++
+		return false
+	case seqReset:
+		sh.seqResets[ev.Exchange]++
+	}
+	books[ev.Exchange] = ev
+	return true
+}
+
+// Get 获取指定交易所与交易对的最新订单簿副本（可能为 nil）
+func (s *ConcurrentStore) Get(exchange, symbolCanon string) *model.BookEvent {
+	sh := s.shardFor(symbolCanon)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	if ev := sh.books[symbolCanon][exchange]; ev != nil {
+		return ev.Clone()
+	}
+	return nil
+}
+
+// GetPair 获取 Leader 与 Follower（Bittap）的订单簿副本（同一时刻一致）
+func (s *ConcurrentStore) GetPair(leader, symbolCanon string) (leaderBook, followerBook *model.BookEvent) {
+	snap := s.Snapshot(symbolCanon)
+	return snap.Get(leader), snap.Get(model.ExchangeBittap)
+}
+
+// Snapshot 获取交易对在各交易所的最新订单簿副本
+// 交易对无任何行情时 Books 为空（非 nil）。
+func (s *ConcurrentStore) Snapshot(symbolCanon string) Snapshot {
+	sh := s.shardFor(symbolCanon)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	books := sh.books[symbolCanon]
+	out := Snapshot{SymbolCanon: symbolCanon, Books: make(map[string]*model.BookEvent, len(books))}
+	for ex, ev := range books {
+		out.Books[ex] = ev.Clone()
+	}
+	return out
+}
+
+// Symbols 返回已有行情的交易对（升序）
+func (s *ConcurrentStore) Symbols() []string {
+	var out []string
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		for sym := range sh.books {
+			out = append(out, sym)
+		}
+		sh.mu.RUnlock()
+	}
+	sort.Strings(out)
+	return out
+}
+
+// StaleCount 获取指定交易所因 Seq 过期被丢弃的事件数
+func (s *ConcurrentStore) StaleCount(exchange string) int64 {
+	var n int64
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		n += sh.stale[exchange]
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+// SeqResetCount 获取指定交易所识别到的 Seq 重置次数
+func (s *ConcurrentStore) SeqResetCount(exchange string) int64 {
+	var n int64
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		n += sh.seqResets[exchange]
+		sh.mu.RUnlock()
+	}
+	return n
+}
@@ -0,0 +1,91 @@
+// Package store 并发订单簿缓存测试
+package store
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestConcurrentStore_SeqFilteringMatchesStore(t *testing.T) {
+	seqs := []int64{100, 101, 101, 99, 0, 3, 4}
+	s, cs := New(), NewConcurrent()
+	for _, seq := range seqs {
+		ev := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", Seq: seq}
+		if got, want := cs.Update(ev), s.Update(ev); got != want {
+			t.Fatalf("seq=%d Update=%v, want %v", seq, got, want)
+		}
+	}
+	if got, want := cs.Get(model.ExchangeOKX, "BTCUSDT").Seq, s.Get(model.ExchangeOKX, "BTCUSDT").Seq; got != want {
+		t.Fatalf("Seq=%d, want %d", got, want)
+	}
+	if cs.StaleCount(model.ExchangeOKX) != s.StaleCount(model.ExchangeOKX) ||
+		cs.SeqResetCount(model.ExchangeOKX) != s.SeqResetCount(model.ExchangeOKX) {
+		t.Fatalf("计数不一致: stale=%d/%d reset=%d/%d",
+			cs.StaleCount(model.ExchangeOKX), s.StaleCount(model.ExchangeOKX),
+			cs.SeqResetCount(model.ExchangeOKX), s.SeqResetCount(model.ExchangeOKX))
+	}
+}
+
+func TestConcurrentStore_SnapshotIsCopy(t *testing.T) {
+	cs := NewConcurrent()
+	cs.Update(&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100, Bids: []model.Level{{Price: 100, Qty: 1}}})
+	cs.Update(&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99})
+
+	snap := cs.Snapshot("BTCUSDT")
+	if len(snap.Books) != 2 || snap.Get(model.ExchangeOKX).BestBidPx != 100 {
+		t.Fatalf("Snapshot=%+v", snap)
+	}
+	snap.Get(model.ExchangeOKX).BestBidPx = 1
+	snap.Get(model.ExchangeOKX).Bids[0].Price = 1
+	if got := cs.Get(model.ExchangeOKX, "BTCUSDT"); got.BestBidPx != 100 || got.Bids[0].Price != 100 {
+		t.Fatalf("修改副本影响了缓存: %+v", got)
+	}
+
+	leader, follower := cs.GetPair(model.ExchangeOKX, "BTCUSDT")
+	if leader == nil || follower == nil || follower.BestBidPx != 99 {
+		t.Fatalf("GetPair=%+v/%+v", leader, follower)
+	}
+	if snap := cs.Snapshot("ETHUSDT"); snap.Books == nil || len(snap.Books) != 0 {
+		t.Fatalf("无行情交易对 Snapshot=%+v", snap)
+	}
+}
+
+func TestConcurrentStore_ConcurrentReadWrite(t *testing.T) {
+	cs := NewConcurrent()
+	symbols := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "XRPUSDT"}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := int64(1); i <= 1000; i++ {
+			for _, sym := range symbols {
+				cs.Update(&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: sym, Seq: i})
+			}
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				for _, sym := range symbols {
+					_ = cs.Snapshot(sym)
+				}
+				_ = cs.Symbols()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := cs.Symbols(); fmt.Sprint(got) != "[BTCUSDT ETHUSDT SOLUSDT XRPUSDT]" {
+		t.Fatalf("Symbols=%v", got)
+	}
+	for _, sym := range symbols {
+		if got := cs.Get(model.ExchangeBittap, sym).Seq; got != 1000 {
+			t.Fatalf("%s Seq=%d, want 1000", sym, got)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package store
+
+import "latency-arbitrage-validator/internal/core/model"
+
+// History 单个交易所/交易对最近 N 条订单簿（按到达时间递增的环形缓冲区）
+// 用于按时刻回查盘口（as-of join），如信号后 X 毫秒的价格（markout）与延迟成交。
+// 非并发安全，由所属的单写者组件独占使用。
+type History struct {
+	buf []*model.BookEvent
+	pos int
+	n   int
+}
+
+// NewHistory 创建容量为 size 的盘口历史（size<=0 时为 1）
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = 1
+	}
+	return &History{buf: make([]*model.BookEvent, size)}
+}
+
+// Add 追加一条盘口（与最新一条为同一事件时忽略）
+// 返回: 缓冲区已满时被挤出的最旧盘口（否则为 nil）
+func (h *History) Add(ev *model.BookEvent) (evicted *model.BookEvent) {
+	if ev == nil || (h.n > 0 && h.buf[(h.pos+len(h.buf)-1)%len(h.buf)] == ev) {
+		return nil
+	}
+	evicted = h.buf[h.pos]
+	h.buf[h.pos] = ev
+	h.pos = (h.pos + 1) % len(h.buf)
+	if h.n < len(h.buf) {
+		h.n++
+	}
+	return evicted
+}
+
+// AsOf 返回到达时间不晚于 tsNs 的最新盘口；历史中没有时返回 nil
+func (h *History) AsOf(tsNs int64) *model.BookEvent {
+	for i := 1; i <= h.n; i++ {
+		ev := h.buf[(h.pos+len(h.buf)-i)%len(h.buf)]
+		if ev.ArrivedAtUnixNs <= tsNs {
+			return ev
+		}
+	}
+	return nil
+}
+
+// Len 返回历史中的盘口数
+func (h *History) Len() int {
+	return h.n
+}
+
+// Events 返回历史盘口（旧 -> 新）；返回的指针应视为只读
+func (h *History) Events() []*model.BookEvent {
+	out := make([]*model.BookEvent, 0, h.n)
+	for i := h.n; i >= 1; i-- {
+		out = append(out, h.buf[(h.pos+len(h.buf)-i)%len(h.buf)])
+	}
+	return out
+}
@@ -1,30 +1,49 @@
 // Package store 维护所有交易所的最新订单簿状态。
-// 使用单写者模式避免锁和竞态条件。
+// 聚合器热路径使用单写者 Store 避免锁；需要跨 goroutine 读取时使用分片加锁的 ConcurrentStore。
 package store
 
 import "latency-arbitrage-validator/internal/core/model"
 
 // Store 最新订单簿缓存（单写者）
-// 注意：本结构体默认由聚合器单 goroutine 写入；若要跨 goroutine 读，请通过消息或拷贝传递快照。
+// 注意：本结构体默认由聚合器单 goroutine 写入；若要跨 goroutine 读，请使用 ConcurrentStore。
 type Store struct {
 	// books 按交易所、交易对缓存最新 BookEvent
 	// 第一层 key: exchange（okx/binance/bittap）
 	// 第二层 key: SymbolCanon（如 BTCUSDT）
 	books map[string]map[string]*model.BookEvent
+
+	// stale 按交易所统计因 Seq 不大于已缓存值而丢弃的事件数
+	stale map[string]int64
+	// seqResets 按交易所统计识别到的 Seq 重置次数
+	seqResets map[string]int64
+
+	// historySize 每个交易所/交易对保留的历史盘口数（EnableHistory 设置，0 表示不保留）
+	historySize int
+	// history 按交易所、交易对的历史盘口（key 结构同 books）
+	history map[string]map[string]*History
+
+	// onEvict 事件不再被缓存引用时的回调（SetOnEvict 设置，nil 表示不回调）
+	onEvict func(ev *model.BookEvent)
 }
 
 // New 创建新的订单簿缓存
 func New() *Store {
 	return &Store{
-		books: make(map[string]map[string]*model.BookEvent, 3),
+		books:     make(map[string]map[string]*model.BookEvent, 3),
+		stale:     make(map[string]int64, 3),
+		seqResets: make(map[string]int64, 3),
 	}
 }
 
 // Update 更新缓存
+// 同一交易所/交易对的 Seq 不大于已缓存值时视为重复或乱序事件（如重连后的旧推送），丢弃并计数；
+// 任一方 Seq<=0 时无法比较，直接接受。
+// Seq 回落到不足已缓存值一半时视为交易所侧序列重置，接受新事件。
 // 参数 ev: 归一化后的订单簿事件
-func (s *Store) Update(ev *model.BookEvent) {
+// 返回: 是否已写入缓存（false 表示无效或过期事件，调用方应跳过后续处理）
+func (s *Store) Update(ev *model.BookEvent) bool {
 	if ev == nil || ev.Exchange == "" || ev.SymbolCanon == "" {
-		return
+		return false
 	}
 
 	exBooks, ok := s.books[ev.Exchange]
@@ -32,7 +51,101 @@ func (s *Store) Update(ev *model.BookEvent) {
 		exBooks = make(map[string]*model.BookEvent)
 		s.books[ev.Exchange] = exBooks
 	}
+	switch checkSeq(exBooks[ev.SymbolCanon], ev) {
+	case seqStale:
+		s.stale[ev.Exchange]++
+		return false
+	case seqReset:
+		s.seqResets[ev.Exchange]++
+	}
+	prev := exBooks[ev.SymbolCanon]
 	exBooks[ev.SymbolCanon] = ev
+	if s.historySize > 0 {
+		// 被替换的盘口仍在历史中，待挤出环形缓冲区时才不再被引用
+		prev = s.historyFor(ev.Exchange, ev.SymbolCanon).Add(ev)
+	}
+	if s.onEvict != nil && prev != nil && prev != ev {
+		s.onEvict(prev)
+	}
+	return true
+}
+
+// SetOnEvict 设置事件不再被缓存引用（被新事件替换或挤出历史）时的回调，如归还对象池
+// 被 Update 拒绝的事件从未进入缓存，不触发回调，由调用方自行处理。
+func (s *Store) SetOnEvict(fn func(ev *model.BookEvent)) {
+	s.onEvict = fn
+}
+
+// EnableHistory 保留每个交易所/交易对最近 n 条已接受的盘口，供 AsOf 按时刻回查
+// 需在开始 Update 之前调用；n<=0 时不启用。
+func (s *Store) EnableHistory(n int) {
+	if n <= 0 {
+		return
+	}
+	s.historySize = n
+	s.history = make(map[string]map[string]*History, 3)
+}
+
+// historyFor 返回（必要时创建）交易所/交易对的历史盘口
+func (s *Store) historyFor(exchange, symbolCanon string) *History {
+	exHist, ok := s.history[exchange]
+	if !ok {
+		exHist = make(map[string]*History)
+		s.history[exchange] = exHist
+	}
+	h := exHist[symbolCanon]
+	if h == nil {
+		h = NewHistory(s.historySize)
+		exHist[symbolCanon] = h
+	}
+	return h
+}
+
+// AsOf 获取到达时间不晚于 tsNs 的最新盘口（需 EnableHistory）
+// 未启用历史、无历史或 tsNs 早于保留的最旧盘口时返回 nil；返回的指针应视为只读。
+func (s *Store) AsOf(exchange, symbolCanon string, tsNs int64) *model.BookEvent {
+	if h := s.history[exchange][symbolCanon]; h != nil {
+		return h.AsOf(tsNs)
+	}
+	return nil
+}
+
+// History 获取交易所/交易对保留的历史盘口（旧 -> 新，需 EnableHistory）
+func (s *Store) History(exchange, symbolCanon string) []*model.BookEvent {
+	if h := s.history[exchange][symbolCanon]; h != nil {
+		return h.Events()
+	}
+	return nil
+}
+
+// seqVerdict 新事件相对已缓存事件的 Seq 判定
+type seqVerdict int
+
+const (
+	seqAccept seqVerdict = iota
+	seqStale
+	seqReset
+)
+
+// checkSeq 判定新事件是否过期（Store 与 ConcurrentStore 共用同一规则）
+func checkSeq(prev, ev *model.BookEvent) seqVerdict {
+	if prev == nil || prev.Seq <= 0 || ev.Seq <= 0 || ev.Seq > prev.Seq {
+		return seqAccept
+	}
+	if ev.Seq >= prev.Seq/2 {
+		return seqStale
+	}
+	return seqReset
+}
+
+// StaleCount 获取指定交易所因 Seq 过期被丢弃的事件数
+func (s *Store) StaleCount(exchange string) int64 {
+	return s.stale[exchange]
+}
+
+// SeqResetCount 获取指定交易所识别到的 Seq 重置次数
+func (s *Store) SeqResetCount(exchange string) int64 {
+	return s.seqResets[exchange]
 }
 
 // Get 获取指定交易所与交易对的最新订单簿
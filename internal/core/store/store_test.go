@@ -0,0 +1,135 @@
+// Package store 订单簿缓存测试
+package store
+
+import (
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestStore_UpdateSeqFiltering(t *testing.T) {
+	tests := []struct {
+		name      string
+		seqs      []int64
+		wantSeq   int64
+		wantStale int64
+		wantReset int64
+	}{
+		{"递增", []int64{100, 101, 105}, 105, 0, 0},
+		{"重复", []int64{100, 100}, 100, 1, 0},
+		{"乱序", []int64{100, 102, 101}, 102, 1, 0},
+		{"无序列号", []int64{100, 0, 0}, 0, 0, 0},
+		{"序列重置", []int64{1000, 3}, 3, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			for _, seq := range tt.seqs {
+				s.Update(&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", Seq: seq})
+			}
+			if got := s.Get(model.ExchangeOKX, "BTCUSDT").Seq; got != tt.wantSeq {
+				t.Errorf("缓存 Seq=%d, want %d", got, tt.wantSeq)
+			}
+			if got := s.StaleCount(model.ExchangeOKX); got != tt.wantStale {
+				t.Errorf("StaleCount=%d, want %d", got, tt.wantStale)
+			}
+			if got := s.SeqResetCount(model.ExchangeOKX); got != tt.wantReset {
+				t.Errorf("SeqResetCount=%d, want %d", got, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestStore_UpdateIndependentKeys(t *testing.T) {
+	s := New()
+	if !s.Update(&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", Seq: 10}) {
+		t.Fatalf("首个事件应被接受")
+	}
+	// 不同交易对、不同交易所的 Seq 互不影响
+	if !s.Update(&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "ETHUSDT", Seq: 5}) {
+		t.Fatalf("其它交易对的事件应被接受")
+	}
+	if !s.Update(&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", Seq: 5}) {
+		t.Fatalf("其它交易所的事件应被接受")
+	}
+	if s.Update(&model.BookEvent{Exchange: "", SymbolCanon: "BTCUSDT"}) {
+		t.Fatalf("无效事件不应被接受")
+	}
+}
+
+func TestStore_HistoryAsOf(t *testing.T) {
+	s := New()
+	s.EnableHistory(3)
+	for i, ts := range []int64{10, 20, 30, 40} {
+		s.Update(&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", Seq: int64(i + 1), BestBidPx: float64(ts), ArrivedAtUnixNs: ts})
+	}
+	// 过期事件不进入历史
+	s.Update(&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", Seq: 3, ArrivedAtUnixNs: 50})
+
+	tests := []struct {
+		tsNs    int64
+		wantBid float64
+	}{
+		{45, 40},
+		{35, 30},
+		{20, 20},
+		{15, 0}, // 早于保留的最旧盘口（10 已被挤出）
+	}
+	for _, tt := range tests {
+		got := s.AsOf(model.ExchangeBittap, "BTCUSDT", tt.tsNs)
+		if tt.wantBid == 0 {
+			if got != nil {
+				t.Fatalf("AsOf(%d)=%+v, want nil", tt.tsNs, got)
+			}
+			continue
+		}
+		if got == nil || got.BestBidPx != tt.wantBid {
+			t.Fatalf("AsOf(%d)=%+v, want bid %v", tt.tsNs, got, tt.wantBid)
+		}
+	}
+	if h := s.History(model.ExchangeBittap, "BTCUSDT"); len(h) != 3 || h[0].ArrivedAtUnixNs != 20 || h[2].ArrivedAtUnixNs != 40 {
+		t.Fatalf("History=%v", h)
+	}
+	if got := s.AsOf(model.ExchangeOKX, "BTCUSDT", 100); got != nil {
+		t.Fatalf("无历史交易所 AsOf=%+v, want nil", got)
+	}
+
+	// 未启用时不保留历史
+	plain := New()
+	plain.Update(&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1})
+	if got := plain.AsOf(model.ExchangeOKX, "BTCUSDT", 10); got != nil {
+		t.Fatalf("未启用 AsOf=%+v, want nil", got)
+	}
+}
+
+func TestStore_OnEvict(t *testing.T) {
+	var evicted []int64
+	onEvict := func(ev *model.BookEvent) { evicted = append(evicted, ev.Seq) }
+	ev := func(seq int64) *model.BookEvent {
+		return &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", Seq: seq, ArrivedAtUnixNs: seq}
+	}
+
+	// 未启用历史：被替换即不再引用；被拒绝的事件与重复写入的同一事件不回调
+	s := New()
+	s.SetOnEvict(onEvict)
+	first := ev(1)
+	s.Update(first)
+	s.Update(ev(2))
+	s.Update(ev(2))
+	s.Update(ev(3))
+	if len(evicted) != 2 || evicted[0] != 1 || evicted[1] != 2 {
+		t.Fatalf("evicted=%v, want [1 2]", evicted)
+	}
+
+	// 启用历史：挤出环形缓冲区时才回调
+	evicted = nil
+	h := New()
+	h.EnableHistory(2)
+	h.SetOnEvict(onEvict)
+	for seq := int64(1); seq <= 4; seq++ {
+		h.Update(ev(seq))
+	}
+	if len(evicted) != 2 || evicted[0] != 1 || evicted[1] != 2 {
+		t.Fatalf("history evicted=%v, want [1 2]", evicted)
+	}
+}
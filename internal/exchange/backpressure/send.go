@@ -0,0 +1,87 @@
+// Package backpressure 实现订单簿通道已满时的投递策略。
+// 三家交易所客户端共用，策略由 ws.<exchange>.backpressure 配置。
+package backpressure
+
+import (
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// Sender 按配置策略向订单簿通道投递事件
+// 仅由单个读循环调用（通道的唯一生产者）。
+type Sender struct {
+	ch           chan *model.BookEvent
+	policy       string
+	blockTimeout time.Duration
+}
+
+// NewSender 创建投递器
+// 参数 ch: 订单簿事件通道
+// 参数 cfg: 交易所 WS 配置（读取 Backpressure/BlockTimeoutMs）
+func NewSender(ch chan *model.BookEvent, cfg *config.ExchangeWSConfig) *Sender {
+	policy := cfg.Backpressure
+	if policy == "" {
+		policy = config.BackpressureDropOldest
+	}
+	s := &Sender{
+		ch:           ch,
+		policy:       policy,
+		blockTimeout: time.Duration(cfg.BlockTimeoutMs) * time.Millisecond,
+	}
+	if s.blockTimeout <= 0 {
+		s.blockTimeout = 50 * time.Millisecond
+	}
+	return s
+}
+
+// Policy 获取生效的背压策略
+func (s *Sender) Policy() string {
+	return s.policy
+}
+
+// Send 投递事件
+// 被丢弃的事件（含出队的最旧事件）未送达任何消费者，直接归还对象池。
+// 返回: 因通道已满被丢弃的事件数
+func (s *Sender) Send(ev *model.BookEvent) int {
+	select {
+	case s.ch <- ev:
+		return 0
+	default:
+	}
+
+	switch s.policy {
+	case config.BackpressureDropOldest:
+		// 丢弃队首最旧事件后重试（消费者可能已同时取走一条，此时无需丢弃）
+		dropped := 0
+		select {
+		case old := <-s.ch:
+			old.Release()
+			dropped++
+		default:
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			ev.Release()
+			dropped++
+		}
+		return dropped
+
+	case config.BackpressureBlock:
+		timer := time.NewTimer(s.blockTimeout)
+		defer timer.Stop()
+		select {
+		case s.ch <- ev:
+			return 0
+		case <-timer.C:
+			ev.Release()
+			return 1
+		}
+
+	default:
+		ev.Release()
+		return 1
+	}
+}
@@ -0,0 +1,64 @@
+// Package backpressure 背压策略测试
+package backpressure
+
+import (
+	"testing"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func fill(ch chan *model.BookEvent) {
+	for seq := int64(1); len(ch) < cap(ch); seq++ {
+		ch <- &model.BookEvent{Seq: seq}
+	}
+}
+
+func TestSender_Policies(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		wantDropped int
+		// wantSeqs 投递后通道内事件的 Seq 顺序
+		wantSeqs []int64
+	}{
+		{"丢弃最新", config.BackpressureDropNewest, 1, []int64{1, 2}},
+		{"丢弃最旧", config.BackpressureDropOldest, 1, []int64{2, 99}},
+		{"阻塞超时", config.BackpressureBlock, 1, []int64{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan *model.BookEvent, 2)
+			fill(ch)
+			s := NewSender(ch, &config.ExchangeWSConfig{Backpressure: tt.policy, BlockTimeoutMs: 1})
+
+			if got := s.Send(&model.BookEvent{Seq: 99}); got != tt.wantDropped {
+				t.Fatalf("dropped=%d, want %d", got, tt.wantDropped)
+			}
+			close(ch)
+			var seqs []int64
+			for ev := range ch {
+				seqs = append(seqs, ev.Seq)
+			}
+			if len(seqs) != len(tt.wantSeqs) {
+				t.Fatalf("通道内事件 %v, want %v", seqs, tt.wantSeqs)
+			}
+			for i := range seqs {
+				if seqs[i] != tt.wantSeqs[i] {
+					t.Fatalf("通道内事件 %v, want %v", seqs, tt.wantSeqs)
+				}
+			}
+		})
+	}
+}
+
+func TestSender_NotFull(t *testing.T) {
+	ch := make(chan *model.BookEvent, 1)
+	s := NewSender(ch, &config.ExchangeWSConfig{})
+	if s.Policy() != config.BackpressureDropOldest {
+		t.Fatalf("默认策略=%s, want drop_oldest", s.Policy())
+	}
+	if got := s.Send(&model.BookEvent{}); got != 0 {
+		t.Fatalf("通道未满时不应丢弃, dropped=%d", got)
+	}
+}
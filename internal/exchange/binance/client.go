@@ -1,18 +1,16 @@
 // Package binance 实现 Binance 交易所的 WebSocket 客户端。
 // 连接地址: wss://fstream.binance.com/ws
-// 订阅频道: depth5@100ms
+// 订阅频道: depth<N>@<M>ms，N 为 depth_levels，M 为 depth_update_ms（diff_book 模式下为 depth@<M>ms 增量流 + REST 快照）
 // 心跳机制: 协议层 ping/pong
+// 降级: WS 长时间断线时轮询 REST /fapi/v1/depth
 package binance
 
 import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
-	"sync"
 	"sync/atomic"
-	"time"
 
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
@@ -20,49 +18,28 @@ import (
 	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/core/model"
 	"latency-arbitrage-validator/internal/metadata"
-	"latency-arbitrage-validator/internal/util/backoff"
-	"latency-arbitrage-validator/internal/util/timeutil"
+	"latency-arbitrage-validator/internal/output/rawcapture"
+	"latency-arbitrage-validator/internal/ws"
 )
 
 // Client Binance WebSocket 客户端
+// 连接、心跳、看门狗与重连由 ws.Manager 负责，本类型只提供 Binance 协议差异与增量深度同步。
 type Client struct {
 	// cfg WebSocket 配置
 	cfg *config.ExchangeWSConfig
-	// symbolMaps Symbol 映射表（key 为 Canon）
-	symbolMaps map[string]*metadata.SymbolMap
 	// logger 日志记录器
 	logger *zap.Logger
 	// parser 消息解析器
 	parser *Parser
+	// depth 增量深度本地订单簿同步器（仅 diff_book 模式，否则为 nil）
+	depth *depthSync
+	// ws 连接管理器
+	ws *ws.Manager
 
-	// conn WebSocket 连接
-	conn *websocket.Conn
-	// connMu 连接锁
-	connMu sync.Mutex
-
-	// bookCh 订单簿事件输出通道
-	bookCh chan *model.BookEvent
-	// errCh 错误输出通道
-	errCh chan error
-
-	// metrics 连接指标
-	metrics ConnectionMetrics
-	// metricsMu 指标锁
-	metricsMu sync.RWMutex
-
-	// lastMsgTime 最后消息时间（纳秒）
-	lastMsgTime int64
-	// updateCount 更新计数（用于计算 QPS）
-	updateCount int64
-	// backoff 重连退避
-	backoff *backoff.Backoff
-	// closed 是否已关闭
-	closed int32
-
-	// parseErrSampleCount 解析错误计数（用于采样日志）
-	parseErrSampleCount uint64
-	// lastParseErrLogNs 上次解析错误日志时间（纳秒）
-	lastParseErrLogNs int64
+	// bookResyncs 本地订单簿重新同步次数
+	bookResyncs int64
+	// pruneBooks 退订后置 1，读循环据此清理已退订交易对的本地订单簿
+	pruneBooks int32
 }
 
 // NewClient 创建 Binance WebSocket 客户端
@@ -70,284 +47,169 @@ type Client struct {
 // 参数 symbolMaps: Symbol 映射表（key 为 Canon）
 // 参数 logger: 日志记录器
 func NewClient(cfg *config.ExchangeWSConfig, symbolMaps map[string]*metadata.SymbolMap, logger *zap.Logger) *Client {
-	return &Client{
-		cfg:        cfg,
-		symbolMaps: symbolMaps,
-		logger:     logger.Named("binance"),
-		parser:     NewParser(symbolMaps),
-		bookCh:     make(chan *model.BookEvent, 1000),
-		errCh:      make(chan error, 10),
-		backoff:    backoff.NewDefault(),
+	c := &Client{
+		cfg:    cfg,
+		logger: logger.Named("binance"),
+		parser: NewParser(symbolMaps),
 	}
-}
-
-// Connect 建立 WebSocket 连接
-// 参数 ctx: 上下文，用于取消连接
-func (c *Client) Connect(ctx context.Context) error {
-	c.connMu.Lock()
-	defer c.connMu.Unlock()
-
-	header := http.Header{}
-	header.Set("User-Agent", "latency-arbitrage-validator/1.0")
-	header.Set("Origin", "https://www.binance.com")
-
-	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
-	conn, _, err := dialer.DialContext(ctx, c.cfg.URL, header)
-	if err != nil {
-		return fmt.Errorf("连接 Binance WebSocket 失败: %w", err)
+	if cfg.DepthLevels > 0 {
+		c.parser.depth = cfg.DepthLevels
 	}
-
-	readTimeout := time.Duration(c.readTimeoutMs()) * time.Millisecond
-	if readTimeout > 0 {
-		_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
-		conn.SetPongHandler(func(string) error {
-			atomic.StoreInt64(&c.lastMsgTime, timeutil.NowNano())
-			return conn.SetReadDeadline(time.Now().Add(readTimeout))
-		})
+	if cfg.DiffBook {
+		c.depth = newDepthSync(c.parser, newHTTPSnapshotFunc(cfg.SnapshotURL, cfg.SnapshotLimit), c.parser.depth)
 	}
 
-	c.conn = conn
-	c.backoff.Reset()
-	c.logger.Info("Binance WebSocket 连接成功", zap.String("url", c.cfg.URL))
-	return nil
-}
-
-// Subscribe 订阅交易对
-// 订阅 depth5@100ms 行情流
-func (c *Client) Subscribe() error {
-	c.connMu.Lock()
-	defer c.connMu.Unlock()
-
-	if c.conn == nil {
-		return fmt.Errorf("WebSocket 未连接")
+	pingIntervalMs := cfg.PingIntervalMs
+	if pingIntervalMs <= 0 {
+		pingIntervalMs = c.readTimeoutMs() / 2
+		if pingIntervalMs <= 0 {
+			pingIntervalMs = 15000
+		}
 	}
-
-	params := make([]string, 0, len(c.symbolMaps))
-	for _, m := range c.symbolMaps {
+	spec := ws.Spec{
+		Name:           "Binance",
+		Exchange:       model.ExchangeBinance,
+		Origin:         "https://www.binance.com",
+		ReadTimeoutMs:  c.readTimeoutMs(),
+		PingIntervalMs: pingIntervalMs,
+		PingFrame: func(uint64) (int, []byte, error) {
+			return websocket.PingMessage, []byte("ping"), nil
+		},
+		Symbols:        c.parser.symbols.Canons,
+		SubscribeFrame: c.subscribeFrame,
+		ParseAck:       ParseAck,
+		Handle:         c.handle,
+	}
+	if c.depth != nil {
+		// 重连后增量流不再连续，本地订单簿需重新快照同步
+		spec.OnReconnect = c.depth.resetAll
+	}
+	if cfg.RestFallbackURL != "" {
+		spec.PollBook = c.newRESTPoller(cfg.RestFallbackURL)
+	}
+	c.ws = ws.New(cfg, spec, c.logger)
+	return c
+}
+
+// subscribeFrame 构建一帧 depth<N>@<M>ms（diff_book 模式为 depth@<M>ms）订阅请求
+// 参数 id: 请求 ID（响应中原样返回）
+// 参数 canons: 本帧订阅的统一交易对
+func (c *Client) subscribeFrame(id int64, canons []string) ([]byte, error) {
+	return c.buildFrame("SUBSCRIBE", id, c.parser.symbols.Load(), canons)
+}
+
+// buildFrame 构建一帧 SUBSCRIBE/UNSUBSCRIBE 请求
+// 参数 method: SUBSCRIBE 或 UNSUBSCRIBE
+// 参数 maps: 交易对映射（key 为 Canon）
+func (c *Client) buildFrame(method string, id int64, maps map[string]*metadata.SymbolMap, canons []string) ([]byte, error) {
+	stream := fmt.Sprintf("depth%d%s", c.parser.depth, c.updateSpeedSuffix())
+	if c.depth != nil {
+		stream = "depth" + c.updateSpeedSuffix()
+	}
+	params := make([]string, 0, len(canons))
+	for _, canon := range canons {
 		// Binance 订阅参数要求小写 symbol
-		params = append(params, fmt.Sprintf("%s@depth5@100ms", strings.ToLower(m.BinanceSym)))
-	}
-
-	req := SubscribeRequest{
-		Method: "SUBSCRIBE",
-		Params: params,
-		ID:     1,
-	}
-
-	data, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("序列化订阅请求失败: %w", err)
+		params = append(params, fmt.Sprintf("%s@%s", strings.ToLower(maps[canon].BinanceSym), stream))
 	}
-	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		return fmt.Errorf("发送订阅请求失败: %w", err)
-	}
-
-	c.logger.Info("Binance 订阅请求已发送", zap.Int("symbols", len(params)))
-	return nil
-}
-
-// Run 启动客户端主循环
-// 包含读取循环和指标统计
-func (c *Client) Run(ctx context.Context) {
-	go c.pingLoop(ctx)
-	go c.metricsLoop(ctx)
-	c.readLoop(ctx)
+	return json.Marshal(SubscribeRequest{Method: method, Params: params, ID: id})
 }
 
-func (c *Client) readLoop(ctx context.Context) {
-	readTimeout := time.Duration(c.readTimeoutMs()) * time.Millisecond
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-
-		if atomic.LoadInt32(&c.closed) == 1 {
-			return
-		}
-
-		c.connMu.Lock()
-		conn := c.conn
-		c.connMu.Unlock()
-
-		if conn == nil {
-			c.reconnect(ctx)
-			continue
-		}
-
-		_, data, err := conn.ReadMessage()
-		if err != nil {
-			c.logger.Warn("读取 Binance 消息失败", zap.Error(err))
-			c.incrementReconnectCount()
-			c.reconnect(ctx)
-			continue
-		}
-
-		if readTimeout > 0 {
-			_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
-		}
-
-		atomic.StoreInt64(&c.lastMsgTime, timeutil.NowNano())
-
-		events, err := c.parser.Parse(data)
-		if err != nil {
-			c.incrementParseErrorCount()
-			c.maybeLogParseError(err, data)
-			continue
-		}
-
-		for _, event := range events {
-			atomic.AddInt64(&c.updateCount, 1)
-			select {
-			case c.bookCh <- event:
-			default:
-				c.logger.Warn("Binance bookCh 已满，丢弃事件")
-			}
-		}
+// updateSpeedSuffix 深度流推送间隔后缀（250ms 为 Binance 默认间隔，流名不带后缀；未配置按 100ms）
+func (c *Client) updateSpeedSuffix() string {
+	switch ms := c.cfg.DepthUpdateMs; ms {
+	case 0:
+		return "@100ms"
+	case 250:
+		return ""
+	default:
+		return fmt.Sprintf("@%dms", ms)
 	}
 }
 
-func (c *Client) pingLoop(ctx context.Context) {
-	intervalMs := c.cfg.PingIntervalMs
-	if intervalMs <= 0 {
-		intervalMs = c.readTimeoutMs() / 2
-		if intervalMs <= 0 {
-			intervalMs = 15000
-		}
+// handle 解析一条 Binance 消息（diff_book 模式经本地订单簿同步）
+func (c *Client) handle(ctx context.Context, data []byte, nowNs int64) ([]*model.BookEvent, error) {
+	if c.depth == nil {
+		return c.parser.Parse(data, nowNs)
 	}
-
-	ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if atomic.LoadInt32(&c.closed) == 1 {
-				return
-			}
-
-			c.connMu.Lock()
-			conn := c.conn
-			if conn == nil {
-				c.connMu.Unlock()
-				continue
-			}
-
-			deadline := time.Now().Add(5 * time.Second)
-			if err := conn.WriteControl(websocket.PingMessage, []byte("ping"), deadline); err != nil {
-				c.connMu.Unlock()
-				c.logger.Warn("发送 Binance ping 失败", zap.Error(err))
-				continue
-			}
-			c.connMu.Unlock()
-		}
+	// 本地订单簿仅由读循环修改，退订后在此清理
+	if atomic.CompareAndSwapInt32(&c.pruneBooks, 1, 0) {
+		c.depth.prune()
 	}
-}
-
-func (c *Client) metricsLoop(ctx context.Context) {
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-
-	var lastCount int64
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if atomic.LoadInt32(&c.closed) == 1 {
-				return
-			}
-
-			count := atomic.LoadInt64(&c.updateCount)
-			qps := float64(count - lastCount)
-			lastCount = count
-
-			lastMsg := atomic.LoadInt64(&c.lastMsgTime)
-			var ageMs int64
-			if lastMsg > 0 {
-				ageMs = (timeutil.NowNano() - lastMsg) / 1_000_000
-			}
-
-			c.metricsMu.Lock()
-			c.metrics.UpdatesPerSec = qps
-			c.metrics.LastMessageAgeMs = ageMs
-			c.metricsMu.Unlock()
-		}
+	c.incrementBookResyncs(c.depth.drainSnapshots())
+	events, resync, err := c.depth.handle(ctx, data, nowNs)
+	if resync {
+		c.incrementBookResyncs(1)
 	}
+	return events, err
 }
 
-func (c *Client) reconnect(ctx context.Context) {
-	c.closeConn()
+// Connect 建立 WebSocket 连接
+// 参数 ctx: 上下文，用于取消连接
+func (c *Client) Connect(ctx context.Context) error {
+	return c.ws.Connect(ctx)
+}
 
-	delay := c.backoff.Next()
-	c.logger.Info("Binance 准备重连", zap.Duration("delay", delay))
+// Subscribe 订阅交易对
+// 订阅 depth<N>@<M>ms 行情流（diff_book 模式订阅 depth@<M>ms 增量流）
+func (c *Client) Subscribe() error {
+	return c.ws.Subscribe()
+}
 
-	select {
-	case <-ctx.Done():
-		return
-	case <-time.After(delay):
-	}
-
-	if err := c.Connect(ctx); err != nil {
-		c.logger.Error("Binance 重连失败", zap.Error(err))
-		return
-	}
-	if err := c.Subscribe(); err != nil {
-		c.logger.Error("Binance 重新订阅失败", zap.Error(err))
+// Unsubscribe 运行期退订交易对
+// 先从映射表移除（解析器随即丢弃其行情，重连后不再订阅），再发送 UNSUBSCRIBE 请求。
+// 参数 canons: 退订的统一交易对（未订阅的忽略）
+func (c *Client) Unsubscribe(canons []string) error {
+	removed := c.parser.symbols.Remove(canons)
+	if len(removed) == 0 {
+		return nil
 	}
+	atomic.StoreInt32(&c.pruneBooks, 1)
+	return c.ws.Unsubscribe(metadata.SortedCanons(removed), func(id int64, chunk []string) ([]byte, error) {
+		return c.buildFrame("UNSUBSCRIBE", id, removed, chunk)
+	})
 }
 
-func (c *Client) closeConn() {
-	c.connMu.Lock()
-	defer c.connMu.Unlock()
-
-	if c.conn != nil {
-		_ = c.conn.Close()
-		c.conn = nil
-	}
+// Run 启动客户端主循环
+// 包含读取循环、心跳循环、指标统计与看门狗
+func (c *Client) Run(ctx context.Context) {
+	c.ws.Run(ctx)
 }
 
 // Close 关闭客户端
 func (c *Client) Close() error {
-	atomic.StoreInt32(&c.closed, 1)
-	c.closeConn()
-	close(c.bookCh)
-	close(c.errCh)
-	c.logger.Info("Binance 客户端已关闭")
-	return nil
+	return c.ws.Close()
+}
+
+// SetRawCapture 设置原始帧采样录制器（需在 Connect 之前调用）
+func (c *Client) SetRawCapture(r *rawcapture.Recorder) {
+	c.ws.SetRawCapture(r)
 }
 
 // BookCh 获取订单簿事件通道
 func (c *Client) BookCh() <-chan *model.BookEvent {
-	return c.bookCh
+	return c.ws.BookCh()
 }
 
-// ErrCh 获取错误通道
+// ErrCh 获取错误通道（元素为 *connerr.Error；通道满时丢弃）
 func (c *Client) ErrCh() <-chan error {
-	return c.errCh
+	return c.ws.ErrCh()
 }
 
 // Metrics 获取连接指标
 func (c *Client) Metrics() ConnectionMetrics {
-	c.metricsMu.RLock()
-	defer c.metricsMu.RUnlock()
-	return c.metrics
-}
-
-func (c *Client) incrementReconnectCount() {
-	c.metricsMu.Lock()
-	c.metrics.ReconnectCount++
-	c.metricsMu.Unlock()
+	return ConnectionMetrics{
+		Metrics:     c.ws.Metrics(),
+		BookResyncs: atomic.LoadInt64(&c.bookResyncs),
+	}
 }
 
-func (c *Client) incrementParseErrorCount() {
-	c.metricsMu.Lock()
-	c.metrics.ParseErrorCount++
-	c.metricsMu.Unlock()
+// incrementBookResyncs 增加本地订单簿重新同步计数
+func (c *Client) incrementBookResyncs(n int) {
+	if n <= 0 {
+		return
+	}
+	atomic.AddInt64(&c.bookResyncs, int64(n))
+	c.logger.Warn("Binance 增量深度断档，重新同步本地订单簿", zap.Int("symbols", n))
 }
 
 func (c *Client) readTimeoutMs() int {
@@ -357,25 +219,3 @@ func (c *Client) readTimeoutMs() int {
 	// 未配置时使用 30s
 	return 30000
 }
-
-// maybeLogParseError 采样记录解析错误原始消息，避免刷盘
-// 采样策略：每 100 次错误记录 1 条，且同一类日志至少间隔 1 分钟。
-func (c *Client) maybeLogParseError(err error, data []byte) {
-	count := atomic.AddUint64(&c.parseErrSampleCount, 1)
-	if count%100 != 0 {
-		return
-	}
-
-	nowNs := timeutil.NowNano()
-	last := atomic.LoadInt64(&c.lastParseErrLogNs)
-	if last > 0 && nowNs-last < int64(time.Minute) {
-		return
-	}
-	atomic.StoreInt64(&c.lastParseErrLogNs, nowNs)
-
-	sample := data
-	if len(sample) > 200 {
-		sample = sample[:200]
-	}
-	c.logger.Warn("解析 Binance 消息失败（采样）", zap.Error(err), zap.ByteString("data", sample))
-}
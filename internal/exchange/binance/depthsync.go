@@ -0,0 +1,189 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// snapshotRetryNs 快照拉取失败或无法衔接后的重试间隔（纳秒）
+const snapshotRetryNs = int64(time.Second)
+
+// snapshotFunc 拉取指定交易对深度快照的函数（便于测试替换）
+type snapshotFunc func(ctx context.Context, symbol string) (*DepthSnapshot, error)
+
+type snapshotResult struct {
+	canon string
+	gen   uint64
+	snap  *DepthSnapshot
+	err   error
+}
+
+// depthSync 增量深度流本地订单簿同步器（diff_book 模式）
+// 本地订单簿仅由读循环修改；快照在独立 goroutine 中拉取，结果经 results 通道交回读循环，
+// 避免在读循环内做阻塞 REST 请求。
+type depthSync struct {
+	parser *Parser
+	fetch  snapshotFunc
+	// depth 输出 BookEvent 的单边档位数
+	depth int
+
+	books   map[string]*localBook
+	pending map[string]bool
+	// retryAt 快照失败后允许再次拉取的时间（纳秒）
+	retryAt map[string]int64
+	results chan snapshotResult
+	// gen 同步代次（重连后递增，丢弃旧代次的快照结果）
+	gen uint64
+}
+
+// newDepthSync 创建本地订单簿同步器
+// 参数 fetch: 快照拉取函数
+// 参数 depth: 输出单边档位数
+func newDepthSync(parser *Parser, fetch snapshotFunc, depth int) *depthSync {
+	return &depthSync{
+		parser:  parser,
+		fetch:   fetch,
+		depth:   depth,
+		books:   make(map[string]*localBook),
+		pending: make(map[string]bool),
+		retryAt: make(map[string]int64),
+		results: make(chan snapshotResult, 64),
+	}
+}
+
+// resetAll 重置全部本地订单簿（重连后增量流不再连续）
+func (d *depthSync) resetAll() {
+	d.gen++
+	for canon, b := range d.books {
+		b.reset()
+		d.pending[canon] = false
+		d.retryAt[canon] = 0
+	}
+}
+
+// prune 清理已不在映射表中（已退订）的交易对的本地订单簿
+func (d *depthSync) prune() {
+	for canon := range d.books {
+		if _, ok := d.parser.symbols.Get(canon); ok {
+			continue
+		}
+		delete(d.books, canon)
+		delete(d.pending, canon)
+		delete(d.retryAt, canon)
+	}
+}
+
+// drainSnapshots 应用已到达的快照结果（非阻塞）
+// 返回: 快照与缓存推送无法衔接的交易对数（需重新同步）
+func (d *depthSync) drainSnapshots() int {
+	resyncs := 0
+	for {
+		select {
+		case r := <-d.results:
+			if r.gen != d.gen || d.books[r.canon] == nil {
+				continue
+			}
+			d.pending[r.canon] = false
+			if r.err != nil {
+				d.retryAt[r.canon] = timeutil.NowNano() + snapshotRetryNs
+				continue
+			}
+			if err := d.books[r.canon].applySnapshot(r.snap); err != nil {
+				// 限制重拉频率，避免持续断档时占满 REST 权重
+				d.retryAt[r.canon] = timeutil.NowNano() + snapshotRetryNs
+				resyncs++
+			}
+		default:
+			return resyncs
+		}
+	}
+}
+
+// handle 处理一条增量深度推送
+// 返回: 同步完成后的 BookEvent（同步中返回 nil）；resync 表示检测到断档并已触发重新同步
+func (d *depthSync) handle(ctx context.Context, data []byte, arrivedAt int64) (events []*model.BookEvent, resync bool, err error) {
+	u, canon, err := d.parser.decodeDepth(data)
+	if err != nil || u == nil {
+		return nil, false, err
+	}
+
+	b := d.books[canon]
+	if b == nil {
+		b = &localBook{}
+		d.books[canon] = b
+	}
+
+	if b.synced {
+		if err := b.applyUpdate(u); err == nil {
+			return []*model.BookEvent{b.event(canon, d.depth, arrivedAt, u.EventTimeMs)}, false, nil
+		}
+		b.reset()
+		resync = true
+	}
+
+	b.bufferUpdate(u)
+	d.requestSnapshot(ctx, canon, arrivedAt)
+	return nil, resync, nil
+}
+
+// requestSnapshot 异步拉取快照（同一交易对同时最多一个请求）
+func (d *depthSync) requestSnapshot(ctx context.Context, canon string, nowNs int64) {
+	if d.pending[canon] || nowNs < d.retryAt[canon] {
+		return
+	}
+	d.pending[canon] = true
+	gen := d.gen
+	go func() {
+		snap, err := d.fetch(ctx, canon)
+		select {
+		case d.results <- snapshotResult{canon: canon, gen: gen, snap: snap, err: err}:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// newHTTPSnapshotFunc 创建基于 REST /fapi/v1/depth 的快照拉取函数（公共行情接口，无需签名）
+// 参数 baseURL: 快照接口地址
+// 参数 limit: 档位数
+func newHTTPSnapshotFunc(baseURL string, limit int) snapshotFunc {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(ctx context.Context, symbol string) (*DepthSnapshot, error) {
+		q := url.Values{}
+		q.Set("symbol", strings.ToUpper(symbol))
+		q.Set("limit", strconv.Itoa(limit))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"?"+q.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("创建快照请求失败: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("请求 Binance 深度快照失败: %w", err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("读取 Binance 深度快照失败: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Binance 深度快照 HTTP 状态码: %d", resp.StatusCode)
+		}
+
+		var snap DepthSnapshot
+		if err := json.Unmarshal(body, &snap); err != nil {
+			return nil, fmt.Errorf("解析 Binance 深度快照失败: %w", err)
+		}
+		return &snap, nil
+	}
+}
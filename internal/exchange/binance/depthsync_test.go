@@ -0,0 +1,138 @@
+// Package binance 增量深度本地订单簿测试
+package binance
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"latency-arbitrage-validator/internal/util/fastparse"
+)
+
+func diffMsg(first, final, prev int64, bids, asks string) []byte {
+	return []byte(fmt.Sprintf(`{"e":"depthUpdate","E":1700000000000,"s":"BTCUSDT","U":%d,"u":%d,"pu":%d,"b":%s,"a":%s}`,
+		first, final, prev, bids, asks))
+}
+
+// waitSnapshot 等待异步快照结果并应用
+func waitSnapshot(t *testing.T, d *depthSync) int {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(d.results) > 0 {
+			return d.drainSnapshots()
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("等待快照超时")
+	return 0
+}
+
+func TestDepthSync_SnapshotAndDiffs(t *testing.T) {
+	snap := &DepthSnapshot{
+		LastUpdateID: 100,
+		Bids:         fastparse.LevelsOf([][]string{{"99.0", "1"}, {"98.0", "2"}}),
+		Asks:         fastparse.LevelsOf([][]string{{"101.0", "1"}, {"102.0", "2"}}),
+	}
+	var fetches atomic.Int32
+	fetch := func(ctx context.Context, symbol string) (*DepthSnapshot, error) {
+		fetches.Add(1)
+		return snap, nil
+	}
+	d := newDepthSync(NewParser(createTestSymbolMaps()), fetch, 5)
+	ctx := context.Background()
+
+	// 快照前的推送：u < lastUpdateId 的应被丢弃，跨越 lastUpdateId 的作为首条应用
+	for _, msg := range [][]byte{
+		diffMsg(90, 95, 89, `[["97.0","5"]]`, `[]`),
+		diffMsg(96, 103, 95, `[["99.5","3"]]`, `[["101.0","0"]]`),
+	} {
+		events, _, err := d.handle(ctx, msg, 1)
+		if err != nil || events != nil {
+			t.Fatalf("同步前不应输出事件: events=%v err=%v", events, err)
+		}
+	}
+	if n := waitSnapshot(t, d); n != 0 {
+		t.Fatalf("快照应能与缓存推送衔接, resyncs=%d", n)
+	}
+
+	events, resync, err := d.handle(ctx, diffMsg(104, 105, 103, `[["98.0","0"]]`, `[["100.5","4"]]`), 2)
+	if err != nil || resync || len(events) != 1 {
+		t.Fatalf("同步后应输出 1 个事件: events=%d resync=%v err=%v", len(events), resync, err)
+	}
+	ev := events[0]
+	if ev.BestBidPx != 99.5 || ev.BestBidQty != 3 || ev.BestAskPx != 100.5 || ev.BestAskQty != 4 {
+		t.Fatalf("最优价错误: bid=%v@%v ask=%v@%v", ev.BestBidPx, ev.BestBidQty, ev.BestAskPx, ev.BestAskQty)
+	}
+	if ev.Seq != 105 || ev.ArrivedAtUnixNs != 2 {
+		t.Fatalf("Seq=%d ArrivedAtUnixNs=%d, want 105/2", ev.Seq, ev.ArrivedAtUnixNs)
+	}
+	// 95 之前的推送未应用（97.0 不在簿中），98.0 已删除：买盘只剩 99.5、99.0
+	if len(ev.Bids) != 2 || ev.Bids[1].Price != 99.0 || len(ev.Asks) != 2 {
+		t.Fatalf("Bids=%v Asks=%v", ev.Bids, ev.Asks)
+	}
+
+	// pu 不连续 -> 断档重新同步
+	events, resync, _ = d.handle(ctx, diffMsg(110, 111, 109, `[]`, `[]`), 3)
+	if events != nil || !resync {
+		t.Fatalf("断档应触发重新同步: events=%v resync=%v", events, resync)
+	}
+	waitSnapshot(t, d)
+	if n := fetches.Load(); n != 2 {
+		t.Fatalf("fetches=%d, want 2", n)
+	}
+}
+
+func TestDepthSync_StaleSnapshot(t *testing.T) {
+	// 快照早于首条缓存推送（U > lastUpdateId），需重新同步
+	fetch := func(ctx context.Context, symbol string) (*DepthSnapshot, error) {
+		return &DepthSnapshot{LastUpdateID: 100}, nil
+	}
+	d := newDepthSync(NewParser(createTestSymbolMaps()), fetch, 5)
+	if _, _, err := d.handle(context.Background(), diffMsg(120, 125, 119, `[]`, `[]`), 1); err != nil {
+		t.Fatalf("handle 失败: %v", err)
+	}
+	if n := waitSnapshot(t, d); n != 1 {
+		t.Fatalf("过旧快照应计为重新同步, resyncs=%d", n)
+	}
+	if d.books["BTCUSDT"].synced {
+		t.Fatalf("过旧快照不应完成同步")
+	}
+}
+
+func TestDepthSync_PruneUnsubscribed(t *testing.T) {
+	fetch := func(ctx context.Context, symbol string) (*DepthSnapshot, error) {
+		return &DepthSnapshot{LastUpdateID: 100}, nil
+	}
+	parser := NewParser(createTestSymbolMaps())
+	d := newDepthSync(parser, fetch, 5)
+	if _, _, err := d.handle(context.Background(), diffMsg(96, 103, 95, `[]`, `[]`), 1); err != nil {
+		t.Fatalf("handle 失败: %v", err)
+	}
+
+	// 退订后本地订单簿被清理，迟到的快照结果与后续推送均被忽略
+	parser.symbols.Remove([]string{"BTCUSDT"})
+	d.prune()
+	if _, ok := d.books["BTCUSDT"]; ok {
+		t.Fatalf("退订交易对的本地订单簿应被清理")
+	}
+	waitSnapshot(t, d)
+	events, _, err := d.handle(context.Background(), diffMsg(104, 105, 103, `[]`, `[]`), 2)
+	if err != nil || events != nil || len(d.books) != 0 {
+		t.Fatalf("退订后不应再处理推送: events=%v err=%v books=%d", events, err, len(d.books))
+	}
+}
+
+func TestUpsertLevel(t *testing.T) {
+	bids := upsertLevel(nil, 100, 1, true)
+	bids = upsertLevel(bids, 102, 1, true)
+	bids = upsertLevel(bids, 101, 1, true)
+	bids = upsertLevel(bids, 101, 5, true)
+	bids = upsertLevel(bids, 100, 0, true)
+	bids = upsertLevel(bids, 50, 0, true)
+	if len(bids) != 2 || bids[0].Price != 102 || bids[1].Price != 101 || bids[1].Qty != 5 {
+		t.Fatalf("bids=%v", bids)
+	}
+}
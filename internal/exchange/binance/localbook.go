@@ -0,0 +1,182 @@
+package binance
+
+import (
+	"fmt"
+	"sort"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/util/fastparse"
+)
+
+// maxBufferedUpdates 快照到达前最多缓存的增量推送数（超出则丢弃最旧的，由同步校验决定是否重拉快照）
+const maxBufferedUpdates = 1000
+
+// localBook 单交易对本地订单簿（由增量深度流维护）
+// 同步算法遵循 Binance U 本位合约官方说明：
+//  1. 订阅 <symbol>@depth 增量流并缓存推送；
+//  2. 拉取 REST 快照，丢弃 u < lastUpdateId 的推送；
+//  3. 首个应用的推送需满足 U <= lastUpdateId 且 u >= lastUpdateId；
+//  4. 之后每条推送的 pu 必须等于上一条的 u，否则断档需重新同步；
+//  5. 数量为 0 的档位表示删除。
+//
+// 非并发安全，仅由读循环访问。
+type localBook struct {
+	// bids 买盘（价格降序）
+	bids []model.Level
+	// asks 卖盘（价格升序）
+	asks []model.Level
+	// lastUpdateID 最近应用的 updateId
+	lastUpdateID int64
+	// synced 是否已完成快照同步
+	synced bool
+	// awaitingFirst 快照后尚未应用首条推送（首条按 U/u 校验，之后按 pu 校验）
+	awaitingFirst bool
+	// buffer 快照到达前缓存的增量推送
+	buffer []*DepthUpdate
+}
+
+// reset 清空本地订单簿，等待重新同步
+func (b *localBook) reset() {
+	b.bids = b.bids[:0]
+	b.asks = b.asks[:0]
+	b.lastUpdateID = 0
+	b.synced = false
+	b.awaitingFirst = false
+	b.buffer = b.buffer[:0]
+}
+
+// bufferUpdate 缓存快照到达前的增量推送
+func (b *localBook) bufferUpdate(u *DepthUpdate) {
+	if len(b.buffer) >= maxBufferedUpdates {
+		copy(b.buffer, b.buffer[1:])
+		b.buffer = b.buffer[:len(b.buffer)-1]
+	}
+	b.buffer = append(b.buffer, u)
+}
+
+// applySnapshot 以快照初始化本地订单簿，并回放缓存的增量推送
+// 返回错误表示缓存推送无法与快照衔接（需重新拉取快照）。
+func (b *localBook) applySnapshot(snap *DepthSnapshot) error {
+	buffered := b.buffer
+	b.reset()
+
+	b.bids = parseLevels(b.bids, snap.Bids)
+	b.asks = parseLevels(b.asks, snap.Asks)
+	sort.Slice(b.bids, func(i, j int) bool { return b.bids[i].Price > b.bids[j].Price })
+	sort.Slice(b.asks, func(i, j int) bool { return b.asks[i].Price < b.asks[j].Price })
+	b.lastUpdateID = snap.LastUpdateID
+	b.synced = true
+	b.awaitingFirst = true
+
+	for _, u := range buffered {
+		if err := b.applyUpdate(u); err != nil {
+			b.reset()
+			return err
+		}
+	}
+	return nil
+}
+
+// applyUpdate 应用一条增量推送（需已完成快照同步）
+// 返回错误表示推送无法与本地订单簿衔接，调用方应重置并重新同步。
+func (b *localBook) applyUpdate(u *DepthUpdate) error {
+	if u.FinalUpdateID < b.lastUpdateID {
+		return nil // 已包含在快照内的旧推送
+	}
+	if b.awaitingFirst {
+		if u.FirstUpdateID > b.lastUpdateID {
+			return fmt.Errorf("快照过旧: lastUpdateId=%d, 首条推送 U=%d", b.lastUpdateID, u.FirstUpdateID)
+		}
+		b.awaitingFirst = false
+	} else if u.PrevFinalUpdateID != b.lastUpdateID {
+		return fmt.Errorf("增量推送断档: pu=%d, 期望 %d", u.PrevFinalUpdateID, b.lastUpdateID)
+	}
+	b.applyLevels(u)
+	return nil
+}
+
+// applyLevels 合并推送中的档位变化（数量为 0 表示删除）
+func (b *localBook) applyLevels(u *DepthUpdate) {
+	// 格式错误的档位数组按已解析部分合并（与单档解析失败时跳过该档一致）
+	_ = u.Bids.ForEach(func(pxb, qtyb []byte) bool {
+		if px, qty, ok := parseLevel(pxb, qtyb); ok {
+			b.bids = upsertLevel(b.bids, px, qty, true)
+		}
+		return true
+	})
+	_ = u.Asks.ForEach(func(pxb, qtyb []byte) bool {
+		if px, qty, ok := parseLevel(pxb, qtyb); ok {
+			b.asks = upsertLevel(b.asks, px, qty, false)
+		}
+		return true
+	})
+	b.lastUpdateID = u.FinalUpdateID
+}
+
+// event 生成当前本地订单簿的 BookEvent（买卖盘各取前 depth 档）
+func (b *localBook) event(canon string, depth int, arrivedAt, exchTsMs int64) *model.BookEvent {
+	ev := model.AcquireBookEvent()
+	ev.Exchange = model.ExchangeBinance
+	ev.SymbolCanon = canon
+	ev.ArrivedAtUnixNs = arrivedAt
+	ev.ExchTsUnixMs = exchTsMs
+	ev.Seq = b.lastUpdateID
+	if len(b.bids) > 0 {
+		ev.BestBidPx, ev.BestBidQty = b.bids[0].Price, b.bids[0].Qty
+	}
+	if len(b.asks) > 0 {
+		ev.BestAskPx, ev.BestAskQty = b.asks[0].Price, b.asks[0].Qty
+	}
+	nb, na := min(depth, len(b.bids)), min(depth, len(b.asks))
+	ev.Bids = append(ev.Bids[:0], b.bids[:nb]...)
+	ev.Asks = append(ev.Asks[:0], b.asks[:na]...)
+	return ev
+}
+
+// upsertLevel 在有序档位中插入/更新/删除价格档
+// 参数 desc: true 表示价格降序（买盘）
+func upsertLevel(levels []model.Level, px, qty float64, desc bool) []model.Level {
+	i := sort.Search(len(levels), func(i int) bool {
+		if desc {
+			return levels[i].Price <= px
+		}
+		return levels[i].Price >= px
+	})
+	found := i < len(levels) && levels[i].Price == px
+	switch {
+	case qty == 0 && found:
+		return append(levels[:i], levels[i+1:]...)
+	case qty == 0:
+		return levels
+	case found:
+		levels[i].Qty = qty
+		return levels
+	default:
+		levels = append(levels, model.Level{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = model.Level{Price: px, Qty: qty}
+		return levels
+	}
+}
+
+func parseLevels(dst []model.Level, raw fastparse.Levels) []model.Level {
+	_ = raw.ForEach(func(pxb, qtyb []byte) bool {
+		if px, qty, ok := parseLevel(pxb, qtyb); ok && qty > 0 {
+			dst = append(dst, model.Level{Price: px, Qty: qty})
+		}
+		return true
+	})
+	return dst
+}
+
+func parseLevel(pxb, qtyb []byte) (px, qty float64, ok bool) {
+	px, err := fastparse.ParseFloatBytes(pxb)
+	if err != nil || px <= 0 {
+		return 0, 0, false
+	}
+	qty, err = fastparse.ParseFloatBytes(qtyb)
+	if err != nil {
+		return 0, 0, false
+	}
+	return px, qty, true
+}
@@ -1,96 +1,119 @@
 // Package binance 实现 Binance 交易所消息解析。
-// 字段映射: E -> ExchTsUnixMs, Seq=0
+// 字段映射: E -> ExchTsUnixMs, u -> Seq
 package binance
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/core/model"
 	"latency-arbitrage-validator/internal/metadata"
 	"latency-arbitrage-validator/internal/util/fastparse"
-	"latency-arbitrage-validator/internal/util/timeutil"
+	"latency-arbitrage-validator/internal/ws"
 )
 
 // Parser Binance 消息解析器
 type Parser struct {
-	// symbolMaps Symbol 映射表（key 为 Canon），用于过滤未配置交易对
-	symbolMaps map[string]*metadata.SymbolMap
+	// symbols Symbol 映射表（key 为 Canon），用于过滤未配置（或已退订）交易对
+	symbols *metadata.Table
+	// depth 解析并保存的单侧档位数（ws.binance.depth_levels）
+	depth int
 }
 
 // NewParser 创建 Binance 消息解析器
 // 参数 symbolMaps: Symbol 映射表（key 为 Canon）
 func NewParser(symbolMaps map[string]*metadata.SymbolMap) *Parser {
-	return &Parser{symbolMaps: symbolMaps}
+	return &Parser{symbols: metadata.NewTable(symbolMaps), depth: config.DefaultDepthLevels}
 }
 
 // Parse 解析 Binance WebSocket 消息为 BookEvent
 // 参数 data: 原始消息字节
+// 参数 arrivedAt: 消息从 socket 读出时的本地时间（纳秒），由读循环在 ReadMessage 返回后立即采集
 // 返回: 可能包含 0 或 1 个 BookEvent（非深度消息返回空切片）
-func (p *Parser) Parse(data []byte) ([]*model.BookEvent, error) {
-	arrivedAt := timeutil.NowNano()
+func (p *Parser) Parse(data []byte, arrivedAt int64) ([]*model.BookEvent, error) {
+	msg, canon, err := p.decodeDepth(data)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+
+	event := model.AcquireBookEvent()
+	event.Exchange = model.ExchangeBinance
+	event.SymbolCanon = canon
+	event.ArrivedAtUnixNs = arrivedAt
+	event.ExchTsUnixMs = msg.EventTimeMs
+	event.Seq = msg.FinalUpdateID
+	if err := fillTopLevels(event, msg.Bids, msg.Asks, p.depth); err != nil {
+		event.Release()
+		return nil, err
+	}
+
+	return []*model.BookEvent{event}, nil
+}
 
+// fillTopLevels 解析买卖盘前 depth 档写入事件，并以首档作为最优价量
+// 复用事件已有的档位容量（对象池事件无需重新分配）
+func fillTopLevels(ev *model.BookEvent, bids, asks fastparse.Levels, depth int) error {
+	ev.Bids, ev.Asks = ev.Bids[:0], ev.Asks[:0]
+	if err := bids.Top(depth, func(px, qty float64) {
+		ev.Bids = append(ev.Bids, model.Level{Price: px, Qty: qty})
+	}); err != nil {
+		return fmt.Errorf("解析 bids 失败: %w", err)
+	}
+	if err := asks.Top(depth, func(px, qty float64) {
+		ev.Asks = append(ev.Asks, model.Level{Price: px, Qty: qty})
+	}); err != nil {
+		return fmt.Errorf("解析 asks 失败: %w", err)
+	}
+	if len(ev.Bids) > 0 {
+		ev.BestBidPx, ev.BestBidQty = ev.Bids[0].Price, ev.Bids[0].Qty
+	}
+	if len(ev.Asks) > 0 {
+		ev.BestAskPx, ev.BestAskQty = ev.Asks[0].Price, ev.Asks[0].Qty
+	}
+	return nil
+}
+
+// decodeDepth 解码 depthUpdate 推送并过滤未配置交易对
+// 返回: 推送与 Canon；非深度消息或未配置交易对返回 nil
+func (p *Parser) decodeDepth(data []byte) (*DepthUpdate, string, error) {
 	var msg DepthUpdate
 	if err := json.Unmarshal(data, &msg); err != nil {
-		return nil, fmt.Errorf("解析 Binance 消息失败: %w", err)
+		return nil, "", fmt.Errorf("解析 Binance 消息失败: %w", err)
 	}
 
 	if msg.EventType != "depthUpdate" {
-		return nil, nil
+		return nil, "", nil
 	}
 
 	canon := strings.ToUpper(msg.Symbol)
 	if canon == "" {
-		return nil, nil
+		return nil, "", nil
 	}
-	if _, ok := p.symbolMaps[canon]; !ok {
-		return nil, nil
+	if _, ok := p.symbols.Get(canon); !ok {
+		return nil, "", nil
 	}
+	return &msg, canon, nil
+}
 
-	var bestBidPx, bestBidQty, bestAskPx, bestAskQty float64
-	levels := make([]model.Level, 0, 10)
-
-	if len(msg.Bids) > 0 && len(msg.Bids[0]) >= 2 {
-		bestBidPx, _ = fastparse.ParseFloat(msg.Bids[0][0])
-		bestBidQty, _ = fastparse.ParseFloat(msg.Bids[0][1])
+// idMarker 请求响应的字节特征（行情推送不含 id 字段）
+var idMarker = []byte(`"id":`)
 
-		for i, bid := range msg.Bids {
-			if i >= 5 || len(bid) < 2 {
-				break
-			}
-			px, _ := fastparse.ParseFloat(bid[0])
-			qty, _ := fastparse.ParseFloat(bid[1])
-			levels = append(levels, model.Level{Price: px, Qty: qty})
-		}
+// ParseAck 识别 SUBSCRIBE 请求响应（无 error 为确认，带 error 为拒绝）
+// 先做廉价字节匹配，避免每条行情消息多一次 JSON 解析。
+func ParseAck(data []byte) (ws.Ack, bool) {
+	if !bytes.Contains(data, idMarker) {
+		return ws.Ack{}, false
 	}
-
-	if len(msg.Asks) > 0 && len(msg.Asks[0]) >= 2 {
-		bestAskPx, _ = fastparse.ParseFloat(msg.Asks[0][0])
-		bestAskQty, _ = fastparse.ParseFloat(msg.Asks[0][1])
-
-		for i, ask := range msg.Asks {
-			if i >= 5 || len(ask) < 2 {
-				break
-			}
-			px, _ := fastparse.ParseFloat(ask[0])
-			qty, _ := fastparse.ParseFloat(ask[1])
-			levels = append(levels, model.Level{Price: px, Qty: qty})
-		}
+	var resp SubscribeResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return ws.Ack{}, false
 	}
-
-	event := &model.BookEvent{
-		Exchange:        model.ExchangeBinance,
-		SymbolCanon:     canon,
-		BestBidPx:       bestBidPx,
-		BestBidQty:      bestBidQty,
-		BestAskPx:       bestAskPx,
-		BestAskQty:      bestAskQty,
-		Levels:          levels,
-		ArrivedAtUnixNs: arrivedAt,
-		ExchTsUnixMs:    msg.EventTimeMs,
-		Seq:             0,
+	ack := ws.Ack{ID: resp.ID, OK: resp.Error == nil}
+	if resp.Error != nil {
+		ack.Msg = fmt.Sprintf("%d %s", resp.Error.Code, resp.Error.Msg)
 	}
-
-	return []*model.BookEvent{event}, nil
+	return ack, true
 }
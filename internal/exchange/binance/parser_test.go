@@ -4,13 +4,17 @@ package binance
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
 
+	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/metadata"
+	"latency-arbitrage-validator/internal/testkit"
+	"latency-arbitrage-validator/internal/util/fastparse"
 )
 
 // **Feature: latency-arbitrage-validator, Property 1: Parser Round-Trip Consistency (Binance)**
@@ -43,8 +47,8 @@ func TestParser_RoundTrip(t *testing.T) {
 				EventType:   "depthUpdate",
 				EventTimeMs: ts,
 				Symbol:      "BTCUSDT",
-				Bids:        [][]string{{fmt.Sprintf("%.2f", bidPx), fmt.Sprintf("%.4f", bidQty)}},
-				Asks:        [][]string{{fmt.Sprintf("%.2f", askPx), fmt.Sprintf("%.4f", askQty)}},
+				Bids:        fastparse.LevelsOf([][]string{{fmt.Sprintf("%.2f", bidPx), fmt.Sprintf("%.4f", bidQty)}}),
+				Asks:        fastparse.LevelsOf([][]string{{fmt.Sprintf("%.2f", askPx), fmt.Sprintf("%.4f", askQty)}}),
 			}
 
 			data, err := json.Marshal(msg)
@@ -52,7 +56,7 @@ func TestParser_RoundTrip(t *testing.T) {
 				return false
 			}
 
-			events, err := parser.Parse(data)
+			events, err := parser.Parse(data, 1)
 			if err != nil || len(events) != 1 {
 				return false
 			}
@@ -121,7 +125,7 @@ func TestParser_SpecificMessages(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			events, err := parser.Parse([]byte(tt.message))
+			events, err := parser.Parse([]byte(tt.message), 1)
 			if err != nil {
 				t.Fatalf("解析失败: %v", err)
 			}
@@ -154,8 +158,66 @@ func TestParser_SpecificMessages(t *testing.T) {
 func TestParser_InvalidMessages(t *testing.T) {
 	parser := NewParser(createTestSymbolMaps())
 
-	_, err := parser.Parse([]byte(`{invalid json}`))
+	_, err := parser.Parse([]byte(`{invalid json}`), 1)
 	if err == nil {
 		t.Fatalf("期望错误但得到 nil")
 	}
 }
+
+// TestParseAck 测试 SUBSCRIBE 响应识别
+func TestParseAck(t *testing.T) {
+	tests := []struct {
+		data   string
+		isAck  bool
+		wantID int64
+		wantOK bool
+	}{
+		{`{"result":null,"id":2}`, true, 2, true},
+		{`{"error":{"code":2,"msg":"Invalid request"},"id":3}`, true, 3, false},
+		{`{"e":"depthUpdate","E":1,"s":"BTCUSDT","u":5,"b":[],"a":[]}`, false, 0, false},
+	}
+
+	for _, tt := range tests {
+		ack, ok := ParseAck([]byte(tt.data))
+		if ok != tt.isAck || ack.ID != tt.wantID || ack.OK != tt.wantOK {
+			t.Errorf("ParseAck(%q) = %+v, %v", tt.data, ack, ok)
+		}
+	}
+}
+
+// TestClient_BuildFrameUpdateSpeed 测试订阅流名随 depth_update_ms 变化（250ms 为默认间隔，不带后缀）
+func TestClient_BuildFrameUpdateSpeed(t *testing.T) {
+	tests := []struct {
+		updateMs int
+		diffBook bool
+		want     string
+	}{
+		{0, false, "btcusdt@depth5@100ms"},
+		{100, false, "btcusdt@depth5@100ms"},
+		{250, false, "btcusdt@depth5"},
+		{500, false, "btcusdt@depth5@500ms"},
+		{500, true, "btcusdt@depth@500ms"},
+	}
+	for _, tt := range tests {
+		c := &Client{cfg: &config.ExchangeWSConfig{DepthUpdateMs: tt.updateMs}, parser: NewParser(createTestSymbolMaps())}
+		if tt.diffBook {
+			c.depth = &depthSync{}
+		}
+		frame, err := c.buildFrame("SUBSCRIBE", 1, createTestSymbolMaps(), []string{"BTCUSDT"})
+		if err != nil {
+			t.Fatalf("buildFrame: %v", err)
+		}
+		var req SubscribeRequest
+		if err := json.Unmarshal(frame, &req); err != nil {
+			t.Fatalf("解析订阅帧失败: %v", err)
+		}
+		if len(req.Params) != 1 || req.Params[0] != tt.want {
+			t.Errorf("depth_update_ms=%d diff_book=%v: got %v, want %s", tt.updateMs, tt.diffBook, req.Params, tt.want)
+		}
+	}
+}
+
+// TestParser_Golden 回归样本：testdata/golden.jsonl 的帧按顺序解析，输出须与录制结果逐字段一致
+func TestParser_Golden(t *testing.T) {
+	testkit.RunGolden(t, filepath.Join("testdata", "golden.jsonl"), NewParser(createTestSymbolMaps()).Parse)
+}
@@ -0,0 +1,32 @@
+package binance
+
+import (
+	"context"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// newRESTPoller 创建基于 REST /fapi/v1/depth 的深度拉取函数（公共行情接口，无需签名）
+// 仅在 WS 长时间断线时由 ws.Manager 低频调用；limit 取 depth_levels（5/10/20 请求权重最低）。
+// 参数 baseURL: 深度接口地址
+func (c *Client) newRESTPoller(baseURL string) func(ctx context.Context, canon string) (*model.BookEvent, error) {
+	fetch := newHTTPSnapshotFunc(baseURL, c.parser.depth)
+	return func(ctx context.Context, canon string) (*model.BookEvent, error) {
+		m, ok := c.parser.symbols.Get(canon)
+		if !ok {
+			return nil, nil
+		}
+		snap, err := fetch(ctx, m.BinanceSym)
+		if err != nil {
+			return nil, err
+		}
+		arrivedAt := timeutil.NowNano()
+
+		var b localBook
+		if err := b.applySnapshot(snap); err != nil {
+			return nil, err
+		}
+		return b.event(canon, c.parser.depth, arrivedAt, snap.EventTimeMs), nil
+	}
+}
@@ -1,10 +1,15 @@
 // Package binance 定义 Binance 交易所消息类型。
 package binance
 
+import (
+	"latency-arbitrage-validator/internal/util/fastparse"
+	"latency-arbitrage-validator/internal/ws"
+)
+
 // SubscribeRequest Binance WebSocket 订阅请求
-// 订阅 depth5@100ms 行情流。
+// 订阅 depth<N>@<M>ms 行情流。
 type SubscribeRequest struct {
-	// Method 订阅方法: SUBSCRIBE
+	// Method 订阅方法: SUBSCRIBE, UNSUBSCRIBE
 	Method string `json:"method"`
 	// Params 订阅参数列表，如 "btcusdt@depth5@100ms"
 	Params []string `json:"params"`
@@ -19,6 +24,16 @@ type SubscribeResponse struct {
 	Result any `json:"result"`
 	// ID 请求 ID
 	ID int64 `json:"id"`
+	// Error 错误详情（失败时形如 {"error":{"code":2,"msg":"..."},"id":1}）
+	Error *ErrorDetail `json:"error,omitempty"`
+}
+
+// ErrorDetail WebSocket 请求错误详情
+type ErrorDetail struct {
+	// Code 错误码
+	Code int `json:"code"`
+	// Msg 错误消息
+	Msg string `json:"msg"`
 }
 
 // DepthUpdate Binance 深度推送消息（depthUpdate）
@@ -26,6 +41,9 @@ type SubscribeResponse struct {
 // - e: 事件类型（depthUpdate）
 // - E: 事件时间（毫秒） -> BookEvent.ExchTsUnixMs
 // - s: Symbol（如 BTCUSDT） -> BookEvent.SymbolCanon（与 Canon 一致）
+// - U: 本次推送的首个 updateId（增量深度同步校验用）
+// - u: 本次推送的最终 updateId -> BookEvent.Seq
+// - pu: 上一次推送的最终 updateId（增量深度同步校验用）
 // - b: bids [[price, qty], ...]（字符串）
 // - a: asks [[price, qty], ...]（字符串）
 type DepthUpdate struct {
@@ -35,20 +53,34 @@ type DepthUpdate struct {
 	EventTimeMs int64 `json:"E"`
 	// Symbol 交易对（大写）
 	Symbol string `json:"s"`
+	// FirstUpdateID 首个 updateId
+	FirstUpdateID int64 `json:"U"`
+	// FinalUpdateID 最终 updateId（单调递增，用于多连接去重）
+	FinalUpdateID int64 `json:"u"`
+	// PrevFinalUpdateID 上一次推送的最终 updateId（增量流连续性校验）
+	PrevFinalUpdateID int64 `json:"pu"`
+	// Bids 买盘档位（价格、数量）
+	Bids fastparse.Levels `json:"b"`
+	// Asks 卖盘档位（价格、数量）
+	Asks fastparse.Levels `json:"a"`
+}
+
+// DepthSnapshot Binance 深度快照（REST /fapi/v1/depth）
+// 仅在 diff_book 模式下用于初始化本地订单簿。
+type DepthSnapshot struct {
+	// LastUpdateID 快照对应的 updateId
+	LastUpdateID int64 `json:"lastUpdateId"`
+	// EventTimeMs 消息输出时间（毫秒）
+	EventTimeMs int64 `json:"E"`
 	// Bids 买盘档位（价格、数量）
-	Bids [][]string `json:"b"`
+	Bids fastparse.Levels `json:"bids"`
 	// Asks 卖盘档位（价格、数量）
-	Asks [][]string `json:"a"`
+	Asks fastparse.Levels `json:"asks"`
 }
 
-// ConnectionMetrics 连接质量指标
+// ConnectionMetrics 连接质量指标（通用指标之外附加本地订单簿同步统计）
 type ConnectionMetrics struct {
-	// ReconnectCount 重连次数
-	ReconnectCount int64
-	// ParseErrorCount 解析错误次数
-	ParseErrorCount int64
-	// UpdatesPerSec 每秒更新次数
-	UpdatesPerSec float64
-	// LastMessageAgeMs 最后消息距今时间（毫秒）
-	LastMessageAgeMs int64
+	ws.Metrics
+	// BookResyncs 增量深度流断档导致本地订单簿重新同步的次数（仅 diff_book）
+	BookResyncs int64
 }
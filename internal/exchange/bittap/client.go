@@ -5,13 +5,11 @@
 package bittap
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"sync"
-	"sync/atomic"
-	"time"
+	"strconv"
 
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
@@ -19,49 +17,21 @@ import (
 	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/core/model"
 	"latency-arbitrage-validator/internal/metadata"
-	"latency-arbitrage-validator/internal/util/backoff"
-	"latency-arbitrage-validator/internal/util/timeutil"
+	"latency-arbitrage-validator/internal/output/rawcapture"
+	"latency-arbitrage-validator/internal/ws"
 )
 
 // Client Bittap WebSocket 客户端（Follower）
+// 连接、心跳、看门狗与重连由 ws.Manager 负责，本类型只提供 Bittap 协议差异。
 type Client struct {
 	// cfg WebSocket 配置
 	cfg *config.ExchangeWSConfig
-	// symbolMaps Symbol 映射表（key 为 Canon）
-	symbolMaps map[string]*metadata.SymbolMap
 	// logger 日志记录器
 	logger *zap.Logger
 	// parser 消息解析器
 	parser *Parser
-
-	// conn WebSocket 连接
-	conn *websocket.Conn
-	// connMu 连接锁
-	connMu sync.Mutex
-
-	// bookCh 订单簿事件输出通道
-	bookCh chan *model.BookEvent
-	// errCh 错误输出通道
-	errCh chan error
-
-	// metrics 连接指标
-	metrics ConnectionMetrics
-	// metricsMu 指标锁
-	metricsMu sync.RWMutex
-
-	// lastMsgTime 最后消息时间（纳秒）
-	lastMsgTime int64
-	// updateCount 更新计数（用于计算 QPS）
-	updateCount int64
-	// backoff 重连退避
-	backoff *backoff.Backoff
-	// closed 是否已关闭
-	closed int32
-
-	// parseErrSampleCount 解析错误计数（用于采样日志）
-	parseErrSampleCount uint64
-	// lastParseErrLogNs 上次解析错误日志时间（纳秒）
-	lastParseErrLogNs int64
+	// ws 连接管理器
+	ws *ws.Manager
 }
 
 // NewClient 创建 Bittap WebSocket 客户端
@@ -69,311 +39,127 @@ type Client struct {
 // 参数 symbolMaps: Symbol 映射表（key 为 Canon）
 // 参数 logger: 日志记录器
 func NewClient(cfg *config.ExchangeWSConfig, symbolMaps map[string]*metadata.SymbolMap, logger *zap.Logger) *Client {
-	return &Client{
-		cfg:        cfg,
-		symbolMaps: symbolMaps,
-		logger:     logger.Named("bittap"),
-		parser:     NewParser(symbolMaps),
-		bookCh:     make(chan *model.BookEvent, 1000),
-		errCh:      make(chan error, 10),
-		backoff:    backoff.NewDefault(),
+	c := &Client{
+		cfg:    cfg,
+		logger: logger.Named("bittap"),
+		parser: NewParser(symbolMaps),
+	}
+	if cfg.DepthLevels > 0 {
+		c.parser.depth = cfg.DepthLevels
+	}
+	pingIntervalMs := cfg.PingIntervalMs
+	if pingIntervalMs <= 0 {
+		pingIntervalMs = 18000
+	}
+	c.ws = ws.New(cfg, ws.Spec{
+		Name:           "Bittap",
+		Exchange:       model.ExchangeBittap,
+		Origin:         "https://www.bittap.com",
+		ReadTimeoutMs:  c.readTimeoutMs(),
+		PingIntervalMs: pingIntervalMs,
+		PingFrame:      pingFrame,
+		// 先做廉价字节匹配，避免每条深度消息多一次 JSON 解析
+		IsPong: func(data []byte) bool {
+			return bytes.Contains(data, pongMarker) && IsPong(data)
+		},
+		Symbols:        c.parser.symbols.Canons,
+		SubscribeFrame: c.subscribeFrame,
+		ParseAck:       ParseAck,
+		Handle: func(_ context.Context, data []byte, nowNs int64) ([]*model.BookEvent, error) {
+			return c.parser.Parse(data, nowNs)
+		},
+	}, c.logger)
+	return c
+}
+
+// pongMarker PONG 响应的字节特征
+var pongMarker = []byte("PONG")
+
+// pingFrame 构造 JSON PING 请求
+func pingFrame(seq uint64) (int, []byte, error) {
+	data, err := json.Marshal(PingRequest{ID: fmt.Sprintf("ping-%d", seq), Method: "PING"})
+	return websocket.TextMessage, data, err
+}
+
+// subscribeFrame 构建一帧 f_depth30@{symbol}_{tick} 订阅请求
+// 参数 id: 请求 ID（响应中原样返回）
+// 参数 canons: 本帧订阅的统一交易对
+func (c *Client) subscribeFrame(id int64, canons []string) ([]byte, error) {
+	return buildFrame("SUBSCRIBE", id, c.parser.symbols.Load(), canons)
+}
+
+// buildFrame 构建一帧 SUBSCRIBE/UNSUBSCRIBE 请求
+// 参数 method: SUBSCRIBE 或 UNSUBSCRIBE
+// 参数 maps: 交易对映射（key 为 Canon）
+func buildFrame(method string, id int64, maps map[string]*metadata.SymbolMap, canons []string) ([]byte, error) {
+	params := make([]string, 0, len(canons))
+	for _, canon := range canons {
+		m := maps[canon]
+		params = append(params, fmt.Sprintf("f_depth30@%s_%s", m.BittapSym, m.BittapTick))
 	}
+	return json.Marshal(SubscribeRequest{Method: method, Params: params, ID: strconv.FormatInt(id, 10)})
 }
 
 // Connect 建立 WebSocket 连接
 // 参数 ctx: 上下文，用于取消连接
 func (c *Client) Connect(ctx context.Context) error {
-	c.connMu.Lock()
-	defer c.connMu.Unlock()
-
-	header := http.Header{}
-	header.Set("User-Agent", "latency-arbitrage-validator/1.0")
-	header.Set("Origin", "https://www.bittap.com")
-
-	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
-	conn, _, err := dialer.DialContext(ctx, c.cfg.URL, header)
-	if err != nil {
-		return fmt.Errorf("连接 Bittap WebSocket 失败: %w", err)
-	}
-
-	readTimeout := time.Duration(c.readTimeoutMs()) * time.Millisecond
-	if readTimeout > 0 {
-		_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
-	}
-
-	c.conn = conn
-	c.backoff.Reset()
-	c.logger.Info("Bittap WebSocket 连接成功", zap.String("url", c.cfg.URL))
-	return nil
+	return c.ws.Connect(ctx)
 }
 
 // Subscribe 订阅交易对
 // 订阅频道: f_depth30@{symbol}_{tick}
 func (c *Client) Subscribe() error {
-	c.connMu.Lock()
-	defer c.connMu.Unlock()
-
-	if c.conn == nil {
-		return fmt.Errorf("WebSocket 未连接")
-	}
-
-	params := make([]string, 0, len(c.symbolMaps))
-	for _, m := range c.symbolMaps {
-		params = append(params, fmt.Sprintf("f_depth30@%s_%s", m.BittapSym, m.BittapTick))
-	}
-
-	req := SubscribeRequest{
-		Method: "SUBSCRIBE",
-		Params: params,
-		ID:     "validator",
-	}
+	return c.ws.Subscribe()
+}
 
-	data, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("序列化订阅请求失败: %w", err)
-	}
-	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		return fmt.Errorf("发送订阅请求失败: %w", err)
+// Unsubscribe 运行期退订交易对
+// 先从映射表移除（解析器随即丢弃其行情，重连后不再订阅），再发送 UNSUBSCRIBE 请求。
+// 参数 canons: 退订的统一交易对（未订阅的忽略）
+func (c *Client) Unsubscribe(canons []string) error {
+	removed := c.parser.symbols.Remove(canons)
+	if len(removed) == 0 {
+		return nil
 	}
-
-	c.logger.Info("Bittap 订阅请求已发送", zap.Int("symbols", len(params)))
-	return nil
+	return c.ws.Unsubscribe(metadata.SortedCanons(removed), func(id int64, chunk []string) ([]byte, error) {
+		return buildFrame("UNSUBSCRIBE", id, removed, chunk)
+	})
 }
 
 // Run 启动客户端主循环
-// 包含读取循环、心跳循环、指标统计
+// 包含读取循环、心跳循环、指标统计与看门狗
 func (c *Client) Run(ctx context.Context) {
-	go c.heartbeatLoop(ctx)
-	go c.metricsLoop(ctx)
-	c.readLoop(ctx)
-}
-
-func (c *Client) readLoop(ctx context.Context) {
-	readTimeout := time.Duration(c.readTimeoutMs()) * time.Millisecond
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-
-		if atomic.LoadInt32(&c.closed) == 1 {
-			return
-		}
-
-		c.connMu.Lock()
-		conn := c.conn
-		c.connMu.Unlock()
-
-		if conn == nil {
-			c.reconnect(ctx)
-			continue
-		}
-
-		_, data, err := conn.ReadMessage()
-		if err != nil {
-			c.logger.Warn("读取 Bittap 消息失败", zap.Error(err))
-			c.incrementReconnectCount()
-			c.reconnect(ctx)
-			continue
-		}
-
-		if readTimeout > 0 {
-			_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
-		}
-
-		atomic.StoreInt64(&c.lastMsgTime, timeutil.NowNano())
-
-		events, err := c.parser.Parse(data)
-		if err != nil {
-			c.incrementParseErrorCount()
-			c.maybeLogParseError(err, data)
-			continue
-		}
-
-		for _, event := range events {
-			atomic.AddInt64(&c.updateCount, 1)
-			select {
-			case c.bookCh <- event:
-			default:
-				c.logger.Warn("Bittap bookCh 已满，丢弃事件")
-			}
-		}
-	}
-}
-
-func (c *Client) heartbeatLoop(ctx context.Context) {
-	intervalMs := c.cfg.PingIntervalMs
-	if intervalMs <= 0 {
-		intervalMs = 18000
-	}
-
-	ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
-	defer ticker.Stop()
-
-	var seq uint64
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if atomic.LoadInt32(&c.closed) == 1 {
-				return
-			}
-
-			c.connMu.Lock()
-			conn := c.conn
-			if conn == nil {
-				c.connMu.Unlock()
-				continue
-			}
-
-			seq++
-			req := PingRequest{ID: fmt.Sprintf("ping-%d", seq), Method: "PING"}
-			data, err := json.Marshal(req)
-			if err != nil {
-				c.connMu.Unlock()
-				continue
-			}
-			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-				c.connMu.Unlock()
-				c.logger.Warn("发送 Bittap PING 失败", zap.Error(err))
-				continue
-			}
-			c.connMu.Unlock()
-		}
-	}
-}
-
-func (c *Client) metricsLoop(ctx context.Context) {
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-
-	var lastCount int64
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if atomic.LoadInt32(&c.closed) == 1 {
-				return
-			}
-
-			count := atomic.LoadInt64(&c.updateCount)
-			qps := float64(count - lastCount)
-			lastCount = count
-
-			lastMsg := atomic.LoadInt64(&c.lastMsgTime)
-			var ageMs int64
-			if lastMsg > 0 {
-				ageMs = (timeutil.NowNano() - lastMsg) / 1_000_000
-			}
-
-			c.metricsMu.Lock()
-			c.metrics.UpdatesPerSec = qps
-			c.metrics.LastMessageAgeMs = ageMs
-			c.metricsMu.Unlock()
-		}
-	}
-}
-
-func (c *Client) reconnect(ctx context.Context) {
-	c.closeConn()
-
-	delay := c.backoff.Next()
-	c.logger.Info("Bittap 准备重连", zap.Duration("delay", delay))
-
-	select {
-	case <-ctx.Done():
-		return
-	case <-time.After(delay):
-	}
-
-	if err := c.Connect(ctx); err != nil {
-		c.logger.Error("Bittap 重连失败", zap.Error(err))
-		return
-	}
-	if err := c.Subscribe(); err != nil {
-		c.logger.Error("Bittap 重新订阅失败", zap.Error(err))
-	}
-}
-
-func (c *Client) closeConn() {
-	c.connMu.Lock()
-	defer c.connMu.Unlock()
-
-	if c.conn != nil {
-		_ = c.conn.Close()
-		c.conn = nil
-	}
+	c.ws.Run(ctx)
 }
 
 // Close 关闭客户端
 func (c *Client) Close() error {
-	atomic.StoreInt32(&c.closed, 1)
-	c.closeConn()
-	close(c.bookCh)
-	close(c.errCh)
-	c.logger.Info("Bittap 客户端已关闭")
-	return nil
+	return c.ws.Close()
+}
+
+// SetRawCapture 设置原始帧采样录制器（需在 Connect 之前调用）
+func (c *Client) SetRawCapture(r *rawcapture.Recorder) {
+	c.ws.SetRawCapture(r)
 }
 
 // BookCh 获取订单簿事件通道
 func (c *Client) BookCh() <-chan *model.BookEvent {
-	return c.bookCh
+	return c.ws.BookCh()
 }
 
-// ErrCh 获取错误通道
+// ErrCh 获取错误通道（元素为 *connerr.Error；通道满时丢弃）
 func (c *Client) ErrCh() <-chan error {
-	return c.errCh
+	return c.ws.ErrCh()
 }
 
 // Metrics 获取连接指标
 func (c *Client) Metrics() ConnectionMetrics {
-	c.metricsMu.RLock()
-	defer c.metricsMu.RUnlock()
-	return c.metrics
-}
-
-func (c *Client) incrementReconnectCount() {
-	c.metricsMu.Lock()
-	c.metrics.ReconnectCount++
-	c.metricsMu.Unlock()
-}
-
-func (c *Client) incrementParseErrorCount() {
-	c.metricsMu.Lock()
-	c.metrics.ParseErrorCount++
-	c.metricsMu.Unlock()
+	return c.ws.Metrics()
 }
 
 func (c *Client) readTimeoutMs() int {
 	if c.cfg.ReadTimeoutMs > 0 {
 		return c.cfg.ReadTimeoutMs
 	}
+	// 未配置时使用 30s
 	return 30000
 }
-
-// maybeLogParseError 采样记录解析错误原始消息，避免刷盘
-// 采样策略：每 100 次错误记录 1 条，且同一类日志至少间隔 1 分钟。
-func (c *Client) maybeLogParseError(err error, data []byte) {
-	count := atomic.AddUint64(&c.parseErrSampleCount, 1)
-	if count%100 != 0 {
-		return
-	}
-
-	nowNs := timeutil.NowNano()
-	last := atomic.LoadInt64(&c.lastParseErrLogNs)
-	if last > 0 && nowNs-last < int64(time.Minute) {
-		return
-	}
-	atomic.StoreInt64(&c.lastParseErrLogNs, nowNs)
-
-	sample := data
-	if len(sample) > 200 {
-		sample = sample[:200]
-	}
-	c.logger.Warn("解析 Bittap 消息失败（采样）", zap.Error(err), zap.ByteString("data", sample))
-}
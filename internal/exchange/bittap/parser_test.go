@@ -4,6 +4,7 @@ package bittap
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/leanovate/gopter"
@@ -11,6 +12,8 @@ import (
 	"github.com/leanovate/gopter/prop"
 
 	"latency-arbitrage-validator/internal/metadata"
+	"latency-arbitrage-validator/internal/testkit"
+	"latency-arbitrage-validator/internal/util/fastparse"
 )
 
 // **Feature: latency-arbitrage-validator, Property 1: Parser Round-Trip Consistency (Bittap)**
@@ -45,15 +48,15 @@ func TestParser_RoundTrip(t *testing.T) {
 				Symbol:       "BTC-USDT-M",
 				Tick:         "0.1",
 				LastUpdateID: seq,
-				Bids:         [][]string{{fmt.Sprintf("%.2f", bidPx), fmt.Sprintf("%.4f", bidQty)}},
-				Asks:         [][]string{{fmt.Sprintf("%.2f", askPx), fmt.Sprintf("%.4f", askQty)}},
+				Bids:         fastparse.LevelsOf([][]string{{fmt.Sprintf("%.2f", bidPx), fmt.Sprintf("%.4f", bidQty)}}),
+				Asks:         fastparse.LevelsOf([][]string{{fmt.Sprintf("%.2f", askPx), fmt.Sprintf("%.4f", askQty)}}),
 			}
 			data, err := json.Marshal(msg)
 			if err != nil {
 				return false
 			}
 
-			events, err := parser.Parse(data)
+			events, err := parser.Parse(data, 1)
 			if err != nil || len(events) != 1 {
 				return false
 			}
@@ -131,7 +134,7 @@ func TestParser_SpecificMessages(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			events, err := parser.Parse([]byte(tt.message))
+			events, err := parser.Parse([]byte(tt.message), 1)
 			if err != nil {
 				t.Fatalf("解析失败: %v", err)
 			}
@@ -162,11 +165,72 @@ func TestParser_SpecificMessages(t *testing.T) {
 	}
 }
 
+func TestParser_DepthLevels(t *testing.T) {
+	bids := make([][]string, 30)
+	asks := make([][]string, 30)
+	for i := range bids {
+		bids[i] = []string{fmt.Sprintf("%d", 100-i), "1"}
+		asks[i] = []string{fmt.Sprintf("%d", 101+i), "1"}
+	}
+	data, err := json.Marshal(DepthMessage{
+		Event:        "f_depth30",
+		Symbol:       "BTC-USDT-M",
+		LastUpdateID: 1,
+		Bids:         fastparse.LevelsOf(bids),
+		Asks:         fastparse.LevelsOf(asks),
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	for _, depth := range []int{5, 10, 30} {
+		parser := NewParser(createTestSymbolMaps())
+		parser.depth = depth
+		events, err := parser.Parse(data, 1)
+		if err != nil || len(events) != 1 {
+			t.Fatalf("depth=%d: Parse err=%v events=%d", depth, err, len(events))
+		}
+		ev := events[0]
+		if len(ev.Bids) != depth || len(ev.Asks) != depth {
+			t.Fatalf("depth=%d: bids=%d asks=%d", depth, len(ev.Bids), len(ev.Asks))
+		}
+		if ev.Bids[depth-1].Price != float64(100-depth+1) || ev.BestAskPx != 101 {
+			t.Fatalf("depth=%d: 末档买价=%v 最优卖价=%v", depth, ev.Bids[depth-1].Price, ev.BestAskPx)
+		}
+	}
+}
+
 func TestParser_InvalidMessages(t *testing.T) {
 	parser := NewParser(createTestSymbolMaps())
 
-	_, err := parser.Parse([]byte(`{invalid json}`))
+	_, err := parser.Parse([]byte(`{invalid json}`), 1)
 	if err == nil {
 		t.Fatalf("期望错误但得到 nil")
 	}
 }
+
+// TestParseAck 测试 SUBSCRIBE 响应识别
+func TestParseAck(t *testing.T) {
+	tests := []struct {
+		data   string
+		isAck  bool
+		wantID int64
+		wantOK bool
+	}{
+		{`{"result":null,"id":"7"}`, true, 7, true},
+		{`{"error":{"code":400,"msg":"invalid channel"},"id":"8"}`, true, 8, false},
+		{`{"e":"f_depth30","s":"BTC-USDT-M","i":"0.1","lastUpdateId":1,"bids":[],"asks":[]}`, false, 0, false},
+	}
+
+	for _, tt := range tests {
+		ack, ok := ParseAck([]byte(tt.data))
+		if ok != tt.isAck || ack.ID != tt.wantID || ack.OK != tt.wantOK {
+			t.Errorf("ParseAck(%q) = %+v, %v", tt.data, ack, ok)
+		}
+	}
+}
+
+// TestParser_Golden 回归样本：testdata/golden.jsonl 的帧按顺序解析，输出须与录制结果逐字段一致
+func TestParser_Golden(t *testing.T) {
+	testkit.RunGolden(t, filepath.Join("testdata", "golden.jsonl"), NewParser(createTestSymbolMaps()).Parse)
+}
@@ -1,10 +1,15 @@
 // Package bittap 定义 Bittap 交易所消息类型。
 package bittap
 
+import (
+	"latency-arbitrage-validator/internal/util/fastparse"
+	"latency-arbitrage-validator/internal/ws"
+)
+
 // SubscribeRequest Bittap WebSocket 订阅请求
 // 订阅频道格式：f_depth30@{symbol}_{tick}。
 type SubscribeRequest struct {
-	// Method 订阅方法: SUBSCRIBE
+	// Method 订阅方法: SUBSCRIBE, UNSUBSCRIBE
 	Method string `json:"method"`
 	// Params 订阅参数列表，如 "f_depth30@BTC-USDT-M_0.1"
 	Params []string `json:"params"`
@@ -28,6 +33,25 @@ type PongResponse struct {
 	Method string `json:"method"`
 }
 
+// SubscribeResponse Bittap WebSocket 订阅响应
+// 形如 {"result":null,"id":"1"}，失败时带 error 字段。
+type SubscribeResponse struct {
+	// Result 结果（成功为 null）
+	Result any `json:"result"`
+	// ID 请求 ID
+	ID string `json:"id"`
+	// Error 错误详情
+	Error *ErrorDetail `json:"error,omitempty"`
+}
+
+// ErrorDetail WebSocket 请求错误详情
+type ErrorDetail struct {
+	// Code 错误码
+	Code int `json:"code"`
+	// Msg 错误消息
+	Msg string `json:"msg"`
+}
+
 // DepthMessage Bittap 深度推送消息（f_depth30）
 // 字段映射：
 // - e: "f_depth30"
@@ -45,19 +69,10 @@ type DepthMessage struct {
 	// LastUpdateID 序列号
 	LastUpdateID int64 `json:"lastUpdateId"`
 	// Bids 买盘档位（高->低）
-	Bids [][]string `json:"bids"`
+	Bids fastparse.Levels `json:"bids"`
 	// Asks 卖盘档位（低->高）
-	Asks [][]string `json:"asks"`
+	Asks fastparse.Levels `json:"asks"`
 }
 
 // ConnectionMetrics 连接质量指标
-type ConnectionMetrics struct {
-	// ReconnectCount 重连次数
-	ReconnectCount int64
-	// ParseErrorCount 解析错误次数
-	ParseErrorCount int64
-	// UpdatesPerSec 每秒更新次数
-	UpdatesPerSec float64
-	// LastMessageAgeMs 最后消息距今时间（毫秒）
-	LastMessageAgeMs int64
-}
+type ConnectionMetrics = ws.Metrics
@@ -0,0 +1,136 @@
+// Package connerr 定义行情客户端通过错误通道上报的连接错误。
+// 错误按类别计数并由聚合器汇总最近一次错误，用于在指标快照中暴露连接状态。
+package connerr
+
+import (
+	"context"
+	"sync"
+
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// 错误类别
+const (
+	// CategoryDial 建立连接失败
+	CategoryDial = "dial"
+	// CategorySubscribe 发送订阅请求失败
+	CategorySubscribe = "subscribe"
+	// CategoryRead 读取消息失败（连接断开）
+	CategoryRead = "read"
+	// CategoryHeartbeat 发送心跳失败或心跳超时
+	CategoryHeartbeat = "heartbeat"
+	// CategoryParse 消息解析失败（仅上报采样后的错误）
+	CategoryParse = "parse"
+)
+
+// Error 客户端上报的连接错误
+type Error struct {
+	// Exchange 交易所
+	Exchange string
+	// Category 错误类别
+	Category string
+	// AtUnixNs 发生时间（纳秒）
+	AtUnixNs int64
+	// Err 原始错误
+	Err error
+}
+
+// New 创建连接错误，发生时间取当前时间
+// 参数 exchange: 交易所
+// 参数 category: 错误类别
+// 参数 err: 原始错误
+func New(exchange, category string, err error) *Error {
+	return &Error{Exchange: exchange, Category: category, AtUnixNs: timeutil.NowNano(), Err: err}
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return e.Exchange + " " + e.Category + ": " + e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Counts 按类别的错误累计次数（解析错误见各客户端的 ParseErrorCount）
+type Counts struct {
+	// Dial 建立连接失败次数
+	Dial int64
+	// Subscribe 发送订阅请求失败次数
+	Subscribe int64
+	// Read 读取消息失败次数
+	Read int64
+	// Heartbeat 心跳失败或超时次数
+	Heartbeat int64
+}
+
+// Add 按类别累加一次（未知类别与解析错误忽略）
+func (c *Counts) Add(category string) {
+	switch category {
+	case CategoryDial:
+		c.Dial++
+	case CategorySubscribe:
+		c.Subscribe++
+	case CategoryRead:
+		c.Read++
+	case CategoryHeartbeat:
+		c.Heartbeat++
+	}
+}
+
+// Send 非阻塞地投递错误，通道已满时丢弃，返回是否投递成功
+// 错误通道仅用于状态展示，不能反压读循环。
+func Send(ch chan<- error, e *Error) bool {
+	select {
+	case ch <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+// Merge 将多个客户端的错误通道合并为一个
+// 所有输入通道关闭或 ctx 取消后关闭输出通道。
+func Merge(ctx context.Context, chs ...<-chan error) <-chan error {
+	out := make(chan error, 16)
+	var wg sync.WaitGroup
+	wg.Add(len(chs))
+	for _, ch := range chs {
+		go func(ch <-chan error) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case err, ok := <-ch:
+					if !ok {
+						return
+					}
+					select {
+					case out <- err:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Last 某交易所最近一次连接错误（状态输出）
+type Last struct {
+	// Category 错误类别
+	Category string `json:"category"`
+	// Error 错误信息
+	Error string `json:"error"`
+	// AtUnixNs 发生时间（纳秒）
+	AtUnixNs int64 `json:"at_unix_ns"`
+	// Total 本次运行累计收到的错误数
+	Total int64 `json:"total"`
+}
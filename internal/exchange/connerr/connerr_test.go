@@ -0,0 +1,66 @@
+// Package connerr 连接错误上报与合并测试
+package connerr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCounts_Add(t *testing.T) {
+	var c Counts
+	for _, cat := range []string{CategoryDial, CategoryRead, CategoryRead, CategoryHeartbeat, CategorySubscribe, CategoryParse, "unknown"} {
+		c.Add(cat)
+	}
+	if c.Dial != 1 || c.Read != 2 || c.Heartbeat != 1 || c.Subscribe != 1 {
+		t.Fatalf("Counts=%+v", c)
+	}
+}
+
+func TestSend_NonBlocking(t *testing.T) {
+	ch := make(chan error, 1)
+	e := New("okx", CategoryRead, errors.New("eof"))
+	if !Send(ch, e) {
+		t.Fatalf("首次投递应成功")
+	}
+	if Send(ch, e) {
+		t.Fatalf("通道已满时应丢弃")
+	}
+	got := <-ch
+	if got.Error() != "okx read: eof" {
+		t.Fatalf("Error()=%q", got.Error())
+	}
+	var ce *Error
+	if !errors.As(got, &ce) || ce.AtUnixNs <= 0 {
+		t.Fatalf("应可还原为 *Error 且带发生时间: %#v", got)
+	}
+}
+
+func TestMerge_ClosesAfterInputs(t *testing.T) {
+	a := make(chan error, 1)
+	b := make(chan error, 1)
+	out := Merge(context.Background(), a, b)
+
+	a <- New("okx", CategoryDial, errors.New("x"))
+	b <- New("bittap", CategoryRead, errors.New("y"))
+	close(a)
+	close(b)
+
+	n := 0
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				if n != 2 {
+					t.Fatalf("收到 %d 个错误, want 2", n)
+				}
+				return
+			}
+			n++
+		case <-timeout:
+			t.Fatalf("输出通道未关闭")
+		}
+	}
+}
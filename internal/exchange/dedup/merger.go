@@ -0,0 +1,147 @@
+// Package dedup 合并同一交易所多条冗余 WebSocket 连接的行情流。
+// 按交易对保留每个 Seq 的最早到达事件，使测得的 lead-lag 反映最优接入路径，
+// 而非单条连接的偶然快慢。
+package dedup
+
+import (
+	"context"
+	"sync"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// Stats 合并统计
+type Stats struct {
+	// Forwarded 转发事件数
+	Forwarded int64 `json:"forwarded"`
+	// Duplicates 丢弃的重复/过期事件数（Seq 不大于已转发的最新 Seq，且未判定为重置）
+	Duplicates int64 `json:"duplicates"`
+	// SeqResets 识别到的 Seq 重置次数（Seq 回落到不足已转发最大值一半）
+	SeqResets int64 `json:"seq_resets,omitempty"`
+	// FirstBySource 各连接抢先到达（被转发）的事件数，下标与输入顺序一致
+	FirstBySource []int64 `json:"first_by_source"`
+}
+
+// Merger 多连接行情合并器
+// 各连接在自己的读取 goroutine 内串行完成去重与转发，事件不经额外通道中转。
+// Seq<=0 的事件无法去重，直接转发。
+// Seq 回落到不足已转发最大值一半时视为交易所侧序列重置（如服务端重启），接受新事件（与 store.Store 规则一致）。
+type Merger struct {
+	inputs []<-chan *model.BookEvent
+	out    chan *model.BookEvent
+
+	// fwd 串行化去重判定与转发，保证输出顺序与判定顺序一致
+	fwd sync.Mutex
+	// lastSeq 每个交易对已转发的最大 Seq（持有 fwd 时访问）
+	lastSeq map[string]int64
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// NewMerger 创建合并器
+// 参数 bufSize: 输出通道容量
+// 参数 inputs: 各连接的订单簿事件通道（同一交易所）
+func NewMerger(bufSize int, inputs ...<-chan *model.BookEvent) *Merger {
+	return &Merger{
+		inputs:  inputs,
+		out:     make(chan *model.BookEvent, bufSize),
+		lastSeq: make(map[string]int64),
+		stats:   Stats{FirstBySource: make([]int64, len(inputs))},
+	}
+}
+
+// Out 获取合并后的事件通道（所有输入关闭或 ctx 取消后关闭）
+func (m *Merger) Out() <-chan *model.BookEvent {
+	return m.out
+}
+
+// Stats 获取合并统计快照
+func (m *Merger) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := m.stats
+	out.FirstBySource = append([]int64(nil), m.stats.FirstBySource...)
+	return out
+}
+
+// Run 启动合并循环，直到 ctx 取消或所有输入关闭
+func (m *Merger) Run(ctx context.Context) {
+	defer close(m.out)
+
+	var wg sync.WaitGroup
+	for i, in := range m.inputs {
+		wg.Add(1)
+		go func(src int, in <-chan *model.BookEvent) {
+			defer wg.Done()
+			m.read(ctx, src, in)
+		}(i, in)
+	}
+	wg.Wait()
+}
+
+// read 读取单条连接的事件并就地去重转发，直到 ctx 取消或输入关闭
+func (m *Merger) read(ctx context.Context, src int, in <-chan *model.BookEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-in:
+			if !ok {
+				return
+			}
+			if !m.forward(ctx, src, ev) {
+				return
+			}
+		}
+	}
+}
+
+// forward 去重后转发事件
+// 返回: ctx 取消时为 false
+func (m *Merger) forward(ctx context.Context, src int, ev *model.BookEvent) bool {
+	m.fwd.Lock()
+	defer m.fwd.Unlock()
+	if !m.accept(src, ev) {
+		// 重复事件来自另一条连接的独立解析，未转发给任何消费者
+		ev.Release()
+		return true
+	}
+	select {
+	case m.out <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// accept 判断事件是否为该交易对的新 Seq，并更新统计（调用方持有 fwd）
+func (m *Merger) accept(src int, ev *model.BookEvent) bool {
+	if ev == nil {
+		return false
+	}
+
+	var reset bool
+	if ev.Seq > 0 {
+		last := m.lastSeq[ev.SymbolCanon]
+		if ev.Seq <= last {
+			if ev.Seq >= last/2 {
+				m.mu.Lock()
+				m.stats.Duplicates++
+				m.mu.Unlock()
+				return false
+			}
+			reset = true
+		}
+		m.lastSeq[ev.SymbolCanon] = ev.Seq
+	}
+
+	m.mu.Lock()
+	m.stats.Forwarded++
+	m.stats.FirstBySource[src]++
+	if reset {
+		m.stats.SeqResets++
+	}
+	m.mu.Unlock()
+	return true
+}
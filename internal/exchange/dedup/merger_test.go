@@ -0,0 +1,118 @@
+// Package dedup 多连接合并测试
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestMerger_DropsDuplicateAndStaleSeq(t *testing.T) {
+	a := make(chan *model.BookEvent, 8)
+	b := make(chan *model.BookEvent, 8)
+	m := NewMerger(16, a, b)
+
+	// 输入 a 先送达，再关闭 a 后送入 b，保证到达顺序确定
+	a <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 1}
+	a <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 2}
+	a <- &model.BookEvent{SymbolCanon: "ETH-USDT", Seq: 1}
+	close(a)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go m.Run(ctx)
+
+	var got []*model.BookEvent
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-m.Out():
+			got = append(got, ev)
+		case <-ctx.Done():
+			t.Fatalf("等待 a 的事件超时")
+		}
+	}
+
+	b <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 2} // 重复
+	b <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 1} // 过期
+	b <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 0} // 无 Seq，直接转发
+	b <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 3}
+	close(b)
+
+	for ev := range m.Out() {
+		got = append(got, ev)
+	}
+
+	wantSeq := []int64{1, 2, 1, 0, 3}
+	if len(got) != len(wantSeq) {
+		t.Fatalf("转发 %d 条, want %d", len(got), len(wantSeq))
+	}
+	for i, ev := range got {
+		if ev.Seq != wantSeq[i] {
+			t.Fatalf("第 %d 条 Seq=%d, want %d", i, ev.Seq, wantSeq[i])
+		}
+	}
+
+	st := m.Stats()
+	if st.Forwarded != 5 || st.Duplicates != 2 {
+		t.Fatalf("Forwarded=%d Duplicates=%d, want 5/2", st.Forwarded, st.Duplicates)
+	}
+	if st.FirstBySource[0] != 3 || st.FirstBySource[1] != 2 {
+		t.Fatalf("FirstBySource=%v, want [3 2]", st.FirstBySource)
+	}
+}
+
+func TestMerger_AcceptsSeqReset(t *testing.T) {
+	a := make(chan *model.BookEvent, 8)
+	m := NewMerger(16, a)
+
+	a <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 1000}
+	a <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 600} // 不低于一半：过期
+	a <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 10}  // 回落到不足一半：重置
+	a <- &model.BookEvent{SymbolCanon: "BTC-USDT", Seq: 11}
+	close(a)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go m.Run(ctx)
+
+	var got []int64
+	for ev := range m.Out() {
+		got = append(got, ev.Seq)
+	}
+	want := []int64{1000, 10, 11}
+	if len(got) != len(want) {
+		t.Fatalf("转发 Seq=%v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("转发 Seq=%v, want %v", got, want)
+		}
+	}
+	if st := m.Stats(); st.Duplicates != 1 || st.SeqResets != 1 {
+		t.Fatalf("Duplicates=%d SeqResets=%d, want 1/1", st.Duplicates, st.SeqResets)
+	}
+}
+
+func TestMerger_ClosesOnCancel(t *testing.T) {
+	a := make(chan *model.BookEvent)
+	m := NewMerger(1, a)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ctx 取消后 Run 未退出")
+	}
+	if _, ok := <-m.Out(); ok {
+		t.Fatalf("Out 应已关闭")
+	}
+}
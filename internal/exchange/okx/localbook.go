@@ -0,0 +1,128 @@
+package okx
+
+import (
+	"fmt"
+	"sort"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/util/fastparse"
+)
+
+// 行情频道
+const (
+	// ChannelBooks5 5 档全量快照（默认）
+	ChannelBooks5 = "books5"
+	// ChannelBooks 400 档 快照 + 增量（100ms 推送）
+	// 逐笔的 books-l2-tbt / books50-l2-tbt 需 API Key 登录，AGENTS.md §1.1 禁止私有 WS 登录与签名，不予支持。
+	ChannelBooks = "books"
+)
+
+// isIncremental 频道是否为 快照 + 增量 推送（需维护本地订单簿）
+func isIncremental(channel string) bool {
+	return channel == ChannelBooks
+}
+
+// localBook 单交易对本地订单簿（由 books 增量频道维护）
+// 订阅后首条推送 action=snapshot 为全量，之后 action=update 为增量；
+// 每条增量的 prevSeqId 必须等于上一条的 seqId，否则断档，需重新订阅以获取新快照。
+// 数量为 0 的档位表示删除。非并发安全，仅由读循环访问。
+type localBook struct {
+	// bids 买盘（价格降序）
+	bids []model.Level
+	// asks 卖盘（价格升序）
+	asks []model.Level
+	// seq 最近应用的 seqId
+	seq int64
+	// synced 是否已收到快照（断档后置 false，等待重新订阅的快照）
+	synced bool
+}
+
+// apply 应用一条快照或增量推送
+// 返回: 是否已更新（未同步时忽略增量）与断档错误（调用方应重新订阅）
+func (b *localBook) apply(action string, d *Books5Data) (bool, error) {
+	if action == "snapshot" {
+		b.bids = b.bids[:0]
+		b.asks = b.asks[:0]
+		b.applyLevels(d)
+		b.seq = d.SeqId
+		b.synced = true
+		return true, nil
+	}
+	if !b.synced {
+		return false, nil
+	}
+	if d.PrevSeqId != b.seq {
+		b.synced = false
+		return false, fmt.Errorf("增量推送断档: prevSeqId=%d, 期望 %d", d.PrevSeqId, b.seq)
+	}
+	b.applyLevels(d)
+	b.seq = d.SeqId
+	return true, nil
+}
+
+// applyLevels 合并推送中的档位变化（数量为 0 表示删除；格式错误的档位跳过）
+func (b *localBook) applyLevels(d *Books5Data) {
+	_ = d.Bids.ForEach(func(pxb, qtyb []byte) bool {
+		if px, qty, ok := parseLevel(pxb, qtyb); ok {
+			b.bids = upsertLevel(b.bids, px, qty, true)
+		}
+		return true
+	})
+	_ = d.Asks.ForEach(func(pxb, qtyb []byte) bool {
+		if px, qty, ok := parseLevel(pxb, qtyb); ok {
+			b.asks = upsertLevel(b.asks, px, qty, false)
+		}
+		return true
+	})
+}
+
+// fill 以本地订单簿前 depth 档填充事件
+func (b *localBook) fill(ev *model.BookEvent, depth int) {
+	if len(b.bids) > 0 {
+		ev.BestBidPx, ev.BestBidQty = b.bids[0].Price, b.bids[0].Qty
+	}
+	if len(b.asks) > 0 {
+		ev.BestAskPx, ev.BestAskQty = b.asks[0].Price, b.asks[0].Qty
+	}
+	nb, na := min(depth, len(b.bids)), min(depth, len(b.asks))
+	ev.Bids = append(ev.Bids[:0], b.bids[:nb]...)
+	ev.Asks = append(ev.Asks[:0], b.asks[:na]...)
+}
+
+// upsertLevel 在有序档位中插入/更新/删除价格档
+// 参数 desc: true 表示价格降序（买盘）
+func upsertLevel(levels []model.Level, px, qty float64, desc bool) []model.Level {
+	i := sort.Search(len(levels), func(i int) bool {
+		if desc {
+			return levels[i].Price <= px
+		}
+		return levels[i].Price >= px
+	})
+	found := i < len(levels) && levels[i].Price == px
+	switch {
+	case qty == 0 && found:
+		return append(levels[:i], levels[i+1:]...)
+	case qty == 0:
+		return levels
+	case found:
+		levels[i].Qty = qty
+		return levels
+	default:
+		levels = append(levels, model.Level{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = model.Level{Price: px, Qty: qty}
+		return levels
+	}
+}
+
+func parseLevel(pxb, qtyb []byte) (px, qty float64, ok bool) {
+	px, err := fastparse.ParseFloatBytes(pxb)
+	if err != nil || px <= 0 {
+		return 0, 0, false
+	}
+	qty, err = fastparse.ParseFloatBytes(qtyb)
+	if err != nil {
+		return 0, 0, false
+	}
+	return px, qty, true
+}
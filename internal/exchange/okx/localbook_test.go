@@ -0,0 +1,91 @@
+// Package okx 增量频道本地订单簿测试
+package okx
+
+import (
+	"strconv"
+	"testing"
+)
+
+func l2Msg(action string, seq, prev int64, bids, asks string) []byte {
+	return []byte(`{"arg":{"channel":"books","instId":"BTC-USDT-SWAP"},"action":"` + action + `",` +
+		`"data":[{"instId":"BTC-USDT-SWAP","asks":[` + asks + `],"bids":[` + bids + `],"ts":"1000",` +
+		`"seqId":` + strconv.FormatInt(seq, 10) + `,"prevSeqId":` + strconv.FormatInt(prev, 10) + `}]}`)
+}
+
+// TestParser_Incremental 测试快照 + 增量合并、删除档位与断档重订阅
+func TestParser_Incremental(t *testing.T) {
+	parser := NewParser(createTestSymbolMaps())
+	parser.channel = ChannelBooks
+
+	// 快照前的增量忽略
+	events, err := parser.Parse(l2Msg("update", 5, 4, `["99","1","0","1"]`, ``), 1)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("未同步时增量应忽略: %v, %v", events, err)
+	}
+
+	snap := l2Msg("snapshot", 10, -1,
+		`["100","2","0","1"],["99","3","0","1"]`, `["101","1","0","1"],["102","4","0","1"]`)
+	events, err = parser.Parse(snap, 1)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("快照应产生 1 个事件: %v, %v", events, err)
+	}
+	ev := events[0]
+	if ev.BestBidPx != 100 || ev.BestAskPx != 101 || ev.Seq != 10 || len(ev.Bids) != 2 {
+		t.Errorf("快照盘口不符: %+v", ev)
+	}
+
+	// 删除最优卖价、新增更优买价
+	events, err = parser.Parse(l2Msg("update", 11, 10, `["100.5","1","0","1"]`, `["101","0","0","0"]`), 2)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("增量应产生 1 个事件: %v, %v", events, err)
+	}
+	ev = events[0]
+	if ev.BestBidPx != 100.5 || ev.BestAskPx != 102 || ev.BestAskQty != 4 || len(ev.Bids) != 3 || len(ev.Asks) != 1 {
+		t.Errorf("增量合并不符: %+v", ev)
+	}
+
+	// 断档：丢弃推送并记录待重新订阅
+	events, err = parser.Parse(l2Msg("update", 14, 13, `["100.6","1","0","1"]`, ``), 3)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("断档推送应丢弃: %v, %v", events, err)
+	}
+	if got := parser.takeResync(); len(got) != 1 || got[0] != "BTCUSDT" {
+		t.Fatalf("断档交易对应待重新订阅: %v", got)
+	}
+	if got := parser.takeResync(); len(got) != 0 {
+		t.Errorf("takeResync 应清空列表: %v", got)
+	}
+	// 新快照到达前的增量忽略，且不重复请求重订阅
+	events, _ = parser.Parse(l2Msg("update", 15, 14, `["100.7","1","0","1"]`, ``), 4)
+	if len(events) != 0 || len(parser.takeResync()) != 0 {
+		t.Errorf("等待快照期间增量应忽略: %v", events)
+	}
+	events, _ = parser.Parse(snap, 5)
+	if len(events) != 1 || events[0].BestBidPx != 100 {
+		t.Errorf("新快照应恢复同步: %v", events)
+	}
+}
+
+// TestParser_IncrementalDepth 测试输出档位按 depth 截取
+func TestParser_IncrementalDepth(t *testing.T) {
+	parser := NewParser(createTestSymbolMaps())
+	parser.channel = ChannelBooks
+	parser.depth = 1
+
+	msg := []byte(`{"arg":{"channel":"books","instId":"BTC-USDT-SWAP"},"action":"snapshot",` +
+		`"data":[{"instId":"BTC-USDT-SWAP","asks":[["101","1","0","1"],["102","1","0","1"]],` +
+		`"bids":[["100","2","0","1"],["99","1","0","1"]],"ts":"1000","seqId":1,"prevSeqId":-1}]}`)
+	events, err := parser.Parse(msg, 1)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("快照应产生 1 个事件: %v, %v", events, err)
+	}
+	if len(events[0].Bids) != 1 || len(events[0].Asks) != 1 {
+		t.Errorf("档位应截取为 1: %+v", events[0])
+	}
+	// books5 消息在增量频道模式下忽略
+	books5 := `{"arg":{"channel":"books5","instId":"BTC-USDT-SWAP"},"data":[{"instId":"BTC-USDT-SWAP",` +
+		`"asks":[["101","1","0","1"]],"bids":[["100","2","0","1"]],"ts":"1000","seqId":7}]}`
+	if events, _ := parser.Parse([]byte(books5), 1); len(events) != 0 {
+		t.Errorf("非订阅频道应忽略: %v", events)
+	}
+}
@@ -1,64 +1,113 @@
 // Package okx 实现 OKX 交易所消息解析。
 // 字段映射: ts -> ExchTsUnixMs, seqId -> Seq
+// books5 每条推送为全量 5 档；books 频道为 快照 + 增量，由本地订单簿合并后输出。
 package okx
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
+	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/core/model"
 	"latency-arbitrage-validator/internal/metadata"
+	"latency-arbitrage-validator/internal/stats/channelcmp"
 	"latency-arbitrage-validator/internal/util/fastparse"
-	"latency-arbitrage-validator/internal/util/timeutil"
+	"latency-arbitrage-validator/internal/ws"
 )
 
 // Parser OKX 消息解析器
 type Parser struct {
-	// symbolMaps Symbol 映射表，用于将 instId 转换为 Canon
-	symbolMaps map[string]*metadata.SymbolMap
+	// symbols Symbol 映射表，用于将 instId 转换为 Canon（退订时移除）
+	symbols *metadata.Table
+	// depth 解析并保存的单侧档位数（ws.okx.depth_levels）
+	depth int
+	// channel 订阅的行情频道（ws.okx.channel，默认 books5）
+	channel string
+	// books 增量频道的本地订单簿（key 为 Canon，仅读循环访问）
+	books map[string]*localBook
+	// resync 增量断档、需重新订阅的交易对（由客户端在每条消息处理后取走）
+	resync []string
+	// compare 对比频道名称（ws.okx.compare_channel，为空表示不对比）
+	compare string
+	// cmp 频道到达时延对比（compare 非空时设置）
+	cmp *channelcmp.Tracker
 }
 
 // NewParser 创建 OKX 消息解析器
 // 参数 symbolMaps: Symbol 映射表
 func NewParser(symbolMaps map[string]*metadata.SymbolMap) *Parser {
 	return &Parser{
-		symbolMaps: symbolMaps,
+		symbols: metadata.NewTable(symbolMaps),
+		depth:   config.DefaultDepthLevels,
+		channel: ChannelBooks5,
+		books:   make(map[string]*localBook),
 	}
 }
 
 // Parse 解析 OKX WebSocket 消息
 // 参数 data: 原始消息字节
+// 参数 arrivedAt: 消息从 socket 读出时的本地时间（纳秒），由读循环在 ReadMessage 返回后立即采集
 // 返回: BookEvent 列表（一条消息可能包含多个数据）
-func (p *Parser) Parse(data []byte) ([]*model.BookEvent, error) {
-	// 记录到达时间（纳秒）
-	arrivedAt := timeutil.NowNano()
-
+func (p *Parser) Parse(data []byte, arrivedAt int64) ([]*model.BookEvent, error) {
 	// 尝试解析为 books5 消息
 	var msg Books5Message
 	if err := json.Unmarshal(data, &msg); err != nil {
 		return nil, fmt.Errorf("解析 OKX 消息失败: %w", err)
 	}
 
-	// 检查是否为 books5 数据
-	if msg.Arg.Channel != "books5" || len(msg.Data) == 0 {
-		return nil, nil // 非 books5 消息，忽略
+	if p.cmp != nil && msg.Arg.Channel == p.compare {
+		p.observeCompare(msg.Data, arrivedAt)
+		return nil, nil // 对比频道只参与统计，不进入策略
+	}
+
+	// 检查是否为订阅频道数据
+	if msg.Arg.Channel != p.channel || len(msg.Data) == 0 {
+		return nil, nil // 非订阅频道消息，忽略
 	}
 
 	// 解析每条数据
 	events := make([]*model.BookEvent, 0, len(msg.Data))
-	for _, d := range msg.Data {
-		event, err := p.parseBooks5Data(&d, arrivedAt)
+	for i := range msg.Data {
+		var event *model.BookEvent
+		var err error
+		if isIncremental(p.channel) {
+			event = p.parseIncremental(msg.Action, &msg.Data[i], arrivedAt)
+		} else {
+			event, err = p.parseBooks5Data(&msg.Data[i], arrivedAt)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("解析 books5 数据失败: %w", err)
+			return nil, fmt.Errorf("解析 %s 数据失败: %w", p.channel, err)
 		}
 		if event != nil {
 			events = append(events, event)
+			if p.cmp != nil {
+				p.cmp.Observe(channelcmp.RoleCanonical, event.SymbolCanon, event.ExchTsUnixMs, event.BestBidPx, event.BestAskPx, arrivedAt)
+			}
 		}
 	}
 
 	return events, nil
 }
 
+// observeCompare 将对比频道推送的最优价计入频道对比（解析失败的条目忽略）
+func (p *Parser) observeCompare(data []Books5Data, arrivedAt int64) {
+	for i := range data {
+		d := &data[i]
+		canon := p.findCanon(d.InstId)
+		if canon == "" {
+			continue
+		}
+		exchTs, _ := fastparse.ParseInt(d.Ts)
+		var bid, ask float64
+		if d.Bids.Top(1, func(px, _ float64) { bid = px }) != nil || d.Asks.Top(1, func(px, _ float64) { ask = px }) != nil {
+			continue
+		}
+		p.cmp.Observe(channelcmp.RoleCompare, canon, exchTs, bid, ask, arrivedAt)
+	}
+}
+
 // parseBooks5Data 解析单条 books5 数据
 // 参数 d: books5 数据
 // 参数 arrivedAt: 到达时间（纳秒）
@@ -79,61 +128,95 @@ func (p *Parser) parseBooks5Data(d *Books5Data, arrivedAt int64) (*model.BookEve
 
 	// 解析买卖盘
 	// OKX bids/asks 格式: [[价格, 数量, 废弃, 订单数], ...]
-	var bestBidPx, bestBidQty, bestAskPx, bestAskQty float64
-	var levels []model.Level
-
-	// 解析买盘（bids）
-	if len(d.Bids) > 0 {
-		bestBidPx, _ = fastparse.ParseFloat(d.Bids[0][0])
-		bestBidQty, _ = fastparse.ParseFloat(d.Bids[0][1])
+	ev := model.AcquireBookEvent()
+	ev.Exchange = model.ExchangeOKX
+	ev.SymbolCanon = canon
+	ev.ArrivedAtUnixNs = arrivedAt
+	ev.ExchTsUnixMs = exchTs
+	ev.Seq = d.SeqId
+	if err := fillTopLevels(ev, d.Bids, d.Asks, p.depth); err != nil {
+		ev.Release()
+		return nil, err
+	}
+	return ev, nil
+}
 
-		for i, bid := range d.Bids {
-			if i >= 5 {
-				break
-			}
-			px, _ := fastparse.ParseFloat(bid[0])
-			qty, _ := fastparse.ParseFloat(bid[1])
-			levels = append(levels, model.Level{Price: px, Qty: qty})
-		}
+// parseIncremental 将一条快照/增量推送合并到本地订单簿并输出合并后的盘口
+// 断档时记录待重新订阅的交易对并丢弃该推送；未同步（等待新快照）期间的增量忽略。
+func (p *Parser) parseIncremental(action string, d *Books5Data, arrivedAt int64) *model.BookEvent {
+	canon := p.findCanon(d.InstId)
+	if canon == "" {
+		return nil
+	}
+	b := p.books[canon]
+	if b == nil {
+		b = &localBook{}
+		p.books[canon] = b
+	}
+	updated, err := b.apply(action, d)
+	if err != nil {
+		p.resync = append(p.resync, canon)
+		return nil
+	}
+	if !updated {
+		return nil
+	}
+	exchTs, err := fastparse.ParseInt(d.Ts)
+	if err != nil {
+		exchTs = 0
 	}
+	ev := model.AcquireBookEvent()
+	ev.Exchange = model.ExchangeOKX
+	ev.SymbolCanon = canon
+	ev.ArrivedAtUnixNs = arrivedAt
+	ev.ExchTsUnixMs = exchTs
+	ev.Seq = d.SeqId
+	b.fill(ev, p.depth)
+	return ev
+}
 
-	// 解析卖盘（asks）
-	if len(d.Asks) > 0 {
-		bestAskPx, _ = fastparse.ParseFloat(d.Asks[0][0])
-		bestAskQty, _ = fastparse.ParseFloat(d.Asks[0][1])
+// takeResync 取走断档待重新订阅的交易对
+func (p *Parser) takeResync() []string {
+	canons := p.resync
+	p.resync = nil
+	return canons
+}
 
-		for i, ask := range d.Asks {
-			if i >= 5 {
-				break
-			}
-			px, _ := fastparse.ParseFloat(ask[0])
-			qty, _ := fastparse.ParseFloat(ask[1])
-			levels = append(levels, model.Level{Price: px, Qty: qty})
-		}
-	}
+// resetBooks 清空全部本地订单簿（重连后等待新快照）
+func (p *Parser) resetBooks() {
+	p.books = make(map[string]*localBook)
+	p.resync = nil
+}
 
-	return &model.BookEvent{
-		Exchange:        model.ExchangeOKX,
-		SymbolCanon:     canon,
-		BestBidPx:       bestBidPx,
-		BestBidQty:      bestBidQty,
-		BestAskPx:       bestAskPx,
-		BestAskQty:      bestAskQty,
-		Levels:          levels,
-		ArrivedAtUnixNs: arrivedAt,
-		ExchTsUnixMs:    exchTs,
-		Seq:             d.SeqId,
-	}, nil
+// fillTopLevels 解析买卖盘前 depth 档写入事件，并以首档作为最优价量
+// 复用事件已有的档位容量（对象池事件无需重新分配）
+func fillTopLevels(ev *model.BookEvent, bids, asks fastparse.Levels, depth int) error {
+	ev.Bids, ev.Asks = ev.Bids[:0], ev.Asks[:0]
+	if err := bids.Top(depth, func(px, qty float64) {
+		ev.Bids = append(ev.Bids, model.Level{Price: px, Qty: qty})
+	}); err != nil {
+		return fmt.Errorf("解析 bids 失败: %w", err)
+	}
+	if err := asks.Top(depth, func(px, qty float64) {
+		ev.Asks = append(ev.Asks, model.Level{Price: px, Qty: qty})
+	}); err != nil {
+		return fmt.Errorf("解析 asks 失败: %w", err)
+	}
+	if len(ev.Bids) > 0 {
+		ev.BestBidPx, ev.BestBidQty = ev.Bids[0].Price, ev.Bids[0].Qty
+	}
+	if len(ev.Asks) > 0 {
+		ev.BestAskPx, ev.BestAskQty = ev.Asks[0].Price, ev.Asks[0].Qty
+	}
+	return nil
 }
 
 // findCanon 根据 instId 查找 Canon
 // 参数 instId: OKX 合约 ID，如 BTC-USDT-SWAP
 // 返回: Canon，如 BTCUSDT；未找到返回空字符串
 func (p *Parser) findCanon(instId string) string {
-	for _, m := range p.symbolMaps {
-		if m.OKXInstId == instId {
-			return m.Canon
-		}
+	if m, ok := p.symbols.ByOKXInstId(instId); ok {
+		return m.Canon
 	}
 	return ""
 }
@@ -147,6 +230,38 @@ func IsSubscribeResponse(data []byte) bool {
 	return resp.Event == "subscribe" || resp.Event == "error"
 }
 
+// eventMarker 订阅响应的字节特征（行情推送不含 event 字段）
+var eventMarker = []byte(`"event"`)
+
+// ParseAck 识别订阅响应（event=subscribe 为确认，event=error 为拒绝）
+// OKX 对请求中的每个订阅参数分别确认，确认中带回对应交易对；拒绝不带参数，视为整个请求失败。
+// 先做廉价字节匹配，避免每条行情消息多一次 JSON 解析。
+func (p *Parser) ParseAck(data []byte) (ws.Ack, bool) {
+	if !bytes.Contains(data, eventMarker) {
+		return ws.Ack{}, false
+	}
+	var resp SubscribeResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return ws.Ack{}, false
+	}
+	if resp.Event != "subscribe" && resp.Event != "error" {
+		return ws.Ack{}, false
+	}
+	id, _ := strconv.ParseInt(resp.ID, 10, 64)
+	ack := ws.Ack{ID: id, OK: resp.Event == "subscribe"}
+	if !ack.OK {
+		ack.Msg = resp.Code + " " + resp.Msg
+	} else if resp.Arg != nil {
+		// 未配置的 instId 原样返回，不会匹配任何待确认交易对
+		canon := p.findCanon(resp.Arg.InstId)
+		if canon == "" {
+			canon = resp.Arg.InstId
+		}
+		ack.Symbols = []string{canon}
+	}
+	return ack, true
+}
+
 // IsPong 判断是否为 pong 响应
 func IsPong(data []byte) bool {
 	return string(data) == "pong"
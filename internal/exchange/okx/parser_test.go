@@ -4,6 +4,8 @@ package okx
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/leanovate/gopter"
@@ -11,6 +13,9 @@ import (
 	"github.com/leanovate/gopter/prop"
 
 	"latency-arbitrage-validator/internal/metadata"
+	"latency-arbitrage-validator/internal/stats/channelcmp"
+	"latency-arbitrage-validator/internal/testkit"
+	"latency-arbitrage-validator/internal/util/fastparse"
 )
 
 // **Feature: latency-arbitrage-validator, Property 1: Parser Round-Trip Consistency (OKX)**
@@ -58,8 +63,8 @@ func TestParser_RoundTrip(t *testing.T) {
 				Data: []Books5Data{
 					{
 						InstId: "BTC-USDT-SWAP",
-						Bids:   [][]string{{fmt.Sprintf("%.2f", bidPx), fmt.Sprintf("%.4f", bidQty), "0", "1"}},
-						Asks:   [][]string{{fmt.Sprintf("%.2f", askPx), fmt.Sprintf("%.4f", askQty), "0", "1"}},
+						Bids:   fastparse.LevelsOf([][]string{{fmt.Sprintf("%.2f", bidPx), fmt.Sprintf("%.4f", bidQty), "0", "1"}}),
+						Asks:   fastparse.LevelsOf([][]string{{fmt.Sprintf("%.2f", askPx), fmt.Sprintf("%.4f", askQty), "0", "1"}}),
 						Ts:     fmt.Sprintf("%d", ts),
 						SeqId:  seqId,
 					},
@@ -73,7 +78,7 @@ func TestParser_RoundTrip(t *testing.T) {
 			}
 
 			// 解析
-			events, err := parser.Parse(data)
+			events, err := parser.Parse(data, 1)
 			if err != nil {
 				return false
 			}
@@ -162,7 +167,7 @@ func TestParser_SpecificMessages(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			events, err := parser.Parse([]byte(tt.message))
+			events, err := parser.Parse([]byte(tt.message), 1_700_000_000_123_456_789)
 			if err != nil {
 				t.Fatalf("解析失败: %v", err)
 			}
@@ -173,6 +178,9 @@ func TestParser_SpecificMessages(t *testing.T) {
 
 			if tt.wantEvents > 0 {
 				event := events[0]
+				if event.ArrivedAtUnixNs != 1_700_000_000_123_456_789 {
+					t.Errorf("ArrivedAtUnixNs = %d, want 读循环传入值", event.ArrivedAtUnixNs)
+				}
 				if event.SymbolCanon != tt.wantCanon {
 					t.Errorf("SymbolCanon = %s, want %s", event.SymbolCanon, tt.wantCanon)
 				}
@@ -222,7 +230,7 @@ func TestParser_InvalidMessages(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := parser.Parse([]byte(tt.message))
+			_, err := parser.Parse([]byte(tt.message), 1)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -249,6 +257,47 @@ func TestIsPong(t *testing.T) {
 	}
 }
 
+// TestParser_CompareChannel 测试对比频道只计入频道对比、不产生行情事件
+func TestParser_CompareChannel(t *testing.T) {
+	parser := NewParser(createTestSymbolMaps())
+	parser.compare = "bbo-tbt"
+	parser.cmp = channelcmp.NewTracker("books5", "bbo-tbt")
+
+	bbo := `{"arg":{"channel":"bbo-tbt","instId":"BTC-USDT-SWAP"},"data":[{"instId":"BTC-USDT-SWAP",` +
+		`"asks":[["101","1","0","1"]],"bids":[["100","2","0","1"]],"ts":"1000","seqId":7}]}`
+	events, err := parser.Parse([]byte(bbo), 1003_000_000)
+	if err != nil || events != nil {
+		t.Fatalf("对比频道不应产生事件: %v, %v", events, err)
+	}
+
+	books := `{"arg":{"channel":"books5","instId":"BTC-USDT-SWAP"},"data":[{"instId":"BTC-USDT-SWAP",` +
+		`"asks":[["101","1","0","1"],["102","1","0","1"]],"bids":[["100","2","0","1"]],"ts":"1000","seqId":7}]}`
+	events, err = parser.Parse([]byte(books), 1008_000_000)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("books5 应产生 1 个事件: %v, %v", events, err)
+	}
+
+	st := parser.cmp.Stats()
+	if st.Matched != 1 || st.DiffP50Ms != 5 || st.CompareFirstShare != 1 {
+		t.Errorf("频道对比统计不符: %+v", st)
+	}
+}
+
+// TestBuildFrame_CompareChannel 测试对比频道与 books5 在同一请求中订阅
+func TestBuildFrame_CompareChannel(t *testing.T) {
+	frame, err := buildFrame("subscribe", 1, []string{"books5", "bbo-tbt"}, createTestSymbolMaps(), []string{"BTCUSDT"})
+	if err != nil {
+		t.Fatalf("buildFrame: %v", err)
+	}
+	var req SubscribeRequest
+	if err := json.Unmarshal(frame, &req); err != nil {
+		t.Fatalf("解析订阅帧失败: %v", err)
+	}
+	if len(req.Args) != 2 || req.Args[0].Channel != "books5" || req.Args[1].Channel != "bbo-tbt" || req.Args[1].InstId != "BTC-USDT-SWAP" {
+		t.Errorf("订阅参数不符: %+v", req.Args)
+	}
+}
+
 // TestIsSubscribeResponse 测试订阅响应判断
 func TestIsSubscribeResponse(t *testing.T) {
 	tests := []struct {
@@ -268,3 +317,56 @@ func TestIsSubscribeResponse(t *testing.T) {
 		}
 	}
 }
+
+// TestParser_ParseAck 测试订阅确认识别（确认带回对应交易对）
+func TestParser_ParseAck(t *testing.T) {
+	parser := NewParser(createTestSymbolMaps())
+	tests := []struct {
+		data        string
+		isAck       bool
+		wantID      int64
+		wantOK      bool
+		wantSymbols string
+	}{
+		{`{"id":"3","event":"subscribe","arg":{"channel":"books5","instId":"BTC-USDT-SWAP"},"connId":"a4d3ae55"}`, true, 3, true, "BTCUSDT"},
+		{`{"id":"3","event":"subscribe","arg":{"channel":"books5","instId":"XYZ-USDT-SWAP"},"connId":"a4d3ae55"}`, true, 3, true, "XYZ-USDT-SWAP"},
+		{`{"id":"4","event":"error","code":"60018","msg":"Wrong URL or channel","connId":"a4d3ae55"}`, true, 4, false, ""},
+		{`{"arg":{"channel":"books5","instId":"BTC-USDT-SWAP"},"data":[]}`, false, 0, false, ""},
+		{`pong`, false, 0, false, ""},
+	}
+
+	for _, tt := range tests {
+		ack, ok := parser.ParseAck([]byte(tt.data))
+		if ok != tt.isAck || ack.ID != tt.wantID || ack.OK != tt.wantOK || strings.Join(ack.Symbols, ",") != tt.wantSymbols {
+			t.Errorf("ParseAck(%q) = %+v, %v", tt.data, ack, ok)
+		}
+	}
+}
+
+// BenchmarkParser_Parse 解析 books5 推送并在处理完后归还事件（对象池热路径）
+func BenchmarkParser_Parse(b *testing.B) {
+	parser := NewParser(createTestSymbolMaps())
+	data := []byte(`{"arg":{"channel":"books5","instId":"BTC-USDT-SWAP"},"data":[{"instId":"BTC-USDT-SWAP",` +
+		`"bids":[["50000.5","1.5","0","3"],["50000.4","2","0","1"],["50000.3","3","0","2"],["50000.2","4","0","1"],["50000.1","5","0","1"]],` +
+		`"asks":[["50001","2","0","5"],["50001.1","1","0","1"],["50001.2","3","0","2"],["50001.3","4","0","1"],["50001.4","5","0","1"]],` +
+		`"ts":"1700000000000","seqId":12345}]}`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		events, err := parser.Parse(data, 1)
+		if err != nil || len(events) != 1 {
+			b.Fatalf("Parse: %v, %d events", err, len(events))
+		}
+		events[0].Release()
+	}
+}
+
+// TestParser_Golden 回归样本：testdata/golden_<频道>.jsonl 的帧按顺序送入订阅该频道的解析器，输出须与录制结果逐字段一致
+func TestParser_Golden(t *testing.T) {
+	for _, channel := range []string{ChannelBooks5, ChannelBooks} {
+		t.Run(channel, func(t *testing.T) {
+			parser := NewParser(createTestSymbolMaps())
+			parser.channel = channel
+			testkit.RunGolden(t, filepath.Join("testdata", "golden_"+channel+".jsonl"), parser.Parse)
+		})
+	}
+}
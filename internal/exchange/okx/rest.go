@@ -0,0 +1,63 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// newRESTPoller 创建基于 REST /api/v5/market/books 的深度拉取函数（公共行情接口，无需签名）
+// 仅在 WS 长时间断线时由 ws.Manager 低频调用。
+// 参数 baseURL: 深度接口地址
+func (c *Client) newRESTPoller(baseURL string) func(ctx context.Context, canon string) (*model.BookEvent, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	return func(ctx context.Context, canon string) (*model.BookEvent, error) {
+		m, ok := c.parser.symbols.Get(canon)
+		if !ok {
+			return nil, nil
+		}
+		q := url.Values{}
+		q.Set("instId", m.OKXInstId)
+		q.Set("sz", "5")
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"?"+q.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("创建深度请求失败: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("请求 OKX 深度失败: %w", err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		arrivedAt := timeutil.NowNano()
+		if err != nil {
+			return nil, fmt.Errorf("读取 OKX 深度失败: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("OKX 深度 HTTP 状态码: %d", resp.StatusCode)
+		}
+
+		var books BooksResponse
+		if err := json.Unmarshal(body, &books); err != nil {
+			return nil, fmt.Errorf("解析 OKX 深度失败: %w", err)
+		}
+		if books.Code != "0" {
+			return nil, fmt.Errorf("OKX 深度接口返回错误: code=%s, msg=%s", books.Code, books.Msg)
+		}
+		if len(books.Data) == 0 {
+			return nil, nil
+		}
+		d := books.Data[0]
+		d.InstId = m.OKXInstId
+		return c.parser.parseBooks5Data(&d, arrivedAt)
+	}
+}
@@ -0,0 +1,38 @@
+// Package okx REST 轮询降级测试
+package okx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+func TestRESTPoller(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("instId") != "BTC-USDT-SWAP" || r.URL.Query().Get("sz") != "5" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":"0","msg":"","data":[{"asks":[["41006.8","0.6","0","1"]],"bids":[["41006.3","0.3","0","1"]],"ts":"1700000000000"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(&config.ExchangeWSConfig{URL: "ws://unused"}, createTestSymbolMaps(), zap.NewNop())
+	poll := c.newRESTPoller(srv.URL)
+
+	ev, err := poll(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("轮询失败: %v", err)
+	}
+	if ev == nil || ev.SymbolCanon != "BTCUSDT" || ev.BestBidPx != 41006.3 || ev.BestAskPx != 41006.8 || ev.ExchTsUnixMs != 1700000000000 || ev.ArrivedAtUnixNs == 0 {
+		t.Fatalf("事件错误: %+v", ev)
+	}
+	if ev, err := poll(context.Background(), "XYZUSDT"); ev != nil || err != nil {
+		t.Fatalf("未配置交易对应忽略: %+v %v", ev, err)
+	}
+}
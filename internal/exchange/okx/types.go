@@ -1,9 +1,16 @@
 // Package okx 定义 OKX 交易所消息类型。
 package okx
 
+import (
+	"latency-arbitrage-validator/internal/util/fastparse"
+	"latency-arbitrage-validator/internal/ws"
+)
+
 // SubscribeRequest OKX 订阅请求
 // 用于订阅 books5 频道
 type SubscribeRequest struct {
+	// ID 请求 ID（可选，原样出现在响应中，用于匹配确认）
+	ID string `json:"id,omitempty"`
 	// Op 操作类型: subscribe, unsubscribe
 	Op string `json:"op"`
 	// Args 订阅参数列表
@@ -12,7 +19,7 @@ type SubscribeRequest struct {
 
 // SubscribeArg 订阅参数
 type SubscribeArg struct {
-	// Channel 频道名称: books5
+	// Channel 频道名称: books5（或对比频道 bbo-tbt）
 	Channel string `json:"channel"`
 	// InstId 合约 ID: BTC-USDT-SWAP
 	InstId string `json:"instId"`
@@ -20,6 +27,8 @@ type SubscribeArg struct {
 
 // SubscribeResponse OKX 订阅响应
 type SubscribeResponse struct {
+	// ID 对应请求的 ID
+	ID string `json:"id,omitempty"`
 	// Event 事件类型: subscribe, error
 	Event string `json:"event"`
 	// Arg 订阅参数
@@ -49,27 +58,32 @@ type Books5Message struct {
 // - seqId: 序列号
 type Books5Data struct {
 	// Bids 买盘深度: [[价格, 数量, 废弃, 订单数], ...]
-	Bids [][]string `json:"bids"`
+	Bids fastparse.Levels `json:"bids"`
 	// Asks 卖盘深度: [[价格, 数量, 废弃, 订单数], ...]
-	Asks [][]string `json:"asks"`
+	Asks fastparse.Levels `json:"asks"`
 	// Ts 交易所时间戳（毫秒字符串）
 	Ts string `json:"ts"`
 	// SeqId 序列号
 	SeqId int64 `json:"seqId"`
+	// PrevSeqId 上一条推送的序列号（仅 books 增量频道，快照为 -1）
+	PrevSeqId int64 `json:"prevSeqId"`
 	// InstId 合约 ID
 	InstId string `json:"instId"`
 }
 
-// ConnectionMetrics 连接质量指标
+// BooksResponse REST /api/v5/market/books 响应（仅 REST 轮询降级使用）
+type BooksResponse struct {
+	// Code 响应码，"0" 表示成功
+	Code string `json:"code"`
+	// Msg 错误消息
+	Msg string `json:"msg"`
+	// Data 深度数据（与 books5 推送字段一致，不含 instId/seqId）
+	Data []Books5Data `json:"data"`
+}
+
+// ConnectionMetrics 连接质量指标（通用指标之外附加本地订单簿同步统计）
 type ConnectionMetrics struct {
-	// ReconnectCount 重连次数
-	ReconnectCount int64
-	// ParseErrorCount 解析错误次数
-	ParseErrorCount int64
-	// UpdatesPerSec 每秒更新次数
-	UpdatesPerSec float64
-	// LastMessageAgeMs 最后消息距今时间（毫秒）
-	LastMessageAgeMs int64
-	// WsRttMs WebSocket RTT（毫秒）
-	WsRttMs int64
+	ws.Metrics
+	// BookResyncs 增量频道断档导致重新订阅的次数（仅 books 增量频道）
+	BookResyncs int64
 }
@@ -0,0 +1,84 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// binanceSystemKey Binance 系统状态只有全局一条，固定 Key
+const binanceSystemKey = "system"
+
+// binanceSystemStatus /sapi/v1/system/status 响应
+type binanceSystemStatus struct {
+	// Status 0: 正常，1: 系统维护
+	Status int    `json:"status"`
+	Msg    string `json:"msg"`
+}
+
+// ParseBinance 解析 Binance 系统状态响应
+// 返回: 维护中为 ongoing 状态的公告，正常时为 completed（登记时移除）
+func ParseBinance(body []byte) (Notice, error) {
+	var st binanceSystemStatus
+	if err := json.Unmarshal(body, &st); err != nil {
+		return Notice{}, fmt.Errorf("解析 Binance 系统状态失败: %w", err)
+	}
+	n := Notice{Key: binanceSystemKey, Title: st.Msg, State: StateCompleted}
+	if st.Status == 1 {
+		n.State = StateOngoing
+	}
+	return n, nil
+}
+
+// RunBinance 周期性轮询 Binance 系统状态并写入 m，直到 ctx 取消
+// 请求失败时保留上一次结果（不因网络抖动解除或触发暂停）。
+// 参数 url: 系统状态接口地址
+// 参数 intervalMs: 轮询间隔（毫秒）
+// 参数 exchange: 公告登记的交易所标识（model.ExchangeBinance）
+func RunBinance(ctx context.Context, url string, intervalMs int, exchange string, m *Monitor, logger *zap.Logger) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		n, err := fetchBinance(ctx, client, url)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn("查询 Binance 系统状态失败", zap.Error(err))
+		} else {
+			m.Upsert(exchange, n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchBinance 请求一次系统状态
+func fetchBinance(ctx context.Context, client *http.Client, url string) (Notice, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Notice{}, fmt.Errorf("创建系统状态请求失败: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Notice{}, fmt.Errorf("请求 Binance 系统状态失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Notice{}, fmt.Errorf("读取 Binance 系统状态失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Notice{}, fmt.Errorf("Binance 系统状态 HTTP 状态码: %d", resp.StatusCode)
+	}
+	return ParseBinance(body)
+}
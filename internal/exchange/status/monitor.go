@@ -0,0 +1,94 @@
+// Package status 监控交易所系统维护公告。
+// OKX 订阅公共 WS status 频道（计划/进行中维护的推送），Binance 轮询 REST 系统状态接口；
+// 维护期间聚合器暂停以该交易所为 Leader 的信号生成，避免在撮合暂停或行情失真时开仓。
+//
+// 重要：仅使用公共接口，不做任何登录/签名。
+package status
+
+import (
+	"sort"
+	"sync"
+)
+
+// 维护状态（与 OKX status 频道 state 字段一致）
+const (
+	StateScheduled = "scheduled"
+	StateOngoing   = "ongoing"
+	StatePreOpen   = "pre_open"
+	StateCompleted = "completed"
+	StateCanceled  = "canceled"
+)
+
+// Notice 一条维护公告
+type Notice struct {
+	// Key 公告标识（同一公告的后续推送以此覆盖）
+	Key string
+	// Title 公告标题
+	Title string
+	// State 维护状态（State* 常量）
+	State string
+	// BeginMs/EndMs 计划维护起止时间（Unix 毫秒，0 表示未知）
+	BeginMs int64
+	EndMs   int64
+}
+
+// Active 判断公告在 nowNs 时刻是否处于维护中
+// 进行中/预开放状态直接视为维护中；计划状态按起止时间判断（推送可能晚于计划开始时间）。
+func (n Notice) Active(nowNs int64) bool {
+	switch n.State {
+	case StateOngoing, StatePreOpen:
+		return true
+	case StateScheduled:
+		nowMs := nowNs / 1_000_000
+		return n.BeginMs > 0 && nowMs >= n.BeginMs && (n.EndMs == 0 || nowMs < n.EndMs)
+	default:
+		return false
+	}
+}
+
+// Monitor 各交易所的维护公告登记表
+// 由状态订阅/轮询 goroutine 写入，聚合器读取，需加锁。
+type Monitor struct {
+	mu sync.RWMutex
+	// notices 各交易所未结束的公告（key 为交易所，内层 key 为 Notice.Key）
+	notices map[string]map[string]Notice
+}
+
+// NewMonitor 创建维护公告登记表
+func NewMonitor() *Monitor {
+	return &Monitor{notices: make(map[string]map[string]Notice)}
+}
+
+// Upsert 登记或覆盖一条公告；已完成或已取消的公告被移除
+func (m *Monitor) Upsert(exchange string, n Notice) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n.State == StateCompleted || n.State == StateCanceled {
+		delete(m.notices[exchange], n.Key)
+		return
+	}
+	byKey := m.notices[exchange]
+	if byKey == nil {
+		byKey = make(map[string]Notice)
+		m.notices[exchange] = byKey
+	}
+	byKey[n.Key] = n
+}
+
+// Paused 返回交易所在 nowNs 时刻处于维护中的公告（多条时取 Key 最小者，结果确定）
+func (m *Monitor) Paused(exchange string, nowNs int64) (Notice, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	byKey := m.notices[exchange]
+	keys := make([]string, 0, len(byKey))
+	for k, n := range byKey {
+		if n.Active(nowNs) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return Notice{}, false
+	}
+	sort.Strings(keys)
+	return byKey[keys[0]], true
+}
@@ -0,0 +1,143 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/util/backoff"
+)
+
+// okxPingInterval OKX 要求 30 秒内有出站消息，否则断开连接
+const okxPingInterval = 25 * time.Second
+
+// okxSubscribe status 频道订阅请求
+var okxSubscribe = []byte(`{"op":"subscribe","args":[{"channel":"status"}]}`)
+
+// okxPausingServices 影响行情或撮合、需暂停信号的 serviceType
+// 0: WebSocket，5: 交易服务，8: 交易服务（分账户批次），9: 交易服务（分产品批次），99: 其他（如部分产品暂停）；
+// 大宗交易、策略交易、价差交易、跟单交易等不影响公共行情，忽略。
+var okxPausingServices = map[string]bool{"0": true, "5": true, "8": true, "9": true, "99": true}
+
+// okxStatusPush status 频道推送
+type okxStatusPush struct {
+	Arg struct {
+		Channel string `json:"channel"`
+	} `json:"arg"`
+	Data []okxStatusData `json:"data"`
+}
+
+// okxStatusData 单条维护公告
+type okxStatusData struct {
+	Title       string `json:"title"`
+	State       string `json:"state"`
+	Begin       string `json:"begin"`
+	End         string `json:"end"`
+	ServiceType string `json:"serviceType"`
+}
+
+// ParseOKX 解析 OKX status 频道推送
+// 返回: 影响行情或撮合的公告（订阅确认、pong 与其他频道消息返回 nil）
+func ParseOKX(data []byte) ([]Notice, error) {
+	if string(data) == "pong" {
+		return nil, nil
+	}
+	var push okxStatusPush
+	if err := json.Unmarshal(data, &push); err != nil {
+		return nil, fmt.Errorf("解析 OKX status 推送失败: %w", err)
+	}
+	if push.Arg.Channel != "status" {
+		return nil, nil
+	}
+	var out []Notice
+	for _, d := range push.Data {
+		if !okxPausingServices[d.ServiceType] {
+			continue
+		}
+		begin, _ := strconv.ParseInt(d.Begin, 10, 64)
+		end, _ := strconv.ParseInt(d.End, 10, 64)
+		out = append(out, Notice{
+			Key:     d.ServiceType + "/" + d.Begin + "/" + d.Title,
+			Title:   d.Title,
+			State:   d.State,
+			BeginMs: begin,
+			EndMs:   end,
+		})
+	}
+	return out, nil
+}
+
+// RunOKX 订阅 OKX status 频道并将公告写入 m，断线后退避重连，直到 ctx 取消
+// 参数 url: OKX 公共 WS 地址
+// 参数 exchange: 公告登记的交易所标识（model.ExchangeOKX）
+func RunOKX(ctx context.Context, url, exchange string, m *Monitor, logger *zap.Logger) {
+	bo := backoff.NewDefault()
+	for {
+		err := runOKXConn(ctx, url, exchange, m, bo)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("OKX status 频道断开，等待重连", zap.Error(err))
+		if bo.WaitNext(ctx) != nil {
+			return
+		}
+	}
+}
+
+// runOKXConn 单个连接的订阅与读循环
+func runOKXConn(ctx context.Context, url, exchange string, m *Monitor, bo *backoff.Backoff) error {
+	header := http.Header{}
+	header.Set("Origin", "https://www.okx.com")
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, header)
+	if err != nil {
+		return fmt.Errorf("连接 OKX status 频道失败: %w", err)
+	}
+	defer conn.Close()
+	if err := conn.WriteMessage(websocket.TextMessage, okxSubscribe); err != nil {
+		return fmt.Errorf("订阅 OKX status 频道失败: %w", err)
+	}
+	bo.Reset()
+
+	// 心跳与 ctx 取消：仅此 goroutine 写入，读循环只读
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(okxPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				_ = conn.Close()
+				return
+			case <-ticker.C:
+				if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+					_ = conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * okxPingInterval))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		notices, err := ParseOKX(data)
+		if err != nil {
+			continue
+		}
+		for _, n := range notices {
+			m.Upsert(exchange, n)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Package status 交易所维护公告监控测试
+package status
+
+import (
+	"testing"
+)
+
+func TestParseOKX(t *testing.T) {
+	frame := []byte(`{"arg":{"channel":"status"},"data":[` +
+		`{"title":"Spot system upgrade","state":"scheduled","begin":"1000","end":"2000","serviceType":"5"},` +
+		`{"title":"Copy trading upgrade","state":"ongoing","begin":"1000","end":"2000","serviceType":"11"}]}`)
+	notices, err := ParseOKX(frame)
+	if err != nil {
+		t.Fatalf("ParseOKX: %v", err)
+	}
+	if len(notices) != 1 {
+		t.Fatalf("应只保留影响交易的公告, got %d", len(notices))
+	}
+	n := notices[0]
+	if n.Title != "Spot system upgrade" || n.State != StateScheduled || n.BeginMs != 1000 || n.EndMs != 2000 {
+		t.Errorf("解析结果不符: %+v", n)
+	}
+
+	for _, data := range []string{"pong", `{"event":"subscribe","arg":{"channel":"status"}}`} {
+		if notices, err := ParseOKX([]byte(data)); err != nil || notices != nil {
+			t.Errorf("%s: 应忽略, got %v, %v", data, notices, err)
+		}
+	}
+}
+
+func TestParseBinance(t *testing.T) {
+	n, err := ParseBinance([]byte(`{"status":1,"msg":"system_maintenance"}`))
+	if err != nil || n.State != StateOngoing {
+		t.Fatalf("维护中应为 ongoing, got %+v, %v", n, err)
+	}
+	n, err = ParseBinance([]byte(`{"status":0,"msg":"normal"}`))
+	if err != nil || n.State != StateCompleted {
+		t.Fatalf("正常应为 completed, got %+v, %v", n, err)
+	}
+}
+
+func TestMonitor_Paused(t *testing.T) {
+	m := NewMonitor()
+	const ms = int64(1_000_000)
+	m.Upsert("okx", Notice{Key: "a", Title: "upgrade", State: StateScheduled, BeginMs: 1000, EndMs: 2000})
+
+	if _, ok := m.Paused("okx", 500*ms); ok {
+		t.Errorf("计划开始前不应暂停")
+	}
+	if n, ok := m.Paused("okx", 1500*ms); !ok || n.Title != "upgrade" {
+		t.Errorf("计划时段内应暂停, got %+v, %v", n, ok)
+	}
+	if _, ok := m.Paused("okx", 2500*ms); ok {
+		t.Errorf("计划结束后不应暂停")
+	}
+	if _, ok := m.Paused("binance", 1500*ms); ok {
+		t.Errorf("其他交易所不应受影响")
+	}
+
+	// 进行中的维护不受计划时间限制，完成后解除
+	m.Upsert("okx", Notice{Key: "a", Title: "upgrade", State: StateOngoing, BeginMs: 1000, EndMs: 2000})
+	if _, ok := m.Paused("okx", 2500*ms); !ok {
+		t.Errorf("进行中应暂停")
+	}
+	m.Upsert("okx", Notice{Key: "a", State: StateCompleted})
+	if _, ok := m.Paused("okx", 2500*ms); ok {
+		t.Errorf("完成后应解除暂停")
+	}
+}
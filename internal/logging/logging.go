@@ -0,0 +1,134 @@
+// Package logging 根据 app 配置构建 zap 日志：编码选择（json/console）、
+// 输出到 stderr 或按大小轮转的文件、按组件（logger 名称前缀）覆盖日志级别。
+//
+// 组件命名约定：行情客户端位于 exchange 下（exchange.okx、exchange.backup.okx 等），
+// 聚合器为 core；进程级日志使用根 logger（无名称），沿用 app.log_level。
+package logging
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+// New 按 app 配置创建日志记录器
+// 返回: logger 与关闭函数（刷盘并关闭日志文件）
+func New(app config.AppConfig) (*zap.Logger, func(), error) {
+	base, err := parseLevel(app.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+	overrides := make([]levelOverride, 0, len(app.LogLevels))
+	for name, level := range app.LogLevels {
+		lvl, err := parseLevel(level)
+		if err != nil {
+			return nil, nil, err
+		}
+		overrides = append(overrides, levelOverride{prefix: name, level: lvl})
+	}
+	// 更长（更具体）的前缀优先匹配
+	sort.Slice(overrides, func(i, j int) bool { return len(overrides[i].prefix) > len(overrides[j].prefix) })
+
+	encCfg := zap.NewProductionEncoderConfig()
+	encCfg.TimeKey = "ts"
+	encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	var enc zapcore.Encoder
+	if app.LogFormat == "console" {
+		encCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+		enc = zapcore.NewConsoleEncoder(encCfg)
+	} else {
+		enc = zapcore.NewJSONEncoder(encCfg)
+	}
+
+	var sink zapcore.WriteSyncer = zapcore.Lock(os.Stderr)
+	closeFn := func() {}
+	if app.LogFile.Path != "" {
+		f, err := OpenRotatingFile(app.LogFile.Path, app.LogFile.MaxSizeMB, app.LogFile.MaxBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		sink = f
+		closeFn = func() { _ = f.Close() }
+	}
+
+	// 底层 core 接受所有级别，由 componentCore 按 logger 名称过滤
+	inner := zapcore.NewCore(enc, sink, zapcore.DebugLevel)
+	core := &componentCore{Core: inner, base: base, overrides: overrides, min: minLevel(base, overrides)}
+	// 与 zap 生产配置一致的采样：每秒同一消息前 100 条全量，之后每 100 条记录 1 条
+	sampled := zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+	logger := zap.New(sampled, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	return logger, func() { _ = logger.Sync(); closeFn() }, nil
+}
+
+func parseLevel(s string) (zapcore.Level, error) {
+	var lvl zapcore.Level
+	if s == "" {
+		return zapcore.InfoLevel, nil
+	}
+	if err := lvl.Set(strings.ToLower(s)); err != nil {
+		return lvl, err
+	}
+	return lvl, nil
+}
+
+func minLevel(base zapcore.Level, overrides []levelOverride) zapcore.Level {
+	m := base
+	for _, o := range overrides {
+		if o.level < m {
+			m = o.level
+		}
+	}
+	return m
+}
+
+// levelOverride 组件级别覆盖
+type levelOverride struct {
+	prefix string
+	level  zapcore.Level
+}
+
+// componentCore 按 logger 名称前缀决定生效级别
+type componentCore struct {
+	zapcore.Core
+	base      zapcore.Level
+	overrides []levelOverride
+	// min 所有级别中的最低值（快速拒绝）
+	min zapcore.Level
+}
+
+// levelFor 获取 logger 名称对应的生效级别
+// 前缀按 "." 分段匹配：exchange 匹配 exchange、exchange.okx，不匹配 exchanges。
+func (c *componentCore) levelFor(name string) zapcore.Level {
+	for _, o := range c.overrides {
+		if name == o.prefix || strings.HasPrefix(name, o.prefix+".") {
+			return o.level
+		}
+	}
+	return c.base
+}
+
+// Enabled 实现 zapcore.LevelEnabler
+func (c *componentCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.min
+}
+
+// With 实现 zapcore.Core
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	return &clone
+}
+
+// Check 实现 zapcore.Core
+func (c *componentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.min || ent.Level < c.levelFor(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,86 @@
+// Package logging 日志构建与文件轮转测试
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+func TestRotatingFile_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := OpenRotatingFile(path, 0, 2)
+	if err != nil {
+		t.Fatalf("打开失败: %v", err)
+	}
+	r.maxBytes = 10 // 测试用：10 字节即轮转
+	defer r.Close()
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "dddddddd\n",
+		path + ".1": "cccccccc\n",
+		path + ".2": "bbbbbbbb\n",
+	}
+	for p, content := range want {
+		got, err := os.ReadFile(p)
+		if err != nil || string(got) != content {
+			t.Fatalf("%s = %q (err=%v), want %q", p, got, err, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("超出 max_backups 的文件应被删除")
+	}
+}
+
+func TestNew_ComponentLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, closeFn, err := New(config.AppConfig{
+		LogLevel:  "info",
+		LogFormat: "json",
+		LogLevels: map[string]string{"exchange": "debug", "exchange.okx": "warn"},
+		LogFile:   config.LogFileConfig{Path: path},
+	})
+	if err != nil {
+		t.Fatalf("New 失败: %v", err)
+	}
+
+	logger.Debug("root-debug")
+	logger.Info("root-info")
+	ex := logger.Named("exchange")
+	ex.Named("binance").Debug("binance-debug")
+	ex.Named("okx").Info("okx-info")
+	ex.Named("okx").Warn("okx-warn")
+	logger.Named("exchanges").Debug("prefix-mismatch")
+	closeFn()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取日志失败: %v", err)
+	}
+	out := string(data)
+	for _, msg := range []string{"root-info", "binance-debug", "okx-warn"} {
+		if !strings.Contains(out, msg) {
+			t.Errorf("应输出 %s", msg)
+		}
+	}
+	for _, msg := range []string{"root-debug", "okx-info", "prefix-mismatch"} {
+		if strings.Contains(out, msg) {
+			t.Errorf("不应输出 %s", msg)
+		}
+	}
+}
+
+func TestNew_InvalidLevel(t *testing.T) {
+	if _, _, err := New(config.AppConfig{LogLevel: "info", LogLevels: map[string]string{"core": "verbose"}}); err == nil {
+		t.Fatalf("无效的组件级别应返回错误")
+	}
+}
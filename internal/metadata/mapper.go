@@ -17,20 +17,34 @@ import (
 // 参数 f: 元数据获取器
 // 返回: Symbol 映射表（key 为 Canon）
 func BuildSymbolMaps(ctx context.Context, cfg *config.Config, f Fetcher) (map[string]*SymbolMap, error) {
+	result, mapErrs, err := ResolveSymbols(ctx, cfg, f)
+	if err != nil {
+		return nil, err
+	}
+	if len(mapErrs) > 0 {
+		return nil, mapErrs[0]
+	}
+	return result, nil
+}
+
+// ResolveSymbols 获取元数据并映射全部配置的交易对，不在首个映射失败时停止
+// 用于配置检查：一次列出所有无法映射的交易对。
+// 返回: 成功映射的交易对；每个映射失败的交易对一条错误；元数据获取失败时返回 err
+func ResolveSymbols(ctx context.Context, cfg *config.Config, f Fetcher) (map[string]*SymbolMap, []error, error) {
 	// 获取三家交易所的元数据
 	okxInsts, err := f.FetchOKX(ctx, cfg.Metadata.OKX)
 	if err != nil {
-		return nil, fmt.Errorf("获取 OKX 元数据失败: %w", err)
+		return nil, nil, fmt.Errorf("获取 OKX 元数据失败: %w", err)
 	}
 
 	binanceSyms, err := f.FetchBinance(ctx, cfg.Metadata.Binance)
 	if err != nil {
-		return nil, fmt.Errorf("获取 Binance 元数据失败: %w", err)
+		return nil, nil, fmt.Errorf("获取 Binance 元数据失败: %w", err)
 	}
 
 	bittapData, err := f.FetchBittap(ctx, cfg.Metadata.Bittap)
 	if err != nil {
-		return nil, fmt.Errorf("获取 Bittap 元数据失败: %w", err)
+		return nil, nil, fmt.Errorf("获取 Bittap 元数据失败: %w", err)
 	}
 
 	// 构建各交易所的索引
@@ -40,15 +54,17 @@ func BuildSymbolMaps(ctx context.Context, cfg *config.Config, f Fetcher) (map[st
 
 	// 为每个用户配置的交易对构建映射
 	result := make(map[string]*SymbolMap)
+	var mapErrs []error
 	for _, sym := range cfg.Symbols {
 		mapping, err := buildMapping(sym.Input, okxIndex, binanceIndex, bittapIndex)
 		if err != nil {
-			return nil, fmt.Errorf("映射交易对 '%s' 失败: %w", sym.Input, err)
+			mapErrs = append(mapErrs, fmt.Errorf("映射交易对 '%s' 失败: %w", sym.Input, err))
+			continue
 		}
 		result[mapping.Canon] = mapping
 	}
 
-	return result, nil
+	return result, mapErrs, nil
 }
 
 type bittapIndexItem struct {
@@ -2,12 +2,15 @@
 package metadata
 
 import (
+	"context"
 	"strings"
 	"testing"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
+
+	"latency-arbitrage-validator/internal/config"
 )
 
 // **Feature: latency-arbitrage-validator, Property 2: Symbol Normalization Consistency**
@@ -224,3 +227,46 @@ func TestBinanceSymbol_IsUSDTPerpetual(t *testing.T) {
 		})
 	}
 }
+
+// fakeFetcher 固定元数据（不访问网络）
+type fakeFetcher struct{}
+
+func (fakeFetcher) FetchOKX(ctx context.Context, url string) ([]OKXInstrument, error) {
+	return []OKXInstrument{
+		{InstId: "BTC-USDT-SWAP", InstType: "SWAP", Uly: "BTC-USDT", CtType: "linear", SettleCcy: "USDT", TickSz: "0.1"},
+		{InstId: "ETH-USDT-SWAP", InstType: "SWAP", Uly: "ETH-USDT", CtType: "linear", SettleCcy: "USDT", TickSz: "0.01"},
+	}, nil
+}
+
+func (fakeFetcher) FetchBinance(ctx context.Context, url string) ([]BinanceSymbol, error) {
+	return []BinanceSymbol{
+		{Symbol: "BTCUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING"},
+	}, nil
+}
+
+func (fakeFetcher) FetchBittap(ctx context.Context, url string) (*BittapData, error) {
+	return &BittapData{ContractSymbols: []BittapContractSymbol{
+		{SymbolId: "BTC-USDT-M", QuoteCode: "USDT", Status: "OPEN", Depths: []string{"0.1"}},
+		{SymbolId: "ETH-USDT-M", QuoteCode: "USDT", Status: "OPEN", Depths: []string{"0.01"}},
+	}}, nil
+}
+
+func TestResolveSymbols_CollectsAllErrors(t *testing.T) {
+	cfg := &config.Config{Symbols: []config.SymbolConfig{{Input: "BTC-USDT"}, {Input: "ETH-USDT"}, {Input: "DOGE-USDT"}}}
+
+	maps, mapErrs, err := ResolveSymbols(context.Background(), cfg, fakeFetcher{})
+	if err != nil {
+		t.Fatalf("ResolveSymbols 失败: %v", err)
+	}
+	if len(maps) != 1 || maps["BTCUSDT"] == nil || maps["BTCUSDT"].BittapSym != "BTC-USDT-M" {
+		t.Fatalf("maps=%v", maps)
+	}
+	// ETH 缺 Binance、DOGE 三家都缺：各一条错误
+	if len(mapErrs) != 2 || !strings.Contains(mapErrs[0].Error(), "ETH-USDT") || !strings.Contains(mapErrs[1].Error(), "DOGE-USDT") {
+		t.Fatalf("mapErrs=%v", mapErrs)
+	}
+
+	if _, err := BuildSymbolMaps(context.Background(), cfg, fakeFetcher{}); err == nil {
+		t.Fatalf("BuildSymbolMaps 遇到映射失败应返回错误")
+	}
+}
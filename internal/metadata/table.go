@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Table 单个行情客户端持有的交易对映射表（key 为 Canon）
+// 运行期可移除交易对（退订）；采用写时复制，读循环无锁读取。
+// 每个客户端持有独立副本，互不影响。
+type Table struct {
+	// snap 当前快照（只读，修改时整体替换）
+	snap atomic.Pointer[tableSnapshot]
+	// mu 串行化写入
+	mu sync.Mutex
+}
+
+// tableSnapshot 映射表快照及其反向索引（解析器按交易所原生标识 O(1) 查找 Canon）
+type tableSnapshot struct {
+	byCanon map[string]*SymbolMap
+	// byOKXInstId key 为 OKX 合约 ID
+	byOKXInstId map[string]*SymbolMap
+	// byBittapSym key 为大写的 Bittap 交易对（Bittap 推送的大小写不固定）
+	byBittapSym map[string]*SymbolMap
+}
+
+// newTableSnapshot 由映射表构建快照（maps 归快照所有，调用方不得再修改）
+func newTableSnapshot(maps map[string]*SymbolMap) *tableSnapshot {
+	s := &tableSnapshot{
+		byCanon:     maps,
+		byOKXInstId: make(map[string]*SymbolMap, len(maps)),
+		byBittapSym: make(map[string]*SymbolMap, len(maps)),
+	}
+	for _, m := range maps {
+		if m.OKXInstId != "" {
+			s.byOKXInstId[m.OKXInstId] = m
+		}
+		if m.BittapSym != "" {
+			s.byBittapSym[strings.ToUpper(m.BittapSym)] = m
+		}
+	}
+	return s
+}
+
+// NewTable 创建映射表（复制 maps，调用方后续修改不影响本表）
+// 参数 maps: Symbol 映射表（key 为 Canon）
+func NewTable(maps map[string]*SymbolMap) *Table {
+	cp := make(map[string]*SymbolMap, len(maps))
+	for canon, m := range maps {
+		cp[canon] = m
+	}
+	t := &Table{}
+	t.snap.Store(newTableSnapshot(cp))
+	return t
+}
+
+// Load 返回当前映射表快照（只读，不得修改）
+func (t *Table) Load() map[string]*SymbolMap {
+	return t.snap.Load().byCanon
+}
+
+// Get 按 Canon 查找映射
+func (t *Table) Get(canon string) (*SymbolMap, bool) {
+	m, ok := t.Load()[canon]
+	return m, ok
+}
+
+// ByOKXInstId 按 OKX 合约 ID（如 BTC-USDT-SWAP）查找映射
+func (t *Table) ByOKXInstId(instId string) (*SymbolMap, bool) {
+	m, ok := t.snap.Load().byOKXInstId[instId]
+	return m, ok
+}
+
+// ByBittapSym 按 Bittap 交易对（如 BTC-USDT-M，不区分大小写）查找映射
+func (t *Table) ByBittapSym(symbol string) (*SymbolMap, bool) {
+	// 已为大写时 ToUpper 不分配内存
+	m, ok := t.snap.Load().byBittapSym[strings.ToUpper(symbol)]
+	return m, ok
+}
+
+// Canons 返回全部统一交易对（升序）
+func (t *Table) Canons() []string {
+	return SortedCanons(t.Load())
+}
+
+// Remove 移除交易对
+// 参数 canons: 待移除的统一交易对（未配置的忽略）
+// 返回: 实际移除的映射（key 为 Canon；没有可移除的交易对时为 nil）
+func (t *Table) Remove(canons []string) map[string]*SymbolMap {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cur := t.Load()
+	var removed map[string]*SymbolMap
+	for _, canon := range canons {
+		if m, ok := cur[canon]; ok {
+			if removed == nil {
+				removed = make(map[string]*SymbolMap, len(canons))
+			}
+			removed[canon] = m
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	next := make(map[string]*SymbolMap, len(cur)-len(removed))
+	for canon, m := range cur {
+		if _, ok := removed[canon]; !ok {
+			next[canon] = m
+		}
+	}
+	t.snap.Store(newTableSnapshot(next))
+	return removed
+}
@@ -0,0 +1,60 @@
+// Package metadata 交易对映射表测试
+package metadata
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTable_Remove(t *testing.T) {
+	src := map[string]*SymbolMap{
+		"BTCUSDT": {Canon: "BTCUSDT"},
+		"ETHUSDT": {Canon: "ETHUSDT"},
+		"SOLUSDT": {Canon: "SOLUSDT"},
+	}
+	tbl := NewTable(src)
+
+	snap := tbl.Load()
+	removed := tbl.Remove([]string{"ETHUSDT", "ETHUSDT", "XRPUSDT"})
+	if len(removed) != 1 || removed["ETHUSDT"] == nil {
+		t.Fatalf("移除结果错误: %v", removed)
+	}
+	if got := strings.Join(tbl.Canons(), ","); got != "BTCUSDT,SOLUSDT" {
+		t.Fatalf("移除后交易对 = %s", got)
+	}
+	// 写时复制：旧快照与源映射不受影响
+	if len(snap) != 3 || len(src) != 3 {
+		t.Fatalf("旧快照或源映射被修改: %d %d", len(snap), len(src))
+	}
+	if _, ok := tbl.Get("ETHUSDT"); ok {
+		t.Fatalf("已移除交易对仍可查到")
+	}
+	if tbl.Remove([]string{"ETHUSDT"}) != nil {
+		t.Fatalf("重复移除应返回 nil")
+	}
+}
+
+func TestTable_ReverseLookup(t *testing.T) {
+	tbl := NewTable(map[string]*SymbolMap{
+		"BTCUSDT": {Canon: "BTCUSDT", OKXInstId: "BTC-USDT-SWAP", BittapSym: "BTC-USDT-M"},
+		"ETHUSDT": {Canon: "ETHUSDT", OKXInstId: "ETH-USDT-SWAP", BittapSym: "ETH-USDT-M"},
+	})
+	if m, ok := tbl.ByOKXInstId("ETH-USDT-SWAP"); !ok || m.Canon != "ETHUSDT" {
+		t.Fatalf("ByOKXInstId = %v, %v", m, ok)
+	}
+	if m, ok := tbl.ByBittapSym("btc-usdt-m"); !ok || m.Canon != "BTCUSDT" {
+		t.Fatalf("ByBittapSym 应不区分大小写: %v, %v", m, ok)
+	}
+	if _, ok := tbl.ByOKXInstId("BTC-USDT"); ok {
+		t.Fatalf("未配置的合约 ID 不应命中")
+	}
+
+	// 反向索引随移除同步更新
+	tbl.Remove([]string{"BTCUSDT"})
+	if _, ok := tbl.ByOKXInstId("BTC-USDT-SWAP"); ok {
+		t.Fatalf("已移除交易对仍可按 instId 查到")
+	}
+	if _, ok := tbl.ByBittapSym("BTC-USDT-M"); ok {
+		t.Fatalf("已移除交易对仍可按 Bittap 交易对查到")
+	}
+}
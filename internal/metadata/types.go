@@ -1,6 +1,8 @@
 // Package metadata 负责从交易所获取合约元数据并构建 symbol 映射。
 package metadata
 
+import "sort"
+
 // OKXResponse OKX 合约元数据 API 响应
 // API: GET /api/v5/public/instruments?instType=SWAP
 type OKXResponse struct {
@@ -236,3 +238,13 @@ type SymbolMap struct {
 	// TickSize 价格步长
 	TickSize float64
 }
+
+// SortedCanons 返回映射表中全部统一交易对（升序，保证订阅分帧顺序稳定）
+func SortedCanons(maps map[string]*SymbolMap) []string {
+	out := make([]string, 0, len(maps))
+	for canon := range maps {
+		out = append(out, canon)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,44 @@
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// maxLineBytes 读取时单行最大长度
+const maxLineBytes = 4 << 20
+
+// ForEach 逐行读取 JSONL 文件，解码为 T 并回调
+// 空行跳过；无法解析的行返回带行号的错误；回调返回错误时立即停止并返回该错误。
+func ForEach[T any](path string, fn func(v *T) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开 JSONL 文件失败: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), maxLineBytes)
+
+	line := 0
+	for sc.Scan() {
+		line++
+		b := sc.Bytes()
+		if len(b) == 0 {
+			continue
+		}
+		v := new(T)
+		if err := json.Unmarshal(b, v); err != nil {
+			return fmt.Errorf("解析 %s 第 %d 行失败: %w", path, line, err)
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("读取 JSONL 文件失败: %w", err)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type opType int
@@ -26,14 +27,47 @@ type op struct {
 	done chan error
 }
 
+// Stats 写入统计（累计）
+type Stats struct {
+	// Written 成功写入（进入文件缓冲区）的记录数
+	Written int64 `json:"written"`
+	// MarshalErrors JSON 编码失败而丢弃的记录数
+	MarshalErrors int64 `json:"marshal_errors"`
+	// WriteErrors 文件写入或 flush 失败次数（如磁盘已满、权限不足）
+	WriteErrors int64 `json:"write_errors"`
+}
+
+// Options 写入器选项
+type Options struct {
+	// BufferSize 写入缓冲区大小（channel capacity，<=0 时为 1000）
+	BufferSize int
+	// FlushInterval 周期性 flush 间隔（0 表示仅在显式 Flush/Close 时写盘）
+	// 稀疏输出（如 paper_trades）可能长时间填不满文件缓冲区，主机崩溃时最多丢失该间隔内的记录。
+	FlushInterval time.Duration
+	// Fsync 每次 flush 后调用 fsync，使数据落盘而非仅停留在操作系统页缓存
+	Fsync bool
+}
+
 // Writer 异步 JSONL 写入器
-// Write 只负责投递，实际 JSON 编码与文件 I/O 在后台 goroutine 完成。
+// Write 只负责投递，实际 JSON 编码与文件 I/O 在后台 goroutine 完成；
+// 后台的编码/写入错误不会返回给 Write 的调用方，而是计入 Stats、LastError 并回调 OnError。
 type Writer struct {
 	// path 输出文件路径
 	path string
+	// opts 写入器选项
+	opts Options
 	// ch 操作通道
 	ch chan op
 
+	// written/marshalErrs/writeErrs 写入统计
+	written     atomic.Int64
+	marshalErrs atomic.Int64
+	writeErrs   atomic.Int64
+	// lastErr 最近一次编码/写入错误
+	lastErr atomic.Pointer[error]
+	// onError 错误回调（可选，在后台 goroutine 中调用）
+	onError atomic.Pointer[func(error)]
+
 	closeOnce sync.Once
 	closeErr  error
 	closed    int32
@@ -47,8 +81,13 @@ type Writer struct {
 // 参数 path: 输出文件路径
 // 参数 bufferSize: 写入缓冲区大小（channel capacity）
 func NewWriter(path string, bufferSize int) (*Writer, error) {
-	if bufferSize <= 0 {
-		bufferSize = 1000
+	return NewWriterWithOptions(path, Options{BufferSize: bufferSize})
+}
+
+// NewWriterWithOptions 按选项创建 JSONL 写入器（周期性 flush、fsync）
+func NewWriterWithOptions(path string, opts Options) (*Writer, error) {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1000
 	}
 
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
@@ -62,7 +101,8 @@ func NewWriter(path string, bufferSize int) (*Writer, error) {
 
 	w := &Writer{
 		path: path,
-		ch:   make(chan op, bufferSize),
+		opts: opts,
+		ch:   make(chan op, opts.BufferSize),
 	}
 
 	w.wg.Add(1)
@@ -88,6 +128,78 @@ func (w *Writer) Write(v any) error {
 	return nil
 }
 
+// TryWrite 非阻塞写入一条 JSONL 记录
+// 缓冲区已满、正在 flush 或已关闭时直接丢弃，适用于 WS 读循环等不可阻塞的调用方。
+// 返回: 是否已投递
+func (w *Writer) TryWrite(v any) bool {
+	if w == nil || atomic.LoadInt32(&w.closed) == 1 {
+		return false
+	}
+	if !w.sendMu.TryLock() {
+		return false
+	}
+	defer w.sendMu.Unlock()
+	if atomic.LoadInt32(&w.closed) == 1 {
+		return false
+	}
+	select {
+	case w.ch <- op{typ: opWrite, val: v}:
+		return true
+	default:
+		return false
+	}
+}
+
+// OnError 设置错误回调：后台编码或写入失败时调用（在写入 goroutine 中执行，应尽快返回）
+func (w *Writer) OnError(fn func(err error)) {
+	if w == nil {
+		return
+	}
+	w.onError.Store(&fn)
+}
+
+// Stats 获取写入统计
+func (w *Writer) Stats() Stats {
+	if w == nil {
+		return Stats{}
+	}
+	return Stats{
+		Written:       w.written.Load(),
+		MarshalErrors: w.marshalErrs.Load(),
+		WriteErrors:   w.writeErrs.Load(),
+	}
+}
+
+// LastError 获取最近一次编码/写入错误（无错误时为 nil）
+func (w *Writer) LastError() error {
+	if w == nil {
+		return nil
+	}
+	if p := w.lastErr.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// Path 获取输出文件路径
+func (w *Writer) Path() string {
+	if w == nil {
+		return ""
+	}
+	return w.path
+}
+
+// fail 记录一次后台错误并回调
+func (w *Writer) fail(counter *atomic.Int64, err error) error {
+	err = fmt.Errorf("%s: %w", w.path, err)
+	counter.Add(1)
+	w.lastErr.Store(&err)
+	if fn := w.onError.Load(); fn != nil {
+		(*fn)(err)
+	}
+	return err
+}
+
 // Flush 强制 flush 文件缓冲区
 func (w *Writer) Flush() error {
 	if w == nil {
@@ -129,30 +241,64 @@ func (w *Writer) loop(f *os.File) {
 	defer f.Close()
 
 	bw := bufio.NewWriterSize(f, 1<<20) // 1MB buffer
-	encErr := func(err error, done chan error) {
+	// dirty 上次落盘后是否有新写入（周期性 flush 仅在有新数据时写盘）
+	dirty := false
+	flush := func(done chan error) {
+		err := bw.Flush()
+		if err != nil {
+			err = w.fail(&w.writeErrs, fmt.Errorf("flush 失败: %w", err))
+		} else if w.opts.Fsync && dirty {
+			if err = f.Sync(); err != nil {
+				err = w.fail(&w.writeErrs, fmt.Errorf("fsync 失败: %w", err))
+			}
+		}
+		if err == nil {
+			dirty = false
+		}
 		if done != nil {
 			done <- err
 		}
 	}
 
-	for req := range w.ch {
+	var tick <-chan time.Time
+	if w.opts.FlushInterval > 0 {
+		ticker := time.NewTicker(w.opts.FlushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		var req op
+		select {
+		case <-tick:
+			if dirty {
+				flush(nil)
+			}
+			continue
+		case r, ok := <-w.ch:
+			if !ok {
+				return
+			}
+			req = r
+		}
 		switch req.typ {
 		case opWrite:
 			b, err := json.Marshal(req.val)
 			if err != nil {
+				w.fail(&w.marshalErrs, fmt.Errorf("JSON 编码失败: %w", err))
 				continue
 			}
+			b = append(b, '\n')
 			if _, err := bw.Write(b); err != nil {
+				w.fail(&w.writeErrs, fmt.Errorf("写入失败: %w", err))
 				continue
 			}
-			if err := bw.WriteByte('\n'); err != nil {
-				continue
-			}
+			w.written.Add(1)
+			dirty = true
 		case opFlush:
-			encErr(bw.Flush(), req.done)
+			flush(req.done)
 		case opClose:
-			err := bw.Flush()
-			encErr(err, req.done)
+			flush(req.done)
 			return
 		}
 	}
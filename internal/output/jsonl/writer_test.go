@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -29,6 +32,7 @@ func TestPaperTrade_OutputCompleteness_Property(t *testing.T) {
 				leader = "okx"
 			}
 			pt := &model.PaperTrade{
+				SignalID:    leader + "-BTCUSDT-long-1",
 				Leader:      leader,
 				SymbolCanon: "BTCUSDT",
 				Side:        "long",
@@ -53,6 +57,7 @@ func TestPaperTrade_OutputCompleteness_Property(t *testing.T) {
 			}
 
 			required := []string{
+				"signal_id",
 				"leader",
 				"symbol_canon",
 				"side",
@@ -118,3 +123,97 @@ func TestWriter_WriteAndClose(t *testing.T) {
 		t.Fatalf("lines=%d, want 10", lines)
 	}
 }
+
+func TestWriter_ErrorAccounting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "err.jsonl")
+	w, err := NewWriter(path, 100)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	var got []error
+	var mu sync.Mutex
+	w.OnError(func(err error) {
+		mu.Lock()
+		got = append(got, err)
+		mu.Unlock()
+	})
+
+	_ = w.Write(map[string]any{"i": 1})
+	_ = w.Write(map[string]any{"bad": make(chan int)})
+	_ = w.Write(map[string]any{"i": 2})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	st := w.Stats()
+	if st.Written != 2 || st.MarshalErrors != 1 || st.WriteErrors != 0 {
+		t.Fatalf("stats=%+v, want written=2 marshal=1 write=0", st)
+	}
+	if w.LastError() == nil || !strings.Contains(w.LastError().Error(), path) {
+		t.Fatalf("LastError=%v, want error mentioning %s", w.LastError(), path)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("callback calls=%d, want 1", len(got))
+	}
+}
+
+func TestWriter_FlushInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "periodic.jsonl")
+	w, err := NewWriterWithOptions(path, Options{BufferSize: 10, FlushInterval: 10 * time.Millisecond, Fsync: true})
+	if err != nil {
+		t.Fatalf("NewWriterWithOptions: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.Write(map[string]any{"i": 1}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// 不调用 Flush：记录应在 flush 间隔后自动落盘
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if string(b) == "{\"i\":1}\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("flush 间隔后文件内容=%q", b)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if st := w.Stats(); st.WriteErrors != 0 {
+		t.Fatalf("stats=%+v, want no write errors", st)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "read.jsonl")
+	if err := os.WriteFile(path, []byte("{\"i\":1}\n\n{\"i\":2}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	type row struct {
+		I int `json:"i"`
+	}
+	var got []int
+	if err := ForEach(path, func(r *row) error {
+		got = append(got, r.I)
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEach: %v", err)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("got=%v, want [1 2]", got)
+	}
+
+	if err := os.WriteFile(path, []byte("{\"i\":1}\nnot-json\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := ForEach(path, func(r *row) error { return nil }); err == nil || !strings.Contains(err.Error(), "第 2 行") {
+		t.Fatalf("坏行应返回带行号的错误: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package manifest 为每次运行在输出目录写入 manifest.json，
+// 记录输出格式版本、配置快照、symbol 映射、程序构建信息与起止时间，使结果目录可自描述。
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"latency-arbitrage-validator/internal/buildinfo"
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/metadata"
+)
+
+// FileName 清单文件名
+const FileName = "manifest.json"
+
+// Manifest 运行清单
+type Manifest struct {
+	// Command 运行命令（run/replay）
+	Command string `json:"command"`
+	// RunID 运行 ID（独立输出目录名的后半部分；旧输出布局为空）
+	RunID string `json:"run_id,omitempty"`
+	// NodeID 部署节点标识（app.node_id，未配置时为空）
+	NodeID string `json:"node_id,omitempty"`
+	// Build 程序构建信息
+	Build buildinfo.Info `json:"build"`
+	// SchemaVersions 各输出流的记录格式版本（键为流名，如 signals）
+	SchemaVersions map[string]int `json:"schema_versions"`
+	// ConfigPath 配置文件路径
+	ConfigPath string `json:"config_path"`
+	// Config 生效配置快照（含默认值，键与 config.yaml 一致）
+	Config map[string]any `json:"config"`
+	// SymbolMaps symbol 映射（按统一标识排序）
+	SymbolMaps []*metadata.SymbolMap `json:"symbol_maps,omitempty"`
+	// StartedAt 开始时间
+	StartedAt time.Time `json:"started_at"`
+	// StoppedAt 结束时间（运行中为空）
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+}
+
+// New 创建运行清单
+// 参数 symbolMaps: symbol 映射（回放等离线命令可为 nil）
+func New(command, configPath string, cfg *config.Config, symbolMaps map[string]*metadata.SymbolMap, schemaVersions map[string]int) (*Manifest, error) {
+	snap, err := configSnapshot(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		Command:        command,
+		Build:          buildinfo.Get(),
+		SchemaVersions: schemaVersions,
+		ConfigPath:     configPath,
+		Config:         snap,
+		StartedAt:      time.Now().UTC(),
+	}
+	if cfg != nil {
+		m.NodeID = cfg.App.NodeID
+	}
+	for _, canon := range metadata.SortedCanons(symbolMaps) {
+		m.SymbolMaps = append(m.SymbolMaps, symbolMaps[canon])
+	}
+	return m, nil
+}
+
+// Stop 记录结束时间
+func (m *Manifest) Stop() {
+	now := time.Now().UTC()
+	m.StoppedAt = &now
+}
+
+// Write 原子写入 <dir>/manifest.json（先写临时文件再 rename）
+func (m *Manifest) Write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化运行清单失败: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	path := filepath.Join(dir, FileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("写入运行清单失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("替换运行清单失败: %w", err)
+	}
+	return nil
+}
+
+// Read 读取 <dir>/manifest.json
+func Read(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, fmt.Errorf("读取运行清单失败: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("解析运行清单失败: %w", err)
+	}
+	return &m, nil
+}
+
+// configSnapshot 按 yaml 标签将配置转换为通用 map（键与 config.yaml 一致）
+func configSnapshot(cfg *config.Config) (map[string]any, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("序列化配置快照失败: %w", err)
+	}
+	var out map[string]any
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("解析配置快照失败: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,87 @@
+// Package manifest 运行清单测试
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/metadata"
+)
+
+func TestManifest_WriteAndStop(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{Output: config.OutputConfig{Dir: dir, BufferSize: 1000}}
+	maps := map[string]*metadata.SymbolMap{
+		"ETHUSDT": {Canon: "ETHUSDT"},
+		"BTCUSDT": {Canon: "BTCUSDT"},
+	}
+	m, err := New("run", "config.yaml", cfg, maps, map[string]int{"signals": 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := m.Write(dir); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	read := func() map[string]any {
+		data, err := os.ReadFile(filepath.Join(dir, FileName))
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		var out map[string]any
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		return out
+	}
+
+	got := read()
+	if _, ok := got["stopped_at"]; ok {
+		t.Fatal("运行中不应输出 stopped_at")
+	}
+	output, _ := got["config"].(map[string]any)["output"].(map[string]any)
+	if output["dir"] != dir || output["buffer_size"] != float64(1000) {
+		t.Fatalf("config.output=%v，应按 yaml 键输出", output)
+	}
+	syms := got["symbol_maps"].([]any)
+	if len(syms) != 2 || syms[0].(map[string]any)["Canon"] != "BTCUSDT" {
+		t.Fatalf("symbol_maps=%v，应按统一标识排序", syms)
+	}
+	if got["schema_versions"].(map[string]any)["signals"] != float64(1) {
+		t.Fatalf("schema_versions=%v", got["schema_versions"])
+	}
+
+	m.Stop()
+	if err := m.Write(dir); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, ok := read()["stopped_at"]; !ok {
+		t.Fatal("结束后应输出 stopped_at")
+	}
+}
+
+func TestManifest_ReadNodeID(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{App: config.AppConfig{NodeID: "tokyo-1"}}
+	m, err := New("run", "config.yaml", cfg, nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	m.RunID = "20260101-000000"
+	if err := m.Write(dir); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.NodeID != "tokyo-1" || got.RunID != m.RunID || got.Command != "run" {
+		t.Errorf("读回清单不符: %+v", got)
+	}
+	if _, err := Read(t.TempDir()); err == nil {
+		t.Error("缺少清单文件应返回错误")
+	}
+}
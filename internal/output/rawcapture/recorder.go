@@ -0,0 +1,100 @@
+// Package rawcapture 按比例采样录制交易所原始 WebSocket 帧，用于复现解析问题。
+// 解析失败的帧无论是否命中采样都会录制；写入为非阻塞投递，缓冲区满时丢弃。
+package rawcapture
+
+import (
+	"sync/atomic"
+)
+
+// 帧方向
+const (
+	// DirectionIn 收到的帧
+	DirectionIn = "in"
+	// DirectionOut 发出的帧（订阅、应用层心跳等）
+	DirectionOut = "out"
+)
+
+// Frame 原始帧记录（raw_<exchange>.jsonl 一行）
+type Frame struct {
+	// TsUnixNs 本机时间戳（纳秒；收到的帧为 socket 读出时间）
+	TsUnixNs int64 `json:"ts_unix_ns"`
+	// Exchange 交易所标识
+	Exchange string `json:"exchange"`
+	// Direction 方向: in / out
+	Direction string `json:"direction"`
+	// Data 原始帧内容（完整，不截断）
+	Data string `json:"data"`
+	// ParseError 解析错误信息（仅解析失败的帧）
+	ParseError string `json:"parse_error,omitempty"`
+}
+
+// Sink 帧输出目标（jsonl.Writer 满足此接口）
+type Sink interface {
+	// TryWrite 非阻塞投递，返回是否成功
+	TryWrite(v any) bool
+}
+
+// Recorder 单交易所原始帧录制器（并发安全）
+type Recorder struct {
+	exchange string
+	rate     float64
+	sink     Sink
+
+	// seen 收到的帧计数（用于确定性采样）
+	seen uint64
+	// dropped 因缓冲区满未能录制的帧数
+	dropped uint64
+}
+
+// NewRecorder 创建原始帧录制器
+// 参数 exchange: 交易所标识
+// 参数 rate: 采样比例（0-1，1 表示全部录制）
+// 参数 sink: 输出目标
+func NewRecorder(exchange string, rate float64, sink Sink) *Recorder {
+	return &Recorder{exchange: exchange, rate: rate, sink: sink}
+}
+
+// Inbound 按采样比例录制收到的帧
+// 返回: 是否命中采样（命中时调用方无需再为解析失败单独录制）
+func (r *Recorder) Inbound(tsNs int64, data []byte) bool {
+	if r == nil {
+		return false
+	}
+	n := atomic.AddUint64(&r.seen, 1)
+	// 确定性采样：累计应录制数跨过整数边界时录制，比例精确且无需随机数
+	if uint64(float64(n)*r.rate) == uint64(float64(n-1)*r.rate) {
+		return false
+	}
+	r.write(Frame{TsUnixNs: tsNs, Exchange: r.exchange, Direction: DirectionIn, Data: string(data)})
+	return true
+}
+
+// InboundParseError 录制解析失败的帧（不受采样比例限制）
+func (r *Recorder) InboundParseError(tsNs int64, data []byte, err error) {
+	if r == nil || err == nil {
+		return
+	}
+	r.write(Frame{TsUnixNs: tsNs, Exchange: r.exchange, Direction: DirectionIn, Data: string(data), ParseError: err.Error()})
+}
+
+// Outbound 录制发出的帧（数量少，全部录制）
+func (r *Recorder) Outbound(tsNs int64, data []byte) {
+	if r == nil {
+		return
+	}
+	r.write(Frame{TsUnixNs: tsNs, Exchange: r.exchange, Direction: DirectionOut, Data: string(data)})
+}
+
+// Dropped 获取因缓冲区满未能录制的帧数
+func (r *Recorder) Dropped() uint64 {
+	if r == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&r.dropped)
+}
+
+func (r *Recorder) write(f Frame) {
+	if !r.sink.TryWrite(f) {
+		atomic.AddUint64(&r.dropped, 1)
+	}
+}
@@ -0,0 +1,75 @@
+// Package rawcapture 原始帧录制测试
+package rawcapture
+
+import (
+	"errors"
+	"testing"
+)
+
+type memSink struct {
+	frames []Frame
+	full   bool
+}
+
+func (m *memSink) TryWrite(v any) bool {
+	if m.full {
+		return false
+	}
+	m.frames = append(m.frames, v.(Frame))
+	return true
+}
+
+func TestRecorder_SamplingRate(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want int
+	}{
+		{0, 0},
+		{0.1, 10},
+		{0.25, 25},
+		{1, 100},
+	}
+	for _, tt := range tests {
+		sink := &memSink{}
+		r := NewRecorder("okx", tt.rate, sink)
+		for i := 0; i < 100; i++ {
+			r.Inbound(int64(i), []byte("frame"))
+		}
+		if len(sink.frames) != tt.want {
+			t.Errorf("rate=%v 录制 %d 帧, want %d", tt.rate, len(sink.frames), tt.want)
+		}
+	}
+}
+
+func TestRecorder_ParseErrorAndOutbound(t *testing.T) {
+	sink := &memSink{}
+	r := NewRecorder("bittap", 0, sink)
+
+	data := []byte(`{"broken"`)
+	if r.Inbound(7, data) {
+		t.Fatalf("rate=0 不应命中采样")
+	}
+	r.InboundParseError(7, data, errors.New("unexpected EOF"))
+	data[0] = 'X' // 录制内容应为拷贝，不受调用方复用缓冲区影响
+	r.Outbound(8, []byte(`{"method":"PING"}`))
+
+	if len(sink.frames) != 2 {
+		t.Fatalf("录制 %d 帧, want 2", len(sink.frames))
+	}
+	in, out := sink.frames[0], sink.frames[1]
+	if in.Direction != DirectionIn || in.ParseError != "unexpected EOF" || in.Data != `{"broken"` || in.Exchange != "bittap" {
+		t.Fatalf("解析失败帧错误: %+v", in)
+	}
+	if out.Direction != DirectionOut || out.TsUnixNs != 8 {
+		t.Fatalf("发出帧错误: %+v", out)
+	}
+
+	sink.full = true
+	r.Outbound(9, []byte("x"))
+	if r.Dropped() != 1 {
+		t.Fatalf("Dropped=%d, want 1", r.Dropped())
+	}
+
+	var nilRec *Recorder
+	nilRec.Outbound(1, []byte("x")) // 未启用时调用应安全
+}
@@ -0,0 +1,137 @@
+// Package sink 定义输出流的写入目标抽象。
+// 每个逻辑输出流（signals、paper_trades 等）对应一个 Sink；多个目标通过 Tee 并行写入，
+// 新增传输方式只需实现 Sink 并 Register，无需改动聚合器。
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/output/jsonl"
+)
+
+// Sink 输出目标（jsonl.Writer 满足此接口）
+// Write 须为异步投递，编码与 I/O 在后台完成，不得长时间阻塞热路径。
+type Sink interface {
+	// Write 投递一条记录
+	Write(v any) error
+	// Flush 将已投递的记录写出
+	Flush() error
+	// Close 关闭（会先 flush）
+	Close() error
+}
+
+// Reporter 可报告写入统计的 Sink（用于指标快照与错误告警）
+type Reporter interface {
+	// Path 目标标识（文件路径或地址）
+	Path() string
+	// Stats 写入统计
+	Stats() jsonl.Stats
+	// OnError 设置后台错误回调
+	OnError(fn func(err error))
+}
+
+// Factory 按配置创建 Sink
+// 参数 out: 输出配置（缓冲区大小、flush 策略等公共参数）
+type Factory func(cfg config.SinkConfig, out config.OutputConfig) (Sink, error)
+
+// factories 已注册的 sink 类型
+var factories = map[string]Factory{
+	config.SinkTypeJSONL: func(cfg config.SinkConfig, out config.OutputConfig) (Sink, error) {
+		return jsonl.NewWriterWithOptions(cfg.Path, JSONLOptions(out))
+	},
+	config.SinkTypeUDP: func(cfg config.SinkConfig, out config.OutputConfig) (Sink, error) {
+		return NewUDP(cfg.Addr, out.BufferSize)
+	},
+}
+
+// JSONLOptions 按输出配置生成 JSONL 写入器选项
+func JSONLOptions(out config.OutputConfig) jsonl.Options {
+	return jsonl.Options{
+		BufferSize:    out.BufferSize,
+		FlushInterval: time.Duration(out.FlushIntervalMs) * time.Millisecond,
+		Fsync:         out.Fsync,
+	}
+}
+
+// Register 注册 sink 类型（同名覆盖）
+// 须在 Open 之前调用（通常在 init 中），非并发安全。
+func Register(typ string, f Factory) {
+	factories[typ] = f
+}
+
+// Open 按配置创建 Sink
+func Open(cfg config.SinkConfig, out config.OutputConfig) (Sink, error) {
+	f, ok := factories[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("未知 sink 类型: %s", cfg.Type)
+	}
+	return f(cfg, out)
+}
+
+// Tee 将每条记录写入全部 Sink
+type Tee []Sink
+
+// New 组合多个 Sink
+// 返回: 无目标时为 nil；单个目标时直接返回该目标，避免多一层转发
+func New(sinks ...Sink) Sink {
+	switch len(sinks) {
+	case 0:
+		return nil
+	case 1:
+		return sinks[0]
+	}
+	return Tee(sinks)
+}
+
+// Write 写入全部目标（单个目标失败不影响其余目标）
+func (t Tee) Write(v any) error {
+	var errs []error
+	for _, s := range t {
+		if err := s.Write(v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush flush 全部目标
+func (t Tee) Flush() error {
+	var errs []error
+	for _, s := range t {
+		if err := s.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭全部目标
+func (t Tee) Close() error {
+	var errs []error
+	for _, s := range t {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reporters 展开 Tee，返回其中可报告统计的目标
+func Reporters(s Sink) []Reporter {
+	switch v := s.(type) {
+	case nil:
+		return nil
+	case Tee:
+		var out []Reporter
+		for _, child := range v {
+			out = append(out, Reporters(child)...)
+		}
+		return out
+	case Reporter:
+		return []Reporter{v}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Package sink 输出目标测试
+package sink
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	if New() != nil {
+		t.Fatal("New() 应返回 nil")
+	}
+	path := filepath.Join(t.TempDir(), "a.jsonl")
+	s, err := Open(config.SinkConfig{Type: config.SinkTypeJSONL, Path: path}, config.OutputConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	if _, ok := New(s).(Tee); ok {
+		t.Fatal("单个目标不应包装为 Tee")
+	}
+	if _, err := Open(config.SinkConfig{Type: "kafka"}, config.OutputConfig{BufferSize: 10}); err == nil {
+		t.Fatal("未注册类型应返回错误")
+	}
+}
+
+func TestTee_JSONLAndUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket: %v", err)
+	}
+	defer pc.Close()
+
+	path := filepath.Join(t.TempDir(), "signals.jsonl")
+	file, err := Open(config.SinkConfig{Type: config.SinkTypeJSONL, Path: path}, config.OutputConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Open jsonl: %v", err)
+	}
+	udp, err := Open(config.SinkConfig{Type: config.SinkTypeUDP, Addr: pc.LocalAddr().String()}, config.OutputConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Open udp: %v", err)
+	}
+	s := New(file, udp)
+
+	for i := 0; i < 3; i++ {
+		if err := s.Write(map[string]int{"i": i}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	for i := 0; i < 3; i++ {
+		_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("ReadFrom: %v", err)
+		}
+		var got map[string]int
+		if err := json.Unmarshal(buf[:n], &got); err != nil || got["i"] != i {
+			t.Fatalf("datagram %d = %q, err=%v", i, buf[:n], err)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if lines := strings.Count(string(b), "\n"); lines != 3 {
+		t.Fatalf("文件行数=%d, want 3", lines)
+	}
+
+	rs := Reporters(s)
+	if len(rs) != 2 {
+		t.Fatalf("Reporters=%d, want 2", len(rs))
+	}
+	for _, r := range rs {
+		if st := r.Stats(); st.Written != 3 {
+			t.Fatalf("%s stats=%+v, want written=3", r.Path(), st)
+		}
+	}
+	if err := udp.Write(1); err == nil {
+		t.Fatal("关闭后写入应返回错误")
+	}
+}
@@ -0,0 +1,138 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"latency-arbitrage-validator/internal/output/jsonl"
+)
+
+// UDP 异步 UDP sink：每条记录编码为一个 JSON 数据报发送到目标地址
+// 适合转发给本机采集器（如再写入 Kafka）；UDP 不保证送达，目标不可达时计入写入错误。
+type UDP struct {
+	addr string
+	conn net.Conn
+	ch   chan udpOp
+
+	written     atomic.Int64
+	marshalErrs atomic.Int64
+	writeErrs   atomic.Int64
+	onError     atomic.Pointer[func(error)]
+
+	sendMu    sync.Mutex
+	closed    atomic.Bool
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// udpOp 写入/同步请求（done 非空表示 flush 或 close）
+type udpOp struct {
+	val  any
+	done chan struct{}
+}
+
+// NewUDP 创建 UDP sink
+// 参数 addr: 目标地址 host:port
+// 参数 bufferSize: 异步写入缓冲区大小（channel capacity）
+func NewUDP(addr string, bufferSize int) (*UDP, error) {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接 UDP 目标失败: %w", err)
+	}
+	u := &UDP{addr: addr, conn: conn, ch: make(chan udpOp, bufferSize)}
+	u.wg.Add(1)
+	go u.loop()
+	return u, nil
+}
+
+// Write 异步发送一条记录
+func (u *UDP) Write(v any) error {
+	return u.send(udpOp{val: v})
+}
+
+// Flush 等待已投递的记录发送完毕
+func (u *UDP) Flush() error {
+	done := make(chan struct{})
+	if u.send(udpOp{done: done}) != nil {
+		// 已关闭：剩余记录已在 Close 时发送
+		return nil
+	}
+	<-done
+	return nil
+}
+
+// Close 发送剩余记录后关闭连接
+func (u *UDP) Close() error {
+	var err error
+	u.closeOnce.Do(func() {
+		u.sendMu.Lock()
+		u.closed.Store(true)
+		close(u.ch)
+		u.sendMu.Unlock()
+		u.wg.Wait()
+		err = u.conn.Close()
+	})
+	return err
+}
+
+// Path 目标标识
+func (u *UDP) Path() string {
+	return "udp:" + u.addr
+}
+
+// Stats 发送统计
+func (u *UDP) Stats() jsonl.Stats {
+	return jsonl.Stats{
+		Written:       u.written.Load(),
+		MarshalErrors: u.marshalErrs.Load(),
+		WriteErrors:   u.writeErrs.Load(),
+	}
+}
+
+// OnError 设置错误回调（在发送 goroutine 中执行，应尽快返回）
+func (u *UDP) OnError(fn func(err error)) {
+	u.onError.Store(&fn)
+}
+
+func (u *UDP) send(req udpOp) error {
+	u.sendMu.Lock()
+	defer u.sendMu.Unlock()
+	if u.closed.Load() {
+		return fmt.Errorf("udp sink 已关闭")
+	}
+	u.ch <- req
+	return nil
+}
+
+func (u *UDP) fail(counter *atomic.Int64, err error) {
+	counter.Add(1)
+	if fn := u.onError.Load(); fn != nil {
+		(*fn)(fmt.Errorf("%s: %w", u.Path(), err))
+	}
+}
+
+func (u *UDP) loop() {
+	defer u.wg.Done()
+	for req := range u.ch {
+		if req.done != nil {
+			close(req.done)
+			continue
+		}
+		b, err := json.Marshal(req.val)
+		if err != nil {
+			u.fail(&u.marshalErrs, fmt.Errorf("JSON 编码失败: %w", err))
+			continue
+		}
+		if _, err := u.conn.Write(b); err != nil {
+			u.fail(&u.writeErrs, fmt.Errorf("发送失败: %w", err))
+			continue
+		}
+		u.written.Add(1)
+	}
+}
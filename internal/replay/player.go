@@ -0,0 +1,129 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// 回放节奏模式
+const (
+	// ModeMax 不等待，尽快回放（回归测试/回测）
+	ModeMax = "max"
+	// ModeRealtime 按录制时间间隔实时回放
+	ModeRealtime = "realtime"
+	// ModeAccelerated 按录制时间间隔的 1/Speed 加速回放
+	ModeAccelerated = "accelerated"
+	// ModeStep 单步回放：每收到一次 Step 信号推进一个事件
+	ModeStep = "step"
+)
+
+// maxPaceSleep 单次等待上限（录制数据中的长时间空档不按原样等待）
+const maxPaceSleep = 5 * time.Second
+
+// Player 回放驱动器
+// 每个事件回调前将虚拟时钟推进到该事件的到达时间，使下游以录制时间而非墙钟运行。
+type Player struct {
+	// Mode 回放节奏: max / realtime / accelerated / step
+	Mode string
+	// Speed 加速倍数（仅 accelerated，须 > 0）
+	Speed float64
+	// Step 单步信号（仅 step 模式，每次接收推进一个事件）
+	Step <-chan struct{}
+	// Clock 虚拟时钟（回放过程中被推进）
+	Clock *timeutil.VirtualClock
+
+	// sleep 等待函数（便于测试替换）
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewPlayer 创建回放驱动器
+// 参数 mode: 回放节奏
+// 参数 speed: 加速倍数（仅 accelerated）
+func NewPlayer(mode string, speed float64) (*Player, error) {
+	switch mode {
+	case "", ModeMax, ModeRealtime, ModeStep:
+	case ModeAccelerated:
+		if speed <= 0 {
+			return nil, fmt.Errorf("accelerated 模式需要 speed > 0，当前值: %v", speed)
+		}
+	default:
+		return nil, fmt.Errorf("未知回放模式: %s", mode)
+	}
+	if mode == "" {
+		mode = ModeMax
+	}
+	return &Player{
+		Mode:  mode,
+		Speed: speed,
+		Clock: timeutil.NewVirtualClock(0),
+		sleep: sleepCtx,
+	}, nil
+}
+
+// Source 以回放节奏包装事件源
+// 参数 ctx: 取消后停止回放并返回 ctx.Err()
+func (p *Player) Source(ctx context.Context, src Source) Source {
+	return func(fn func(ev *model.BookEvent) error) error {
+		var prevNs int64
+		return src(func(ev *model.BookEvent) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := p.pace(ctx, prevNs, ev.ArrivedAtUnixNs); err != nil {
+				return err
+			}
+			prevNs = ev.ArrivedAtUnixNs
+			p.Clock.Set(ev.ArrivedAtUnixNs)
+			return fn(ev)
+		})
+	}
+}
+
+// pace 按模式等待到下一个事件
+func (p *Player) pace(ctx context.Context, prevNs, nextNs int64) error {
+	switch p.Mode {
+	case ModeStep:
+		select {
+		case _, ok := <-p.Step:
+			if !ok {
+				return fmt.Errorf("单步信号已关闭")
+			}
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case ModeRealtime, ModeAccelerated:
+		if prevNs == 0 || nextNs <= prevNs {
+			return nil
+		}
+		speed := 1.0
+		if p.Mode == ModeAccelerated {
+			speed = p.Speed
+		}
+		d := time.Duration(float64(nextNs-prevNs) / speed)
+		if d > maxPaceSleep {
+			d = maxPaceSleep
+		}
+		return p.sleep(ctx, d)
+	default:
+		return nil
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
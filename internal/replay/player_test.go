@@ -0,0 +1,156 @@
+// Package replay 回放节奏与虚拟时钟测试
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func playerEvents() []*model.BookEvent {
+	return []*model.BookEvent{
+		{Exchange: "okx", SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_000_000_000},
+		{Exchange: "bittap", SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_200_000_000},
+		{Exchange: "okx", SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_200_000_000},
+		{Exchange: "bittap", SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 61_200_000_000},
+	}
+}
+
+func TestNewPlayer_InvalidMode(t *testing.T) {
+	if _, err := NewPlayer("fast", 1); err == nil {
+		t.Fatalf("未知模式应返回错误")
+	}
+	if _, err := NewPlayer(ModeAccelerated, 0); err == nil {
+		t.Fatalf("accelerated 模式 speed=0 应返回错误")
+	}
+	p, err := NewPlayer("", 0)
+	if err != nil || p.Mode != ModeMax {
+		t.Fatalf("空模式应默认为 max: mode=%v err=%v", p, err)
+	}
+}
+
+func TestPlayer_ClockFollowsEvents(t *testing.T) {
+	p, _ := NewPlayer(ModeMax, 0)
+	var seen []int64
+	err := p.Source(context.Background(), SliceSource(playerEvents()))(func(ev *model.BookEvent) error {
+		// 回调时虚拟时钟等于当前事件的到达时间
+		seen = append(seen, p.Clock.NowNano())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("回放失败: %v", err)
+	}
+	for i, ev := range playerEvents() {
+		if seen[i] != ev.ArrivedAtUnixNs {
+			t.Fatalf("事件 %d 时钟=%d, want %d", i, seen[i], ev.ArrivedAtUnixNs)
+		}
+	}
+}
+
+func TestPlayer_AcceleratedSleeps(t *testing.T) {
+	p, _ := NewPlayer(ModeAccelerated, 4)
+	var sleeps []time.Duration
+	p.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	if err := p.Source(context.Background(), SliceSource(playerEvents()))(func(*model.BookEvent) error { return nil }); err != nil {
+		t.Fatalf("回放失败: %v", err)
+	}
+	// 首个事件与同时间戳事件不等待；60s 空档按 4 倍速为 15s，截断为上限
+	want := []time.Duration{50 * time.Millisecond, maxPaceSleep}
+	if len(sleeps) != len(want) {
+		t.Fatalf("sleeps=%v, want %v", sleeps, want)
+	}
+	for i := range want {
+		if sleeps[i] != want[i] {
+			t.Fatalf("sleeps=%v, want %v", sleeps, want)
+		}
+	}
+}
+
+func TestPlayer_PaceModes(t *testing.T) {
+	// 含时间回退的事件：等待时长为 0，虚拟时钟保持单调
+	events := []*model.BookEvent{
+		{Exchange: "okx", SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_000_000_000},
+		{Exchange: "okx", SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_100_000_000},
+		{Exchange: "bittap", SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_050_000_000},
+		{Exchange: "bittap", SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_250_000_000},
+	}
+	wantClock := []int64{1_000_000_000, 1_100_000_000, 1_100_000_000, 1_250_000_000}
+
+	cases := []struct {
+		name   string
+		mode   string
+		speed  float64
+		sleeps []time.Duration
+	}{
+		{name: "max", mode: ModeMax},
+		{name: "realtime", mode: ModeRealtime, sleeps: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}},
+		{name: "accelerated x2", mode: ModeAccelerated, speed: 2, sleeps: []time.Duration{50 * time.Millisecond, 100 * time.Millisecond}},
+		{name: "accelerated x0.5", mode: ModeAccelerated, speed: 0.5, sleeps: []time.Duration{200 * time.Millisecond, 400 * time.Millisecond}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPlayer(tc.mode, tc.speed)
+			if err != nil {
+				t.Fatalf("NewPlayer: %v", err)
+			}
+			var sleeps []time.Duration
+			p.sleep = func(ctx context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				return nil
+			}
+			var clock []int64
+			err = p.Source(context.Background(), SliceSource(events))(func(*model.BookEvent) error {
+				clock = append(clock, p.Clock.NowNano())
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("回放失败: %v", err)
+			}
+			if len(sleeps) != len(tc.sleeps) {
+				t.Fatalf("sleeps=%v, want %v", sleeps, tc.sleeps)
+			}
+			for i := range tc.sleeps {
+				if sleeps[i] != tc.sleeps[i] {
+					t.Fatalf("sleeps=%v, want %v", sleeps, tc.sleeps)
+				}
+			}
+			for i := range wantClock {
+				if clock[i] != wantClock[i] {
+					t.Fatalf("clock=%v, want %v", clock, wantClock)
+				}
+			}
+		})
+	}
+}
+
+func TestPlayer_Step(t *testing.T) {
+	p, _ := NewPlayer(ModeStep, 0)
+	step := make(chan struct{}, 2)
+	step <- struct{}{}
+	step <- struct{}{}
+	close(step)
+	p.Step = step
+
+	n := 0
+	err := p.Source(context.Background(), SliceSource(playerEvents()))(func(*model.BookEvent) error {
+		n++
+		return nil
+	})
+	if err == nil || n != 2 {
+		t.Fatalf("单步信号耗尽后应停止: n=%d err=%v", n, err)
+	}
+}
+
+func TestPlayer_Cancel(t *testing.T) {
+	p, _ := NewPlayer(ModeRealtime, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Source(ctx, SliceSource(playerEvents()))(func(*model.BookEvent) error { return nil }); err != context.Canceled {
+		t.Fatalf("err=%v, want context.Canceled", err)
+	}
+}
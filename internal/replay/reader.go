@@ -0,0 +1,124 @@
+// Package replay 读取录制的 BookEvent 流（books.jsonl），供回测/回放使用。
+// 录制文件由聚合器按到达顺序写出，每行一个 BookEvent JSON。
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// maxLineBytes 单行最大长度（BookEvent 含深度档位，通常 < 4KB）
+const maxLineBytes = 4 << 20
+
+// Source 事件源：按录制顺序逐条回调
+// 每次调用都从头开始遍历，便于多个 goroutine 独立回放同一份数据。
+type Source func(fn func(ev *model.BookEvent) error) error
+
+// recordedEvent 录制行的解码结构
+// 兼容旧录制格式：买卖档位混合在 Levels 中（买盘在前），读取时按最优买价拆分为 Bids/Asks。
+type recordedEvent struct {
+	model.BookEvent
+	Levels []model.Level
+}
+
+// bookEvent 返回拆分旧格式档位后的事件
+func (r *recordedEvent) bookEvent() *model.BookEvent {
+	ev := &r.BookEvent
+	if len(r.Levels) == 0 || ev.Bids != nil || ev.Asks != nil {
+		return ev
+	}
+	for _, l := range r.Levels {
+		if ev.BestBidPx > 0 && l.Price <= ev.BestBidPx {
+			ev.Bids = append(ev.Bids, l)
+		} else {
+			ev.Asks = append(ev.Asks, l)
+		}
+	}
+	return ev
+}
+
+// FileSource 创建基于 books.jsonl 文件的事件源
+// 参数 path: 录制文件路径
+func FileSource(path string) Source {
+	return func(fn func(ev *model.BookEvent) error) error {
+		return ForEachEvent(path, fn)
+	}
+}
+
+// SliceSource 创建基于内存切片的事件源（测试或小数据集使用）
+func SliceSource(events []*model.BookEvent) Source {
+	return func(fn func(ev *model.BookEvent) error) error {
+		for _, ev := range events {
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ForEachEvent 逐行读取录制文件并回调
+// 空行跳过；无法解析的行返回带行号的错误。
+// 回调返回错误时立即停止并返回该错误。
+func ForEachEvent(path string, fn func(ev *model.BookEvent) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开录制文件失败: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), maxLineBytes)
+
+	line := 0
+	for sc.Scan() {
+		line++
+		b := sc.Bytes()
+		if len(b) == 0 {
+			continue
+		}
+		var rec recordedEvent
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return fmt.Errorf("解析录制文件第 %d 行失败: %w", line, err)
+		}
+		if err := fn(rec.bookEvent()); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("读取录制文件失败: %w", err)
+	}
+	return nil
+}
+
+// TimeRange 按到达时间截取事件源：仅回调 fromNs <= ArrivedAtUnixNs < toNs 的事件
+func TimeRange(src Source, fromNs, toNs int64) Source {
+	return func(fn func(ev *model.BookEvent) error) error {
+		return src(func(ev *model.BookEvent) error {
+			if ev.ArrivedAtUnixNs < fromNs || ev.ArrivedAtUnixNs >= toNs {
+				return nil
+			}
+			return fn(ev)
+		})
+	}
+}
+
+// Bounds 扫描事件源，返回最早与最晚的到达时间（纳秒）
+// 事件源为空时 first=last=0。
+func Bounds(src Source) (first, last int64, err error) {
+	err = src(func(ev *model.BookEvent) error {
+		ts := ev.ArrivedAtUnixNs
+		if first == 0 || ts < first {
+			first = ts
+		}
+		if ts > last {
+			last = ts
+		}
+		return nil
+	})
+	return first, last, err
+}
@@ -0,0 +1,69 @@
+// Package replay 录制文件读取测试
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestForEachEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.jsonl")
+	content := `{"Exchange":"okx","SymbolCanon":"BTCUSDT","BestBidPx":100,"BestAskPx":100.1,"ArrivedAtUnixNs":1}
+
+{"Exchange":"bittap","SymbolCanon":"BTCUSDT","BestBidPx":99.9,"BestAskPx":100,"ArrivedAtUnixNs":2,"Seq":7}
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("写入测试文件失败: %v", err)
+	}
+
+	var got []*model.BookEvent
+	if err := FileSource(path)(func(ev *model.BookEvent) error {
+		got = append(got, ev)
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachEvent err=%v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len=%d, want 2", len(got))
+	}
+	if got[1].Exchange != model.ExchangeBittap || got[1].Seq != 7 || got[1].ArrivedAtUnixNs != 2 {
+		t.Fatalf("第二条事件解析错误: %+v", got[1])
+	}
+}
+
+func TestForEachEvent_LegacyLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.jsonl")
+	content := `{"Exchange":"okx","SymbolCanon":"BTCUSDT","BestBidPx":100,"BestAskPx":100.1,"Levels":[{"Price":100,"Qty":1},{"Price":99.9,"Qty":2},{"Price":100.1,"Qty":3}]}
+{"Exchange":"okx","SymbolCanon":"BTCUSDT","BestBidPx":100,"BestAskPx":100.1,"Bids":[{"Price":100,"Qty":1}],"Asks":[]}
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("写入测试文件失败: %v", err)
+	}
+	var got []*model.BookEvent
+	if err := ForEachEvent(path, func(ev *model.BookEvent) error {
+		got = append(got, ev)
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachEvent err=%v", err)
+	}
+	// 旧格式按最优买价拆分
+	if len(got[0].Bids) != 2 || got[0].Bids[1].Price != 99.9 || len(got[0].Asks) != 1 || got[0].Asks[0].Qty != 3 {
+		t.Fatalf("旧格式拆分错误: bids=%v asks=%v", got[0].Bids, got[0].Asks)
+	}
+	if len(got[1].Bids) != 1 || len(got[1].Asks) != 0 {
+		t.Fatalf("新格式解析错误: bids=%v asks=%v", got[1].Bids, got[1].Asks)
+	}
+}
+
+func TestForEachEvent_BadLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.jsonl")
+	if err := os.WriteFile(path, []byte("{not json}\n"), 0o644); err != nil {
+		t.Fatalf("写入测试文件失败: %v", err)
+	}
+	if err := ForEachEvent(path, func(*model.BookEvent) error { return nil }); err == nil {
+		t.Fatalf("无效行应返回错误")
+	}
+}
@@ -0,0 +1,68 @@
+package replay
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// SyntheticFollower 合成 Follower 校准事件源
+// 丢弃录制中的 Bittap 事件，改由指定 Leader 的行情延迟 lagMs 后生成：
+// 合成盘口以 Leader 中间价为中心、按 spreadBps 展开买卖价，数量沿用 Leader。
+// Follower 严格滞后 Leader 固定时延，引擎/执行器/EV 的结果可与已知的理论收益对照。
+// 参数 leader: 作为合成来源的 Leader（okx/binance）
+// 参数 lagMs: Follower 相对 Leader 的到达时延（毫秒）
+// 参数 spreadBps: 合成盘口的买卖价差（基点）
+func SyntheticFollower(src Source, leader string, lagMs int, spreadBps float64) Source {
+	lagNs := int64(lagMs) * 1_000_000
+	half := spreadBps / 2 / 10000
+	return func(fn func(ev *model.BookEvent) error) error {
+		// pending 待投递的合成事件（Leader 按到达顺序录制，队列按到达时间递增）
+		var pending []*model.BookEvent
+		flush := func(untilNs int64) error {
+			n := 0
+			for n < len(pending) && pending[n].ArrivedAtUnixNs <= untilNs {
+				if err := fn(pending[n]); err != nil {
+					return err
+				}
+				n++
+			}
+			pending = pending[n:]
+			return nil
+		}
+		err := src(func(ev *model.BookEvent) error {
+			if ev.Exchange == model.ExchangeBittap {
+				return nil
+			}
+			if err := flush(ev.ArrivedAtUnixNs); err != nil {
+				return err
+			}
+			if err := fn(ev); err != nil {
+				return err
+			}
+			if ev.Exchange != leader || !ev.IsValid() {
+				return nil
+			}
+			mid := (ev.BestBidPx + ev.BestAskPx) / 2
+			pending = append(pending, &model.BookEvent{
+				Exchange:        model.ExchangeBittap,
+				SymbolCanon:     ev.SymbolCanon,
+				BestBidPx:       mid * (1 - half),
+				BestBidQty:      ev.BestBidQty,
+				BestAskPx:       mid * (1 + half),
+				BestAskQty:      ev.BestAskQty,
+				ArrivedAtUnixNs: ev.ArrivedAtUnixNs + lagNs,
+				Seq:             ev.Seq,
+				Degraded:        ev.Degraded,
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, ev := range pending {
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
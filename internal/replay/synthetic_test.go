@@ -0,0 +1,49 @@
+// Package replay 合成 Follower 事件源测试
+package replay
+
+import (
+	"math"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestSyntheticFollower(t *testing.T) {
+	events := []*model.BookEvent{
+		{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 99, BestAskPx: 101, ArrivedAtUnixNs: 0},
+		{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 50, BestAskPx: 51, ArrivedAtUnixNs: 1},
+		{Exchange: model.ExchangeBinance, SymbolCanon: "BTCUSDT", BestBidPx: 99, BestAskPx: 101, ArrivedAtUnixNs: 50_000_000},
+		{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 109, BestAskPx: 111, ArrivedAtUnixNs: 150_000_000},
+	}
+
+	var got []*model.BookEvent
+	src := SyntheticFollower(SliceSource(events), model.ExchangeOKX, 100, 20)
+	if err := src(func(ev *model.BookEvent) error {
+		got = append(got, ev)
+		return nil
+	}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+
+	// okx@0, binance@50ms, synth@100ms, okx@150ms, synth@250ms（录制的 Bittap 被丢弃）
+	wantEx := []string{model.ExchangeOKX, model.ExchangeBinance, model.ExchangeBittap, model.ExchangeOKX, model.ExchangeBittap}
+	if len(got) != len(wantEx) {
+		t.Fatalf("len=%d, want %d", len(got), len(wantEx))
+	}
+	for i, ex := range wantEx {
+		if got[i].Exchange != ex {
+			t.Fatalf("got[%d].Exchange=%s, want %s", i, got[i].Exchange, ex)
+		}
+	}
+
+	syn := got[2]
+	if syn.ArrivedAtUnixNs != 100_000_000 {
+		t.Fatalf("ArrivedAtUnixNs=%d, want 100ms", syn.ArrivedAtUnixNs)
+	}
+	if math.Abs(syn.BestBidPx-99.9) > 1e-9 || math.Abs(syn.BestAskPx-100.1) > 1e-9 {
+		t.Fatalf("合成盘口=%v/%v, want 99.9/100.1", syn.BestBidPx, syn.BestAskPx)
+	}
+	if got[4].ArrivedAtUnixNs != 250_000_000 || math.Abs(got[4].BestBidPx-109.89) > 1e-9 {
+		t.Fatalf("末尾合成事件错误: %+v", got[4])
+	}
+}
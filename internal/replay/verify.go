@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"math"
+	"strconv"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// signalKey 信号匹配键：策略变体、链路、方向与触发信号的两侧快照到达时间
+// 快照到达时间随 books.jsonl 录制，回放时不变；信号 ID 含检测时刻，实时运行与回放不同，不能用于匹配。
+type signalKey struct {
+	variant         string
+	leader          string
+	symbol          string
+	side            model.Side
+	leaderArrived   int64
+	followerArrived int64
+}
+
+func keyOf(s *model.Signal) signalKey {
+	k := signalKey{variant: s.Variant, leader: s.Leader, symbol: s.SymbolCanon, side: s.Side}
+	if s.LeaderBook != nil {
+		k.leaderArrived = s.LeaderBook.ArrivedAtUnixNs
+	}
+	if s.FollowerBook != nil {
+		k.followerArrived = s.FollowerBook.ArrivedAtUnixNs
+	}
+	return k
+}
+
+// SignalMismatch 同一触发快照上录制与重算信号的字段差异
+type SignalMismatch struct {
+	// ID 录制信号 ID
+	ID string
+	// Field 字段名（与 signals.jsonl 键一致）
+	Field string
+	// Recorded/Recomputed 录制值与重算值
+	Recorded   string
+	Recomputed string
+}
+
+// SignalDiff 录制信号与重算信号的比对结果
+type SignalDiff struct {
+	// Recorded/Recomputed 两侧信号数
+	Recorded   int
+	Recomputed int
+	// Matched 按触发快照匹配上的信号数（含字段不一致的）
+	Matched int
+	// Missing 录制中有、重算中没有的信号（按录制顺序）
+	Missing []*model.Signal
+	// Extra 重算中有、录制中没有的信号（按重算顺序）
+	Extra []*model.Signal
+	// Mismatches 匹配信号的字段差异
+	Mismatches []SignalMismatch
+}
+
+// OK 两侧信号是否完全一致
+func (d *SignalDiff) OK() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Mismatches) == 0
+}
+
+// DiffSignals 按触发快照匹配录制信号与重算信号，并比较决策相关字段
+// 比较字段: 价差、入场阈值、深度、手续费、净边际（相对误差 tol 以内视为一致）与过滤原因、EV 拒绝标记；
+// 不比较快照年龄与链路时延 P50（依赖实时运行的墙钟与指标周期）。
+// 同一触发快照对应多条信号时按出现顺序配对。
+func DiffSignals(recorded, recomputed []*model.Signal, tol float64) *SignalDiff {
+	d := &SignalDiff{Recorded: len(recorded), Recomputed: len(recomputed)}
+	pending := make(map[signalKey][]*model.Signal, len(recorded))
+	for _, s := range recorded {
+		k := keyOf(s)
+		pending[k] = append(pending[k], s)
+	}
+	for _, s := range recomputed {
+		k := keyOf(s)
+		q := pending[k]
+		if len(q) == 0 {
+			d.Extra = append(d.Extra, s)
+			continue
+		}
+		rec := q[0]
+		pending[k] = q[1:]
+		d.Matched++
+		d.Mismatches = append(d.Mismatches, compareSignal(rec, s, tol)...)
+	}
+	for _, s := range recorded {
+		k := keyOf(s)
+		if q := pending[k]; len(q) > 0 && q[0] == s {
+			d.Missing = append(d.Missing, s)
+			pending[k] = q[1:]
+		}
+	}
+	return d
+}
+
+// compareSignal 比较一对匹配信号的决策相关字段
+func compareSignal(rec, got *model.Signal, tol float64) []SignalMismatch {
+	var out []SignalMismatch
+	floats := []struct {
+		field    string
+		rec, got float64
+	}{
+		{"SpreadBps", rec.SpreadBps, got.SpreadBps},
+		{"theta_entry_bps", rec.ThetaEntryBps, got.ThetaEntryBps},
+		{"depth_usd", rec.DepthUSD, got.DepthUSD},
+		{"fee_bps", rec.FeeBps, got.FeeBps},
+		{"net_edge_bps", rec.NetEdgeBps, got.NetEdgeBps},
+	}
+	for _, f := range floats {
+		if !closeEnough(f.rec, f.got, tol) {
+			out = append(out, SignalMismatch{
+				ID:         rec.ID,
+				Field:      f.field,
+				Recorded:   strconv.FormatFloat(f.rec, 'g', -1, 64),
+				Recomputed: strconv.FormatFloat(f.got, 'g', -1, 64),
+			})
+		}
+	}
+	if rec.FilterReason != got.FilterReason {
+		out = append(out, SignalMismatch{ID: rec.ID, Field: "FilterReason", Recorded: rec.FilterReason, Recomputed: got.FilterReason})
+	}
+	if rec.RejectedByEV != got.RejectedByEV {
+		out = append(out, SignalMismatch{
+			ID:         rec.ID,
+			Field:      "RejectedByEV",
+			Recorded:   strconv.FormatBool(rec.RejectedByEV),
+			Recomputed: strconv.FormatBool(got.RejectedByEV),
+		})
+	}
+	return out
+}
+
+// closeEnough 相对误差（绝对值小于 1 时按绝对误差）是否在 tol 以内
+func closeEnough(a, b, tol float64) bool {
+	diff := math.Abs(a - b)
+	return diff <= tol*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
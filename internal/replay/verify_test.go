@@ -0,0 +1,74 @@
+// Package replay 信号重算比对测试
+package replay
+
+import (
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func sig(id string, leaderNs, followerNs int64, spread float64) *model.Signal {
+	return &model.Signal{
+		ID:           id,
+		Leader:       model.ExchangeOKX,
+		SymbolCanon:  "BTCUSDT",
+		Side:         model.SideLong,
+		SpreadBps:    spread,
+		LeaderBook:   &model.BookEvent{ArrivedAtUnixNs: leaderNs},
+		FollowerBook: &model.BookEvent{ArrivedAtUnixNs: followerNs},
+	}
+}
+
+func TestDiffSignals_Identical(t *testing.T) {
+	rec := []*model.Signal{sig("a", 1, 2, 5), sig("b", 3, 4, 6)}
+	// 重算信号 ID 含检测时刻，与录制不同也能按触发快照匹配
+	got := []*model.Signal{sig("x", 1, 2, 5), sig("y", 3, 4, 6+1e-12)}
+	d := DiffSignals(rec, got, 1e-9)
+	if !d.OK() || d.Matched != 2 {
+		t.Fatalf("应完全一致: %+v", d)
+	}
+}
+
+func TestDiffSignals_Differences(t *testing.T) {
+	filtered := sig("c", 5, 6, 7)
+	filtered.FilterReason = model.FilterReasonBlackout
+	rec := []*model.Signal{sig("a", 1, 2, 5), sig("b", 3, 4, 6), filtered}
+	changed := sig("c2", 5, 6, 7.5)
+	got := []*model.Signal{sig("a2", 1, 2, 5), changed, sig("d", 8, 9, 4)}
+
+	d := DiffSignals(rec, got, 1e-9)
+	if d.OK() || d.Matched != 2 || d.Recorded != 3 || d.Recomputed != 3 {
+		t.Fatalf("比对结果不符: %+v", d)
+	}
+	if len(d.Missing) != 1 || d.Missing[0].ID != "b" {
+		t.Errorf("Missing=%v", d.Missing)
+	}
+	if len(d.Extra) != 1 || d.Extra[0].ID != "d" {
+		t.Errorf("Extra=%v", d.Extra)
+	}
+	if len(d.Mismatches) != 2 {
+		t.Fatalf("Mismatches=%+v", d.Mismatches)
+	}
+	fields := map[string]bool{}
+	for _, m := range d.Mismatches {
+		if m.ID != "c" {
+			t.Errorf("差异应标记录制信号 ID: %+v", m)
+		}
+		fields[m.Field] = true
+	}
+	if !fields["SpreadBps"] || !fields["FilterReason"] {
+		t.Errorf("差异字段不符: %+v", d.Mismatches)
+	}
+}
+
+func TestDiffSignals_DuplicateKeys(t *testing.T) {
+	// 同一触发快照的两个变体信号按变体区分；同变体重复信号按顺序配对
+	v := sig("v", 1, 2, 5)
+	v.Variant = "wide"
+	rec := []*model.Signal{sig("a", 1, 2, 5), sig("a", 1, 2, 5), v}
+	got := []*model.Signal{sig("a", 1, 2, 5)}
+	d := DiffSignals(rec, got, 1e-9)
+	if d.Matched != 1 || len(d.Missing) != 2 || d.Missing[1].Variant != "wide" {
+		t.Errorf("重复键比对不符: %+v", d)
+	}
+}
@@ -0,0 +1,132 @@
+// Package bars 由中间价与价差构建固定周期的 OHLC K 线（bars.jsonl）。
+// 下游画图与波动率研究可直接使用 1s/1m 等 K 线，无需从逐笔行情重建。
+// 某周期内无更新时不输出该周期的 K 线（不做缺口填充）。
+package bars
+
+import (
+	"sort"
+)
+
+// SchemaVersion bars.jsonl 记录格式版本（字段含义变更或删除字段时递增）
+const SchemaVersion = 1
+
+// K 线序列类型
+const (
+	// SeriesMid 交易所中间价
+	SeriesMid = "mid"
+	// SeriesSpread Leader 与 Follower 中间价价差（基点，(Leader_mid - Follower_mid) / Follower_mid × 10000）
+	SeriesSpread = "spread"
+)
+
+// Bar 单根 K 线
+type Bar struct {
+	// SchemaVersion 输出格式版本
+	SchemaVersion int `json:"schema_version"`
+	// Series 序列类型: mid / spread
+	Series string `json:"series"`
+	// Source mid 为交易所（okx/binance/bittap），spread 为链路 Leader（okx/binance）
+	Source string `json:"source"`
+	// SymbolCanon 统一交易对
+	SymbolCanon string `json:"symbol"`
+	// IntervalMs K 线周期（毫秒）
+	IntervalMs int `json:"interval_ms"`
+	// StartNs 周期起点（纳秒，按周期对齐）
+	StartNs int64 `json:"start_ns"`
+	// Open/High/Low/Close 开高低收
+	Open  float64 `json:"open"`
+	High  float64 `json:"high"`
+	Low   float64 `json:"low"`
+	Close float64 `json:"close"`
+	// Count 周期内的更新次数
+	Count int `json:"count"`
+}
+
+// key K 线键（序列 + 来源 + 交易对 + 周期）
+type key struct {
+	series     string
+	source     string
+	symbol     string
+	intervalMs int
+}
+
+// Builder K 线构建器（单 goroutine 使用，由聚合器独占）
+// 新的更新落入下一周期时，上一根 K 线即完成并交给 emit。
+type Builder struct {
+	intervalsMs []int
+	emit        func(*Bar)
+	open        map[key]*Bar
+}
+
+// New 创建 K 线构建器
+// 参数 intervalsMs: K 线周期列表（毫秒，如 1000、60000）
+// 参数 emit: 完成的 K 线回调
+func New(intervalsMs []int, emit func(*Bar)) *Builder {
+	return &Builder{
+		intervalsMs: intervalsMs,
+		emit:        emit,
+		open:        make(map[key]*Bar),
+	}
+}
+
+// Observe 记录一次序列取值，并完成已跨周期的 K 线
+// 参数 tsNs: 取值时刻（纳秒，事件到达时间）
+func (b *Builder) Observe(tsNs int64, series, source, symbol string, value float64) {
+	for _, intervalMs := range b.intervalsMs {
+		intervalNs := int64(intervalMs) * 1_000_000
+		startNs := tsNs - tsNs%intervalNs
+		k := key{series: series, source: source, symbol: symbol, intervalMs: intervalMs}
+		bar := b.open[k]
+		if bar != nil && startNs > bar.StartNs {
+			b.emit(bar)
+			bar = nil
+		}
+		if bar == nil {
+			bar = &Bar{
+				SchemaVersion: SchemaVersion,
+				Series:        series,
+				Source:        source,
+				SymbolCanon:   symbol,
+				IntervalMs:    intervalMs,
+				StartNs:       startNs,
+				Open:          value,
+				High:          value,
+				Low:           value,
+			}
+			b.open[k] = bar
+		}
+		if value > bar.High {
+			bar.High = value
+		}
+		if value < bar.Low {
+			bar.Low = value
+		}
+		bar.Close = value
+		bar.Count++
+	}
+}
+
+// Flush 输出所有未完成的 K 线（停机或回放结束时调用）
+// 按序列、来源、交易对、周期排序输出，同一份回放数据的结果可复现。
+func (b *Builder) Flush() {
+	keys := make([]key, 0, len(b.open))
+	for k := range b.open {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ki, kj := keys[i], keys[j]
+		if ki.series != kj.series {
+			return ki.series < kj.series
+		}
+		if ki.source != kj.source {
+			return ki.source < kj.source
+		}
+		if ki.symbol != kj.symbol {
+			return ki.symbol < kj.symbol
+		}
+		return ki.intervalMs < kj.intervalMs
+	})
+	for _, k := range keys {
+		b.emit(b.open[k])
+		delete(b.open, k)
+	}
+}
@@ -0,0 +1,45 @@
+// Package bars K 线构建器测试
+package bars
+
+import (
+	"testing"
+)
+
+func TestBuilder_OHLC(t *testing.T) {
+	var out []*Bar
+	b := New([]int{1000, 60000}, func(bar *Bar) { out = append(out, bar) })
+
+	b.Observe(1_000_000_000, SeriesMid, "okx", "BTCUSDT", 100)
+	b.Observe(1_200_000_000, SeriesMid, "okx", "BTCUSDT", 103)
+	b.Observe(1_500_000_000, SeriesMid, "okx", "BTCUSDT", 99)
+	b.Observe(1_900_000_000, SeriesMid, "okx", "BTCUSDT", 101)
+	if len(out) != 0 {
+		t.Fatalf("周期未结束不应输出 K 线: %d", len(out))
+	}
+
+	// 跨入下一秒：1s K 线完成，1m K 线继续累积
+	b.Observe(2_100_000_000, SeriesMid, "okx", "BTCUSDT", 102)
+	if len(out) != 1 {
+		t.Fatalf("应输出 1 根 K 线，实际 %d", len(out))
+	}
+	got := out[0]
+	if got.IntervalMs != 1000 || got.StartNs != 1_000_000_000 || got.Open != 100 || got.High != 103 || got.Low != 99 || got.Close != 101 || got.Count != 4 {
+		t.Fatalf("1s K 线错误: %+v", got)
+	}
+
+	// 不同序列互不影响
+	b.Observe(2_200_000_000, SeriesSpread, "okx", "BTCUSDT", 5)
+	if len(out) != 1 {
+		t.Fatalf("不同序列不应完成其它 K 线")
+	}
+
+	b.Flush()
+	if len(out) != 5 {
+		t.Fatalf("Flush 后应共输出 5 根 K 线，实际 %d", len(out))
+	}
+	for _, bar := range out[1:] {
+		if bar.IntervalMs == 60000 && bar.Series == SeriesMid && (bar.Open != 100 || bar.Close != 102 || bar.Count != 5) {
+			t.Fatalf("1m K 线错误: %+v", bar)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package channelcmp 比较同一交易所两个行情频道的到达时延（如 OKX books5 与 bbo-tbt）。
+// 两个频道在同一连接上订阅，按（交易对, 交易所时间戳, 最优买卖价）匹配同一次盘口变化，
+// 统计两者到达时间差；未匹配的更新只计入各自的交易所时间戳 → 到达时延。
+// 用于决定哪个频道应作为 Leader 的正式行情输入。
+package channelcmp
+
+import (
+	"sort"
+	"sync"
+)
+
+// Role 频道角色
+type Role int
+
+const (
+	// RoleCanonical 正式频道（进入策略）
+	RoleCanonical Role = iota
+	// RoleCompare 对比频道（只参与统计）
+	RoleCompare
+)
+
+// windowSize 各分布保留的最近样本数
+const windowSize = 4096
+
+// matchWindowNs 等待另一频道同一变化的最长时间，超时未匹配的更新丢弃
+const matchWindowNs = 2_000_000_000
+
+// pruneEvery 每多少次观测清理一次超时的待匹配更新
+const pruneEvery = 256
+
+// Stats 两个频道的到达时延对比
+type Stats struct {
+	// Canonical / Compare 正式频道与对比频道名称
+	Canonical string `json:"canonical"`
+	Compare   string `json:"compare"`
+	// CanonicalUpdates / CompareUpdates 各频道累计更新次数
+	CanonicalUpdates int64 `json:"canonical_updates"`
+	CompareUpdates   int64 `json:"compare_updates"`
+	// Matched 两个频道均收到的同一盘口变化次数（累计）
+	Matched int64 `json:"matched"`
+	// CompareFirstShare 最近匹配样本中对比频道先到的占比
+	CompareFirstShare float64 `json:"compare_first_share"`
+	// DiffP50Ms/DiffP90Ms/DiffP99Ms 正式频道到达时间 − 对比频道到达时间（毫秒，正值表示对比频道更快）
+	DiffP50Ms float64 `json:"diff_p50_ms"`
+	DiffP90Ms float64 `json:"diff_p90_ms"`
+	DiffP99Ms float64 `json:"diff_p99_ms"`
+	// CanonicalLagP50Ms / CompareLagP50Ms 各频道 本地到达 − 交易所时间戳 的中位数（毫秒，含时钟偏差）
+	CanonicalLagP50Ms float64 `json:"canonical_lag_p50_ms"`
+	CompareLagP50Ms   float64 `json:"compare_lag_p50_ms"`
+}
+
+// matchKey 同一盘口变化的匹配键
+type matchKey struct {
+	symbol   string
+	exchTsMs int64
+	bid, ask float64
+}
+
+// pendingObs 等待另一频道匹配的更新
+type pendingObs struct {
+	role      Role
+	arrivedNs int64
+}
+
+// ring 固定容量的最近样本
+type ring struct {
+	buf  []int64
+	next int
+}
+
+func (r *ring) add(v int64) {
+	if len(r.buf) < windowSize {
+		r.buf = append(r.buf, v)
+		return
+	}
+	r.buf[r.next] = v
+	r.next = (r.next + 1) % windowSize
+}
+
+// sorted 返回升序副本
+func (r *ring) sorted() []int64 {
+	out := make([]int64, len(r.buf))
+	copy(out, r.buf)
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Tracker 频道到达时延对比追踪器
+// 由连接读循环写入、指标快照读取，需加锁。
+type Tracker struct {
+	mu        sync.Mutex
+	canonical string
+	compare   string
+	pending   map[matchKey]pendingObs
+	observed  int
+	updates   [2]int64
+	matched   int64
+	diffs     ring
+	lags      [2]ring
+}
+
+// NewTracker 创建频道对比追踪器
+// 参数 canonical: 正式频道名称
+// 参数 compare: 对比频道名称
+func NewTracker(canonical, compare string) *Tracker {
+	return &Tracker{
+		canonical: canonical,
+		compare:   compare,
+		pending:   make(map[matchKey]pendingObs),
+	}
+}
+
+// Observe 记录一个频道的一次盘口更新
+// 参数 exchTsMs: 交易所时间戳（毫秒，0 表示未知，不参与匹配与时延统计）
+// 参数 arrivedNs: 本地到达时间（纳秒）
+func (t *Tracker) Observe(role Role, symbol string, exchTsMs int64, bid, ask float64, arrivedNs int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.updates[role]++
+	if exchTsMs <= 0 {
+		return
+	}
+	t.lags[role].add(arrivedNs - exchTsMs*1_000_000)
+
+	t.observed++
+	if t.observed%pruneEvery == 0 {
+		for k, p := range t.pending {
+			if arrivedNs-p.arrivedNs > matchWindowNs {
+				delete(t.pending, k)
+			}
+		}
+	}
+
+	k := matchKey{symbol: symbol, exchTsMs: exchTsMs, bid: bid, ask: ask}
+	p, ok := t.pending[k]
+	if !ok || p.role == role {
+		// 同一频道重复推送同一变化时保留最早的到达时间
+		if !ok {
+			t.pending[k] = pendingObs{role: role, arrivedNs: arrivedNs}
+		}
+		return
+	}
+	delete(t.pending, k)
+	canonicalNs, compareNs := p.arrivedNs, arrivedNs
+	if role == RoleCanonical {
+		canonicalNs, compareNs = arrivedNs, p.arrivedNs
+	}
+	t.matched++
+	t.diffs.add(canonicalNs - compareNs)
+}
+
+// Stats 返回当前对比统计
+func (t *Tracker) Stats() Stats {
+	t.mu.Lock()
+	diffs := t.diffs.sorted()
+	canonicalLags := t.lags[RoleCanonical].sorted()
+	compareLags := t.lags[RoleCompare].sorted()
+	st := Stats{
+		Canonical:        t.canonical,
+		Compare:          t.compare,
+		CanonicalUpdates: t.updates[RoleCanonical],
+		CompareUpdates:   t.updates[RoleCompare],
+		Matched:          t.matched,
+	}
+	t.mu.Unlock()
+
+	if n := len(diffs); n > 0 {
+		firstIdx := sort.Search(n, func(i int) bool { return diffs[i] > 0 })
+		st.CompareFirstShare = float64(n-firstIdx) / float64(n)
+	}
+	st.DiffP50Ms = quantileMs(diffs, 0.5)
+	st.DiffP90Ms = quantileMs(diffs, 0.9)
+	st.DiffP99Ms = quantileMs(diffs, 0.99)
+	st.CanonicalLagP50Ms = quantileMs(canonicalLags, 0.5)
+	st.CompareLagP50Ms = quantileMs(compareLags, 0.5)
+	return st
+}
+
+// quantileMs 升序样本（纳秒）的分位数，换算为毫秒；无样本时为 0
+func quantileMs(sorted []int64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return float64(sorted[int(float64(len(sorted)-1)*q)]) / 1e6
+}
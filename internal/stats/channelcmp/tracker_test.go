@@ -0,0 +1,53 @@
+// Package channelcmp 频道到达时延对比测试
+package channelcmp
+
+import (
+	"testing"
+)
+
+func TestTracker_MatchesSameBookChange(t *testing.T) {
+	tr := NewTracker("books5", "bbo-tbt")
+	const ms = int64(1_000_000)
+
+	// 对比频道先到 5ms
+	for i := int64(0); i < 10; i++ {
+		ts := 1000 + i
+		px := 100 + float64(i)
+		tr.Observe(RoleCompare, "BTCUSDT", ts, px, px+1, (ts+20)*ms)
+		tr.Observe(RoleCanonical, "BTCUSDT", ts, px, px+1, (ts+25)*ms)
+	}
+	// 正式频道先到 1 次
+	tr.Observe(RoleCanonical, "BTCUSDT", 2000, 1, 2, 2010*ms)
+	tr.Observe(RoleCompare, "BTCUSDT", 2000, 1, 2, 2012*ms)
+	// 仅对比频道收到的变化不匹配
+	tr.Observe(RoleCompare, "BTCUSDT", 3000, 5, 6, 3020*ms)
+	// 不同交易对不匹配
+	tr.Observe(RoleCanonical, "ETHUSDT", 3000, 5, 6, 3020*ms)
+
+	st := tr.Stats()
+	if st.Matched != 11 || st.CanonicalUpdates != 12 || st.CompareUpdates != 12 {
+		t.Fatalf("计数不符: %+v", st)
+	}
+	if st.DiffP50Ms != 5 {
+		t.Errorf("DiffP50Ms=%v, want 5", st.DiffP50Ms)
+	}
+	if want := 10.0 / 11; st.CompareFirstShare != want {
+		t.Errorf("CompareFirstShare=%v, want %v", st.CompareFirstShare, want)
+	}
+	if st.CompareLagP50Ms != 20 || st.CanonicalLagP50Ms != 25 {
+		t.Errorf("频道时延不符: canonical=%v compare=%v", st.CanonicalLagP50Ms, st.CompareLagP50Ms)
+	}
+}
+
+func TestTracker_PrunesUnmatched(t *testing.T) {
+	tr := NewTracker("books5", "bbo-tbt")
+	for i := int64(0); i < pruneEvery; i++ {
+		tr.Observe(RoleCompare, "BTCUSDT", 1+i, float64(i), float64(i)+1, i*1_000_000_000)
+	}
+	if len(tr.pending) >= pruneEvery {
+		t.Fatalf("超时未匹配的更新应被清理, pending=%d", len(tr.pending))
+	}
+	if st := tr.Stats(); st.Matched != 0 || st.DiffP50Ms != 0 {
+		t.Errorf("无匹配时差值应为 0: %+v", st)
+	}
+}
@@ -0,0 +1,89 @@
+// Package equity 维护影子成交的累计净利曲线（权益曲线）与回撤统计。
+// 与 ev 包的滚动窗口不同，本包统计整个会话的累计结果。
+package equity
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// EquityStats 权益曲线统计（会话累计）
+// 单位：基点。
+type EquityStats struct {
+	// Trades 累计平仓笔数
+	Trades int64
+	// CumNetBps 累计净利（权益曲线当前值）
+	CumNetBps float64
+	// PeakBps 权益曲线历史最高值
+	PeakBps float64
+	// DrawdownBps 当前回撤（峰值 - 当前值，>=0）
+	DrawdownBps float64
+	// MaxDrawdownBps 最大回撤（>=0）
+	MaxDrawdownBps float64
+
+	// LosingStreak 当前连续亏损笔数
+	LosingStreak int64
+	// MaxLosingStreak 最长连续亏损笔数
+	MaxLosingStreak int64
+
+	// GrossProfitBps 盈利笔净利之和
+	GrossProfitBps float64
+	// GrossLossBps 亏损笔净亏绝对值之和
+	GrossLossBps float64
+	// ProfitFactor 盈亏比 = GrossProfitBps / GrossLossBps（无亏损时为 0）
+	ProfitFactor float64
+}
+
+// Curve 权益曲线（单 goroutine 使用，由聚合器独占写入）
+type Curve struct {
+	stats EquityStats
+}
+
+// NewCurve 创建权益曲线
+func NewCurve() *Curve {
+	return &Curve{}
+}
+
+// Add 追加一笔已平仓影子成交
+func (c *Curve) Add(pos *model.Position) {
+	if pos == nil || !pos.Closed {
+		return
+	}
+	s := &c.stats
+	net := pos.NetPnLBps
+
+	s.Trades++
+	s.CumNetBps += net
+	if s.CumNetBps > s.PeakBps {
+		s.PeakBps = s.CumNetBps
+	}
+	s.DrawdownBps = s.PeakBps - s.CumNetBps
+	if s.DrawdownBps > s.MaxDrawdownBps {
+		s.MaxDrawdownBps = s.DrawdownBps
+	}
+
+	if net > 0 {
+		s.GrossProfitBps += net
+		s.LosingStreak = 0
+	} else {
+		s.GrossLossBps -= net
+		s.LosingStreak++
+		if s.LosingStreak > s.MaxLosingStreak {
+			s.MaxLosingStreak = s.LosingStreak
+		}
+	}
+}
+
+// Restore 用检查点中的统计替换当前曲线（重启后延续会话累计结果）
+func (c *Curve) Restore(stats EquityStats) {
+	c.stats = stats
+	c.stats.ProfitFactor = 0
+}
+
+// Stats 返回当前统计快照
+func (c *Curve) Stats() EquityStats {
+	out := c.stats
+	if out.GrossLossBps > 0 {
+		out.ProfitFactor = out.GrossProfitBps / out.GrossLossBps
+	}
+	return out
+}
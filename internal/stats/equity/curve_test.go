@@ -0,0 +1,44 @@
+// Package equity 权益曲线测试
+package equity
+
+import (
+	"math"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestCurve_Empty(t *testing.T) {
+	s := NewCurve().Stats()
+	if s.Trades != 0 || s.CumNetBps != 0 || s.MaxDrawdownBps != 0 || s.ProfitFactor != 0 {
+		t.Fatalf("空曲线统计应为零值: %+v", s)
+	}
+}
+
+func TestCurve_DrawdownAndStreak(t *testing.T) {
+	c := NewCurve()
+	// 权益: 5, 15, 12, 4, 6, 1
+	for _, net := range []float64{5, 10, -3, -8, 2, -5} {
+		c.Add(&model.Position{Closed: true, NetPnLBps: net})
+	}
+	// 未平仓的样本应被忽略
+	c.Add(&model.Position{NetPnLBps: 100})
+
+	s := c.Stats()
+	if s.Trades != 6 {
+		t.Fatalf("Trades=%d, want 6", s.Trades)
+	}
+	if math.Abs(s.CumNetBps-1) > 1e-9 || math.Abs(s.PeakBps-15) > 1e-9 {
+		t.Fatalf("CumNetBps=%f PeakBps=%f, want 1/15", s.CumNetBps, s.PeakBps)
+	}
+	if math.Abs(s.MaxDrawdownBps-14) > 1e-9 || math.Abs(s.DrawdownBps-14) > 1e-9 {
+		t.Fatalf("MaxDrawdownBps=%f DrawdownBps=%f, want 14/14", s.MaxDrawdownBps, s.DrawdownBps)
+	}
+	if s.MaxLosingStreak != 2 || s.LosingStreak != 1 {
+		t.Fatalf("MaxLosingStreak=%d LosingStreak=%d, want 2/1", s.MaxLosingStreak, s.LosingStreak)
+	}
+	// 盈利 17，亏损 16
+	if math.Abs(s.ProfitFactor-17.0/16.0) > 1e-9 {
+		t.Fatalf("ProfitFactor=%f, want %f", s.ProfitFactor, 17.0/16.0)
+	}
+}
@@ -4,6 +4,12 @@
 package ev
 
 import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/core/model"
 )
 
@@ -12,6 +18,7 @@ type tradeSample struct {
 	grossPnLBps float64
 	feeBps      float64
 	netPnLBps   float64
+	exitTimeNs  int64
 	symbolCanon string
 	exitReason  model.ExitReason
 }
@@ -31,26 +38,39 @@ type EVStats struct {
 	AvgProfit float64
 	// AvgLoss 平均亏损 L（毛亏损绝对值，基点）
 	AvgLoss float64
-	// FeeBps 平均手续费 f（基点，入场+出场）
+	// FeeBps 平均交易成本 f（基点，入场+出场手续费，含资金/借币成本）
 	FeeBps float64
 
 	// EV 期望值（基点）
 	EV float64
 	// PRequired 盈亏平衡胜率 p_required
 	PRequired float64
+
+	// Sharpe 每笔净利的夏普比率（均值/样本标准差，不年化；样本<2 或标准差为 0 时为 0）
+	Sharpe float64
+	// Sortino 每笔净利的索提诺比率（均值/下行偏差，目标收益 0，不年化；无亏损样本时为 0）
+	Sortino float64
+
+	// EWMA 为 true 时 WinRate/AvgProfit/AvgLoss/FeeBps/EV/PRequired 为指数加权结果
+	// （Count/WinCount/LossCount 仍为窗口内样本数）
+	EWMA bool
 }
 
 // Calculator EV 计算器（滚动窗口）
 // 仅用于研究/验证，输入来自影子成交结果（Position）。
+// 默认按最近 N 笔成交滚动；设置时间窗口后额外剔除出场时间早于 now-窗口 的样本。
+// 并发安全：分片聚合器可从多个 goroutine 写入与读取同一计算器。
 type Calculator struct {
-	// windowSize 滚动窗口大小
+	mu sync.Mutex
+
+	// windowSize 滚动窗口大小（时间窗口模式下为样本数上限）
 	windowSize int
+	// windowNs 时间窗口（纳秒，0 表示仅按笔数滚动）
+	windowNs int64
 	// buf 环形缓冲区
 	buf []tradeSample
 	// pos 写入位置
 	pos int
-	// full 是否已填满
-	full bool
 
 	// 维护滚动统计（O(1) 更新）
 	count     int64
@@ -59,6 +79,32 @@ type Calculator struct {
 	sumWinR   float64
 	sumLossL  float64
 	sumFee    float64
+	// sumNet/sumNetSq/sumDownSq 用于 Sharpe/Sortino（下行项仅累计净利<0 的平方）
+	sumNet    float64
+	sumNetSq  float64
+	sumDownSq float64
+
+	// ewmaAlpha 指数加权衰减系数（0 表示不启用）
+	// 每笔新成交权重为 1，历史权重乘以 (1-alpha)。
+	ewmaAlpha float64
+	ewWin     float64
+	ewLoss    float64
+	ewWinR    float64
+	ewLossL   float64
+	ewFee     float64
+
+	// bySymbol 按交易对维护的窗口统计（O(1) 更新，供持仓期间 EV 恶化退出判断）
+	bySymbol map[string]*symbolSums
+}
+
+// symbolSums 单个交易对在窗口内的累计量
+type symbolSums struct {
+	count     int64
+	winCount  int64
+	lossCount int64
+	sumWinR   float64
+	sumLossL  float64
+	sumFee    float64
 }
 
 // NewCalculator 创建 EV 计算器
@@ -70,7 +116,21 @@ func NewCalculator(windowSize int) *Calculator {
 	return &Calculator{
 		windowSize: windowSize,
 		buf:        make([]tradeSample, windowSize),
+		bySymbol:   make(map[string]*symbolSums),
+	}
+}
+
+// NewCalculatorFromConfig 按配置创建 EV 计算器
+// WindowMs>0 时按时间窗口滚动（WindowSize 作为样本数上限）。
+func NewCalculatorFromConfig(cfg config.EVConfig) *Calculator {
+	c := NewCalculator(cfg.WindowSize)
+	if cfg.WindowMs > 0 {
+		c.windowNs = cfg.WindowMs * int64(time.Millisecond)
+	}
+	if cfg.EWMAAlpha > 0 && cfg.EWMAAlpha <= 1 {
+		c.ewmaAlpha = cfg.EWMAAlpha
 	}
+	return c
 }
 
 // Add 添加一笔影子成交结果到滚动统计
@@ -78,35 +138,38 @@ func (c *Calculator) Add(pos *model.Position) {
 	if pos == nil || !pos.Closed {
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	s := tradeSample{
 		win:         pos.NetPnLBps > 0,
 		grossPnLBps: pos.GrossPnLBps,
-		feeBps:      pos.FeeBps,
+		feeBps:      pos.CostBps(),
 		netPnLBps:   pos.NetPnLBps,
+		exitTimeNs:  pos.ExitTimeNs,
 		symbolCanon: pos.SymbolCanon,
 		exitReason:  pos.ExitReason,
 	}
 
-	// 若环已满，移除旧样本对统计的贡献
-	if c.full {
-		old := c.buf[c.pos]
-		c.count--
-		if old.win {
-			c.winCount--
-			c.sumWinR -= old.grossPnLBps
-		} else {
-			c.lossCount--
-			c.sumLossL -= abs(old.grossPnLBps)
-		}
-		c.sumFee -= old.feeBps
+	c.push(s)
+	if c.ewmaAlpha > 0 {
+		c.addEWMA(s)
+	}
+
+	c.expire(s.exitTimeNs)
+}
+
+// push 将样本写入滚动窗口并更新窗口统计
+func (c *Calculator) push(s tradeSample) {
+	// 若环已满，移除最旧样本对统计的贡献
+	if c.count >= int64(c.windowSize) {
+		c.removeOldest()
 	}
 
 	c.buf[c.pos] = s
 	c.pos++
 	if c.pos >= c.windowSize {
 		c.pos = 0
-		c.full = true
 	}
 
 	c.count++
@@ -118,6 +181,109 @@ func (c *Calculator) Add(pos *model.Position) {
 		c.sumLossL += abs(s.grossPnLBps)
 	}
 	c.sumFee += s.feeBps
+	c.sumNet += s.netPnLBps
+	c.sumNetSq += s.netPnLBps * s.netPnLBps
+	if s.netPnLBps < 0 {
+		c.sumDownSq += s.netPnLBps * s.netPnLBps
+	}
+
+	sym := c.bySymbol[s.symbolCanon]
+	if sym == nil {
+		sym = &symbolSums{}
+		c.bySymbol[s.symbolCanon] = sym
+	}
+	sym.count++
+	if s.win {
+		sym.winCount++
+		sym.sumWinR += s.grossPnLBps
+	} else {
+		sym.lossCount++
+		sym.sumLossL += abs(s.grossPnLBps)
+	}
+	sym.sumFee += s.feeBps
+}
+
+// addEWMA 更新指数加权累计量（不受滚动窗口剔除影响）
+func (c *Calculator) addEWMA(s tradeSample) {
+	decay := 1 - c.ewmaAlpha
+	c.ewWin *= decay
+	c.ewLoss *= decay
+	c.ewWinR *= decay
+	c.ewLossL *= decay
+	c.ewFee *= decay
+
+	if s.win {
+		c.ewWin++
+		c.ewWinR += s.grossPnLBps
+	} else {
+		c.ewLoss++
+		c.ewLossL += abs(s.grossPnLBps)
+	}
+	c.ewFee += s.feeBps
+}
+
+// Expire 剔除出场时间早于 nowNs-窗口 的样本（仅时间窗口模式生效）
+// 聚合器在做 EV 拒绝判断与输出指标前调用，避免冷门交易对长期沿用陈旧样本。
+// 参数 nowNs: 当前时间（纳秒；回放时为事件时间）
+func (c *Calculator) Expire(nowNs int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(nowNs)
+}
+
+// expire 剔除过期样本（调用方持有锁）
+func (c *Calculator) expire(nowNs int64) {
+	if c.windowNs <= 0 || nowNs <= 0 {
+		return
+	}
+	cutoff := nowNs - c.windowNs
+	for c.count > 0 && c.buf[c.oldest()].exitTimeNs < cutoff {
+		c.removeOldest()
+	}
+}
+
+// oldest 返回最旧样本在环中的下标
+func (c *Calculator) oldest() int {
+	i := c.pos - int(c.count)
+	if i < 0 {
+		i += c.windowSize
+	}
+	return i
+}
+
+// removeOldest 移除最旧样本对统计的贡献
+func (c *Calculator) removeOldest() {
+	old := c.buf[c.oldest()]
+	c.count--
+	if old.win {
+		c.winCount--
+		c.sumWinR -= old.grossPnLBps
+	} else {
+		c.lossCount--
+		c.sumLossL -= abs(old.grossPnLBps)
+	}
+	c.sumFee -= old.feeBps
+	c.sumNet -= old.netPnLBps
+	c.sumNetSq -= old.netPnLBps * old.netPnLBps
+	if old.netPnLBps < 0 {
+		c.sumDownSq -= old.netPnLBps * old.netPnLBps
+	}
+
+	if sym := c.bySymbol[old.symbolCanon]; sym != nil {
+		sym.count--
+		if sym.count <= 0 {
+			delete(c.bySymbol, old.symbolCanon)
+			return
+		}
+		if old.win {
+			sym.winCount--
+			sym.sumWinR -= old.grossPnLBps
+		} else {
+			sym.lossCount--
+			sym.sumLossL -= abs(old.grossPnLBps)
+		}
+		sym.sumFee -= old.feeBps
+	}
 }
 
 // Snapshot 获取当前 EV 统计快照
@@ -134,6 +300,8 @@ func (c *Calculator) Snapshot() *model.EVSnapshot {
 
 // Stats 返回滚动窗口统计
 func (c *Calculator) Stats() EVStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	out := EVStats{
 		Count:     c.count,
 		WinCount:  c.winCount,
@@ -143,34 +311,160 @@ func (c *Calculator) Stats() EVStats {
 		return out
 	}
 
-	out.WinRate = float64(c.winCount) / float64(c.count)
-	out.FeeBps = c.sumFee / float64(c.count)
+	if c.ewmaAlpha > 0 {
+		// 指数加权：近期样本权重更高，使 EV 拒绝更快响应行情切换
+		total := c.ewWin + c.ewLoss
+		out.EWMA = true
+		out.WinRate = c.ewWin / total
+		out.FeeBps = c.ewFee / total
+		if c.ewWin > 0 {
+			out.AvgProfit = c.ewWinR / c.ewWin
+		}
+		if c.ewLoss > 0 {
+			out.AvgLoss = c.ewLossL / c.ewLoss
+		}
+	} else {
+		out.WinRate = float64(c.winCount) / float64(c.count)
+		out.FeeBps = c.sumFee / float64(c.count)
 
-	if c.winCount > 0 {
-		out.AvgProfit = c.sumWinR / float64(c.winCount)
+		if c.winCount > 0 {
+			out.AvgProfit = c.sumWinR / float64(c.winCount)
+		}
+		if c.lossCount > 0 {
+			out.AvgLoss = c.sumLossL / float64(c.lossCount)
+		}
 	}
-	if c.lossCount > 0 {
-		out.AvgLoss = c.sumLossL / float64(c.lossCount)
+
+	out.EV, out.PRequired = expectedValue(out.WinRate, out.AvgProfit, out.AvgLoss, out.FeeBps)
+	out.Sharpe, out.Sortino = c.riskRatios()
+	return out
+}
+
+// SymbolStats 返回单个交易对在滚动窗口内的统计
+// 始终按窗口等权计算（不受 EWMA 影响），不含 Sharpe/Sortino。
+func (c *Calculator) SymbolStats(symbolCanon string) EVStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sym := c.bySymbol[symbolCanon]
+	if sym == nil || sym.count <= 0 {
+		return EVStats{}
+	}
+	out := EVStats{
+		Count:     sym.count,
+		WinCount:  sym.winCount,
+		LossCount: sym.lossCount,
+		WinRate:   float64(sym.winCount) / float64(sym.count),
+		FeeBps:    sym.sumFee / float64(sym.count),
 	}
+	if sym.winCount > 0 {
+		out.AvgProfit = sym.sumWinR / float64(sym.winCount)
+	}
+	if sym.lossCount > 0 {
+		out.AvgLoss = sym.sumLossL / float64(sym.lossCount)
+	}
+	out.EV, out.PRequired = expectedValue(out.WinRate, out.AvgProfit, out.AvgLoss, out.FeeBps)
+	return out
+}
 
-	// EV = p × (R - f) + (1 - p) × (-L - f)
-	p := out.WinRate
-	R := out.AvgProfit
-	L := out.AvgLoss
-	f := out.FeeBps
-	out.EV = p*(R-f) + (1-p)*(-L-f)
+// NetPnLSamples 返回窗口内每笔净利（基点，按出场先后排序，显著性检验用）
+func (c *Calculator) NetPnLSamples() []float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	samples := c.samples()
+	out := make([]float64, len(samples))
+	for i, s := range samples {
+		out[i] = s.netPnLBps
+	}
+	return out
+}
 
-	// p_required = (L + f) / (R + L)
-	den := R + L
-	if den > 0 {
-		out.PRequired = (L + f) / den
-	} else {
-		out.PRequired = 1
+// Reset 清空窗口样本与 EWMA 累计量（窗口配置保留）
+func (c *Calculator) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
+}
+
+// reset 清空统计（调用方持有锁）
+func (c *Calculator) reset() {
+	c.pos, c.count, c.winCount, c.lossCount = 0, 0, 0, 0
+	c.sumWinR, c.sumLossL, c.sumFee = 0, 0, 0
+	c.sumNet, c.sumNetSq, c.sumDownSq = 0, 0, 0
+	c.bySymbol = make(map[string]*symbolSums)
+	c.ewWin, c.ewLoss, c.ewWinR, c.ewLossL, c.ewFee = 0, 0, 0, 0, 0
+}
+
+// Merge 将另一个计算器的窗口样本并入本计算器（分片或变体汇总为全局统计）
+// 双方样本按出场时间合并后重建窗口，超出本计算器窗口大小时仅保留最新的样本；
+// EWMA 累计量直接相加（近似：双方各自按本方最新成交衰减）。other 不被修改。
+func (c *Calculator) Merge(other *Calculator) {
+	if other == nil || other == c {
+		return
+	}
+	other.mu.Lock()
+	theirs := other.samples()
+	oWin, oLoss, oWinR, oLossL, oFee := other.ewWin, other.ewLoss, other.ewWinR, other.ewLossL, other.ewFee
+	other.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	merged := append(c.samples(), theirs...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].exitTimeNs < merged[j].exitTimeNs })
+	ewWin, ewLoss, ewWinR, ewLossL, ewFee := c.ewWin, c.ewLoss, c.ewWinR, c.ewLossL, c.ewFee
+	c.reset()
+	for _, s := range merged {
+		c.push(s)
 	}
+	if c.ewmaAlpha > 0 {
+		c.ewWin, c.ewLoss = ewWin+oWin, ewLoss+oLoss
+		c.ewWinR, c.ewLossL, c.ewFee = ewWinR+oWinR, ewLossL+oLossL, ewFee+oFee
+	}
+}
 
+// samples 返回窗口内样本副本（旧 -> 新，调用方持有锁）
+func (c *Calculator) samples() []tradeSample {
+	out := make([]tradeSample, 0, c.count)
+	for i, idx := int64(0), c.oldest(); i < c.count; i++ {
+		out = append(out, c.buf[idx])
+		idx++
+		if idx >= c.windowSize {
+			idx = 0
+		}
+	}
 	return out
 }
 
+// expectedValue 计算 EV 与盈亏平衡胜率
+// EV = p × (R - f) + (1 - p) × (-L - f)
+// p_required = (L + f) / (R + L)（R + L 为 0 时取 1）
+func expectedValue(p, R, L, f float64) (ev, pRequired float64) {
+	ev = p*(R-f) + (1-p)*(-L-f)
+	if den := R + L; den > 0 {
+		return ev, (L + f) / den
+	}
+	return ev, 1
+}
+
+// riskRatios 计算窗口内每笔净利的 Sharpe 与 Sortino（不年化）
+func (c *Calculator) riskRatios() (sharpe, sortino float64) {
+	n := float64(c.count)
+	mean := c.sumNet / n
+
+	if c.count >= 2 {
+		// 样本方差：(Σx² - n·mean²) / (n-1)，浮点误差可能导致微小负值
+		variance := (c.sumNetSq - n*mean*mean) / (n - 1)
+		if variance > 1e-12 {
+			sharpe = mean / math.Sqrt(variance)
+		}
+	}
+
+	downside := c.sumDownSq / n
+	if downside > 1e-12 {
+		sortino = mean / math.Sqrt(downside)
+	}
+	return sharpe, sortino
+}
+
 func abs(v float64) float64 {
 	if v < 0 {
 		return -v
@@ -3,9 +3,11 @@ package ev
 
 import (
 	"math"
+	"sync"
 	"testing"
 	"time"
 
+	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/core/model"
 )
 
@@ -66,4 +68,237 @@ func TestCalculator_RollingWindow(t *testing.T) {
 	if math.Abs(stats.AvgLoss-10.0) > 1e-9 {
 		t.Fatalf("AvgLoss=%f, want 10", stats.AvgLoss)
 	}
+	if got := c.NetPnLSamples(); len(got) != 2 || got[0] != -1 || got[1] != 1 {
+		t.Fatalf("NetPnLSamples=%v, want [-1 1]", got)
+	}
+}
+
+func TestCalculator_SymbolStats(t *testing.T) {
+	c := NewCalculator(3)
+
+	c.Add(&model.Position{Closed: true, SymbolCanon: "BTCUSDT", NetPnLBps: 8, GrossPnLBps: 10, FeeBps: 2})
+	c.Add(&model.Position{Closed: true, SymbolCanon: "ETHUSDT", NetPnLBps: 3, GrossPnLBps: 5, FeeBps: 2})
+	c.Add(&model.Position{Closed: true, SymbolCanon: "BTCUSDT", NetPnLBps: -22, GrossPnLBps: -20, FeeBps: 2})
+
+	// BTC: p=1/2, R=10, L=20, f=2 => EV=-7
+	btc := c.SymbolStats("BTCUSDT")
+	if btc.Count != 2 || math.Abs(btc.EV-(-7)) > 1e-9 {
+		t.Fatalf("BTC stats=%+v, want Count=2 EV=-7", btc)
+	}
+
+	// 窗口滚动剔除最早的 BTC 盈利样本：BTC 仅剩亏损
+	c.Add(&model.Position{Closed: true, SymbolCanon: "ETHUSDT", NetPnLBps: 3, GrossPnLBps: 5, FeeBps: 2})
+	btc = c.SymbolStats("BTCUSDT")
+	if btc.Count != 1 || btc.WinCount != 0 || math.Abs(btc.EV-(-22)) > 1e-9 {
+		t.Fatalf("BTC stats=%+v, want Count=1 EV=-22", btc)
+	}
+	if eth := c.SymbolStats("ETHUSDT"); eth.Count != 2 || eth.EV <= 0 {
+		t.Fatalf("ETH stats=%+v, want Count=2 EV>0", eth)
+	}
+	if sol := c.SymbolStats("SOLUSDT"); sol.Count != 0 {
+		t.Fatalf("SOL stats=%+v, want 空", sol)
+	}
+}
+
+func TestCalculator_SharpeSortino(t *testing.T) {
+	c := NewCalculator(2)
+	// 滑出窗口的样本不应影响结果
+	c.Add(&model.Position{Closed: true, NetPnLBps: -100, GrossPnLBps: -98, FeeBps: 2})
+	c.Add(&model.Position{Closed: true, NetPnLBps: 6, GrossPnLBps: 8, FeeBps: 2})
+	c.Add(&model.Position{Closed: true, NetPnLBps: -2, GrossPnLBps: 0, FeeBps: 2})
+
+	stats := c.Stats()
+	// 净利 {6,-2}: mean=2, 样本标准差=sqrt(32), 下行偏差=sqrt(4/2)
+	if math.Abs(stats.Sharpe-2/math.Sqrt(32)) > 1e-9 {
+		t.Fatalf("Sharpe=%f, want %f", stats.Sharpe, 2/math.Sqrt(32))
+	}
+	if math.Abs(stats.Sortino-2/math.Sqrt(2)) > 1e-9 {
+		t.Fatalf("Sortino=%f, want %f", stats.Sortino, 2/math.Sqrt(2))
+	}
+
+	// 无亏损样本：Sortino 为 0
+	c2 := NewCalculator(10)
+	c2.Add(&model.Position{Closed: true, NetPnLBps: 3, GrossPnLBps: 5, FeeBps: 2})
+	c2.Add(&model.Position{Closed: true, NetPnLBps: 5, GrossPnLBps: 7, FeeBps: 2})
+	if s := c2.Stats(); s.Sortino != 0 || s.Sharpe <= 0 {
+		t.Fatalf("Sharpe=%f Sortino=%f, want >0/0", s.Sharpe, s.Sortino)
+	}
+}
+
+func TestCalculator_TimeWindow(t *testing.T) {
+	// 时间窗口 1s，样本数上限 10
+	c := NewCalculatorFromConfig(config.EVConfig{WindowSize: 10, WindowMs: 1000})
+
+	const sec = int64(time.Second)
+	c.Add(&model.Position{Closed: true, NetPnLBps: -8, GrossPnLBps: -6, FeeBps: 2, ExitTimeNs: 1 * sec})
+	c.Add(&model.Position{Closed: true, NetPnLBps: 5, GrossPnLBps: 7, FeeBps: 2, ExitTimeNs: 1*sec + sec/2})
+	if s := c.Stats(); s.Count != 2 {
+		t.Fatalf("Count=%d, want 2", s.Count)
+	}
+
+	// 新样本到达时剔除过期样本（1s 前的亏损单）
+	c.Add(&model.Position{Closed: true, NetPnLBps: 3, GrossPnLBps: 5, FeeBps: 2, ExitTimeNs: 2*sec + sec/4})
+	s := c.Stats()
+	if s.Count != 2 || s.LossCount != 0 {
+		t.Fatalf("Count=%d LossCount=%d, want 2/0", s.Count, s.LossCount)
+	}
+
+	// 无新成交时由 Expire 推进
+	c.Expire(10 * sec)
+	if s := c.Stats(); s.Count != 0 || s.EV != 0 {
+		t.Fatalf("全部过期后 Count=%d EV=%f, want 0/0", s.Count, s.EV)
+	}
+
+	// 过期后可继续写入，环形缓冲下标保持一致
+	c.Add(&model.Position{Closed: true, NetPnLBps: 1, GrossPnLBps: 3, FeeBps: 2, ExitTimeNs: 11 * sec})
+	if s := c.Stats(); s.Count != 1 || math.Abs(s.AvgProfit-3) > 1e-9 {
+		t.Fatalf("Count=%d AvgProfit=%f, want 1/3", s.Count, s.AvgProfit)
+	}
+}
+
+func TestCalculator_EWMA(t *testing.T) {
+	c := NewCalculatorFromConfig(config.EVConfig{WindowSize: 100, EWMAAlpha: 0.5})
+
+	// 早期 4 笔盈利，随后 2 笔亏损：等权 EV 为正，指数加权后转负
+	for i := 0; i < 4; i++ {
+		c.Add(&model.Position{Closed: true, NetPnLBps: 8, GrossPnLBps: 10, FeeBps: 2})
+	}
+	for i := 0; i < 2; i++ {
+		c.Add(&model.Position{Closed: true, NetPnLBps: -12, GrossPnLBps: -10, FeeBps: 2})
+	}
+
+	s := c.Stats()
+	if !s.EWMA || s.Count != 6 {
+		t.Fatalf("EWMA=%v Count=%d, want true/6", s.EWMA, s.Count)
+	}
+	// 权重: 盈利 0.03125+0.0625+0.125+0.25=0.46875，亏损 0.5+1=1.5
+	wantP := 0.46875 / 1.96875
+	if math.Abs(s.WinRate-wantP) > 1e-9 {
+		t.Fatalf("WinRate=%f, want %f", s.WinRate, wantP)
+	}
+	wantEV := wantP*(10-2) + (1-wantP)*(-10-2)
+	if math.Abs(s.EV-wantEV) > 1e-9 || s.EV >= 0 {
+		t.Fatalf("EV=%f, want %f (<0)", s.EV, wantEV)
+	}
+
+	plain := NewCalculator(100)
+	for i := 0; i < 4; i++ {
+		plain.Add(&model.Position{Closed: true, NetPnLBps: 8, GrossPnLBps: 10, FeeBps: 2})
+	}
+	for i := 0; i < 2; i++ {
+		plain.Add(&model.Position{Closed: true, NetPnLBps: -12, GrossPnLBps: -10, FeeBps: 2})
+	}
+	if ps := plain.Stats(); ps.EWMA || ps.EV <= 0 {
+		t.Fatalf("等权 EWMA=%v EV=%f, want false/>0", ps.EWMA, ps.EV)
+	}
+}
+
+func TestCalculator_StateRoundTrip(t *testing.T) {
+	cfg := config.EVConfig{WindowSize: 3, EWMAAlpha: 0.2}
+	c := NewCalculatorFromConfig(cfg)
+	for i, net := range []float64{5, -3, 8, -1} {
+		c.Add(&model.Position{Closed: true, NetPnLBps: net, GrossPnLBps: net + 2, FeeBps: 2, ExitTime: time.Unix(int64(i), 0)})
+	}
+
+	restored := NewCalculatorFromConfig(cfg)
+	restored.RestoreState(c.ExportState())
+	if got, want := restored.Stats(), c.Stats(); got != want {
+		t.Fatalf("恢复后统计不一致:\n got=%+v\nwant=%+v", got, want)
+	}
+
+	// 恢复后继续累计，行为应与原计算器一致
+	p := &model.Position{Closed: true, NetPnLBps: 4, GrossPnLBps: 6, FeeBps: 2, ExitTime: time.Unix(10, 0)}
+	c.Add(p)
+	restored.Add(p)
+	if got, want := restored.Stats(), c.Stats(); got != want {
+		t.Fatalf("继续累计后统计不一致:\n got=%+v\nwant=%+v", got, want)
+	}
+}
+
+func TestCalculator_ResetAndMerge(t *testing.T) {
+	a := NewCalculator(10)
+	b := NewCalculator(10)
+	all := NewCalculator(10)
+	for i, net := range []float64{5, -3, 8, -1, 2} {
+		p := &model.Position{Closed: true, SymbolCanon: "BTCUSDT", NetPnLBps: net, GrossPnLBps: net + 2, FeeBps: 2, ExitTimeNs: int64(i + 1)}
+		if i%2 == 0 {
+			a.Add(p)
+		} else {
+			b.Add(p)
+		}
+		all.Add(p)
+	}
+
+	a.Merge(b)
+	if got, want := a.Stats(), all.Stats(); got != want {
+		t.Fatalf("合并后统计不一致:\n got=%+v\nwant=%+v", got, want)
+	}
+	if got, want := a.SymbolStats("BTCUSDT"), all.SymbolStats("BTCUSDT"); got != want {
+		t.Fatalf("合并后交易对统计不一致:\n got=%+v\nwant=%+v", got, want)
+	}
+	if b.Stats().Count != 2 {
+		t.Fatalf("被合并方 Count=%d, want 2（不应被修改）", b.Stats().Count)
+	}
+
+	// 窗口较小时仅保留最新样本
+	small := NewCalculator(2)
+	small.Merge(all)
+	if got := small.NetPnLSamples(); len(got) != 2 || got[0] != -1 || got[1] != 2 {
+		t.Fatalf("小窗口合并样本=%v, want [-1 2]", got)
+	}
+
+	a.Reset()
+	if st := a.Stats(); st.Count != 0 || st.EV != 0 {
+		t.Fatalf("Reset 后统计未清空: %+v", st)
+	}
+	if st := a.SymbolStats("BTCUSDT"); st.Count != 0 {
+		t.Fatalf("Reset 后交易对统计未清空: %+v", st)
+	}
+}
+
+func TestCalculator_Concurrent(t *testing.T) {
+	c := NewCalculator(100)
+	other := NewCalculator(100)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				c.Add(&model.Position{Closed: true, SymbolCanon: "BTCUSDT", NetPnLBps: float64(i%7 - 3), FeeBps: 2, ExitTimeNs: int64(i)})
+				_ = c.Stats()
+				_ = c.SymbolStats("BTCUSDT")
+				if g == 0 && i%50 == 0 {
+					other.Merge(c)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if st := c.Stats(); st.Count != 100 {
+		t.Fatalf("Count=%d, want 100", st.Count)
+	}
+}
+
+func TestHourOfDay_Buckets(t *testing.T) {
+	h := NewHourOfDay()
+	hour := int64(3600) * int64(time.Second)
+	h.AddTrade(1*hour+5, 10, 2, 8)   // 01:00 赢
+	h.AddTrade(1*hour+10, -6, 2, -8) // 01:00 输
+	h.AddTrade(25*hour, 4, 2, 2)     // 次日 01:00 赢
+	h.AddTrade(13*hour, 4, 2, 2)     // 13:00 赢
+	h.Add(&model.Position{Closed: false, EntryTimeNs: 2 * hour})
+
+	stats := h.Stats()
+	if len(stats) != 2 || stats[0].Hour != 1 || stats[1].Hour != 13 {
+		t.Fatalf("stats=%+v, want hours [1 13]", stats)
+	}
+	s := stats[0]
+	if s.Count != 3 || math.Abs(s.WinRate-2.0/3.0) > 1e-9 || math.Abs(s.AvgNetBps-2.0/3.0) > 1e-9 {
+		t.Fatalf("hour 1: %+v", s)
+	}
+	// p=2/3, R=7, L=6, f=2 => EV = 2/3×5 + 1/3×(-8) = 2/3
+	if math.Abs(s.EV-2.0/3.0) > 1e-9 {
+		t.Fatalf("EV=%f, want %f", s.EV, 2.0/3.0)
+	}
 }
@@ -0,0 +1,95 @@
+package ev
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// HourEVStats 单个 UTC 小时的 EV 统计（会话累计）
+type HourEVStats struct {
+	// Hour UTC 小时 [0, 23]
+	Hour int `json:"hour"`
+	// Count 样本数
+	Count int64 `json:"count"`
+	// WinRate 胜率 p
+	WinRate float64 `json:"win_rate"`
+	// AvgNetBps 平均净利（基点）
+	AvgNetBps float64 `json:"avg_net_bps"`
+	// EV 期望值（基点）
+	EV float64 `json:"ev"`
+	// PRequired 盈亏平衡胜率
+	PRequired float64 `json:"p_required"`
+}
+
+type hourBucket struct {
+	count     int64
+	winCount  int64
+	lossCount int64
+	sumWinR   float64
+	sumLossL  float64
+	sumFee    float64
+	sumNet    float64
+}
+
+// HourOfDay 按入场时间的 UTC 小时分桶累计 EV
+// Bittap 时延与可捕获边际在亚洲/欧洲/美洲时段可能不同，分桶结果用于安排部署时段。
+// 非并发安全：由聚合器 goroutine 独占。
+type HourOfDay struct {
+	buckets [24]hourBucket
+}
+
+// NewHourOfDay 创建按小时分桶的 EV 统计
+func NewHourOfDay() *HourOfDay {
+	return &HourOfDay{}
+}
+
+// Add 添加一笔已平仓的影子成交
+func (h *HourOfDay) Add(pos *model.Position) {
+	if pos == nil || !pos.Closed {
+		return
+	}
+	h.AddTrade(pos.EntryTimeNs, pos.GrossPnLBps, pos.CostBps(), pos.NetPnLBps)
+}
+
+// AddTrade 按字段添加一笔成交（供离线报告使用）
+// 参数 entryNs: 入场时间（纳秒），决定所属小时
+func (h *HourOfDay) AddTrade(entryNs int64, grossBps, feeBps, netBps float64) {
+	b := &h.buckets[timeutil.HourOfDayUTC(entryNs)]
+	b.count++
+	if netBps > 0 {
+		b.winCount++
+		b.sumWinR += grossBps
+	} else {
+		b.lossCount++
+		b.sumLossL += abs(grossBps)
+	}
+	b.sumFee += feeBps
+	b.sumNet += netBps
+}
+
+// Stats 获取有样本的各小时统计（按小时升序）
+func (h *HourOfDay) Stats() []HourEVStats {
+	var out []HourEVStats
+	for hour, b := range h.buckets {
+		if b.count == 0 {
+			continue
+		}
+		n := float64(b.count)
+		s := HourEVStats{
+			Hour:      hour,
+			Count:     b.count,
+			WinRate:   float64(b.winCount) / n,
+			AvgNetBps: b.sumNet / n,
+		}
+		var R, L float64
+		if b.winCount > 0 {
+			R = b.sumWinR / float64(b.winCount)
+		}
+		if b.lossCount > 0 {
+			L = b.sumLossL / float64(b.lossCount)
+		}
+		s.EV, s.PRequired = expectedValue(s.WinRate, R, L, b.sumFee/n)
+		out = append(out, s)
+	}
+	return out
+}
@@ -5,12 +5,15 @@ import "latency-arbitrage-validator/internal/core/model"
 
 // ApplyRejection 将 EV 结果应用到套利信号上
 // 规则：当已有样本（Count>0）且 EV<0 时，标记信号为 RejectedByEV。
+// 信号已被引擎过滤（如波动率）时保留原 FilterReason。
 func ApplyRejection(sig *model.Signal, stats EVStats) {
 	if sig == nil {
 		return
 	}
 	if stats.Count > 0 && stats.EV < 0 {
 		sig.RejectedByEV = true
-		sig.FilterReason = "ev_negative"
+		if sig.FilterReason == "" {
+			sig.FilterReason = "ev_negative"
+		}
 	}
 }
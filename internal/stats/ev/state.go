@@ -0,0 +1,80 @@
+package ev
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// Sample 滚动窗口中的一笔样本（检查点序列化用）
+type Sample struct {
+	Win         bool    `json:"win"`
+	GrossPnLBps float64 `json:"gross_pnl_bps"`
+	FeeBps      float64 `json:"fee_bps"`
+	NetPnLBps   float64 `json:"net_pnl_bps"`
+	ExitTimeNs  int64   `json:"exit_time_ns"`
+	SymbolCanon string  `json:"symbol_canon"`
+	ExitReason  string  `json:"exit_reason"`
+}
+
+// EWMAState 指数加权累计量
+type EWMAState struct {
+	Win   float64 `json:"win"`
+	Loss  float64 `json:"loss"`
+	WinR  float64 `json:"win_r"`
+	LossL float64 `json:"loss_l"`
+	Fee   float64 `json:"fee"`
+}
+
+// State EV 计算器状态（检查点）
+type State struct {
+	// Samples 窗口内样本（旧 -> 新）
+	Samples []Sample `json:"samples"`
+	// EWMA 指数加权累计量（未启用 EWMA 时为 nil）
+	EWMA *EWMAState `json:"ewma,omitempty"`
+}
+
+// ExportState 导出当前窗口样本与 EWMA 累计量
+func (c *Calculator) ExportState() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := State{Samples: make([]Sample, 0, c.count)}
+	for _, s := range c.samples() {
+		st.Samples = append(st.Samples, Sample{
+			Win:         s.win,
+			GrossPnLBps: s.grossPnLBps,
+			FeeBps:      s.feeBps,
+			NetPnLBps:   s.netPnLBps,
+			ExitTimeNs:  s.exitTimeNs,
+			SymbolCanon: s.symbolCanon,
+			ExitReason:  string(s.exitReason),
+		})
+	}
+	if c.ewmaAlpha > 0 {
+		st.EWMA = &EWMAState{Win: c.ewWin, Loss: c.ewLoss, WinR: c.ewWinR, LossL: c.ewLossL, Fee: c.ewFee}
+	}
+	return st
+}
+
+// RestoreState 用检查点替换当前状态
+// 窗口大小变小时仅保留最新的样本；时间窗口在下一次 Expire 时生效。
+func (c *Calculator) RestoreState(st State) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
+
+	for _, s := range st.Samples {
+		c.push(tradeSample{
+			win:         s.Win,
+			grossPnLBps: s.GrossPnLBps,
+			feeBps:      s.FeeBps,
+			netPnLBps:   s.NetPnLBps,
+			exitTimeNs:  s.ExitTimeNs,
+			symbolCanon: s.SymbolCanon,
+			exitReason:  model.ExitReason(s.ExitReason),
+		})
+	}
+
+	if c.ewmaAlpha > 0 && st.EWMA != nil {
+		e := st.EWMA
+		c.ewWin, c.ewLoss, c.ewWinR, c.ewLossL, c.ewFee = e.Win, e.Loss, e.WinR, e.LossL, e.Fee
+	}
+}
@@ -0,0 +1,429 @@
+// Package infoshare 估计 Leader 与 Follower 在价格发现中的贡献份额。
+// 两个市场交易同一标的，对数中间价协整（协整向量 (1, -1)），对其拟合 VECM：
+//
+//	ΔP_t = α (P_L,t-1 − P_F,t-1) + Σ Γ_k ΔP_t-k + c + e_t
+//
+// 由误差修正系数 α 的正交补得到 Gonzalo-Granger 成分份额（CS），
+// 结合残差协方差得到 Hasbrouck 信息份额（IS）。IS 依赖 Cholesky 分解的变量顺序，
+// 分别以 Leader 在前/在后计算得到上下界。Leader 份额接近 1 说明价格发现主要发生在 Leader。
+//
+// 中间价按固定采样间隔（如 1s）前向填充；采样（Observe）在聚合器 goroutine 中进行，
+// 估计（Compute）开销较大，应在快照后于独立 goroutine 中执行。
+package infoshare
+
+import (
+	"math"
+	"sort"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// Result 单条链路、单个交易对的信息份额估计结果
+type Result struct {
+	// TsUnixNs 计算时间（纳秒）
+	TsUnixNs int64 `json:"ts_unix_ns"`
+	// Leader 领先交易所: okx 或 binance
+	Leader string `json:"leader"`
+	// SymbolCanon 内部统一交易对
+	SymbolCanon string `json:"symbol_canon"`
+	// SampleMs 采样间隔（毫秒）
+	SampleMs int `json:"sample_ms"`
+	// Samples 参与回归的样本数
+	Samples int `json:"samples"`
+	// Lags VECM 差分滞后阶数
+	Lags int `json:"lags"`
+	// AlphaLeader / AlphaFollower 误差修正系数（Follower 向 Leader 收敛时 AlphaFollower > 0）
+	AlphaLeader   float64 `json:"alpha_leader"`
+	AlphaFollower float64 `json:"alpha_follower"`
+	// LeaderCS Leader 的 Gonzalo-Granger 成分份额（Follower 份额 = 1 − LeaderCS）
+	LeaderCS float64 `json:"leader_cs"`
+	// LeaderISLower / LeaderISUpper Leader 的 Hasbrouck 信息份额上下界（两种 Cholesky 顺序）
+	LeaderISLower float64 `json:"leader_is_lower"`
+	LeaderISUpper float64 `json:"leader_is_upper"`
+	// LeaderIS 上下界中点
+	LeaderIS float64 `json:"leader_is"`
+}
+
+// series 单个交易所、单个交易对的前向填充对数中间价环形序列
+type series struct {
+	logMids []float64
+	// firstSample 首个有效采样序号
+	firstSample int64
+	// lastSample 最近写入的采样序号
+	lastSample int64
+	lastLogMid float64
+}
+
+func newSeries(size int) *series {
+	return &series{logMids: make([]float64, size), firstSample: -1}
+}
+
+// observe 写入采样点 s 的对数中间价；乱序（早于最近采样点）的更新忽略
+func (r *series) observe(s int64, logMid float64) {
+	n := int64(len(r.logMids))
+	if r.firstSample < 0 {
+		r.firstSample, r.lastSample = s, s
+		r.logMids[s%n] = logMid
+		r.lastLogMid = logMid
+		return
+	}
+	if s < r.lastSample {
+		return
+	}
+	// 前向填充中间缺失的采样点（最多填满一圈）
+	from := r.lastSample + 1
+	if s-from > n {
+		from = s - n
+	}
+	for i := from; i < s; i++ {
+		r.logMids[i%n] = r.lastLogMid
+	}
+	r.logMids[s%n] = logMid
+	r.lastSample = s
+	r.lastLogMid = logMid
+}
+
+// window 导出采样点 [end-len+1, end] 的对数中间价（未观测到的采样点为 NaN）
+func (r *series) window(end int64) []float64 {
+	n := int64(len(r.logMids))
+	out := make([]float64, n)
+	start := end - n + 1
+	for i := int64(0); i < n; i++ {
+		s := start + i
+		switch {
+		case r.firstSample < 0 || s < r.firstSample || s <= r.lastSample-n:
+			out[i] = math.NaN()
+		case s > r.lastSample:
+			out[i] = r.lastLogMid
+		default:
+			out[i] = r.logMids[s%n]
+		}
+	}
+	return out
+}
+
+// Pair 一条链路、一个交易对的采样快照（供 Compute 使用）
+type Pair struct {
+	TsUnixNs    int64
+	Leader      string
+	SymbolCanon string
+	SampleMs    int
+	Lags        int
+	// LeaderLogMids / FollowerLogMids 对齐的对数中间价（NaN 表示无数据）
+	LeaderLogMids   []float64
+	FollowerLogMids []float64
+}
+
+// Estimator 信息份额采样器
+// 非并发安全：Observe 与 Snapshot 须在同一 goroutine 中调用。
+type Estimator struct {
+	sampleNs   int64
+	sampleMs   int
+	lags       int
+	windowSize int
+
+	// series 按 交易所 → 交易对 索引
+	series map[string]map[string]*series
+}
+
+// NewEstimator 创建信息份额采样器
+// 参数 sampleMs: 采样间隔（毫秒）
+// 参数 windowMs: 估计窗口长度（毫秒）
+// 参数 lags: VECM 差分滞后阶数
+func NewEstimator(sampleMs, windowMs, lags int) *Estimator {
+	if sampleMs <= 0 {
+		sampleMs = 1000
+	}
+	windowSize := windowMs / sampleMs
+	if windowSize < 2 {
+		windowSize = 2
+	}
+	if lags < 0 {
+		lags = 0
+	}
+	return &Estimator{
+		sampleNs:   int64(sampleMs) * 1_000_000,
+		sampleMs:   sampleMs,
+		lags:       lags,
+		windowSize: windowSize,
+		series:     make(map[string]map[string]*series, 3),
+	}
+}
+
+// Observe 记录一条行情的对数中间价
+func (e *Estimator) Observe(ev *model.BookEvent) {
+	if ev == nil || ev.SymbolCanon == "" || ev.BestBidPx <= 0 || ev.BestAskPx <= 0 {
+		return
+	}
+	bySym := e.series[ev.Exchange]
+	if bySym == nil {
+		bySym = make(map[string]*series)
+		e.series[ev.Exchange] = bySym
+	}
+	s := bySym[ev.SymbolCanon]
+	if s == nil {
+		s = newSeries(e.windowSize)
+		bySym[ev.SymbolCanon] = s
+	}
+	s.observe(ev.ArrivedAtUnixNs/e.sampleNs, math.Log((ev.BestBidPx+ev.BestAskPx)/2))
+}
+
+// Snapshot 导出各链路、各交易对截至 nowNs 前最后一个完整采样点的窗口
+// 返回按（Leader, 交易对）排序的快照；仅包含 Leader 与 Follower 均有数据的交易对。
+func (e *Estimator) Snapshot(nowNs int64) []Pair {
+	end := nowNs/e.sampleNs - 1
+	followers := e.series[model.ExchangeBittap]
+	var out []Pair
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		syms := make([]string, 0, len(e.series[leader]))
+		for sym := range e.series[leader] {
+			if followers[sym] != nil {
+				syms = append(syms, sym)
+			}
+		}
+		sort.Strings(syms)
+		for _, sym := range syms {
+			out = append(out, Pair{
+				TsUnixNs:        nowNs,
+				Leader:          leader,
+				SymbolCanon:     sym,
+				SampleMs:        e.sampleMs,
+				Lags:            e.lags,
+				LeaderLogMids:   e.series[leader][sym].window(end),
+				FollowerLogMids: followers[sym].window(end),
+			})
+		}
+	}
+	return out
+}
+
+// minSamplesPerRegressor 每个回归变量至少需要的样本数
+const minSamplesPerRegressor = 10
+
+// Compute 拟合 VECM 并计算 Leader 的成分份额与信息份额
+// 返回: 结果与是否有效（样本不足、价格无变动或系数退化时为 false）
+func (p Pair) Compute() (Result, bool) {
+	res := Result{
+		TsUnixNs:    p.TsUnixNs,
+		Leader:      p.Leader,
+		SymbolCanon: p.SymbolCanon,
+		SampleMs:    p.SampleMs,
+		Lags:        p.Lags,
+	}
+	alpha, omega, n, ok := fitVECM(p.LeaderLogMids, p.FollowerLogMids, p.Lags)
+	res.Samples = n
+	if !ok {
+		return res, false
+	}
+	res.AlphaLeader, res.AlphaFollower = alpha[0], alpha[1]
+
+	// α 的正交补 α⊥ ∝ (α_F, −α_L)，归一化后即成分份额
+	denom := alpha[1] - alpha[0]
+	if math.Abs(denom) < 1e-12 {
+		return res, false
+	}
+	gL, gF := alpha[1]/denom, -alpha[0]/denom
+	res.LeaderCS = gL
+
+	first, ok1 := leaderShare(gL, gF, omega[0][0], omega[1][1], omega[0][1])
+	// Leader 在后：交换变量顺序后计算 Follower 份额
+	followerFirst, ok2 := leaderShare(gF, gL, omega[1][1], omega[0][0], omega[0][1])
+	if !ok1 || !ok2 {
+		return res, false
+	}
+	last := 1 - followerFirst
+	res.LeaderISLower, res.LeaderISUpper = math.Min(first, last), math.Max(first, last)
+	res.LeaderIS = (res.LeaderISLower + res.LeaderISUpper) / 2
+	return res, true
+}
+
+// leaderShare 以第一个变量在前做 Cholesky 分解，计算第一个变量的 Hasbrouck 信息份额
+// 参数 g1, g2: 归一化的 α⊥（长期冲击权重）
+// 参数 s11, s22, s12: 残差协方差
+func leaderShare(g1, g2, s11, s22, s12 float64) (float64, bool) {
+	if s11 <= 0 {
+		return 0, false
+	}
+	f11 := math.Sqrt(s11)
+	f21 := s12 / f11
+	f22sq := s22 - f21*f21
+	if f22sq < 0 {
+		f22sq = 0
+	}
+	a := g1*f11 + g2*f21
+	b := g2 * math.Sqrt(f22sq)
+	total := a*a + b*b
+	if total <= 0 {
+		return 0, false
+	}
+	return a * a / total, true
+}
+
+// fitVECM 对两条对数价格序列逐方程 OLS 拟合协整向量为 (1, −1) 的 VECM
+// 返回: 两个方程的误差修正系数、残差协方差、样本数与是否有效
+func fitVECM(l, f []float64, lags int) ([2]float64, [2][2]float64, int, bool) {
+	var alpha [2]float64
+	var omega [2][2]float64
+	if len(l) != len(f) {
+		return alpha, omega, 0, false
+	}
+	dl := diff(l)
+	df := diff(f)
+
+	// 回归变量: 常数、z_{t-1}、ΔL_{t-1..t-p}、ΔF_{t-1..t-p}
+	k := 2 + 2*lags
+	var xs [][]float64
+	var ys [2][]float64
+	// dl[t-1] 为 t-1 → t 的差分
+	for t := lags + 1; t < len(l); t++ {
+		if !finite(l[t-1], f[t-1], dl[t-1], df[t-1]) {
+			continue
+		}
+		x := make([]float64, 0, k)
+		x = append(x, 1, l[t-1]-f[t-1])
+		ok := true
+		for j := 1; j <= lags; j++ {
+			if !finite(dl[t-1-j], df[t-1-j]) {
+				ok = false
+				break
+			}
+			x = append(x, dl[t-1-j])
+		}
+		if !ok {
+			continue
+		}
+		for j := 1; j <= lags; j++ {
+			x = append(x, df[t-1-j])
+		}
+		xs = append(xs, x)
+		ys[0] = append(ys[0], dl[t-1])
+		ys[1] = append(ys[1], df[t-1])
+	}
+	n := len(xs)
+	if n < minSamplesPerRegressor*k {
+		return alpha, omega, n, false
+	}
+
+	xtx := make([][]float64, k)
+	for i := range xtx {
+		xtx[i] = make([]float64, k)
+	}
+	for _, x := range xs {
+		for i := 0; i < k; i++ {
+			for j := i; j < k; j++ {
+				xtx[i][j] += x[i] * x[j]
+			}
+		}
+	}
+	for i := 0; i < k; i++ {
+		for j := 0; j < i; j++ {
+			xtx[i][j] = xtx[j][i]
+		}
+	}
+
+	var resid [2][]float64
+	for eq := 0; eq < 2; eq++ {
+		xty := make([]float64, k)
+		for r, x := range xs {
+			for i := 0; i < k; i++ {
+				xty[i] += x[i] * ys[eq][r]
+			}
+		}
+		beta, ok := solve(xtx, xty)
+		if !ok {
+			return alpha, omega, n, false
+		}
+		alpha[eq] = beta[1]
+		resid[eq] = make([]float64, n)
+		for r, x := range xs {
+			var fit float64
+			for i := 0; i < k; i++ {
+				fit += beta[i] * x[i]
+			}
+			resid[eq][r] = ys[eq][r] - fit
+		}
+	}
+
+	dof := float64(n - k)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			var s float64
+			for r := 0; r < n; r++ {
+				s += resid[i][r] * resid[j][r]
+			}
+			omega[i][j] = s / dof
+		}
+	}
+	return alpha, omega, n, true
+}
+
+// diff 相邻差分（任一端为 NaN 时结果为 NaN）
+func diff(xs []float64) []float64 {
+	if len(xs) < 2 {
+		return nil
+	}
+	out := make([]float64, len(xs)-1)
+	for i := 1; i < len(xs); i++ {
+		out[i-1] = xs[i] - xs[i-1]
+	}
+	return out
+}
+
+// finite 判断全部取值均非 NaN/Inf
+func finite(vs ...float64) bool {
+	for _, v := range vs {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// solve 部分主元高斯消元求解 a·x = b（不修改入参）
+// 返回: 解与是否有效（矩阵奇异时为 false）
+func solve(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n+1)
+		copy(m[i], a[i])
+		m[i][n] = b[i]
+	}
+	// 各列按原始最大绝对值判断奇异：对数价差与收益率量级相差数个数量级，不能用全局阈值
+	scale := make([]float64, n)
+	for c := 0; c < n; c++ {
+		for r := 0; r < n; r++ {
+			scale[c] = math.Max(scale[c], math.Abs(a[r][c]))
+		}
+		if scale[c] == 0 {
+			return nil, false
+		}
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(m[r][col]) > math.Abs(m[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(m[pivot][col]) <= 1e-12*scale[col] {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for r := col + 1; r < n; r++ {
+			factor := m[r][col] / m[col][col]
+			for c := col; c <= n; c++ {
+				m[r][c] -= factor * m[col][c]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		s := m[i][n]
+		for j := i + 1; j < n; j++ {
+			s -= m[i][j] * x[j]
+		}
+		x[i] = s / m[i][i]
+	}
+	return x, true
+}
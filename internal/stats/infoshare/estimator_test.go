@@ -0,0 +1,100 @@
+// Package infoshare 信息份额估计测试
+package infoshare
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func book(ex string, mid float64, arrivedMs int64) *model.BookEvent {
+	return &model.BookEvent{
+		Exchange:        ex,
+		SymbolCanon:     "BTCUSDT",
+		BestBidPx:       mid - 0.05,
+		BestAskPx:       mid + 0.05,
+		ArrivedAtUnixNs: arrivedMs * 1_000_000,
+	}
+}
+
+// simulate 生成一对协整价格：driver 随机游走，adjuster 每步以 speed 向 driver 收敛并叠加小噪声
+func simulate(n int, speed float64, seed int64) (driver, adjuster []float64) {
+	rng := rand.New(rand.NewSource(seed))
+	d, a := 100.0, 100.0
+	for i := 0; i < n; i++ {
+		a += speed*(d-a) + rng.NormFloat64()*0.005
+		d += rng.NormFloat64() * 0.05
+		driver = append(driver, d)
+		adjuster = append(adjuster, a)
+	}
+	return driver, adjuster
+}
+
+func TestEstimator_LeaderDrivesPriceDiscovery(t *testing.T) {
+	e := NewEstimator(1000, 3_600_000, 2)
+	leader, follower := simulate(3600, 0.5, 1)
+	for i := range leader {
+		ms := int64(i) * 1000
+		e.Observe(book(model.ExchangeOKX, leader[i], ms))
+		e.Observe(book(model.ExchangeBittap, follower[i], ms+100))
+	}
+
+	pairs := e.Snapshot(3600 * 1_000_000_000)
+	if len(pairs) != 1 || pairs[0].Leader != model.ExchangeOKX {
+		t.Fatalf("pairs=%d, want 1 (okx)", len(pairs))
+	}
+	res, ok := pairs[0].Compute()
+	if !ok {
+		t.Fatalf("估计应有效: %+v", res)
+	}
+	if res.AlphaFollower <= 0 || math.Abs(res.AlphaLeader) > 0.1 {
+		t.Errorf("Follower 应向 Leader 收敛: alpha_leader=%v alpha_follower=%v", res.AlphaLeader, res.AlphaFollower)
+	}
+	if res.LeaderCS < 0.9 {
+		t.Errorf("LeaderCS=%v, want ~1", res.LeaderCS)
+	}
+	if res.LeaderISLower < 0.8 || res.LeaderISLower > res.LeaderISUpper || res.LeaderISUpper > 1 {
+		t.Errorf("IS 上下界不合理: [%v, %v]", res.LeaderISLower, res.LeaderISUpper)
+	}
+}
+
+func TestPair_FollowerDrivesPriceDiscovery(t *testing.T) {
+	// 角色互换：Follower 主导，Leader 收敛
+	follower, leader := simulate(3000, 0.5, 2)
+	p := Pair{Lags: 1, LeaderLogMids: logs(leader), FollowerLogMids: logs(follower)}
+	res, ok := p.Compute()
+	if !ok {
+		t.Fatalf("估计应有效: %+v", res)
+	}
+	if res.LeaderCS > 0.1 || res.LeaderISUpper > 0.2 {
+		t.Errorf("Leader 份额应接近 0: cs=%v is=[%v, %v]", res.LeaderCS, res.LeaderISLower, res.LeaderISUpper)
+	}
+}
+
+func TestPair_InsufficientSamples(t *testing.T) {
+	leader, follower := simulate(20, 0.5, 3)
+	p := Pair{Lags: 2, LeaderLogMids: logs(leader), FollowerLogMids: logs(follower)}
+	if res, ok := p.Compute(); ok {
+		t.Fatalf("样本不足应无效: %+v", res)
+	}
+
+	// 窗口开头无数据（NaN）的采样点跳过，价格不变时无法估计
+	flat := make([]float64, 200)
+	for i := range flat {
+		flat[i] = math.Log(100)
+	}
+	flat[0] = math.NaN()
+	if res, ok := (Pair{Lags: 1, LeaderLogMids: flat, FollowerLogMids: flat}).Compute(); ok {
+		t.Fatalf("价格无变动应无效: %+v", res)
+	}
+}
+
+func logs(mids []float64) []float64 {
+	out := make([]float64, len(mids))
+	for i, m := range mids {
+		out[i] = math.Log(m)
+	}
+	return out
+}
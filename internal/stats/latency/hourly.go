@@ -0,0 +1,62 @@
+package latency
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// hourWindowSize 每个小时桶保留的样本数
+const hourWindowSize = 2000
+
+// HourLatencyStats 单个 UTC 小时的到达时延分位数（毫秒）
+type HourLatencyStats struct {
+	// Hour UTC 小时 [0, 23]
+	Hour int `json:"hour"`
+	// Count 样本总数（累计）
+	Count int64 `json:"count"`
+	// P50Ms 到达时延 P50（毫秒）
+	P50Ms float64 `json:"p50_ms"`
+	// P90Ms 到达时延 P90（毫秒）
+	P90Ms float64 `json:"p90_ms"`
+	// P99Ms 到达时延 P99（毫秒）
+	P99Ms float64 `json:"p99_ms"`
+}
+
+// newHourWindows 创建 24 个小时桶窗口
+func newHourWindows() [24]*rollingWindow {
+	var out [24]*rollingWindow
+	for i := range out {
+		out[i] = newRollingWindow(hourWindowSize)
+	}
+	return out
+}
+
+// HourlyStats 获取指定 Leader 按 UTC 小时分桶的到达时延（仅有样本的小时，按小时升序）
+// 小时按 Follower 到达时间计算。需排序各桶样本，应由聚合器按指标周期调用。
+// 参数 leader: okx 或 binance
+func (t *Tracker) HourlyStats(leader string) []HourLatencyStats {
+	var lt linkTracker
+	switch leader {
+	case model.ExchangeOKX:
+		lt = t.okx
+	case model.ExchangeBinance:
+		lt = t.binance
+	default:
+		return nil
+	}
+
+	var out []HourLatencyStats
+	for hour, w := range lt.hourly {
+		count, qs := w.snapshotQuantiles(0.50, 0.90, 0.99)
+		if count == 0 {
+			continue
+		}
+		out = append(out, HourLatencyStats{
+			Hour:  hour,
+			Count: count,
+			P50Ms: float64(qs[0]) / 1_000_000.0,
+			P90Ms: float64(qs[1]) / 1_000_000.0,
+			P99Ms: float64(qs[2]) / 1_000_000.0,
+		})
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package latency
+
+import (
+	"sort"
+	"sync"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// leaderLeadWindowSize 每个交易对保留的领先样本数
+const leaderLeadWindowSize = 1000
+
+// LeaderLeadStats 单个交易对 OKX 与 Binance 之间的领先统计
+// 领先样本定义：一方中间价变动后，另一方在匹配窗口内同向变动，两者到达时间之差。
+// 有符号时延 = Binance 变动到达时间 - OKX 变动到达时间（正值表示 OKX 先动）。单位：毫秒。
+type LeaderLeadStats struct {
+	// SymbolCanon 内部统一交易对
+	SymbolCanon string `json:"symbol_canon"`
+	// Count 匹配的变动对数（累计）
+	Count int64 `json:"count"`
+	// OKXFirst OKX 先动次数（累计）
+	OKXFirst int64 `json:"okx_first"`
+	// BinanceFirst Binance 先动次数（累计）
+	BinanceFirst int64 `json:"binance_first"`
+	// LeadP10Ms 有符号时延 P10（毫秒）
+	LeadP10Ms float64 `json:"lead_p10_ms"`
+	// LeadP50Ms 有符号时延 P50（毫秒）
+	LeadP50Ms float64 `json:"lead_p50_ms"`
+	// LeadP90Ms 有符号时延 P90（毫秒）
+	LeadP90Ms float64 `json:"lead_p90_ms"`
+}
+
+// leaderMove 一次中间价变动
+type leaderMove struct {
+	arrivedNs int64
+	dir       int8
+	// matched 已与对方的变动配对（不再参与配对）
+	matched bool
+}
+
+// leaderPair 单个交易对的两个 Leader 变动状态，下标 0 为 OKX、1 为 Binance
+type leaderPair struct {
+	lastMid  [2]float64
+	lastMove [2]leaderMove
+
+	lead         *rollingWindow
+	okxFirst     int64
+	binanceFirst int64
+}
+
+// leaderComparison OKX 与 Binance 的逐交易对领先比较
+type leaderComparison struct {
+	matchWindowNs int64
+
+	mu    sync.Mutex
+	pairs map[string]*leaderPair
+}
+
+// EnableLeaderComparison 启用 OKX 与 Binance 之间的逐交易对领先比较
+// 需在开始 AddLeader 之前调用；matchWindowMs<=0 时不启用。
+// 参数 matchWindowMs: 两个 Leader 同向变动视为同一次行情的最大间隔（毫秒）
+func (t *Tracker) EnableLeaderComparison(matchWindowMs int) {
+	if matchWindowMs <= 0 {
+		return
+	}
+	t.leaders = &leaderComparison{
+		matchWindowNs: int64(matchWindowMs) * 1_000_000,
+		pairs:         make(map[string]*leaderPair),
+	}
+}
+
+// AddLeader 记录一条 Leader 行情（OKX 或 Binance）
+// 仅中间价发生变动时参与比较；未启用比较时忽略。
+func (t *Tracker) AddLeader(ev *model.BookEvent) {
+	lc := t.leaders
+	if lc == nil || ev == nil || ev.SymbolCanon == "" || ev.BestBidPx <= 0 || ev.BestAskPx <= 0 {
+		return
+	}
+	var idx int
+	switch ev.Exchange {
+	case model.ExchangeOKX:
+		idx = 0
+	case model.ExchangeBinance:
+		idx = 1
+	default:
+		return
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	p := lc.pairs[ev.SymbolCanon]
+	if p == nil {
+		p = &leaderPair{lead: newRollingWindow(leaderLeadWindowSize)}
+		lc.pairs[ev.SymbolCanon] = p
+	}
+
+	mid := (ev.BestBidPx + ev.BestAskPx) / 2
+	prev := p.lastMid[idx]
+	p.lastMid[idx] = mid
+	if prev == 0 || mid == prev {
+		return
+	}
+	dir := int8(1)
+	if mid < prev {
+		dir = -1
+	}
+
+	move := leaderMove{arrivedNs: ev.ArrivedAtUnixNs, dir: dir}
+	other := &p.lastMove[1-idx]
+	if other.arrivedNs > 0 && !other.matched && other.dir == dir {
+		if d := ev.ArrivedAtUnixNs - other.arrivedNs; d >= 0 && d <= lc.matchWindowNs {
+			// 对方先动：有符号时延以 OKX 先动为正
+			if idx == 1 {
+				p.okxFirst++
+				p.lead.add(d)
+			} else {
+				p.binanceFirst++
+				p.lead.add(-d)
+			}
+			other.matched = true
+			move.matched = true
+		}
+	}
+	p.lastMove[idx] = move
+}
+
+// LeaderStats 获取各交易对的 Leader 领先统计（按交易对排序）
+// 需排序窗口样本，应由聚合器按指标周期调用。未启用比较时返回 nil。
+func (t *Tracker) LeaderStats() []LeaderLeadStats {
+	lc := t.leaders
+	if lc == nil {
+		return nil
+	}
+
+	lc.mu.Lock()
+	out := make([]LeaderLeadStats, 0, len(lc.pairs))
+	windows := make([]*rollingWindow, 0, len(lc.pairs))
+	for sym, p := range lc.pairs {
+		if p.okxFirst+p.binanceFirst == 0 {
+			continue
+		}
+		out = append(out, LeaderLeadStats{
+			SymbolCanon:  sym,
+			OKXFirst:     p.okxFirst,
+			BinanceFirst: p.binanceFirst,
+		})
+		windows = append(windows, p.lead)
+	}
+	lc.mu.Unlock()
+
+	for i, w := range windows {
+		count, qs := w.snapshotQuantiles(0.10, 0.50, 0.90)
+		out[i].Count = count
+		out[i].LeadP10Ms = float64(qs[0]) / 1_000_000.0
+		out[i].LeadP50Ms = float64(qs[1]) / 1_000_000.0
+		out[i].LeadP90Ms = float64(qs[2]) / 1_000_000.0
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SymbolCanon < out[j].SymbolCanon })
+	return out
+}
@@ -0,0 +1,97 @@
+// Package latency Leader 领先比较测试
+package latency
+
+import (
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func leaderBook(ex string, mid float64, arrivedMs int64) *model.BookEvent {
+	return &model.BookEvent{
+		Exchange:        ex,
+		SymbolCanon:     "BTCUSDT",
+		BestBidPx:       mid - 0.5,
+		BestAskPx:       mid + 0.5,
+		ArrivedAtUnixNs: arrivedMs * 1_000_000,
+	}
+}
+
+func TestTracker_LeaderComparison(t *testing.T) {
+	tr := NewTracker(100)
+	tr.EnableLeaderComparison(1000)
+
+	// 初始报价
+	tr.AddLeader(leaderBook(model.ExchangeOKX, 100, 0))
+	tr.AddLeader(leaderBook(model.ExchangeBinance, 100, 0))
+
+	// OKX 先涨，Binance 20ms 后跟涨
+	tr.AddLeader(leaderBook(model.ExchangeOKX, 101, 1000))
+	tr.AddLeader(leaderBook(model.ExchangeBinance, 101, 1020))
+	// Binance 先跌，OKX 5ms 后跟跌
+	tr.AddLeader(leaderBook(model.ExchangeBinance, 100, 2000))
+	tr.AddLeader(leaderBook(model.ExchangeOKX, 100, 2005))
+	// OKX 再涨，Binance 超出匹配窗口才跟涨：不配对
+	tr.AddLeader(leaderBook(model.ExchangeOKX, 101, 3000))
+	tr.AddLeader(leaderBook(model.ExchangeBinance, 101, 4500))
+	// 反向变动不配对
+	tr.AddLeader(leaderBook(model.ExchangeOKX, 102, 7000))
+	tr.AddLeader(leaderBook(model.ExchangeBinance, 100, 7010))
+
+	stats := tr.LeaderStats()
+	if len(stats) != 1 {
+		t.Fatalf("len(stats)=%d, want 1", len(stats))
+	}
+	s := stats[0]
+	if s.Count != 2 || s.OKXFirst != 1 || s.BinanceFirst != 1 {
+		t.Fatalf("Count=%d OKXFirst=%d BinanceFirst=%d, want 2/1/1", s.Count, s.OKXFirst, s.BinanceFirst)
+	}
+	// 样本 {-5ms, +20ms}：P10 取最小值
+	if s.LeadP10Ms != -5 {
+		t.Fatalf("LeadP10Ms=%v, want -5", s.LeadP10Ms)
+	}
+}
+
+func TestTracker_LeaderComparisonDisabled(t *testing.T) {
+	tr := NewTracker(100)
+	tr.AddLeader(leaderBook(model.ExchangeOKX, 100, 0))
+	if stats := tr.LeaderStats(); stats != nil {
+		t.Fatalf("未启用时应返回 nil: %+v", stats)
+	}
+}
+
+func TestTracker_LeadShare(t *testing.T) {
+	tr := NewTracker(100)
+	tr.EnableLeadShare(2, 1000)
+
+	tr.ObserveMove(leaderBook(model.ExchangeOKX, 100, 0))
+	tr.ObserveMove(leaderBook(model.ExchangeBinance, 100, 0))
+	tr.ObserveMove(leaderBook(model.ExchangeBittap, 100, 0))
+
+	// OKX 先涨，Follower 随后上涨：OKX 领先；Binance 未动
+	tr.ObserveMove(leaderBook(model.ExchangeOKX, 101, 1000))
+	tr.ObserveMove(leaderBook(model.ExchangeBittap, 101, 1050))
+	// 两个 Leader 都下跌，Follower 下跌：均领先
+	tr.ObserveMove(leaderBook(model.ExchangeOKX, 100, 2000))
+	tr.ObserveMove(leaderBook(model.ExchangeBinance, 99, 2010))
+	tr.ObserveMove(leaderBook(model.ExchangeBittap, 100, 2050))
+	// Follower 自行上涨（Leader 最近变动已超出窗口）：均未领先
+	tr.ObserveMove(leaderBook(model.ExchangeBittap, 101, 5000))
+	// 低于阈值的抖动不计入
+	tr.ObserveMove(leaderBook(model.ExchangeBittap, 101.01, 5100))
+
+	got := tr.LeadShare()
+	if len(got) != 2 {
+		t.Fatalf("len=%d, want 2", len(got))
+	}
+	bn, ok := got[0], got[1]
+	if bn.Leader != model.ExchangeBinance || ok.Leader != model.ExchangeOKX {
+		t.Fatalf("排序错误: %+v", got)
+	}
+	if ok.FollowerMoves != 3 || ok.Led != 2 {
+		t.Fatalf("okx moves=%d led=%d, want 3/2", ok.FollowerMoves, ok.Led)
+	}
+	if bn.FollowerMoves != 3 || bn.Led != 1 {
+		t.Fatalf("binance moves=%d led=%d, want 3/1", bn.FollowerMoves, bn.Led)
+	}
+}
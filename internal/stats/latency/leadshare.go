@@ -0,0 +1,164 @@
+package latency
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// LeadShareStats 单条链路、单个交易对的 Leader 领先占比
+// 对 Follower 每次显著中间价变动，检查 Leader 是否已在之前的窗口内同向显著变动。
+type LeadShareStats struct {
+	// Leader 领先交易所: okx 或 binance
+	Leader string `json:"leader"`
+	// SymbolCanon 内部统一交易对
+	SymbolCanon string `json:"symbol_canon"`
+	// FollowerMoves Follower 显著变动次数（累计）
+	FollowerMoves int64 `json:"follower_moves"`
+	// Led 其中 Leader 已先行同向变动的次数（累计）
+	Led int64 `json:"led"`
+	// Share 领先占比 = Led / FollowerMoves
+	Share float64 `json:"share"`
+}
+
+// sigMove 一次显著变动
+type sigMove struct {
+	atNs int64
+	dir  int8
+}
+
+// moveDetector 单个交易所的显著变动检测：中间价偏离锚点超过阈值即记为一次变动并重置锚点
+type moveDetector struct {
+	anchor float64
+	last   sigMove
+}
+
+// observe 返回本次更新是否构成显著变动
+func (d *moveDetector) observe(mid float64, atNs int64, minMoveBps float64) bool {
+	if d.anchor == 0 {
+		d.anchor = mid
+		return false
+	}
+	moveBps := (mid - d.anchor) / d.anchor * 10000
+	if math.Abs(moveBps) < minMoveBps {
+		return false
+	}
+	dir := int8(1)
+	if moveBps < 0 {
+		dir = -1
+	}
+	d.anchor = mid
+	d.last = sigMove{atNs: atNs, dir: dir}
+	return true
+}
+
+// leadShareSymbol 单个交易对的检测状态，leaders 下标 0 为 OKX、1 为 Binance
+type leadShareSymbol struct {
+	follower moveDetector
+	leaders  [2]moveDetector
+	moves    [2]int64
+	led      [2]int64
+}
+
+// leadShareTracker Leader 领先占比统计
+type leadShareTracker struct {
+	minMoveBps float64
+	windowNs   int64
+
+	mu      sync.Mutex
+	symbols map[string]*leadShareSymbol
+}
+
+// EnableLeadShare 启用 Leader 领先占比统计
+// 需在开始 ObserveMove 之前调用；minMoveBps<=0 或 windowMs<=0 时不启用。
+// 参数 minMoveBps: 显著变动阈值（中间价相对锚点的偏离，基点）
+// 参数 windowMs: Leader 先行变动的回看窗口（毫秒）
+func (t *Tracker) EnableLeadShare(minMoveBps float64, windowMs int) {
+	if minMoveBps <= 0 || windowMs <= 0 {
+		return
+	}
+	t.leadShare = &leadShareTracker{
+		minMoveBps: minMoveBps,
+		windowNs:   int64(windowMs) * 1_000_000,
+		symbols:    make(map[string]*leadShareSymbol),
+	}
+}
+
+// ObserveMove 记录一条行情（Leader 或 Follower）的中间价
+// Follower 发生显著变动时，分别判断两个 Leader 是否已在窗口内同向变动。未启用时忽略。
+func (t *Tracker) ObserveMove(ev *model.BookEvent) {
+	ls := t.leadShare
+	if ls == nil || ev == nil || ev.SymbolCanon == "" || ev.BestBidPx <= 0 || ev.BestAskPx <= 0 {
+		return
+	}
+	mid := (ev.BestBidPx + ev.BestAskPx) / 2
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	s := ls.symbols[ev.SymbolCanon]
+	if s == nil {
+		s = &leadShareSymbol{}
+		ls.symbols[ev.SymbolCanon] = s
+	}
+
+	switch ev.Exchange {
+	case model.ExchangeOKX:
+		s.leaders[0].observe(mid, ev.ArrivedAtUnixNs, ls.minMoveBps)
+	case model.ExchangeBinance:
+		s.leaders[1].observe(mid, ev.ArrivedAtUnixNs, ls.minMoveBps)
+	case model.ExchangeBittap:
+		if !s.follower.observe(mid, ev.ArrivedAtUnixNs, ls.minMoveBps) {
+			return
+		}
+		fm := s.follower.last
+		for i := range s.leaders {
+			// 尚未收到该 Leader 行情时不计入分母
+			if s.leaders[i].anchor == 0 {
+				continue
+			}
+			s.moves[i]++
+			lm := s.leaders[i].last
+			if lm.atNs > 0 && lm.dir == fm.dir && fm.atNs-lm.atNs >= 0 && fm.atNs-lm.atNs <= ls.windowNs {
+				s.led[i]++
+			}
+		}
+	}
+}
+
+// LeadShare 获取各链路、各交易对的领先占比（按 Leader、交易对排序）
+// 未启用时返回 nil。
+func (t *Tracker) LeadShare() []LeadShareStats {
+	ls := t.leadShare
+	if ls == nil {
+		return nil
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	var out []LeadShareStats
+	for sym, s := range ls.symbols {
+		for i, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+			if s.moves[i] == 0 {
+				continue
+			}
+			out = append(out, LeadShareStats{
+				Leader:        leader,
+				SymbolCanon:   sym,
+				FollowerMoves: s.moves[i],
+				Led:           s.led[i],
+				Share:         float64(s.led[i]) / float64(s.moves[i]),
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Leader != out[j].Leader {
+			return out[i].Leader < out[j].Leader
+		}
+		return out[i].SymbolCanon < out[j].SymbolCanon
+	})
+	return out
+}
@@ -0,0 +1,147 @@
+package latency
+
+import (
+	"sync"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// SpikeEvent 时延尖峰事件
+// 近期 P90 时延相对基线跃升超过阈值时产生，通常意味着网络或交易所异常，
+// 同期产生的信号可信度下降。
+type SpikeEvent struct {
+	// Type 事件类型，固定为 latency_spike
+	Type string `json:"type"`
+	// TsUnixNs 检测时间（纳秒）
+	TsUnixNs int64 `json:"ts_unix_ns"`
+	// Leader 领先交易所: okx 或 binance
+	Leader string `json:"leader"`
+	// RecentP90Ms 近期窗口 P90 时延（毫秒）
+	RecentP90Ms float64 `json:"recent_p90_ms"`
+	// BaselineP90Ms 基线窗口 P90 时延（毫秒）
+	BaselineP90Ms float64 `json:"baseline_p90_ms"`
+	// Factor 近期/基线倍数
+	Factor float64 `json:"factor"`
+}
+
+// SpikeEventType 时延尖峰事件类型
+const SpikeEventType = "latency_spike"
+
+// spikeDetector 单链路尖峰检测状态
+// 近期窗口独立于统计窗口，基线取统计窗口（长窗口）的 P90。
+type spikeDetector struct {
+	recent *rollingWindow
+
+	mu      sync.Mutex
+	inSpike bool
+	count   int64
+}
+
+// add 记录一个到达时延样本（detector 为 nil 时忽略）
+func (d *spikeDetector) add(lagNs int64) {
+	if d == nil {
+		return
+	}
+	d.recent.add(lagNs)
+}
+
+// state 返回累计尖峰次数与当前是否处于尖峰中
+func (d *spikeDetector) state() (int64, bool) {
+	if d == nil {
+		return 0, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.count, d.inSpike
+}
+
+// reset 清空近期窗口与尖峰状态（detector 为 nil 时忽略）
+func (d *spikeDetector) reset() {
+	if d == nil {
+		return
+	}
+	d.recent.reset()
+	d.mu.Lock()
+	d.inSpike = false
+	d.count = 0
+	d.mu.Unlock()
+}
+
+// EnableSpikeDetection 启用时延尖峰检测
+// 需在开始 Add 之前调用；SpikeFactor<=0 时不启用。
+func (t *Tracker) EnableSpikeDetection(cfg config.LatencyConfig) {
+	if cfg.SpikeFactor <= 0 || cfg.SpikeRecentWindow <= 0 {
+		return
+	}
+	t.spikeCfg = cfg
+	t.okx.spike = &spikeDetector{recent: newRollingWindow(cfg.SpikeRecentWindow)}
+	t.binance.spike = &spikeDetector{recent: newRollingWindow(cfg.SpikeRecentWindow)}
+}
+
+// CheckSpikes 检查各 Leader 链路是否进入时延尖峰
+// 仅在进入尖峰时返回事件；回落到阈值以下后重新布防。
+// 需排序窗口样本，应由聚合器按固定间隔调用，不在每条行情上调用。
+// 参数 nowNs: 当前时间（纳秒）
+func (t *Tracker) CheckSpikes(nowNs int64) []SpikeEvent {
+	var out []SpikeEvent
+	for _, l := range []struct {
+		leader string
+		lt     linkTracker
+	}{
+		{model.ExchangeOKX, t.okx},
+		{model.ExchangeBinance, t.binance},
+	} {
+		if ev, ok := t.checkLink(nowNs, l.leader, l.lt); ok {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+func (t *Tracker) checkLink(nowNs int64, leader string, lt linkTracker) (SpikeEvent, bool) {
+	d := lt.spike
+	if d == nil {
+		return SpikeEvent{}, false
+	}
+
+	// 近期窗口未填满时样本过少，不做判断
+	d.recent.mu.Lock()
+	recentFull := d.recent.full
+	d.recent.mu.Unlock()
+	if !recentFull {
+		return SpikeEvent{}, false
+	}
+
+	_, recentQs := d.recent.snapshotQuantiles(0.90)
+	_, baseQs := lt.arrived.snapshotQuantilesSince(t.windowSince(), 0.90)
+	recentMs := float64(recentQs[0]) / 1_000_000.0
+	baseMs := float64(baseQs[0]) / 1_000_000.0
+
+	spiking := recentMs-baseMs >= t.spikeCfg.SpikeMinDeltaMs &&
+		(baseMs <= 0 || recentMs >= t.spikeCfg.SpikeFactor*baseMs)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !spiking {
+		d.inSpike = false
+		return SpikeEvent{}, false
+	}
+	if d.inSpike {
+		return SpikeEvent{}, false
+	}
+	d.inSpike = true
+	d.count++
+
+	ev := SpikeEvent{
+		Type:          SpikeEventType,
+		TsUnixNs:      nowNs,
+		Leader:        leader,
+		RecentP90Ms:   recentMs,
+		BaselineP90Ms: baseMs,
+	}
+	if baseMs > 0 {
+		ev.Factor = recentMs / baseMs
+	}
+	return ev, true
+}
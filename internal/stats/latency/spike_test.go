@@ -0,0 +1,73 @@
+// Package latency 时延尖峰检测测试
+package latency
+
+import (
+	"testing"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func addLag(tr *Tracker, leader string, lagMs int64) {
+	leaderEv := &model.BookEvent{Exchange: leader, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_000_000_000}
+	followerEv := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_000_000_000 + lagMs*1_000_000}
+	tr.Add(leaderEv, followerEv)
+}
+
+func TestTracker_SpikeDetection(t *testing.T) {
+	tr := NewTracker(1000)
+	tr.EnableSpikeDetection(config.LatencyConfig{SpikeFactor: 3, SpikeMinDeltaMs: 20, SpikeRecentWindow: 10})
+
+	// 基线：900 个 10ms 样本
+	for i := 0; i < 900; i++ {
+		addLag(tr, model.ExchangeOKX, 10)
+	}
+	if got := tr.CheckSpikes(1); len(got) != 0 {
+		t.Fatalf("平稳时不应报警: %+v", got)
+	}
+
+	// 近期窗口全部 80ms：P90 80 >= 3 × 10
+	for i := 0; i < 10; i++ {
+		addLag(tr, model.ExchangeOKX, 80)
+	}
+	got := tr.CheckSpikes(2)
+	if len(got) != 1 || got[0].Leader != model.ExchangeOKX || got[0].Type != SpikeEventType {
+		t.Fatalf("应产生一个 okx 尖峰事件: %+v", got)
+	}
+	if got[0].RecentP90Ms != 80 || got[0].BaselineP90Ms != 10 {
+		t.Fatalf("RecentP90Ms=%f BaselineP90Ms=%f, want 80/10", got[0].RecentP90Ms, got[0].BaselineP90Ms)
+	}
+
+	// 持续尖峰不重复报警
+	if again := tr.CheckSpikes(3); len(again) != 0 {
+		t.Fatalf("尖峰持续期间不应重复报警: %+v", again)
+	}
+	if s := tr.Stats(model.ExchangeOKX); s.SpikeCount != 1 || !s.InSpike {
+		t.Fatalf("SpikeCount=%d InSpike=%v, want 1/true", s.SpikeCount, s.InSpike)
+	}
+
+	// 回落后重新布防
+	for i := 0; i < 10; i++ {
+		addLag(tr, model.ExchangeOKX, 10)
+	}
+	tr.CheckSpikes(4)
+	if s := tr.Stats(model.ExchangeOKX); s.InSpike {
+		t.Fatal("回落后 InSpike 应为 false")
+	}
+
+	// Binance 链路无样本，始终不报警
+	if s := tr.Stats(model.ExchangeBinance); s.SpikeCount != 0 {
+		t.Fatalf("binance SpikeCount=%d, want 0", s.SpikeCount)
+	}
+}
+
+func TestTracker_SpikeDetectionDisabled(t *testing.T) {
+	tr := NewTracker(100)
+	tr.EnableSpikeDetection(config.LatencyConfig{})
+	for i := 0; i < 100; i++ {
+		addLag(tr, model.ExchangeOKX, 500)
+	}
+	if got := tr.CheckSpikes(1); got != nil {
+		t.Fatalf("未启用时不应报警: %+v", got)
+	}
+}
@@ -0,0 +1,93 @@
+package latency
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// WindowState 滚动窗口状态（检查点）
+type WindowState struct {
+	// Values 窗口内样本（旧 -> 新，纳秒）
+	Values []int64 `json:"values"`
+	// Times 与 Values 对应的样本时间（纳秒，时间窗口模式用；旧检查点无此字段）
+	Times []int64 `json:"times,omitempty"`
+	// Count 累计样本数（含已滚出窗口的样本）
+	Count int64 `json:"count"`
+}
+
+// LinkState 单条 Leader→Follower 链路的时延窗口状态
+type LinkState struct {
+	Arrived WindowState `json:"arrived"`
+	Event   WindowState `json:"event"`
+	// Negative 负到达时延样本（旧检查点无此字段，恢复为空）
+	Negative WindowState `json:"negative"`
+}
+
+// State 时延追踪器状态（检查点）
+// key 为 Leader 交易所（okx / binance）。尖峰检测状态不保存，重启后重新建立基线。
+type State map[string]LinkState
+
+// ExportState 导出两条链路的滚动窗口
+func (t *Tracker) ExportState() State {
+	return State{
+		model.ExchangeOKX:     {Arrived: t.okx.arrived.export(), Event: t.okx.event.export(), Negative: t.okx.negative.export()},
+		model.ExchangeBinance: {Arrived: t.binance.arrived.export(), Event: t.binance.event.export(), Negative: t.binance.negative.export()},
+	}
+}
+
+// RestoreState 用检查点替换滚动窗口内容（未出现的链路保持不变）
+func (t *Tracker) RestoreState(st State) {
+	if ls, ok := st[model.ExchangeOKX]; ok {
+		t.okx.arrived.restore(ls.Arrived)
+		t.okx.event.restore(ls.Event)
+		t.okx.negative.restore(ls.Negative)
+	}
+	if ls, ok := st[model.ExchangeBinance]; ok {
+		t.binance.arrived.restore(ls.Arrived)
+		t.binance.event.restore(ls.Event)
+		t.binance.negative.restore(ls.Negative)
+	}
+	// 时间窗口的当前时刻取恢复样本中最新的时间
+	for _, ls := range st {
+		for _, ts := range ls.Arrived.Times {
+			if ts > t.latestNs.Load() {
+				t.latestNs.Store(ts)
+			}
+		}
+	}
+}
+
+func (w *rollingWindow) export() WindowState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	values := make([]int64, 0, len(w.buf))
+	times := make([]int64, 0, len(w.ts))
+	if w.full {
+		values = append(values, w.buf[w.pos:]...)
+		values = append(values, w.buf[:w.pos]...)
+		times = append(times, w.ts[w.pos:]...)
+		times = append(times, w.ts[:w.pos]...)
+	} else {
+		values = append(values, w.buf...)
+		times = append(times, w.ts...)
+	}
+	return WindowState{Values: values, Times: times, Count: w.count}
+}
+
+func (w *rollingWindow) restore(st WindowState) {
+	w.reset()
+
+	for i, v := range st.Values {
+		var ts int64
+		if i < len(st.Times) {
+			ts = st.Times[i]
+		}
+		w.addAt(v, ts)
+	}
+
+	w.mu.Lock()
+	if st.Count > w.count {
+		w.count = st.Count
+	}
+	w.mu.Unlock()
+}
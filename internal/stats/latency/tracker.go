@@ -5,7 +5,9 @@ package latency
 import (
 	"sort"
 	"sync"
+	"sync/atomic"
 
+	"latency-arbitrage-validator/internal/config"
 	"latency-arbitrage-validator/internal/core/model"
 	"latency-arbitrage-validator/internal/util/timeutil"
 )
@@ -15,7 +17,7 @@ import (
 type LatencyStats struct {
 	// Leader 领先交易所: okx 或 binance
 	Leader string
-	// Count 样本总数（累计）
+	// Count 样本总数（累计，不含负时延样本）
 	Count int64
 
 	// ArrivedP50Ms 基于到达时间的 P50 时延（毫秒）
@@ -24,6 +26,13 @@ type LatencyStats struct {
 	ArrivedP90Ms float64
 	// ArrivedP99Ms 基于到达时间的 P99 时延（毫秒）
 	ArrivedP99Ms float64
+	// ArrivedP999Ms 基于到达时间的 P99.9 时延（毫秒）
+	ArrivedP999Ms float64
+	// ArrivedMinMs/ArrivedMaxMs/ArrivedMeanMs 窗口内到达时延的最小值、最大值与均值（毫秒）
+	// 尾部事件（偶发的数百毫秒延迟）在 P99 中可能完全不可见
+	ArrivedMinMs  float64
+	ArrivedMaxMs  float64
+	ArrivedMeanMs float64
 
 	// EventP50Ms 基于交易所事件时间的 P50 时延（毫秒）
 	EventP50Ms float64
@@ -31,11 +40,33 @@ type LatencyStats struct {
 	EventP90Ms float64
 	// EventP99Ms 基于交易所事件时间的 P99 时延（毫秒）
 	EventP99Ms float64
+	// EventP999Ms 基于交易所事件时间的 P99.9 时延（毫秒）
+	EventP999Ms float64
+	// EventMinMs/EventMaxMs/EventMeanMs 窗口内事件时延的最小值、最大值与均值（毫秒）
+	EventMinMs  float64
+	EventMaxMs  float64
+	EventMeanMs float64
+
+	// NegativeCount Follower 先于匹配的 Leader 快照到达（到达时延为负）的样本数（累计）
+	// 负时延意味着匹配错误或 Follower 领先的行情，单独统计，不计入上面的到达时延分布
+	NegativeCount int64
+	// NegativeP50Ms/NegativeP90Ms/NegativeP99Ms/NegativeMaxMs 负时延幅度（Follower 提前量）的分位数与最大值（毫秒，取正值）
+	NegativeP50Ms float64
+	NegativeP90Ms float64
+	NegativeP99Ms float64
+	NegativeMaxMs float64
+
+	// SpikeCount 累计时延尖峰次数（需启用尖峰检测）
+	SpikeCount int64
+	// InSpike 当前是否处于时延尖峰中
+	InSpike bool
 }
 
 type rollingWindow struct {
-	size  int
-	buf   []int64
+	size int
+	buf  []int64
+	// ts 与 buf 对应的样本时间（纳秒，Follower 到达时间；时间窗口模式用）
+	ts    []int64
 	pos   int
 	count int64
 	full  bool
@@ -44,10 +75,15 @@ type rollingWindow struct {
 }
 
 func newRollingWindow(size int) *rollingWindow {
-	return &rollingWindow{size: size, buf: make([]int64, 0, size)}
+	return &rollingWindow{size: size, buf: make([]int64, 0, size), ts: make([]int64, 0, size)}
 }
 
 func (w *rollingWindow) add(v int64) {
+	w.addAt(v, 0)
+}
+
+// addAt 记录一个样本及其时间（纳秒）
+func (w *rollingWindow) addAt(v, tsNs int64) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
@@ -58,6 +94,7 @@ func (w *rollingWindow) add(v int64) {
 
 	if !w.full {
 		w.buf = append(w.buf, v)
+		w.ts = append(w.ts, tsNs)
 		if len(w.buf) == w.size {
 			w.full = true
 			w.pos = 0
@@ -66,34 +103,69 @@ func (w *rollingWindow) add(v int64) {
 	}
 
 	w.buf[w.pos] = v
+	w.ts[w.pos] = tsNs
 	w.pos++
 	if w.pos >= w.size {
 		w.pos = 0
 	}
 }
 
+// reset 清空窗口样本与累计计数
+func (w *rollingWindow) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = w.buf[:0]
+	w.ts = w.ts[:0]
+	w.pos = 0
+	w.count = 0
+	w.full = false
+}
+
 func (w *rollingWindow) snapshotQuantiles(qs ...float64) (count int64, values []int64) {
+	return w.snapshotQuantilesSince(0, qs...)
+}
+
+// snapshotQuantilesSince 计算样本时间 >= sinceNs 的分位数（sinceNs<=0 表示窗口内全部样本）
+func (w *rollingWindow) snapshotQuantilesSince(sinceNs int64, qs ...float64) (count int64, values []int64) {
+	count, sorted := w.sortedSince(sinceNs)
+	return count, quantiles(sorted, qs...)
+}
+
+// sortedSince 返回累计样本数与样本时间 >= sinceNs 的样本（升序副本；sinceNs<=0 表示窗口内全部样本）
+func (w *rollingWindow) sortedSince(sinceNs int64) (count int64, sorted []int64) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	count = w.count
-	if len(w.buf) == 0 {
-		return count, make([]int64, len(qs))
+	var tmp []int64
+	if sinceNs > 0 {
+		for i, ts := range w.ts {
+			if ts >= sinceNs {
+				tmp = append(tmp, w.buf[i])
+			}
+		}
+	} else {
+		tmp = make([]int64, len(w.buf))
+		copy(tmp, w.buf)
 	}
-
-	tmp := make([]int64, len(w.buf))
-	copy(tmp, w.buf)
 	sort.Slice(tmp, func(i, j int) bool { return tmp[i] < tmp[j] })
+	return count, tmp
+}
 
-	values = make([]int64, len(qs))
-	n := len(tmp)
+// quantiles 计算升序样本的分位数（q<=0 为最小值，q>=1 为最大值；无样本时全为 0）
+func quantiles(sorted []int64, qs ...float64) []int64 {
+	values := make([]int64, len(qs))
+	n := len(sorted)
+	if n == 0 {
+		return values
+	}
 	for i, q := range qs {
 		if q <= 0 {
-			values[i] = tmp[0]
+			values[i] = sorted[0]
 			continue
 		}
 		if q >= 1 {
-			values[i] = tmp[n-1]
+			values[i] = sorted[n-1]
 			continue
 		}
 		idx := int(float64(n-1) * q)
@@ -103,14 +175,32 @@ func (w *rollingWindow) snapshotQuantiles(qs ...float64) (count int64, values []
 		if idx >= n {
 			idx = n - 1
 		}
-		values[i] = tmp[idx]
+		values[i] = sorted[idx]
 	}
-	return count, values
+	return values
+}
+
+// meanNs 样本均值（纳秒，无样本时为 0）
+func meanNs(samples []int64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range samples {
+		sum += float64(v)
+	}
+	return sum / float64(len(samples))
 }
 
 type linkTracker struct {
 	arrived *rollingWindow
 	event   *rollingWindow
+	// negative 负到达时延样本（存幅度，即 Follower 提前量）
+	negative *rollingWindow
+	// spike 尖峰检测状态（未启用时为 nil）
+	spike *spikeDetector
+	// hourly 按 UTC 小时分桶的到达时延
+	hourly [24]*rollingWindow
 }
 
 // Tracker 时延追踪器
@@ -120,6 +210,19 @@ type Tracker struct {
 	okx linkTracker
 	// binance Binance↙Bittap 链路统计
 	binance linkTracker
+
+	// spikeCfg 尖峰检测配置（EnableSpikeDetection 设置）
+	spikeCfg config.LatencyConfig
+
+	// leaders OKX 与 Binance 的逐交易对领先比较（EnableLeaderComparison 设置，nil 表示不启用）
+	leaders *leaderComparison
+	// leadShare Leader 领先占比统计（EnableLeadShare 设置，nil 表示不启用）
+	leadShare *leadShareTracker
+
+	// windowNs 分位数的时间窗口（纳秒，EnableTimeWindow 设置，0 表示仅按样本数滚动）
+	windowNs int64
+	// latestNs 最新样本的 Follower 到达时间（纳秒），作为时间窗口的当前时刻
+	latestNs atomic.Int64
 }
 
 // NewTracker 创建时延追踪器
@@ -127,12 +230,16 @@ type Tracker struct {
 func NewTracker(windowSize int) *Tracker {
 	return &Tracker{
 		okx: linkTracker{
-			arrived: newRollingWindow(windowSize),
-			event:   newRollingWindow(windowSize),
+			arrived:  newRollingWindow(windowSize),
+			event:    newRollingWindow(windowSize),
+			negative: newRollingWindow(windowSize),
+			hourly:   newHourWindows(),
 		},
 		binance: linkTracker{
-			arrived: newRollingWindow(windowSize),
-			event:   newRollingWindow(windowSize),
+			arrived:  newRollingWindow(windowSize),
+			event:    newRollingWindow(windowSize),
+			negative: newRollingWindow(windowSize),
+			hourly:   newHourWindows(),
 		},
 	}
 }
@@ -141,6 +248,7 @@ func NewTracker(windowSize int) *Tracker {
 // 时延定义：
 // - arrived_lag_ns = follower.ArrivedAtUnixNs - leader.ArrivedAtUnixNs
 // - event_lag_ns = follower.ArrivedAtUnixNs - leader.ExchTsUnixMs（转换为 ns；若 ExchTsUnixMs<=0 则不记录）
+// arrived_lag_ns < 0 的样本计入负时延统计，不参与到达时延分位数、小时分桶与尖峰检测。
 func (t *Tracker) Add(leaderEv, followerEv *model.BookEvent) {
 	if leaderEv == nil || followerEv == nil {
 		return
@@ -160,18 +268,29 @@ func (t *Tracker) Add(leaderEv, followerEv *model.BookEvent) {
 		lagEventNs = 0
 	}
 
+	tsNs := followerEv.ArrivedAtUnixNs
+	if tsNs > t.latestNs.Load() {
+		t.latestNs.Store(tsNs)
+	}
+	var lt *linkTracker
 	switch leaderEv.Exchange {
 	case model.ExchangeOKX:
-		t.okx.arrived.add(lagArrivedNs)
-		if lagEventNs != 0 {
-			t.okx.event.add(lagEventNs)
-		}
+		lt = &t.okx
 	case model.ExchangeBinance:
-		t.binance.arrived.add(lagArrivedNs)
-		if lagEventNs != 0 {
-			t.binance.event.add(lagEventNs)
-		}
+		lt = &t.binance
+	default:
+		return
+	}
+	if lagEventNs != 0 {
+		lt.event.addAt(lagEventNs, tsNs)
 	}
+	if lagArrivedNs < 0 {
+		lt.negative.addAt(-lagArrivedNs, tsNs)
+		return
+	}
+	lt.arrived.addAt(lagArrivedNs, tsNs)
+	lt.hourly[timeutil.HourOfDayUTC(tsNs)].add(lagArrivedNs)
+	lt.spike.add(lagArrivedNs)
 }
 
 // Stats 获取指定 Leader 的统计快照
@@ -187,18 +306,92 @@ func (t *Tracker) Stats(leader string) LatencyStats {
 		return LatencyStats{Leader: leader}
 	}
 
-	arrivedCount, arrivedQs := lt.arrived.snapshotQuantiles(0.50, 0.90, 0.99)
-	eventCount, eventQs := lt.event.snapshotQuantiles(0.50, 0.90, 0.99)
-	_ = eventCount
+	since := t.windowSince()
+	arrivedCount, arrived := lt.arrived.sortedSince(since)
+	_, event := lt.event.sortedSince(since)
+	// 分位数依次为 P50/P90/P99/P99.9/最小值/最大值
+	arrivedQs := quantiles(arrived, 0.50, 0.90, 0.99, 0.999, 0, 1)
+	eventQs := quantiles(event, 0.50, 0.90, 0.99, 0.999, 0, 1)
+	negativeCount, negative := lt.negative.sortedSince(since)
+	negativeQs := quantiles(negative, 0.50, 0.90, 0.99, 1)
 
-	return LatencyStats{
-		Leader:       leader,
-		Count:        arrivedCount,
-		ArrivedP50Ms: float64(arrivedQs[0]) / 1_000_000.0,
-		ArrivedP90Ms: float64(arrivedQs[1]) / 1_000_000.0,
-		ArrivedP99Ms: float64(arrivedQs[2]) / 1_000_000.0,
-		EventP50Ms:   float64(eventQs[0]) / 1_000_000.0,
-		EventP90Ms:   float64(eventQs[1]) / 1_000_000.0,
-		EventP99Ms:   float64(eventQs[2]) / 1_000_000.0,
+	out := LatencyStats{
+		Leader:        leader,
+		Count:         arrivedCount,
+		ArrivedP50Ms:  float64(arrivedQs[0]) / 1_000_000.0,
+		ArrivedP90Ms:  float64(arrivedQs[1]) / 1_000_000.0,
+		ArrivedP99Ms:  float64(arrivedQs[2]) / 1_000_000.0,
+		ArrivedP999Ms: float64(arrivedQs[3]) / 1_000_000.0,
+		ArrivedMinMs:  float64(arrivedQs[4]) / 1_000_000.0,
+		ArrivedMaxMs:  float64(arrivedQs[5]) / 1_000_000.0,
+		ArrivedMeanMs: meanNs(arrived) / 1_000_000.0,
+		EventP50Ms:    float64(eventQs[0]) / 1_000_000.0,
+		EventP90Ms:    float64(eventQs[1]) / 1_000_000.0,
+		EventP99Ms:    float64(eventQs[2]) / 1_000_000.0,
+		EventP999Ms:   float64(eventQs[3]) / 1_000_000.0,
+		EventMinMs:    float64(eventQs[4]) / 1_000_000.0,
+		EventMaxMs:    float64(eventQs[5]) / 1_000_000.0,
+		EventMeanMs:   meanNs(event) / 1_000_000.0,
+		NegativeCount: negativeCount,
+		NegativeP50Ms: float64(negativeQs[0]) / 1_000_000.0,
+		NegativeP90Ms: float64(negativeQs[1]) / 1_000_000.0,
+		NegativeP99Ms: float64(negativeQs[2]) / 1_000_000.0,
+		NegativeMaxMs: float64(negativeQs[3]) / 1_000_000.0,
+	}
+	out.SpikeCount, out.InSpike = lt.spike.state()
+	return out
+}
+
+// ArrivedP50Ms 返回指定 Leader 窗口内到达时延的 P50（毫秒，无样本时为 0）
+// 只排序到达时延窗口，供 latency_fill 等需要较高频率刷新的场景使用。
+func (t *Tracker) ArrivedP50Ms(leader string) float64 {
+	var w *rollingWindow
+	switch leader {
+	case model.ExchangeOKX:
+		w = t.okx.arrived
+	case model.ExchangeBinance:
+		w = t.binance.arrived
+	default:
+		return 0
+	}
+	_, arrived := w.sortedSince(t.windowSince())
+	return float64(quantiles(arrived, 0.50)[0]) / 1_000_000.0
+}
+
+// EnableTimeWindow 启用分位数的时间窗口：仅统计最近 windowMs 内（按 Follower 到达时间）的样本
+// 样本数上限仍为 NewTracker 的 windowSize；需在开始 Add 之前调用，windowMs<=0 时不启用。
+// 当前时刻取最新样本的到达时间，回放时与实时运行行为一致。
+func (t *Tracker) EnableTimeWindow(windowMs int64) {
+	if windowMs <= 0 {
+		return
+	}
+	t.windowNs = windowMs * 1_000_000
+}
+
+// windowSince 返回时间窗口起点（纳秒，未启用时为 0）
+func (t *Tracker) windowSince() int64 {
+	if t.windowNs <= 0 {
+		return 0
+	}
+	latest := t.latestNs.Load()
+	if latest <= 0 {
+		return 0
+	}
+	return latest - t.windowNs
+}
+
+// Reset 清空两条链路的时延窗口、小时分桶与尖峰检测状态
+// 用于已知网络事件恢复后重新建立统计，使分位数只反映之后的链路状况。
+// 逐交易对领先比较与领先占比统计不受影响。
+func (t *Tracker) Reset() {
+	for _, lt := range []linkTracker{t.okx, t.binance} {
+		lt.arrived.reset()
+		lt.event.reset()
+		lt.negative.reset()
+		for _, w := range lt.hourly {
+			w.reset()
+		}
+		lt.spike.reset()
 	}
+	t.latestNs.Store(0)
 }
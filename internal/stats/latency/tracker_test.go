@@ -200,3 +200,150 @@ func idxQuantile(sorted []int64, q float64) int {
 func approxEqual(a, b float64, eps float64) bool {
 	return math.Abs(a-b) <= eps
 }
+
+func TestTracker_HourlyStats(t *testing.T) {
+	tr := NewTracker(100)
+	hourNs := int64(3600) * 1_000_000_000
+	add := func(followerNs, lagMs int64) {
+		tr.Add(
+			&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs - lagMs*1_000_000},
+			&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs},
+		)
+	}
+	add(3*hourNs+1, 10)
+	add(3*hourNs+2, 10)
+	add(27*hourNs, 50) // 次日 03:00
+	add(20*hourNs, 5)
+
+	got := tr.HourlyStats(model.ExchangeOKX)
+	if len(got) != 2 || got[0].Hour != 3 || got[1].Hour != 20 {
+		t.Fatalf("HourlyStats=%+v, want hours [3 20]", got)
+	}
+	if got[0].Count != 3 || got[0].P50Ms != 10 || got[0].P99Ms != 10 {
+		t.Fatalf("hour 3: %+v", got[0])
+	}
+	if got := tr.HourlyStats(model.ExchangeBinance); got != nil {
+		t.Fatalf("binance 无样本应为 nil: %+v", got)
+	}
+}
+
+func TestTracker_TimeWindowAndReset(t *testing.T) {
+	tr := NewTracker(100)
+	tr.EnableTimeWindow(60_000) // 最近 1 分钟
+	minNs := int64(60) * 1_000_000_000
+	add := func(followerNs, lagMs int64) {
+		tr.Add(
+			&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs - lagMs*1_000_000},
+			&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs},
+		)
+	}
+	// 网络事件期间的高时延样本
+	for i := int64(0); i < 10; i++ {
+		add(10*minNs+i, 500)
+	}
+	if st := tr.Stats(model.ExchangeOKX); st.ArrivedP50Ms != 500 {
+		t.Fatalf("事件期间 P50=%v, want 500", st.ArrivedP50Ms)
+	}
+	// 两分钟后恢复正常：旧样本滚出时间窗口
+	for i := int64(0); i < 3; i++ {
+		add(12*minNs+i, 20)
+	}
+	st := tr.Stats(model.ExchangeOKX)
+	if st.ArrivedP50Ms != 20 || st.ArrivedP99Ms != 20 {
+		t.Fatalf("时间窗口 P50/P99=%v/%v, want 20/20", st.ArrivedP50Ms, st.ArrivedP99Ms)
+	}
+	if st.Count != 13 {
+		t.Fatalf("Count=%d, want 13（累计）", st.Count)
+	}
+
+	// 检查点保留样本时间，恢复后时间窗口仍然生效
+	restored := NewTracker(100)
+	restored.EnableTimeWindow(60_000)
+	restored.RestoreState(tr.ExportState())
+	if got := restored.Stats(model.ExchangeOKX); got.ArrivedP50Ms != 20 {
+		t.Fatalf("恢复后 P50=%v, want 20", got.ArrivedP50Ms)
+	}
+
+	tr.Reset()
+	if st := tr.Stats(model.ExchangeOKX); st.Count != 0 || st.ArrivedP50Ms != 0 {
+		t.Fatalf("Reset 后统计未清空: %+v", st)
+	}
+	if got := tr.HourlyStats(model.ExchangeOKX); got != nil {
+		t.Fatalf("Reset 后小时分桶未清空: %+v", got)
+	}
+}
+
+func TestTracker_TailStats(t *testing.T) {
+	tr := NewTracker(1000)
+	const baseNs = int64(1_000_000_000_000)
+	for ms := int64(1); ms <= 1000; ms++ {
+		followerNs := baseNs + ms*1_000_000_000
+		tr.Add(
+			&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs - ms*1_000_000},
+			&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs},
+		)
+	}
+	st := tr.Stats(model.ExchangeOKX)
+	if st.ArrivedP999Ms != 999 || st.ArrivedMinMs != 1 || st.ArrivedMaxMs != 1000 {
+		t.Fatalf("P99.9/min/max=%v/%v/%v, want 999/1/1000", st.ArrivedP999Ms, st.ArrivedMinMs, st.ArrivedMaxMs)
+	}
+	if !approxEqual(st.ArrivedMeanMs, 500.5, 1e-9) {
+		t.Fatalf("mean=%v, want 500.5", st.ArrivedMeanMs)
+	}
+	// Leader 无交易所时间戳：事件时延无样本，全部为 0
+	if st.EventP999Ms != 0 || st.EventMaxMs != 0 || st.EventMeanMs != 0 {
+		t.Fatalf("无事件时延样本时应为 0: %+v", st)
+	}
+}
+
+func TestTracker_NegativeLag(t *testing.T) {
+	tr := NewTracker(100)
+	add := func(lagMs int64) {
+		const followerNs = int64(1_000_000_000_000)
+		tr.Add(
+			&model.BookEvent{Exchange: model.ExchangeBinance, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs - lagMs*1_000_000},
+			&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs},
+		)
+	}
+	for i := 0; i < 5; i++ {
+		add(20)
+	}
+	add(-3)
+	add(-8)
+
+	st := tr.Stats(model.ExchangeBinance)
+	if st.Count != 5 || st.ArrivedMinMs != 20 {
+		t.Fatalf("负时延不应计入到达时延分布: count=%d min=%v", st.Count, st.ArrivedMinMs)
+	}
+	if st.NegativeCount != 2 || st.NegativeP50Ms != 3 || st.NegativeMaxMs != 8 {
+		t.Fatalf("负时延统计不符: count=%d p50=%v max=%v", st.NegativeCount, st.NegativeP50Ms, st.NegativeMaxMs)
+	}
+
+	// 检查点保留负时延样本
+	restored := NewTracker(100)
+	restored.RestoreState(tr.ExportState())
+	if got := restored.Stats(model.ExchangeBinance); got.NegativeCount != 2 || got.NegativeMaxMs != 8 {
+		t.Fatalf("恢复后负时延统计不符: %+v", got)
+	}
+}
+
+func TestTracker_ArrivedP50Ms(t *testing.T) {
+	tr := NewTracker(1000)
+	if got := tr.ArrivedP50Ms(model.ExchangeOKX); got != 0 {
+		t.Fatalf("无样本时应为 0，实际 %v", got)
+	}
+	const baseNs = int64(1_000_000_000_000)
+	for ms := int64(1); ms <= 99; ms++ {
+		followerNs := baseNs + ms*1_000_000_000
+		tr.Add(
+			&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs - ms*1_000_000},
+			&model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: followerNs},
+		)
+	}
+	if got, want := tr.ArrivedP50Ms(model.ExchangeOKX), tr.Stats(model.ExchangeOKX).ArrivedP50Ms; got != want || got != 50 {
+		t.Fatalf("ArrivedP50Ms=%v, Stats().ArrivedP50Ms=%v, want 50", got, want)
+	}
+	if got := tr.ArrivedP50Ms(model.ExchangeBinance); got != 0 {
+		t.Fatalf("Binance 链路无样本时应为 0，实际 %v", got)
+	}
+}
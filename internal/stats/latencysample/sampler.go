@@ -0,0 +1,73 @@
+// Package latencysample 对 Leader → Follower 时延观测做抽样输出（latency_samples.jsonl）。
+// 指标快照只给出分位数；原始样本用于研究分布形态（双峰、厚尾等），无需从分位数反推。
+package latencysample
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// SchemaVersion latency_samples.jsonl 记录格式版本（字段含义变更或删除字段时递增）
+const SchemaVersion = 1
+
+// Sample 单条时延样本
+// 口径与时延统计一致：以 Follower 更新到达时刻对照该 Leader 的最新快照。
+type Sample struct {
+	// SchemaVersion 输出格式版本
+	SchemaVersion int `json:"schema_version"`
+	// TsNs Follower 更新到达时间（纳秒）
+	TsNs int64 `json:"ts_ns"`
+	// Leader 链路 Leader（okx/binance）
+	Leader string `json:"leader"`
+	// SymbolCanon 统一交易对
+	SymbolCanon string `json:"symbol"`
+	// LagArrivedMs 到达时延 = Follower 到达时间 - Leader 到达时间（毫秒）
+	LagArrivedMs float64 `json:"lag_arrived_ms"`
+	// LagEventMs 事件时延 = Follower 到达时间 - Leader 交易所时间戳（毫秒，Leader 无时间戳时不输出）
+	LagEventMs float64 `json:"lag_event_ms,omitempty"`
+	// LeaderSeq/FollowerSeq 两侧快照的序列号
+	LeaderSeq   int64 `json:"leader_seq"`
+	FollowerSeq int64 `json:"follower_seq"`
+}
+
+// Sampler 时延样本抽样器（单 goroutine 使用，由聚合器独占）
+// 每条链路独立计数，每 every 次观测输出一条（首次观测即输出）。
+type Sampler struct {
+	every int64
+	seen  map[string]int64
+}
+
+// New 创建时延样本抽样器
+// 参数 every: 每条链路每 N 次观测输出一条（<=1 表示全部输出）
+func New(every int) *Sampler {
+	if every < 1 {
+		every = 1
+	}
+	return &Sampler{every: int64(every), seen: make(map[string]int64, 2)}
+}
+
+// Observe 记录一次时延观测
+// 返回: (样本, 是否命中抽样)
+func (s *Sampler) Observe(leaderBook, followerBook *model.BookEvent) (*Sample, bool) {
+	if leaderBook == nil || followerBook == nil {
+		return nil, false
+	}
+	n := s.seen[leaderBook.Exchange]
+	s.seen[leaderBook.Exchange] = n + 1
+	if n%s.every != 0 {
+		return nil, false
+	}
+	sample := &Sample{
+		SchemaVersion: SchemaVersion,
+		TsNs:          followerBook.ArrivedAtUnixNs,
+		Leader:        leaderBook.Exchange,
+		SymbolCanon:   followerBook.SymbolCanon,
+		LagArrivedMs:  float64(followerBook.ArrivedAtUnixNs-leaderBook.ArrivedAtUnixNs) / 1e6,
+		LeaderSeq:     leaderBook.Seq,
+		FollowerSeq:   followerBook.Seq,
+	}
+	if leaderBook.ExchTsUnixMs > 0 {
+		sample.LagEventMs = float64(followerBook.ArrivedAtUnixNs-timeutil.MsToNano(leaderBook.ExchTsUnixMs)) / 1e6
+	}
+	return sample, true
+}
@@ -0,0 +1,43 @@
+// Package latencysample 时延样本抽样器测试
+package latencysample
+
+import (
+	"math"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestSampler_EveryN(t *testing.T) {
+	s := New(3)
+	okx := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_000_000_000, ExchTsUnixMs: 995, Seq: 11}
+	binance := &model.BookEvent{Exchange: model.ExchangeBinance, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_000_000_000}
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", ArrivedAtUnixNs: 1_012_500_000, Seq: 22}
+
+	var hits []int
+	for i := 0; i < 7; i++ {
+		if _, ok := s.Observe(okx, follower); ok {
+			hits = append(hits, i)
+		}
+	}
+	if len(hits) != 3 || hits[0] != 0 || hits[1] != 3 || hits[2] != 6 {
+		t.Fatalf("应每 3 次观测输出一条, got %v", hits)
+	}
+
+	// 各链路独立计数
+	sample, ok := s.Observe(binance, follower)
+	if !ok {
+		t.Fatalf("Binance 链路首次观测应输出")
+	}
+	if sample.LagEventMs != 0 {
+		t.Errorf("Leader 无交易所时间戳时 LagEventMs 应为 0, got %v", sample.LagEventMs)
+	}
+
+	sample, _ = New(1).Observe(okx, follower)
+	if math.Abs(sample.LagArrivedMs-12.5) > 1e-9 || math.Abs(sample.LagEventMs-17.5) > 1e-9 {
+		t.Errorf("时延计算不符: arrived=%v event=%v", sample.LagArrivedMs, sample.LagEventMs)
+	}
+	if sample.Leader != model.ExchangeOKX || sample.LeaderSeq != 11 || sample.FollowerSeq != 22 {
+		t.Errorf("样本字段不符: %+v", sample)
+	}
+}
@@ -0,0 +1,267 @@
+// Package leadlag 基于中间价收益率互相关估计 Leader 对 Follower 的信息领先。
+// 到达时间差只说明谁的消息先到，不能证明价格发现发生在 Leader；
+// 互相关峰值出现在正滞后处，才说明 Leader 的价格变动能预测 Follower 随后的变动。
+//
+// 中间价按固定时间桶（如 10ms）前向填充采样，收益率为相邻桶的对数收益。
+// 采样（Observe）在聚合器 goroutine 中进行，只做 O(1) 写入；
+// 互相关计算（Compute）开销较大，应在快照后于独立 goroutine 中执行。
+package leadlag
+
+import (
+	"math"
+	"sort"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// Result 单条链路、单个交易对的互相关估计结果
+type Result struct {
+	// TsUnixNs 计算时间（纳秒）
+	TsUnixNs int64 `json:"ts_unix_ns"`
+	// Leader 领先交易所: okx 或 binance
+	Leader string `json:"leader"`
+	// SymbolCanon 内部统一交易对
+	SymbolCanon string `json:"symbol_canon"`
+	// BucketMs 采样桶宽度（毫秒）
+	BucketMs int `json:"bucket_ms"`
+	// Samples 参与计算的有效收益率桶数（零滞后处）
+	Samples int `json:"samples"`
+	// LagsMs 滞后网格（毫秒，正值表示 Leader 领先 Follower）
+	LagsMs []int `json:"lags_ms"`
+	// Corr 各滞后处的相关系数（样本不足时为 0）
+	Corr []float64 `json:"corr"`
+	// PeakLagMs 相关系数最大处的滞后（毫秒）
+	PeakLagMs int `json:"peak_lag_ms"`
+	// PeakCorr 最大相关系数
+	PeakCorr float64 `json:"peak_corr"`
+}
+
+// series 单个交易所、单个交易对的前向填充中间价环形序列
+type series struct {
+	mids []float64
+	// firstBucket 首个有效桶序号
+	firstBucket int64
+	// lastBucket 最近写入的桶序号
+	lastBucket int64
+	lastMid    float64
+}
+
+func newSeries(size int) *series {
+	return &series{mids: make([]float64, size), firstBucket: -1}
+}
+
+// observe 写入桶 b 的中间价；乱序（早于最近桶）的更新忽略
+func (s *series) observe(b int64, mid float64) {
+	n := int64(len(s.mids))
+	if s.firstBucket < 0 {
+		s.firstBucket, s.lastBucket = b, b
+		s.mids[b%n] = mid
+		s.lastMid = mid
+		return
+	}
+	if b < s.lastBucket {
+		return
+	}
+	// 前向填充中间缺失的桶（最多填满一圈）
+	from := s.lastBucket + 1
+	if b-from > n {
+		from = b - n
+	}
+	for i := from; i < b; i++ {
+		s.mids[i%n] = s.lastMid
+	}
+	s.mids[b%n] = mid
+	s.lastBucket = b
+	s.lastMid = mid
+}
+
+// window 导出桶 [end-len+1, end] 的中间价（未观测到的桶为 NaN）
+func (s *series) window(end int64) []float64 {
+	n := int64(len(s.mids))
+	out := make([]float64, n)
+	start := end - n + 1
+	for i := int64(0); i < n; i++ {
+		b := start + i
+		switch {
+		case s.firstBucket < 0 || b < s.firstBucket || b <= s.lastBucket-n:
+			out[i] = math.NaN()
+		case b > s.lastBucket:
+			out[i] = s.lastMid
+		default:
+			out[i] = s.mids[b%n]
+		}
+	}
+	return out
+}
+
+// Pair 一条链路、一个交易对的采样快照（供 Compute 使用）
+type Pair struct {
+	TsUnixNs    int64
+	Leader      string
+	SymbolCanon string
+	BucketMs    int
+	MaxLag      int
+	// LeaderMids / FollowerMids 对齐的桶中间价（NaN 表示无数据）
+	LeaderMids   []float64
+	FollowerMids []float64
+}
+
+// Estimator 互相关采样器
+// 非并发安全：Observe 与 Snapshot 须在同一 goroutine 中调用。
+type Estimator struct {
+	bucketNs   int64
+	bucketMs   int
+	maxLag     int
+	windowSize int
+
+	// series 按 交易所 → 交易对 索引
+	series map[string]map[string]*series
+}
+
+// NewEstimator 创建互相关采样器
+// 参数 bucketMs: 采样桶宽度（毫秒）
+// 参数 maxLagMs: 最大滞后（毫秒），滞后网格为 [-maxLagMs, maxLagMs]，步长 bucketMs
+// 参数 windowMs: 计算窗口长度（毫秒）
+func NewEstimator(bucketMs, maxLagMs, windowMs int) *Estimator {
+	if bucketMs <= 0 {
+		bucketMs = 10
+	}
+	windowSize := windowMs / bucketMs
+	if windowSize < 2 {
+		windowSize = 2
+	}
+	return &Estimator{
+		bucketNs:   int64(bucketMs) * 1_000_000,
+		bucketMs:   bucketMs,
+		maxLag:     maxLagMs / bucketMs,
+		windowSize: windowSize,
+		series:     make(map[string]map[string]*series, 3),
+	}
+}
+
+// Observe 记录一条行情的中间价
+func (e *Estimator) Observe(ev *model.BookEvent) {
+	if ev == nil || ev.SymbolCanon == "" || ev.BestBidPx <= 0 || ev.BestAskPx <= 0 {
+		return
+	}
+	bySym := e.series[ev.Exchange]
+	if bySym == nil {
+		bySym = make(map[string]*series)
+		e.series[ev.Exchange] = bySym
+	}
+	s := bySym[ev.SymbolCanon]
+	if s == nil {
+		s = newSeries(e.windowSize)
+		bySym[ev.SymbolCanon] = s
+	}
+	s.observe(ev.ArrivedAtUnixNs/e.bucketNs, (ev.BestBidPx+ev.BestAskPx)/2)
+}
+
+// Snapshot 导出各链路、各交易对截至 nowNs 前最后一个完整桶的采样窗口
+// 返回按（Leader, 交易对）排序的快照；仅包含 Leader 与 Follower 均有数据的交易对。
+func (e *Estimator) Snapshot(nowNs int64) []Pair {
+	end := nowNs/e.bucketNs - 1
+	followers := e.series[model.ExchangeBittap]
+	var out []Pair
+	for _, leader := range []string{model.ExchangeOKX, model.ExchangeBinance} {
+		syms := make([]string, 0, len(e.series[leader]))
+		for sym := range e.series[leader] {
+			if followers[sym] != nil {
+				syms = append(syms, sym)
+			}
+		}
+		sort.Strings(syms)
+		for _, sym := range syms {
+			out = append(out, Pair{
+				TsUnixNs:     nowNs,
+				Leader:       leader,
+				SymbolCanon:  sym,
+				BucketMs:     e.bucketMs,
+				MaxLag:       e.maxLag,
+				LeaderMids:   e.series[leader][sym].window(end),
+				FollowerMids: followers[sym].window(end),
+			})
+		}
+	}
+	return out
+}
+
+// returns 计算相邻桶对数收益（任一端无数据时为 NaN）
+func returns(mids []float64) []float64 {
+	if len(mids) < 2 {
+		return nil
+	}
+	out := make([]float64, len(mids)-1)
+	for i := 1; i < len(mids); i++ {
+		prev, cur := mids[i-1], mids[i]
+		if math.IsNaN(prev) || math.IsNaN(cur) || prev <= 0 || cur <= 0 {
+			out[i-1] = math.NaN()
+			continue
+		}
+		out[i-1] = math.Log(cur / prev)
+	}
+	return out
+}
+
+// corrAt 计算 corr(l[t], f[t+lag])，跳过 NaN；返回相关系数与样本数
+func corrAt(l, f []float64, lag int) (float64, int) {
+	var n int
+	var sumL, sumF, sumLL, sumFF, sumLF float64
+	for t := range l {
+		j := t + lag
+		if j < 0 || j >= len(f) {
+			continue
+		}
+		x, y := l[t], f[j]
+		if math.IsNaN(x) || math.IsNaN(y) {
+			continue
+		}
+		n++
+		sumL += x
+		sumF += y
+		sumLL += x * x
+		sumFF += y * y
+		sumLF += x * y
+	}
+	if n < 2 {
+		return 0, n
+	}
+	fn := float64(n)
+	cov := sumLF - sumL*sumF/fn
+	varL := sumLL - sumL*sumL/fn
+	varF := sumFF - sumF*sumF/fn
+	if varL <= 0 || varF <= 0 {
+		return 0, n
+	}
+	return cov / math.Sqrt(varL*varF), n
+}
+
+// Compute 计算滞后网格上的互相关
+func (p Pair) Compute() Result {
+	res := Result{
+		TsUnixNs:    p.TsUnixNs,
+		Leader:      p.Leader,
+		SymbolCanon: p.SymbolCanon,
+		BucketMs:    p.BucketMs,
+		LagsMs:      make([]int, 0, 2*p.MaxLag+1),
+		Corr:        make([]float64, 0, 2*p.MaxLag+1),
+	}
+	l := returns(p.LeaderMids)
+	f := returns(p.FollowerMids)
+
+	peakSet := false
+	for k := -p.MaxLag; k <= p.MaxLag; k++ {
+		c, n := corrAt(l, f, k)
+		if k == 0 {
+			res.Samples = n
+		}
+		res.LagsMs = append(res.LagsMs, k*p.BucketMs)
+		res.Corr = append(res.Corr, c)
+		if !peakSet || c > res.PeakCorr {
+			res.PeakCorr = c
+			res.PeakLagMs = k * p.BucketMs
+			peakSet = true
+		}
+	}
+	return res
+}
@@ -0,0 +1,81 @@
+// Package leadlag 收益率互相关估计测试
+package leadlag
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func book(ex string, mid float64, arrivedMs int64) *model.BookEvent {
+	return &model.BookEvent{
+		Exchange:        ex,
+		SymbolCanon:     "BTCUSDT",
+		BestBidPx:       mid - 0.05,
+		BestAskPx:       mid + 0.05,
+		ArrivedAtUnixNs: arrivedMs * 1_000_000,
+	}
+}
+
+func TestEstimator_PeakAtFollowerDelay(t *testing.T) {
+	const delayMs = 30
+	e := NewEstimator(10, 100, 20000)
+	rng := rand.New(rand.NewSource(1))
+
+	// Leader 随机游走，Follower 在 30ms 后复制 Leader 价格
+	mid := 100.0
+	var mids []float64
+	for ms := int64(0); ms < 20000; ms += 10 {
+		mid += rng.NormFloat64() * 0.1
+		mids = append(mids, mid)
+		e.Observe(book(model.ExchangeOKX, mid, ms))
+		if i := len(mids) - 1 - delayMs/10; i >= 0 {
+			e.Observe(book(model.ExchangeBittap, mids[i], ms))
+		}
+	}
+
+	pairs := e.Snapshot(20000 * 1_000_000)
+	if len(pairs) != 1 || pairs[0].Leader != model.ExchangeOKX {
+		t.Fatalf("pairs=%d, want 1 (okx)", len(pairs))
+	}
+	res := pairs[0].Compute()
+	if res.PeakLagMs != delayMs {
+		t.Fatalf("PeakLagMs=%d, want %d", res.PeakLagMs, delayMs)
+	}
+	if res.PeakCorr < 0.99 {
+		t.Fatalf("PeakCorr=%v, want ~1", res.PeakCorr)
+	}
+	if len(res.LagsMs) != 21 || res.LagsMs[0] != -100 || res.LagsMs[20] != 100 {
+		t.Fatalf("滞后网格错误: %v", res.LagsMs)
+	}
+}
+
+func TestSeries_ForwardFillAndGaps(t *testing.T) {
+	s := newSeries(4)
+	s.observe(10, 1)
+	s.observe(12, 3)
+
+	got := s.window(13)
+	// 桶 10..13：1, 1(前向填充), 3, 3(最近值延续)
+	want := []float64{1, 1, 3, 3}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("window=%v, want %v", got, want)
+		}
+	}
+
+	// 首个观测之前的桶无数据
+	if got := s.window(11); !math.IsNaN(got[0]) || !math.IsNaN(got[1]) || got[2] != 1 {
+		t.Fatalf("window(11)=%v, 前两个桶应为 NaN", got)
+	}
+}
+
+func TestCorrAt_Degenerate(t *testing.T) {
+	// 常数序列方差为 0，相关系数为 0
+	c, n := corrAt([]float64{0, 0, 0}, []float64{1, 2, 3}, 0)
+	if c != 0 || n != 3 {
+		t.Fatalf("corr=%v n=%d, want 0/3", c, n)
+	}
+}
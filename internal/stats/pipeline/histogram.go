@@ -0,0 +1,109 @@
+// Package pipeline 统计本机处理管线各阶段耗时。
+// 阶段划分：read→parse（解析）、parse→aggregator（通道排队）、aggregator→engine（聚合器处理），
+// 用于区分交易所本身的 lead-lag 与本进程的处理延迟。
+package pipeline
+
+import "math"
+
+// BucketBoundsUs 直方图桶上界（微秒，含）
+// 最后一个桶收纳超过最大上界的样本，因此桶数为 len(BucketBoundsUs)+1。
+var BucketBoundsUs = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 25000, 50000, 100000}
+
+// HistogramStats 直方图快照
+type HistogramStats struct {
+	// Count 样本数
+	Count int64 `json:"count"`
+	// MeanUs 平均耗时（微秒）
+	MeanUs float64 `json:"mean_us"`
+	// P50Us/P90Us/P99Us 分位数（取所在桶上界估计，微秒）
+	P50Us float64 `json:"p50_us"`
+	P90Us float64 `json:"p90_us"`
+	P99Us float64 `json:"p99_us"`
+	// MaxUs 最大耗时（微秒）
+	MaxUs float64 `json:"max_us"`
+	// Buckets 各桶样本数，与 BucketBoundsUs 对应（末位为溢出桶）
+	Buckets []int64 `json:"buckets"`
+}
+
+// Histogram 固定桶耗时直方图（非并发安全，由调用方保证单 goroutine 访问）
+type Histogram struct {
+	buckets []int64
+	count   int64
+	sumUs   float64
+	maxUs   float64
+}
+
+// NewHistogram 创建耗时直方图
+func NewHistogram() *Histogram {
+	return &Histogram{buckets: make([]int64, len(BucketBoundsUs)+1)}
+}
+
+// Add 记录一个耗时样本
+// 参数 durNs: 耗时（纳秒），负值按 0 处理（时钟回拨）
+func (h *Histogram) Add(durNs int64) {
+	if durNs < 0 {
+		durNs = 0
+	}
+	us := float64(durNs) / 1000
+
+	idx := len(BucketBoundsUs)
+	for i, bound := range BucketBoundsUs {
+		if us <= bound {
+			idx = i
+			break
+		}
+	}
+	h.buckets[idx]++
+	h.count++
+	h.sumUs += us
+	if us > h.maxUs {
+		h.maxUs = us
+	}
+}
+
+// Stats 获取直方图快照
+func (h *Histogram) Stats() HistogramStats {
+	out := HistogramStats{
+		Count:   h.count,
+		MaxUs:   h.maxUs,
+		Buckets: append([]int64(nil), h.buckets...),
+	}
+	if h.count == 0 {
+		return out
+	}
+	out.MeanUs = h.sumUs / float64(h.count)
+	out.P50Us = h.quantile(0.50)
+	out.P90Us = h.quantile(0.90)
+	out.P99Us = h.quantile(0.99)
+	return out
+}
+
+// Reset 清空直方图
+func (h *Histogram) Reset() {
+	for i := range h.buckets {
+		h.buckets[i] = 0
+	}
+	h.count = 0
+	h.sumUs = 0
+	h.maxUs = 0
+}
+
+// quantile 以所在桶上界估计分位数（溢出桶与不超过上界的情况取最大值）
+func (h *Histogram) quantile(q float64) float64 {
+	rank := int64(math.Ceil(q * float64(h.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	var cum int64
+	for i, n := range h.buckets {
+		cum += n
+		if cum < rank {
+			continue
+		}
+		if i < len(BucketBoundsUs) {
+			return math.Min(BucketBoundsUs[i], h.maxUs)
+		}
+		break
+	}
+	return h.maxUs
+}
@@ -0,0 +1,62 @@
+// Package pipeline 管线耗时统计测试
+package pipeline
+
+import (
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestHistogram_Quantiles(t *testing.T) {
+	h := NewHistogram()
+	// 90 个 8µs（落在 10µs 桶），9 个 80µs（100µs 桶），1 个 300ms（溢出桶）
+	for i := 0; i < 90; i++ {
+		h.Add(8_000)
+	}
+	for i := 0; i < 9; i++ {
+		h.Add(80_000)
+	}
+	h.Add(300_000_000)
+
+	s := h.Stats()
+	if s.Count != 100 {
+		t.Fatalf("Count=%d, want 100", s.Count)
+	}
+	if s.P50Us != 10 || s.P90Us != 10 || s.P99Us != 100 {
+		t.Fatalf("P50/P90/P99=%v/%v/%v, want 10/10/100", s.P50Us, s.P90Us, s.P99Us)
+	}
+	if s.MaxUs != 300_000 {
+		t.Fatalf("MaxUs=%v, want 300000", s.MaxUs)
+	}
+	if s.Buckets[1] != 90 || s.Buckets[4] != 9 || s.Buckets[len(BucketBoundsUs)] != 1 {
+		t.Fatalf("Buckets=%v", s.Buckets)
+	}
+
+	h.Reset()
+	if s := h.Stats(); s.Count != 0 || s.MaxUs != 0 || s.P99Us != 0 {
+		t.Fatalf("Reset 后应为零值: %+v", s)
+	}
+}
+
+func TestTracker_ObserveAndSnapshot(t *testing.T) {
+	tr := NewTracker()
+	ev := &model.BookEvent{Exchange: model.ExchangeBittap, ArrivedAtUnixNs: 1_000_000, ParsedAtUnixNs: 1_020_000}
+	tr.Observe(ev, 1_500_000, 1_540_000)
+	// 缺少解析时间（如回放事件）不计入
+	tr.Observe(&model.BookEvent{Exchange: model.ExchangeOKX, ArrivedAtUnixNs: 1}, 2, 3)
+
+	snap := tr.Snapshot()
+	if _, ok := snap[model.ExchangeOKX]; ok {
+		t.Fatalf("无 ParsedAtUnixNs 的事件不应计入")
+	}
+	s := snap[model.ExchangeBittap]
+	if s.Parse.MaxUs != 20 || s.Queue.MaxUs != 480 || s.Handle.MaxUs != 40 || s.Total.MaxUs != 540 {
+		t.Fatalf("阶段耗时错误: parse=%v queue=%v handle=%v total=%v",
+			s.Parse.MaxUs, s.Queue.MaxUs, s.Handle.MaxUs, s.Total.MaxUs)
+	}
+
+	// 快照后清零
+	if s := tr.Snapshot()[model.ExchangeBittap]; s.Total.Count != 0 {
+		t.Fatalf("Snapshot 后应清零, Count=%d", s.Total.Count)
+	}
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import "latency-arbitrage-validator/internal/core/model"
+
+// Stats 单个交易所输入的各阶段耗时快照
+type Stats struct {
+	// Parse socket 读出 → 解析完成
+	Parse HistogramStats `json:"parse"`
+	// Queue 解析完成 → 聚合器取出（含通道排队与冗余合并）
+	Queue HistogramStats `json:"queue"`
+	// Handle 聚合器取出 → 各链路策略评估完成
+	Handle HistogramStats `json:"handle"`
+	// Total socket 读出 → 策略评估完成
+	Total HistogramStats `json:"total"`
+}
+
+type stages struct {
+	parse  *Histogram
+	queue  *Histogram
+	handle *Histogram
+	total  *Histogram
+}
+
+// Tracker 管线耗时追踪器（按交易所分组）
+// 仅由聚合器 goroutine 访问，不加锁；快照后清零，即统计窗口为相邻两次快照之间。
+type Tracker struct {
+	byExchange map[string]*stages
+}
+
+// NewTracker 创建管线耗时追踪器
+func NewTracker() *Tracker {
+	return &Tracker{byExchange: make(map[string]*stages)}
+}
+
+// Observe 记录一个事件在各阶段的耗时
+// 参数 ev: 订单簿事件（需带 ArrivedAtUnixNs 与 ParsedAtUnixNs）
+// 参数 dequeuedNs: 聚合器取出事件的时间（纳秒）
+// 参数 handledNs: 聚合器处理完成的时间（纳秒）
+func (t *Tracker) Observe(ev *model.BookEvent, dequeuedNs, handledNs int64) {
+	if ev == nil || ev.ArrivedAtUnixNs <= 0 || ev.ParsedAtUnixNs <= 0 {
+		return
+	}
+	s := t.byExchange[ev.Exchange]
+	if s == nil {
+		s = &stages{parse: NewHistogram(), queue: NewHistogram(), handle: NewHistogram(), total: NewHistogram()}
+		t.byExchange[ev.Exchange] = s
+	}
+	s.parse.Add(ev.ParsedAtUnixNs - ev.ArrivedAtUnixNs)
+	s.queue.Add(dequeuedNs - ev.ParsedAtUnixNs)
+	s.handle.Add(handledNs - dequeuedNs)
+	s.total.Add(handledNs - ev.ArrivedAtUnixNs)
+}
+
+// Snapshot 获取各交易所的阶段耗时并清零
+func (t *Tracker) Snapshot() map[string]Stats {
+	out := make(map[string]Stats, len(t.byExchange))
+	for ex, s := range t.byExchange {
+		out[ex] = Stats{
+			Parse:  s.parse.Stats(),
+			Queue:  s.queue.Stats(),
+			Handle: s.handle.Stats(),
+			Total:  s.total.Stats(),
+		}
+		s.parse.Reset()
+		s.queue.Reset()
+		s.handle.Reset()
+		s.total.Reset()
+	}
+	return out
+}
@@ -0,0 +1,229 @@
+// Package pnlfactor 分析影子成交净利与开仓时市场状态的关系（validator analyze）。
+// 影子成交按信号 ID 关联到 signals.jsonl 的触发快照（价差、深度），
+// 按开仓时刻关联到同一链路之前最近的 latency_samples.jsonl 样本（实际观测到的滞后），
+// 再按因子分位数分桶并做 OLS 回归，回答"滞后多大、价差多大、深度多厚、什么时段才赚钱"。
+package pnlfactor
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/stats/latencysample"
+)
+
+// 因子名称
+const (
+	// FactorLag 开仓前最近一条时延样本的到达时延（毫秒）
+	FactorLag = "lag_ms"
+	// FactorSpread 触发信号的价差（基点）
+	FactorSpread = "spread_bps"
+	// FactorDepth 触发时 Leader 前 5 档名义价值（USD，回归中取 log10）
+	FactorDepth = "depth_usd"
+	// FactorHour 开仓 UTC 小时
+	FactorHour = "hour_utc"
+)
+
+// Observation 单笔影子成交及其开仓时的因子
+// 无法关联的因子为 NaN，分桶与回归时跳过该笔。
+type Observation struct {
+	// Leader/Variant 链路与策略变体
+	Leader  string
+	Variant string
+	// NetPnLBps 净利（基点）
+	NetPnLBps float64
+	// LagMs/SpreadBps/DepthUSD 因子值
+	LagMs     float64
+	SpreadBps float64
+	DepthUSD  float64
+	// Hour 开仓 UTC 小时
+	Hour int
+}
+
+// Value 返回指定因子的取值（未知因子为 NaN）
+func (o *Observation) Value(factor string) float64 {
+	switch factor {
+	case FactorLag:
+		return o.LagMs
+	case FactorSpread:
+		return o.SpreadBps
+	case FactorDepth:
+		return o.DepthUSD
+	case FactorHour:
+		return float64(o.Hour)
+	default:
+		return math.NaN()
+	}
+}
+
+// signalKey 信号关联键（不同变体的信号 ID 可能相同）
+type signalKey struct {
+	variant string
+	id      string
+}
+
+// Joiner 关联影子成交、信号与时延样本
+type Joiner struct {
+	// maxGapNs 时延样本距开仓时刻的最大间隔（纳秒），超过视为无观测
+	maxGapNs int64
+	signals  map[signalKey]*model.Signal
+	// samples 按 "leader|symbol" 分组的时延样本
+	samples map[string][]latencysample.Sample
+	// sorted 各组样本是否已按时间升序排列
+	sorted bool
+}
+
+// NewJoiner 创建关联器
+// 参数 maxGapMs: 时延样本距开仓时刻的最大间隔（毫秒）
+func NewJoiner(maxGapMs int64) *Joiner {
+	return &Joiner{
+		maxGapNs: maxGapMs * 1_000_000,
+		signals:  make(map[signalKey]*model.Signal),
+		samples:  make(map[string][]latencysample.Sample),
+	}
+}
+
+// AddSignal 记录一条信号（只保留关联所需字段，深度档位丢弃）
+func (j *Joiner) AddSignal(s *model.Signal) {
+	j.signals[signalKey{s.Variant, s.ID}] = s.WithoutDepth()
+}
+
+// AddSample 记录一条时延样本
+func (j *Joiner) AddSample(s *latencysample.Sample) {
+	k := s.Leader + "|" + s.SymbolCanon
+	j.samples[k] = append(j.samples[k], *s)
+	j.sorted = false
+}
+
+// Join 生成一笔影子成交的观测（需在全部信号与样本加入之后调用）
+func (j *Joiner) Join(t *model.PaperTrade) Observation {
+	o := Observation{
+		Leader:    t.Leader,
+		Variant:   t.Variant,
+		NetPnLBps: t.NetPnLBps,
+		LagMs:     math.NaN(),
+		SpreadBps: math.NaN(),
+		DepthUSD:  math.NaN(),
+		Hour:      time.Unix(0, t.TEntryNs).UTC().Hour(),
+	}
+	atNs := t.TEntryNs
+	if s := j.signals[signalKey{t.Variant, t.SignalID}]; s != nil {
+		o.SpreadBps = s.SpreadBps
+		if s.DepthUSD > 0 {
+			o.DepthUSD = s.DepthUSD
+		}
+		if s.DetectedAtNs > 0 {
+			atNs = s.DetectedAtNs
+		}
+	}
+	if lag, ok := j.lagAt(t.Leader, t.SymbolCanon, atNs); ok {
+		o.LagMs = lag
+	}
+	return o
+}
+
+// lagAt 返回 atNs 之前（含）最近一条样本的到达时延
+func (j *Joiner) lagAt(leader, symbol string, atNs int64) (float64, bool) {
+	if !j.sorted {
+		for _, s := range j.samples {
+			sort.SliceStable(s, func(a, b int) bool { return s[a].TsNs < s[b].TsNs })
+		}
+		j.sorted = true
+	}
+	samples := j.samples[leader+"|"+symbol]
+	if len(samples) == 0 {
+		return 0, false
+	}
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].TsNs > atNs }) - 1
+	if i < 0 || atNs-samples[i].TsNs > j.maxGapNs {
+		return 0, false
+	}
+	return samples[i].LagArrivedMs, true
+}
+
+// Bucket 单个因子分桶的净利统计
+type Bucket struct {
+	// Lo/Hi 桶内因子取值范围（闭区间）
+	Lo, Hi float64
+	// Trades 成交笔数
+	Trades int
+	// WinRate 胜率（净利 > 0）
+	WinRate float64
+	// AvgNetBps 平均净利（基点）
+	AvgNetBps float64
+}
+
+// QuantileBuckets 按因子取值的分位数将观测分为 n 个等笔数桶（取值相同的观测不跨桶）
+// 小时因子请使用 HourBuckets。
+func QuantileBuckets(obs []Observation, factor string, n int) []Bucket {
+	type pair struct{ x, y float64 }
+	var ps []pair
+	for i := range obs {
+		if x := obs[i].Value(factor); !math.IsNaN(x) {
+			ps = append(ps, pair{x, obs[i].NetPnLBps})
+		}
+	}
+	if len(ps) == 0 || n <= 0 {
+		return nil
+	}
+	sort.Slice(ps, func(a, b int) bool { return ps[a].x < ps[b].x })
+
+	var out []Bucket
+	start := 0
+	for k := 1; k <= n && start < len(ps); k++ {
+		end := len(ps) * k / n
+		if k == n {
+			end = len(ps)
+		}
+		if end <= start {
+			continue
+		}
+		for end < len(ps) && ps[end].x == ps[end-1].x {
+			end++
+		}
+		b := Bucket{Lo: ps[start].x, Hi: ps[end-1].x, Trades: end - start}
+		wins, sum := 0, 0.0
+		for _, p := range ps[start:end] {
+			sum += p.y
+			if p.y > 0 {
+				wins++
+			}
+		}
+		b.WinRate = float64(wins) / float64(b.Trades)
+		b.AvgNetBps = sum / float64(b.Trades)
+		out = append(out, b)
+		start = end
+	}
+	return out
+}
+
+// HourBuckets 按开仓 UTC 小时分桶（只输出有成交的小时）
+func HourBuckets(obs []Observation) []Bucket {
+	var hours [24]struct {
+		n, wins int
+		sum     float64
+	}
+	for i := range obs {
+		h := &hours[obs[i].Hour]
+		h.n++
+		h.sum += obs[i].NetPnLBps
+		if obs[i].NetPnLBps > 0 {
+			h.wins++
+		}
+	}
+	var out []Bucket
+	for hour, h := range hours {
+		if h.n == 0 {
+			continue
+		}
+		out = append(out, Bucket{
+			Lo:        float64(hour),
+			Hi:        float64(hour),
+			Trades:    h.n,
+			WinRate:   float64(h.wins) / float64(h.n),
+			AvgNetBps: h.sum / float64(h.n),
+		})
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+// Package pnlfactor 净利因子分析测试
+package pnlfactor
+
+import (
+	"math"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/stats/latencysample"
+)
+
+const hourNs = int64(3_600_000_000_000)
+
+func TestJoiner_Join(t *testing.T) {
+	j := NewJoiner(1000)
+	j.AddSignal(&model.Signal{ID: "s1", SpreadBps: 12, DepthUSD: 5e5, DetectedAtNs: 3*hourNs + 500_000_000})
+	j.AddSignal(&model.Signal{ID: "s1", Variant: "wide", SpreadBps: 20})
+	// 样本乱序加入
+	j.AddSample(&latencysample.Sample{TsNs: 3*hourNs + 400_000_000, Leader: "okx", SymbolCanon: "BTCUSDT", LagArrivedMs: 80})
+	j.AddSample(&latencysample.Sample{TsNs: 3*hourNs + 100_000_000, Leader: "okx", SymbolCanon: "BTCUSDT", LagArrivedMs: 40})
+	j.AddSample(&latencysample.Sample{TsNs: 3*hourNs + 600_000_000, Leader: "okx", SymbolCanon: "BTCUSDT", LagArrivedMs: 10})
+
+	o := j.Join(&model.PaperTrade{SignalID: "s1", Leader: "okx", SymbolCanon: "BTCUSDT", TEntryNs: 3*hourNs + 700_000_000, NetPnLBps: 3})
+	// 按信号检测时刻取之前最近的样本
+	if o.SpreadBps != 12 || o.DepthUSD != 5e5 || o.LagMs != 80 || o.Hour != 3 || o.NetPnLBps != 3 {
+		t.Errorf("关联结果不符: %+v", o)
+	}
+
+	// 变体信号按（变体, ID）关联；无深度与超出间隔的时延为 NaN
+	o = j.Join(&model.PaperTrade{SignalID: "s1", Variant: "wide", Leader: "okx", SymbolCanon: "BTCUSDT", TEntryNs: 5 * hourNs})
+	if o.SpreadBps != 20 || !math.IsNaN(o.DepthUSD) || !math.IsNaN(o.LagMs) || o.Hour != 5 {
+		t.Errorf("变体关联结果不符: %+v", o)
+	}
+	// 无信号时按开仓时刻关联时延
+	o = j.Join(&model.PaperTrade{SignalID: "missing", Leader: "okx", SymbolCanon: "BTCUSDT", TEntryNs: 3*hourNs + 700_000_000})
+	if !math.IsNaN(o.SpreadBps) || o.LagMs != 10 {
+		t.Errorf("无信号关联结果不符: %+v", o)
+	}
+}
+
+func TestQuantileBuckets(t *testing.T) {
+	var obs []Observation
+	for i := 0; i < 10; i++ {
+		// 时延越小净利越高
+		obs = append(obs, Observation{LagMs: float64(i * 10), NetPnLBps: float64(5 - i)})
+	}
+	obs = append(obs, Observation{LagMs: math.NaN(), NetPnLBps: 100})
+
+	bs := QuantileBuckets(obs, FactorLag, 2)
+	if len(bs) != 2 {
+		t.Fatalf("分桶数 %d, want 2", len(bs))
+	}
+	if bs[0].Lo != 0 || bs[0].Hi != 40 || bs[0].Trades != 5 || bs[0].AvgNetBps != 3 || bs[0].WinRate != 1 {
+		t.Errorf("低时延桶不符: %+v", bs[0])
+	}
+	if bs[1].Trades != 5 || bs[1].AvgNetBps != -2 || bs[1].WinRate != 0 {
+		t.Errorf("高时延桶不符: %+v", bs[1])
+	}
+
+	// 取值相同的观测不跨桶
+	same := []Observation{{SpreadBps: 1}, {SpreadBps: 1}, {SpreadBps: 1}, {SpreadBps: 2}}
+	bs = QuantileBuckets(same, FactorSpread, 2)
+	if len(bs) != 2 || bs[0].Trades != 3 || bs[1].Trades != 1 {
+		t.Errorf("相同取值分桶不符: %+v", bs)
+	}
+}
+
+func TestHourBuckets(t *testing.T) {
+	obs := []Observation{{Hour: 8, NetPnLBps: 2}, {Hour: 8, NetPnLBps: -1}, {Hour: 0, NetPnLBps: 1}}
+	bs := HourBuckets(obs)
+	if len(bs) != 2 || bs[0].Lo != 0 || bs[1].Lo != 8 || bs[1].Trades != 2 || bs[1].AvgNetBps != 0.5 || bs[1].WinRate != 0.5 {
+		t.Errorf("小时分桶不符: %+v", bs)
+	}
+}
+
+func TestRegress(t *testing.T) {
+	// net = 10 - 0.1·lag + 0.5·spread + 小扰动
+	var obs []Observation
+	for i := 0; i < 200; i++ {
+		lag := float64(i % 50 * 4)
+		spread := float64(i%7) + 5
+		noise := float64(i%3-1) * 0.01
+		obs = append(obs, Observation{LagMs: lag, SpreadBps: spread, NetPnLBps: 10 - 0.1*lag + 0.5*spread + noise})
+	}
+	obs = append(obs, Observation{LagMs: math.NaN(), SpreadBps: 1, NetPnLBps: 1000})
+
+	res, ok := Regress(obs, []string{FactorLag, FactorSpread})
+	if !ok {
+		t.Fatal("回归应有效")
+	}
+	if res.N != 200 || res.R2 < 0.999 || len(res.Coefs) != 3 {
+		t.Fatalf("回归结果不符: %+v", res)
+	}
+	want := []float64{10, -0.1, 0.5}
+	for i, c := range res.Coefs {
+		if math.Abs(c.Beta-want[i]) > 0.01 {
+			t.Errorf("%s beta=%v, want %v", c.Name, c.Beta, want[i])
+		}
+	}
+	if res.Coefs[1].TStat > -100 {
+		t.Errorf("lag 系数应高度显著: %+v", res.Coefs[1])
+	}
+
+	// 深度取 log10
+	depth := []Observation{{DepthUSD: 1e4, NetPnLBps: 1}, {DepthUSD: 1e5, NetPnLBps: 2}, {DepthUSD: 1e6, NetPnLBps: 3.1}}
+	res, ok = Regress(depth, []string{FactorDepth})
+	if !ok || res.Coefs[1].Name != "log10_depth_usd" || math.Abs(res.Coefs[1].Beta-1.05) > 1e-9 {
+		t.Errorf("深度回归不符: %+v", res)
+	}
+
+	// 样本不足或自变量无变化时无效
+	if _, ok := Regress(depth[:2], []string{FactorDepth}); ok {
+		t.Error("样本数不足时应无效")
+	}
+	flat := []Observation{{SpreadBps: 1, NetPnLBps: 1}, {SpreadBps: 1, NetPnLBps: 2}, {SpreadBps: 1, NetPnLBps: 3}}
+	if _, ok := Regress(flat, []string{FactorSpread}); ok {
+		t.Error("共线时应无效")
+	}
+}
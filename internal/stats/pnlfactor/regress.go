@@ -0,0 +1,168 @@
+package pnlfactor
+
+import (
+	"math"
+)
+
+// Coef 回归系数
+type Coef struct {
+	// Name 自变量名（截距为 "const"；深度为 log10(depth_usd)）
+	Name string
+	// Beta 系数
+	Beta float64
+	// StdErr 标准误（OLS 同方差假设）
+	StdErr float64
+	// TStat t 统计量
+	TStat float64
+}
+
+// Regression 净利对因子的 OLS 回归结果
+type Regression struct {
+	// N 参与回归的笔数（任一因子缺失的观测跳过）
+	N int
+	// R2 决定系数
+	R2 float64
+	// Coefs 截距与各因子系数
+	Coefs []Coef
+}
+
+// Regress 以 NetPnLBps 为因变量，对给定因子做 OLS 回归（含截距）
+// 深度因子取 log10；小时因子按数值处理无意义，应使用 HourBuckets。
+// 返回: 回归结果与是否有效（样本数不足或设计矩阵奇异时为 false）
+func Regress(obs []Observation, factors []string) (Regression, bool) {
+	k := len(factors) + 1
+	var xs [][]float64
+	var ys []float64
+	for i := range obs {
+		row := make([]float64, k)
+		row[0] = 1
+		ok := true
+		for j, f := range factors {
+			v := obs[i].Value(f)
+			if f == FactorDepth {
+				v = math.Log10(v)
+			}
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				ok = false
+				break
+			}
+			row[j+1] = v
+		}
+		if ok {
+			xs = append(xs, row)
+			ys = append(ys, obs[i].NetPnLBps)
+		}
+	}
+	n := len(ys)
+	if n <= k {
+		return Regression{}, false
+	}
+
+	// 正规方程 X'X·β = X'y
+	xtx := make([][]float64, k)
+	xty := make([]float64, k)
+	for a := 0; a < k; a++ {
+		xtx[a] = make([]float64, k)
+	}
+	for i, row := range xs {
+		for a := 0; a < k; a++ {
+			xty[a] += row[a] * ys[i]
+			for b := 0; b < k; b++ {
+				xtx[a][b] += row[a] * row[b]
+			}
+		}
+	}
+	beta, ok := solve(xtx, xty)
+	if !ok {
+		return Regression{}, false
+	}
+
+	var mean, sse, sst float64
+	for _, y := range ys {
+		mean += y
+	}
+	mean /= float64(n)
+	for i, row := range xs {
+		fit := 0.0
+		for a := 0; a < k; a++ {
+			fit += row[a] * beta[a]
+		}
+		sse += (ys[i] - fit) * (ys[i] - fit)
+		sst += (ys[i] - mean) * (ys[i] - mean)
+	}
+	sigma2 := sse / float64(n-k)
+
+	res := Regression{N: n}
+	if sst > 0 {
+		res.R2 = 1 - sse/sst
+	}
+	names := append([]string{"const"}, factors...)
+	for a := 0; a < k; a++ {
+		// (X'X)^-1 的第 a 个对角元
+		e := make([]float64, k)
+		e[a] = 1
+		col, ok := solve(xtx, e)
+		if !ok {
+			return Regression{}, false
+		}
+		name := names[a]
+		if name == FactorDepth {
+			name = "log10_" + FactorDepth
+		}
+		c := Coef{Name: name, Beta: beta[a], StdErr: math.Sqrt(sigma2 * col[a])}
+		if c.StdErr > 0 {
+			c.TStat = c.Beta / c.StdErr
+		}
+		res.Coefs = append(res.Coefs, c)
+	}
+	return res, true
+}
+
+// solve 部分主元高斯消元求解 a·x = b（不修改入参）
+// 返回: 解与是否有效（矩阵奇异时为 false）
+func solve(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n+1)
+		copy(m[i], a[i])
+		m[i][n] = b[i]
+	}
+	// 各列按原始最大绝对值判断奇异：截距与各因子（如对数深度、毫秒时延）量级不同，不能用全局阈值
+	scale := make([]float64, n)
+	for c := 0; c < n; c++ {
+		for r := 0; r < n; r++ {
+			scale[c] = math.Max(scale[c], math.Abs(a[r][c]))
+		}
+		if scale[c] == 0 {
+			return nil, false
+		}
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(m[r][col]) > math.Abs(m[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(m[pivot][col]) <= 1e-12*scale[col] {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for r := col + 1; r < n; r++ {
+			factor := m[r][col] / m[col][col]
+			for c := col; c <= n; c++ {
+				m[r][c] -= factor * m[col][c]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		s := m[i][n]
+		for j := i + 1; j < n; j++ {
+			s -= m[i][j] * x[j]
+		}
+		x[i] = s / m[i][i]
+	}
+	return x, true
+}
@@ -0,0 +1,57 @@
+// Package procstats 采集进程资源指标（goroutine、堆内存、GC、线程）。
+// 与业务指标写入同一 metrics 流，长时间运行时的内存/goroutine 泄漏可直接在快照中发现。
+package procstats
+
+import (
+	"runtime"
+	"runtime/pprof"
+)
+
+// Stats 进程资源快照
+type Stats struct {
+	// Goroutines 当前 goroutine 数
+	Goroutines int `json:"goroutines"`
+	// HeapInUseBytes 堆上正在使用的 span 字节数
+	HeapInUseBytes uint64 `json:"heap_inuse_bytes"`
+	// HeapAllocBytes 堆上已分配且未释放的对象字节数
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	// HeapObjects 堆对象数
+	HeapObjects uint64 `json:"heap_objects"`
+	// SysBytes 从操作系统获取的内存总量
+	SysBytes uint64 `json:"sys_bytes"`
+	// NumGC 累计 GC 次数
+	NumGC uint32 `json:"num_gc"`
+	// GCPauseTotalMs 累计 GC STW 暂停时长（毫秒）
+	GCPauseTotalMs float64 `json:"gc_pause_total_ms"`
+	// LastGCPauseMs 最近一次 GC 暂停时长（毫秒）
+	LastGCPauseMs float64 `json:"last_gc_pause_ms"`
+	// CgoCalls 累计 CGO 调用次数
+	CgoCalls int64 `json:"cgo_calls"`
+	// ThreadsCreated 累计创建的 OS 线程数
+	ThreadsCreated int `json:"threads_created"`
+}
+
+// Read 采集当前进程资源快照
+// runtime.ReadMemStats 会短暂 STW，应按指标周期调用，不在行情热路径上调用。
+func Read() Stats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	s := Stats{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapInUseBytes: ms.HeapInuse,
+		HeapAllocBytes: ms.HeapAlloc,
+		HeapObjects:    ms.HeapObjects,
+		SysBytes:       ms.Sys,
+		NumGC:          ms.NumGC,
+		GCPauseTotalMs: float64(ms.PauseTotalNs) / 1e6,
+		CgoCalls:       runtime.NumCgoCall(),
+	}
+	if ms.NumGC > 0 {
+		s.LastGCPauseMs = float64(ms.PauseNs[(ms.NumGC+255)%256]) / 1e6
+	}
+	if p := pprof.Lookup("threadcreate"); p != nil {
+		s.ThreadsCreated = p.Count()
+	}
+	return s
+}
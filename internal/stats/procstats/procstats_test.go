@@ -0,0 +1,24 @@
+// Package procstats 进程资源指标测试
+package procstats
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	runtime.GC()
+	s := Read()
+	if s.Goroutines < 1 {
+		t.Fatalf("Goroutines=%d, want >= 1", s.Goroutines)
+	}
+	if s.HeapInUseBytes == 0 || s.SysBytes == 0 {
+		t.Fatalf("内存指标应大于 0: %+v", s)
+	}
+	if s.NumGC < 1 {
+		t.Fatalf("NumGC=%d, want >= 1", s.NumGC)
+	}
+	if s.ThreadsCreated < 1 {
+		t.Fatalf("ThreadsCreated=%d, want >= 1", s.ThreadsCreated)
+	}
+}
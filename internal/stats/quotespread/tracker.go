@@ -0,0 +1,111 @@
+// Package quotespread 按交易对跟踪 Follower（Bittap）报价价差的滚动中位数。
+// 影子成交可用其一半替代全局 slippage_bps，使执行成本假设跟随实际盘口。
+package quotespread
+
+import (
+	"sort"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// DefaultWindowSize 默认每个交易对保留的价差样本数
+const DefaultWindowSize = 500
+
+// Tracker 报价价差跟踪器（单 goroutine 使用，由聚合器独占写入）
+type Tracker struct {
+	// windowSize 每个交易对的滚动窗口大小
+	windowSize int
+	// symbols 按交易对的价差窗口
+	symbols map[string]*window
+}
+
+// window 单个交易对的价差环形缓冲区（基点）
+type window struct {
+	buf []float64
+	pos int
+	// dirty 新样本写入后中位数需重新计算
+	dirty  bool
+	median float64
+	// scratch 排序用缓冲区（复用，避免每次分配）
+	scratch []float64
+}
+
+// New 创建报价价差跟踪器
+// 参数 windowSize: 每个交易对保留的样本数（<=0 时使用 DefaultWindowSize）
+func New(windowSize int) *Tracker {
+	if windowSize <= 0 {
+		windowSize = DefaultWindowSize
+	}
+	return &Tracker{
+		windowSize: windowSize,
+		symbols:    make(map[string]*window),
+	}
+}
+
+// Observe 记录一次 Bittap 报价价差；其它交易所与无效报价忽略
+// 价差 = (ask - bid) / mid × 10000
+func (t *Tracker) Observe(ev *model.BookEvent) {
+	if ev == nil || ev.Exchange != model.ExchangeBittap || ev.SymbolCanon == "" {
+		return
+	}
+	if ev.BestBidPx <= 0 || ev.BestAskPx < ev.BestBidPx {
+		return
+	}
+	mid := (ev.BestBidPx + ev.BestAskPx) / 2
+	bps := (ev.BestAskPx - ev.BestBidPx) / mid * 10000
+
+	w := t.symbols[ev.SymbolCanon]
+	if w == nil {
+		w = &window{buf: make([]float64, 0, t.windowSize)}
+		t.symbols[ev.SymbolCanon] = w
+	}
+	if len(w.buf) < t.windowSize {
+		w.buf = append(w.buf, bps)
+	} else {
+		w.buf[w.pos] = bps
+		w.pos++
+		if w.pos >= t.windowSize {
+			w.pos = 0
+		}
+	}
+	w.dirty = true
+}
+
+// MedianBps 返回交易对报价价差的滚动中位数（基点）
+// 返回: (中位数, 是否有样本)
+func (t *Tracker) MedianBps(symbolCanon string) (float64, bool) {
+	w := t.symbols[symbolCanon]
+	if w == nil || len(w.buf) == 0 {
+		return 0, false
+	}
+	if w.dirty {
+		w.scratch = append(w.scratch[:0], w.buf...)
+		sort.Float64s(w.scratch)
+		n := len(w.scratch)
+		if n%2 == 1 {
+			w.median = w.scratch[n/2]
+		} else {
+			w.median = (w.scratch[n/2-1] + w.scratch[n/2]) / 2
+		}
+		w.dirty = false
+	}
+	return w.median, true
+}
+
+// HalfSpreadBps 返回滚动中位价差的一半（基点），作为单边 Taker 滑点估计
+// 返回: (半价差, 是否有样本)
+func (t *Tracker) HalfSpreadBps(symbolCanon string) (float64, bool) {
+	m, ok := t.MedianBps(symbolCanon)
+	return m / 2, ok
+}
+
+// Medians 返回各交易对的滚动中位价差（基点，指标输出用）
+func (t *Tracker) Medians() map[string]float64 {
+	out := make(map[string]float64, len(t.symbols))
+	for sym := range t.symbols {
+		if m, ok := t.MedianBps(sym); ok {
+			out[sym] = m
+		}
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+// Package quotespread 报价价差跟踪测试
+package quotespread
+
+import (
+	"math"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func bittapBook(sym string, bid, ask float64) *model.BookEvent {
+	return &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: sym, BestBidPx: bid, BestAskPx: ask}
+}
+
+func TestTracker_RollingMedian(t *testing.T) {
+	tr := New(3)
+	if _, ok := tr.MedianBps("BTCUSDT"); ok {
+		t.Fatalf("无样本时不应返回中位数")
+	}
+
+	// 价差约 2、10、4 bps → 中位数约 4bps
+	tr.Observe(bittapBook("BTCUSDT", 99.99, 100.01))
+	tr.Observe(bittapBook("BTCUSDT", 99.95, 100.05))
+	tr.Observe(bittapBook("BTCUSDT", 99.98, 100.02))
+	if m, ok := tr.MedianBps("BTCUSDT"); !ok || math.Abs(m-4) > 1e-6 {
+		t.Fatalf("MedianBps=%v ok=%v, want 4", m, ok)
+	}
+
+	// 窗口滚动剔除 2bps：剩 10、4、20 → 中位数 10bps，半价差 5bps
+	tr.Observe(bittapBook("BTCUSDT", 99.90, 100.10))
+	if h, ok := tr.HalfSpreadBps("BTCUSDT"); !ok || math.Abs(h-5) > 1e-6 {
+		t.Fatalf("HalfSpreadBps=%v ok=%v, want 5", h, ok)
+	}
+}
+
+func TestTracker_IgnoresNonFollowerAndInvalid(t *testing.T) {
+	tr := New(10)
+	tr.Observe(&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 99, BestAskPx: 101})
+	tr.Observe(bittapBook("BTCUSDT", 0, 100))
+	tr.Observe(bittapBook("BTCUSDT", 101, 100))
+	if got := tr.Medians(); len(got) != 0 {
+		t.Fatalf("Medians=%v, want 空", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Package significance 检验影子成交 EV 是否显著大于 0。
+// 对每笔净利（NetPnLBps）样本做单侧 t 检验与 bootstrap 检验（H0: 均值 <= 0），
+// 输出 p 值，避免把 "40 笔 EV = +0.8 bps" 这类小样本结果误读为稳定正收益。
+package significance
+
+import (
+	"math"
+	"math/rand"
+)
+
+// DefaultBootstrapIterations 默认 bootstrap 重抽样次数
+const DefaultBootstrapIterations = 2000
+
+// Result 显著性检验结果
+// 单位：基点。样本不足 2 笔时 p 值为 1（无证据）。
+type Result struct {
+	// N 样本数
+	N int
+	// MeanBps 每笔净利均值
+	MeanBps float64
+	// StdErrBps 均值的标准误
+	StdErrBps float64
+	// TStat t 统计量
+	TStat float64
+	// PValueT 单侧 t 检验 p 值（EV > 0 的显著性，越小越显著）
+	PValueT float64
+	// PValueBootstrap 单侧 bootstrap p 值（按零假设平移样本后重抽样）
+	PValueBootstrap float64
+}
+
+// Test 对净利样本同时做 t 检验与 bootstrap 检验
+// 参数 iterations: bootstrap 重抽样次数（<=0 时使用 DefaultBootstrapIterations）
+// 参数 seed: 随机种子（相同种子与样本得到相同结果）
+func Test(samples []float64, iterations int, seed int64) Result {
+	r := Result{N: len(samples), PValueT: 1, PValueBootstrap: 1}
+	if len(samples) == 0 {
+		return r
+	}
+	mean, se := meanStdErr(samples)
+	r.MeanBps = mean
+	r.StdErrBps = se
+	r.TStat, r.PValueT = TTest(samples)
+	r.PValueBootstrap = Bootstrap(samples, iterations, seed)
+	return r
+}
+
+// TTest 单侧单样本 t 检验（H0: 均值 <= 0，H1: 均值 > 0）
+// 返回: (t 统计量, p 值)；样本 < 2 时 p=1；标准差为 0 时 t 记为 0（避免 JSON 输出 Inf），p 按均值符号取 0 或 1。
+func TTest(samples []float64) (t, p float64) {
+	n := len(samples)
+	if n < 2 {
+		return 0, 1
+	}
+	mean, se := meanStdErr(samples)
+	if se <= 0 {
+		if mean > 0 {
+			return 0, 0
+		}
+		return 0, 1
+	}
+	t = mean / se
+	return t, studentTSurvival(t, float64(n-1))
+}
+
+// Bootstrap 单侧 bootstrap 检验（H0: 均值 <= 0）
+// 将样本平移至均值为 0 后有放回重抽样，p = (1 + #{重抽样均值 >= 观测均值}) / (B + 1)。
+// 样本 < 2 时返回 1。
+func Bootstrap(samples []float64, iterations int, seed int64) float64 {
+	n := len(samples)
+	if n < 2 {
+		return 1
+	}
+	if iterations <= 0 {
+		iterations = DefaultBootstrapIterations
+	}
+	mean, _ := meanStdErr(samples)
+	rng := rand.New(rand.NewSource(seed))
+	hits := 0
+	for b := 0; b < iterations; b++ {
+		var sum float64
+		for i := 0; i < n; i++ {
+			sum += samples[rng.Intn(n)] - mean
+		}
+		if sum/float64(n) >= mean {
+			hits++
+		}
+	}
+	return float64(hits+1) / float64(iterations+1)
+}
+
+// meanStdErr 返回样本均值与均值的标准误（样本 < 2 时标准误为 0）
+func meanStdErr(samples []float64) (mean, se float64) {
+	n := float64(len(samples))
+	for _, x := range samples {
+		mean += x
+	}
+	mean /= n
+	if len(samples) < 2 {
+		return mean, 0
+	}
+	var ss float64
+	for _, x := range samples {
+		d := x - mean
+		ss += d * d
+	}
+	return mean, math.Sqrt(ss / (n - 1) / n)
+}
+
+// studentTSurvival 自由度 df 的 Student t 分布上尾概率 P(T > t)
+// P(T > t) = I_{df/(df+t²)}(df/2, 1/2) / 2（t >= 0）
+func studentTSurvival(t, df float64) float64 {
+	tail := 0.5 * regIncBeta(df/2, 0.5, df/(df+t*t))
+	if t >= 0 {
+		return tail
+	}
+	return 1 - tail
+}
+
+// regIncBeta 正则化不完全 Beta 函数 I_x(a, b)（连分式展开，Lentz 算法）
+func regIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// 连分式在 x < (a+1)/(a+b+2) 时收敛较快，否则利用对称性 I_x(a,b) = 1 - I_{1-x}(b,a)
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaCF(b, a, 1-x)/b
+	}
+	return front * betaCF(a, b, x) / a
+}
+
+// betaCF 不完全 Beta 函数的连分式部分
+func betaCF(a, b, x float64) float64 {
+	const (
+		maxIter = 200
+		eps     = 1e-14
+		tiny    = 1e-300
+	)
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		// 偶数项
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// 奇数项
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,81 @@
+// Package significance 显著性检验测试
+package significance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStudentTSurvival(t *testing.T) {
+	tests := []struct {
+		t, df, want float64
+	}{
+		{0, 5, 0.5},
+		{1, 1, 0.25},           // Cauchy 分布
+		{2, 10, 0.036694},      // t 分布表
+		{-2, 10, 1 - 0.036694}, // 对称性
+		{1.644854, 1e7, 0.05},  // 大自由度趋近正态
+		{3.169273, 10, 0.005},  // 双侧 0.01 临界值
+	}
+	for _, tt := range tests {
+		if got := studentTSurvival(tt.t, tt.df); math.Abs(got-tt.want) > 1e-5 {
+			t.Errorf("studentTSurvival(%v, %v)=%v, want %v", tt.t, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestTTest(t *testing.T) {
+	// 均值 1、样本标准差 1、n=4 → t=2, df=3, p≈0.0697
+	tStat, p := TTest([]float64{-0.2247448713915890, 1, 1, 2.224744871391589})
+	if math.Abs(tStat-2) > 1e-9 {
+		t.Fatalf("t=%v, want 2", tStat)
+	}
+	if math.Abs(p-0.069663) > 1e-5 {
+		t.Fatalf("p=%v, want 0.069663", p)
+	}
+
+	if _, p := TTest([]float64{1}); p != 1 {
+		t.Fatalf("单样本 p=%v, want 1", p)
+	}
+	if _, p := TTest([]float64{2, 2, 2}); p != 0 {
+		t.Fatalf("零方差正均值 p=%v, want 0", p)
+	}
+	if _, p := TTest([]float64{-1, -1}); p != 1 {
+		t.Fatalf("零方差负均值 p=%v, want 1", p)
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	// 显著为正：均值 5、噪声 ±1
+	pos := make([]float64, 100)
+	for i := range pos {
+		pos[i] = 5 + float64(i%3-1)
+	}
+	if p := Bootstrap(pos, 500, 1); p > 0.01 {
+		t.Fatalf("显著为正样本 p=%v, want <= 0.01", p)
+	}
+
+	// 均值为 0 的对称噪声不显著
+	noise := make([]float64, 100)
+	for i := range noise {
+		noise[i] = float64(i%5 - 2)
+	}
+	if p := Bootstrap(noise, 500, 1); p < 0.2 {
+		t.Fatalf("零均值样本 p=%v, want >= 0.2", p)
+	}
+
+	// 相同种子结果确定
+	if Bootstrap(noise, 200, 7) != Bootstrap(noise, 200, 7) {
+		t.Fatal("相同种子结果不一致")
+	}
+}
+
+func TestTest_SmallSample(t *testing.T) {
+	r := Test([]float64{0.8}, 0, 1)
+	if r.N != 1 || r.MeanBps != 0.8 || r.PValueT != 1 || r.PValueBootstrap != 1 {
+		t.Fatalf("单样本结果错误: %+v", r)
+	}
+	if r := Test(nil, 0, 1); r.N != 0 || r.PValueT != 1 {
+		t.Fatalf("空样本结果错误: %+v", r)
+	}
+}
@@ -0,0 +1,79 @@
+// Package spreadsample 按固定间隔对 Leader/Follower 价差做精简采样（spreads.jsonl）。
+// 每条样本只含两侧最优价与多空价差，为价差分布研究提供原始序列，无需录制完整订单簿。
+package spreadsample
+
+import (
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// SchemaVersion spreads.jsonl 记录格式版本（字段含义变更或删除字段时递增）
+const SchemaVersion = 1
+
+// Sample 单条价差样本
+type Sample struct {
+	// SchemaVersion 输出格式版本
+	SchemaVersion int `json:"schema_version"`
+	// TsNs 采样时刻（纳秒，触发采样的事件到达时间）
+	TsNs int64 `json:"ts_ns"`
+	// Leader 链路 Leader（okx/binance）
+	Leader string `json:"leader"`
+	// SymbolCanon 统一交易对
+	SymbolCanon string `json:"symbol"`
+	// LeaderBid/LeaderAsk Leader 最优买卖价
+	LeaderBid float64 `json:"leader_bid"`
+	LeaderAsk float64 `json:"leader_ask"`
+	// FollowerBid/FollowerAsk Follower 最优买卖价
+	FollowerBid float64 `json:"follower_bid"`
+	FollowerAsk float64 `json:"follower_ask"`
+	// LongSpreadBps 多头价差 (Leader_bid - Follower_ask) / Follower_ask × 10000
+	LongSpreadBps float64 `json:"long_spread_bps"`
+	// ShortSpreadBps 空头价差 (Follower_bid - Leader_ask) / Leader_ask × 10000
+	ShortSpreadBps float64 `json:"short_spread_bps"`
+}
+
+// key 采样键（链路 + 交易对）
+type key struct {
+	leader string
+	symbol string
+}
+
+// Sampler 价差采样器（单 goroutine 使用，由聚合器独占）
+// 每条链路、每个交易对在盘口更新时检查距上次采样是否已满间隔，满则输出一条样本。
+type Sampler struct {
+	intervalNs int64
+	last       map[key]int64
+}
+
+// New 创建价差采样器
+// 参数 intervalMs: 同一链路、同一交易对两次采样的最小间隔（毫秒）
+func New(intervalMs int) *Sampler {
+	return &Sampler{
+		intervalNs: int64(intervalMs) * 1_000_000,
+		last:       make(map[key]int64),
+	}
+}
+
+// Sample 盘口更新时尝试采样
+// 返回: (样本, 是否到达采样时刻)；任一侧盘口无效时不采样。
+func (s *Sampler) Sample(nowNs int64, leader string, leaderBook, followerBook *model.BookEvent) (*Sample, bool) {
+	if leaderBook == nil || followerBook == nil || !leaderBook.IsValid() || !followerBook.IsValid() {
+		return nil, false
+	}
+	k := key{leader: leader, symbol: leaderBook.SymbolCanon}
+	if last, ok := s.last[k]; ok && nowNs-last < s.intervalNs {
+		return nil, false
+	}
+	s.last[k] = nowNs
+	return &Sample{
+		SchemaVersion:  SchemaVersion,
+		TsNs:           nowNs,
+		Leader:         leader,
+		SymbolCanon:    leaderBook.SymbolCanon,
+		LeaderBid:      leaderBook.BestBidPx,
+		LeaderAsk:      leaderBook.BestAskPx,
+		FollowerBid:    followerBook.BestBidPx,
+		FollowerAsk:    followerBook.BestAskPx,
+		LongSpreadBps:  (leaderBook.BestBidPx - followerBook.BestAskPx) / followerBook.BestAskPx * 10000,
+		ShortSpreadBps: (followerBook.BestBidPx - leaderBook.BestAskPx) / leaderBook.BestAskPx * 10000,
+	}, true
+}
@@ -0,0 +1,43 @@
+// Package spreadsample 价差采样器测试
+package spreadsample
+
+import (
+	"math"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestSampler_Interval(t *testing.T) {
+	s := New(1000)
+	leader := &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: 100.2, BestAskPx: 100.3}
+	follower := &model.BookEvent{Exchange: model.ExchangeBittap, SymbolCanon: "BTCUSDT", BestBidPx: 99.9, BestAskPx: 100.0}
+
+	sample, ok := s.Sample(1_000_000_000, model.ExchangeOKX, leader, follower)
+	if !ok {
+		t.Fatalf("首次更新应采样")
+	}
+	if math.Abs(sample.LongSpreadBps-20) > 1e-9 {
+		t.Fatalf("LongSpreadBps=%v, want 20", sample.LongSpreadBps)
+	}
+	if want := (99.9 - 100.3) / 100.3 * 10000; math.Abs(sample.ShortSpreadBps-want) > 1e-9 {
+		t.Fatalf("ShortSpreadBps=%v, want %v", sample.ShortSpreadBps, want)
+	}
+
+	// 间隔内不重复采样；其它链路独立计时
+	if _, ok := s.Sample(1_500_000_000, model.ExchangeOKX, leader, follower); ok {
+		t.Fatalf("间隔内不应采样")
+	}
+	if _, ok := s.Sample(1_500_000_000, model.ExchangeBinance, leader, follower); !ok {
+		t.Fatalf("Binance 链路应独立采样")
+	}
+	if _, ok := s.Sample(2_000_000_000, model.ExchangeOKX, leader, follower); !ok {
+		t.Fatalf("满间隔后应采样")
+	}
+
+	// 无效盘口不采样
+	crossed := &model.BookEvent{SymbolCanon: "ETHUSDT", BestBidPx: 10, BestAskPx: 9}
+	if _, ok := s.Sample(3_000_000_000, model.ExchangeOKX, crossed, follower); ok {
+		t.Fatalf("无效盘口不应采样")
+	}
+}
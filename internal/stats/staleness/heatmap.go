@@ -0,0 +1,167 @@
+// Package staleness 按周期统计 Follower 报价陈旧度与 Leader 活跃度的二维分布（staleness.jsonl）。
+// 每次 Leader 更新时记录：Follower 距上次更新的时长（陈旧度）与 Leader 中间价自该次 Follower 更新以来的变动。
+// 陈旧度高而 Leader 变动小的格子说明 Follower 只是行情平静；陈旧度高且 Leader 已大幅变动的格子才是可套利的报价滞后。
+// 长期集中在高陈旧度行的交易对，Bittap 本身更新过慢，不适合作为套利标的。
+package staleness
+
+import (
+	"math"
+	"sort"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// SchemaVersion staleness.jsonl 记录格式版本（字段含义变更或删除字段时递增）
+const SchemaVersion = 1
+
+// StalenessEdgesMs 陈旧度分桶边界（毫秒）
+// 第 i 行覆盖 [edges[i-1], edges[i])，首行从 0 开始，末行为 >= 最后一个边界。
+var StalenessEdgesMs = []int64{50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// MoveEdgesBps Leader 中间价变动分桶边界（基点，取绝对值，分桶规则同上）
+var MoveEdgesBps = []float64{1, 2, 5, 10, 20, 50}
+
+// Heatmap 单条链路、单个交易对一个周期的陈旧度 × Leader 变动分布
+type Heatmap struct {
+	// SchemaVersion 输出格式版本
+	SchemaVersion int `json:"schema_version"`
+	// Leader 链路 Leader（okx/binance）
+	Leader string `json:"leader"`
+	// SymbolCanon 统一交易对
+	SymbolCanon string `json:"symbol"`
+	// IntervalMs 统计周期（毫秒）
+	IntervalMs int `json:"interval_ms"`
+	// StartNs 周期起点（纳秒，按周期对齐）
+	StartNs int64 `json:"start_ns"`
+	// StalenessEdgesMs 行边界（Follower 陈旧度，毫秒）
+	StalenessEdgesMs []int64 `json:"staleness_edges_ms"`
+	// MoveEdgesBps 列边界（Leader 自 Follower 上次更新以来的中间价变动，基点）
+	MoveEdgesBps []float64 `json:"move_edges_bps"`
+	// Counts 各格子的 Leader 更新次数（行: 陈旧度，列: 变动）
+	Counts [][]int64 `json:"counts"`
+	// LeaderUpdates 周期内 Leader 更新次数
+	LeaderUpdates int64 `json:"leader_updates"`
+	// FollowerUpdates 周期内 Follower 更新次数
+	FollowerUpdates int64 `json:"follower_updates"`
+}
+
+// key 统计键（链路 + 交易对）
+type key struct {
+	leader string
+	symbol string
+}
+
+// Builder 陈旧度分布构建器（单 goroutine 使用，由聚合器独占）
+// 新的更新落入下一周期时，上一周期的分布即完成并交给 emit。
+type Builder struct {
+	intervalMs int
+	intervalNs int64
+	emit       func(*Heatmap)
+	open       map[key]*Heatmap
+	// anchorMid 各链路、交易对在 Follower 最近一次更新时的 Leader 中间价
+	anchorMid map[key]float64
+}
+
+// New 创建陈旧度分布构建器
+// 参数 intervalMs: 统计周期（毫秒）
+// 参数 emit: 完成的分布回调
+func New(intervalMs int, emit func(*Heatmap)) *Builder {
+	return &Builder{
+		intervalMs: intervalMs,
+		intervalNs: int64(intervalMs) * 1_000_000,
+		emit:       emit,
+		open:       make(map[key]*Heatmap),
+		anchorMid:  make(map[key]float64),
+	}
+}
+
+// Observe 记录一条链路上的盘口更新
+// 参数 ev: 本次更新（Leader 或 Follower）
+// 参数 leaderBook/followerBook: 该链路两侧的最新盘口（含本次更新）
+func (b *Builder) Observe(leader string, ev, leaderBook, followerBook *model.BookEvent) {
+	if ev == nil || !ev.IsValid() {
+		return
+	}
+	k := key{leader: leader, symbol: ev.SymbolCanon}
+	if ev.Exchange == model.ExchangeBittap {
+		if leaderBook != nil && leaderBook.IsValid() {
+			b.anchorMid[k] = leaderBook.MidPrice()
+		} else {
+			delete(b.anchorMid, k)
+		}
+		b.window(k, ev.ArrivedAtUnixNs).FollowerUpdates++
+		return
+	}
+
+	hm := b.window(k, ev.ArrivedAtUnixNs)
+	hm.LeaderUpdates++
+	if followerBook == nil || !followerBook.IsValid() {
+		return
+	}
+	anchor, ok := b.anchorMid[k]
+	if !ok {
+		// Follower 更新时 Leader 尚无盘口：以首个 Leader 盘口为锚点
+		anchor = ev.MidPrice()
+		b.anchorMid[k] = anchor
+	}
+	staleMs := (ev.ArrivedAtUnixNs - followerBook.ArrivedAtUnixNs) / 1_000_000
+	moveBps := math.Abs(ev.MidPrice()-anchor) / anchor * 10000
+	hm.Counts[stalenessBucket(staleMs)][moveBucket(moveBps)]++
+}
+
+// window 返回 tsNs 所在周期的分布，跨周期时先完成上一周期
+func (b *Builder) window(k key, tsNs int64) *Heatmap {
+	startNs := tsNs - tsNs%b.intervalNs
+	hm := b.open[k]
+	if hm != nil && startNs > hm.StartNs {
+		b.emit(hm)
+		hm = nil
+	}
+	if hm == nil {
+		counts := make([][]int64, len(StalenessEdgesMs)+1)
+		for i := range counts {
+			counts[i] = make([]int64, len(MoveEdgesBps)+1)
+		}
+		hm = &Heatmap{
+			SchemaVersion:    SchemaVersion,
+			Leader:           k.leader,
+			SymbolCanon:      k.symbol,
+			IntervalMs:       b.intervalMs,
+			StartNs:          startNs,
+			StalenessEdgesMs: StalenessEdgesMs,
+			MoveEdgesBps:     MoveEdgesBps,
+			Counts:           counts,
+		}
+		b.open[k] = hm
+	}
+	return hm
+}
+
+// Flush 输出所有未完成的分布（停机或回放结束时调用）
+// 按链路、交易对排序输出，同一份回放数据的结果可复现。
+func (b *Builder) Flush() {
+	keys := make([]key, 0, len(b.open))
+	for k := range b.open {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].leader != keys[j].leader {
+			return keys[i].leader < keys[j].leader
+		}
+		return keys[i].symbol < keys[j].symbol
+	})
+	for _, k := range keys {
+		b.emit(b.open[k])
+		delete(b.open, k)
+	}
+}
+
+// stalenessBucket 陈旧度所在行
+func stalenessBucket(ms int64) int {
+	return sort.Search(len(StalenessEdgesMs), func(i int) bool { return ms < StalenessEdgesMs[i] })
+}
+
+// moveBucket 变动所在列
+func moveBucket(bps float64) int {
+	return sort.Search(len(MoveEdgesBps), func(i int) bool { return bps < MoveEdgesBps[i] })
+}
@@ -0,0 +1,87 @@
+// Package staleness 报价陈旧度分布测试
+package staleness
+
+import (
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func book(ex string, mid float64, arrivedMs int64) *model.BookEvent {
+	return &model.BookEvent{
+		Exchange:        ex,
+		SymbolCanon:     "BTCUSDT",
+		BestBidPx:       mid - 0.01,
+		BestAskPx:       mid + 0.01,
+		ArrivedAtUnixNs: arrivedMs * 1_000_000,
+	}
+}
+
+func TestBuilder_Buckets(t *testing.T) {
+	var out []*Heatmap
+	b := New(60000, func(hm *Heatmap) { out = append(out, hm) })
+
+	leader := book(model.ExchangeOKX, 100, 0)
+	b.Observe(model.ExchangeOKX, leader, leader, nil)
+	follower := book(model.ExchangeBittap, 100, 10)
+	b.Observe(model.ExchangeOKX, follower, leader, follower)
+
+	// Follower 陈旧 30ms、Leader 未变动 → 第 0 行第 0 列
+	l1 := book(model.ExchangeOKX, 100, 40)
+	b.Observe(model.ExchangeOKX, l1, l1, follower)
+	// Follower 陈旧 3s、Leader 变动 30bps → 陈旧度 [2500, 5000) 行、变动 [20, 50) 列
+	l2 := book(model.ExchangeOKX, 100.3, 3010)
+	b.Observe(model.ExchangeOKX, l2, l2, follower)
+
+	// 跨周期完成上一周期
+	l3 := book(model.ExchangeOKX, 100.3, 61000)
+	b.Observe(model.ExchangeOKX, l3, l3, follower)
+	if len(out) != 1 {
+		t.Fatalf("应完成 1 个周期, got %d", len(out))
+	}
+	hm := out[0]
+	if hm.StartNs != 0 || hm.LeaderUpdates != 3 || hm.FollowerUpdates != 1 {
+		t.Errorf("周期统计不符: start=%d leader=%d follower=%d", hm.StartNs, hm.LeaderUpdates, hm.FollowerUpdates)
+	}
+	if hm.Counts[0][0] != 1 {
+		t.Errorf("Counts[0][0]=%d, want 1", hm.Counts[0][0])
+	}
+	if hm.Counts[6][5] != 1 {
+		t.Errorf("Counts[6][5]=%d, want 1", hm.Counts[6][5])
+	}
+	if len(hm.Counts) != len(StalenessEdgesMs)+1 || len(hm.Counts[0]) != len(MoveEdgesBps)+1 {
+		t.Errorf("网格尺寸不符: %dx%d", len(hm.Counts), len(hm.Counts[0]))
+	}
+
+	b.Flush()
+	if len(out) != 2 || out[1].StartNs != 60000*1_000_000 || out[1].LeaderUpdates != 1 {
+		t.Fatalf("Flush 应输出未完成周期, got %d", len(out))
+	}
+}
+
+func TestBuilder_FollowerUpdateResetsAnchor(t *testing.T) {
+	var out []*Heatmap
+	b := New(60000, func(hm *Heatmap) { out = append(out, hm) })
+
+	leader := book(model.ExchangeOKX, 100, 0)
+	b.Observe(model.ExchangeOKX, leader, leader, nil)
+	f1 := book(model.ExchangeBittap, 100, 0)
+	b.Observe(model.ExchangeOKX, f1, leader, f1)
+	moved := book(model.ExchangeOKX, 101, 100)
+	b.Observe(model.ExchangeOKX, moved, moved, f1)
+
+	// Follower 跟上后，以当时的 Leader 中间价为新锚点
+	f2 := book(model.ExchangeBittap, 101, 120)
+	b.Observe(model.ExchangeOKX, f2, moved, f2)
+	same := book(model.ExchangeOKX, 101, 130)
+	b.Observe(model.ExchangeOKX, same, same, f2)
+
+	b.Flush()
+	hm := out[0]
+	if hm.Counts[2][6] != 1 {
+		t.Errorf("100bps 变动应落在末列: %v", hm.Counts[2])
+	}
+	if hm.Counts[0][0] != 1 {
+		t.Errorf("锚点重置后变动应为 0: %v", hm.Counts[0])
+	}
+}
@@ -0,0 +1,222 @@
+// Package vantage 对比部署在不同地域的多个节点观测到的 Follower 时延，
+// 用于选择观测 Bittap 滞后最小的接入位置。
+// 各节点的时延样本（latency_samples.jsonl）按（Leader, 交易对, 时间桶）对齐：
+// 只有全部节点在同一时间桶内都有样本时才参与比较，避免不同节点运行时段不同造成偏差。
+// 时间桶取分钟级，节点间的时钟偏差（NTP 同步后为毫秒级）不影响对齐。
+package vantage
+
+import (
+	"sort"
+)
+
+// AllSymbols 汇总行中表示全部交易对的占位符
+const AllSymbols = "*"
+
+// NodeStats 单个节点在一个时间桶内的时延统计
+type NodeStats struct {
+	// Samples 样本数
+	Samples int `json:"samples"`
+	// P50Ms 时延中位数（毫秒）
+	P50Ms float64 `json:"p50_ms"`
+}
+
+// Bucket 对齐后的时间桶（merged.jsonl 记录）
+type Bucket struct {
+	// TsNs 时间桶起点（纳秒）
+	TsNs int64 `json:"ts_ns"`
+	// BucketMs 时间桶长度（毫秒）
+	BucketMs int64 `json:"bucket_ms"`
+	// Leader 链路 Leader（okx/binance）
+	Leader string `json:"leader"`
+	// Symbol 统一交易对
+	Symbol string `json:"symbol"`
+	// Nodes 各节点统计（键为 node_id）
+	Nodes map[string]NodeStats `json:"nodes"`
+	// BestNode 时延中位数最小的节点
+	BestNode string `json:"best_node"`
+}
+
+// Summary 单个节点在一条链路（或全部交易对）上的对比汇总
+type Summary struct {
+	// Node 节点标识
+	Node string
+	// Leader 链路 Leader
+	Leader string
+	// Symbol 统一交易对（AllSymbols 表示该 Leader 下全部交易对）
+	Symbol string
+	// Buckets 参与比较的对齐时间桶数
+	Buckets int
+	// Samples 对齐时间桶内的样本数
+	Samples int
+	// P50Ms/P90Ms 对齐时间桶内全部样本的时延分位数（毫秒）
+	P50Ms float64
+	P90Ms float64
+	// Best 该节点时延中位数最小的时间桶数
+	Best int
+	// BestShare 最优时间桶占比
+	BestShare float64
+}
+
+// key 对齐键
+type key struct {
+	leader string
+	symbol string
+	tsNs   int64
+}
+
+// Aligner 多节点时延样本对齐器（非并发安全）
+type Aligner struct {
+	bucketNs int64
+	nodes    map[string]bool
+	data     map[key]map[string][]float64
+}
+
+// NewAligner 创建对齐器
+// 参数 bucketMs: 时间桶长度（毫秒）
+func NewAligner(bucketMs int64) *Aligner {
+	if bucketMs <= 0 {
+		bucketMs = 60000
+	}
+	return &Aligner{
+		bucketNs: bucketMs * 1_000_000,
+		nodes:    make(map[string]bool),
+		data:     make(map[key]map[string][]float64),
+	}
+}
+
+// AddNode 登记节点（没有任何样本的节点同样参与对齐，使全部时间桶都不对齐）
+func (a *Aligner) AddNode(node string) {
+	a.nodes[node] = true
+}
+
+// Add 记录一个节点的一条时延样本
+// 参数 tsNs: 样本时间（纳秒）
+// 参数 lagMs: 时延（毫秒）
+func (a *Aligner) Add(node, leader, symbol string, tsNs int64, lagMs float64) {
+	a.nodes[node] = true
+	k := key{leader: leader, symbol: symbol, tsNs: tsNs - tsNs%a.bucketNs}
+	byNode := a.data[k]
+	if byNode == nil {
+		byNode = make(map[string][]float64, len(a.nodes))
+		a.data[k] = byNode
+	}
+	byNode[node] = append(byNode[node], lagMs)
+}
+
+// Nodes 返回已登记的节点（按名称排序）
+func (a *Aligner) Nodes() []string {
+	out := make([]string, 0, len(a.nodes))
+	for n := range a.nodes {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Buckets 返回全部节点均有样本的时间桶（按 Leader、交易对、时间排序）
+// 只有一个节点时没有可比较的时间桶。
+func (a *Aligner) Buckets() []Bucket {
+	nodes := a.Nodes()
+	if len(nodes) < 2 {
+		return nil
+	}
+	var out []Bucket
+	for k, byNode := range a.data {
+		if len(byNode) != len(nodes) {
+			continue
+		}
+		b := Bucket{
+			TsNs:     k.tsNs,
+			BucketMs: a.bucketNs / 1_000_000,
+			Leader:   k.leader,
+			Symbol:   k.symbol,
+			Nodes:    make(map[string]NodeStats, len(nodes)),
+		}
+		best := 0.0
+		for _, n := range nodes {
+			st := NodeStats{Samples: len(byNode[n]), P50Ms: quantile(sorted(byNode[n]), 0.5)}
+			b.Nodes[n] = st
+			// 并列时取名称靠前的节点
+			if b.BestNode == "" || st.P50Ms < best {
+				b.BestNode, best = n, st.P50Ms
+			}
+		}
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Leader != out[j].Leader {
+			return out[i].Leader < out[j].Leader
+		}
+		if out[i].Symbol != out[j].Symbol {
+			return out[i].Symbol < out[j].Symbol
+		}
+		return out[i].TsNs < out[j].TsNs
+	})
+	return out
+}
+
+// Summaries 按（Leader, 交易对, 节点）汇总对齐时间桶，每个 Leader 追加一组全部交易对的汇总
+// 排序: Leader、交易对（AllSymbols 在最后）、节点。
+func (a *Aligner) Summaries() []Summary {
+	type sumKey struct{ leader, symbol, node string }
+	acc := make(map[sumKey]*Summary)
+	lags := make(map[sumKey][]float64)
+	add := func(k sumKey, b Bucket, samples []float64) {
+		s := acc[k]
+		if s == nil {
+			s = &Summary{Node: k.node, Leader: k.leader, Symbol: k.symbol}
+			acc[k] = s
+		}
+		s.Buckets++
+		s.Samples += len(samples)
+		if b.BestNode == k.node {
+			s.Best++
+		}
+		lags[k] = append(lags[k], samples...)
+	}
+	for _, b := range a.Buckets() {
+		byNode := a.data[key{leader: b.Leader, symbol: b.Symbol, tsNs: b.TsNs}]
+		for node := range b.Nodes {
+			add(sumKey{b.Leader, b.Symbol, node}, b, byNode[node])
+			add(sumKey{b.Leader, AllSymbols, node}, b, byNode[node])
+		}
+	}
+
+	out := make([]Summary, 0, len(acc))
+	for k, s := range acc {
+		v := sorted(lags[k])
+		s.P50Ms = quantile(v, 0.5)
+		s.P90Ms = quantile(v, 0.9)
+		s.BestShare = float64(s.Best) / float64(s.Buckets)
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Leader != out[j].Leader {
+			return out[i].Leader < out[j].Leader
+		}
+		if out[i].Symbol != out[j].Symbol {
+			if out[i].Symbol == AllSymbols || out[j].Symbol == AllSymbols {
+				return out[j].Symbol == AllSymbols
+			}
+			return out[i].Symbol < out[j].Symbol
+		}
+		return out[i].Node < out[j].Node
+	})
+	return out
+}
+
+// sorted 返回升序副本
+func sorted(v []float64) []float64 {
+	out := make([]float64, len(v))
+	copy(out, v)
+	sort.Float64s(out)
+	return out
+}
+
+// quantile 升序样本的分位数（最近秩），无样本时为 0
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*q)]
+}
@@ -0,0 +1,65 @@
+// Package vantage 多节点时延对齐测试
+package vantage
+
+import (
+	"testing"
+)
+
+const minNs = int64(60_000_000_000)
+
+func TestAligner_AlignsCommonBuckets(t *testing.T) {
+	a := NewAligner(60000)
+	// 第 0 分钟: 两个节点都有样本，tokyo 更快
+	a.Add("tokyo", "okx", "BTCUSDT", 1_000, 5)
+	a.Add("tokyo", "okx", "BTCUSDT", 2_000, 7)
+	a.Add("tokyo", "okx", "BTCUSDT", 3_000, 9)
+	a.Add("frankfurt", "okx", "BTCUSDT", 5_000, 20)
+	// 第 1 分钟: frankfurt 更快
+	a.Add("tokyo", "okx", "BTCUSDT", minNs+1, 30)
+	a.Add("frankfurt", "okx", "BTCUSDT", minNs+2, 10)
+	// 第 2 分钟: 仅 tokyo 有样本，不参与比较
+	a.Add("tokyo", "okx", "BTCUSDT", 2*minNs, 1)
+	// 另一交易对第 0 分钟 tokyo 更快
+	a.Add("tokyo", "okx", "ETHUSDT", 10, 2)
+	a.Add("frankfurt", "okx", "ETHUSDT", 20, 4)
+
+	buckets := a.Buckets()
+	if len(buckets) != 3 {
+		t.Fatalf("对齐时间桶 %d, want 3", len(buckets))
+	}
+	b := buckets[0]
+	if b.Symbol != "BTCUSDT" || b.TsNs != 0 || b.BestNode != "tokyo" || b.Nodes["tokyo"].Samples != 3 || b.Nodes["tokyo"].P50Ms != 7 {
+		t.Errorf("首个时间桶不符: %+v", b)
+	}
+	if buckets[1].TsNs != minNs || buckets[1].BestNode != "frankfurt" {
+		t.Errorf("第二个时间桶不符: %+v", buckets[1])
+	}
+
+	sums := a.Summaries()
+	// okx × (BTCUSDT, ETHUSDT, *) × 2 节点
+	if len(sums) != 6 {
+		t.Fatalf("汇总行 %d, want 6", len(sums))
+	}
+	if s := sums[1]; s.Symbol != "BTCUSDT" || s.Node != "tokyo" || s.Buckets != 2 || s.Samples != 4 || s.Best != 1 || s.BestShare != 0.5 {
+		t.Errorf("tokyo BTCUSDT 汇总不符: %+v", s)
+	}
+	if s := sums[5]; s.Symbol != AllSymbols || s.Node != "tokyo" || s.Buckets != 3 || s.Best != 2 {
+		t.Errorf("tokyo 全部交易对汇总不符: %+v", s)
+	}
+}
+
+func TestAligner_SingleOrEmptyNode(t *testing.T) {
+	a := NewAligner(60000)
+	a.Add("tokyo", "okx", "BTCUSDT", 1, 5)
+	if got := a.Buckets(); got != nil {
+		t.Errorf("单节点不应有可比较时间桶: %v", got)
+	}
+	// 没有样本的节点使全部时间桶不对齐
+	a.AddNode("frankfurt")
+	if got := a.Buckets(); len(got) != 0 {
+		t.Errorf("缺样本节点时不应对齐: %v", got)
+	}
+	if nodes := a.Nodes(); len(nodes) != 2 || nodes[0] != "frankfurt" {
+		t.Errorf("Nodes=%v", nodes)
+	}
+}
@@ -0,0 +1,104 @@
+// Package volatility 按交易所、交易对跟踪中间价的 realized volatility（1s 采样）。
+// 由聚合器独占写入，信号引擎与影子成交执行器共用同一套估计口径。
+package volatility
+
+import (
+	"math"
+	"time"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+// DefaultWindowMs 默认估计窗口（毫秒）
+const DefaultWindowMs = 60_000
+
+// Tracker realized vol 跟踪器（单 goroutine 使用）
+type Tracker struct {
+	// windowSamples 每个交易对保留的 1s 样本数
+	windowSamples int
+	series        map[key]*series
+}
+
+// key 跟踪键（交易所 + 交易对）
+type key struct {
+	exchange string
+	symbol   string
+}
+
+// series 单个交易对的中间价采样
+type series struct {
+	lastSampleNs int64
+	samples      []float64
+}
+
+// New 创建 realized vol 跟踪器
+// 参数 windowMs: 估计窗口（毫秒，<=0 时使用 DefaultWindowMs）
+func New(windowMs int) *Tracker {
+	if windowMs <= 0 {
+		windowMs = DefaultWindowMs
+	}
+	return &Tracker{
+		windowSamples: windowMs / 1000,
+		series:        make(map[key]*series),
+	}
+}
+
+// Observe 以订单簿事件的到达时间与中间价更新采样（同一交易对每秒最多一个样本）
+func (t *Tracker) Observe(ev *model.BookEvent) {
+	if ev == nil || ev.SymbolCanon == "" || !ev.IsValid() {
+		return
+	}
+	k := key{exchange: ev.Exchange, symbol: ev.SymbolCanon}
+	s := t.series[k]
+	if s == nil {
+		s = &series{samples: make([]float64, 0, t.windowSamples)}
+		t.series[k] = s
+	}
+	if s.lastSampleNs > 0 && ev.ArrivedAtUnixNs-s.lastSampleNs < int64(time.Second) {
+		return
+	}
+	s.lastSampleNs = ev.ArrivedAtUnixNs
+	if len(s.samples) >= t.windowSamples {
+		copy(s.samples, s.samples[1:])
+		s.samples = s.samples[:len(s.samples)-1]
+	}
+	s.samples = append(s.samples, ev.MidPrice())
+}
+
+// RealizedVol 返回交易所、交易对当前窗口的 realized vol
+// 返回: (1s 对数收益标准差, 是否有足够样本)
+func (t *Tracker) RealizedVol(exchange, symbolCanon string) (float64, bool) {
+	s := t.series[key{exchange: exchange, symbol: symbolCanon}]
+	if s == nil || len(s.samples) < 3 {
+		return 0, false
+	}
+	return Realized(s.samples), true
+}
+
+// Realized 计算按时间顺序排列的价格样本的 realized volatility（对数收益的样本标准差）
+// 跳过非正价格；有效收益少于 2 个时返回 0。
+func Realized(samples []float64) float64 {
+	var sum float64
+	count := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] <= 0 || samples[i] <= 0 {
+			continue
+		}
+		sum += math.Log(samples[i] / samples[i-1])
+		count++
+	}
+	if count < 2 {
+		return 0
+	}
+	mean := sum / float64(count)
+
+	var ss float64
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] <= 0 || samples[i] <= 0 {
+			continue
+		}
+		d := math.Log(samples[i]/samples[i-1]) - mean
+		ss += d * d
+	}
+	return math.Sqrt(ss / float64(count-1))
+}
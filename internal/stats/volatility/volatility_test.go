@@ -0,0 +1,48 @@
+// Package volatility realized vol 跟踪器测试
+package volatility
+
+import (
+	"math"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+)
+
+func TestTracker_RealizedVol(t *testing.T) {
+	tr := New(3000)
+	observe := func(tsNs int64, mid float64) {
+		tr.Observe(&model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: "BTCUSDT", BestBidPx: mid - 0.005, BestAskPx: mid + 0.005, ArrivedAtUnixNs: tsNs})
+	}
+	observe(1_000_000_000, 100)
+	observe(1_500_000_000, 150) // 1s 内不重复采样
+	observe(2_000_000_000, 101)
+	if _, ok := tr.RealizedVol(model.ExchangeOKX, "BTCUSDT"); ok {
+		t.Fatalf("样本不足时不应返回估计")
+	}
+	observe(3_000_000_000, 100)
+	observe(4_000_000_000, 102) // 窗口 3 个样本，淘汰最早的 100
+
+	got, ok := tr.RealizedVol(model.ExchangeOKX, "BTCUSDT")
+	want := Realized([]float64{101, 100, 102})
+	if !ok || math.Abs(got-want) > 1e-15 || got <= 0 {
+		t.Fatalf("RealizedVol=(%v, %v), want %v", got, ok, want)
+	}
+	if _, ok := tr.RealizedVol(model.ExchangeBinance, "BTCUSDT"); ok {
+		t.Fatalf("不同交易所应独立统计")
+	}
+}
+
+func TestRealized(t *testing.T) {
+	if got := Realized([]float64{100, 100, 100}); got != 0 {
+		t.Fatalf("价格不变时 vol=%v, want 0", got)
+	}
+	if got := Realized([]float64{100}); got != 0 {
+		t.Fatalf("单个样本 vol=%v, want 0", got)
+	}
+	r1, r2 := math.Log(1.01), math.Log(0.99)
+	mean := (r1 + r2) / 2
+	want := math.Sqrt(((r1-mean)*(r1-mean) + (r2-mean)*(r2-mean)) / 1)
+	if got := Realized([]float64{100, 101, 99.99}); math.Abs(got-want) > 1e-12 {
+		t.Fatalf("vol=%v, want %v", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package testkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// px 价格/数量格式化（与交易所一致的字符串形式）
+func px(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// OKXBooks5 构造 OKX books5 推送帧（单档，数量为 1）
+// 参数 instID: 合约 ID，如 BTC-USDT-SWAP
+// 参数 tsMs: 交易所时间戳（毫秒）
+// 参数 seqID: 序列号
+func OKXBooks5(instID string, bidPx, askPx float64, tsMs, seqID int64) []byte {
+	return []byte(fmt.Sprintf(
+		`{"arg":{"channel":"books5","instId":"%s"},"data":[{"asks":[["%s","1","0","1"]],"bids":[["%s","1","0","1"]],"instId":"%s","ts":"%d","seqId":%d}]}`,
+		instID, px(askPx), px(bidPx), instID, tsMs, seqID))
+}
+
+// BinanceDepth 构造 Binance depthUpdate 推送帧（单档，数量为 1）
+// 参数 symbol: 交易对，如 BTCUSDT
+// 参数 eventMs: 事件时间（毫秒）
+// 参数 updateID: 最终 updateId（U/u 相同，pu 为 updateID-1）
+func BinanceDepth(symbol string, bidPx, askPx float64, eventMs, updateID int64) []byte {
+	return []byte(fmt.Sprintf(
+		`{"e":"depthUpdate","E":%d,"s":"%s","U":%d,"u":%d,"pu":%d,"b":[["%s","1"]],"a":[["%s","1"]]}`,
+		eventMs, symbol, updateID, updateID, updateID-1, px(bidPx), px(askPx)))
+}
+
+// BittapDepth 构造 Bittap f_depth30 推送帧（单档，数量为 1）
+// 参数 symbol: 交易对，如 BTC-USDT-M
+// 参数 updateID: lastUpdateId
+func BittapDepth(symbol string, bidPx, askPx float64, updateID int64) []byte {
+	return []byte(fmt.Sprintf(
+		`{"e":"f_depth30","s":"%s","i":"0.1","lastUpdateId":%d,"bids":[["%s","1"]],"asks":[["%s","1"]]}`,
+		symbol, updateID, px(bidPx), px(askPx)))
+}
+
+// OKXPong OKX 文本心跳应答："ping" -> "pong"
+func OKXPong(msg []byte) []byte {
+	if string(msg) == "ping" {
+		return []byte("pong")
+	}
+	return nil
+}
+
+// BittapPong Bittap 心跳应答：{"method":"PING"} -> {"id":...,"result":"PONG"}
+func BittapPong(msg []byte) []byte {
+	var req struct {
+		ID     string `json:"id"`
+		Method string `json:"method"`
+	}
+	if json.Unmarshal(msg, &req) != nil || req.Method != "PING" {
+		return nil
+	}
+	return []byte(fmt.Sprintf(`{"id":%q,"result":"PONG"}`, req.ID))
+}
@@ -0,0 +1,90 @@
+package testkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/output/jsonl"
+)
+
+// GoldenCase 解析器回归样本（testdata/golden*.jsonl 一行）
+// 字段与 rawcapture.Frame 兼容：raw_<exchange>.jsonl 的录制行补上 name 与 want 即成为一条样本。
+type GoldenCase struct {
+	// Name 样本名称（子测试名）
+	Name string `json:"name"`
+	// TsUnixNs 帧到达时间（纳秒），作为 Parse 的 arrivedAt
+	TsUnixNs int64 `json:"ts_unix_ns"`
+	// Data 原始帧内容
+	Data string `json:"data"`
+	// Want 期望输出的 BookEvent（与 books.jsonl 同一编码；为空表示该帧不产生事件）
+	Want []model.BookEvent `json:"want"`
+	// WantErr 期望解析错误包含的子串（为空表示不应出错）
+	WantErr string `json:"want_err,omitempty"`
+}
+
+// ParseFunc 交易所解析器的 Parse 方法
+type ParseFunc func(data []byte, arrivedAt int64) ([]*model.BookEvent, error)
+
+// LoadGolden 读取回归样本文件
+func LoadGolden(path string) ([]GoldenCase, error) {
+	var cases []GoldenCase
+	err := jsonl.ForEach(path, func(c *GoldenCase) error {
+		if c.Name == "" || c.Data == "" {
+			return fmt.Errorf("%s 第 %d 条样本缺少 name 或 data", path, len(cases)+1)
+		}
+		cases = append(cases, *c)
+		return nil
+	})
+	return cases, err
+}
+
+// RunGolden 按文件顺序将样本逐帧送入同一解析器，断言输出与期望逐字段一致
+// 同一文件内的样本共享解析器状态（用于快照 + 增量等有状态频道）；交易所格式漂移
+// （新增必填字段、类型变化）表现为解析错误或字段不一致，使测试失败。
+func RunGolden(t *testing.T, path string, parse ParseFunc) {
+	t.Helper()
+	cases, err := LoadGolden(path)
+	if err != nil {
+		t.Fatalf("读取回归样本失败: %v", err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("%s 没有样本", path)
+	}
+	for _, c := range cases {
+		got, err := parse([]byte(c.Data), c.TsUnixNs)
+		switch {
+		case c.WantErr != "" && err == nil:
+			t.Errorf("%s: 期望错误包含 %q，实际无错误", c.Name, c.WantErr)
+		case c.WantErr != "" && !strings.Contains(err.Error(), c.WantErr):
+			t.Errorf("%s: 期望错误包含 %q，实际 %v", c.Name, c.WantErr, err)
+		case c.WantErr == "" && err != nil:
+			t.Errorf("%s: 解析失败: %v", c.Name, err)
+		default:
+			if diff := DiffEvents(c.Want, got); diff != "" {
+				t.Errorf("%s: %s", c.Name, diff)
+			}
+		}
+		for _, ev := range got {
+			ev.Release()
+		}
+	}
+}
+
+// DiffEvents 比较期望与实际事件（按 JSON 编码逐条比较，浮点需完全相等）
+// 返回: 首个差异的描述；一致时返回空字符串
+func DiffEvents(want []model.BookEvent, got []*model.BookEvent) string {
+	if len(want) != len(got) {
+		return fmt.Sprintf("事件数 期望 %d，实际 %d", len(want), len(got))
+	}
+	for i := range want {
+		w, _ := json.Marshal(&want[i])
+		g, _ := json.Marshal(got[i])
+		if string(w) != string(g) {
+			return fmt.Sprintf("第 %d 个事件不一致\n期望: %s\n实际: %s", i+1, w, g)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,275 @@
+// Package testkit 提供集成测试用的可编排模拟 WebSocket 服务器。
+// 服务器按连接顺序执行脚本：推送 OKX/Binance/Bittap 原始帧、延迟发送、等待客户端消息、
+// 主动断开连接，用于在不连接真实交易所的情况下测试客户端重连与心跳行为。
+// 另提供解析器回归样本（testdata/golden*.jsonl）的读取与比对，供各交易所解析器测试使用。
+package testkit
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Action 脚本中的一个步骤
+// 执行顺序：先等待 Delay，再按 AwaitRecv → Frame → Drop 的顺序执行已设置的字段。
+type Action struct {
+	// Delay 执行前等待时长
+	Delay time.Duration
+	// AwaitRecv 等待收到包含该子串的客户端消息（如 "subscribe"）
+	AwaitRecv string
+	// Frame 发送的文本帧
+	Frame []byte
+	// Drop 直接关闭底层连接（不发送关闭帧，模拟网络中断）
+	Drop bool
+}
+
+// Send 发送一帧
+func Send(frame []byte) Action {
+	return Action{Frame: frame}
+}
+
+// SendAfter 等待 d 后发送一帧
+func SendAfter(d time.Duration, frame []byte) Action {
+	return Action{Delay: d, Frame: frame}
+}
+
+// Wait 仅等待 d
+func Wait(d time.Duration) Action {
+	return Action{Delay: d}
+}
+
+// Await 等待客户端发送包含 substr 的消息
+func Await(substr string) Action {
+	return Action{AwaitRecv: substr}
+}
+
+// Drop 断开连接
+func Drop() Action {
+	return Action{Drop: true}
+}
+
+// Responder 心跳应答函数：对客户端消息返回应答帧（返回 nil 表示不应答）
+type Responder func(msg []byte) []byte
+
+// Server 模拟 WebSocket 服务器
+// 第 i 个连接执行第 i 个脚本；连接数超过脚本数时沿用最后一个脚本。
+// 脚本执行完毕后连接保持打开，直到客户端断开或服务器关闭。
+type Server struct {
+	srv      *httptest.Server
+	upgrader websocket.Upgrader
+	scripts  [][]Action
+
+	mu        sync.Mutex
+	responder Responder
+	conns     int
+	active    map[*serverConn]struct{}
+	received  [][]byte
+
+	// done 服务器关闭信号
+	done chan struct{}
+}
+
+// serverConn 单个连接（写入串行化：脚本与心跳应答可能并发写）
+type serverConn struct {
+	ws   *websocket.Conn
+	wmu  sync.Mutex
+	recv chan []byte
+}
+
+// NewServer 创建并启动模拟服务器
+// 参数 scripts: 每个连接依次执行的脚本
+func NewServer(scripts ...[]Action) *Server {
+	s := &Server{
+		// 客户端会携带交易所 Origin 头，模拟服务器不做来源校验
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		scripts:  scripts,
+		active:   make(map[*serverConn]struct{}),
+		done:     make(chan struct{}),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL 获取 ws:// 地址
+func (s *Server) URL() string {
+	return "ws" + strings.TrimPrefix(s.srv.URL, "http")
+}
+
+// SetResponder 设置心跳应答函数（如 OKXPong、BittapPong）
+// Binance 使用协议层 ping/pong，服务器默认自动应答，无需设置。
+func (s *Server) SetResponder(r Responder) {
+	s.mu.Lock()
+	s.responder = r
+	s.mu.Unlock()
+}
+
+// Connections 获取累计建立的连接数
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns
+}
+
+// Received 获取全部连接收到的客户端消息（副本）
+func (s *Server) Received() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([][]byte, len(s.received))
+	copy(out, s.received)
+	return out
+}
+
+// Broadcast 立即向所有活动连接发送一帧（脚本之外的临时推送）
+func (s *Server) Broadcast(frame []byte) {
+	for _, c := range s.activeConns() {
+		_ = c.write(frame)
+	}
+}
+
+// DropAll 断开所有活动连接
+func (s *Server) DropAll() {
+	for _, c := range s.activeConns() {
+		_ = c.ws.Close()
+	}
+}
+
+// Close 关闭服务器及所有连接
+func (s *Server) Close() {
+	close(s.done)
+	s.DropAll()
+	s.srv.Close()
+}
+
+func (s *Server) activeConns() []*serverConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*serverConn, 0, len(s.active))
+	for c := range s.active {
+		out = append(out, c)
+	}
+	return out
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &serverConn{ws: ws, recv: make(chan []byte, 256)}
+
+	s.mu.Lock()
+	idx := s.conns
+	s.conns++
+	s.active[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.active, c)
+		s.mu.Unlock()
+		_ = ws.Close()
+	}()
+
+	readDone := make(chan struct{})
+	go s.readLoop(c, readDone)
+
+	var script []Action
+	if n := len(s.scripts); n > 0 {
+		if idx >= n {
+			idx = n - 1
+		}
+		script = s.scripts[idx]
+	}
+	if !s.runScript(c, script, readDone) {
+		return
+	}
+
+	select {
+	case <-readDone:
+	case <-s.done:
+	}
+}
+
+// readLoop 读取客户端消息：记录、心跳应答、转交脚本等待
+func (s *Server) readLoop(c *serverConn, readDone chan struct{}) {
+	defer close(readDone)
+	for {
+		_, msg, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.received = append(s.received, msg)
+		responder := s.responder
+		s.mu.Unlock()
+
+		if responder != nil {
+			if reply := responder(msg); reply != nil {
+				_ = c.write(reply)
+				continue
+			}
+		}
+
+		select {
+		case c.recv <- msg:
+		default:
+		}
+	}
+}
+
+// runScript 执行脚本，返回 false 表示连接已结束
+func (s *Server) runScript(c *serverConn, script []Action, readDone <-chan struct{}) bool {
+	for _, a := range script {
+		if a.Delay > 0 {
+			t := time.NewTimer(a.Delay)
+			select {
+			case <-t.C:
+			case <-readDone:
+				t.Stop()
+				return false
+			case <-s.done:
+				t.Stop()
+				return false
+			}
+		}
+		if a.AwaitRecv != "" && !s.await(c, a.AwaitRecv, readDone) {
+			return false
+		}
+		if a.Frame != nil {
+			if err := c.write(a.Frame); err != nil {
+				return false
+			}
+		}
+		if a.Drop {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) await(c *serverConn, substr string, readDone <-chan struct{}) bool {
+	for {
+		select {
+		case msg := <-c.recv:
+			if bytes.Contains(msg, []byte(substr)) {
+				return true
+			}
+		case <-readDone:
+			return false
+		case <-s.done:
+			return false
+		}
+	}
+}
+
+func (c *serverConn) write(frame []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.ws.WriteMessage(websocket.TextMessage, frame)
+}
@@ -0,0 +1,152 @@
+// Package testkit 模拟 WebSocket 服务器测试（含客户端重连/看门狗集成测试）
+package testkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/exchange/bittap"
+	"latency-arbitrage-validator/internal/exchange/connerr"
+	"latency-arbitrage-validator/internal/exchange/okx"
+	"latency-arbitrage-validator/internal/metadata"
+)
+
+func testSymbolMaps() map[string]*metadata.SymbolMap {
+	return map[string]*metadata.SymbolMap{
+		"BTCUSDT": {Canon: "BTCUSDT", OKXInstId: "BTC-USDT-SWAP", BinanceSym: "BTCUSDT", BittapSym: "BTC-USDT-M", BittapTick: "0.1"},
+	}
+}
+
+func waitEvent(t *testing.T, ch <-chan *model.BookEvent, timeout time.Duration) *model.BookEvent {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(timeout):
+		t.Fatalf("等待 BookEvent 超时")
+		return nil
+	}
+}
+
+func TestServer_ScriptDelayAndResponder(t *testing.T) {
+	frame := BittapDepth("BTC-USDT-M", 100, 100.1, 1)
+	srv := NewServer([]Action{Await("SUBSCRIBE"), SendAfter(100*time.Millisecond, frame), Drop()})
+	defer srv.Close()
+	srv.SetResponder(BittapPong)
+
+	conn, _, err := websocket.DefaultDialer.Dial(srv.URL(), nil)
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"ping-1","method":"PING"}`))
+	_, msg, err := conn.ReadMessage()
+	if err != nil || !bittap.IsPong(msg) {
+		t.Fatalf("应收到 PONG: msg=%s err=%v", msg, err)
+	}
+
+	start := time.Now()
+	_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"method":"SUBSCRIBE","params":["f_depth30@BTC-USDT-M_0.1"]}`))
+	_, msg, err = conn.ReadMessage()
+	if err != nil || string(msg) != string(frame) {
+		t.Fatalf("应收到脚本帧: msg=%s err=%v", msg, err)
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Fatalf("延迟发送过早: %v", d)
+	}
+
+	// 脚本最后一步断开连接
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatalf("连接应已断开")
+	}
+	if n := len(srv.Received()); n != 2 {
+		t.Fatalf("Received=%d, want 2", n)
+	}
+}
+
+func TestServer_OKXClientReconnect(t *testing.T) {
+	srv := NewServer(
+		[]Action{Await("subscribe"), Send(OKXBooks5("BTC-USDT-SWAP", 100, 100.1, 1, 1)), Drop()},
+		[]Action{Await("subscribe"), Send(OKXBooks5("BTC-USDT-SWAP", 101, 101.1, 2, 2))},
+	)
+	defer srv.Close()
+	srv.SetResponder(OKXPong)
+
+	cfg := &config.ExchangeWSConfig{URL: srv.URL(), PingIntervalMs: 25000, PongTimeoutMs: 10000}
+	client := okx.NewClient(cfg, testSymbolMaps(), zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	if err := client.Subscribe(); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	go client.Run(ctx)
+
+	ev := waitEvent(t, client.BookCh(), 2*time.Second)
+	if ev.BestBidPx != 100 || ev.Seq != 1 {
+		t.Fatalf("首个事件错误: bid=%v seq=%d", ev.BestBidPx, ev.Seq)
+	}
+	// 断线后按退避（约 1s）重连并重新订阅
+	ev = waitEvent(t, client.BookCh(), 5*time.Second)
+	if ev.BestBidPx != 101 || ev.Seq != 2 {
+		t.Fatalf("重连后事件错误: bid=%v seq=%d", ev.BestBidPx, ev.Seq)
+	}
+	if m := client.Metrics(); m.ReconnectCount < 1 || m.Errors.Read < 1 {
+		t.Fatalf("ReconnectCount=%d Errors.Read=%d, want >= 1", m.ReconnectCount, m.Errors.Read)
+	}
+	select {
+	case err := <-client.ErrCh():
+		var ce *connerr.Error
+		if !errors.As(err, &ce) || ce.Exchange != model.ExchangeOKX || ce.Category != connerr.CategoryRead {
+			t.Fatalf("错误通道内容错误: %v", err)
+		}
+	default:
+		t.Fatalf("断线后错误通道应收到 read 错误")
+	}
+	if n := srv.Connections(); n != 2 {
+		t.Fatalf("Connections=%d, want 2", n)
+	}
+}
+
+func TestServer_OKXWatchdog(t *testing.T) {
+	// 首个连接推送一帧后静默（连接不断开），看门狗应强制重连
+	srv := NewServer(
+		[]Action{Await("subscribe"), Send(OKXBooks5("BTC-USDT-SWAP", 100, 100.1, 1, 1))},
+		[]Action{Await("subscribe"), Send(OKXBooks5("BTC-USDT-SWAP", 101, 101.1, 2, 2))},
+	)
+	defer srv.Close()
+
+	cfg := &config.ExchangeWSConfig{URL: srv.URL(), PingIntervalMs: 25000, PongTimeoutMs: 10000, StaleTimeoutMs: 200}
+	client := okx.NewClient(cfg, testSymbolMaps(), zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	if err := client.Subscribe(); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	go client.Run(ctx)
+
+	waitEvent(t, client.BookCh(), 2*time.Second)
+	ev := waitEvent(t, client.BookCh(), 5*time.Second)
+	if ev.Seq != 2 {
+		t.Fatalf("看门狗重连后 seq=%d, want 2", ev.Seq)
+	}
+	if m := client.Metrics(); m.WatchdogTrips < 1 {
+		t.Fatalf("WatchdogTrips=%d, want >= 1", m.WatchdogTrips)
+	}
+}
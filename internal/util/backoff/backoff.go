@@ -4,10 +4,16 @@
 package backoff
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"time"
 )
 
+// ErrMaxAttempts 连续重试次数达到上限（WaitNext 返回的错误包装此错误）
+var ErrMaxAttempts = errors.New("backoff: 重试次数已达上限")
+
 // Backoff 指数退避计算器
 // 每次调用 Next() 返回下一次重试的等待时间
 // 等待时间按指数增长，直到达到最大值
@@ -20,6 +26,10 @@ type Backoff struct {
 	jitter float64
 	// attempt 当前重试次数
 	attempt int
+	// maxAttempts 最大连续重试次数（0 表示不限制）
+	maxAttempts int
+	// onRetry 每次重试等待前的回调（可选）
+	onRetry func(attempt int, delay time.Duration)
 }
 
 // New 创建新的退避计算器
@@ -79,3 +89,42 @@ func (b *Backoff) Reset() {
 func (b *Backoff) Attempt() int {
 	return b.attempt
 }
+
+// SetMaxAttempts 设置最大连续重试次数（<=0 表示不限制）
+// 达到上限后 WaitNext 返回 ErrMaxAttempts；Reset 后重新计数。
+func (b *Backoff) SetMaxAttempts(n int) {
+	if n < 0 {
+		n = 0
+	}
+	b.maxAttempts = n
+}
+
+// OnRetry 设置每次重试等待前的回调，用于日志与指标
+// 参数 fn: attempt 为本次重试序号（从 1 开始），delay 为即将等待的时长
+func (b *Backoff) OnRetry(fn func(attempt int, delay time.Duration)) {
+	b.onRetry = fn
+}
+
+// WaitNext 按下一次退避时长等待
+// ctx 取消时立即返回 ctx.Err()，停机不会被未完成的等待拖延；
+// 连续重试次数已达上限时不等待，直接返回包装了 ErrMaxAttempts 的错误。
+func (b *Backoff) WaitNext(ctx context.Context) error {
+	if b.maxAttempts > 0 && b.attempt >= b.maxAttempts {
+		return fmt.Errorf("%w（%d 次）", ErrMaxAttempts, b.attempt)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delay := b.Next()
+	if b.onRetry != nil {
+		b.onRetry(b.attempt, delay)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -2,6 +2,8 @@
 package backoff
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -239,3 +241,44 @@ func TestBackoff_JitterRange_Specific(t *testing.T) {
 		}
 	}
 }
+
+// TestBackoff_WaitNext 测试 WaitNext 的取消、重试上限与回调
+func TestBackoff_WaitNext(t *testing.T) {
+	b := New(time.Millisecond, 2*time.Millisecond, 0)
+	b.SetMaxAttempts(2)
+	var attempts []int
+	b.OnRetry(func(attempt int, delay time.Duration) {
+		attempts = append(attempts, attempt)
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := b.WaitNext(ctx); err != nil {
+			t.Fatalf("第 %d 次等待不应失败: %v", i+1, err)
+		}
+	}
+	if err := b.WaitNext(ctx); !errors.Is(err, ErrMaxAttempts) {
+		t.Fatalf("超过上限应返回 ErrMaxAttempts, got %v", err)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("回调序号不符: %v", attempts)
+	}
+
+	// 重置后重新计数
+	b.Reset()
+	if err := b.WaitNext(ctx); err != nil {
+		t.Fatalf("重置后应可继续重试: %v", err)
+	}
+
+	// 取消的 ctx 立即返回，不等待退避时长
+	slow := New(time.Hour, time.Hour, 0)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := slow.WaitNext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ctx 已取消应返回 context.Canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("ctx 已取消时不应等待")
+	}
+}
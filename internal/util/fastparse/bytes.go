@@ -0,0 +1,194 @@
+package fastparse
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// errLevels 深度档位 JSON 格式错误
+var errLevels = errors.New("fastparse: 深度档位格式错误")
+
+// ParseFloatBytes 从字节切片解析浮点数，不分配字符串
+// 兼容 JSON 字符串形式的数字（两端的双引号会被去掉），如 "12345.67" 或 12345.67
+// 参数 b: 待解析的字节
+// 返回: 解析后的浮点数和可能的错误
+func ParseFloatBytes(b []byte) (float64, error) {
+	// string(b) 仅作为不逃逸的临时参数传入，短数字由编译器在栈上转换，无堆分配
+	return strconv.ParseFloat(string(unquote(b)), 64)
+}
+
+// ParseIntBytes 从字节切片解析整数，不分配字符串
+// 兼容 JSON 字符串形式的数字（两端的双引号会被去掉）
+// 参数 b: 待解析的字节
+// 返回: 解析后的整数和可能的错误
+func ParseIntBytes(b []byte) (int64, error) {
+	return strconv.ParseInt(string(unquote(b)), 10, 64)
+}
+
+// unquote 去掉两端的双引号（不处理转义，数字字段不含转义字符）
+func unquote(b []byte) []byte {
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		return b[1 : len(b)-1]
+	}
+	return b
+}
+
+// Levels 深度档位数组的原始 JSON：[[价格, 数量, ...], ...]
+// 解码时整体保存字节，由 ForEach 直接在字节上遍历，避免 [][]string 为每档每个字段分配字符串。
+type Levels []byte
+
+// LevelsOf 由字符串档位构造 Levels（REST 回退与测试用，非热路径）
+func LevelsOf(rows [][]string) Levels {
+	if rows == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('[')
+		for j, v := range row {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.Quote(v))
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// UnmarshalJSON 保存原始字节（复制，data 在调用返回后可能被复用）
+func (l *Levels) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	*l = append((*l)[:0], data...)
+	return nil
+}
+
+// MarshalJSON 原样输出
+func (l Levels) MarshalJSON() ([]byte, error) {
+	if len(l) == 0 {
+		return []byte("null"), nil
+	}
+	return l, nil
+}
+
+// ForEach 依次回调每档的价格与数量字节（已去掉引号，仅在回调期间有效）
+// 少于 2 个字段的档位被跳过；fn 返回 false 时停止遍历。
+func (l Levels) ForEach(fn func(px, qty []byte) bool) error {
+	b := skipSpace(l)
+	if len(b) == 0 || string(b) == "null" {
+		return nil
+	}
+	if b[0] != '[' {
+		return errLevels
+	}
+	b = skipSpace(b[1:])
+	if len(b) > 0 && b[0] == ']' {
+		return nil
+	}
+	for {
+		if len(b) == 0 || b[0] != '[' {
+			return errLevels
+		}
+		b = b[1:]
+		var fields [2][]byte
+		n := 0
+		for {
+			var v []byte
+			var ok bool
+			v, b, ok = scalar(skipSpace(b))
+			if !ok {
+				return errLevels
+			}
+			if n < len(fields) {
+				fields[n] = v
+			}
+			n++
+			b = skipSpace(b)
+			if len(b) == 0 {
+				return errLevels
+			}
+			if b[0] == ',' {
+				b = b[1:]
+				continue
+			}
+			if b[0] != ']' {
+				return errLevels
+			}
+			b = b[1:]
+			break
+		}
+		if n >= 2 && !fn(fields[0], fields[1]) {
+			return nil
+		}
+		b = skipSpace(b)
+		if len(b) == 0 {
+			return errLevels
+		}
+		switch b[0] {
+		case ',':
+			b = skipSpace(b[1:])
+		case ']':
+			return nil
+		default:
+			return errLevels
+		}
+	}
+}
+
+// Top 依次回调前 n 档的价格与数量（解析失败的字段记为 0）
+func (l Levels) Top(n int, fn func(px, qty float64)) error {
+	i := 0
+	return l.ForEach(func(pxb, qtyb []byte) bool {
+		if i >= n {
+			return false
+		}
+		px, _ := ParseFloatBytes(pxb)
+		qty, _ := ParseFloatBytes(qtyb)
+		fn(px, qty)
+		i++
+		return i < n
+	})
+}
+
+// scalar 读取一个字符串或数字字段
+// 返回: (字段内容（字符串去掉引号）, 剩余字节, 是否成功)
+func scalar(b []byte) ([]byte, []byte, bool) {
+	if len(b) == 0 {
+		return nil, nil, false
+	}
+	if b[0] == '"' {
+		end := bytes.IndexByte(b[1:], '"')
+		if end < 0 {
+			return nil, nil, false
+		}
+		return b[1 : 1+end], b[2+end:], true
+	}
+	i := 0
+	for i < len(b) && b[i] != ',' && b[i] != ']' && !isSpace(b[i]) {
+		i++
+	}
+	if i == 0 {
+		return nil, nil, false
+	}
+	return b[:i], b[i:], true
+}
+
+func skipSpace(b []byte) []byte {
+	for len(b) > 0 && isSpace(b[0]) {
+		b = b[1:]
+	}
+	return b
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
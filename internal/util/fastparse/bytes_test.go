@@ -0,0 +1,85 @@
+// Package fastparse 字节解析测试
+package fastparse
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	if v, err := ParseFloatBytes([]byte(`"12345.67"`)); err != nil || v != 12345.67 {
+		t.Fatalf("ParseFloatBytes(quoted)=%v, %v", v, err)
+	}
+	if v, err := ParseFloatBytes([]byte(`0.5`)); err != nil || v != 0.5 {
+		t.Fatalf("ParseFloatBytes(bare)=%v, %v", v, err)
+	}
+	if _, err := ParseFloatBytes([]byte(`"abc"`)); err == nil {
+		t.Fatal("ParseFloatBytes 应拒绝非数字")
+	}
+	if v, err := ParseIntBytes([]byte(`"1700000000000"`)); err != nil || v != 1700000000000 {
+		t.Fatalf("ParseIntBytes=%v, %v", v, err)
+	}
+}
+
+func TestLevels_ForEach(t *testing.T) {
+	var msg struct {
+		Bids Levels `json:"bids"`
+		Asks Levels `json:"asks"`
+		None Levels `json:"none"`
+	}
+	data := []byte(`{"bids": [ ["100.5", "1.2", "0", "4"], ["100.4","3"] ,[7]], "asks": [[101, 2]], "none": null}`)
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	type level struct{ px, qty string }
+	collect := func(l Levels) ([]level, error) {
+		var out []level
+		err := l.ForEach(func(px, qty []byte) bool {
+			out = append(out, level{string(px), string(qty)})
+			return true
+		})
+		return out, err
+	}
+	bids, err := collect(msg.Bids)
+	if err != nil || len(bids) != 2 || bids[0] != (level{"100.5", "1.2"}) || bids[1] != (level{"100.4", "3"}) {
+		t.Fatalf("bids=%v, err=%v（不足 2 个字段的档位应跳过）", bids, err)
+	}
+	asks, err := collect(msg.Asks)
+	if err != nil || len(asks) != 1 || asks[0] != (level{"101", "2"}) {
+		t.Fatalf("asks=%v, err=%v", asks, err)
+	}
+	if none, err := collect(msg.None); err != nil || none != nil {
+		t.Fatalf("null=%v, err=%v", none, err)
+	}
+
+	n := 0
+	if err := msg.Bids.Top(1, func(px, qty float64) { n++ }); err != nil || n != 1 {
+		t.Fatalf("Top(1) 回调 %d 次, err=%v", n, err)
+	}
+	for _, bad := range []string{`{}`, `[["1","2"]`, `[["1" "2"]]`, `[["1","2"]x]`} {
+		if _, err := collect(Levels(bad)); err == nil {
+			t.Fatalf("ForEach(%s) 应返回格式错误", bad)
+		}
+	}
+}
+
+func TestLevelsOf_RoundTrip(t *testing.T) {
+	b, err := json.Marshal(struct {
+		Bids Levels `json:"bids"`
+	}{LevelsOf([][]string{{"1.5", "2"}})})
+	if err != nil || string(b) != `{"bids":[["1.5","2"]]}` {
+		t.Fatalf("Marshal=%s, err=%v", b, err)
+	}
+}
+
+func TestLevels_NoAllocs(t *testing.T) {
+	l := Levels(`[["100.5","1.2"],["100.4","3"],["100.3","4"],["100.2","5"],["100.1","6"]]`)
+	var sum float64
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = l.Top(5, func(px, qty float64) { sum += px * qty })
+	})
+	if allocs != 0 {
+		t.Fatalf("Top allocs=%v, want 0", allocs)
+	}
+}
@@ -0,0 +1,68 @@
+// Package logsample 实现热路径日志采样。
+// WS 读循环中的解析错误、通道满丢弃等告警可能每秒发生成千上万次，
+// 逐条记录会拖慢读循环并刷爆磁盘；采样器只放行第 1、N+1、2N+1... 次，
+// 并可限制两次放行之间的最小间隔。可并发调用，无锁。
+package logsample
+
+import (
+	"sync/atomic"
+	"time"
+
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// Sampler 日志采样器
+type Sampler struct {
+	// every 每 every 次事件放行 1 次（<=1 表示每次都满足计数条件）
+	every uint64
+	// minIntervalNs 两次放行之间的最小间隔（纳秒，0 表示不限制）
+	minIntervalNs int64
+	// nowFn 时间源（测试可替换）
+	nowFn func() int64
+
+	count atomic.Uint64
+	// lastNs 上次放行时间
+	lastNs atomic.Int64
+	// lastCount 上次放行时的事件计数（用于计算被抑制次数）
+	lastCount atomic.Uint64
+}
+
+// New 创建日志采样器
+// 参数 every: 每 every 次事件放行 1 次
+// 参数 minInterval: 两次放行之间的最小间隔（0 表示不限制）
+func New(every int, minInterval time.Duration) *Sampler {
+	if every < 1 {
+		every = 1
+	}
+	return &Sampler{every: uint64(every), minIntervalNs: int64(minInterval), nowFn: timeutil.NowNano}
+}
+
+// Allow 记录一次事件并判断是否应输出日志
+// 返回: ok 是否放行；suppressed 自上次放行以来被抑制的事件数（ok 为 true 时有效）
+func (s *Sampler) Allow() (ok bool, suppressed uint64) {
+	n := s.count.Add(1)
+	if (n-1)%s.every != 0 {
+		return false, 0
+	}
+	if s.minIntervalNs > 0 {
+		nowNs := s.nowFn()
+		last := s.lastNs.Load()
+		if last > 0 && nowNs-last < s.minIntervalNs {
+			return false, 0
+		}
+		// 并发放行时只允许一个调用方成功
+		if !s.lastNs.CompareAndSwap(last, nowNs) {
+			return false, 0
+		}
+	}
+	prev := s.lastCount.Swap(n)
+	if prev > 0 {
+		suppressed = n - prev - 1
+	}
+	return true, suppressed
+}
+
+// Count 获取累计事件数
+func (s *Sampler) Count() uint64 {
+	return s.count.Load()
+}
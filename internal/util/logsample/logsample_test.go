@@ -0,0 +1,48 @@
+// Package logsample 日志采样器测试
+package logsample
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampler_Every(t *testing.T) {
+	s := New(3, 0)
+	var allowed []int
+	var suppressed []uint64
+	for i := 1; i <= 7; i++ {
+		if ok, n := s.Allow(); ok {
+			allowed = append(allowed, i)
+			suppressed = append(suppressed, n)
+		}
+	}
+	// 放行第 1、4、7 次
+	if len(allowed) != 3 || allowed[0] != 1 || allowed[1] != 4 || allowed[2] != 7 {
+		t.Fatalf("allowed=%v, want [1 4 7]", allowed)
+	}
+	if suppressed[0] != 0 || suppressed[1] != 2 || suppressed[2] != 2 {
+		t.Fatalf("suppressed=%v, want [0 2 2]", suppressed)
+	}
+	if s.Count() != 7 {
+		t.Fatalf("Count=%d, want 7", s.Count())
+	}
+}
+
+func TestSampler_MinInterval(t *testing.T) {
+	now := int64(1)
+	s := New(1, time.Second)
+	s.nowFn = func() int64 { return now }
+
+	if ok, _ := s.Allow(); !ok {
+		t.Fatalf("首次应放行")
+	}
+	now += int64(500 * time.Millisecond)
+	if ok, _ := s.Allow(); ok {
+		t.Fatalf("间隔不足不应放行")
+	}
+	now += int64(600 * time.Millisecond)
+	ok, suppressed := s.Allow()
+	if !ok || suppressed != 1 {
+		t.Fatalf("ok=%v suppressed=%d, want true/1", ok, suppressed)
+	}
+}
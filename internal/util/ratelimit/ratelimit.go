@@ -0,0 +1,80 @@
+// Package ratelimit 实现令牌桶限速。
+// 用于限制单个 WS 连接的出站消息频率（订阅/退订/心跳），
+// 避免重连后批量订阅触发交易所的消息频率限制甚至封禁 IP。
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// Limiter 令牌桶限速器（可并发调用）
+type Limiter struct {
+	// rate 每秒补充的令牌数（<=0 表示不限速）
+	rate float64
+	// burst 桶容量
+	burst float64
+	// nowFn 时间源（测试可替换）
+	nowFn func() int64
+
+	mu sync.Mutex
+	// tokens 当前令牌数（预约后可为负，表示已排队的等待）
+	tokens float64
+	// lastNs 上次补充令牌的时间
+	lastNs int64
+}
+
+// New 创建限速器（初始为满桶）
+// 参数 rate: 每秒允许的消息数（<=0 表示不限速）
+// 参数 burst: 允许的突发消息数（<1 时按 1 处理）
+func New(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: rate, burst: float64(burst), tokens: float64(burst), nowFn: timeutil.NowNano}
+}
+
+// Reserve 预约一个令牌
+// 返回: 调用方需等待的时长（0 表示可立即发送）
+func (l *Limiter) Reserve() time.Duration {
+	if l == nil || l.rate <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	nowNs := l.nowFn()
+	if l.lastNs > 0 {
+		l.tokens += float64(nowNs-l.lastNs) / 1e9 * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.lastNs = nowNs
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * 1e9)
+}
+
+// Wait 预约一个令牌并等待至可发送
+// 返回: ctx 在等待期间取消时返回 ctx.Err()（已预约的令牌不退还）
+func (l *Limiter) Wait(ctx context.Context) error {
+	delay := l.Reserve()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+// Package ratelimit 令牌桶限速测试
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Reserve(t *testing.T) {
+	var nowNs int64 = 1
+	l := New(5, 2)
+	l.nowFn = func() int64 { return nowNs }
+
+	// 满桶允许 2 条突发，之后每条间隔 200ms
+	if d := l.Reserve(); d != 0 {
+		t.Fatalf("第 1 条 delay=%v, want 0", d)
+	}
+	if d := l.Reserve(); d != 0 {
+		t.Fatalf("第 2 条 delay=%v, want 0", d)
+	}
+	if d := l.Reserve(); d != 200*time.Millisecond {
+		t.Fatalf("第 3 条 delay=%v, want 200ms", d)
+	}
+	if d := l.Reserve(); d != 400*time.Millisecond {
+		t.Fatalf("第 4 条 delay=%v, want 400ms", d)
+	}
+
+	// 1 秒后补充 5 个令牌：抵消 2 个欠账后剩 3 个，但不超过桶容量 2
+	nowNs += int64(time.Second)
+	if d := l.Reserve(); d != 0 {
+		t.Fatalf("补充后 delay=%v, want 0", d)
+	}
+	if d := l.Reserve(); d != 0 {
+		t.Fatalf("补充后第 2 条 delay=%v, want 0", d)
+	}
+	if d := l.Reserve(); d != 200*time.Millisecond {
+		t.Fatalf("桶容量上限错误 delay=%v, want 200ms", d)
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	var l *Limiter
+	if d := l.Reserve(); d != 0 {
+		t.Fatalf("nil 限速器 delay=%v", d)
+	}
+	if d := New(0, 1).Reserve(); d != 0 {
+		t.Fatalf("rate=0 不应限速 delay=%v", d)
+	}
+}
+
+func TestLimiter_WaitCanceled(t *testing.T) {
+	l := New(1, 1)
+	l.Reserve()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Fatalf("ctx 已取消应返回错误")
+	}
+}
@@ -0,0 +1,56 @@
+package timeutil
+
+import (
+	"sync/atomic"
+)
+
+// Clock 时钟接口
+// 实盘使用系统时钟；回放使用虚拟时钟，使依赖“当前时间”的逻辑（指标周期、尖峰检测等）
+// 以录制数据的时间推进，结果可确定性复现。
+type Clock interface {
+	// NowNano 当前 Unix 纳秒时间戳
+	NowNano() int64
+}
+
+type systemClock struct{}
+
+func (systemClock) NowNano() int64 {
+	return NowNano()
+}
+
+// SystemClock 系统时钟（单调时钟 + 启动时 Unix 时间，同 NowNano）
+var SystemClock Clock = systemClock{}
+
+// VirtualClock 虚拟时钟（并发安全）
+// 由回放驱动推进，只进不退。
+type VirtualClock struct {
+	nowNs int64
+}
+
+// NewVirtualClock 创建虚拟时钟
+// 参数 startNs: 初始时间（纳秒）
+func NewVirtualClock(startNs int64) *VirtualClock {
+	return &VirtualClock{nowNs: startNs}
+}
+
+// NowNano 当前虚拟时间（纳秒）
+func (c *VirtualClock) NowNano() int64 {
+	return atomic.LoadInt64(&c.nowNs)
+}
+
+// Set 将虚拟时间推进到 ns（早于当前时间时忽略，保证单调）
+func (c *VirtualClock) Set(ns int64) {
+	for {
+		cur := atomic.LoadInt64(&c.nowNs)
+		if ns <= cur || atomic.CompareAndSwapInt64(&c.nowNs, cur, ns) {
+			return
+		}
+	}
+}
+
+// Advance 将虚拟时间前进 d 纳秒
+func (c *VirtualClock) Advance(d int64) {
+	if d > 0 {
+		atomic.AddInt64(&c.nowNs, d)
+	}
+}
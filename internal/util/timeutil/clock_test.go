@@ -0,0 +1,111 @@
+// Package timeutil 虚拟时钟测试
+package timeutil
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestVirtualClock_SetAdvance(t *testing.T) {
+	type op struct {
+		set     int64 // >0 时调用 Set
+		advance int64 // 否则调用 Advance
+		want    int64
+	}
+	cases := []struct {
+		name  string
+		start int64
+		ops   []op
+	}{
+		{
+			name:  "Set 只进不退",
+			start: 100,
+			ops: []op{
+				{set: 200, want: 200},
+				{set: 150, want: 200}, // 早于当前时间：忽略
+				{set: 200, want: 200}, // 等于当前时间：不变
+				{set: 300, want: 300},
+			},
+		},
+		{
+			name:  "Advance 前进且忽略非正值",
+			start: 0,
+			ops: []op{
+				{advance: 10, want: 10},
+				{advance: 0, want: 10},
+				{advance: -5, want: 10},
+				{advance: 5, want: 15},
+			},
+		},
+		{
+			name:  "Set 与 Advance 交替",
+			start: 1_000,
+			ops: []op{
+				{advance: 500, want: 1_500},
+				{set: 1_200, want: 1_500},
+				{set: 2_000, want: 2_000},
+				{advance: 1, want: 2_001},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewVirtualClock(tc.start)
+			if got := c.NowNano(); got != tc.start {
+				t.Fatalf("初始时间=%d, want %d", got, tc.start)
+			}
+			for i, o := range tc.ops {
+				if o.set > 0 {
+					c.Set(o.set)
+				} else {
+					c.Advance(o.advance)
+				}
+				if got := c.NowNano(); got != o.want {
+					t.Fatalf("第 %d 步后时间=%d, want %d", i, got, o.want)
+				}
+			}
+		})
+	}
+}
+
+func TestVirtualClock_ConcurrentSetMonotonic(t *testing.T) {
+	const (
+		writers = 8
+		steps   = 1000
+	)
+	c := NewVirtualClock(0)
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// 各 goroutine 以交错顺序写入，较小值可能晚于较大值到达
+			for i := 0; i < steps; i++ {
+				c.Set(int64(i*writers + w))
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	var prev int64
+	for {
+		now := c.NowNano()
+		if now < prev {
+			t.Fatalf("时间回退: %d -> %d", prev, now)
+		}
+		prev = now
+		select {
+		case <-done:
+			if got, want := c.NowNano(), int64((steps-1)*writers+writers-1); got != want {
+				t.Fatalf("最终时间=%d, want %d", got, want)
+			}
+			return
+		default:
+		}
+	}
+}
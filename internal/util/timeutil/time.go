@@ -85,3 +85,15 @@ func SinceNano(startNs int64) time.Duration {
 func SinceMs(startMs int64) int64 {
 	return NowMs() - startMs
 }
+
+// HourOfDayUTC 获取纳秒时间戳对应的 UTC 小时
+// 参数 ns: 纳秒时间戳
+// 返回: 小时 [0, 23]
+func HourOfDayUTC(ns int64) int {
+	const hourNs = int64(3600) * 1_000_000_000
+	h := (ns / hourNs) % 24
+	if h < 0 {
+		h += 24
+	}
+	return int(h)
+}
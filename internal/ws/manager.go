@@ -0,0 +1,1023 @@
+// Package ws 实现三家交易所行情客户端共用的 WebSocket 连接管理。
+// Manager 负责建连、订阅、读循环、心跳、看门狗、断线退避重连与连接指标；
+// 交易所差异（订阅请求、心跳帧、pong 识别、消息解析）通过 Spec 中的钩子注入。
+//
+// 重要：仅用于公共行情 WS，不做任何登录/签名/私有频道。
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/exchange/backpressure"
+	"latency-arbitrage-validator/internal/exchange/connerr"
+	"latency-arbitrage-validator/internal/output/rawcapture"
+	"latency-arbitrage-validator/internal/util/backoff"
+	"latency-arbitrage-validator/internal/util/logsample"
+	"latency-arbitrage-validator/internal/util/ratelimit"
+	"latency-arbitrage-validator/internal/util/timeutil"
+)
+
+// errPongTimeout 心跳响应超时（上报到错误通道）
+var errPongTimeout = errors.New("心跳响应超时")
+
+// Spec 交易所差异部分
+type Spec struct {
+	// Name 日志与错误信息中的交易所显示名（如 "OKX"）
+	Name string
+	// Exchange 交易所标识（model.Exchange*）
+	Exchange string
+	// Origin 握手请求的 Origin 头
+	Origin string
+
+	// ReadTimeoutMs 读超时（毫秒，每收到消息或 pong 后顺延；0 表示不设置）
+	ReadTimeoutMs int
+	// PingIntervalMs 心跳间隔（毫秒，<=0 表示不主动发送心跳）
+	PingIntervalMs int
+	// PongTimeoutMs 发出心跳后等待 pong 的超时（毫秒，0 表示不检查）
+	PongTimeoutMs int
+
+	// PingFrame 构造第 seq 个心跳帧（seq 从 1 开始）
+	// messageType 为 websocket.PingMessage 时以协议层 ping 控制帧发送，pong 由控制帧处理器识别。
+	PingFrame func(seq uint64) (messageType int, data []byte, err error)
+	// IsPong 识别应用层 pong 文本消息（nil 表示仅使用协议层 pong）
+	IsPong func(data []byte) bool
+	// Symbols 返回需订阅的统一交易对（按固定顺序）
+	Symbols func() []string
+	// SubscribeFrame 构建一个订阅请求帧（交易对按 subscribe_chunk_size 拆分后逐帧构建）
+	SubscribeFrame func(id int64, symbols []string) ([]byte, error)
+	// ParseAck 识别订阅确认/拒绝消息（nil 表示不跟踪确认，发送即视为订阅成功）
+	ParseAck func(data []byte) (Ack, bool)
+	// OnReconnect 重连成功、重新订阅之前调用（可选，如重置本地订单簿）
+	OnReconnect func()
+	// Handle 解析一条行情消息（已排除 pong 与订阅确认），返回的事件由 Manager 投递
+	// 返回 nil 事件与 nil 错误表示忽略该消息。
+	Handle func(ctx context.Context, data []byte, nowNs int64) ([]*model.BookEvent, error)
+	// PollBook 通过 REST 深度接口拉取单个交易对的订单簿（nil 表示不支持 REST 轮询降级）
+	// 返回事件的 ArrivedAtUnixNs 为响应读出时间；Manager 负责标记 Degraded。
+	PollBook func(ctx context.Context, canon string) (*model.BookEvent, error)
+}
+
+// 通道容量默认值（ws.<exchange>.book_ch_size / err_ch_size 未设置时）
+const (
+	defaultBookChSize = 1000
+	defaultErrChSize  = 10
+)
+
+// Manager WebSocket 连接管理器
+type Manager struct {
+	// cfg WebSocket 配置
+	cfg *config.ExchangeWSConfig
+	// spec 交易所差异钩子
+	spec Spec
+	// logger 日志记录器
+	logger *zap.Logger
+
+	// conn WebSocket 连接
+	conn *websocket.Conn
+	// connMu 连接锁（gorilla/websocket 不允许并发多写者，写入同样由它串行化）
+	connMu sync.Mutex
+	// netDial TCP 建连（本地地址绑定与 socket 参数）
+	netDial dialFunc
+	// endpoint 接入点 IP 缓存（pre_resolve_dns 或 pin_ip 启用时非 nil，按 IP 建连）
+	endpoint *endpoint
+	// endpointResolved 是否已完成启动时的域名预解析（受 connMu 保护）
+	endpointResolved bool
+
+	// bookCh 订单簿事件输出通道
+	bookCh chan *model.BookEvent
+	// sender 按背压策略向 bookCh 投递
+	sender *backpressure.Sender
+	// dropLog 丢弃事件告警采样
+	dropLog *logsample.Sampler
+	// raw 原始帧采样录制（可选，nil 表示关闭）
+	raw *rawcapture.Recorder
+	// errCh 错误输出通道
+	errCh chan error
+	// bookHighWater/errHighWater 两个输出通道观测到的最大占用（条数，累计）
+	bookHighWater atomic.Int64
+	errHighWater  atomic.Int64
+	// subs 当前连接上各交易对的订阅状态
+	subs subTracker
+	// nextReqID 订阅请求 ID（连接间单调递增，避免旧确认被误认）
+	nextReqID int64
+	// limiter 出站消息令牌桶（订阅、退订与心跳共用）
+	limiter *ratelimit.Limiter
+
+	// metrics 连接指标
+	metrics Metrics
+	// metricsMu 指标锁
+	metricsMu sync.RWMutex
+
+	// lastMsgTime 最后消息时间（纳秒）
+	lastMsgTime int64
+	// lastPingSentNs 上次发送心跳的时间（纳秒）
+	lastPingSentNs int64
+	// lastPongRecvNs 上次收到 pong 的时间（纳秒）
+	lastPongRecvNs int64
+	// connectedAtNs 最近一次建连时间（纳秒，看门狗计时起点）
+	connectedAtNs int64
+	// downSinceNs 连接断开的起始时间（纳秒，0 表示连接正常）
+	downSinceNs int64
+	// updateCount 更新计数（用于计算 QPS）
+	updateCount int64
+	// backoff 重连退避
+	backoff *backoff.Backoff
+	// closed 是否已关闭
+	closed int32
+
+	// parseErrLog 解析错误日志采样
+	parseErrLog *logsample.Sampler
+	// pollErrLog REST 轮询错误日志采样
+	pollErrLog *logsample.Sampler
+	// parseNsSum/parseCount/parseMaxNs 当前统计周期的解析耗时累计（由 metricsLoop 每秒清零）
+	parseNsSum int64
+	parseCount int64
+	parseMaxNs int64
+}
+
+// New 创建连接管理器
+// 参数 cfg: WebSocket 配置
+// 参数 spec: 交易所差异钩子
+// 参数 logger: 日志记录器（已按交易所命名）
+func New(cfg *config.ExchangeWSConfig, spec Spec, logger *zap.Logger) *Manager {
+	bookSize, errSize := cfg.BookChSize, cfg.ErrChSize
+	if bookSize <= 0 {
+		bookSize = defaultBookChSize
+	}
+	if errSize <= 0 {
+		errSize = defaultErrChSize
+	}
+	bookCh := make(chan *model.BookEvent, bookSize)
+	m := &Manager{
+		cfg:         cfg,
+		spec:        spec,
+		logger:      logger,
+		bookCh:      bookCh,
+		sender:      backpressure.NewSender(bookCh, cfg),
+		errCh:       make(chan error, errSize),
+		limiter:     ratelimit.New(cfg.MaxMsgsPerSec, cfg.MsgBurst),
+		backoff:     backoff.NewDefault(),
+		dropLog:     logsample.New(1000, 0),
+		parseErrLog: logsample.New(100, time.Minute),
+		pollErrLog:  logsample.New(100, time.Minute),
+	}
+	m.netDial = newNetDial(cfg)
+	if cfg.PreResolveDNS || cfg.PinIP != "" {
+		ep, err := newEndpoint(cfg.URL, cfg.PinIP, m.netDial)
+		if err != nil {
+			logger.Warn(spec.Name+" 接入点地址无法解析，按域名建连", zap.Error(err))
+		} else {
+			m.endpoint = ep
+		}
+	}
+	m.backoff.SetMaxAttempts(cfg.ReconnectMaxAttempts)
+	m.backoff.OnRetry(func(attempt int, delay time.Duration) {
+		m.logger.Info(m.spec.Name+" 准备重连", zap.Int("attempt", attempt), zap.Duration("delay", delay))
+	})
+	return m
+}
+
+// Connect 建立 WebSocket 连接
+// 参数 ctx: 上下文，用于取消连接
+func (m *Manager) Connect(ctx context.Context) error {
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+
+	header := http.Header{}
+	header.Set("User-Agent", "latency-arbitrage-validator/1.0")
+	header.Set("Origin", m.spec.Origin)
+
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second, NetDialContext: m.netDial}
+	if m.endpoint != nil {
+		m.resolveEndpoint(ctx)
+		// 按缓存 IP 建连；URL 不变，TLS SNI 与 Host 头仍为域名
+		dialer.NetDialContext = m.endpoint.DialContext
+	}
+	conn, _, err := dialer.DialContext(ctx, m.cfg.URL, header)
+	if err != nil {
+		// 首次建连失败同样计为断线，读循环重连期间 REST 轮询降级照常启动
+		atomic.CompareAndSwapInt64(&m.downSinceNs, 0, timeutil.NowNano())
+		return fmt.Errorf("连接 %s WebSocket 失败: %w", m.spec.Name, err)
+	}
+
+	readTimeout := m.readTimeout()
+	if readTimeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
+	}
+	// 协议层 pong：计入消息时间与 RTT，并顺延读超时
+	conn.SetPongHandler(func(string) error {
+		nowNs := timeutil.NowNano()
+		atomic.StoreInt64(&m.lastMsgTime, nowNs)
+		m.recordPong(nowNs)
+		if readTimeout > 0 {
+			return conn.SetReadDeadline(time.Now().Add(readTimeout))
+		}
+		return nil
+	})
+
+	m.conn = conn
+	atomic.StoreInt64(&m.connectedAtNs, timeutil.NowNano())
+	atomic.StoreInt64(&m.downSinceNs, 0)
+	m.logger.Info(m.spec.Name+" WebSocket 连接成功", zap.String("url", m.cfg.URL))
+	return nil
+}
+
+// Subscribe 发送订阅请求
+// 交易对按 subscribe_chunk_size 拆分为多帧，帧间间隔 subscribe_interval_ms；
+// 各交易对的确认单独跟踪，被拒绝或超时未确认的由 subscribeRetryLoop 重试。
+func (m *Manager) Subscribe() error {
+	symbols := m.spec.Symbols()
+	m.subs.reset(len(symbols))
+	frames, err := m.sendSubscribe(symbols)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info(m.spec.Name+" 订阅请求已发送", zap.Int("symbols", len(symbols)), zap.Int("frames", frames))
+	return nil
+}
+
+// sendSubscribe 分帧发送订阅请求并登记待确认
+// 返回: 发送的帧数
+func (m *Manager) sendSubscribe(symbols []string) (int, error) {
+	chunks := chunkSymbols(symbols, m.cfg.SubscribeChunkSize)
+	interval := time.Duration(m.cfg.SubscribeIntervalMs) * time.Millisecond
+
+	for i, chunk := range chunks {
+		// 帧间隔在锁外等待，不阻塞心跳写入
+		if i > 0 && interval > 0 {
+			time.Sleep(interval)
+		}
+		id := atomic.AddInt64(&m.nextReqID, 1)
+		data, err := m.spec.SubscribeFrame(id, chunk)
+		if err != nil {
+			return i, fmt.Errorf("序列化订阅请求失败: %w", err)
+		}
+		// 先登记再发送，避免确认先于登记到达
+		if m.spec.ParseAck != nil {
+			m.subs.add(id, chunk, timeutil.NowNano())
+		}
+		if err := m.writeText(data); err != nil {
+			return i, fmt.Errorf("发送订阅请求失败: %w", err)
+		}
+		if m.spec.ParseAck == nil {
+			m.subs.markAcked(chunk)
+		}
+		m.metricsMu.Lock()
+		m.metrics.SubscribeChunksSent++
+		m.metricsMu.Unlock()
+	}
+	return len(chunks), nil
+}
+
+// Unsubscribe 退订交易对
+// 调用方应已将交易对从 Spec.Symbols 中移除，重连后不再订阅；未连接时只清理订阅状态。
+// 参数 symbols: 退订的统一交易对
+// 参数 frame: 构建一个退订请求帧（按 subscribe_chunk_size 拆分后逐帧构建）
+func (m *Manager) Unsubscribe(symbols []string, frame func(id int64, symbols []string) ([]byte, error)) error {
+	if len(symbols) == 0 {
+		return nil
+	}
+	m.subs.forget(symbols, len(m.spec.Symbols()))
+
+	m.connMu.Lock()
+	connected := m.conn != nil
+	m.connMu.Unlock()
+	if !connected {
+		return nil
+	}
+
+	frames, err := m.sendUnsubscribe(symbols, frame)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info(m.spec.Name+" 退订请求已发送", zap.Strings("symbols", symbols), zap.Int("frames", frames))
+	return nil
+}
+
+// Resubscribe 在当前连接上先退订再重新订阅交易对（交易对仍在 Spec.Symbols 中）
+// 用于增量行情断档后让交易所重新推送全量快照；未连接时忽略（重连后会重新订阅）。
+// 参数 frame: 构建一个退订请求帧
+func (m *Manager) Resubscribe(symbols []string, frame func(id int64, symbols []string) ([]byte, error)) error {
+	if len(symbols) == 0 {
+		return nil
+	}
+	m.connMu.Lock()
+	connected := m.conn != nil
+	m.connMu.Unlock()
+	if !connected {
+		return nil
+	}
+
+	if _, err := m.sendUnsubscribe(symbols, frame); err != nil {
+		return err
+	}
+	if _, err := m.sendSubscribe(symbols); err != nil {
+		return err
+	}
+	m.logger.Info(m.spec.Name+" 已重新订阅", zap.Strings("symbols", symbols))
+	return nil
+}
+
+// sendUnsubscribe 分帧发送退订请求（退订不跟踪确认）
+// 返回: 发送的帧数
+func (m *Manager) sendUnsubscribe(symbols []string, frame func(id int64, symbols []string) ([]byte, error)) (int, error) {
+	chunks := chunkSymbols(symbols, m.cfg.SubscribeChunkSize)
+	interval := time.Duration(m.cfg.SubscribeIntervalMs) * time.Millisecond
+	for i, chunk := range chunks {
+		if i > 0 && interval > 0 {
+			time.Sleep(interval)
+		}
+		data, err := frame(atomic.AddInt64(&m.nextReqID, 1), chunk)
+		if err != nil {
+			return i, fmt.Errorf("序列化退订请求失败: %w", err)
+		}
+		if err := m.writeText(data); err != nil {
+			return i, fmt.Errorf("发送退订请求失败: %w", err)
+		}
+	}
+	return len(chunks), nil
+}
+
+// writeText 按出站限速等待后，在连接锁内发送一条文本帧
+func (m *Manager) writeText(data []byte) error {
+	m.throttle()
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+
+	if m.conn == nil {
+		return fmt.Errorf("WebSocket 未连接")
+	}
+	m.raw.Outbound(timeutil.NowNano(), data)
+	return m.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// handleAck 处理订阅确认：按交易对记录订阅状态，请求完成时计数，拒绝时告警并上报
+// 被拒绝的交易对由 subscribeRetryLoop 重试。
+func (m *Manager) handleAck(ack Ack) {
+	res := m.subs.ack(ack)
+	if !res.known || !res.done {
+		// 未知或已处理过的请求，或 OKX 逐参数确认尚未到齐
+		return
+	}
+	m.metricsMu.Lock()
+	if ack.OK {
+		m.metrics.SubscribeChunksAcked++
+	} else {
+		m.metrics.SubscribeChunksRejected++
+	}
+	m.metricsMu.Unlock()
+	if ack.OK {
+		return
+	}
+
+	m.logger.Warn(m.spec.Name+" 订阅请求被拒绝",
+		zap.Int64("id", ack.ID), zap.Strings("symbols", res.rejected), zap.String("msg", ack.Msg))
+	m.reportError(connerr.CategorySubscribe, fmt.Errorf("订阅请求 %d 被拒绝: %s", ack.ID, ack.Msg))
+}
+
+// subscribeRetryLoop 订阅确认检查循环
+// 发送超过 subscribe_ack_timeout_ms 仍未确认的交易对视为失败；失败（含被拒绝）的交易对
+// 在同一连接上最多重试 subscribe_max_retries 次，耗尽后告警放弃，直到下次重连重新订阅。
+func (m *Manager) subscribeRetryLoop(ctx context.Context) {
+	if m.spec.ParseAck == nil {
+		return
+	}
+	timeout := time.Duration(m.cfg.SubscribeAckTimeoutMs) * time.Millisecond
+	interval := time.Second
+	if timeout > 0 && timeout/4 < interval {
+		interval = timeout / 4
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if atomic.LoadInt32(&m.closed) == 1 {
+				return
+			}
+
+			m.connMu.Lock()
+			hasConn := m.conn != nil
+			m.connMu.Unlock()
+			if !hasConn {
+				continue
+			}
+
+			if timeout > 0 {
+				if n, symbols := m.subs.expire(timeutil.NowNano(), int64(timeout)); n > 0 {
+					m.logger.Warn(m.spec.Name+" 订阅确认超时", zap.Int("frames", n), zap.Strings("symbols", symbols))
+					m.reportError(connerr.CategorySubscribe, fmt.Errorf("%d 个订阅请求确认超时", n))
+				}
+			}
+
+			retry, exhausted := m.subs.takeRetry(m.cfg.SubscribeMaxRetries)
+			if len(exhausted) > 0 {
+				m.logger.Warn(m.spec.Name+" 订阅重试次数耗尽，放弃订阅", zap.Strings("symbols", exhausted))
+			}
+			if len(retry) == 0 {
+				continue
+			}
+			m.metricsMu.Lock()
+			m.metrics.SubscribeRetries += int64(len(retry))
+			m.metricsMu.Unlock()
+			m.logger.Info(m.spec.Name+" 重新订阅未确认的交易对", zap.Strings("symbols", retry))
+			if _, err := m.sendSubscribe(retry); err != nil {
+				m.logger.Warn(m.spec.Name+" 重新订阅失败", zap.Error(err))
+				m.reportError(connerr.CategorySubscribe, err)
+			}
+		}
+	}
+}
+
+// Run 启动主循环（读循环、心跳、指标统计、看门狗、订阅确认检查、REST 轮询降级），直到 ctx 取消或关闭
+func (m *Manager) Run(ctx context.Context) {
+	go m.heartbeatLoop(ctx)
+	go m.metricsLoop(ctx)
+	go m.watchdogLoop(ctx)
+	go m.subscribeRetryLoop(ctx)
+	go m.restFallbackLoop(ctx)
+	m.readLoop(ctx)
+}
+
+// readLoop 读取循环
+// 持续读取 WebSocket 消息并解析，断线时退避重连
+func (m *Manager) readLoop(ctx context.Context) {
+	readTimeout := m.readTimeout()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if atomic.LoadInt32(&m.closed) == 1 {
+			return
+		}
+
+		m.connMu.Lock()
+		conn := m.conn
+		m.connMu.Unlock()
+
+		if conn == nil {
+			if !m.reconnect(ctx) {
+				return
+			}
+			continue
+		}
+
+		_, data, err := conn.ReadMessage()
+		// 到达时间在 socket 读出后立即采集，不含后续 JSON 解析与排队耗时
+		nowNs := timeutil.NowNano()
+		if err != nil {
+			m.logger.Warn("读取 "+m.spec.Name+" 消息失败", zap.Error(err))
+			m.reportError(connerr.CategoryRead, err)
+			m.incrementReconnectCount()
+			if !m.reconnect(ctx) {
+				return
+			}
+			continue
+		}
+
+		if readTimeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
+		}
+
+		atomic.StoreInt64(&m.lastMsgTime, nowNs)
+		m.maybeResetBackoff(nowNs)
+		sampled := m.raw.Inbound(nowNs, data)
+
+		if m.spec.IsPong != nil && m.spec.IsPong(data) {
+			m.recordPong(nowNs)
+			continue
+		}
+		if m.spec.ParseAck != nil {
+			if ack, ok := m.spec.ParseAck(data); ok {
+				m.handleAck(ack)
+				continue
+			}
+		}
+
+		events, err := m.spec.Handle(ctx, data, nowNs)
+		parsedNs := timeutil.NowNano()
+		m.recordParse(parsedNs - nowNs)
+		if err != nil {
+			m.incrementParseErrorCount()
+			m.maybeLogParseError(err, data)
+			if !sampled {
+				m.raw.InboundParseError(nowNs, data, err)
+			}
+			continue
+		}
+
+		for _, event := range events {
+			atomic.AddInt64(&m.updateCount, 1)
+			event.ParsedAtUnixNs = parsedNs
+			if dropped := m.sender.Send(event); dropped > 0 {
+				m.recordDropped(dropped)
+			}
+			observeHighWater(&m.bookHighWater, len(m.bookCh))
+		}
+	}
+}
+
+// heartbeatLoop 心跳循环
+// 按 PingIntervalMs 发送心跳；配置了 PongTimeoutMs 时在下一次发送前检查上一轮 pong 是否按期返回
+func (m *Manager) heartbeatLoop(ctx context.Context) {
+	if m.spec.PingIntervalMs <= 0 || m.spec.PingFrame == nil {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(m.spec.PingIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	var seq uint64
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if atomic.LoadInt32(&m.closed) == 1 {
+				return
+			}
+
+			// 上一轮心跳的 pong 未按期返回时直接重连，不再发送新心跳
+			if m.spec.PongTimeoutMs > 0 && m.pongOverdue() {
+				m.logger.Warn(m.spec.Name + " 心跳超时，触发重连")
+				m.reportError(connerr.CategoryHeartbeat, errPongTimeout)
+				m.incrementReconnectCount()
+				m.closeConn()
+				continue
+			}
+
+			// 心跳同样计入交易所的消息频率，限速等待在锁外进行
+			m.throttle()
+
+			m.connMu.Lock()
+			conn := m.conn
+			if conn == nil {
+				m.connMu.Unlock()
+				continue
+			}
+
+			seq++
+			msgType, data, err := m.spec.PingFrame(seq)
+			if err != nil {
+				m.connMu.Unlock()
+				continue
+			}
+			pingTime := timeutil.NowNano()
+			if msgType == websocket.PingMessage {
+				err = conn.WriteControl(websocket.PingMessage, data, time.Now().Add(5*time.Second))
+			} else {
+				m.raw.Outbound(pingTime, data)
+				err = conn.WriteMessage(msgType, data)
+			}
+			if err != nil {
+				m.connMu.Unlock()
+				m.logger.Warn("发送 "+m.spec.Name+" 心跳失败", zap.Error(err))
+				m.reportError(connerr.CategoryHeartbeat, err)
+				continue
+			}
+			atomic.StoreInt64(&m.lastPingSentNs, pingTime)
+			m.connMu.Unlock()
+		}
+	}
+}
+
+// pongOverdue 最近一次心跳发出后超过 PongTimeoutMs 仍未收到 pong
+func (m *Manager) pongOverdue() bool {
+	lastPing := atomic.LoadInt64(&m.lastPingSentNs)
+	lastPong := atomic.LoadInt64(&m.lastPongRecvNs)
+	if lastPing <= 0 || lastPong >= lastPing {
+		return false
+	}
+	return timeutil.NowNano()-lastPing > int64(m.spec.PongTimeoutMs)*1_000_000
+}
+
+// metricsLoop 指标统计循环
+// 每秒计算 QPS、最后消息距今时间与解析耗时
+func (m *Manager) metricsLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var lastCount int64
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if atomic.LoadInt32(&m.closed) == 1 {
+				return
+			}
+
+			count := atomic.LoadInt64(&m.updateCount)
+			qps := float64(count - lastCount)
+			lastCount = count
+
+			lastMsg := atomic.LoadInt64(&m.lastMsgTime)
+			var ageMs int64
+			if lastMsg > 0 {
+				ageMs = (timeutil.NowNano() - lastMsg) / 1_000_000
+			}
+
+			// 解析耗时（近 1 秒）
+			parseNs := atomic.SwapInt64(&m.parseNsSum, 0)
+			parseCount := atomic.SwapInt64(&m.parseCount, 0)
+			parseMaxNs := atomic.SwapInt64(&m.parseMaxNs, 0)
+			var parseAvgUs float64
+			if parseCount > 0 {
+				parseAvgUs = float64(parseNs) / float64(parseCount) / 1000
+			}
+
+			m.metricsMu.Lock()
+			m.metrics.ParseAvgUs = parseAvgUs
+			m.metrics.ParseMaxUs = float64(parseMaxNs) / 1000
+			m.metrics.UpdatesPerSec = qps
+			m.metrics.LastMessageAgeMs = ageMs
+			m.metricsMu.Unlock()
+		}
+	}
+}
+
+// watchdogLoop 行情看门狗
+// 连接仍打开但超过 stale_timeout_ms 未收到任何消息（含心跳响应）时，
+// 关闭连接促使 readLoop 重连，避免半开 TCP 连接导致读循环无限阻塞。
+func (m *Manager) watchdogLoop(ctx context.Context) {
+	staleMs := m.cfg.StaleTimeoutMs
+	if staleMs <= 0 {
+		return
+	}
+	staleNs := int64(staleMs) * 1_000_000
+
+	interval := time.Duration(staleMs) * time.Millisecond / 4
+	if interval > time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if atomic.LoadInt32(&m.closed) == 1 {
+				return
+			}
+
+			m.connMu.Lock()
+			hasConn := m.conn != nil
+			m.connMu.Unlock()
+			if !hasConn {
+				continue
+			}
+
+			// 以最后消息时间与建连时间中较晚者为起点，避免刚重连即被判定超时
+			last := atomic.LoadInt64(&m.lastMsgTime)
+			if connectedAt := atomic.LoadInt64(&m.connectedAtNs); connectedAt > last {
+				last = connectedAt
+			}
+			if last <= 0 {
+				continue
+			}
+			silentNs := timeutil.NowNano() - last
+			if silentNs <= staleNs {
+				continue
+			}
+
+			m.logger.Warn(m.spec.Name+" 行情静默超时，强制重连", zap.Duration("silent", time.Duration(silentNs)))
+			m.metricsMu.Lock()
+			m.metrics.WatchdogTrips++
+			m.metricsMu.Unlock()
+			m.closeConn()
+		}
+	}
+}
+
+// restFallbackLoop REST 轮询降级循环
+// 连接断开超过 rest_fallback_after_ms 后，按 rest_fallback_interval_ms 依次拉取各交易对的 REST 深度，
+// 以 Degraded 事件投递（下游暂停时延统计，价差监控继续）；连接恢复后自动停止。
+// 轮询在独立 goroutine 中进行，不占用读循环。
+func (m *Manager) restFallbackLoop(ctx context.Context) {
+	if m.cfg.RestFallbackAfterMs <= 0 || m.spec.PollBook == nil {
+		return
+	}
+	afterNs := int64(m.cfg.RestFallbackAfterMs) * 1_000_000
+	interval := time.Duration(m.cfg.RestFallbackIntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var wasActive bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if atomic.LoadInt32(&m.closed) == 1 {
+				return
+			}
+
+			downSince := atomic.LoadInt64(&m.downSinceNs)
+			active := downSince > 0 && timeutil.NowNano()-downSince >= afterNs
+			if active != wasActive {
+				wasActive = active
+				m.metricsMu.Lock()
+				m.metrics.RestFallbackActive = active
+				m.metricsMu.Unlock()
+				if active {
+					m.logger.Warn(m.spec.Name+" WebSocket 长时间断线，改用 REST 轮询深度（降级行情）",
+						zap.Duration("down", time.Duration(timeutil.NowNano()-downSince)))
+				} else {
+					m.logger.Info(m.spec.Name + " WebSocket 已恢复，停止 REST 轮询")
+				}
+			}
+			if active {
+				m.pollOnce(ctx)
+			}
+		}
+	}
+}
+
+// pollOnce 依次拉取全部交易对的 REST 深度并投递降级事件
+func (m *Manager) pollOnce(ctx context.Context) {
+	for _, canon := range m.spec.Symbols() {
+		if ctx.Err() != nil || atomic.LoadInt32(&m.closed) == 1 || atomic.LoadInt64(&m.downSinceNs) == 0 {
+			return
+		}
+		ev, err := m.spec.PollBook(ctx, canon)
+		if err != nil {
+			m.metricsMu.Lock()
+			m.metrics.RestPollErrors++
+			m.metricsMu.Unlock()
+			if ok, suppressed := m.pollErrLog.Allow(); ok {
+				m.logger.Warn(m.spec.Name+" REST 轮询深度失败（采样）",
+					zap.String("symbol", canon), zap.Error(err), zap.Uint64("suppressed", suppressed))
+			}
+			continue
+		}
+		if ev == nil {
+			continue
+		}
+		// REST 快照的序列号与 WS 推送不可比较，置 0 以免恢复后 WS 事件被判为过期
+		ev.Seq = 0
+		ev.Degraded = true
+		ev.ParsedAtUnixNs = timeutil.NowNano()
+		m.metricsMu.Lock()
+		m.metrics.RestPolls++
+		m.metricsMu.Unlock()
+		if dropped := m.sender.Send(ev); dropped > 0 {
+			m.recordDropped(dropped)
+		}
+		observeHighWater(&m.bookHighWater, len(m.bookCh))
+	}
+}
+
+// reconnect 按退避等待后重连并重新订阅
+// 返回: 是否继续读循环（ctx 取消或连续重连次数达到 reconnect_max_attempts 时为 false）
+func (m *Manager) reconnect(ctx context.Context) bool {
+	m.closeConn()
+
+	if err := m.backoff.WaitNext(ctx); err != nil {
+		if errors.Is(err, backoff.ErrMaxAttempts) {
+			m.logger.Error(m.spec.Name+" 连续重连失败，放弃重连", zap.Error(err))
+			m.reportError(connerr.CategoryDial, err)
+		}
+		return false
+	}
+
+	if err := m.Connect(ctx); err != nil {
+		m.logger.Error(m.spec.Name+" 重连失败", zap.Error(err))
+		m.reportError(connerr.CategoryDial, err)
+		return true
+	}
+	if m.spec.OnReconnect != nil {
+		m.spec.OnReconnect()
+	}
+	if err := m.Subscribe(); err != nil {
+		m.logger.Error(m.spec.Name+" 重新订阅失败", zap.Error(err))
+		m.reportError(connerr.CategorySubscribe, err)
+	}
+	return true
+}
+
+// maybeResetBackoff 连接持续健康（建连后有消息流入）超过 backoff_reset_after_ms 后重置重连退避
+// 建连成功即重置会让反复闪断的接入点始终以基础间隔重连，持续冲击交易所；仅由读循环调用。
+func (m *Manager) maybeResetBackoff(nowNs int64) {
+	if m.backoff.Attempt() == 0 {
+		return
+	}
+	if nowNs-atomic.LoadInt64(&m.connectedAtNs) >= int64(m.cfg.BackoffResetAfterMs)*int64(time.Millisecond) {
+		m.backoff.Reset()
+	}
+}
+
+// closeConn 关闭当前连接
+func (m *Manager) closeConn() {
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+
+	if m.conn != nil {
+		_ = m.conn.Close()
+		m.conn = nil
+		atomic.CompareAndSwapInt64(&m.downSinceNs, 0, timeutil.NowNano())
+	}
+}
+
+// Close 关闭连接并关闭输出通道
+func (m *Manager) Close() error {
+	atomic.StoreInt32(&m.closed, 1)
+	m.closeConn()
+	close(m.bookCh)
+	close(m.errCh)
+	m.logger.Info(m.spec.Name + " 客户端已关闭")
+	return nil
+}
+
+// SetRawCapture 设置原始帧采样录制器（需在 Connect 之前调用）
+func (m *Manager) SetRawCapture(r *rawcapture.Recorder) {
+	m.raw = r
+}
+
+// BookCh 获取订单簿事件通道
+func (m *Manager) BookCh() <-chan *model.BookEvent {
+	return m.bookCh
+}
+
+// ErrCh 获取错误通道（元素为 *connerr.Error；通道满时丢弃）
+func (m *Manager) ErrCh() <-chan error {
+	return m.errCh
+}
+
+// Metrics 获取连接指标
+func (m *Manager) Metrics() Metrics {
+	m.metricsMu.RLock()
+	mt := m.metrics
+	m.metricsMu.RUnlock()
+	st := m.subs.state()
+	mt.SubscribeChunksPending = int64(st.pending)
+	mt.SymbolsConfigured = int64(st.configured)
+	mt.SymbolsSubscribed = int64(st.subscribed)
+	mt.BookChCap = int64(cap(m.bookCh))
+	mt.BookChHighWater = m.bookHighWater.Load()
+	mt.ErrChCap = int64(cap(m.errCh))
+	mt.ErrChHighWater = m.errHighWater.Load()
+	mt.Reconnecting = atomic.LoadInt64(&m.downSinceNs) > 0
+	if m.endpoint != nil {
+		mt.RemoteIP = m.endpoint.Remote()
+	}
+	return mt
+}
+
+// observeHighWater 记录通道占用的最大值
+func observeHighWater(hw *atomic.Int64, n int) {
+	v := int64(n)
+	for {
+		cur := hw.Load()
+		if v <= cur || hw.CompareAndSwap(cur, v) {
+			return
+		}
+	}
+}
+
+// resolveEndpoint 首次建连前预解析接入点域名并缓存 IP（调用方持有 connMu）
+// 解析失败不阻止建连：有固定 IP 时先尝试固定 IP，之后的建连会重新解析。
+func (m *Manager) resolveEndpoint(ctx context.Context) {
+	if m.endpointResolved {
+		return
+	}
+	m.endpointResolved = true
+	start := time.Now()
+	addrs, err := m.endpoint.resolve(ctx)
+	if err != nil {
+		m.logger.Warn(m.spec.Name+" 预解析接入点失败", zap.String("host", m.endpoint.host), zap.Error(err))
+		return
+	}
+	m.logger.Info(m.spec.Name+" 接入点已预解析",
+		zap.String("host", m.endpoint.host),
+		zap.Strings("ips", addrs),
+		zap.String("pinned", m.endpoint.pinned),
+		zap.Duration("elapsed", time.Since(start)))
+}
+
+// readTimeout 读超时（0 表示不设置）
+func (m *Manager) readTimeout() time.Duration {
+	return time.Duration(m.spec.ReadTimeoutMs) * time.Millisecond
+}
+
+// recordPong 收到 pong 时按最近一次心跳计算 RTT
+// 仅在心跳之后的首个 pong 计算，避免服务端主动 pong 造成误差。
+func (m *Manager) recordPong(nowNs int64) {
+	lastPong := atomic.SwapInt64(&m.lastPongRecvNs, nowNs)
+	lastPing := atomic.LoadInt64(&m.lastPingSentNs)
+	if lastPing <= 0 || lastPong >= lastPing {
+		return
+	}
+	m.metricsMu.Lock()
+	m.metrics.WsRttMs = (nowNs - lastPing) / 1_000_000
+	m.metricsMu.Unlock()
+}
+
+// reportError 按类别计数并非阻塞地上报连接错误
+// 参数 category: 错误类别（connerr.Category*）
+// 参数 err: 原始错误
+func (m *Manager) reportError(category string, err error) {
+	m.metricsMu.Lock()
+	m.metrics.Errors.Add(category)
+	m.metricsMu.Unlock()
+
+	if atomic.LoadInt32(&m.closed) == 1 {
+		return
+	}
+	connerr.Send(m.errCh, connerr.New(m.spec.Exchange, category, err))
+	observeHighWater(&m.errHighWater, len(m.errCh))
+}
+
+// throttle 按出站令牌桶等待（批量订阅时可能等待数秒，调用方不得持有连接锁）
+func (m *Manager) throttle() {
+	delay := m.limiter.Reserve()
+	if delay <= 0 {
+		return
+	}
+	m.metricsMu.Lock()
+	m.metrics.WritesThrottled++
+	m.metricsMu.Unlock()
+	time.Sleep(delay)
+}
+
+// incrementReconnectCount 增加重连计数
+func (m *Manager) incrementReconnectCount() {
+	m.metricsMu.Lock()
+	m.metrics.ReconnectCount++
+	m.metricsMu.Unlock()
+}
+
+// recordDropped 累计背压丢弃事件数，并采样告警（首次及此后每 1000 次记录 1 条）
+func (m *Manager) recordDropped(n int) {
+	m.metricsMu.Lock()
+	m.metrics.DroppedEvents += int64(n)
+	total := m.metrics.DroppedEvents
+	m.metricsMu.Unlock()
+
+	if ok, suppressed := m.dropLog.Allow(); ok {
+		m.logger.Warn(m.spec.Name+" bookCh 已满，丢弃事件",
+			zap.String("policy", m.sender.Policy()),
+			zap.Int64("dropped_total", total),
+			zap.Uint64("suppressed", suppressed))
+	}
+}
+
+// recordParse 累计单条消息解析耗时
+// 参数 durNs: 从 socket 读出到解析完成的耗时（纳秒）
+func (m *Manager) recordParse(durNs int64) {
+	atomic.AddInt64(&m.parseNsSum, durNs)
+	atomic.AddInt64(&m.parseCount, 1)
+	for {
+		cur := atomic.LoadInt64(&m.parseMaxNs)
+		if durNs <= cur || atomic.CompareAndSwapInt64(&m.parseMaxNs, cur, durNs) {
+			return
+		}
+	}
+}
+
+// incrementParseErrorCount 增加解析错误计数
+func (m *Manager) incrementParseErrorCount() {
+	m.metricsMu.Lock()
+	m.metrics.ParseErrorCount++
+	m.metricsMu.Unlock()
+}
+
+// maybeLogParseError 采样记录解析错误原始消息，避免刷盘
+// 采样策略：首次及此后每 100 次错误记录 1 条，且两条日志至少间隔 1 分钟。
+func (m *Manager) maybeLogParseError(err error, data []byte) {
+	ok, suppressed := m.parseErrLog.Allow()
+	if !ok {
+		return
+	}
+
+	sample := data
+	if len(sample) > 200 {
+		sample = sample[:200]
+	}
+	m.logger.Warn("解析 "+m.spec.Name+" 消息失败（采样）", zap.Error(err), zap.ByteString("data", sample), zap.Uint64("suppressed", suppressed))
+	m.reportError(connerr.CategoryParse, err)
+}
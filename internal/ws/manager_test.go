@@ -0,0 +1,547 @@
+// Package ws 连接管理器测试（分帧订阅与确认、心跳应答、断线重连钩子）
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/config"
+	"latency-arbitrage-validator/internal/core/model"
+	"latency-arbitrage-validator/internal/exchange/connerr"
+)
+
+// echoServer 对每帧 "sub:<id>:..." 回复 "ack:<id>"，两帧订阅到齐后推送一条 "book"；
+// 收到 "ping" 回复 "pong"；
+// 第一个连接在推送后断开。
+func echoServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var conns int32
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		n := atomic.AddInt32(&conns, 1)
+		subs := 0
+		for {
+			_, msg, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			switch {
+			case string(msg) == "ping":
+				_ = c.WriteMessage(websocket.TextMessage, []byte("pong"))
+			case strings.HasPrefix(string(msg), "sub:"):
+				id := strings.Split(string(msg), ":")[1]
+				_ = c.WriteMessage(websocket.TextMessage, []byte("ack:"+id))
+				// 两帧订阅都到达后才推送行情
+				if subs++; subs < 2 {
+					continue
+				}
+				_ = c.WriteMessage(websocket.TextMessage, []byte("book"))
+				if n == 1 {
+					time.Sleep(100 * time.Millisecond)
+					return
+				}
+			}
+		}
+	}))
+	return srv, &conns
+}
+
+func TestManager_SubscribeHeartbeatReconnect(t *testing.T) {
+	srv, conns := echoServer(t)
+	defer srv.Close()
+
+	var reconnects int32
+	// 3 个交易对按 2 个一帧拆分为 2 帧
+	cfg := &config.ExchangeWSConfig{URL: "ws" + strings.TrimPrefix(srv.URL, "http"), SubscribeChunkSize: 2, SubscribeIntervalMs: 10}
+	m := New(cfg, Spec{
+		Name:           "Test",
+		Exchange:       model.ExchangeOKX,
+		PingIntervalMs: 20,
+		PingFrame: func(uint64) (int, []byte, error) {
+			return websocket.TextMessage, []byte("ping"), nil
+		},
+		IsPong:  func(data []byte) bool { return string(data) == "pong" },
+		Symbols: func() []string { return []string{"AUSDT", "BUSDT", "CUSDT"} },
+		SubscribeFrame: func(id int64, symbols []string) ([]byte, error) {
+			return []byte("sub:" + strconv.FormatInt(id, 10) + ":" + strings.Join(symbols, ",")), nil
+		},
+		ParseAck: func(data []byte) (Ack, bool) {
+			rest, ok := strings.CutPrefix(string(data), "ack:")
+			if !ok {
+				return Ack{}, false
+			}
+			id, _ := strconv.ParseInt(rest, 10, 64)
+			return Ack{ID: id, OK: true}, true
+		},
+		OnReconnect: func() { atomic.AddInt32(&reconnects, 1) },
+		Handle: func(_ context.Context, data []byte, nowNs int64) ([]*model.BookEvent, error) {
+			return []*model.BookEvent{{Exchange: model.ExchangeOKX, ArrivedAtUnixNs: nowNs}}, nil
+		},
+	}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	if err := m.Subscribe(); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	go m.Run(ctx)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case ev := <-m.BookCh():
+			if ev.ParsedAtUnixNs < ev.ArrivedAtUnixNs {
+				t.Fatalf("ParsedAt 应不早于 ArrivedAt: %+v", ev)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("第 %d 个事件超时（连接数 %d）", i+1, atomic.LoadInt32(conns))
+		}
+	}
+
+	if n := atomic.LoadInt32(&reconnects); n < 1 {
+		t.Fatalf("OnReconnect 调用 %d 次, want >= 1", n)
+	}
+	mt := m.Metrics()
+	if mt.ReconnectCount < 1 || mt.Errors.Read < 1 {
+		t.Fatalf("ReconnectCount=%d Errors.Read=%d, want >= 1", mt.ReconnectCount, mt.Errors.Read)
+	}
+	// 两个连接各发送 2 帧并全部确认
+	if mt.SubscribeChunksSent != 4 || mt.SubscribeChunksAcked != 4 || mt.SubscribeChunksPending != 0 {
+		t.Fatalf("订阅帧统计错误: sent=%d acked=%d pending=%d", mt.SubscribeChunksSent, mt.SubscribeChunksAcked, mt.SubscribeChunksPending)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt64(&m.lastPongRecvNs) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("应收到 pong")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	_ = m.Close()
+}
+
+func TestChunkSymbols(t *testing.T) {
+	syms := []string{"A", "B", "C", "D", "E"}
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{0, []int{5}},
+		{5, []int{5}},
+		{2, []int{2, 2, 1}},
+		{1, []int{1, 1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		chunks := chunkSymbols(syms, tt.size)
+		if len(chunks) != len(tt.want) {
+			t.Fatalf("size=%d: %d 帧, want %d", tt.size, len(chunks), len(tt.want))
+		}
+		for i, c := range chunks {
+			if len(c) != tt.want[i] {
+				t.Fatalf("size=%d: 第 %d 帧 %d 个, want %d", tt.size, i, len(c), tt.want[i])
+			}
+		}
+	}
+	if chunkSymbols(nil, 2) != nil {
+		t.Fatalf("空列表应不产生订阅帧")
+	}
+}
+
+func TestSubTracker(t *testing.T) {
+	var tr subTracker
+	tr.reset(4)
+	tr.add(1, []string{"A", "B"}, 0)
+	tr.add(2, []string{"C"}, 0)
+	tr.add(3, []string{"D"}, 100)
+
+	// 逐参数确认：A 确认后请求 1 仍待确认
+	if res := tr.ack(Ack{ID: 1, OK: true, Symbols: []string{"A"}}); !res.known || res.done {
+		t.Fatalf("部分确认结果错误: %+v", res)
+	}
+	if res := tr.ack(Ack{ID: 1, OK: true, Symbols: []string{"B"}}); !res.done {
+		t.Fatalf("全部参数确认后请求应完成: %+v", res)
+	}
+	if res := tr.ack(Ack{ID: 2, OK: false, Msg: "bad"}); !res.done || len(res.rejected) != 1 {
+		t.Fatalf("拒绝结果错误: %+v", res)
+	}
+	if res := tr.ack(Ack{ID: 9, OK: true}); res.known {
+		t.Fatalf("未登记请求不应被识别")
+	}
+	if st := tr.state(); st.configured != 4 || st.subscribed != 2 || st.pending != 1 {
+		t.Fatalf("状态错误: %+v", st)
+	}
+
+	// D 超时，C 被拒绝：两者都进入重试
+	if n, syms := tr.expire(200, 50); n != 1 || len(syms) != 1 || syms[0] != "D" {
+		t.Fatalf("超时结果错误: %d %v", n, syms)
+	}
+	retry, exhausted := tr.takeRetry(1)
+	if strings.Join(retry, ",") != "C,D" || len(exhausted) != 0 {
+		t.Fatalf("第一轮重试错误: %v %v", retry, exhausted)
+	}
+	tr.add(4, retry, 300)
+	tr.ack(Ack{ID: 4, OK: false})
+	retry, exhausted = tr.takeRetry(1)
+	if len(retry) != 0 || strings.Join(exhausted, ",") != "C,D" {
+		t.Fatalf("重试耗尽错误: %v %v", retry, exhausted)
+	}
+}
+
+// TestManager_RetryRejectedSubscribe 首次订阅被拒绝的交易对应自动重试直至确认
+func TestManager_RetryRejectedSubscribe(t *testing.T) {
+	var subs int32
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			_, msg, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			id := strings.Split(string(msg), ":")[1]
+			// 第一次订阅拒绝，之后确认
+			if atomic.AddInt32(&subs, 1) == 1 {
+				_ = c.WriteMessage(websocket.TextMessage, []byte("nak:"+id))
+				continue
+			}
+			_ = c.WriteMessage(websocket.TextMessage, []byte("ack:"+id))
+		}
+	}))
+	defer srv.Close()
+
+	cfg := &config.ExchangeWSConfig{
+		URL:                   "ws" + strings.TrimPrefix(srv.URL, "http"),
+		SubscribeAckTimeoutMs: 40,
+		SubscribeMaxRetries:   2,
+	}
+	m := New(cfg, Spec{
+		Name:     "Test",
+		Exchange: model.ExchangeBittap,
+		Symbols:  func() []string { return []string{"AUSDT", "BUSDT"} },
+		SubscribeFrame: func(id int64, symbols []string) ([]byte, error) {
+			return []byte("sub:" + strconv.FormatInt(id, 10) + ":" + strings.Join(symbols, ",")), nil
+		},
+		ParseAck: func(data []byte) (Ack, bool) {
+			parts := strings.Split(string(data), ":")
+			id, _ := strconv.ParseInt(parts[1], 10, 64)
+			return Ack{ID: id, OK: parts[0] == "ack"}, true
+		},
+		Handle: func(context.Context, []byte, int64) ([]*model.BookEvent, error) { return nil, nil },
+	}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	if err := m.Subscribe(); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	go m.Run(ctx)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		mt := m.Metrics()
+		if mt.SymbolsSubscribed == 2 {
+			if mt.SymbolsConfigured != 2 || mt.SubscribeRetries != 2 || mt.SubscribeChunksRejected != 1 || mt.Errors.Subscribe != 1 {
+				t.Fatalf("订阅指标错误: %+v", mt)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("被拒绝的交易对未重试成功: %+v", mt)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	_ = m.Close()
+}
+
+// TestManager_Unsubscribe 退订发送请求帧，并从订阅状态中移除交易对
+func TestManager_Unsubscribe(t *testing.T) {
+	frames := make(chan string, 10)
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			_, msg, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			frames <- string(msg)
+			if strings.HasPrefix(string(msg), "sub:") {
+				_ = c.WriteMessage(websocket.TextMessage, []byte("ack:"+strings.Split(string(msg), ":")[1]))
+			}
+		}
+	}))
+	defer srv.Close()
+
+	var symbols atomic.Value
+	symbols.Store([]string{"AUSDT", "BUSDT", "CUSDT"})
+	cfg := &config.ExchangeWSConfig{URL: "ws" + strings.TrimPrefix(srv.URL, "http")}
+	m := New(cfg, Spec{
+		Name:     "Test",
+		Exchange: model.ExchangeBinance,
+		Symbols:  func() []string { return symbols.Load().([]string) },
+		SubscribeFrame: func(id int64, symbols []string) ([]byte, error) {
+			return []byte("sub:" + strconv.FormatInt(id, 10) + ":" + strings.Join(symbols, ",")), nil
+		},
+		ParseAck: func(data []byte) (Ack, bool) {
+			rest, ok := strings.CutPrefix(string(data), "ack:")
+			if !ok {
+				return Ack{}, false
+			}
+			id, _ := strconv.ParseInt(rest, 10, 64)
+			return Ack{ID: id, OK: true}, true
+		},
+		Handle: func(context.Context, []byte, int64) ([]*model.BookEvent, error) { return nil, nil },
+	}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	if err := m.Subscribe(); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	go m.Run(ctx)
+	<-frames
+
+	deadline := time.Now().Add(2 * time.Second)
+	for m.Metrics().SymbolsSubscribed != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("订阅未确认: %+v", m.Metrics())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	symbols.Store([]string{"AUSDT"})
+	err := m.Unsubscribe([]string{"BUSDT", "CUSDT"}, func(id int64, symbols []string) ([]byte, error) {
+		return []byte("unsub:" + strconv.FormatInt(id, 10) + ":" + strings.Join(symbols, ",")), nil
+	})
+	if err != nil {
+		t.Fatalf("退订失败: %v", err)
+	}
+	select {
+	case f := <-frames:
+		if !strings.HasPrefix(f, "unsub:") || !strings.HasSuffix(f, ":BUSDT,CUSDT") {
+			t.Fatalf("退订帧错误: %s", f)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("未收到退订帧")
+	}
+	if mt := m.Metrics(); mt.SymbolsConfigured != 1 || mt.SymbolsSubscribed != 1 {
+		t.Fatalf("退订后订阅状态错误: configured=%d subscribed=%d", mt.SymbolsConfigured, mt.SymbolsSubscribed)
+	}
+	cancel()
+	_ = m.Close()
+}
+
+// TestManager_WriteRateLimit 出站订阅帧按令牌桶限速
+func TestManager_WriteRateLimit(t *testing.T) {
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	// 每帧 1 个交易对、不设帧间隔，4 帧在 20 条/秒、突发 1 的限速下至少耗时 150ms
+	cfg := &config.ExchangeWSConfig{
+		URL:                "ws" + strings.TrimPrefix(srv.URL, "http"),
+		SubscribeChunkSize: 1,
+		MaxMsgsPerSec:      20,
+		MsgBurst:           1,
+	}
+	m := New(cfg, Spec{
+		Name:     "Test",
+		Exchange: model.ExchangeBinance,
+		Symbols:  func() []string { return []string{"AUSDT", "BUSDT", "CUSDT", "DUSDT"} },
+		SubscribeFrame: func(id int64, symbols []string) ([]byte, error) {
+			return []byte("sub:" + strconv.FormatInt(id, 10)), nil
+		},
+		Handle: func(context.Context, []byte, int64) ([]*model.BookEvent, error) { return nil, nil },
+	}, zap.NewNop())
+	if err := m.Connect(context.Background()); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer m.Close()
+
+	start := time.Now()
+	if err := m.Subscribe(); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Fatalf("4 帧耗时 %v，未按限速发送", elapsed)
+	}
+	if mt := m.Metrics(); mt.WritesThrottled != 3 || mt.SymbolsSubscribed != 4 {
+		t.Fatalf("WritesThrottled=%d SymbolsSubscribed=%d, want 3/4", mt.WritesThrottled, mt.SymbolsSubscribed)
+	}
+}
+
+// TestManager_RestFallback 连接断开超过阈值后轮询 REST 并投递降级事件
+func TestManager_RestFallback(t *testing.T) {
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		// 建连后立即断开，之后的重连均失败
+		_ = c.Close()
+	}))
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	var polls int32
+	cfg := &config.ExchangeWSConfig{URL: wsURL, RestFallbackAfterMs: 1, RestFallbackIntervalMs: 20}
+	m := New(cfg, Spec{
+		Name:     "Test",
+		Exchange: model.ExchangeOKX,
+		Symbols:  func() []string { return []string{"AUSDT"} },
+		SubscribeFrame: func(id int64, symbols []string) ([]byte, error) {
+			return []byte("sub"), nil
+		},
+		Handle: func(context.Context, []byte, int64) ([]*model.BookEvent, error) { return nil, nil },
+		PollBook: func(_ context.Context, canon string) (*model.BookEvent, error) {
+			atomic.AddInt32(&polls, 1)
+			return &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: canon, Seq: 42}, nil
+		},
+	}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	srv.Close()
+	go m.Run(ctx)
+
+	select {
+	case ev := <-m.BookCh():
+		if !ev.Degraded || ev.Seq != 0 || ev.SymbolCanon != "AUSDT" || ev.ParsedAtUnixNs == 0 {
+			t.Fatalf("降级事件字段错误: %+v", ev)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("未收到 REST 轮询事件（轮询 %d 次）", atomic.LoadInt32(&polls))
+	}
+	if mt := m.Metrics(); !mt.RestFallbackActive || mt.RestPolls < 1 {
+		t.Fatalf("RestFallbackActive=%v RestPolls=%d", mt.RestFallbackActive, mt.RestPolls)
+	}
+	cancel()
+	_ = m.Close()
+}
+
+// TestManager_RestFallbackInitialConnectFailure 首次建连失败后同样启动 REST 轮询降级
+func TestManager_RestFallbackInitialConnectFailure(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+	srv.Close()
+
+	cfg := &config.ExchangeWSConfig{URL: wsURL, RestFallbackAfterMs: 1, RestFallbackIntervalMs: 20}
+	m := New(cfg, Spec{
+		Name:     "Test",
+		Exchange: model.ExchangeOKX,
+		Symbols:  func() []string { return []string{"AUSDT"} },
+		SubscribeFrame: func(id int64, symbols []string) ([]byte, error) {
+			return []byte("sub"), nil
+		},
+		Handle: func(context.Context, []byte, int64) ([]*model.BookEvent, error) { return nil, nil },
+		PollBook: func(_ context.Context, canon string) (*model.BookEvent, error) {
+			return &model.BookEvent{Exchange: model.ExchangeOKX, SymbolCanon: canon}, nil
+		},
+	}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Connect(ctx); err == nil {
+		t.Fatalf("服务端已关闭，连接应失败")
+	}
+	go m.Run(ctx)
+
+	select {
+	case ev := <-m.BookCh():
+		if !ev.Degraded || ev.SymbolCanon != "AUSDT" {
+			t.Fatalf("降级事件字段错误: %+v", ev)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("首次建连失败后未启动 REST 轮询")
+	}
+	if mt := m.Metrics(); !mt.RestFallbackActive || !mt.Reconnecting {
+		t.Fatalf("RestFallbackActive=%v Reconnecting=%v", mt.RestFallbackActive, mt.Reconnecting)
+	}
+	cancel()
+	_ = m.Close()
+}
+
+func TestManager_ChannelCapacities(t *testing.T) {
+	m := New(&config.ExchangeWSConfig{BookChSize: 5, ErrChSize: 3}, Spec{Name: "Test", Exchange: model.ExchangeOKX}, zap.NewNop())
+	m.reportError(connerr.CategoryRead, errors.New("x"))
+	m.reportError(connerr.CategoryRead, errors.New("y"))
+	<-m.ErrCh()
+	m.reportError(connerr.CategoryRead, errors.New("z"))
+
+	mt := m.Metrics()
+	if mt.BookChCap != 5 || mt.ErrChCap != 3 {
+		t.Fatalf("BookChCap=%d ErrChCap=%d, want 5/3", mt.BookChCap, mt.ErrChCap)
+	}
+	if mt.ErrChHighWater != 2 || mt.BookChHighWater != 0 {
+		t.Fatalf("ErrChHighWater=%d BookChHighWater=%d, want 2/0", mt.ErrChHighWater, mt.BookChHighWater)
+	}
+
+	// 未配置时使用默认容量
+	if mt := New(&config.ExchangeWSConfig{}, Spec{}, zap.NewNop()).Metrics(); mt.BookChCap != defaultBookChSize || mt.ErrChCap != defaultErrChSize {
+		t.Fatalf("默认容量=%d/%d", mt.BookChCap, mt.ErrChCap)
+	}
+}
+
+func TestManager_BackoffResetAfterHealthy(t *testing.T) {
+	m := New(&config.ExchangeWSConfig{BackoffResetAfterMs: 1000}, Spec{Name: "Test", Exchange: model.ExchangeOKX}, zap.NewNop())
+	m.backoff.Next()
+	m.backoff.Next()
+	const connectedNs = int64(10 * time.Second)
+	atomic.StoreInt64(&m.connectedAtNs, connectedNs)
+
+	// 建连后不足 1 秒：闪断的连接不重置退避
+	m.maybeResetBackoff(connectedNs + int64(500*time.Millisecond))
+	if got := m.backoff.Attempt(); got != 2 {
+		t.Fatalf("持续时间不足时不应重置, attempt=%d", got)
+	}
+	m.maybeResetBackoff(connectedNs + int64(time.Second))
+	if got := m.backoff.Attempt(); got != 0 {
+		t.Fatalf("持续健康后应重置, attempt=%d", got)
+	}
+}
@@ -0,0 +1,61 @@
+package ws
+
+import "latency-arbitrage-validator/internal/exchange/connerr"
+
+// Metrics 连接质量指标
+type Metrics struct {
+	// ReconnectCount 重连次数
+	ReconnectCount int64
+	// ParseErrorCount 解析错误次数
+	ParseErrorCount int64
+	// UpdatesPerSec 每秒更新次数
+	UpdatesPerSec float64
+	// LastMessageAgeMs 最后消息距今时间（毫秒）
+	LastMessageAgeMs int64
+	// Reconnecting 连接是否已断开、正在等待重连
+	Reconnecting bool
+	// WsRttMs WebSocket RTT（毫秒）
+	WsRttMs int64
+	// WatchdogTrips 看门狗因长时间无消息强制重连的次数
+	WatchdogTrips int64
+	// ParseAvgUs 近 1 秒单条消息平均解析耗时（微秒）
+	ParseAvgUs float64
+	// ParseMaxUs 近 1 秒单条消息最大解析耗时（微秒）
+	ParseMaxUs float64
+	// DroppedEvents 订单簿通道已满时按背压策略丢弃的事件数
+	DroppedEvents int64
+	// BookChCap 订单簿事件通道容量
+	BookChCap int64
+	// BookChHighWater 订单簿事件通道观测到的最大占用（累计；接近 BookChCap 时应调大 book_ch_size）
+	BookChHighWater int64
+	// ErrChCap 连接错误通道容量
+	ErrChCap int64
+	// ErrChHighWater 连接错误通道观测到的最大占用（累计）
+	ErrChHighWater int64
+	// Errors 按类别的连接错误累计次数
+	Errors connerr.Counts
+	// SubscribeChunksSent 已发送的订阅请求帧数（累计）
+	SubscribeChunksSent int64
+	// SubscribeChunksAcked 交易所确认成功的订阅请求帧数（累计）
+	SubscribeChunksAcked int64
+	// SubscribeChunksRejected 交易所拒绝的订阅请求帧数（累计）
+	SubscribeChunksRejected int64
+	// SubscribeChunksPending 当前连接上尚未收到确认的订阅请求帧数
+	SubscribeChunksPending int64
+	// SubscribeRetries 因被拒绝或确认超时而重新订阅的交易对次数（累计）
+	SubscribeRetries int64
+	// SymbolsConfigured 当前连接应订阅的交易对数
+	SymbolsConfigured int64
+	// WritesThrottled 因出站限速而延迟发送的消息数（累计）
+	WritesThrottled int64
+	// SymbolsSubscribed 当前连接上已确认订阅成功的交易对数（小于 SymbolsConfigured 说明有交易对未订阅上）
+	SymbolsSubscribed int64
+	// RestFallbackActive 是否正处于 REST 轮询降级状态
+	RestFallbackActive bool
+	// RestPolls REST 轮询成功拉取的深度次数（累计）
+	RestPolls int64
+	// RestPollErrors REST 轮询失败次数（累计）
+	RestPollErrors int64
+	// RemoteIP 当前连接建连使用的 IP（仅 pre_resolve_dns / pin_ip 启用时记录）
+	RemoteIP string `json:",omitempty"`
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// dialTimeout 单个 IP 的 TCP 建连超时
+const dialTimeout = 5 * time.Second
+
+// endpoint 接入点 IP 缓存
+// 启动时解析一次域名并缓存全部 IP，重连直接按 IP 建连（TLS SNI 与 Host 头仍为域名），
+// 避免每次重连的 DNS 查询抖动计入重连耗时；pin_ip 指定的 IP 总是优先尝试。
+// 缓存的 IP 全部连不上时重新解析一次，解析失败则回退为按域名建连。
+type endpoint struct {
+	host string
+	port string
+	// pinned 固定优先的 IP（为空表示不固定）
+	pinned string
+	// lookup 域名解析（测试可替换）
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+	// dial TCP 建连（含 socket 参数设置，测试可替换）
+	dial dialFunc
+
+	mu sync.Mutex
+	// addrs 缓存的 IP（按解析顺序）
+	addrs []string
+	// remote 最近一次建连成功的 IP
+	remote string
+}
+
+// newEndpoint 解析 WebSocket 地址的主机与端口
+// 参数 pinIP: 固定优先的 IP（可为空）
+// 参数 dial: TCP 建连函数
+func newEndpoint(rawURL, pinIP string, dial dialFunc) (*endpoint, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析地址失败: %w", err)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	return &endpoint{
+		host:   u.Hostname(),
+		port:   port,
+		pinned: pinIP,
+		lookup: net.DefaultResolver.LookupIPAddr,
+		dial:   dial,
+	}, nil
+}
+
+// resolve 解析域名并替换 IP 缓存
+func (e *endpoint) resolve(ctx context.Context) ([]string, error) {
+	ips, err := e.lookup(ctx, e.host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, ip.IP.String())
+	}
+	e.mu.Lock()
+	e.addrs = addrs
+	e.mu.Unlock()
+	return addrs, nil
+}
+
+// candidates 按优先级返回待尝试的 IP（固定 IP 在前，去重）
+func (e *endpoint) candidates() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]string, 0, len(e.addrs)+1)
+	if e.pinned != "" {
+		out = append(out, e.pinned)
+	}
+	for _, a := range e.addrs {
+		if a != e.pinned {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// DialContext 作为 websocket.Dialer.NetDialContext：忽略 addr 中的域名，按缓存 IP 依次建连
+func (e *endpoint) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if conn, err := e.dialAny(ctx, network, e.candidates()); err == nil {
+		return conn, nil
+	}
+	// 缓存全部失败（或尚未解析）：重新解析后再试一轮；解析失败时回退为按域名建连
+	addrs, err := e.resolve(ctx)
+	if err != nil {
+		return e.dial(ctx, network, addr)
+	}
+	return e.dialAny(ctx, network, addrs)
+}
+
+// dialAny 依次尝试 IP，返回第一个成功的连接；全部失败时返回最后一个错误
+func (e *endpoint) dialAny(ctx context.Context, network string, ips []string) (net.Conn, error) {
+	lastErr := fmt.Errorf("%s 无可用 IP", e.host)
+	for _, ip := range ips {
+		conn, err := e.dial(ctx, network, net.JoinHostPort(ip, e.port))
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		e.mu.Lock()
+		e.remote = ip
+		e.mu.Unlock()
+		return conn, nil
+	}
+	return nil, lastErr
+}
+
+// Remote 返回最近一次建连成功的 IP
+func (e *endpoint) Remote() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.remote
+}
@@ -0,0 +1,112 @@
+// Package ws 接入点 IP 缓存与固定 IP 建连测试
+package ws
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+// TestEndpoint_DialOrder 测试固定 IP 优先、缓存失败后重新解析、解析失败回退域名
+func TestEndpoint_DialOrder(t *testing.T) {
+	ep, err := newEndpoint("wss://ws.example.com/ws", "10.0.0.9", nil)
+	if err != nil {
+		t.Fatalf("newEndpoint: %v", err)
+	}
+	if ep.host != "ws.example.com" || ep.port != "443" {
+		t.Fatalf("host/port 不符: %s %s", ep.host, ep.port)
+	}
+
+	lookups := 0
+	resolved := []net.IPAddr{{IP: net.ParseIP("10.0.0.1")}, {IP: net.ParseIP("10.0.0.2")}}
+	ep.lookup = func(context.Context, string) ([]net.IPAddr, error) {
+		lookups++
+		return resolved, nil
+	}
+	var dialed []string
+	up := map[string]bool{"10.0.0.2:443": true, "ws.example.com:443": true}
+	ep.dial = func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if !up[addr] {
+			return nil, errors.New("refused")
+		}
+		c, _ := net.Pipe()
+		return c, nil
+	}
+
+	if _, err := ep.resolve(context.Background()); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	conn, err := ep.DialContext(context.Background(), "tcp", "ws.example.com:443")
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	_ = conn.Close()
+	if want := "10.0.0.9:443,10.0.0.1:443,10.0.0.2:443"; strings.Join(dialed, ",") != want {
+		t.Errorf("建连顺序 %v, want %s", dialed, want)
+	}
+	if lookups != 1 || ep.Remote() != "10.0.0.2" {
+		t.Errorf("缓存可用时不应重新解析: lookups=%d remote=%s", lookups, ep.Remote())
+	}
+
+	// 缓存 IP 全部失败：重新解析一次
+	up["10.0.0.2:443"] = false
+	resolved = []net.IPAddr{{IP: net.ParseIP("10.0.0.3")}}
+	up["10.0.0.3:443"] = true
+	dialed = nil
+	if _, err := ep.DialContext(context.Background(), "tcp", "ws.example.com:443"); err != nil {
+		t.Fatalf("重新解析后应建连成功: %v", err)
+	}
+	if lookups != 2 || ep.Remote() != "10.0.0.3" {
+		t.Errorf("应重新解析: lookups=%d remote=%s dialed=%v", lookups, ep.Remote(), dialed)
+	}
+
+	// 解析失败：回退为按域名建连
+	up["10.0.0.3:443"] = false
+	ep.lookup = func(context.Context, string) ([]net.IPAddr, error) { return nil, errors.New("no such host") }
+	dialed = nil
+	if _, err := ep.DialContext(context.Background(), "tcp", "ws.example.com:443"); err != nil {
+		t.Fatalf("应回退为按域名建连: %v", err)
+	}
+	if dialed[len(dialed)-1] != "ws.example.com:443" {
+		t.Errorf("最后应按域名建连: %v", dialed)
+	}
+}
+
+// TestManager_PinIP 测试固定 IP 建连：域名不可解析时仍连到固定 IP，且 Host 头保持域名
+func TestManager_PinIP(t *testing.T) {
+	hosts := make(chan string, 1)
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.Host
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = c.Close()
+	}))
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	cfg := &config.ExchangeWSConfig{URL: "ws://venue.invalid:" + port + "/ws", PinIP: "127.0.0.1"}
+	m := New(cfg, Spec{Name: "Test"}, zap.NewNop())
+	if err := m.Connect(context.Background()); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer m.Close()
+	if h := <-hosts; h != "venue.invalid:"+port {
+		t.Errorf("Host 头应为域名: %s", h)
+	}
+	if ip := m.Metrics().RemoteIP; ip != "127.0.0.1" {
+		t.Errorf("RemoteIP=%s, want 127.0.0.1", ip)
+	}
+}
@@ -0,0 +1,55 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+// dialFunc TCP 建连函数（websocket.Dialer.NetDialContext 签名）
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newNetDial 按 ws 配置构建 TCP 建连函数：绑定本地地址，建连后设置 TCP_NODELAY 与内核收发缓冲区
+func newNetDial(cfg *config.ExchangeWSConfig) dialFunc {
+	d := &net.Dialer{Timeout: dialTimeout}
+	if cfg.LocalAddr != "" {
+		d.LocalAddr = &net.TCPAddr{IP: net.ParseIP(cfg.LocalAddr)}
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if err := tuneConn(conn, cfg); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("设置 socket 参数失败: %w", err)
+		}
+		return conn, nil
+	}
+}
+
+// tuneConn 设置 TCP 连接参数（非 TCP 连接忽略）
+func tuneConn(conn net.Conn, cfg *config.ExchangeWSConfig) error {
+	tc, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if cfg.TCPNoDelay != nil {
+		if err := tc.SetNoDelay(*cfg.TCPNoDelay); err != nil {
+			return fmt.Errorf("TCP_NODELAY: %w", err)
+		}
+	}
+	if cfg.SocketReadBuffer > 0 {
+		if err := tc.SetReadBuffer(cfg.SocketReadBuffer); err != nil {
+			return fmt.Errorf("SO_RCVBUF: %w", err)
+		}
+	}
+	if cfg.SocketWriteBuffer > 0 {
+		if err := tc.SetWriteBuffer(cfg.SocketWriteBuffer); err != nil {
+			return fmt.Errorf("SO_SNDBUF: %w", err)
+		}
+	}
+	return nil
+}
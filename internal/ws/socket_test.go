@@ -0,0 +1,47 @@
+// Package ws socket 参数设置测试
+package ws
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"latency-arbitrage-validator/internal/config"
+)
+
+// TestNewNetDial 测试本地地址绑定与 socket 参数设置
+func TestNewNetDial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			_ = c.Close()
+		}
+	}()
+
+	noDelay := false
+	cfg := &config.ExchangeWSConfig{
+		TCPNoDelay:        &noDelay,
+		SocketReadBuffer:  1 << 20,
+		SocketWriteBuffer: 1 << 16,
+		LocalAddr:         "127.0.0.1",
+	}
+	conn, err := newNetDial(cfg)(context.Background(), "tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("建连失败: %v", err)
+	}
+	defer conn.Close()
+	if ip := conn.LocalAddr().(*net.TCPAddr).IP.String(); ip != "127.0.0.1" {
+		t.Errorf("本地地址 %s, want 127.0.0.1", ip)
+	}
+
+	// 绑定不存在的本地地址时建连失败
+	cfg.LocalAddr = "192.0.2.1"
+	if conn, err := newNetDial(cfg)(context.Background(), "tcp", ln.Addr().String()); err == nil {
+		_ = conn.Close()
+		t.Errorf("绑定不可用的本地地址应失败")
+	}
+}
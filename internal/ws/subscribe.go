@@ -0,0 +1,259 @@
+package ws
+
+import (
+	"sort"
+	"sync"
+)
+
+// Ack 交易所对订阅请求的确认或拒绝
+type Ack struct {
+	// ID 对应的请求 ID
+	ID int64
+	// OK 是否订阅成功
+	OK bool
+	// Msg 拒绝原因（成功时为空）
+	Msg string
+	// Symbols 本条确认覆盖的统一交易对（为空表示覆盖整个请求，如 Binance/Bittap 按请求确认；
+	// OKX 对每个订阅参数分别确认）
+	Symbols []string
+}
+
+// chunkSymbols 按 size 将交易对拆分为多个订阅请求（size<=0 表示不拆分）
+func chunkSymbols(symbols []string, size int) [][]string {
+	if len(symbols) == 0 {
+		return nil
+	}
+	if size <= 0 || size >= len(symbols) {
+		return [][]string{symbols}
+	}
+	chunks := make([][]string, 0, (len(symbols)+size-1)/size)
+	for start := 0; start < len(symbols); start += size {
+		end := start + size
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		chunks = append(chunks, symbols[start:end])
+	}
+	return chunks
+}
+
+// pendingChunk 已发送但尚未完全确认的订阅请求
+type pendingChunk struct {
+	// symbols 尚未确认的交易对
+	symbols []string
+	// sentAtNs 发送时间（纳秒）
+	sentAtNs int64
+}
+
+// ackResult 一条确认的处理结果
+type ackResult struct {
+	// known 是否对应已登记的请求
+	known bool
+	// done 请求是否已全部确认（成功时）或被拒绝
+	done bool
+	// rejected 被拒绝的交易对
+	rejected []string
+}
+
+// subTracker 当前连接上各交易对的订阅状态
+// 订阅在读循环（重连）、重试 goroutine 或启动 goroutine 中发送，确认在读循环中处理，需加锁。
+type subTracker struct {
+	mu sync.Mutex
+	// pending 待确认请求（key 为请求 ID）
+	pending map[int64]*pendingChunk
+	// acked 已确认订阅成功的交易对
+	acked map[string]struct{}
+	// failed 被拒绝或确认超时、等待重试的交易对
+	failed map[string]struct{}
+	// exhausted 重试次数耗尽、放弃订阅的交易对
+	exhausted map[string]struct{}
+	// attempts 各交易对在当前连接上的重试次数
+	attempts map[string]int
+	// configured 当前连接应订阅的交易对数
+	configured int
+}
+
+// reset 新连接开始订阅时清空状态（旧连接上的请求不会再收到确认）
+// 参数 configured: 应订阅的交易对数
+func (t *subTracker) reset(configured int) {
+	t.mu.Lock()
+	t.pending = make(map[int64]*pendingChunk)
+	t.acked = make(map[string]struct{})
+	t.failed = make(map[string]struct{})
+	t.exhausted = make(map[string]struct{})
+	t.attempts = make(map[string]int)
+	t.configured = configured
+	t.mu.Unlock()
+}
+
+// init 未调用 reset 时延迟初始化（调用方持有锁）
+func (t *subTracker) init() {
+	if t.pending == nil {
+		t.pending = make(map[int64]*pendingChunk)
+		t.acked = make(map[string]struct{})
+		t.failed = make(map[string]struct{})
+		t.exhausted = make(map[string]struct{})
+		t.attempts = make(map[string]int)
+	}
+}
+
+// add 登记一个待确认请求
+func (t *subTracker) add(id int64, symbols []string, nowNs int64) {
+	t.mu.Lock()
+	t.init()
+	t.pending[id] = &pendingChunk{symbols: append([]string(nil), symbols...), sentAtNs: nowNs}
+	t.mu.Unlock()
+}
+
+// markAcked 不跟踪确认时直接视为订阅成功
+func (t *subTracker) markAcked(symbols []string) {
+	t.mu.Lock()
+	t.init()
+	for _, s := range symbols {
+		t.acked[s] = struct{}{}
+	}
+	t.mu.Unlock()
+}
+
+// ack 处理一条确认或拒绝
+// 成功确认时移除对应交易对，请求内交易对全部确认后视为完成；拒绝时请求内剩余交易对全部进入重试。
+func (t *subTracker) ack(a Ack) ackResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.init()
+
+	chunk, ok := t.pending[a.ID]
+	if !ok {
+		return ackResult{}
+	}
+	if !a.OK {
+		delete(t.pending, a.ID)
+		for _, s := range chunk.symbols {
+			t.failed[s] = struct{}{}
+		}
+		return ackResult{known: true, done: true, rejected: chunk.symbols}
+	}
+
+	if len(a.Symbols) == 0 {
+		for _, s := range chunk.symbols {
+			t.acked[s] = struct{}{}
+		}
+		delete(t.pending, a.ID)
+		return ackResult{known: true, done: true}
+	}
+	remaining := chunk.symbols[:0]
+	for _, s := range chunk.symbols {
+		if containsString(a.Symbols, s) {
+			t.acked[s] = struct{}{}
+			continue
+		}
+		remaining = append(remaining, s)
+	}
+	chunk.symbols = remaining
+	if len(remaining) > 0 {
+		return ackResult{known: true}
+	}
+	delete(t.pending, a.ID)
+	return ackResult{known: true, done: true}
+}
+
+// expire 将发送超过 timeoutNs 仍未确认的请求标记为失败
+// 返回: 超时的请求数与其中的交易对
+func (t *subTracker) expire(nowNs, timeoutNs int64) (int, []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var chunks int
+	var symbols []string
+	for id, chunk := range t.pending {
+		if nowNs-chunk.sentAtNs <= timeoutNs {
+			continue
+		}
+		delete(t.pending, id)
+		chunks++
+		for _, s := range chunk.symbols {
+			t.failed[s] = struct{}{}
+			symbols = append(symbols, s)
+		}
+	}
+	sort.Strings(symbols)
+	return chunks, symbols
+}
+
+// takeRetry 取出待重试的交易对（按名称排序），重试次数耗尽的移入 exhausted
+// 参数 maxRetries: 单个交易对最大重试次数
+// 返回: 本轮重试的交易对与本轮新放弃的交易对
+func (t *subTracker) takeRetry(maxRetries int) (retry, exhausted []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for s := range t.failed {
+		delete(t.failed, s)
+		if _, ok := t.acked[s]; ok {
+			continue
+		}
+		if t.attempts[s] >= maxRetries {
+			t.exhausted[s] = struct{}{}
+			exhausted = append(exhausted, s)
+			continue
+		}
+		t.attempts[s]++
+		retry = append(retry, s)
+	}
+	sort.Strings(retry)
+	sort.Strings(exhausted)
+	return retry, exhausted
+}
+
+// forget 退订后移除交易对的全部订阅状态，避免被重试重新订阅
+// 参数 symbols: 退订的交易对
+// 参数 configured: 退订后应订阅的交易对数
+func (t *subTracker) forget(symbols []string, configured int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.init()
+
+	for _, s := range symbols {
+		delete(t.acked, s)
+		delete(t.failed, s)
+		delete(t.exhausted, s)
+		delete(t.attempts, s)
+	}
+	for id, chunk := range t.pending {
+		remaining := chunk.symbols[:0]
+		for _, s := range chunk.symbols {
+			if !containsString(symbols, s) {
+				remaining = append(remaining, s)
+			}
+		}
+		chunk.symbols = remaining
+		if len(remaining) == 0 {
+			delete(t.pending, id)
+		}
+	}
+	t.configured = configured
+}
+
+// subState 订阅状态计数
+type subState struct {
+	configured int
+	subscribed int
+	pending    int
+}
+
+// state 返回当前连接的订阅状态计数
+func (t *subTracker) state() subState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return subState{configured: t.configured, subscribed: len(t.acked), pending: len(t.pending)}
+}
+
+// containsString 判断 list 是否包含 s（确认中的交易对很少，线性查找即可）
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}